@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// Join challenge headers. Clients send the challenge and its solution; the
+// gateway forwards the verdict to the booking service, which screens joins.
+const (
+	JoinChallengeHeader         = "X-Queue-Challenge"
+	JoinChallengeSolutionHeader = "X-Queue-Challenge-Solution"
+	JoinChallengeResultHeader   = "X-Queue-Challenge-Result"
+	ClientASNHeader             = "X-Client-ASN"
+)
+
+// Join challenge results, as forwarded in JoinChallengeResultHeader
+const (
+	JoinChallengePassed  = "passed"
+	JoinChallengeFailed  = "failed"
+	JoinChallengeMissing = "missing"
+)
+
+// Join challenge errors
+var (
+	ErrMalformedChallenge = errors.New("malformed join challenge")
+	ErrExpiredChallenge   = errors.New("join challenge expired")
+	ErrChallengeUnsolved  = errors.New("join challenge not solved")
+	ErrChallengeReused    = errors.New("join challenge already used")
+)
+
+// JoinChallengeConfig holds configuration for the queue join proof-of-work challenge
+type JoinChallengeConfig struct {
+	// Secret signs challenges so the gateway need not store them
+	Secret string
+	// Difficulty is the leading zero bits sha256(challenge + solution) must have
+	Difficulty int
+	// TTL is how long a challenge may be solved and used
+	TTL time.Duration
+	// ChallengePath issues challenges (GET)
+	ChallengePath string
+	// JoinPath is the queue join endpoint whose challenge is verified (POST)
+	JoinPath string
+	// ASNHeader is the header the edge proxy sets to the client's ASN
+	// ("" = ASNs are not forwarded)
+	ASNHeader string
+	// RedisClient records used challenges so a solution is spent on one
+	// join (optional; without it a solution can be replayed until it expires)
+	RedisClient *pkgredis.Client
+}
+
+// DefaultJoinChallengeConfig returns sensible defaults, overridable via ENV
+func DefaultJoinChallengeConfig(secret string) JoinChallengeConfig {
+	return JoinChallengeConfig{
+		Secret:        secret,
+		Difficulty:    getEnvInt("QUEUE_CHALLENGE_DIFFICULTY", 18),
+		TTL:           time.Duration(getEnvInt("QUEUE_CHALLENGE_TTL_SECONDS", 120)) * time.Second,
+		ChallengePath: "/api/v1/queue/challenge",
+		JoinPath:      "/api/v1/queue/join",
+	}
+}
+
+// JoinChallenger issues and verifies queue join challenges. A challenge is
+// "<expires>.<difficulty>.<nonce>.<signature>"; it is solved by a string whose
+// sha256 together with the challenge has Difficulty leading zero bits.
+type JoinChallenger struct {
+	config JoinChallengeConfig
+}
+
+// NewJoinChallenger creates a new join challenger
+func NewJoinChallenger(config JoinChallengeConfig) *JoinChallenger {
+	return &JoinChallenger{config: config}
+}
+
+// Issue returns a new challenge and when it expires
+func (j *JoinChallenger) Issue() (string, time.Time, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(j.config.TTL)
+	payload := fmt.Sprintf("%d.%d.%s", expiresAt.Unix(), j.config.Difficulty, hex.EncodeToString(nonce))
+	return payload + "." + j.sign(payload), expiresAt, nil
+}
+
+// Verify checks a challenge's signature, expiry and solution, and spends it
+// when a Redis client is configured
+func (j *JoinChallenger) Verify(c *gin.Context, challenge, solution string) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 4 {
+		return ErrMalformedChallenge
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(j.sign(payload))) {
+		return ErrMalformedChallenge
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrMalformedChallenge
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ErrMalformedChallenge
+	}
+	ttl := time.Until(time.Unix(expires, 0))
+	if ttl <= 0 {
+		return ErrExpiredChallenge
+	}
+	if solution == "" || leadingZeroBits(sha256.Sum256([]byte(challenge+solution))) < difficulty {
+		return ErrChallengeUnsolved
+	}
+
+	if j.config.RedisClient != nil {
+		fresh, err := j.config.RedisClient.SetNX(c.Request.Context(), "queue:challenge:used:"+parts[2], 1, ttl).Result()
+		if err != nil {
+			// Fail open: a solved challenge is still worth its work
+			return nil
+		}
+		if !fresh {
+			return ErrChallengeReused
+		}
+	}
+	return nil
+}
+
+// sign returns the signature of a challenge payload
+func (j *JoinChallenger) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(j.config.Secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// leadingZeroBits counts the leading zero bits of a hash
+func leadingZeroBits(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// ChallengeHandler returns a handler that issues join challenges
+func (j *JoinChallenger) ChallengeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		challenge, expiresAt, err := j.Issue()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "CHALLENGE_FAILED",
+					"message": "Failed to issue challenge",
+				},
+			})
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"challenge":  challenge,
+				"algorithm":  "sha256",
+				"difficulty": j.config.Difficulty,
+				"expires_at": expiresAt.Unix(),
+			},
+		})
+	}
+}
+
+// JoinChallengeMiddleware verifies the challenge sent with queue joins and
+// forwards the result in JoinChallengeResultHeader. It never blocks a join
+// itself: the booking service weighs the result with its other signals.
+func JoinChallengeMiddleware(config JoinChallengeConfig) gin.HandlerFunc {
+	challenger := NewJoinChallenger(config)
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost || c.Request.URL.Path != config.JoinPath {
+			c.Next()
+			return
+		}
+
+		// Never pass on values sent by the client
+		c.Request.Header.Del(JoinChallengeResultHeader)
+		c.Request.Header.Del(ClientASNHeader)
+		if config.ASNHeader != "" {
+			if asn := c.GetHeader(config.ASNHeader); asn != "" {
+				c.Request.Header.Set(ClientASNHeader, asn)
+			}
+		}
+
+		result := JoinChallengeMissing
+		if challenge := c.GetHeader(JoinChallengeHeader); challenge != "" {
+			result = JoinChallengePassed
+			if err := challenger.Verify(c, challenge, c.GetHeader(JoinChallengeSolutionHeader)); err != nil {
+				result = JoinChallengeFailed
+			}
+		}
+		c.Request.Header.Set(JoinChallengeResultHeader, result)
+
+		c.Next()
+	}
+}
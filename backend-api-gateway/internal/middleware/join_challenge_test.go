@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+// solveChallenge brute-forces a challenge's solution
+func solveChallenge(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		solution := strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(challenge+solution))) >= difficulty {
+			return solution
+		}
+	}
+}
+
+func setupJoinChallengeTestRouter(config JoinChallengeConfig) (*gin.Engine, *string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(config.ChallengePath, NewJoinChallenger(config).ChallengeHandler())
+	router.Use(JoinChallengeMiddleware(config))
+
+	forwarded := new(string)
+	router.POST(config.JoinPath, func(c *gin.Context) {
+		*forwarded = c.GetHeader(JoinChallengeResultHeader)
+		c.Status(http.StatusCreated)
+	})
+	return router, forwarded
+}
+
+func TestJoinChallengeMiddleware(t *testing.T) {
+	config := DefaultJoinChallengeConfig("test-challenge-secret")
+	config.Difficulty = 8
+	client, _ := redistest.NewClient(t)
+	config.RedisClient = client
+	router, forwarded := setupJoinChallengeTestRouter(config)
+
+	issue := func() string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, config.ChallengePath, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data struct {
+				Challenge  string `json:"challenge"`
+				Difficulty int    `json:"difficulty"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 8, response.Data.Difficulty)
+		return response.Data.Challenge
+	}
+	join := func(challenge, solution string, headers ...string) string {
+		req := httptest.NewRequest(http.MethodPost, config.JoinPath, nil)
+		req.Header.Set(JoinChallengeHeader, challenge)
+		req.Header.Set(JoinChallengeSolutionHeader, solution)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return *forwarded
+	}
+
+	t.Run("forwards a solved challenge once", func(t *testing.T) {
+		challenge := issue()
+		solution := solveChallenge(challenge, 8)
+		assert.Equal(t, JoinChallengePassed, join(challenge, solution))
+		assert.Equal(t, JoinChallengeFailed, join(challenge, solution))
+	})
+
+	t.Run("fails a wrong solution", func(t *testing.T) {
+		challenge := issue()
+		wrong := "x"
+		for leadingZeroBits(sha256.Sum256([]byte(challenge+wrong))) >= 8 {
+			wrong += "x"
+		}
+		assert.Equal(t, JoinChallengeFailed, join(challenge, wrong))
+	})
+
+	t.Run("fails a forged challenge", func(t *testing.T) {
+		forged := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10) + ".0.00.bad"
+		assert.Equal(t, JoinChallengeFailed, join(forged, "1"))
+	})
+
+	t.Run("overwrites a result sent by the client", func(t *testing.T) {
+		assert.Equal(t, JoinChallengeMissing, join("", "", JoinChallengeResultHeader, JoinChallengePassed))
+	})
+}
+
+func TestJoinChallenger_Verify_Expired(t *testing.T) {
+	config := DefaultJoinChallengeConfig("test-challenge-secret")
+	config.Difficulty = 0
+	config.TTL = -time.Second
+	challenger := NewJoinChallenger(config)
+
+	challenge, _, err := challenger.Issue()
+	assert.NoError(t, err)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, config.JoinPath, nil)
+	assert.ErrorIs(t, challenger.Verify(c, challenge, "1"), ErrExpiredChallenge)
+}
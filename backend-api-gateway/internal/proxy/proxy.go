@@ -9,6 +9,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"path"
 	"runtime/debug"
 	"strings"
 	"sync"
@@ -47,6 +48,14 @@ type ProxyConfig struct {
 	Routes        []RouteConfig
 	DefaultTimeout time.Duration
 	JWTSecret     string
+	// BlockedPrefixes are never proxied, even when a route matches them
+	BlockedPrefixes []string
+}
+
+// internalPrefixes are service-to-service endpoints that must not be
+// reachable through the gateway
+var internalPrefixes = []string{
+	"/api/v1/auth/users/",
 }
 
 // ReverseProxy manages routing to backend services
@@ -142,8 +151,25 @@ func (rp *ReverseProxy) initProxy(service ServiceConfig) {
 	rp.mu.Unlock()
 }
 
+// isBlocked reports whether a request path falls under a blocked prefix
+func (rp *ReverseProxy) isBlocked(requestPath string) bool {
+	cleaned := path.Clean(requestPath)
+	if strings.HasSuffix(requestPath, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	for _, prefix := range rp.config.BlockedPrefixes {
+		if strings.HasPrefix(cleaned, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // findRoute finds the matching route for a request
 func (rp *ReverseProxy) findRoute(path, method string) *RouteConfig {
+	if rp.isBlocked(path) {
+		return nil
+	}
 	for _, route := range rp.config.Routes {
 		if strings.HasPrefix(path, route.PathPrefix) {
 			// Check method if restricted
@@ -233,6 +259,14 @@ func (rp *ReverseProxy) Handler() gin.HandlerFunc {
 			c.Request.Header.Set("X-Tenant-ID", tenantID.(string))
 		}
 
+		// Identity headers for per-identity purchase limits; never pass on
+		// values sent by the client
+		c.Request.Header.Del("X-User-Phone")
+		if phone, exists := c.Get(pkgmiddleware.ContextKeyPhone); exists {
+			c.Request.Header.Set("X-User-Phone", phone.(string))
+		}
+		c.Request.Header.Set("X-Client-IP", c.ClientIP())
+
 		// Add request ID for tracing
 		if requestID := c.GetHeader("X-Request-ID"); requestID != "" {
 			c.Request.Header.Set("X-Request-ID", requestID)
@@ -314,7 +348,8 @@ func DefaultConfig() ProxyConfig {
 	bookingURL := getEnvOrDefault("BOOKING_SERVICE_URL", "http://localhost:8083")
 
 	return ProxyConfig{
-		DefaultTimeout: 30 * time.Second,
+		DefaultTimeout:  30 * time.Second,
+		BlockedPrefixes: internalPrefixes,
 		Routes: []RouteConfig{
 			// Auth Service routes (public)
 			{
@@ -392,8 +427,9 @@ func ConfigFromEnv(authURL, ticketURL, bookingURL, paymentURL, jwtSecret string)
 	}
 
 	return ProxyConfig{
-		DefaultTimeout: 30 * time.Second,
-		JWTSecret:      jwtSecret,
+		DefaultTimeout:  30 * time.Second,
+		JWTSecret:       jwtSecret,
+		BlockedPrefixes: internalPrefixes,
 		Routes: []RouteConfig{
 			// Auth Service routes
 			{
@@ -500,6 +536,18 @@ func ConfigFromEnv(authURL, ticketURL, bookingURL, paymentURL, jwtSecret string)
 				},
 				RequireAuth: true,
 			},
+			// Availability - public SSE streams of zone availability for event pages
+			{
+				PathPrefix:  "/api/v1/availability",
+				StripPrefix: "",
+				Service: ServiceConfig{
+					Name:    "booking-service",
+					BaseURL: bookingURL,
+					Timeout: 5 * time.Minute, // SSE streaming requires longer timeout
+				},
+				RequireAuth:    false,
+				AllowedMethods: []string{"GET"},
+			},
 			// Admin - booking service admin endpoints (protected)
 			{
 				PathPrefix:  "/api/v1/admin",
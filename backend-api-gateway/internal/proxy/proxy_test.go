@@ -145,6 +145,35 @@ func TestFindRoute(t *testing.T) {
 	}
 }
 
+func TestFindRoute_BlockedPrefixes(t *testing.T) {
+	rp := NewReverseProxy(DefaultConfig())
+
+	tests := []struct {
+		name        string
+		path        string
+		method      string
+		expectMatch bool
+	}{
+		{name: "auth login", path: "/api/v1/auth/login", method: "POST", expectMatch: true},
+		{name: "stripe customer", path: "/api/v1/auth/users/123/stripe-customer", method: "GET"},
+		{name: "users prefix", path: "/api/v1/auth/users/", method: "POST"},
+		{name: "double slash", path: "/api/v1/auth//users/123/stripe-customer", method: "GET"},
+		{name: "dot segments", path: "/api/v1/auth/me/../users/123/stripe-customer", method: "PUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := rp.findRoute(tt.path, tt.method)
+			if tt.expectMatch && route == nil {
+				t.Fatal("Expected route to be found")
+			}
+			if !tt.expectMatch && route != nil {
+				t.Errorf("Expected %s to be blocked, matched %s", tt.path, route.PathPrefix)
+			}
+		})
+	}
+}
+
 func TestReverseProxy_Handler_NotFound(t *testing.T) {
 	config := ProxyConfig{
 		Routes: []RouteConfig{
@@ -449,6 +478,58 @@ func TestReverseProxyUserContextHeaders(t *testing.T) {
 	}
 }
 
+// TestReverseProxyIdentityHeaders tests that identity headers come from the gateway only
+func TestReverseProxyIdentityHeaders(t *testing.T) {
+	var receivedHeaders http.Header
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	config := ProxyConfig{
+		Routes: []RouteConfig{
+			{
+				PathPrefix: "/api/v1/test",
+				Service: ServiceConfig{
+					Name:    "test-service",
+					BaseURL: backend.URL,
+				},
+			},
+		},
+	}
+	handler := NewReverseProxy(config).Handler()
+
+	for _, phone := range []string{"+66812345678", ""} {
+		w := httptest.NewRecorder()
+		c, engine := gin.CreateTestContext(w)
+		// As in main: no trusted proxies, so X-Forwarded-For is ignored
+		if err := engine.SetTrustedProxies(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest("GET", "/api/v1/test", nil)
+		req.RemoteAddr = "203.0.113.7:4321"
+		req.Header.Set("X-User-Phone", "+10000000000")
+		req.Header.Set("X-Client-IP", "198.51.100.1")
+		req.Header.Set("X-Forwarded-For", "198.51.100.2")
+		c.Request = req
+		if phone != "" {
+			c.Set("phone", phone)
+		}
+
+		handler(c)
+
+		if got := receivedHeaders.Get("X-User-Phone"); got != phone {
+			t.Errorf("Expected X-User-Phone header '%s', got '%s'", phone, got)
+		}
+		if got := receivedHeaders.Get("X-Client-IP"); got != "203.0.113.7" {
+			t.Errorf("Expected X-Client-IP header '203.0.113.7', got '%s'", got)
+		}
+	}
+}
+
 // TestReverseProxyStripPrefix tests path prefix stripping
 func TestReverseProxyStripPrefix(t *testing.T) {
 	var receivedPath string
@@ -73,6 +73,7 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      cfg.Redis.PoolSize,
 		MaxRetries:    3,
 		RetryInterval: 2 * time.Second,
@@ -94,6 +95,12 @@ func main() {
 
 	router := gin.New()
 
+	// Only believe X-Forwarded-For from the configured edge proxies; otherwise
+	// ClientIP is the connection address and clients cannot spoof their IP
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal(fmt.Sprintf("Invalid SERVER_TRUSTED_PROXIES: %v", err))
+	}
+
 	// Apply global middlewares
 	router.Use(gin.Recovery())
 
@@ -122,6 +129,13 @@ func main() {
 		log.Warn("Rate limiting DISABLED (RATE_LIMIT_ENABLED=false)")
 	}
 
+	// Proof-of-work challenge for queue joins; the booking service screens
+	// joins on the result the gateway forwards
+	joinChallengeConfig := middleware.DefaultJoinChallengeConfig(getEnv("QUEUE_CHALLENGE_SECRET", cfg.JWT.Secret))
+	joinChallengeConfig.ASNHeader = os.Getenv("CLIENT_ASN_HEADER")
+	joinChallengeConfig.RedisClient = redis
+	router.Use(middleware.JoinChallengeMiddleware(joinChallengeConfig))
+
 	// Health check handlers (no database - microservice pattern)
 	healthHandler := handler.NewHealthHandler(nil, redis)
 	router.GET("/health", healthHandler.Health)
@@ -138,6 +152,9 @@ func main() {
 				"service": "api-gateway",
 			})
 		})
+
+		// Join challenges are issued by the gateway itself
+		v1.GET("/queue/challenge", middleware.NewJoinChallenger(joinChallengeConfig).ChallengeHandler())
 	}
 
 	// Configure reverse proxy for backend services
@@ -23,6 +23,8 @@ type User struct {
 	Role             Role      `json:"role"`
 	TenantID         string    `json:"tenant_id"`          // For multi-tenant support
 	StripeCustomerID string    `json:"stripe_customer_id"` // Stripe Customer ID for payment portal
+	Phone            string    `json:"phone,omitempty"`
+	PhoneVerified    bool      `json:"phone_verified"` // Only a verified phone goes into access tokens
 	IsActive         bool      `json:"is_active"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
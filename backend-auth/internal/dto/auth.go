@@ -115,3 +115,14 @@ func (r *UpdateProfileRequest) Validate() (bool, string) {
 	}
 	return true, ""
 }
+
+// LookupUsersRequest represents a request to resolve emails to user IDs
+type LookupUsersRequest struct {
+	Emails []string `json:"emails" binding:"required,min=1,max=500,dive,email"`
+}
+
+// UserLookupResponse pairs an email with the ID of its user
+type UserLookupResponse struct {
+	Email  string `json:"email"`
+	UserID string `json:"user_id"`
+}
@@ -425,3 +425,39 @@ func (h *AuthHandler) UpdateStripeCustomerID(c *gin.Context) {
 		"stripe_customer_id": req.StripeCustomerID,
 	}))
 }
+
+// LookupUsers resolves emails to user IDs for other services
+// POST /internal/users/lookup
+func (h *AuthHandler) LookupUsers(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.auth.lookup_users")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	var req dto.LookupUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request body")
+		c.JSON(http.StatusBadRequest, response.BadRequest(err.Error()))
+		return
+	}
+
+	userIDs, err := h.authService.LookupUserIDs(ctx, req.Emails)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, response.InternalError(err.Error()))
+		return
+	}
+
+	users := make([]dto.UserLookupResponse, 0, len(userIDs))
+	for _, email := range req.Emails {
+		if userID, ok := userIDs[email]; ok {
+			users = append(users, dto.UserLookupResponse{Email: email, UserID: userID})
+			delete(userIDs, email)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("found", len(users)))
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, response.Success(users))
+}
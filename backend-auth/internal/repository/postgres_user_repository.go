@@ -49,7 +49,7 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User)
 // GetByID retrieves a user by ID
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `
-		SELECT id, email, password_hash, COALESCE(first_name, '') as first_name, role, COALESCE(tenant_id::text, '') as tenant_id, COALESCE(stripe_customer_id, '') as stripe_customer_id, is_active, created_at, updated_at
+		SELECT id, email, password_hash, COALESCE(first_name, '') as first_name, role, COALESCE(tenant_id::text, '') as tenant_id, COALESCE(stripe_customer_id, '') as stripe_customer_id, COALESCE(phone, '') as phone, COALESCE(phone_verified, false) as phone_verified, is_active, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -62,6 +62,8 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*domai
 		&user.Role,
 		&user.TenantID,
 		&user.StripeCustomerID,
+		&user.Phone,
+		&user.PhoneVerified,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -78,7 +80,7 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*domai
 // GetByEmail retrieves a user by email
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, password_hash, COALESCE(first_name, '') as first_name, role, COALESCE(tenant_id::text, '') as tenant_id, COALESCE(stripe_customer_id, '') as stripe_customer_id, is_active, created_at, updated_at
+		SELECT id, email, password_hash, COALESCE(first_name, '') as first_name, role, COALESCE(tenant_id::text, '') as tenant_id, COALESCE(stripe_customer_id, '') as stripe_customer_id, COALESCE(phone, '') as phone, COALESCE(phone_verified, false) as phone_verified, is_active, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -91,6 +93,8 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 		&user.Role,
 		&user.TenantID,
 		&user.StripeCustomerID,
+		&user.Phone,
+		&user.PhoneVerified,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
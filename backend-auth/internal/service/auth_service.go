@@ -58,6 +58,8 @@ type AuthService interface {
 	GetStripeCustomerID(ctx context.Context, userID string) (string, error)
 	// UpdateStripeCustomerID updates the Stripe Customer ID for a user
 	UpdateStripeCustomerID(ctx context.Context, userID, stripeCustomerID string) error
+	// LookupUserIDs maps emails to the IDs of active users; unknown emails are omitted
+	LookupUserIDs(ctx context.Context, emails []string) (map[string]string, error)
 }
 
 // authService implements AuthService
@@ -446,7 +448,7 @@ func (s *authService) UpdateProfile(ctx context.Context, userID string, req *dto
 // generateTokenPair generates access and refresh tokens
 func (s *authService) generateTokenPair(user *domain.User) (*domain.TokenPair, error) {
 	// Generate access token
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"sub":       user.ID, // Standard JWT subject claim
 		"user_id":   user.ID,
 		"email":     user.Email,
@@ -454,7 +456,13 @@ func (s *authService) generateTokenPair(user *domain.User) (*domain.TokenPair, e
 		"tenant_id": user.TenantID,
 		"exp":       time.Now().Add(s.config.AccessTokenExpiry).Unix(),
 		"iat":       time.Now().Unix(),
-	})
+	}
+	// Verified phone (OIDC claims); the gateway forwards it for per-identity purchase limits
+	if user.PhoneVerified && user.Phone != "" {
+		claims["phone_number"] = user.Phone
+		claims["phone_number_verified"] = true
+	}
+	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	accessTokenString, err := accessToken.SignedString([]byte(s.config.JWTSecret))
 	if err != nil {
@@ -524,3 +532,33 @@ func (s *authService) UpdateStripeCustomerID(ctx context.Context, userID, stripe
 	span.SetStatus(codes.Ok, "")
 	return nil
 }
+
+// LookupUserIDs maps emails to the IDs of active users. Emails without an
+// active user are left out of the result.
+func (s *authService) LookupUserIDs(ctx context.Context, emails []string) (map[string]string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.auth.lookup_user_ids")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("emails", len(emails)))
+
+	userIDs := make(map[string]string, len(emails))
+	for _, email := range emails {
+		if _, seen := userIDs[email]; seen {
+			continue
+		}
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if user == nil || !user.IsActive {
+			continue
+		}
+		userIDs[email] = user.ID
+	}
+
+	span.SetAttributes(attribute.Int("found", len(userIDs)))
+	span.SetStatus(codes.Ok, "")
+	return userIDs, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-auth/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-auth/internal/dto"
 	"golang.org/x/crypto/bcrypt"
@@ -815,6 +816,52 @@ func TestJWTClaimsContainTenantID(t *testing.T) {
 	}
 }
 
+func TestJWTClaimsContainVerifiedPhone(t *testing.T) {
+	tests := []struct {
+		name      string
+		verified  bool
+		wantPhone string
+	}{
+		{name: "verified phone", verified: true, wantPhone: "+66812345678"},
+		{name: "unverified phone", verified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAuthService(newMockUserRepository(), newMockSessionRepository(), &AuthServiceConfig{
+				JWTSecret:          "test-secret-key",
+				AccessTokenExpiry:  15 * time.Minute,
+				RefreshTokenExpiry: 7 * 24 * time.Hour,
+				BcryptCost:         10,
+			}).(*authService)
+
+			pair, err := svc.generateTokenPair(&domain.User{
+				ID:            "phone-user-id",
+				Email:         "phone@example.com",
+				Role:          domain.RoleCustomer,
+				Phone:         "+66812345678",
+				PhoneVerified: tt.verified,
+			})
+			if err != nil {
+				t.Fatalf("generateTokenPair() error = %v", err)
+			}
+
+			claims := jwt.MapClaims{}
+			if _, err := jwt.ParseWithClaims(pair.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+				return []byte("test-secret-key"), nil
+			}); err != nil {
+				t.Fatalf("ParseWithClaims() error = %v", err)
+			}
+
+			phone, _ := claims["phone_number"].(string)
+			verified, _ := claims["phone_number_verified"].(bool)
+			if phone != tt.wantPhone || verified != (tt.wantPhone != "") {
+				t.Errorf("phone claims = %q, %v, want %q", phone, verified, tt.wantPhone)
+			}
+		})
+	}
+}
+
 func TestTokenExpiry(t *testing.T) {
 	userRepo := newMockUserRepository()
 	sessionRepo := newMockSessionRepository()
@@ -1042,3 +1089,33 @@ func TestAuthService_UpdateProfile(t *testing.T) {
 		}
 	})
 }
+
+func TestAuthService_LookupUserIDs(t *testing.T) {
+	userRepo := newMockUserRepository()
+	sessionRepo := newMockSessionRepository()
+	config := &AuthServiceConfig{
+		JWTSecret:          "test-secret-key",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 7 * 24 * time.Hour,
+		BcryptCost:         10,
+	}
+	svc := NewAuthService(userRepo, sessionRepo, config)
+
+	for _, user := range []*domain.User{
+		{ID: "active-id", Email: "active@example.com", IsActive: true},
+		{ID: "inactive-id", Email: "inactive@example.com", IsActive: false},
+	} {
+		userRepo.users[user.ID] = user
+		userRepo.emailIndex[user.Email] = user
+	}
+
+	userIDs, err := svc.LookupUserIDs(context.Background(), []string{
+		"active@example.com", "inactive@example.com", "unknown@example.com", "active@example.com",
+	})
+	if err != nil {
+		t.Fatalf("LookupUserIDs() error = %v", err)
+	}
+	if len(userIDs) != 1 || userIDs["active@example.com"] != "active-id" {
+		t.Errorf("LookupUserIDs() = %v, want only active@example.com -> active-id", userIDs)
+	}
+}
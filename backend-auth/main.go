@@ -162,6 +162,7 @@ func main() {
 
 			// Internal endpoints for service-to-service communication
 			// These endpoints are used by payment-service to manage Stripe Customer IDs
+			// (the gateway refuses to proxy /api/v1/auth/users/*)
 			internal := auth.Group("/users")
 			{
 				internal.GET("/:id/stripe-customer", container.AuthHandler.GetStripeCustomerID)
@@ -183,6 +184,13 @@ func main() {
 		}
 	}
 
+	// Service-to-service routes outside /api/v1, so the gateway never proxies them
+	// User lookup is used by booking-service to resolve comp ticket recipients
+	internalAPI := router.Group("/internal")
+	{
+		internalAPI.POST("/users/lookup", container.AuthHandler.LookupUsers)
+	}
+
 	// Create HTTP server
 	port := cfg.Server.Port
 	if port == 0 {
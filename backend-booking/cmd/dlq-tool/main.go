@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/retry"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+const usage = `dlq-tool - browse, replay and purge dead letters
+
+Saga dead letter table (saga_dead_letters):
+  dlq-tool list           [-topic T] [-error-code C] [-saga-id S] [-from RFC3339] [-to RFC3339] [-all] [-limit N] [-offset N]
+  dlq-tool show           -id ID
+  dlq-tool replay         (-ids ID,ID | -topic T [-error-code C] [-from ..] [-to ..] [-limit N]) [-target-topic T] [-payload-file F] [-rate N] [-keep] [-dry-run]
+  dlq-tool mark-processed -ids ID,ID
+  dlq-tool purge          (-ids ID,ID | -topic T | -error-code C | -from .. | -to ..) [-all] -yes
+
+Kafka DLQ topics (pkg/retry.DLQMessage):
+  dlq-tool kafka-list     -dlq-topic T [-topic T] [-error-code C] [-from ..] [-to ..] [-idle 5s] [-limit N]
+  dlq-tool kafka-replay   -dlq-topic T [-topic T] [-error-code C] [-from ..] [-to ..] [-target-topic T] [-rate N] [-limit N] [-dry-run]
+
+Connection settings are read from the same environment as the booking service.
+`
+
+// options holds all command line flags; each subcommand uses the subset it needs
+type options struct {
+	ids         string
+	id          string
+	topic       string
+	errorCode   string
+	sagaID      string
+	from        string
+	to          string
+	all         bool
+	limit       int
+	offset      int
+	targetTopic string
+	payloadFile string
+	rate        float64
+	keep        bool
+	dryRun      bool
+	yes         bool
+	dlqTopic    string
+	idle        time.Duration
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+
+	opts := &options{}
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	fs.StringVar(&opts.ids, "ids", "", "comma separated dead letter IDs")
+	fs.StringVar(&opts.id, "id", "", "dead letter ID")
+	fs.StringVar(&opts.topic, "topic", "", "original topic filter")
+	fs.StringVar(&opts.errorCode, "error-code", "", "error code filter")
+	fs.StringVar(&opts.sagaID, "saga-id", "", "saga ID filter")
+	fs.StringVar(&opts.from, "from", "", "only entries at or after this time (RFC3339)")
+	fs.StringVar(&opts.to, "to", "", "only entries before this time (RFC3339)")
+	fs.BoolVar(&opts.all, "all", false, "include processed dead letters")
+	fs.IntVar(&opts.limit, "limit", 50, "maximum number of entries")
+	fs.IntVar(&opts.offset, "offset", 0, "entries to skip")
+	fs.StringVar(&opts.targetTopic, "target-topic", "", "replay to this topic instead of the original")
+	fs.StringVar(&opts.payloadFile, "payload-file", "", "replace the payload with this JSON file (single ID only)")
+	fs.Float64Var(&opts.rate, "rate", 10, "replay rate limit in messages per second")
+	fs.BoolVar(&opts.keep, "keep", false, "do not mark replayed dead letters as processed")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print what would be replayed without publishing")
+	fs.BoolVar(&opts.yes, "yes", false, "confirm a destructive operation")
+	fs.StringVar(&opts.dlqTopic, "dlq-topic", "", "Kafka DLQ topic to read (e.g. booking-events.dlq)")
+	fs.DurationVar(&opts.idle, "idle", 5*time.Second, "stop reading a Kafka DLQ topic after this long without records")
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := logger.Init(&logger.Config{Level: "error", ServiceName: "dlq-tool"}); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	ctx := context.Background()
+
+	switch cmd {
+	case "list", "show", "replay", "mark-processed", "purge":
+		err = runTableCommand(ctx, cfg, cmd, opts)
+	case "kafka-list", "kafka-replay":
+		err = runKafkaCommand(ctx, cfg, cmd, opts)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("%s: %v", cmd, err)
+	}
+}
+
+// runTableCommand handles subcommands backed by the saga_dead_letters table
+func runTableCommand(ctx context.Context, cfg *config.Config, cmd string, opts *options) error {
+	db, err := database.NewPostgres(ctx, &database.PostgresConfig{
+		Host:          cfg.BookingDatabase.Host,
+		Port:          cfg.BookingDatabase.Port,
+		User:          cfg.BookingDatabase.User,
+		Password:      cfg.BookingDatabase.Password,
+		Database:      cfg.BookingDatabase.DBName,
+		SSLMode:       cfg.BookingDatabase.SSLMode,
+		MaxConns:      2,
+		MinConns:      1,
+		MaxRetries:    1,
+		RetryInterval: time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+	defer db.Close()
+
+	store := pkgsaga.NewPostgresStore(db.Pool())
+
+	filter, err := opts.deadLetterFilter()
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "list":
+		items, err := store.ListDeadLetters(ctx, filter)
+		if err != nil {
+			return err
+		}
+		total, err := store.CountDeadLetters(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, dl := range items {
+			fmt.Printf("%s  %s  %-40s  code=%-16s retries=%d processed=%v  %s\n",
+				dl.ID, dl.CreatedAt.Format(time.RFC3339), dl.Topic, dl.ErrorCode, dl.RetryCount, dl.Processed, truncate(dl.ErrorMessage, 80))
+		}
+		fmt.Printf("\n%d shown, %d matching\n", len(items), total)
+		return nil
+
+	case "show":
+		if opts.id == "" {
+			return errors.New("-id is required")
+		}
+		dl, err := store.GetDeadLetter(ctx, opts.id)
+		if err != nil {
+			return err
+		}
+		return printJSON(dl)
+
+	case "replay":
+		req := &service.DLQReplayRequest{
+			IDs:             splitIDs(opts.ids),
+			Topic:           opts.targetTopic,
+			KeepUnprocessed: opts.keep,
+		}
+		if len(req.IDs) == 0 {
+			if filter.Topic == "" && filter.ErrorCode == "" && filter.From.IsZero() && filter.To.IsZero() {
+				return errors.New("replay needs -ids or at least one filter")
+			}
+			req.Filter = filter
+		}
+		if opts.payloadFile != "" {
+			payload, err := os.ReadFile(opts.payloadFile)
+			if err != nil {
+				return fmt.Errorf("failed to read payload file: %w", err)
+			}
+			if !json.Valid(payload) {
+				return errors.New("payload file is not valid JSON")
+			}
+			req.Payload = payload
+		}
+
+		if opts.dryRun {
+			selection := req.Filter
+			if len(req.IDs) > 0 {
+				selection = &pkgsaga.DeadLetterFilter{IDs: req.IDs, IncludeProcessed: true}
+			}
+			items, err := store.ListDeadLetters(ctx, selection)
+			if err != nil {
+				return err
+			}
+			for _, dl := range items {
+				target := dl.Topic
+				if opts.targetTopic != "" {
+					target = opts.targetTopic
+				}
+				fmt.Printf("would replay %s -> %s (key=%s)\n", dl.ID, target, dl.MessageKey)
+			}
+			fmt.Printf("\n%d dead letters selected (dry run)\n", len(items))
+			return nil
+		}
+
+		producer, err := newProducer(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer producer.Close()
+
+		svc := service.NewDLQService(store, &retry.KafkaProducerAdapter{Producer: producer}, &service.DLQServiceConfig{
+			MaxReplayBatch: opts.limit,
+			Replay:         &retry.ReplayConfig{RatePerSecond: opts.rate, Source: "dlq-tool"},
+		})
+		resp, err := svc.Replay(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printJSON(resp)
+
+	case "mark-processed":
+		ids := splitIDs(opts.ids)
+		if len(ids) == 0 {
+			return errors.New("-ids is required")
+		}
+		marked, err := store.MarkDeadLettersProcessed(ctx, ids)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d dead letters marked processed\n", marked)
+		return nil
+
+	case "purge":
+		// -all widens a selection rather than making one
+		if len(filter.IDs) == 0 && filter.Topic == "" && filter.ErrorCode == "" && filter.SagaID == "" &&
+			filter.From.IsZero() && filter.To.IsZero() {
+			return errors.New("purge needs -ids or at least one filter")
+		}
+		count, err := store.CountDeadLetters(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if !opts.yes {
+			fmt.Printf("%d dead letters match; re-run with -yes to delete them\n", count)
+			return nil
+		}
+		purged, err := store.PurgeDeadLetters(ctx, filter)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d dead letters purged\n", purged)
+		return nil
+	}
+
+	return nil
+}
+
+// runKafkaCommand handles subcommands that read a Kafka DLQ topic
+func runKafkaCommand(ctx context.Context, cfg *config.Config, cmd string, opts *options) error {
+	if opts.dlqTopic == "" {
+		return errors.New("-dlq-topic is required")
+	}
+
+	filter := &retry.DLQFilter{
+		OriginalTopic: opts.topic,
+		ErrorCode:     opts.errorCode,
+	}
+	var err error
+	if filter.From, err = parseTime(opts.from); err != nil {
+		return err
+	}
+	if filter.To, err = parseTime(opts.to); err != nil {
+		return err
+	}
+
+	msgs, err := readDLQTopic(ctx, cfg, opts.dlqTopic, filter, opts.limit, opts.idle)
+	if err != nil {
+		return err
+	}
+
+	if cmd == "kafka-list" || opts.dryRun {
+		for _, msg := range msgs {
+			target := msg.OriginalTopic
+			if opts.targetTopic != "" {
+				target = opts.targetTopic
+			}
+			fmt.Printf("%s  %s  %s -> %s  code=%-16s attempts=%d  %s\n",
+				msg.ID, msg.MovedToDLQAt.Format(time.RFC3339), opts.dlqTopic, target, msg.ErrorCode, msg.Attempts, truncate(msg.Error, 80))
+		}
+		fmt.Printf("\n%d matching messages\n", len(msgs))
+		return nil
+	}
+
+	producer, err := newProducer(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	replayer := retry.NewDLQReplayer(&retry.KafkaProducerAdapter{Producer: producer}, &retry.ReplayConfig{
+		RatePerSecond: opts.rate,
+		Source:        "dlq-tool",
+	})
+	return printJSON(replayer.ReplayAll(ctx, msgs, &retry.ReplayOptions{Topic: opts.targetTopic}))
+}
+
+// readDLQTopic reads a DLQ topic from the beginning with a throwaway consumer group
+// and returns the messages matching the filter. Offsets are never committed.
+func readDLQTopic(ctx context.Context, cfg *config.Config, topic string, filter *retry.DLQFilter, limit int, idle time.Duration) ([]*retry.DLQMessage, error) {
+	consumer, err := kafka.NewConsumer(ctx, &kafka.ConsumerConfig{
+		Brokers:  cfg.Kafka.Brokers,
+		GroupID:  fmt.Sprintf("dlq-tool-%d", time.Now().UnixNano()),
+		Topics:   []string{topic},
+		ClientID: "dlq-tool",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka consumer init failed: %w", err)
+	}
+	defer consumer.Close()
+
+	var msgs []*retry.DLQMessage
+	for limit <= 0 || len(msgs) < limit {
+		pollCtx, cancel := context.WithTimeout(ctx, idle)
+		records, err := consumer.Poll(pollCtx)
+		timedOut := pollCtx.Err() != nil
+		cancel()
+		if timedOut {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			msg, err := retry.DecodeDLQMessage(record.Value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "skipping %s/%d@%d: %v\n", record.Topic, record.Partition, record.Offset, err)
+				continue
+			}
+			if filter.Matches(msg) {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+
+	if limit > 0 && len(msgs) > limit {
+		msgs = msgs[:limit]
+	}
+	return msgs, nil
+}
+
+// newProducer creates a Kafka producer for replays
+func newProducer(ctx context.Context, cfg *config.Config) (*kafka.Producer, error) {
+	producer, err := kafka.NewProducer(ctx, &kafka.ProducerConfig{
+		Brokers:       cfg.Kafka.Brokers,
+		ClientID:      "dlq-tool",
+		MaxRetries:    3,
+		RetryInterval: time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka producer init failed: %w", err)
+	}
+	return producer, nil
+}
+
+// deadLetterFilter builds a table filter from the flags
+func (o *options) deadLetterFilter() (*pkgsaga.DeadLetterFilter, error) {
+	filter := &pkgsaga.DeadLetterFilter{
+		IDs:              splitIDs(o.ids),
+		Topic:            o.topic,
+		ErrorCode:        o.errorCode,
+		SagaID:           o.sagaID,
+		IncludeProcessed: o.all,
+		Limit:            o.limit,
+		Offset:           o.offset,
+	}
+
+	var err error
+	if filter.From, err = parseTime(o.from); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTime(o.to); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (want RFC3339): %w", value, err)
+	}
+	return t, nil
+}
+
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
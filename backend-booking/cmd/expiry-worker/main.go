@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Initialize logger
+	logCfg := &logger.Config{
+		Level:       cfg.App.Environment,
+		ServiceName: "expiry-worker",
+		Development: cfg.IsDevelopment(),
+	}
+	if err := logger.Init(logCfg); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	appLog := logger.Get()
+	appLog.Info("Starting Expiry Worker...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Initialize database connection (uses BookingDatabase - Microservice pattern)
+	dbCfg := &database.PostgresConfig{
+		Host:          cfg.BookingDatabase.Host,
+		Port:          cfg.BookingDatabase.Port,
+		User:          cfg.BookingDatabase.User,
+		Password:      cfg.BookingDatabase.Password,
+		Database:      cfg.BookingDatabase.DBName,
+		SSLMode:       cfg.BookingDatabase.SSLMode,
+		MaxConns:      10,
+		MinConns:      2,
+		MaxRetries:    3,
+		RetryInterval: 2 * time.Second,
+	}
+	db, err := database.NewPostgres(ctx, dbCfg)
+	if err != nil {
+		appLog.Fatal(fmt.Sprintf("Failed to connect to database: %v", err))
+	}
+	defer db.Close()
+	appLog.Info("Database connected")
+
+	// Initialize Redis connection
+	redisCfg := &pkgredis.Config{
+		Host:          cfg.Redis.Host,
+		Port:          cfg.Redis.Port,
+		Password:      cfg.Redis.Password,
+		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
+		PoolSize:      50,
+		MinIdleConns:  10,
+		MaxRetries:    3,
+		RetryInterval: 2 * time.Second,
+	}
+	redis, err := pkgredis.NewClient(ctx, redisCfg)
+	if err != nil {
+		appLog.Fatal(fmt.Sprintf("Failed to connect to Redis: %v", err))
+	}
+	defer redis.Close()
+	appLog.Info("Redis connected")
+
+	// Initialize repositories
+	bookingRepo := repository.NewPostgresBookingRepository(db.Pool())
+	transactionalRepo := repository.NewTransactionalBookingRepository(db.Pool())
+	reservationRepo := repository.NewRedisReservationRepository(redis).WithZoneShards(cfg.Booking.ZoneInventoryShards)
+
+	// Pre-load Lua scripts into Redis
+	if err := reservationRepo.LoadScripts(ctx); err != nil {
+		appLog.Warn(fmt.Sprintf("Failed to pre-load Lua scripts: %v", err))
+	} else {
+		appLog.Info("Lua scripts pre-loaded into Redis")
+	}
+
+	// Create and start worker; replicas share the expiry stream consumer group
+	expiryWorker := worker.NewExpiryWorker(bookingRepo, transactionalRepo, reservationRepo, nil)
+
+	// Scan the database on one elected replica only
+	electorDone := make(chan struct{})
+	if cfg.Booking.WorkerLeasesEnabled {
+		elector := lease.NewElector(lease.NewManager(redis), &lease.ElectorConfig{Name: "expiry-scan", TTL: cfg.Booking.WorkerLeaseTTL})
+		expiryWorker.WithLeadership(elector)
+		go func() {
+			defer close(electorDone)
+			elector.Run(ctx, func(ctx context.Context, l *lease.Lease) {
+				appLog.Info(fmt.Sprintf("Leading the expiry scan as %s (fencing token %d)", l.Holder, l.Token))
+				<-ctx.Done()
+				appLog.Info("Stepped down from leading the expiry scan")
+			})
+		}()
+	} else {
+		close(electorDone)
+	}
+
+	if err := expiryWorker.Start(ctx); err != nil {
+		appLog.Fatal(fmt.Sprintf("Failed to start expiry worker: %v", err))
+	}
+
+	appLog.Info("Expiry Worker started successfully")
+
+	// Wait for interrupt signal for graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	appLog.Info("Shutting down worker...")
+	expiryWorker.Stop()
+	cancel()
+	<-electorDone // Hand the scan over at once
+
+	appLog.Info("Worker exited gracefully")
+}
@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
@@ -67,6 +68,7 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      cfg.Redis.PoolSize,
 		MaxRetries:    3,
 		RetryInterval: 2 * time.Second,
@@ -78,8 +80,8 @@ func main() {
 	defer redis.Close()
 	appLog.Info("Redis connected")
 
-	// Initialize Kafka consumer
-	consumerCfg := &kafka.ConsumerConfig{
+	// Initialize Kafka runner
+	runnerCfg := &kafka.RunnerConfig{
 		Brokers:        cfg.Kafka.Brokers,
 		GroupID:        "inventory-sync-worker",
 		Topics:         []string{"booking-events"},
@@ -87,13 +89,14 @@ func main() {
 		MaxRetries:     3,
 		RetryInterval:  2 * time.Second,
 		SessionTimeout: 30 * time.Second,
+		DrainTimeout:   20 * time.Second,
 	}
-	consumer, err := kafka.NewConsumer(ctx, consumerCfg)
+	runner, err := kafka.NewRunner(ctx, runnerCfg)
 	if err != nil {
-		appLog.Fatal(fmt.Sprintf("Failed to create Kafka consumer: %v", err))
+		appLog.Fatal(fmt.Sprintf("Failed to create Kafka runner: %v", err))
 	}
-	defer consumer.Close()
-	appLog.Info("Kafka consumer connected")
+	defer runner.Close()
+	appLog.Info("Kafka consumer runner connected")
 
 	// Create worker configuration
 	workerCfg := &worker.InventoryWorkerConfig{
@@ -103,7 +106,8 @@ func main() {
 	}
 
 	// Create and start inventory worker
-	inventoryWorker := worker.NewInventoryWorker(workerCfg, consumer, db, redis, appLog)
+	reservationRepo := repository.NewRedisReservationRepository(redis).WithZoneShards(cfg.Booking.ZoneInventoryShards)
+	inventoryWorker := worker.NewInventoryWorker(workerCfg, runner, db, reservationRepo, appLog)
 
 	// Rebuild Redis from DB on startup if enabled
 	if workerCfg.RebuildOnStartup {
@@ -117,7 +121,11 @@ func main() {
 	}
 
 	// Start worker
-	go inventoryWorker.Start(ctx)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		inventoryWorker.Start(ctx)
+	}()
 	appLog.Info("Inventory worker started")
 
 	// Wait for shutdown signal
@@ -128,7 +136,7 @@ func main() {
 	appLog.Info("Shutting down inventory worker...")
 	cancel()
 
-	// Give worker time to finish
-	time.Sleep(2 * time.Second)
+	// Wait for in-flight records to drain and the last batch to be flushed
+	<-workerDone
 	appLog.Info("Inventory worker stopped")
 }
@@ -9,11 +9,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 )
 
 func main() {
@@ -40,12 +45,33 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Initialize OpenTelemetry (exports the adaptive release rate)
+	if cfg.OTel.Enabled {
+		_, err := telemetry.Init(ctx, &telemetry.Config{
+			Enabled:       true,
+			ServiceName:   "queue-release-worker",
+			CollectorAddr: cfg.OTel.CollectorAddr,
+			SampleRatio:   cfg.OTel.SampleRatio,
+			Environment:   cfg.App.Environment,
+		})
+		if err != nil {
+			appLog.Warn(fmt.Sprintf("Failed to initialize telemetry (continuing without metrics): %v", err))
+		} else {
+			defer telemetry.Shutdown(ctx)
+			if err := metrics.Init(); err != nil {
+				appLog.Warn(fmt.Sprintf("Failed to initialize metrics: %v", err))
+			}
+			appLog.Info("OpenTelemetry initialized")
+		}
+	}
+
 	// Initialize Redis connection
 	redisCfg := &pkgredis.Config{
 		Host:          cfg.Redis.Host,
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      cfg.Redis.PoolSize,
 		MaxRetries:    3,
 		RetryInterval: 2 * time.Second,
@@ -84,10 +110,78 @@ func main() {
 	// Create and start queue release worker (pass redis client for Pub/Sub publishing)
 	queueWorker := worker.NewQueueReleaseWorker(workerCfg, queueRepo, redis, appLog)
 
+	// Adapt each event's release rate to its booking health and stop
+	// releasing once its zones are sold out
+	if cfg.Booking.AdmissionHealthEnabled {
+		admissionCfg := &worker.AdmissionControlConfig{
+			Window:            getEnvDuration("QUEUE_HEALTH_WINDOW", 2*time.Minute),
+			TargetSuccessRate: getEnvFloat("QUEUE_TARGET_SUCCESS_RATE", 0.95),
+			TargetLatency:     getEnvDuration("QUEUE_TARGET_RESERVE_LATENCY", 500*time.Millisecond),
+			MaxPaymentBacklog: int64(getEnvInt("QUEUE_MAX_PAYMENT_BACKLOG", 0)),
+		}
+		zoneSeats := repository.NewRedisReservationRepository(redis).WithZoneShards(cfg.Booking.ZoneInventoryShards)
+		queueWorker.WithAdmissionControl(repository.NewRedisAdmissionHealthRepository(redis), zoneSeats, admissionCfg)
+		appLog.Info(fmt.Sprintf("Adaptive release: window=%v, target success rate=%.2f, target latency=%v",
+			admissionCfg.Window, admissionCfg.TargetSuccessRate, admissionCfg.TargetLatency))
+	}
+
+	// Let replicas share the queues: each event's queue is released by the
+	// replica holding its lease, and the scheduler ticks on an elected leader
+	var leader *lease.Elector
+	if cfg.Booking.WorkerLeasesEnabled {
+		leases := lease.NewManager(redis)
+		queueWorker.WithLeases(leases, "", cfg.Booking.WorkerLeaseTTL)
+		leader = lease.NewElector(leases, &lease.ElectorConfig{Name: "queue-scheduler", TTL: cfg.Booking.WorkerLeaseTTL})
+		go leader.Run(ctx, func(ctx context.Context, l *lease.Lease) {
+			appLog.Info(fmt.Sprintf("Leading the queue scheduler as %s (fencing token %d)", l.Holder, l.Token))
+			<-ctx.Done()
+			appLog.Info("Stepped down from leading the queue scheduler")
+		})
+		appLog.Info(fmt.Sprintf("Worker leases enabled: ttl=%v", cfg.Booking.WorkerLeaseTTL))
+	}
+
 	// Start worker in background
 	go queueWorker.Start(ctx)
 	appLog.Info("Queue release worker started")
 
+	// Open, randomize and close each event's queue on its sale window
+	if cfg.Booking.QueueScheduleEnabled {
+		// Windows are keyed by event ID, so per-key ordering applies each
+		// event's windows in order. A window that fails to apply is retried
+		// rather than committed, so no sale window is lost.
+		runner, err := kafka.NewRunner(ctx, &kafka.RunnerConfig{
+			Brokers:        cfg.Kafka.Brokers,
+			GroupID:        "queue-scheduler",
+			Topics:         []string{domain.EventScheduleTopic},
+			ClientID:       "queue-worker",
+			MaxRetries:     3,
+			RetryInterval:  2 * time.Second,
+			SessionTimeout: 30 * time.Second,
+			Ordering:       kafka.OrderByKey,
+			Concurrency:    2,
+			RetryBackoff:   2 * time.Second,
+			ErrorHandler: func(ctx context.Context, record *kafka.Record, err error) error {
+				appLog.Warn(fmt.Sprintf("Retrying event schedule at offset %d: %v", record.Offset, err))
+				return err
+			},
+		})
+		if err != nil {
+			appLog.Warn(fmt.Sprintf("Failed to create Kafka runner (queue scheduler disabled): %v", err))
+		} else {
+			defer runner.Close()
+			schedulerCfg := &worker.QueueSchedulerConfig{
+				WaitingRoomLead: cfg.Booking.QueueWaitingRoomLead,
+				TickInterval:    getEnvDuration("QUEUE_SCHEDULE_TICK_INTERVAL", 5*time.Second),
+			}
+			scheduler := worker.NewQueueScheduler(schedulerCfg, runner, queueRepo, queueRepo, redis, appLog)
+			if leader != nil {
+				scheduler.WithLeadership(leader)
+			}
+			go scheduler.Start(ctx)
+			appLog.Info(fmt.Sprintf("Queue scheduler started: waiting room lead=%v", schedulerCfg.WaitingRoomLead))
+		}
+	}
+
 	// Start metrics reporter in background
 	go reportMetrics(ctx, queueWorker, appLog)
 
@@ -142,6 +236,17 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+// getEnvFloat gets a float environment variable with a default
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		var f float64
+		if _, err := fmt.Sscanf(val, "%g", &f); err == nil {
+			return f
+		}
+	}
+	return defaultVal
+}
+
 // getEnvDuration gets a duration environment variable with a default
 func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 	if val := os.Getenv(key); val != "" {
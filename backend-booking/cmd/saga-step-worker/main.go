@@ -70,6 +70,7 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      100,
 		MinIdleConns:  20,
 		MaxRetries:    3,
@@ -84,7 +85,7 @@ func main() {
 
 	// Initialize repositories
 	bookingRepo := repository.NewPostgresBookingRepository(db.Pool())
-	reservationRepo := repository.NewRedisReservationRepository(redis)
+	reservationRepo := repository.NewRedisReservationRepository(redis).WithZoneShards(cfg.Booking.ZoneInventoryShards)
 
 	// Pre-load Lua scripts into Redis
 	if err := reservationRepo.LoadScripts(ctx); err != nil {
@@ -93,28 +94,6 @@ func main() {
 		appLog.Info("Lua scripts pre-loaded into Redis")
 	}
 
-	// Initialize Kafka consumer for booking step commands
-	consumerCfg := &kafka.ConsumerConfig{
-		Brokers: cfg.Kafka.Brokers,
-		GroupID: "saga-step-worker-booking",
-		Topics: []string{
-			saga.TopicSagaReserveSeatsCommand,
-			saga.TopicSagaReleaseSeatsCommand,
-			saga.TopicSagaConfirmBookingCommand,
-			saga.TopicSagaSendNotificationCommand, // NON-CRITICAL step
-		},
-		ClientID:       "saga-step-worker-booking",
-		MaxRetries:     3,
-		RetryInterval:  2 * time.Second,
-		SessionTimeout: 30 * time.Second,
-	}
-	consumer, err := kafka.NewConsumer(ctx, consumerCfg)
-	if err != nil {
-		appLog.Fatal(fmt.Sprintf("Failed to create Kafka consumer: %v", err))
-	}
-	defer consumer.Close()
-	appLog.Info("Kafka consumer connected")
-
 	// Initialize Kafka producer for saga events
 	producer, err := saga.NewKafkaSagaProducer(ctx, &saga.KafkaSagaProducerConfig{
 		Brokers:       cfg.Kafka.Brokers,
@@ -137,23 +116,59 @@ func main() {
 	dlqHandler := saga.NewDLQHandler(producer, sagaStore, &saga.ZapLogger{})
 	appLog.Info("DLQ handler initialized")
 
+	workerCfg := &worker.SagaStepWorkerConfig{
+		RetryAttempts: 3,
+		RetryDelay:    time.Second,
+	}
+
+	// Initialize Kafka runner for booking step commands.
+	// Commands are keyed by saga ID, so per-key ordering keeps each saga's steps
+	// in order while different sagas on the same partition run concurrently.
+	runnerCfg := &kafka.RunnerConfig{
+		Brokers: cfg.Kafka.Brokers,
+		GroupID: "saga-step-worker-booking",
+		Topics: []string{
+			saga.TopicSagaReserveSeatsCommand,
+			saga.TopicSagaReleaseSeatsCommand,
+			saga.TopicSagaConfirmBookingCommand,
+			saga.TopicSagaSendNotificationCommand, // NON-CRITICAL step
+		},
+		ClientID:       "saga-step-worker-booking",
+		MaxRetries:     3,
+		RetryInterval:  2 * time.Second,
+		SessionTimeout: 30 * time.Second,
+		Ordering:       kafka.OrderByKey,
+		Concurrency:    5,
+		DrainTimeout:   20 * time.Second,
+		// Steps retry on their own; a step that still fails goes to the DLQ
+		// before its offset is committed
+		ErrorHandler: dlqHandler.ErrorHandler(workerCfg.RetryAttempts),
+	}
+	runner, err := kafka.NewRunner(ctx, runnerCfg)
+	if err != nil {
+		appLog.Fatal(fmt.Sprintf("Failed to create Kafka runner: %v", err))
+	}
+	defer runner.Close()
+	appLog.Info("Kafka consumer runner connected")
+
 	// Create step worker
 	stepWorker := worker.NewSagaStepWorker(
-		consumer,
+		runner,
 		producer,
 		bookingRepo,
 		reservationRepo,
 		dlqHandler, // DLQ handler for non-critical step failures
-		&worker.SagaStepWorkerConfig{
-			WorkerCount:   5,
-			RetryAttempts: 3,
-			RetryDelay:    time.Second,
-		},
+		workerCfg,
 	)
+	if cfg.Booking.AdmissionHealthEnabled {
+		stepWorker.WithAdmissionHealth(repository.NewRedisAdmissionHealthRepository(redis))
+	}
 
 	// Start worker
+	workerDone := make(chan struct{})
 	go func() {
-		if err := stepWorker.Start(ctx); err != nil {
+		defer close(workerDone)
+		if err := stepWorker.Start(ctx); err != nil && err != context.Canceled {
 			appLog.Error(fmt.Sprintf("Worker error: %v", err))
 		}
 	}()
@@ -168,6 +183,7 @@ func main() {
 	appLog.Info("Shutting down worker...")
 	cancel()
 
-	time.Sleep(2 * time.Second)
+	// Wait for in-flight steps to drain and their offsets to be committed
+	<-workerDone
 	appLog.Info("Worker exited gracefully")
 }
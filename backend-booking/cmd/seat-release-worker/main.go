@@ -10,12 +10,14 @@ import (
 	"time"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 )
 
 func main() {
@@ -68,6 +70,7 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      100,
 		MinIdleConns:  20,
 		MaxRetries:    3,
@@ -80,8 +83,29 @@ func main() {
 	defer redis.Close()
 	appLog.Info("Redis connected")
 
-	// Initialize Kafka consumer
-	consumerCfg := &kafka.ConsumerConfig{
+	// Initialize repositories
+	bookingRepo := repository.NewPostgresBookingRepository(db.Pool())
+	reservationRepo := repository.NewRedisReservationRepository(redis).WithZoneShards(cfg.Booking.ZoneInventoryShards)
+
+	// Pre-load Lua scripts into Redis
+	if err := reservationRepo.LoadScripts(ctx); err != nil {
+		appLog.Warn(fmt.Sprintf("Failed to pre-load Lua scripts: %v", err))
+	} else {
+		appLog.Info("Lua scripts pre-loaded into Redis")
+	}
+
+	workerCfg := &worker.SeatReleaseWorkerConfig{
+		RetryAttempts: 3,
+		RetryDelay:    time.Second,
+	}
+
+	// Releases that still fail after their retries are kept in the saga
+	// DLQ table for manual investigation
+	dlqHandler := saga.NewDLQHandler(nil, pkgsaga.NewPostgresStore(db.Pool()), &saga.ZapLogger{})
+
+	// Initialize Kafka runner. Events are keyed by booking ID, so per-key
+	// ordering keeps each booking's events in order.
+	runnerCfg := &kafka.RunnerConfig{
 		Brokers:        cfg.Kafka.Brokers,
 		GroupID:        "seat-release-worker",
 		Topics:         []string{"payment.seat-release"},
@@ -89,40 +113,31 @@ func main() {
 		MaxRetries:     3,
 		RetryInterval:  2 * time.Second,
 		SessionTimeout: 30 * time.Second,
+		Ordering:       kafka.OrderByKey,
+		Concurrency:    5,
+		DrainTimeout:   20 * time.Second,
+		ErrorHandler:   dlqHandler.ErrorHandler(workerCfg.RetryAttempts),
 	}
-	consumer, err := kafka.NewConsumer(ctx, consumerCfg)
+	runner, err := kafka.NewRunner(ctx, runnerCfg)
 	if err != nil {
-		appLog.Fatal(fmt.Sprintf("Failed to create Kafka consumer: %v", err))
-	}
-	defer consumer.Close()
-	appLog.Info("Kafka consumer connected")
-
-	// Initialize repositories
-	bookingRepo := repository.NewPostgresBookingRepository(db.Pool())
-	reservationRepo := repository.NewRedisReservationRepository(redis)
-
-	// Pre-load Lua scripts into Redis
-	if err := reservationRepo.LoadScripts(ctx); err != nil {
-		appLog.Warn(fmt.Sprintf("Failed to pre-load Lua scripts: %v", err))
-	} else {
-		appLog.Info("Lua scripts pre-loaded into Redis")
+		appLog.Fatal(fmt.Sprintf("Failed to create Kafka runner: %v", err))
 	}
+	defer runner.Close()
+	appLog.Info("Kafka consumer runner connected")
 
 	// Create worker
 	seatReleaseWorker := worker.NewSeatReleaseWorker(
-		consumer,
+		runner,
 		bookingRepo,
 		reservationRepo,
-		&worker.SeatReleaseWorkerConfig{
-			WorkerCount:   5,
-			RetryAttempts: 3,
-			RetryDelay:    time.Second,
-		},
+		workerCfg,
 	)
 
 	// Start worker
+	workerDone := make(chan struct{})
 	go func() {
-		if err := seatReleaseWorker.Start(ctx); err != nil {
+		defer close(workerDone)
+		if err := seatReleaseWorker.Start(ctx); err != nil && err != context.Canceled {
 			appLog.Error(fmt.Sprintf("Worker error: %v", err))
 		}
 	}()
@@ -137,8 +152,7 @@ func main() {
 	appLog.Info("Shutting down worker...")
 	cancel()
 
-	// Give worker time to finish processing
-	time.Sleep(2 * time.Second)
-
+	// Wait for in-flight releases to drain and their offsets to be committed
+	<-workerDone
 	appLog.Info("Worker exited gracefully")
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/retry"
 	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 )
 
@@ -28,13 +29,31 @@ type Container struct {
 	BookingService service.BookingService
 	QueueService   service.QueueService
 	SagaService    service.SagaService
+	DLQService     service.DLQService
+	// InventoryReconciler is nil when TicketServiceURL or the inventory readers are not configured
+	InventoryReconciler service.InventoryReconciler
+	// ReservationFailover is nil when FallbackStore or TicketServiceURL is not configured
+	ReservationFailover service.ReservationFailover
+	// CompService is nil when CompStore, TicketServiceURL or AuthServiceURL is not configured
+	CompService service.CompService
+	// AvailabilityHub is nil when availability streams are disabled or TicketServiceURL is not configured
+	AvailabilityHub service.AvailabilityHub
+	// IdentityLimiter is nil when IdentityLimitStore or IdentityLimiterConfig is not configured
+	IdentityLimiter service.IdentityLimiter
 
 	// Handlers
-	HealthHandler  *handler.HealthHandler
-	BookingHandler *handler.BookingHandler
-	QueueHandler   *handler.QueueHandler
-	AdminHandler   *handler.AdminHandler
-	SagaHandler    *handler.SagaHandler
+	HealthHandler       *handler.HealthHandler
+	BookingHandler      *handler.BookingHandler
+	QueueHandler        *handler.QueueHandler
+	QueueSocketHandler  *handler.QueueSocketHandler // nil when queue WebSockets are disabled
+	QueueAdminHandler   *handler.QueueAdminHandler
+	AdminHandler        *handler.AdminHandler
+	SagaHandler         *handler.SagaHandler
+	DLQHandler          *handler.DLQAdminHandler      // nil when DLQ tooling is not configured
+	CompHandler         *handler.CompAdminHandler     // nil when comp issuance is not configured
+	AvailabilityHandler *handler.AvailabilityHandler  // nil when availability streams are disabled
+	IdentityHandler     *handler.IdentityLimitHandler // nil when identity limits are disabled
+	ScriptHandler       *handler.ScriptAdminHandler
 }
 
 // ContainerConfig contains configuration for building the container
@@ -48,10 +67,29 @@ type ContainerConfig struct {
 	ServiceConfig        *service.BookingServiceConfig
 	QueueServiceConfig   *service.QueueServiceConfig
 	TicketServiceURL     string // URL of ticket service for zone sync
+	AuthServiceURL       string // URL of auth service for resolving comp recipients
 	SagaProducer         saga.SagaProducer
 	SagaStore            pkgsaga.Store
 	SagaServiceConfig    *service.SagaServiceConfig
 	BookingHandlerConfig *handler.BookingHandlerConfig
+	DeadLetterStore      service.DeadLetterStore // Saga dead letter table for DLQ admin tooling
+	DLQPublisher         retry.KafkaPublisher    // Producer used to replay dead letters
+	DLQServiceConfig     *service.DLQServiceConfig
+	InventoryReader      repository.BookingInventoryReader // Booking rows for inventory reconciliation
+	InventoryStore       repository.ZoneInventoryStore     // Redis inventory for reconciliation
+	ReconcilerConfig     *service.InventoryReconcilerConfig
+	FallbackStore        repository.FallbackReservationStore // Postgres reservations while Redis is unavailable
+	FailoverConfig       *service.ReservationFailoverConfig
+	LuaScriptsDir        string                      // Directory Lua scripts are reloaded from (empty = reload disabled)
+	CompStore            repository.CompBookingStore // Writes comp bookings issued by organizers
+	CompServiceConfig    *service.CompServiceConfig
+	AvailabilityConfig   *service.AvailabilityHubConfig       // nil disables availability streams
+	QueueSocketConfig    *handler.QueueSocketConfig           // nil disables queue WebSockets
+	IdentityLimitStore   repository.IdentityLimitStore        // Redis tallies for per-identity purchase limits
+	IdentityConfig       *service.IdentityLimiterConfig       // nil disables identity limits
+	IdentityAuditor      service.IdentityAuditor              // Records identity limit hits (optional)
+	PaymentServiceURL    string                               // URL of payment service for the card of a payment
+	AdmissionHealth      repository.AdmissionHealthRepository // Booking health and queue throughput for release rate and wait estimates (optional)
 	// Note: Saga is now triggered asynchronously after payment success via webhook
 	// Booking handler always uses fast path (Redis Lua + PostgreSQL)
 }
@@ -71,7 +109,75 @@ func NewContainer(cfg *ContainerConfig) *Container {
 	var zoneSyncer service.ZoneSyncer
 	if cfg.TicketServiceURL != "" {
 		zoneFetcher := service.NewHTTPZoneFetcher(cfg.TicketServiceURL)
+
+		// Serve reservations from Postgres while Redis is unavailable; zones are
+		// activated from the ticket service's seat totals
+		if primary, ok := c.ReservationRepo.(service.FailoverPrimary); ok && cfg.FallbackStore != nil {
+			c.ReservationFailover = service.NewReservationFailover(primary, cfg.FallbackStore, zoneFetcher, cfg.FailoverConfig)
+			c.ReservationRepo = c.ReservationFailover
+		}
+
 		zoneSyncer = service.NewZoneSyncer(zoneFetcher, c.ReservationRepo)
+
+		// Inventory reconciliation uses the ticket service as the zone catalog
+		if cfg.InventoryReader != nil && cfg.InventoryStore != nil {
+			c.InventoryReconciler = service.NewInventoryReconciler(zoneFetcher, cfg.InventoryReader, cfg.InventoryStore, cfg.ReconcilerConfig)
+		}
+
+		// Comp tickets are issued from the ticket service's zone allocations to
+		// recipients resolved by the auth service
+		if cfg.CompStore != nil && cfg.AuthServiceURL != "" {
+			c.CompService = service.NewCompService(
+				cfg.CompStore,
+				zoneFetcher,
+				service.NewHTTPAllocationIssuer(cfg.TicketServiceURL),
+				service.NewHTTPUserResolver(cfg.AuthServiceURL),
+				c.EventPublisher,
+				cfg.CompServiceConfig,
+			)
+		}
+
+		// Availability streams list a show's zones from the ticket service
+		if cfg.AvailabilityConfig != nil {
+			c.AvailabilityHub = service.NewAvailabilityHub(c.Redis, zoneFetcher, c.ReservationRepo, cfg.AvailabilityConfig)
+		}
+	}
+
+	// Per-identity purchase limits; cards are only limited when the payment
+	// service can be asked for the card of a payment
+	serviceConfig := cfg.ServiceConfig
+	if cfg.IdentityLimitStore != nil && cfg.IdentityConfig != nil {
+		var payments service.PaymentCardFetcher
+		if cfg.PaymentServiceURL != "" {
+			payments = service.NewHTTPPaymentCardFetcher(cfg.PaymentServiceURL)
+		}
+		c.IdentityLimiter = service.NewIdentityLimiter(cfg.IdentityLimitStore, payments, cfg.IdentityAuditor, cfg.IdentityConfig)
+
+		limited := service.BookingServiceConfig{}
+		if serviceConfig != nil {
+			limited = *serviceConfig
+		}
+		limited.IdentityLimiter = c.IdentityLimiter
+		serviceConfig = &limited
+	}
+
+	// Reservation outcomes and payments drive the queue's release rate; its
+	// throughput drives wait estimates
+	queueServiceConfig := cfg.QueueServiceConfig
+	if cfg.AdmissionHealth != nil {
+		reported := service.BookingServiceConfig{}
+		if serviceConfig != nil {
+			reported = *serviceConfig
+		}
+		reported.AdmissionHealth = cfg.AdmissionHealth
+		serviceConfig = &reported
+
+		queueReported := service.QueueServiceConfig{}
+		if queueServiceConfig != nil {
+			queueReported = *queueServiceConfig
+		}
+		queueReported.AdmissionHealth = cfg.AdmissionHealth
+		queueServiceConfig = &queueReported
 	}
 
 	// Initialize services
@@ -80,12 +186,12 @@ func NewContainer(cfg *ContainerConfig) *Container {
 		c.ReservationRepo,
 		c.EventPublisher,
 		zoneSyncer,
-		cfg.ServiceConfig,
+		serviceConfig,
 	)
 
 	c.QueueService = service.NewQueueService(
 		c.QueueRepo,
-		cfg.QueueServiceConfig,
+		queueServiceConfig,
 	)
 
 	// Initialize saga service (optional - depends on Kafka availability)
@@ -95,6 +201,11 @@ func NewContainer(cfg *ContainerConfig) *Container {
 		c.SagaService = service.NewNoOpSagaService()
 	}
 
+	// Initialize DLQ tooling (optional - needs both the dead letter table and a producer)
+	if cfg.DeadLetterStore != nil && cfg.DLQPublisher != nil {
+		c.DLQService = service.NewDLQService(cfg.DeadLetterStore, cfg.DLQPublisher, cfg.DLQServiceConfig)
+	}
+
 	// Initialize handlers
 	c.HealthHandler = handler.NewHealthHandler(c.DB, c.Redis)
 
@@ -103,8 +214,25 @@ func NewContainer(cfg *ContainerConfig) *Container {
 	c.BookingHandler = handler.NewBookingHandler(c.BookingService, c.QueueService, cfg.BookingHandlerConfig)
 
 	c.QueueHandler = handler.NewQueueHandler(c.QueueService, c.Redis)
-	c.AdminHandler = handler.NewAdminHandler(c.Redis)
+	if cfg.QueueSocketConfig != nil {
+		c.QueueSocketHandler = handler.NewQueueSocketHandler(c.QueueService, c.Redis, cfg.QueueSocketConfig)
+	}
+	c.QueueAdminHandler = handler.NewQueueAdminHandler(c.QueueService, c.Redis)
+	c.AdminHandler = handler.NewAdminHandler(c.ReservationRepo, c.InventoryReconciler)
 	c.SagaHandler = handler.NewSagaHandler(c.SagaService)
+	if c.DLQService != nil {
+		c.DLQHandler = handler.NewDLQAdminHandler(c.DLQService)
+	}
+	if c.CompService != nil {
+		c.CompHandler = handler.NewCompAdminHandler(c.CompService)
+	}
+	if c.AvailabilityHub != nil {
+		c.AvailabilityHandler = handler.NewAvailabilityHandler(c.AvailabilityHub)
+	}
+	if c.IdentityLimiter != nil {
+		c.IdentityHandler = handler.NewIdentityLimitHandler(c.IdentityLimiter)
+	}
+	c.ScriptHandler = handler.NewScriptAdminHandler(c.Redis.Scripts(), cfg.LuaScriptsDir)
 
 	return c
 }
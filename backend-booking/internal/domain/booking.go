@@ -15,6 +15,10 @@ const (
 	BookingStatusExpired   BookingStatus = "expired"
 )
 
+// BookingReasonComp is the status reason of complimentary bookings, which are
+// issued confirmed from an organizer's allocation without a reservation or payment
+const BookingReasonComp = "comp"
+
 // IsValid checks if the status is a valid BookingStatus
 func (s BookingStatus) IsValid() bool {
 	switch s {
@@ -171,6 +175,11 @@ func (b *Booking) IsCancelled() bool {
 	return b.Status == BookingStatusCancelled
 }
 
+// IsComp checks if the booking is a complimentary ticket
+func (b *Booking) IsComp() bool {
+	return b.StatusReason == BookingReasonComp
+}
+
 // Confirm marks the booking as confirmed
 func (b *Booking) Confirm(paymentID string) error {
 	if !b.CanConfirm() {
@@ -36,6 +36,7 @@ type BookingEventData struct {
 	TotalPrice       float64   `json:"total_price"`
 	Currency         string    `json:"currency"`
 	Status           string    `json:"status"`
+	StatusReason     string    `json:"status_reason,omitempty"`
 	PaymentID        string    `json:"payment_id,omitempty"`
 	ConfirmationCode string    `json:"confirmation_code,omitempty"`
 	ReservedAt       time.Time `json:"reserved_at"`
@@ -63,6 +64,7 @@ func NewBookingEvent(eventType BookingEventType, booking *Booking, eventID strin
 			TotalPrice:       booking.TotalPrice,
 			Currency:         booking.Currency,
 			Status:           string(booking.Status),
+			StatusReason:     booking.StatusReason,
 			PaymentID:        booking.PaymentID,
 			ConfirmationCode: booking.ConfirmationCode,
 			ReservedAt:       booking.ReservedAt,
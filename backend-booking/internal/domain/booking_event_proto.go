@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Protobuf field numbers from api/proto/booking_event.proto
+const (
+	protoEventID    protowire.Number = 1
+	protoEventType  protowire.Number = 2
+	protoOccurredAt protowire.Number = 3
+	protoVersion    protowire.Number = 4
+	protoData       protowire.Number = 5
+
+	protoDataBookingID        protowire.Number = 1
+	protoDataTenantID         protowire.Number = 2
+	protoDataUserID           protowire.Number = 3
+	protoDataEventID          protowire.Number = 4
+	protoDataShowID           protowire.Number = 5
+	protoDataZoneID           protowire.Number = 6
+	protoDataQuantity         protowire.Number = 7
+	protoDataUnitPrice        protowire.Number = 8
+	protoDataTotalPrice       protowire.Number = 9
+	protoDataCurrency         protowire.Number = 10
+	protoDataStatus           protowire.Number = 11
+	protoDataPaymentID        protowire.Number = 12
+	protoDataConfirmationCode protowire.Number = 13
+	protoDataReservedAt       protowire.Number = 14
+	protoDataConfirmedAt      protowire.Number = 15
+	protoDataCancelledAt      protowire.Number = 16
+	protoDataExpiresAt        protowire.Number = 17
+	protoDataStatusReason     protowire.Number = 18
+)
+
+// MarshalProto encodes the event using the booking.events.v1.BookingEvent schema
+func (e *BookingEvent) MarshalProto() ([]byte, error) {
+	b := make([]byte, 0, 256)
+	b = kafka.AppendProtoString(b, protoEventID, e.EventID)
+	b = kafka.AppendProtoString(b, protoEventType, string(e.EventType))
+	b = kafka.AppendProtoTimestamp(b, protoOccurredAt, e.OccurredAt)
+	b = kafka.AppendProtoInt64(b, protoVersion, int64(e.Version))
+	if e.BookingData != nil {
+		b = kafka.AppendProtoMessage(b, protoData, e.BookingData.marshalProto())
+	}
+	return b, nil
+}
+
+// UnmarshalProto decodes an event encoded with the booking.events.v1.BookingEvent schema
+func (e *BookingEvent) UnmarshalProto(data []byte) error {
+	*e = BookingEvent{}
+	return kafka.RangeProtoFields(data, func(num protowire.Number, f kafka.ProtoField) error {
+		var err error
+		switch num {
+		case protoEventID:
+			e.EventID = f.String()
+		case protoEventType:
+			e.EventType = BookingEventType(f.String())
+		case protoOccurredAt:
+			e.OccurredAt, err = f.Timestamp()
+		case protoVersion:
+			e.Version = int(f.Int64())
+		case protoData:
+			e.BookingData = &BookingEventData{}
+			err = e.BookingData.unmarshalProto(f.Bytes())
+		}
+		return err
+	})
+}
+
+func (d *BookingEventData) marshalProto() []byte {
+	b := make([]byte, 0, 192)
+	b = kafka.AppendProtoString(b, protoDataBookingID, d.BookingID)
+	b = kafka.AppendProtoString(b, protoDataTenantID, d.TenantID)
+	b = kafka.AppendProtoString(b, protoDataUserID, d.UserID)
+	b = kafka.AppendProtoString(b, protoDataEventID, d.EventID)
+	b = kafka.AppendProtoString(b, protoDataShowID, d.ShowID)
+	b = kafka.AppendProtoString(b, protoDataZoneID, d.ZoneID)
+	b = kafka.AppendProtoInt64(b, protoDataQuantity, int64(d.Quantity))
+	b = kafka.AppendProtoDouble(b, protoDataUnitPrice, d.UnitPrice)
+	b = kafka.AppendProtoDouble(b, protoDataTotalPrice, d.TotalPrice)
+	b = kafka.AppendProtoString(b, protoDataCurrency, d.Currency)
+	b = kafka.AppendProtoString(b, protoDataStatus, d.Status)
+	b = kafka.AppendProtoString(b, protoDataPaymentID, d.PaymentID)
+	b = kafka.AppendProtoString(b, protoDataConfirmationCode, d.ConfirmationCode)
+	b = kafka.AppendProtoTimestamp(b, protoDataReservedAt, d.ReservedAt)
+	if d.ConfirmedAt != nil {
+		b = kafka.AppendProtoTimestamp(b, protoDataConfirmedAt, *d.ConfirmedAt)
+	}
+	if d.CancelledAt != nil {
+		b = kafka.AppendProtoTimestamp(b, protoDataCancelledAt, *d.CancelledAt)
+	}
+	b = kafka.AppendProtoTimestamp(b, protoDataExpiresAt, d.ExpiresAt)
+	b = kafka.AppendProtoString(b, protoDataStatusReason, d.StatusReason)
+	return b
+}
+
+func (d *BookingEventData) unmarshalProto(data []byte) error {
+	return kafka.RangeProtoFields(data, func(num protowire.Number, f kafka.ProtoField) error {
+		var err error
+		switch num {
+		case protoDataBookingID:
+			d.BookingID = f.String()
+		case protoDataTenantID:
+			d.TenantID = f.String()
+		case protoDataUserID:
+			d.UserID = f.String()
+		case protoDataEventID:
+			d.EventID = f.String()
+		case protoDataShowID:
+			d.ShowID = f.String()
+		case protoDataZoneID:
+			d.ZoneID = f.String()
+		case protoDataQuantity:
+			d.Quantity = int(f.Int64())
+		case protoDataUnitPrice:
+			d.UnitPrice = f.Double()
+		case protoDataTotalPrice:
+			d.TotalPrice = f.Double()
+		case protoDataCurrency:
+			d.Currency = f.String()
+		case protoDataStatus:
+			d.Status = f.String()
+		case protoDataPaymentID:
+			d.PaymentID = f.String()
+		case protoDataConfirmationCode:
+			d.ConfirmationCode = f.String()
+		case protoDataReservedAt:
+			d.ReservedAt, err = f.Timestamp()
+		case protoDataConfirmedAt:
+			d.ConfirmedAt, err = optionalTimestamp(f)
+		case protoDataCancelledAt:
+			d.CancelledAt, err = optionalTimestamp(f)
+		case protoDataExpiresAt:
+			d.ExpiresAt, err = f.Timestamp()
+		case protoDataStatusReason:
+			d.StatusReason = f.String()
+		}
+		return err
+	})
+}
+
+func optionalTimestamp(f kafka.ProtoField) (*time.Time, error) {
+	t, err := f.Timestamp()
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
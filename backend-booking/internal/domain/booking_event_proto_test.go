@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+)
+
+func newTestBookingEvent() *BookingEvent {
+	reservedAt := time.Date(2026, 5, 1, 10, 0, 0, 500, time.UTC)
+	confirmedAt := reservedAt.Add(2 * time.Minute)
+
+	booking := &Booking{
+		ID:               "6f1c2a9e-3b4d-4e5f-8a7b-1c2d3e4f5a6b",
+		TenantID:         "tenant-1",
+		UserID:           "user-42",
+		EventID:          "event-concert-2026",
+		ShowID:           "show-1",
+		ZoneID:           "zone-vip-a",
+		Quantity:         4,
+		UnitPrice:        2500,
+		TotalPrice:       10000,
+		Currency:         "THB",
+		Status:           BookingStatusConfirmed,
+		PaymentID:        "pay-123",
+		ConfirmationCode: "BR-ABC123",
+		ReservedAt:       reservedAt,
+		ConfirmedAt:      &confirmedAt,
+		ExpiresAt:        reservedAt.Add(10 * time.Minute),
+	}
+
+	event := NewBookingEvent(BookingEventConfirmed, booking, "evt-1")
+	event.OccurredAt = confirmedAt
+	return event
+}
+
+func TestBookingEvent_ProtoRoundTrip(t *testing.T) {
+	in := newTestBookingEvent()
+
+	data, err := in.MarshalProto()
+	if err != nil {
+		t.Fatalf("MarshalProto() error = %v", err)
+	}
+
+	var out BookingEvent
+	if err := out.UnmarshalProto(data); err != nil {
+		t.Fatalf("UnmarshalProto() error = %v", err)
+	}
+
+	if out.EventID != in.EventID || out.EventType != in.EventType || out.Version != in.Version || !out.OccurredAt.Equal(in.OccurredAt) {
+		t.Errorf("envelope = %+v, want %+v", out, in)
+	}
+
+	got, want := out.BookingData, in.BookingData
+	if got == nil {
+		t.Fatal("BookingData is nil")
+	}
+	if got.BookingID != want.BookingID || got.ZoneID != want.ZoneID || got.Quantity != want.Quantity ||
+		got.TotalPrice != want.TotalPrice || got.ConfirmationCode != want.ConfirmationCode {
+		t.Errorf("BookingData = %+v, want %+v", got, want)
+	}
+	if !got.ReservedAt.Equal(want.ReservedAt) || !got.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Errorf("timestamps = %v/%v, want %v/%v", got.ReservedAt, got.ExpiresAt, want.ReservedAt, want.ExpiresAt)
+	}
+	if got.ConfirmedAt == nil || !got.ConfirmedAt.Equal(*want.ConfirmedAt) {
+		t.Errorf("ConfirmedAt = %v, want %v", got.ConfirmedAt, want.ConfirmedAt)
+	}
+	if got.CancelledAt != nil {
+		t.Errorf("CancelledAt = %v, want nil", got.CancelledAt)
+	}
+}
+
+func TestBookingEvent_ProtoRoundTripStatusReason(t *testing.T) {
+	in := newTestBookingEvent()
+	in.BookingData.StatusReason = BookingReasonComp
+
+	data, err := in.MarshalProto()
+	if err != nil {
+		t.Fatalf("MarshalProto() error = %v", err)
+	}
+
+	var out BookingEvent
+	if err := out.UnmarshalProto(data); err != nil {
+		t.Fatalf("UnmarshalProto() error = %v", err)
+	}
+	if out.BookingData.StatusReason != BookingReasonComp {
+		t.Errorf("StatusReason = %q, want %q", out.BookingData.StatusReason, BookingReasonComp)
+	}
+}
+
+func TestBookingEvent_DecodeByContentType(t *testing.T) {
+	in := newTestBookingEvent()
+
+	for _, c := range []kafka.Codec{kafka.JSONCodec{}, kafka.ProtobufCodec{}, kafka.MsgPackCodec{}} {
+		t.Run(c.Name(), func(t *testing.T) {
+			value, err := c.Marshal(in)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			record := &kafka.Record{
+				Value:   value,
+				Headers: map[string]string{kafka.HeaderContentType: c.ContentType()},
+			}
+			var out BookingEvent
+			if err := record.Decode(&out); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if out.Key() != in.Key() || out.BookingData.TotalPrice != in.BookingData.TotalPrice {
+				t.Errorf("Decode() = %+v, want %+v", out.BookingData, in.BookingData)
+			}
+		})
+	}
+}
+
+// BenchmarkBookingEventCodecs compares encode/decode cost and payload size:
+//
+//	go test ./internal/domain -run ^$ -bench BookingEventCodecs -benchmem
+func BenchmarkBookingEventCodecs(b *testing.B) {
+	event := newTestBookingEvent()
+	codecs := []kafka.Codec{kafka.JSONCodec{}, kafka.ProtobufCodec{}, kafka.MsgPackCodec{}}
+
+	for _, c := range codecs {
+		data, err := c.Marshal(event)
+		if err != nil {
+			b.Fatalf("%s Marshal() error = %v", c.Name(), err)
+		}
+
+		b.Run(c.Name()+"/marshal", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ReportMetric(float64(len(data)), "bytes/msg")
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Marshal(event); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(c.Name()+"/unmarshal", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var out BookingEvent
+				if err := c.Unmarshal(data, &out); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
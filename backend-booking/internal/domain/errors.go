@@ -29,6 +29,7 @@ var (
 	// Availability errors
 	ErrInsufficientSeats  = errors.New("insufficient seats available")
 	ErrMaxTicketsExceeded = errors.New("maximum tickets per user exceeded")
+	ErrIdentityLimitExceeded = errors.New("purchase limit for this phone, card, device or network exceeded")
 
 	// Zone errors
 	ErrZoneNotFound = errors.New("zone not found")
@@ -47,6 +48,10 @@ var (
 	ErrQueuePassExpired      = errors.New("queue pass has expired or already used")
 	ErrQueuePassUserMismatch = errors.New("queue pass does not belong to this user")
 	ErrQueuePassEventMismatch = errors.New("queue pass is for a different event")
+	ErrQueuePassExhausted     = errors.New("queue pass has no reservations or seats left")
+	ErrQueuePassDeviceMismatch = errors.New("queue pass is bound to another device")
+	ErrQueueJoinRejected       = errors.New("queue join refused as automated traffic")
+	ErrQueueDrained            = errors.New("queue has been closed for this event")
 )
 
 // IsNotFoundError checks if the error is a not found error
@@ -76,7 +81,8 @@ func IsConflictError(err error) bool {
 		errors.Is(err, ErrAlreadyReleased) ||
 		errors.Is(err, ErrBookingAlreadyExists) ||
 		errors.Is(err, ErrInsufficientSeats) ||
-		errors.Is(err, ErrMaxTicketsExceeded)
+		errors.Is(err, ErrMaxTicketsExceeded) ||
+		errors.Is(err, ErrIdentityLimitExceeded)
 }
 
 // IsExpiredError checks if the error is an expiration error
@@ -70,6 +70,7 @@ func TestIsConflictError(t *testing.T) {
 		{"booking already exists", ErrBookingAlreadyExists, true},
 		{"insufficient seats", ErrInsufficientSeats, true},
 		{"max tickets exceeded", ErrMaxTicketsExceeded, true},
+		{"identity limit exceeded", ErrIdentityLimitExceeded, true},
 		{"booking not found", ErrBookingNotFound, false},
 		{"invalid user id", ErrInvalidUserID, false},
 		{"nil error", nil, false},
@@ -2,6 +2,68 @@ package domain
 
 import "time"
 
+// Queue modes, set per event in its queue configuration
+const (
+	QueueModeFIFO    = "fifo"    // Positions follow join time (default)
+	QueueModeLottery = "lottery" // Users who join before opening are drawn into a random order
+)
+
+// Queue states, set per event by operators and the queue scheduler.
+// Positions are kept while a queue is paused; a drained queue is emptied and
+// refuses joins until it is resumed, as does a queue whose waiting room has
+// not opened yet.
+const (
+	QueueStateActive    = "active"    // Releasing users (default)
+	QueueStatePaused    = "paused"    // Holding every user in place
+	QueueStateDrained   = "drained"   // Closed and emptied
+	QueueStateScheduled = "scheduled" // Waiting room not open yet
+)
+
+// DefaultQueuePassReservations is how many reservations a queue pass may be
+// spent on when its event does not configure a budget
+const DefaultQueuePassReservations = 1
+
+// QueueLaneGeneral names the lane of users no priority lane admits
+const QueueLaneGeneral = "general"
+
+// QueueLaneParked names the lane suspicious joins are parked in. It has no
+// share of release batches, so it is only drained once every other lane is.
+const QueueLaneParked = "parked"
+
+// Limits of an event's priority lanes
+const (
+	MaxQueueLanes       = 4      // Priority lanes per event (join_queue.lua's lane KEYS besides the parked lane)
+	MaxQueueLaneUserIDs = 10_000 // Users on one lane's allow-list
+)
+
+// QueueLane is a priority lane of an event's virtual queue. Users join it
+// when their role or tenant claim matches, or when they are on its
+// allow-list; it is given Share percent of every release batch.
+type QueueLane struct {
+	Name    string   `json:"name"`
+	Share   int      `json:"share"`
+	Roles   []string `json:"roles,omitempty"`
+	Tenants []string `json:"tenants,omitempty"`
+	UserIDs []string `json:"user_ids,omitempty"`
+}
+
+// MatchesClaims reports whether the lane admits a user's role or tenant
+// claim; its allow-list is checked separately
+func (l *QueueLane) MatchesClaims(role, tenantID string) bool {
+	return (role != "" && contains(l.Roles, role)) ||
+		(tenantID != "" && contains(l.Tenants, tenantID))
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // QueueEntry represents a user's position in the virtual queue
 type QueueEntry struct {
 	UserID    string    `json:"user_id"`
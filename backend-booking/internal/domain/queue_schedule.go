@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// EventScheduleTopic carries the sale windows of events, published by the
+// ticket service whenever an event or one of its shows changes
+const EventScheduleTopic = "event-schedules"
+
+// DefaultWaitingRoomLead is how long before its sale starts an event's
+// waiting room opens
+const DefaultWaitingRoomLead = 30 * time.Minute
+
+// Phases of a scheduled queue
+const (
+	QueuePhasePending = "pending" // Waiting room not open; joins are refused
+	QueuePhaseWaiting = "waiting" // Waiting room open; joins enter the lottery
+	QueuePhaseOnSale  = "on_sale" // Lottery drawn; users are released
+	QueuePhaseClosed  = "closed"  // Sale over; the queue is drained
+)
+
+// EventSchedule is the sale window of an event as the ticket service
+// publishes it. SaleStartAt is the earlier of the event's booking start and
+// its shows' sale starts; SaleEndAt is the event's booking end, or the
+// latest of its shows' sale ends.
+type EventSchedule struct {
+	EventID     string     `json:"event_id"`
+	Status      string     `json:"status"`
+	SaleStartAt *time.Time `json:"sale_start_at,omitempty"`
+	SaleEndAt   *time.Time `json:"sale_end_at,omitempty"`
+	Deleted     bool       `json:"deleted,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsScheduled reports whether the event's queue should follow its sale
+// window: it is published and its sale has a start
+func (s *EventSchedule) IsScheduled() bool {
+	return !s.Deleted && s.Status == "published" && s.SaleStartAt != nil
+}
+
+// QueuePhaseAt returns the phase of a queue whose waiting room opens at
+// opensAt, whose sale starts at startsAt and ends at endsAt (zero = never)
+func QueuePhaseAt(now, opensAt, startsAt, endsAt time.Time) string {
+	switch {
+	case !endsAt.IsZero() && !now.Before(endsAt):
+		return QueuePhaseClosed
+	case !now.Before(startsAt):
+		return QueuePhaseOnSale
+	case !now.Before(opensAt):
+		return QueuePhaseWaiting
+	default:
+		return QueuePhasePending
+	}
+}
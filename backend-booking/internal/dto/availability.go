@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// ZoneAvailability is the live seat count of one zone
+type ZoneAvailability struct {
+	ZoneID         string `json:"zone_id"`
+	AvailableSeats int64  `json:"available_seats"`
+	SoldOut        bool   `json:"sold_out"`
+}
+
+// ShowAvailabilityEvent is pushed on a show's availability stream: every zone
+// of the show when the stream opens, then only the zones that changed
+type ShowAvailabilityEvent struct {
+	ShowID    string             `json:"show_id"`
+	Zones     []ZoneAvailability `json:"zones"`
+	Timestamp time.Time          `json:"timestamp"`
+}
@@ -16,6 +16,12 @@ type ReserveSeatsRequest struct {
 	UnitPrice      float64 `json:"unit_price,omitempty"`
 	IdempotencyKey string  `json:"idempotency_key,omitempty"`
 	QueuePass      string  `json:"queue_pass,omitempty"` // JWT token from virtual queue
+
+	// Identity is checked against per-identity purchase limits (set by the handler)
+	Identity PurchaseIdentity `json:"-"`
+	// QueuePassID is the ID of the validated queue pass, whose budget the
+	// reservation spends (set by the handler)
+	QueuePassID string `json:"-"`
 }
 
 // ReserveSeatsResponse represents response after reserving seats
@@ -24,11 +30,19 @@ type ReserveSeatsResponse struct {
 	Status     string    `json:"status"`
 	ExpiresAt  time.Time `json:"expires_at"`
 	TotalPrice float64   `json:"total_price"`
+	// QueuePassReservationsLeft is how many more reservations the queue pass
+	// may be spent on; absent when no pass was spent
+	QueuePassReservationsLeft *int64 `json:"queue_pass_reservations_left,omitempty"`
 }
 
 // ConfirmBookingRequest represents request to confirm a booking
 type ConfirmBookingRequest struct {
 	PaymentID string `json:"payment_id,omitempty"`
+
+	// Identity and AuthToken are set by the handler; the card is looked up
+	// from the payment with the caller's token
+	Identity  PurchaseIdentity `json:"-"`
+	AuthToken string           `json:"-"`
 }
 
 // ConfirmBookingResponse represents response after confirming a booking
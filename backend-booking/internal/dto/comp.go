@@ -0,0 +1,87 @@
+package dto
+
+import "strings"
+
+// Allocation buckets comp tickets can be issued from
+const (
+	CompBucketHeld = "held"
+	CompBucketComp = "comp"
+)
+
+// CompRecipient is a comp ticket recipient, identified by user ID or email
+type CompRecipient struct {
+	UserID   string `json:"user_id,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Quantity int    `json:"quantity,omitempty"` // Defaults to 1
+}
+
+// IssueCompsRequest represents request to issue complimentary tickets from a
+// zone's held or comp allocation
+type IssueCompsRequest struct {
+	TenantID   string          `json:"tenant_id,omitempty"`
+	EventID    string          `json:"event_id" binding:"required"`
+	ShowID     string          `json:"show_id" binding:"required"`
+	ZoneID     string          `json:"zone_id" binding:"required"`
+	Bucket     string          `json:"bucket,omitempty"` // held or comp (default: comp)
+	Reason     string          `json:"reason,omitempty" binding:"omitempty,max=500"`
+	Recipients []CompRecipient `json:"recipients" binding:"required,min=1"`
+}
+
+// Validate validates the request and fills in defaults
+func (r *IssueCompsRequest) Validate(maxSeats int) (bool, string) {
+	if r.Bucket == "" {
+		r.Bucket = CompBucketComp
+	}
+	if r.Bucket != CompBucketHeld && r.Bucket != CompBucketComp {
+		return false, "Bucket must be one of held, comp"
+	}
+	if len(r.Recipients) == 0 {
+		return false, "At least one recipient is required"
+	}
+
+	seats := 0
+	for i := range r.Recipients {
+		recipient := &r.Recipients[i]
+		recipient.Email = strings.TrimSpace(recipient.Email)
+		if (recipient.UserID == "") == (recipient.Email == "") {
+			return false, "Each recipient needs exactly one of user_id, email"
+		}
+		if recipient.Quantity == 0 {
+			recipient.Quantity = 1
+		}
+		if recipient.Quantity < 0 {
+			return false, "Quantity must be greater than 0"
+		}
+		seats += recipient.Quantity
+	}
+	if maxSeats > 0 && seats > maxSeats {
+		return false, "Too many seats in one request"
+	}
+	return true, ""
+}
+
+// Seats returns the total seats the request issues
+func (r *IssueCompsRequest) Seats() int {
+	seats := 0
+	for _, recipient := range r.Recipients {
+		seats += recipient.Quantity
+	}
+	return seats
+}
+
+// CompBookingResponse represents a comp booking in API response
+type CompBookingResponse struct {
+	BookingID        string `json:"booking_id"`
+	UserID           string `json:"user_id"`
+	Email            string `json:"email,omitempty"`
+	Quantity         int    `json:"quantity"`
+	ConfirmationCode string `json:"confirmation_code"`
+}
+
+// IssueCompsResponse represents response after issuing comp tickets
+type IssueCompsResponse struct {
+	ZoneID   string                 `json:"zone_id"`
+	Bucket   string                 `json:"bucket"`
+	Seats    int                    `json:"seats"`
+	Bookings []*CompBookingResponse `json:"bookings"`
+}
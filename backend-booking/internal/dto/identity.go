@@ -0,0 +1,62 @@
+package dto
+
+import "fmt"
+
+// Identity kinds purchase limits can be set on
+const (
+	IdentityKindPhone    = "phone"     // Verified phone number from the access token
+	IdentityKindCard     = "card"      // Payment card brand and last four digits
+	IdentityKindDevice   = "device"    // X-Device-ID sent by the client
+	IdentityKindIPSubnet = "ip_subnet" // Client IP masked to its /24 (IPv6: /64)
+)
+
+// IdentityKinds lists the identity kinds in the order they are checked
+var IdentityKinds = []string{IdentityKindPhone, IdentityKindCard, IdentityKindDevice, IdentityKindIPSubnet}
+
+// PurchaseIdentity is who stands behind a reservation or payment beyond the
+// user account. It is filled from trusted headers and the payment record,
+// never from the request body.
+type PurchaseIdentity struct {
+	Phone        string
+	DeviceID     string
+	IP           string
+	CardBrand    string
+	CardLastFour string
+	UserAgent    string
+	RequestID    string
+}
+
+// SetIdentityLimitsRequest replaces an event's per-identity purchase limits.
+// Kinds left out use the service defaults; 0 removes the limit for the event.
+type SetIdentityLimitsRequest struct {
+	Limits map[string]int `json:"limits"`
+}
+
+// Validate validates the request
+func (r *SetIdentityLimitsRequest) Validate() (bool, string) {
+	for kind, max := range r.Limits {
+		known := false
+		for _, k := range IdentityKinds {
+			if kind == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return false, fmt.Sprintf("Unknown identity kind %q; must be one of phone, card, device, ip_subnet", kind)
+		}
+		if max < 0 {
+			return false, fmt.Sprintf("Limit for %s cannot be negative", kind)
+		}
+	}
+	return true, ""
+}
+
+// IdentityLimitsResponse represents an event's per-identity purchase limits
+type IdentityLimitsResponse struct {
+	EventID string `json:"event_id"`
+	// Limits are the limits in force, per kind (0 = unlimited)
+	Limits map[string]int `json:"limits"`
+	// Overrides are the kinds set for this event rather than by default
+	Overrides map[string]int `json:"overrides"`
+}
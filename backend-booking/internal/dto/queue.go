@@ -1,12 +1,44 @@
 package dto
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+)
 
 // JoinQueueRequest represents request to join the queue
 type JoinQueueRequest struct {
 	EventID string `json:"event_id" binding:"required"`
+	// Role and TenantID are the user's claims, set by the handler from the
+	// API gateway's headers; they decide the user's priority lane
+	Role     string `json:"-"`
+	TenantID string `json:"-"`
+	// Signals are what the join is screened for bots on (set by the handler)
+	Signals JoinSignals `json:"-"`
+}
+
+// JoinSignals are the signals of a queue join that bot screening weighs
+type JoinSignals struct {
+	IP        string
+	ASN       string // Network of the IP, as set by the edge proxy
+	DeviceID  string
+	UserAgent string
+	RequestID string
+	// Challenge is the gateway's verdict on the join's proof-of-work
+	// challenge: passed, failed or missing ("" when the gateway does not
+	// challenge joins)
+	Challenge string
 }
 
+// Join challenge results, as forwarded by the API gateway
+const (
+	JoinChallengePassed  = "passed"
+	JoinChallengeFailed  = "failed"
+	JoinChallengeMissing = "missing"
+)
+
 // JoinQueueResponse represents response after joining the queue
 type JoinQueueResponse struct {
 	Position      int64     `json:"position"`
@@ -15,6 +47,24 @@ type JoinQueueResponse struct {
 	JoinedAt      time.Time `json:"joined_at"`
 	ExpiresAt     time.Time `json:"expires_at"`
 	Message       string    `json:"message,omitempty"`
+	// InLottery is set when the user joined a lottery queue before it opened;
+	// Position stays 0 until the draw at LotteryDrawAt
+	InLottery     bool       `json:"in_lottery,omitempty"`
+	LotteryDrawAt *time.Time `json:"lottery_draw_at,omitempty"`
+	// EstimatedWaitRange is where the wait is expected to fall given how
+	// steadily the queue has been moving; EstimateBasis names what it is
+	// estimated from (releases, passes_used or default)
+	EstimatedWaitRange *WaitRange `json:"estimated_wait_range,omitempty"`
+	EstimateBasis      string     `json:"estimate_basis,omitempty"`
+	// Lane is the user's lane when the event has priority lanes ("general"
+	// for everyone no lane admits); Position and the wait are within it
+	Lane string `json:"lane,omitempty"`
+}
+
+// WaitRange is the span an estimated wait is expected to fall within
+type WaitRange struct {
+	MinSeconds int64 `json:"min_seconds"`
+	MaxSeconds int64 `json:"max_seconds"`
 }
 
 // QueuePositionResponse represents current queue position
@@ -29,6 +79,19 @@ type QueuePositionResponse struct {
 	QueuePass     string    `json:"queue_pass,omitempty"`
 	// QueuePassExpiresAt indicates when the queue pass expires (5 minutes validity)
 	QueuePassExpiresAt time.Time `json:"queue_pass_expires_at,omitempty"`
+	// InLottery is set while the user awaits the lottery draw at LotteryDrawAt
+	InLottery     bool       `json:"in_lottery,omitempty"`
+	LotteryDrawAt *time.Time `json:"lottery_draw_at,omitempty"`
+	// EstimatedWaitRange and EstimateBasis qualify EstimatedWait as in JoinQueueResponse
+	EstimatedWaitRange *WaitRange `json:"estimated_wait_range,omitempty"`
+	EstimateBasis      string     `json:"estimate_basis,omitempty"`
+	// Lane is as in JoinQueueResponse; TotalInLane counts the users in it
+	Lane        string `json:"lane,omitempty"`
+	TotalInLane int64  `json:"total_in_lane,omitempty"`
+	// QueueState is set while operators have paused the queue, with the
+	// message they left for waiting users
+	QueueState   string `json:"queue_state,omitempty"`
+	QueueMessage string `json:"queue_message,omitempty"`
 }
 
 // QueueStatusResponse represents queue status for an event
@@ -36,6 +99,13 @@ type QueueStatusResponse struct {
 	EventID      string `json:"event_id"`
 	TotalInQueue int64  `json:"total_in_queue"`
 	IsOpen       bool   `json:"is_open"`
+	// EstimatedWait is the wait of a user joining now, qualified as in JoinQueueResponse
+	EstimatedWait      int64      `json:"estimated_wait_seconds"`
+	EstimatedWaitRange *WaitRange `json:"estimated_wait_range,omitempty"`
+	EstimateBasis      string     `json:"estimate_basis,omitempty"`
+	// State is active, paused or drained, with the operators' message
+	State   string `json:"state"`
+	Message string `json:"message,omitempty"`
 }
 
 // LeaveQueueRequest represents request to leave the queue
@@ -49,3 +119,172 @@ type LeaveQueueResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// QueueConfigRequest replaces an event's virtual queue configuration
+type QueueConfigRequest struct {
+	MaxConcurrentBookings int `json:"max_concurrent_bookings"` // 0 = worker default
+	QueuePassTTLMinutes   int `json:"queue_pass_ttl_minutes"`  // 0 = worker default
+	// Mode is "fifo" (default) or "lottery"
+	Mode string `json:"mode"`
+	// LotteryOpensAt is when a lottery queue opens; everyone who joins
+	// before it is given a random position at that moment
+	LotteryOpensAt *time.Time `json:"lottery_opens_at,omitempty"`
+	// ReleaseRateOverride fixes the users released per interval instead of
+	// adapting it to booking health (0 = adaptive)
+	ReleaseRateOverride int `json:"release_rate_override"`
+	// Lanes are priority lanes with a share of every release batch. A user
+	// joins the first lane admitting their role or tenant claim or listing
+	// their user ID; everyone else queues in the general lane.
+	Lanes []QueueLane `json:"lanes,omitempty"`
+}
+
+// QueueLane is a priority lane of an event's virtual queue
+type QueueLane struct {
+	Name string `json:"name"`
+	// Share is the percentage of each release batch the lane is given
+	// ahead of the general lane
+	Share   int      `json:"share"`
+	Roles   []string `json:"roles,omitempty"`
+	Tenants []string `json:"tenants,omitempty"`
+	UserIDs []string `json:"user_ids,omitempty"` // Allow-list
+}
+
+// laneNamePattern is what a lane name may look like; it is part of Redis keys
+var laneNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Validate validates the request
+func (r *QueueConfigRequest) Validate() (bool, string) {
+	if r.MaxConcurrentBookings < 0 {
+		return false, "max_concurrent_bookings cannot be negative"
+	}
+	if r.QueuePassTTLMinutes < 0 {
+		return false, "queue_pass_ttl_minutes cannot be negative"
+	}
+	if r.ReleaseRateOverride < 0 {
+		return false, "release_rate_override cannot be negative"
+	}
+	if valid, msg := r.validateLanes(); !valid {
+		return false, msg
+	}
+	switch r.Mode {
+	case "", "fifo":
+		if r.LotteryOpensAt != nil {
+			return false, "lottery_opens_at is only valid with mode lottery"
+		}
+	case "lottery":
+		if r.LotteryOpensAt == nil || r.LotteryOpensAt.IsZero() {
+			return false, "lottery_opens_at is required with mode lottery"
+		}
+		if len(r.Lanes) > 0 {
+			return false, "lanes are only valid with mode fifo"
+		}
+	default:
+		return false, fmt.Sprintf("Unknown queue mode %q; must be fifo or lottery", r.Mode)
+	}
+	return true, ""
+}
+
+// validateLanes validates the priority lanes
+func (r *QueueConfigRequest) validateLanes() (bool, string) {
+	if len(r.Lanes) > domain.MaxQueueLanes {
+		return false, fmt.Sprintf("at most %d lanes are allowed", domain.MaxQueueLanes)
+	}
+	seen := make(map[string]bool, len(r.Lanes))
+	total := 0
+	for _, lane := range r.Lanes {
+		switch {
+		case !laneNamePattern.MatchString(lane.Name):
+			return false, fmt.Sprintf("Lane name %q must be 1-32 lowercase letters, digits, - or _", lane.Name)
+		case lane.Name == domain.QueueLaneGeneral || lane.Name == domain.QueueLaneParked:
+			return false, fmt.Sprintf("Lane name %q is reserved", lane.Name)
+		case seen[lane.Name]:
+			return false, fmt.Sprintf("Lane %q is listed twice", lane.Name)
+		case lane.Share < 1 || lane.Share > 100:
+			return false, fmt.Sprintf("Lane %q share must be between 1 and 100", lane.Name)
+		case len(lane.Roles) == 0 && len(lane.Tenants) == 0 && len(lane.UserIDs) == 0:
+			return false, fmt.Sprintf("Lane %q admits nobody; set roles, tenants or user_ids", lane.Name)
+		case len(lane.UserIDs) > domain.MaxQueueLaneUserIDs:
+			return false, fmt.Sprintf("Lane %q lists more than %d user_ids", lane.Name, domain.MaxQueueLaneUserIDs)
+		}
+		seen[lane.Name] = true
+		total += lane.Share
+	}
+	if total > 100 {
+		return false, "lane shares cannot add up to more than 100"
+	}
+	return true, ""
+}
+
+// QueueConfigResponse represents an event's virtual queue configuration
+type QueueConfigResponse struct {
+	EventID               string      `json:"event_id"`
+	MaxConcurrentBookings int         `json:"max_concurrent_bookings"`
+	QueuePassTTLMinutes   int         `json:"queue_pass_ttl_minutes"`
+	Mode                  string      `json:"mode"`
+	LotteryOpensAt        *time.Time  `json:"lottery_opens_at,omitempty"`
+	ReleaseRateOverride   int         `json:"release_rate_override"`
+	Lanes                 []QueueLane `json:"lanes,omitempty"`
+	// ReleaseRate is the rate the queue release worker currently admits at
+	ReleaseRate *QueueReleaseRate `json:"release_rate,omitempty"`
+	// Control is the state operators last put the queue in
+	Control *QueueControlResponse `json:"control,omitempty"`
+}
+
+// QueueReleaseRate is the number of users an event's queue releases per
+// release interval and what decided it
+type QueueReleaseRate struct {
+	Rate      int64     `json:"rate"`
+	Reason    string    `json:"reason"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QueueControlRequest pauses, resumes or drains an event's virtual queue
+type QueueControlRequest struct {
+	// Message is broadcast to the users waiting in the queue
+	Message string `json:"message" binding:"max=500"`
+	// UpdatedBy is the operator, set by the handler
+	UpdatedBy string `json:"-"`
+}
+
+// QueueControlResponse represents the state of an event's virtual queue
+type QueueControlResponse struct {
+	EventID   string    `json:"event_id"`
+	State     string    `json:"state"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Drained is how many waiting users a drain removed
+	Drained int64 `json:"drained,omitempty"`
+}
+
+// ExpireQueuePassesResponse represents the result of expiring an event's
+// outstanding queue passes
+type ExpireQueuePassesResponse struct {
+	EventID string `json:"event_id"`
+	Expired int64  `json:"expired"`
+}
+
+// Queue socket message types. Clients send subscribe and unsubscribe; the
+// server sends the rest, with the same data as the SSE stream's events.
+const (
+	QueueSocketSubscribe   = "subscribe"
+	QueueSocketUnsubscribe = "unsubscribe"
+	QueueSocketPosition    = "position"     // Data is a QueuePositionResponse
+	QueueSocketStatus      = "queue_status" // Data is the operators' broadcast
+	QueueSocketError       = "error"        // Data names the event ending a subscription
+	QueueSocketHeartbeat   = "heartbeat"
+)
+
+// QueueSocketRequest is a message a client sends on the queue WebSocket
+type QueueSocketRequest struct {
+	Type    string `json:"type"`
+	EventID string `json:"event_id"`
+}
+
+// QueueSocketMessage is a message the server sends on the queue WebSocket,
+// for the event it names
+type QueueSocketMessage struct {
+	Type    string      `json:"type"`
+	EventID string      `json:"event_id,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
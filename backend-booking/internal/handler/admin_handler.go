@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,7 +11,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
-	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -18,20 +20,22 @@ import (
 
 // AdminHandler handles admin HTTP requests
 type AdminHandler struct {
-	redis            *pkgredis.Client
+	zones            repository.ReservationRepository
+	reconciler       service.InventoryReconciler // nil when reconciliation is not configured
 	ticketServiceURL string
 	httpClient       *http.Client
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(redis *pkgredis.Client) *AdminHandler {
+func NewAdminHandler(zones repository.ReservationRepository, reconciler service.InventoryReconciler) *AdminHandler {
 	ticketURL := os.Getenv("TICKET_SERVICE_URL")
 	if ticketURL == "" {
 		ticketURL = "http://localhost:8082"
 	}
 
 	return &AdminHandler{
-		redis:            redis,
+		zones:            zones,
+		reconciler:       reconciler,
 		ticketServiceURL: ticketURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
@@ -121,8 +125,7 @@ func (h *AdminHandler) syncZoneAvailability(ctx context.Context) (int, error) {
 	count := 0
 	for _, zone := range ticketResp.Data {
 		// Set zone availability in Redis
-		key := fmt.Sprintf("zone:availability:%s", zone.ID)
-		if err := h.redis.Set(ctx, key, zone.AvailableSeats, 0).Err(); err != nil {
+		if err := h.zones.SetZoneAvailability(ctx, zone.ID, int64(zone.AvailableSeats)); err != nil {
 			continue
 		}
 		count++
@@ -196,6 +199,22 @@ func (h *AdminHandler) GetInventoryStatus(c *gin.Context) {
 		InSync          bool   `json:"in_sync"`
 	}
 
+	zoneIDs := make([]string, 0, len(ticketResp.Data))
+	for _, zone := range ticketResp.Data {
+		zoneIDs = append(zoneIDs, zone.ID)
+	}
+	redisAvailable, err := h.zones.GetZoneAvailabilities(ctx, zoneIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to read redis availability",
+			Code:    "REDIS_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	var zones []ZoneStatus
 	for _, zone := range ticketResp.Data {
 		z := ZoneStatus{
@@ -206,9 +225,8 @@ func (h *AdminHandler) GetInventoryStatus(c *gin.Context) {
 		}
 
 		// Get Redis value
-		key := fmt.Sprintf("zone:availability:%s", zone.ID)
-		val, err := h.redis.Get(ctx, key).Int64()
-		if err != nil {
+		val, ok := redisAvailable[zone.ID]
+		if !ok {
 			z.RedisAvailable = -1 // Not set in Redis
 			z.InSync = false
 		} else {
@@ -227,3 +245,84 @@ func (h *AdminHandler) GetInventoryStatus(c *gin.Context) {
 		"count":   len(zones),
 	})
 }
+
+// GetInventoryReconciliation handles GET /admin/inventory-reconciliation
+// Returns the report of the most recent reconciliation run
+func (h *AdminHandler) GetInventoryReconciliation(c *gin.Context) {
+	if !h.requireReconciler(c) {
+		return
+	}
+
+	report := h.reconciler.LastReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "no reconciliation report yet",
+			Code:    "NOT_FOUND",
+			Message: "the reconciler has not completed a run yet",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// RunInventoryReconciliation handles POST /admin/inventory-reconciliation/run
+// Runs reconciliation immediately; ?dry_run=true reports drift without correcting it
+func (h *AdminHandler) RunInventoryReconciliation(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.inventory_reconciliation")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	if !h.requireReconciler(c) {
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	span.SetAttributes(attribute.Bool("dry_run", dryRun))
+
+	report, err := h.reconciler.Reconcile(ctx, dryRun)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, service.ErrReconcileInProgress) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "reconciliation in progress",
+				Code:    "RECONCILE_IN_PROGRESS",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to reconcile inventory",
+			Code:    "RECONCILE_FAILED",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("zones_drifted", report.ZonesDrifted),
+		attribute.Int("zones_corrected", report.ZonesCorrected),
+	)
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// requireReconciler writes 503 and returns false when reconciliation is not configured
+func (h *AdminHandler) requireReconciler(c *gin.Context) bool {
+	if h.reconciler != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+		Error:   "inventory reconciliation not configured",
+		Code:    "RECONCILER_DISABLED",
+		Message: "TICKET_SERVICE_URL is required for inventory reconciliation",
+	})
+	return false
+}
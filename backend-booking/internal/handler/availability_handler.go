@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// availabilityKeepalive keeps idle streams open through proxies
+	availabilityKeepalive = 15 * time.Second
+	// availabilityMaxStream ends a stream before the gateway's 5 minute
+	// timeout; EventSource reconnects and receives a fresh snapshot
+	availabilityMaxStream = 5*time.Minute - 10*time.Second
+)
+
+// AvailabilityHandler streams live zone availability to event pages
+type AvailabilityHandler struct {
+	hub service.AvailabilityHub
+}
+
+// NewAvailabilityHandler creates a new availability handler
+func NewAvailabilityHandler(hub service.AvailabilityHub) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		hub: hub,
+	}
+}
+
+// StreamShowAvailability handles GET /availability/shows/:show_id/stream (SSE)
+// Sends every zone of the show as a snapshot event, then availability events
+// with the zones whose seats changed, at most one per hub interval. Seat maps
+// use them to grey out sold-out zones without polling GET /zones/:id.
+func (h *AvailabilityHandler) StreamShowAvailability(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.availability.stream_show")
+	defer span.End()
+
+	showID := c.Param("show_id")
+	if showID == "" {
+		span.SetStatus(codes.Error, "show_id required")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "show_id required",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+	span.SetAttributes(attribute.String("show_id", showID))
+
+	sub, err := h.hub.Subscribe(ctx, showID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		switch {
+		case errors.Is(err, service.ErrShowNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "show not found",
+				Code:  "SHOW_NOT_FOUND",
+			})
+		case errors.Is(err, service.ErrAvailabilityHubStopped):
+			c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+				Error: "availability stream unavailable",
+				Code:  "SERVICE_UNAVAILABLE",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "failed to open availability stream",
+				Code:    "INTERNAL_ERROR",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+	defer sub.Close()
+
+	// Set SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
+
+	writeAvailabilityEvent(c, "snapshot", sub.Snapshot)
+
+	keepalive := time.NewTicker(availabilityKeepalive)
+	defer keepalive.Stop()
+
+	maxStream := time.NewTimer(availabilityMaxStream)
+	defer maxStream.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Client disconnected
+			span.SetStatus(codes.Ok, "")
+			return
+
+		case event, ok := <-sub.Updates():
+			if !ok {
+				span.SetStatus(codes.Ok, "")
+				return
+			}
+			writeAvailabilityEvent(c, "availability", event)
+
+		case <-keepalive.C:
+			c.Writer.WriteString(": keepalive\n\n")
+			c.Writer.Flush()
+
+		case <-maxStream.C:
+			span.SetStatus(codes.Ok, "max_stream")
+			return
+		}
+	}
+}
+
+// writeAvailabilityEvent writes one SSE event and flushes it to the client
+func writeAvailabilityEvent(c *gin.Context, name string, event *dto.ShowAvailabilityEvent) {
+	data, _ := json.Marshal(event)
+	c.Writer.WriteString(fmt.Sprintf("event: %s\ndata: %s\n\n", name, data))
+	c.Writer.Flush()
+}
@@ -76,6 +76,7 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 	if req.TenantID == "" {
 		req.TenantID = c.GetString("tenant_id")
 	}
+	req.Identity = purchaseIdentity(c)
 
 	span.SetAttributes(
 		attribute.String("user_id", userID),
@@ -88,12 +89,14 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 
 	// Validate queue pass if required
 	if h.requireQueuePass {
-		if err := h.queueService.ValidateQueuePass(ctx, userID, req.EventID, req.QueuePass); err != nil {
+		claims, err := h.queueService.ValidateQueuePass(ctx, userID, req.EventID, req.QueuePass)
+		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			h.handleError(c, err)
 			return
 		}
+		req.QueuePassID = claims.ID
 		span.SetAttributes(attribute.Bool("queue_pass_valid", true))
 	}
 
@@ -106,8 +109,8 @@ func (h *BookingHandler) ReserveSeats(c *gin.Context) {
 		return
 	}
 
-	// Delete queue pass after successful reservation (one-time use)
-	if h.requireQueuePass && h.queueService != nil {
+	// Delete the queue pass once its budget is used up
+	if left := result.QueuePassReservationsLeft; left != nil && *left <= 0 && h.queueService != nil {
 		// Run in background - don't block the response
 		go func() {
 			_ = h.queueService.DeleteQueuePass(ctx, userID, req.EventID)
@@ -153,6 +156,8 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 	var req dto.ConfirmBookingRequest
 	// PaymentID is optional, so we don't fail if body is empty
 	_ = c.ShouldBindJSON(&req)
+	req.Identity = purchaseIdentity(c)
+	req.AuthToken = c.GetHeader("Authorization")
 
 	if req.PaymentID != "" {
 		span.SetAttributes(attribute.String("payment_id", req.PaymentID))
@@ -170,6 +175,19 @@ func (h *BookingHandler) ConfirmBooking(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// purchaseIdentity collects the identities behind a request for per-identity
+// purchase limits. The gateway sets X-User-Phone from a verified token claim
+// and the client IP (see TrustGatewayClientIP); X-Device-ID comes from the client.
+func purchaseIdentity(c *gin.Context) dto.PurchaseIdentity {
+	return dto.PurchaseIdentity{
+		Phone:     c.GetHeader("X-User-Phone"),
+		DeviceID:  c.GetHeader("X-Device-ID"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetHeader("X-Request-ID"),
+	}
+}
+
 // ReleaseBooking handles DELETE /bookings/:id
 func (h *BookingHandler) ReleaseBooking(c *gin.Context) {
 	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.booking.release")
@@ -457,6 +475,11 @@ func (h *BookingHandler) handleError(c *gin.Context, err error) {
 			Error: err.Error(),
 			Code:  "MAX_TICKETS_EXCEEDED",
 		})
+	case errors.Is(err, domain.ErrIdentityLimitExceeded):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{
+			Error: err.Error(),
+			Code:  "IDENTITY_LIMIT_EXCEEDED",
+		})
 	case errors.Is(err, domain.ErrAlreadyConfirmed):
 		c.JSON(http.StatusConflict, dto.ErrorResponse{
 			Error: err.Error(),
@@ -497,6 +520,17 @@ func (h *BookingHandler) handleError(c *gin.Context, err error) {
 			Error: err.Error(),
 			Code:  "QUEUE_PASS_MISMATCH",
 		})
+	case errors.Is(err, domain.ErrQueuePassExhausted):
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Error:   err.Error(),
+			Code:    "QUEUE_PASS_EXHAUSTED",
+			Message: "Your queue pass has been used up. Please rejoin the queue.",
+		})
+	case errors.Is(err, domain.ErrQueuePassDeviceMismatch):
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Error: err.Error(),
+			Code:  "QUEUE_PASS_DEVICE_MISMATCH",
+		})
 	default:
 		_ = c.Error(err) // Log the error with gin
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
@@ -0,0 +1,15 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+// ClientIPHeader carries the client IP the API gateway saw on the connection
+const ClientIPHeader = "X-Client-IP"
+
+// TrustGatewayClientIP makes c.ClientIP() return the gateway's X-Client-IP
+// for requests from the gateway addresses in gatewayProxies. Requests from
+// anywhere else get their connection address, so callers that bypass the
+// gateway cannot pick the IP their purchase limits and join screening use.
+func TrustGatewayClientIP(router *gin.Engine, gatewayProxies []string) error {
+	router.RemoteIPHeaders = []string{ClientIPHeader}
+	return router.SetTrustedProxies(gatewayProxies)
+}
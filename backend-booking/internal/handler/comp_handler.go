@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// compIssuerRoles are the roles allowed to issue comp tickets (X-User-Role,
+// set by the API gateway); the ticket service checks the token again
+var compIssuerRoles = map[string]bool{
+	"admin":     true,
+	"organizer": true,
+}
+
+// CompAdminHandler handles admin HTTP requests for complimentary tickets
+type CompAdminHandler struct {
+	compService service.CompService
+}
+
+// NewCompAdminHandler creates a new comp admin handler
+func NewCompAdminHandler(compService service.CompService) *CompAdminHandler {
+	return &CompAdminHandler{
+		compService: compService,
+	}
+}
+
+// IssueComps handles POST /admin/comps
+// Issues confirmed, zero-price bookings to a list of user IDs or emails from
+// a zone's held or comp allocation
+func (h *CompAdminHandler) IssueComps(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.comps.issue")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	if !compIssuerRoles[c.GetHeader("X-User-Role")] {
+		span.SetStatus(codes.Error, "forbidden")
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Error: "only organizers and admins can issue comp tickets",
+			Code:  "FORBIDDEN",
+		})
+		return
+	}
+
+	var req dto.IssueCompsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+	if tenantID := c.GetHeader("X-Tenant-ID"); tenantID != "" {
+		req.TenantID = tenantID
+	}
+	span.SetAttributes(
+		attribute.String("zone_id", req.ZoneID),
+		attribute.Int("recipients", len(req.Recipients)),
+	)
+
+	result, err := h.compService.IssueComps(ctx, &req, c.GetHeader("Authorization"))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		switch {
+		case errors.Is(err, service.ErrInvalidCompRequest):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid request",
+				Code:    "INVALID_REQUEST",
+				Message: err.Error(),
+			})
+		case errors.Is(err, service.ErrCompRecipientNotFound):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "unknown recipients",
+				Code:    "RECIPIENT_NOT_FOUND",
+				Message: err.Error(),
+			})
+		case errors.Is(err, service.ErrCompZoneNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: err.Error(),
+				Code:  "ZONE_NOT_FOUND",
+			})
+		case errors.Is(err, service.ErrCompAllocationShort):
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   err.Error(),
+				Code:    "INSUFFICIENT_ALLOCATION",
+				Message: "Not enough seats in the " + req.Bucket + " bucket",
+			})
+		case errors.Is(err, service.ErrCompForbidden):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error: err.Error(),
+				Code:  "FORBIDDEN",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "failed to issue comp tickets",
+				Code:    "INTERNAL_ERROR",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	span.SetAttributes(attribute.Int("bookings", len(result.Bookings)))
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// DLQAdminHandler handles admin HTTP requests for dead letter browsing, replay and purge
+type DLQAdminHandler struct {
+	dlqService service.DLQService
+}
+
+// NewDLQAdminHandler creates a new DLQ admin handler
+func NewDLQAdminHandler(dlqService service.DLQService) *DLQAdminHandler {
+	return &DLQAdminHandler{
+		dlqService: dlqService,
+	}
+}
+
+// DLQReplayRequest represents a replay request body
+type DLQReplayRequest struct {
+	IDs             []string        `json:"ids"`
+	Topic           string          `json:"topic"`
+	ErrorCode       string          `json:"error_code"`
+	From            *time.Time      `json:"from"`
+	To              *time.Time      `json:"to"`
+	Limit           int             `json:"limit"`
+	TargetTopic     string          `json:"target_topic"`
+	Payload         json.RawMessage `json:"payload"`
+	KeepUnprocessed bool            `json:"keep_unprocessed"`
+}
+
+// DLQPurgeRequest represents a purge request body
+type DLQPurgeRequest struct {
+	IDs              []string   `json:"ids"`
+	Topic            string     `json:"topic"`
+	ErrorCode        string     `json:"error_code"`
+	SagaID           string     `json:"saga_id"`
+	From             *time.Time `json:"from"`
+	To               *time.Time `json:"to"`
+	IncludeProcessed bool       `json:"include_processed"`
+}
+
+// DLQMarkProcessedRequest represents a mark-processed request body
+type DLQMarkProcessedRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1"`
+}
+
+// ListDeadLetters handles GET /admin/dlq
+// Query: topic, error_code, saga_id, from, to (RFC3339), include_processed, limit, offset
+func (h *DLQAdminHandler) ListDeadLetters(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.dlq.list")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	filter, err := parseDeadLetterFilter(c)
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.dlqService.ListDeadLetters(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to list dead letters",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("total", page.Total))
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    page.Items,
+		"total":   page.Total,
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+}
+
+// GetDeadLetter handles GET /admin/dlq/:id
+func (h *DLQAdminHandler) GetDeadLetter(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.dlq.get")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("dead_letter_id", id))
+
+	dl, err := h.dlqService.GetDeadLetter(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, pkgsaga.ErrDeadLetterNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error: "dead letter not found",
+				Code:  "NOT_FOUND",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to get dead letter",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    dl,
+	})
+}
+
+// ReplayDeadLetters handles POST /admin/dlq/replay
+// Replays dead letters selected by IDs or filter to their original (or target) topic
+func (h *DLQAdminHandler) ReplayDeadLetters(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.dlq.replay")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	var req DLQReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if len(req.Payload) > 0 && !json.Valid(req.Payload) {
+		span.SetStatus(codes.Error, "invalid payload")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error: "payload must be valid JSON",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	replayReq := &service.DLQReplayRequest{
+		IDs:             req.IDs,
+		Topic:           req.TargetTopic,
+		Payload:         req.Payload,
+		KeepUnprocessed: req.KeepUnprocessed,
+	}
+	if len(req.IDs) == 0 && (req.Topic != "" || req.ErrorCode != "" || req.From != nil || req.To != nil) {
+		filter := &pkgsaga.DeadLetterFilter{
+			Topic:     req.Topic,
+			ErrorCode: req.ErrorCode,
+			Limit:     req.Limit,
+		}
+		if req.From != nil {
+			filter.From = *req.From
+		}
+		if req.To != nil {
+			filter.To = *req.To
+		}
+		replayReq.Filter = filter
+	}
+
+	resp, err := h.dlqService.Replay(ctx, replayReq)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.handleError(c, err, "failed to replay dead letters")
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("replayed", resp.Replayed),
+		attribute.Int("failed", resp.Failed),
+	)
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: resp.Failed == 0,
+		Data:    resp,
+		Message: fmt.Sprintf("Replayed %d of %d dead letters", resp.Replayed, resp.Selected),
+	})
+}
+
+// MarkProcessed handles POST /admin/dlq/mark-processed
+func (h *DLQAdminHandler) MarkProcessed(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.dlq.mark_processed")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	var req DLQMarkProcessedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	marked, err := h.dlqService.MarkProcessed(ctx, req.IDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.handleError(c, err, "failed to mark dead letters as processed")
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"marked":  marked,
+	})
+}
+
+// PurgeDeadLetters handles POST /admin/dlq/purge
+// At least one selector (ids, topic, error_code, saga_id, from or to) is
+// required so a bare request cannot wipe the table
+func (h *DLQAdminHandler) PurgeDeadLetters(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.dlq.purge")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	var req DLQPurgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	filter := &pkgsaga.DeadLetterFilter{
+		IDs:              req.IDs,
+		Topic:            req.Topic,
+		ErrorCode:        req.ErrorCode,
+		SagaID:           req.SagaID,
+		IncludeProcessed: req.IncludeProcessed,
+	}
+	if req.From != nil {
+		filter.From = *req.From
+	}
+	if req.To != nil {
+		filter.To = *req.To
+	}
+
+	purged, err := h.dlqService.Purge(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.handleError(c, err, "failed to purge dead letters")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("purged", purged))
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"purged":  purged,
+	})
+}
+
+// handleError maps DLQ service errors to HTTP responses
+func (h *DLQAdminHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrDLQNothingSelected):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "no dead letters selected",
+			Code:    "NOTHING_SELECTED",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrDLQEditRequiresSingleID):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   message,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+}
+
+// parseDeadLetterFilter builds a dead letter filter from query parameters
+func parseDeadLetterFilter(c *gin.Context) (*pkgsaga.DeadLetterFilter, error) {
+	filter := &pkgsaga.DeadLetterFilter{
+		Topic:     c.Query("topic"),
+		ErrorCode: c.Query("error_code"),
+		SagaID:    c.Query("saga_id"),
+		Limit:     50,
+	}
+
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		filter.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+		filter.To = t
+	}
+	if v := c.Query("include_processed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include_processed: %w", err)
+		}
+		filter.IncludeProcessed = b
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			return nil, fmt.Errorf("limit must be between 1 and 500")
+		}
+		filter.Limit = n
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("offset must be a non-negative integer")
+		}
+		filter.Offset = n
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+	"github.com/stretchr/testify/assert"
+)
+
+// purgeRecordingStore is a DeadLetterStore that records purges
+type purgeRecordingStore struct {
+	purged []*pkgsaga.DeadLetterFilter
+}
+
+func (s *purgeRecordingStore) ListDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) ([]*pkgsaga.DeadLetter, error) {
+	return nil, nil
+}
+
+func (s *purgeRecordingStore) CountDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error) {
+	return 0, nil
+}
+
+func (s *purgeRecordingStore) GetDeadLetter(ctx context.Context, id string) (*pkgsaga.DeadLetter, error) {
+	return nil, pkgsaga.ErrDeadLetterNotFound
+}
+
+func (s *purgeRecordingStore) MarkDeadLettersProcessed(ctx context.Context, ids []string) (int64, error) {
+	return int64(len(ids)), nil
+}
+
+func (s *purgeRecordingStore) PurgeDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error) {
+	s.purged = append(s.purged, filter)
+	return 3, nil
+}
+
+func TestDLQAdminHandler_PurgeRequiresSelector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &purgeRecordingStore{}
+	handler := NewDLQAdminHandler(service.NewDLQService(store, nil, nil))
+	router := gin.New()
+	router.POST("/api/v1/admin/dlq/purge", handler.PurgeDeadLetters)
+
+	purge := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/admin/dlq/purge", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, body := range []string{`{}`, `{"include_processed":true}`} {
+		w := purge(body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "NOTHING_SELECTED", body)
+	}
+	assert.Empty(t, store.purged, "an unbounded purge reached the store")
+
+	w := purge(`{"saga_id":"saga-1","include_processed":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, store.purged, 1) {
+		assert.Equal(t, "saga-1", store.purged[0].SagaID)
+		assert.True(t, store.purged[0].IncludeProcessed)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// IdentityLimitHandler handles admin HTTP requests for per-identity purchase limits
+type IdentityLimitHandler struct {
+	limiter service.IdentityLimiter
+}
+
+// NewIdentityLimitHandler creates a new identity limit handler
+func NewIdentityLimitHandler(limiter service.IdentityLimiter) *IdentityLimitHandler {
+	return &IdentityLimitHandler{
+		limiter: limiter,
+	}
+}
+
+// GetIdentityLimits handles GET /admin/events/:event_id/identity-limits
+// Returns the limits in force for the event and which of them it overrides
+func (h *IdentityLimitHandler) GetIdentityLimits(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.identity_limits.get")
+	defer span.End()
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	limits, err := h.limiter.GetLimits(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to get identity limits",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    limits,
+	})
+}
+
+// SetIdentityLimits handles PUT /admin/events/:event_id/identity-limits
+// Replaces the event's overrides; kinds left out use the service defaults
+// again. Changes reach every replica within the limits cache TTL.
+func (h *IdentityLimitHandler) SetIdentityLimits(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.identity_limits.set")
+	defer span.End()
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	var req dto.SetIdentityLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	limits, err := h.limiter.SetLimits(ctx, eventID, &req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, service.ErrInvalidIdentityLimits) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid identity limits",
+				Code:    "INVALID_REQUEST",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to set identity limits",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    limits,
+	})
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// QueueAdminHandler handles admin HTTP requests for event virtual queues
+type QueueAdminHandler struct {
+	queueService service.QueueService
+	redisClient  *redis.Client // For Pub/Sub broadcasts to SSE clients (optional)
+}
+
+// NewQueueAdminHandler creates a new queue admin handler
+func NewQueueAdminHandler(queueService service.QueueService, redisClient *redis.Client) *QueueAdminHandler {
+	return &QueueAdminHandler{
+		queueService: queueService,
+		redisClient:  redisClient,
+	}
+}
+
+// GetQueueConfig handles GET /admin/events/:event_id/queue-config
+// Includes the release rate the queue release worker currently admits at
+func (h *QueueAdminHandler) GetQueueConfig(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.queue_config.get")
+	defer span.End()
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	config, err := h.queueService.GetQueueConfig(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to get queue config",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    config,
+	})
+}
+
+// SetQueueConfig handles PUT /admin/events/:event_id/queue-config
+// Replaces the event's queue config, including its admission mode and
+// release rate override. Changes reach the queue release worker within its
+// config cache TTL.
+func (h *QueueAdminHandler) SetQueueConfig(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.queue_config.set")
+	defer span.End()
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	var req dto.QueueConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	config, err := h.queueService.SetQueueConfig(ctx, eventID, &req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, service.ErrInvalidQueueConfig) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid queue config",
+				Code:    "INVALID_REQUEST",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to set queue config",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    config,
+	})
+}
+
+// queueControlFunc changes the state of an event's queue
+type queueControlFunc func(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error)
+
+// PauseQueue handles POST /admin/events/:event_id/queue/pause
+// The queue release worker stops releasing on its next tick; waiting users
+// keep their positions and are sent the optional message
+func (h *QueueAdminHandler) PauseQueue(c *gin.Context) {
+	h.controlQueue(c, "handler.admin.queue.pause", h.queueService.PauseQueue)
+}
+
+// ResumeQueue handles POST /admin/events/:event_id/queue/resume
+// Releases resume on the worker's next tick; a drained queue takes joins again
+func (h *QueueAdminHandler) ResumeQueue(c *gin.Context) {
+	h.controlQueue(c, "handler.admin.queue.resume", h.queueService.ResumeQueue)
+}
+
+// DrainQueue handles POST /admin/events/:event_id/queue/drain
+// Closes the queue to joins and removes everyone waiting; connected users
+// are sent the optional message and their streams end
+func (h *QueueAdminHandler) DrainQueue(c *gin.Context) {
+	h.controlQueue(c, "handler.admin.queue.drain", h.queueService.DrainQueue)
+}
+
+// controlQueue changes the state of an event's queue and broadcasts it to
+// the users waiting in it
+func (h *QueueAdminHandler) controlQueue(c *gin.Context, spanName string, control queueControlFunc) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), spanName)
+	defer span.End()
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	// The body is optional: it only carries the message
+	var req dto.QueueControlRequest
+	if c.Request.Body != nil {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			span.SetStatus(codes.Error, "invalid request")
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid request",
+				Code:    "INVALID_REQUEST",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	req.UpdatedBy = c.GetString("user_id")
+
+	result, err := control(ctx, eventID, &req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		switch {
+		case errors.Is(err, domain.ErrQueueDrained):
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "queue drained",
+				Code:    "QUEUE_DRAINED",
+				Message: "Resume the queue before pausing it",
+			})
+		case errors.Is(err, domain.ErrInvalidEventID):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error: "invalid event_id",
+				Code:  "INVALID_REQUEST",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "failed to change queue state",
+				Code:    "INTERNAL_ERROR",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	// The state is set either way; users who miss the broadcast see it in
+	// their next position update
+	if h.redisClient != nil {
+		msg := &worker.QueueBroadcastMessage{
+			EventID: eventID,
+			State:   result.State,
+			Message: result.Message,
+			SentAt:  time.Now().Unix(),
+		}
+		if err := worker.PublishQueueBroadcast(ctx, h.redisClient, msg); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// ExpireQueuePasses handles POST /admin/events/:event_id/queue/expire-passes
+// Every outstanding queue pass stops working at once and its booking slot
+// goes back to the queue
+func (h *QueueAdminHandler) ExpireQueuePasses(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.queue.expire_passes")
+	defer span.End()
+
+	eventID := c.Param("event_id")
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	result, err := h.queueService.ExpireQueuePasses(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "failed to expire queue passes",
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
@@ -62,6 +62,11 @@ func (h *QueueHandler) JoinQueue(c *gin.Context) {
 		return
 	}
 
+	// Priority lanes admit by the claims the API gateway forwards
+	req.Role = c.GetHeader("X-User-Role")
+	req.TenantID = c.GetString("tenant_id")
+	req.Signals = joinSignals(c)
+
 	span.SetAttributes(
 		attribute.String("user_id", userID),
 		attribute.String("event_id", req.EventID),
@@ -79,6 +84,20 @@ func (h *QueueHandler) JoinQueue(c *gin.Context) {
 	c.JSON(http.StatusCreated, result)
 }
 
+// joinSignals collects what a join is screened for bots on. The gateway
+// sets the client IP (see TrustGatewayClientIP), X-Client-ASN from the edge
+// proxy and X-Queue-Challenge-Result from the proof-of-work challenge it verified.
+func joinSignals(c *gin.Context) dto.JoinSignals {
+	return dto.JoinSignals{
+		IP:        c.ClientIP(),
+		ASN:       c.GetHeader("X-Client-ASN"),
+		DeviceID:  c.GetHeader("X-Device-ID"),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetHeader("X-Request-ID"),
+		Challenge: c.GetHeader("X-Queue-Challenge-Result"),
+	}
+}
+
 // GetPosition handles GET /queue/position/:event_id
 func (h *QueueHandler) GetPosition(c *gin.Context) {
 	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.queue.position")
@@ -240,14 +259,10 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 	// FAST PATH: Check if user already has queue pass
 	result, err := h.queueService.GetPosition(ctx, userID, eventID)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotInQueue) {
-			data, _ := json.Marshal(map[string]interface{}{
-				"event":   "not_in_queue",
-				"message": "User is not in queue",
-			})
+		if data, ok := queueLeftEvent(err); ok {
 			c.Writer.WriteString(fmt.Sprintf("event: error\ndata: %s\n\n", data))
 			c.Writer.Flush()
-			span.SetStatus(codes.Error, "not_in_queue")
+			span.SetStatus(codes.Error, err.Error())
 			return
 		}
 		// Other error - return error response
@@ -285,12 +300,14 @@ func (h *QueueHandler) StreamPosition(c *gin.Context) {
 }
 
 // streamWithPubSub uses Redis Pub/Sub to wait for queue pass notification
-// Uses per-user channel for targeted delivery - no broadcast amplification
+// Uses per-user channel for targeted delivery - no broadcast amplification;
+// only operators' rare state changes are broadcast on the event's channel
 func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, userID, eventID string) {
 	// Subscribe to queue pass channel for this USER (targeted delivery)
 	// Trade-off: More Redis connections but no broadcast storm
 	channel := worker.QueuePassChannelKey(eventID, userID)
-	pubsub := h.redisClient.Subscribe(ctx, channel)
+	broadcastChannel := worker.QueueBroadcastChannelKey(eventID)
+	pubsub := h.redisClient.Subscribe(ctx, channel, broadcastChannel)
 	defer pubsub.Close()
 
 	// Get the channel for receiving messages
@@ -311,6 +328,21 @@ func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, use
 			return
 
 		case msg := <-msgChan:
+			// Operators paused, resumed or drained the queue
+			if msg.Channel == broadcastChannel {
+				var broadcast worker.QueueBroadcastMessage
+				if err := json.Unmarshal([]byte(msg.Payload), &broadcast); err != nil {
+					continue
+				}
+				data, _ := json.Marshal(broadcast)
+				c.Writer.WriteString(fmt.Sprintf("event: queue_status\ndata: %s\n\n", data))
+				c.Writer.Flush()
+				if broadcast.State == domain.QueueStateDrained {
+					return // Nobody is left in the queue
+				}
+				continue
+			}
+
 			// Received queue pass notification - this is already for this user (per-user channel)
 			var queuePassMsg worker.QueuePassReadyMessage
 			if err := json.Unmarshal([]byte(msg.Payload), &queuePassMsg); err != nil {
@@ -321,14 +353,7 @@ func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, use
 			// No filtering needed - per-user channel guarantees this is for us
 
 			// Send queue pass to client
-			result := &dto.QueuePositionResponse{
-				Position:           0,
-				TotalInQueue:       0,
-				IsReady:            true,
-				QueuePass:          queuePassMsg.QueuePass,
-				QueuePassExpiresAt: time.Unix(queuePassMsg.ExpiresAt, 0),
-			}
-			data, _ := json.Marshal(result)
+			data, _ := json.Marshal(queuePassReadyPosition(&queuePassMsg))
 			c.Writer.WriteString(fmt.Sprintf("event: position\ndata: %s\n\n", data))
 			c.Writer.Flush()
 			return // Done, close connection
@@ -337,11 +362,7 @@ func (h *QueueHandler) streamWithPubSub(c *gin.Context, ctx context.Context, use
 			// Send keepalive with current position (low frequency)
 			result, err := h.queueService.GetPosition(ctx, userID, eventID)
 			if err != nil {
-				if errors.Is(err, domain.ErrNotInQueue) {
-					data, _ := json.Marshal(map[string]interface{}{
-						"event":   "not_in_queue",
-						"message": "User is not in queue",
-					})
+				if data, ok := queueLeftEvent(err); ok {
 					c.Writer.WriteString(fmt.Sprintf("event: error\ndata: %s\n\n", data))
 					c.Writer.Flush()
 					return
@@ -390,11 +411,7 @@ func (h *QueueHandler) streamWithPolling(c *gin.Context, ctx context.Context, us
 		case <-ticker.C:
 			result, err := h.queueService.GetPosition(ctx, userID, eventID)
 			if err != nil {
-				if errors.Is(err, domain.ErrNotInQueue) {
-					data, _ := json.Marshal(map[string]interface{}{
-						"event":   "not_in_queue",
-						"message": "User is not in queue",
-					})
+				if data, ok := queueLeftEvent(err); ok {
 					fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
 					c.Writer.Flush()
 					return false
@@ -414,6 +431,38 @@ func (h *QueueHandler) streamWithPolling(c *gin.Context, ctx context.Context, us
 	})
 }
 
+// queuePassReadyPosition returns the position announcing a queue pass
+func queuePassReadyPosition(msg *worker.QueuePassReadyMessage) *dto.QueuePositionResponse {
+	return &dto.QueuePositionResponse{
+		Position:           0,
+		TotalInQueue:       0,
+		IsReady:            true,
+		QueuePass:          msg.QueuePass,
+		QueuePassExpiresAt: time.Unix(msg.ExpiresAt, 0),
+	}
+}
+
+// queueLeftEvent returns the data of the error event ending a position
+// stream once the user is no longer in the queue: they left it, or
+// operators drained it
+func queueLeftEvent(err error) ([]byte, bool) {
+	switch {
+	case errors.Is(err, domain.ErrQueueDrained):
+		data, _ := json.Marshal(map[string]interface{}{
+			"event":   "queue_closed",
+			"message": "Queue has been closed",
+		})
+		return data, true
+	case errors.Is(err, domain.ErrNotInQueue):
+		data, _ := json.Marshal(map[string]interface{}{
+			"event":   "not_in_queue",
+			"message": "User is not in queue",
+		})
+		return data, true
+	}
+	return nil, false
+}
+
 // handleError converts domain errors to HTTP responses
 func (h *QueueHandler) handleError(c *gin.Context, err error) {
 	switch {
@@ -442,6 +491,16 @@ func (h *QueueHandler) handleError(c *gin.Context, err error) {
 			Error: err.Error(),
 			Code:  "INVALID_TOKEN",
 		})
+	case errors.Is(err, domain.ErrQueueDrained):
+		c.JSON(http.StatusGone, dto.ErrorResponse{
+			Error: err.Error(),
+			Code:  "QUEUE_CLOSED",
+		})
+	case errors.Is(err, domain.ErrQueueJoinRejected):
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Error: err.Error(),
+			Code:  "QUEUE_JOIN_REJECTED",
+		})
 	case errors.Is(err, domain.ErrInvalidUserID):
 		c.JSON(http.StatusForbidden, dto.ErrorResponse{
 			Error: err.Error(),
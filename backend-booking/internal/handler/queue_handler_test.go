@@ -12,6 +12,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -53,9 +56,12 @@ func (m *MockQueueService) GetQueueStatus(ctx context.Context, eventID string) (
 	return args.Get(0).(*dto.QueueStatusResponse), args.Error(1)
 }
 
-func (m *MockQueueService) ValidateQueuePass(ctx context.Context, userID, eventID, queuePass string) error {
+func (m *MockQueueService) ValidateQueuePass(ctx context.Context, userID, eventID, queuePass string) (*service.QueuePassClaims, error) {
 	args := m.Called(ctx, userID, eventID, queuePass)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.QueuePassClaims), args.Error(1)
 }
 
 func (m *MockQueueService) DeleteQueuePass(ctx context.Context, userID, eventID string) error {
@@ -63,6 +69,54 @@ func (m *MockQueueService) DeleteQueuePass(ctx context.Context, userID, eventID
 	return args.Error(0)
 }
 
+func (m *MockQueueService) GetQueueConfig(ctx context.Context, eventID string) (*dto.QueueConfigResponse, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.QueueConfigResponse), args.Error(1)
+}
+
+func (m *MockQueueService) SetQueueConfig(ctx context.Context, eventID string, req *dto.QueueConfigRequest) (*dto.QueueConfigResponse, error) {
+	args := m.Called(ctx, eventID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.QueueConfigResponse), args.Error(1)
+}
+
+func (m *MockQueueService) PauseQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error) {
+	args := m.Called(ctx, eventID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.QueueControlResponse), args.Error(1)
+}
+
+func (m *MockQueueService) ResumeQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error) {
+	args := m.Called(ctx, eventID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.QueueControlResponse), args.Error(1)
+}
+
+func (m *MockQueueService) DrainQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error) {
+	args := m.Called(ctx, eventID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.QueueControlResponse), args.Error(1)
+}
+
+func (m *MockQueueService) ExpireQueuePasses(ctx context.Context, eventID string) (*dto.ExpireQueuePassesResponse, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExpireQueuePassesResponse), args.Error(1)
+}
+
 // newTestQueueHandler creates a QueueHandler for testing
 func newTestQueueHandler(queueService *MockQueueService) *QueueHandler {
 	return &QueueHandler{
@@ -135,6 +189,69 @@ func TestQueueHandler_JoinQueue_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestQueueHandler_JoinQueue_Rejected(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := newTestQueueHandler(mockService)
+	router := setupQueueTestRouter(handler)
+	assert.NoError(t, TrustGatewayClientIP(router, []string{"10.0.0.2"}))
+
+	// The join is screened on the signals the gateway forwards
+	mockService.On("JoinQueue", mock.Anything, "user-123", mock.MatchedBy(func(req *dto.JoinQueueRequest) bool {
+		return req.Signals == dto.JoinSignals{
+			IP:        "203.0.113.7",
+			ASN:       "AS64500",
+			DeviceID:  "device-1",
+			UserAgent: "test-agent",
+			Challenge: dto.JoinChallengeFailed,
+		}
+	})).Return(nil, domain.ErrQueueJoinRejected)
+
+	body, _ := json.Marshal(dto.JoinQueueRequest{EventID: "event-123"})
+	req, _ := http.NewRequest("POST", "/api/v1/queue/join", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.2:41000"
+	req.Header.Set("X-User-ID", "user-123")
+	req.Header.Set("X-Client-IP", "203.0.113.7")
+	req.Header.Set("X-Client-ASN", "AS64500")
+	req.Header.Set("X-Device-ID", "device-1")
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("X-Queue-Challenge-Result", dto.JoinChallengeFailed)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var response dto.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "QUEUE_JOIN_REJECTED", response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestQueueHandler_JoinQueue_IgnoresClientIPFromOutsideGateway(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := newTestQueueHandler(mockService)
+	router := setupQueueTestRouter(handler)
+	assert.NoError(t, TrustGatewayClientIP(router, []string{"10.0.0.2"}))
+
+	// A caller that bypasses the gateway is screened on its own address
+	mockService.On("JoinQueue", mock.Anything, "user-123", mock.MatchedBy(func(req *dto.JoinQueueRequest) bool {
+		return req.Signals.IP == "198.51.100.9"
+	})).Return(nil, domain.ErrQueueJoinRejected)
+
+	body, _ := json.Marshal(dto.JoinQueueRequest{EventID: "event-123"})
+	req, _ := http.NewRequest("POST", "/api/v1/queue/join", bytes.NewBuffer(body))
+	req.RemoteAddr = "198.51.100.9:41000"
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-123")
+	req.Header.Set("X-Client-IP", "203.0.113.7")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestQueueHandler_JoinQueue_Unauthorized(t *testing.T) {
 	mockService := new(MockQueueService)
 	handler := newTestQueueHandler(mockService)
@@ -203,6 +320,30 @@ func TestQueueHandler_JoinQueue_AlreadyInQueue(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestQueueHandler_JoinQueue_Drained(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := newTestQueueHandler(mockService)
+	router := setupQueueTestRouter(handler)
+
+	mockService.On("JoinQueue", mock.Anything, "user-123", mock.AnythingOfType("*dto.JoinQueueRequest")).Return(nil, domain.ErrQueueDrained)
+
+	body, _ := json.Marshal(dto.JoinQueueRequest{EventID: "event-123"})
+	req, _ := http.NewRequest("POST", "/api/v1/queue/join", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-123")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGone, w.Code)
+
+	var response dto.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "QUEUE_CLOSED", response.Code)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestQueueHandler_GetPosition_Success(t *testing.T) {
 	mockService := new(MockQueueService)
 	handler := newTestQueueHandler(mockService)
@@ -450,3 +591,100 @@ func TestQueueHandler_GetPosition_NoQueuePassWhenNotReady(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func setupQueueAdminTestRouter(handler *QueueAdminHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Next()
+	})
+
+	admin := router.Group("/api/v1/admin/events/:event_id/queue")
+	{
+		admin.POST("/pause", handler.PauseQueue)
+		admin.POST("/resume", handler.ResumeQueue)
+		admin.POST("/drain", handler.DrainQueue)
+		admin.POST("/expire-passes", handler.ExpireQueuePasses)
+	}
+	return router
+}
+
+func TestQueueAdminHandler_ControlQueue(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+
+	t.Run("drain broadcasts to the queue", func(t *testing.T) {
+		mockService := new(MockQueueService)
+		router := setupQueueAdminTestRouter(NewQueueAdminHandler(mockService, client))
+
+		pubsub := client.Subscribe(context.Background(), worker.QueueBroadcastChannelKey("event-123"))
+		defer pubsub.Close()
+		_, err := pubsub.Receive(context.Background()) // Subscription confirmed
+		assert.NoError(t, err)
+
+		mockService.On("DrainQueue", mock.Anything, "event-123", &dto.QueueControlRequest{Message: "Sold out", UpdatedBy: "admin-1"}).
+			Return(&dto.QueueControlResponse{EventID: "event-123", State: domain.QueueStateDrained, Message: "Sold out", Drained: 42}, nil)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/events/event-123/queue/drain", bytes.NewBufferString(`{"message":"Sold out"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		select {
+		case msg := <-pubsub.Channel():
+			var broadcast worker.QueueBroadcastMessage
+			assert.NoError(t, json.Unmarshal([]byte(msg.Payload), &broadcast))
+			assert.Equal(t, domain.QueueStateDrained, broadcast.State)
+			assert.Equal(t, "Sold out", broadcast.Message)
+		case <-time.After(time.Second):
+			t.Fatal("no broadcast received")
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("pause without a body", func(t *testing.T) {
+		mockService := new(MockQueueService)
+		router := setupQueueAdminTestRouter(NewQueueAdminHandler(mockService, client))
+
+		mockService.On("PauseQueue", mock.Anything, "event-123", &dto.QueueControlRequest{UpdatedBy: "admin-1"}).
+			Return(&dto.QueueControlResponse{EventID: "event-123", State: domain.QueueStatePaused}, nil)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/events/event-123/queue/pause", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("pausing a drained queue conflicts", func(t *testing.T) {
+		mockService := new(MockQueueService)
+		router := setupQueueAdminTestRouter(NewQueueAdminHandler(mockService, client))
+
+		mockService.On("PauseQueue", mock.Anything, "event-123", mock.Anything).Return(nil, domain.ErrQueueDrained)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/events/event-123/queue/pause", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var response dto.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "QUEUE_DRAINED", response.Code)
+	})
+
+	t.Run("expire passes", func(t *testing.T) {
+		mockService := new(MockQueueService)
+		router := setupQueueAdminTestRouter(NewQueueAdminHandler(mockService, nil))
+
+		mockService.On("ExpireQueuePasses", mock.Anything, "event-123").
+			Return(&dto.ExpireQueuePassesResponse{EventID: "event-123", Expired: 7}, nil)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admin/events/event-123/queue/expire-passes", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"expired":7`)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,492 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// queueSocketRefresh is how often followed positions are re-read, as the
+	// SSE stream's keepalive does
+	queueSocketRefresh = 15 * time.Second
+	// queueSocketPoll re-reads positions when Redis Pub/Sub is unavailable
+	queueSocketPoll = 500 * time.Millisecond
+	// queueSocketReadLimit bounds client messages, which are small
+	queueSocketReadLimit = 4096
+)
+
+// QueueSocketConfig holds configuration for queue position WebSockets
+type QueueSocketConfig struct {
+	// MaxConnections caps the sockets one instance holds; further upgrades
+	// are refused with 503 so clients back off or fall back to SSE
+	// (default: 10000)
+	MaxConnections int
+	// MaxEvents caps the event queues one socket follows (default: 5)
+	MaxEvents int
+	// SendBuffer is how many messages may wait for a client before it is
+	// dropped as too slow (default: 32)
+	SendBuffer int
+	// PingInterval is how often heartbeats are sent; a client that answers
+	// none for two intervals is disconnected (default: 15 seconds)
+	PingInterval time.Duration
+	// WriteTimeout bounds each write to a client (default: 10 seconds)
+	WriteTimeout time.Duration
+	// MaxLifetime ends a socket before the gateway's 5 minute timeout, with a
+	// timeout error for each queue it followed, as the SSE stream does;
+	// clients reconnect and subscribe again (default: 5 minutes less 10 seconds)
+	MaxLifetime time.Duration
+}
+
+// QueueSocketHandler streams queue positions over WebSockets, for clients
+// whose proxies drop SSE. A socket follows several events' queues at once
+// with the same events as GET /queue/position/:event_id/stream: position
+// updates, the position carrying the queue pass once it is ready, operators'
+// queue_status broadcasts and heartbeats.
+type QueueSocketHandler struct {
+	queueService service.QueueService
+	redisClient  *redis.Client // For Pub/Sub subscriptions (nil = poll)
+	config       *QueueSocketConfig
+	upgrader     websocket.Upgrader
+	connections  atomic.Int64
+}
+
+// NewQueueSocketHandler creates a new queue socket handler
+func NewQueueSocketHandler(queueService service.QueueService, redisClient *redis.Client, cfg *QueueSocketConfig) *QueueSocketHandler {
+	if cfg == nil {
+		cfg = &QueueSocketConfig{}
+	}
+	if cfg.MaxConnections <= 0 {
+		cfg.MaxConnections = 10000
+	}
+	if cfg.MaxEvents <= 0 {
+		cfg.MaxEvents = 5
+	}
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = 32
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 15 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.MaxLifetime <= 0 {
+		cfg.MaxLifetime = 5*time.Minute - 10*time.Second
+	}
+
+	return &QueueSocketHandler{
+		queueService: queueService,
+		redisClient:  redisClient,
+		config:       cfg,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Users are identified by the gateway's headers, never cookies,
+			// so another site cannot open a socket as them
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Connections returns the number of sockets open on this instance
+func (h *QueueSocketHandler) Connections() int64 {
+	return h.connections.Load()
+}
+
+// StreamPositions handles GET /queue/ws (WebSocket)
+// Queues given as event_id query parameters are followed from the start;
+// clients follow more, or stop following one, by sending
+// {"type":"subscribe"|"unsubscribe","event_id":"..."}.
+func (h *QueueSocketHandler) StreamPositions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error: "unauthorized",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	if h.connections.Add(1) > int64(h.config.MaxConnections) {
+		h.connections.Add(-1)
+		metrics.RecordQueueSocketRejected(c.Request.Context(), "connection_limit")
+		c.Header("Retry-After", "5")
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+			Error:   "too many queue connections",
+			Code:    "TOO_MANY_CONNECTIONS",
+			Message: "Retry later or use GET /queue/position/:event_id/stream",
+		})
+		return
+	}
+	defer h.connections.Add(-1)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // The upgrader replied with the error
+	}
+
+	// The request context ends with the handler; the socket gets its own
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	metrics.RecordQueueSocketConnection(ctx, 1)
+	defer metrics.RecordQueueSocketConnection(ctx, -1)
+
+	socket := &queueSocket{
+		handler:  h,
+		conn:     conn,
+		userID:   userID,
+		send:     make(chan *dto.QueueSocketMessage, h.config.SendBuffer),
+		cancel:   cancel,
+		events:   make(map[string]struct{}),
+		channels: make(map[string]string),
+	}
+	socket.run(ctx, c.QueryArray("event_id"))
+}
+
+// queueSocket is one client's WebSocket and the event queues it follows
+type queueSocket struct {
+	handler *QueueSocketHandler
+	conn    *websocket.Conn
+	userID  string
+	send    chan *dto.QueueSocketMessage
+	pubsub  *goredis.PubSub // nil when positions are polled
+
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+
+	mu       sync.Mutex
+	events   map[string]struct{} // Followed event IDs
+	channels map[string]string   // Pub/Sub channel -> event ID
+}
+
+// run serves the socket until the client leaves or the socket is closed
+func (s *queueSocket) run(ctx context.Context, eventIDs []string) {
+	if s.handler.redisClient != nil {
+		s.pubsub = s.handler.redisClient.Subscribe(ctx)
+		defer s.pubsub.Close()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.writeLoop(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		s.pumpLoop(ctx)
+	}()
+
+	for _, eventID := range eventIDs {
+		s.subscribe(ctx, eventID)
+	}
+	s.readLoop(ctx)
+
+	s.close(websocket.CloseNormalClosure, "")
+	wg.Wait()
+
+	s.mu.Lock()
+	metrics.RecordQueueSocketSubscription(context.Background(), -int64(len(s.events)))
+	s.mu.Unlock()
+}
+
+// close ends the socket; the first reason given is sent to the client
+func (s *queueSocket) close(code int, text string) {
+	s.closeOnce.Do(func() {
+		s.closeCode = code
+		s.closeText = text
+		s.cancel()
+	})
+}
+
+// readLoop handles the client's subscribe and unsubscribe messages
+func (s *queueSocket) readLoop(ctx context.Context) {
+	pongWait := 2 * s.handler.config.PingInterval
+	s.conn.SetReadLimit(queueSocketReadLimit)
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return // Client left, went silent, or the socket was closed
+		}
+		s.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var req dto.QueueSocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			s.sendError(req.EventID, "invalid_request", "Message is not valid JSON")
+			continue
+		}
+		switch req.Type {
+		case dto.QueueSocketSubscribe:
+			s.subscribe(ctx, req.EventID)
+		case dto.QueueSocketUnsubscribe:
+			s.unsubscribe(ctx, req.EventID)
+		default:
+			s.sendError(req.EventID, "invalid_request", fmt.Sprintf("Unknown message type %q", req.Type))
+		}
+	}
+}
+
+// writeLoop writes queued messages and heartbeats to the client, which is
+// the only writer of the socket
+func (s *queueSocket) writeLoop(ctx context.Context) {
+	cfg := s.handler.config
+	heartbeat := time.NewTicker(cfg.PingInterval)
+	defer heartbeat.Stop()
+	defer s.conn.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Flush what is queued, e.g. the errors ending the socket
+			for {
+				select {
+				case msg := <-s.send:
+					if !s.write(msg) {
+						return
+					}
+					continue
+				default:
+				}
+				break
+			}
+			closing := websocket.FormatCloseMessage(s.closeCode, s.closeText)
+			s.conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(cfg.WriteTimeout))
+			return
+
+		case msg := <-s.send:
+			if !s.write(msg) {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+
+		case <-heartbeat.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.WriteTimeout)); err != nil {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+			// Browsers do not surface pings to scripts
+			if !s.write(&dto.QueueSocketMessage{Type: dto.QueueSocketHeartbeat}) {
+				s.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// write writes one message, reporting whether the client took it in time
+func (s *queueSocket) write(msg *dto.QueueSocketMessage) bool {
+	s.conn.SetWriteDeadline(time.Now().Add(s.handler.config.WriteTimeout))
+	if err := s.conn.WriteJSON(msg); err != nil {
+		metrics.RecordQueueSocketDropped(context.Background(), "write_failed")
+		return false
+	}
+	return true
+}
+
+// enqueue queues a message for the client. A client that lets SendBuffer
+// messages pile up is dropped rather than slowing the others down.
+func (s *queueSocket) enqueue(msg *dto.QueueSocketMessage) {
+	select {
+	case s.send <- msg:
+	default:
+		metrics.RecordQueueSocketDropped(context.Background(), "slow_consumer")
+		s.close(websocket.CloseTryAgainLater, "client too slow")
+	}
+}
+
+// pumpLoop turns queue pass and broadcast notifications, and periodic
+// position reads, into messages
+func (s *queueSocket) pumpLoop(ctx context.Context) {
+	interval := queueSocketRefresh
+	var messages <-chan *goredis.Message
+	if s.pubsub != nil {
+		messages = s.pubsub.Channel()
+	} else {
+		interval = queueSocketPoll
+	}
+
+	refresh := time.NewTicker(interval)
+	defer refresh.Stop()
+
+	lifetime := time.NewTimer(s.handler.config.MaxLifetime)
+	defer lifetime.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			s.notify(ctx, msg)
+
+		case <-refresh.C:
+			for _, eventID := range s.followed() {
+				s.sendPosition(ctx, eventID)
+			}
+
+		case <-lifetime.C:
+			data, _ := json.Marshal(map[string]interface{}{
+				"event":   "timeout",
+				"message": "Queue wait timeout",
+			})
+			for _, eventID := range s.followed() {
+				s.enqueue(&dto.QueueSocketMessage{Type: dto.QueueSocketError, EventID: eventID, Data: json.RawMessage(data)})
+			}
+			s.close(websocket.CloseNormalClosure, "max lifetime reached")
+			return
+		}
+	}
+}
+
+// notify relays a queue pass or an operators' broadcast to the client
+func (s *queueSocket) notify(ctx context.Context, msg *goredis.Message) {
+	s.mu.Lock()
+	eventID, ok := s.channels[msg.Channel]
+	s.mu.Unlock()
+	if !ok {
+		return // Unsubscribed meanwhile
+	}
+
+	// Operators paused, resumed or drained the queue
+	if msg.Channel == worker.QueueBroadcastChannelKey(eventID) {
+		var broadcast worker.QueueBroadcastMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &broadcast); err != nil {
+			return
+		}
+		s.enqueue(&dto.QueueSocketMessage{Type: dto.QueueSocketStatus, EventID: eventID, Data: broadcast})
+		if broadcast.State == domain.QueueStateDrained {
+			s.unsubscribe(ctx, eventID) // Nobody is left in the queue
+		}
+		return
+	}
+
+	var queuePassMsg worker.QueuePassReadyMessage
+	if err := json.Unmarshal([]byte(msg.Payload), &queuePassMsg); err != nil {
+		return
+	}
+	s.enqueue(&dto.QueueSocketMessage{Type: dto.QueueSocketPosition, EventID: eventID, Data: queuePassReadyPosition(&queuePassMsg)})
+	s.unsubscribe(ctx, eventID)
+}
+
+// subscribe follows an event's queue, sending its current position. The
+// channels are subscribed first so a pass issued meanwhile is not missed.
+func (s *queueSocket) subscribe(ctx context.Context, eventID string) {
+	if eventID == "" {
+		s.sendError("", "invalid_request", "event_id required")
+		return
+	}
+
+	s.mu.Lock()
+	_, following := s.events[eventID]
+	full := !following && len(s.events) >= s.handler.config.MaxEvents
+	if !following && !full {
+		s.events[eventID] = struct{}{}
+		s.channels[worker.QueuePassChannelKey(eventID, s.userID)] = eventID
+		s.channels[worker.QueueBroadcastChannelKey(eventID)] = eventID
+	}
+	s.mu.Unlock()
+
+	if full {
+		metrics.RecordQueueSocketRejected(ctx, "subscription_limit")
+		s.sendError(eventID, "subscription_limit",
+			fmt.Sprintf("A connection follows at most %d queues", s.handler.config.MaxEvents))
+		return
+	}
+	if !following {
+		metrics.RecordQueueSocketSubscription(ctx, 1)
+		if s.pubsub != nil {
+			err := s.pubsub.Subscribe(ctx, worker.QueuePassChannelKey(eventID, s.userID), worker.QueueBroadcastChannelKey(eventID))
+			if err != nil {
+				s.unsubscribe(ctx, eventID)
+				s.sendError(eventID, "internal_error", "Failed to follow queue")
+				return
+			}
+		}
+	}
+
+	s.sendPosition(ctx, eventID)
+}
+
+// unsubscribe stops following an event's queue
+func (s *queueSocket) unsubscribe(ctx context.Context, eventID string) {
+	passChannel := worker.QueuePassChannelKey(eventID, s.userID)
+	broadcastChannel := worker.QueueBroadcastChannelKey(eventID)
+
+	s.mu.Lock()
+	_, following := s.events[eventID]
+	delete(s.events, eventID)
+	delete(s.channels, passChannel)
+	delete(s.channels, broadcastChannel)
+	s.mu.Unlock()
+	if !following {
+		return
+	}
+
+	metrics.RecordQueueSocketSubscription(ctx, -1)
+	if s.pubsub != nil && ctx.Err() == nil {
+		_ = s.pubsub.Unsubscribe(ctx, passChannel, broadcastChannel)
+	}
+}
+
+// sendPosition sends the user's position in an event's queue, and stops
+// following the queue once the pass is ready or the user is out of it
+func (s *queueSocket) sendPosition(ctx context.Context, eventID string) {
+	result, err := s.handler.queueService.GetPosition(ctx, s.userID, eventID)
+	if err != nil {
+		if data, ok := queueLeftEvent(err); ok {
+			s.enqueue(&dto.QueueSocketMessage{Type: dto.QueueSocketError, EventID: eventID, Data: json.RawMessage(data)})
+			s.unsubscribe(ctx, eventID)
+		}
+		return // Transient; the heartbeat keeps the socket alive
+	}
+
+	s.enqueue(&dto.QueueSocketMessage{Type: dto.QueueSocketPosition, EventID: eventID, Data: result})
+	if result.IsReady && result.QueuePass != "" {
+		s.unsubscribe(ctx, eventID)
+	}
+}
+
+// sendError sends an error event about a request or an event's queue
+func (s *queueSocket) sendError(eventID, event, message string) {
+	s.enqueue(&dto.QueueSocketMessage{
+		Type:    dto.QueueSocketError,
+		EventID: eventID,
+		Data:    map[string]interface{}{"event": event, "message": message},
+	})
+}
+
+// followed returns the event IDs the socket follows
+func (s *queueSocket) followed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	eventIDs := make([]string, 0, len(s.events))
+	for eventID := range s.events {
+		eventIDs = append(eventIDs, eventID)
+	}
+	return eventIDs
+}
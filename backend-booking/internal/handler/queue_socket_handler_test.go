@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/worker"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupQueueSocketTestServer(t *testing.T, handler *QueueSocketHandler) string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-User-ID"); userID != "" {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	router.GET("/api/v1/queue/ws", handler.StreamPositions)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/queue/ws"
+}
+
+func dialQueueSocket(t *testing.T, url string) (*websocket.Conn, *http.Response, error) {
+	header := http.Header{}
+	header.Set("X-User-ID", "user-123")
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// readQueueSocket reads the next message that is not a heartbeat
+func readQueueSocket(t *testing.T, conn *websocket.Conn) dto.QueueSocketMessage {
+	t.Helper()
+	for {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg dto.QueueSocketMessage
+		require.NoError(t, conn.ReadJSON(&msg))
+		if msg.Type != dto.QueueSocketHeartbeat {
+			return msg
+		}
+	}
+}
+
+func TestQueueSocketHandler_MultiplexesEvents(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	mockService := new(MockQueueService)
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-a").
+		Return(&dto.QueuePositionResponse{Position: 12, TotalInQueue: 40}, nil)
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-b").
+		Return(&dto.QueuePositionResponse{Position: 3, TotalInQueue: 9}, nil)
+	mockService.On("GetPosition", mock.Anything, "user-123", "event-c").
+		Return(&dto.QueuePositionResponse{Position: 1, TotalInQueue: 1}, nil)
+
+	handler := NewQueueSocketHandler(mockService, client, &QueueSocketConfig{MaxEvents: 2})
+	conn, _, err := dialQueueSocket(t, setupQueueSocketTestServer(t, handler)+"?event_id=event-a")
+	require.NoError(t, err)
+
+	msg := readQueueSocket(t, conn)
+	assert.Equal(t, dto.QueueSocketPosition, msg.Type)
+	assert.Equal(t, "event-a", msg.EventID)
+
+	require.NoError(t, conn.WriteJSON(dto.QueueSocketRequest{Type: dto.QueueSocketSubscribe, EventID: "event-b"}))
+	msg = readQueueSocket(t, conn)
+	assert.Equal(t, dto.QueueSocketPosition, msg.Type)
+	assert.Equal(t, "event-b", msg.EventID)
+
+	// A third queue is past the socket's limit
+	require.NoError(t, conn.WriteJSON(dto.QueueSocketRequest{Type: dto.QueueSocketSubscribe, EventID: "event-c"}))
+	msg = readQueueSocket(t, conn)
+	assert.Equal(t, dto.QueueSocketError, msg.Type)
+	assert.Equal(t, "event-c", msg.EventID)
+
+	passChannel := worker.QueuePassChannelKey("event-a", "user-123")
+	broadcastChannel := worker.QueueBroadcastChannelKey("event-b")
+	require.Eventually(t, func() bool {
+		subs := mr.PubSubNumSub(passChannel, broadcastChannel)
+		return subs[passChannel] == 1 && subs[broadcastChannel] == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// event-a's pass is ready
+	ctx := context.Background()
+	payload, _ := json.Marshal(worker.QueuePassReadyMessage{UserID: "user-123", EventID: "event-a", QueuePass: "pass-a", ExpiresAt: time.Now().Add(5 * time.Minute).Unix()})
+	require.NoError(t, client.Publish(ctx, passChannel, payload).Err())
+	msg = readQueueSocket(t, conn)
+	assert.Equal(t, dto.QueueSocketPosition, msg.Type)
+	assert.Equal(t, "event-a", msg.EventID)
+	data, _ := json.Marshal(msg.Data)
+	var position dto.QueuePositionResponse
+	require.NoError(t, json.Unmarshal(data, &position))
+	assert.True(t, position.IsReady)
+	assert.Equal(t, "pass-a", position.QueuePass)
+
+	// event-b is drained on the same socket
+	payload, _ = json.Marshal(worker.QueueBroadcastMessage{EventID: "event-b", State: domain.QueueStateDrained, Message: "Sold out"})
+	require.NoError(t, client.Publish(ctx, broadcastChannel, payload).Err())
+	msg = readQueueSocket(t, conn)
+	assert.Equal(t, dto.QueueSocketStatus, msg.Type)
+	assert.Equal(t, "event-b", msg.EventID)
+
+	// Both queues are done with, so the socket follows none of their channels
+	require.Eventually(t, func() bool {
+		subs := mr.PubSubNumSub(passChannel, broadcastChannel)
+		return subs[passChannel] == 0 && subs[broadcastChannel] == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestQueueSocketHandler_ConnectionLimit(t *testing.T) {
+	mockService := new(MockQueueService)
+	handler := NewQueueSocketHandler(mockService, nil, &QueueSocketConfig{MaxConnections: 1})
+	url := setupQueueSocketTestServer(t, handler)
+
+	_, _, err := dialQueueSocket(t, url)
+	require.NoError(t, err)
+
+	_, resp, err := dialQueueSocket(t, url)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	assert.Equal(t, int64(1), handler.Connections())
+}
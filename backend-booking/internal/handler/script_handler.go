@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ScriptRegistry is the Lua script registry served by the script admin API
+type ScriptRegistry interface {
+	Status() []pkgredis.ScriptStatus
+	ReloadDir(ctx context.Context, dir string, pins map[string]string) ([]pkgredis.ScriptStatus, error)
+}
+
+// ScriptAdminHandler handles admin HTTP requests for Lua script status and reload
+type ScriptAdminHandler struct {
+	registry ScriptRegistry
+	dir      string // directory reloads read <name>.lua from; empty disables reload
+}
+
+// NewScriptAdminHandler creates a new script admin handler
+func NewScriptAdminHandler(registry ScriptRegistry, dir string) *ScriptAdminHandler {
+	return &ScriptAdminHandler{
+		registry: registry,
+		dir:      dir,
+	}
+}
+
+// ScriptReloadRequest represents a reload request body. Scripts maps each
+// script to reload to the SHA1 its file must have.
+type ScriptReloadRequest struct {
+	Scripts map[string]string `json:"scripts" binding:"required,min=1"`
+}
+
+// ListScripts handles GET /admin/scripts
+// Returns the version and SHA each registered script is served with
+func (h *ScriptAdminHandler) ListScripts(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    h.registry.Status(),
+	})
+}
+
+// ReloadScripts handles POST /admin/scripts/reload
+// Reloads scripts from the scripts directory. Only this replica switches to
+// the new bodies; call it on every replica.
+func (h *ScriptAdminHandler) ReloadScripts(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.admin.scripts.reload")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	if h.dir == "" {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+			Error:   "script reload not configured",
+			Code:    "SCRIPT_RELOAD_DISABLED",
+			Message: "LUA_SCRIPTS_DIR is required for script reload",
+		})
+		return
+	}
+
+	var req ScriptReloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+	span.SetAttributes(attribute.Int("scripts", len(req.Scripts)))
+
+	statuses, err := h.registry.ReloadDir(ctx, h.dir, req.Scripts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{
+			Error:   "failed to reload scripts",
+			Code:    "SCRIPT_RELOAD_FAILED",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Data:    statuses,
+		Message: fmt.Sprintf("Reloaded %d scripts", len(req.Scripts)),
+	})
+}
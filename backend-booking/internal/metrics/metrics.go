@@ -33,6 +33,28 @@ var (
 	ActiveReservations *telemetry.UpDownCounter
 	QueueDepth         *telemetry.UpDownCounter
 
+	// Inventory reconciliation
+	InventoryDrift       *telemetry.Gauge
+	InventoryCorrections *telemetry.Counter
+
+	// Reservation fallback (Postgres while Redis is unavailable)
+	ReservationFallbackMode *telemetry.Gauge
+
+	// Per-identity purchase limits (anti-scalping)
+	IdentityLimitHits *telemetry.Counter
+
+	// Adaptive queue release
+	QueueReleaseRate *telemetry.Gauge
+
+	// Bot screening of queue joins
+	QueueJoinsScreened *telemetry.Counter
+
+	// Queue position WebSockets
+	QueueSocketConnections   *telemetry.UpDownCounter
+	QueueSocketSubscriptions *telemetry.UpDownCounter
+	QueueSocketRejected      *telemetry.Counter
+	QueueSocketDropped       *telemetry.Counter
+
 	initOnce sync.Once
 	initErr  error
 )
@@ -180,6 +202,102 @@ func initMetrics() error {
 		return err
 	}
 
+	// Inventory reconciliation
+	InventoryDrift, err = telemetry.NewGauge(telemetry.MetricOpts{
+		Name:        "booking_inventory_drift_seats",
+		Description: "Observed minus expected available seats per zone and source",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	InventoryCorrections, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "booking_inventory_corrections_total",
+		Description: "Total number of seats adjusted by the inventory reconciler",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Reservation fallback
+	ReservationFallbackMode, err = telemetry.NewGauge(telemetry.MetricOpts{
+		Name:        "booking_reservation_fallback_mode",
+		Description: "Reservation store in use: 0 = Redis, 1 = Postgres fallback, 2 = resyncing into Redis",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Identity limits
+	IdentityLimitHits, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "booking_identity_limit_hits_total",
+		Description: "Total number of reservations and payments refused by a per-identity purchase limit",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Adaptive queue release
+	QueueReleaseRate, err = telemetry.NewGauge(telemetry.MetricOpts{
+		Name:        "queue_release_rate",
+		Description: "Users the queue release worker admits per release interval, as adapted to booking health",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Join screening
+	QueueJoinsScreened, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "queue_joins_screened_total",
+		Description: "Total number of queue joins screened for bots, by decision (allow, park, reject)",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Queue position WebSockets
+	QueueSocketConnections, err = telemetry.NewUpDownCounter(telemetry.MetricOpts{
+		Name:        "queue_ws_connections",
+		Description: "Current number of open queue position WebSockets",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	QueueSocketSubscriptions, err = telemetry.NewUpDownCounter(telemetry.MetricOpts{
+		Name:        "queue_ws_subscriptions",
+		Description: "Current number of event queues followed over WebSockets",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	QueueSocketRejected, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "queue_ws_rejected_total",
+		Description: "Total number of queue WebSockets and subscriptions refused, by reason (connection_limit, subscription_limit)",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
+	QueueSocketDropped, err = telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "queue_ws_dropped_total",
+		Description: "Total number of queue WebSockets closed for not keeping up, by reason (slow_consumer, write_failed)",
+		Unit:        "1",
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -311,3 +429,95 @@ func RecordSlowRequest(ctx context.Context, operation string, durationSeconds fl
 		)
 	}
 }
+
+// RecordInventoryDrift records the drift of a zone's available seats for a source (redis, ticket_db)
+func RecordInventoryDrift(ctx context.Context, zoneID, source string, drift int64) {
+	if InventoryDrift != nil {
+		InventoryDrift.Record(ctx, drift,
+			attribute.String("zone_id", zoneID),
+			attribute.String("source", source),
+		)
+	}
+}
+
+// RecordInventoryCorrection records seats adjusted by the inventory reconciler
+func RecordInventoryCorrection(ctx context.Context, zoneID, source string, seats int64) {
+	if seats < 0 {
+		seats = -seats
+	}
+	if InventoryCorrections != nil {
+		InventoryCorrections.Add(ctx, seats,
+			attribute.String("zone_id", zoneID),
+			attribute.String("source", source),
+		)
+	}
+}
+
+// RecordReservationFallbackMode records which store serves reservations (0 Redis, 1 fallback, 2 resyncing)
+func RecordReservationFallbackMode(ctx context.Context, mode int64) {
+	if ReservationFallbackMode != nil {
+		ReservationFallbackMode.Record(ctx, mode)
+	}
+}
+
+// RecordIdentityLimitHit records a reservation or payment refused by the limit of an identity kind (phone, card, device, ip_subnet)
+func RecordIdentityLimitHit(ctx context.Context, eventID, kind, stage string) {
+	if IdentityLimitHits != nil {
+		IdentityLimitHits.Inc(ctx,
+			attribute.String("event_id", eventID),
+			attribute.String("kind", kind),
+			attribute.String("stage", stage),
+		)
+	}
+}
+
+// RecordQueueReleaseRate records the users an event's queue admits per release interval
+func RecordQueueReleaseRate(ctx context.Context, eventID string, rate int64) {
+	if QueueReleaseRate != nil {
+		QueueReleaseRate.Record(ctx, rate,
+			attribute.String("event_id", eventID),
+		)
+	}
+}
+
+// RecordQueueJoinScreened records the screening decision on a queue join
+func RecordQueueJoinScreened(ctx context.Context, eventID, decision string) {
+	if QueueJoinsScreened != nil {
+		QueueJoinsScreened.Inc(ctx,
+			attribute.String("event_id", eventID),
+			attribute.String("decision", decision),
+		)
+	}
+}
+
+// RecordQueueSocketConnection records a queue WebSocket opening (+1) or closing (-1)
+func RecordQueueSocketConnection(ctx context.Context, delta int64) {
+	if QueueSocketConnections != nil {
+		QueueSocketConnections.Add(ctx, delta)
+	}
+}
+
+// RecordQueueSocketSubscription records an event queue followed (+1) or no longer followed (-1) over a WebSocket
+func RecordQueueSocketSubscription(ctx context.Context, delta int64) {
+	if QueueSocketSubscriptions != nil {
+		QueueSocketSubscriptions.Add(ctx, delta)
+	}
+}
+
+// RecordQueueSocketRejected records a queue WebSocket or subscription refused by a limit
+func RecordQueueSocketRejected(ctx context.Context, reason string) {
+	if QueueSocketRejected != nil {
+		QueueSocketRejected.Inc(ctx,
+			attribute.String("reason", reason),
+		)
+	}
+}
+
+// RecordQueueSocketDropped records a queue WebSocket closed because its client did not keep up
+func RecordQueueSocketDropped(ctx context.Context, reason string) {
+	if QueueSocketDropped != nil {
+		QueueSocketDropped.Inc(ctx,
+			attribute.String("reason", reason),
+		)
+	}
+}
@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// admissionBucket is the span of one bucket of downstream health counters
+	admissionBucket = 10 * time.Second
+	// admissionBucketTTL bounds the longest window health can be read over
+	admissionBucketTTL = 5 * time.Minute
+	// admissionZonesTTL is how long an event remembers the zones it sold from
+	admissionZonesTTL = 24 * time.Hour
+	// admissionRateTTL is how long a computed release rate stays readable
+	// after the queue release worker stops updating it
+	admissionRateTTL = 5 * time.Minute
+)
+
+// AdmissionHealth is what the booking flow behind an event's queue reported
+// over a trailing window. The queue release worker admits users according to it.
+type AdmissionHealth struct {
+	// Reserved is the reservations that succeeded
+	Reserved int64
+	// Failed is the reservations that failed on an error rather than a
+	// business rule (sold out, per-user limit)
+	Failed int64
+	// Confirmed is the bookings paid for
+	Confirmed int64
+	// Latency is the summed duration of reserved and failed attempts
+	Latency time.Duration
+	// ZoneIDs is every zone the event has sold from
+	ZoneIDs []string
+}
+
+// Attempts returns the reservations that succeeded or failed on an error
+func (h *AdmissionHealth) Attempts() int64 {
+	return h.Reserved + h.Failed
+}
+
+// SuccessRate returns the share of attempts that succeeded (1 without attempts)
+func (h *AdmissionHealth) SuccessRate() float64 {
+	if h.Attempts() == 0 {
+		return 1
+	}
+	return float64(h.Reserved) / float64(h.Attempts())
+}
+
+// AvgLatency returns the mean duration of a reservation attempt
+func (h *AdmissionHealth) AvgLatency() time.Duration {
+	if h.Attempts() == 0 {
+		return 0
+	}
+	return h.Latency / time.Duration(h.Attempts())
+}
+
+// PaymentBacklog returns how many more seats were reserved than paid for in
+// the window, i.e. how far payments fall behind reservations
+func (h *AdmissionHealth) PaymentBacklog() int64 {
+	if h.Reserved <= h.Confirmed {
+		return 0
+	}
+	return h.Reserved - h.Confirmed
+}
+
+// AdmissionRate is the release rate the queue release worker last computed
+// for an event
+type AdmissionRate struct {
+	// Rate is the users released per release interval
+	Rate int64 `json:"rate"`
+	// Reason names what set the rate (healthy, degraded, sold_out, override, ...)
+	Reason    string    `json:"reason"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QueueThroughput is how many users an event's queue let through per bucket
+// over a trailing window, newest bucket first. Only complete buckets are
+// included, so the bucket in progress does not read as a slowdown.
+type QueueThroughput struct {
+	Bucket time.Duration
+	// Released is the users the queue release worker released, from one
+	// lane when the throughput is read for a lane
+	Released []int64
+	// PassesUsed is the queue passes spent on a reservation
+	PassesUsed []int64
+}
+
+// AdmissionHealthRepository records the health of the booking flow per event,
+// the queue release rate derived from it and the queue's throughput.
+// Counters are kept in time buckets so old samples age out without a sweeper.
+type AdmissionHealthRepository interface {
+	// RecordReserve records a reservation attempt. Attempts refused by a
+	// business rule are not recorded; they say nothing about health.
+	RecordReserve(ctx context.Context, eventID, zoneID string, success bool, latency time.Duration) error
+
+	// RecordConfirm records a booking that has been paid for
+	RecordConfirm(ctx context.Context, eventID string) error
+
+	// GetAdmissionHealth sums an event's counters over the trailing window
+	// (at most five minutes)
+	GetAdmissionHealth(ctx context.Context, eventID string, window time.Duration) (*AdmissionHealth, error)
+
+	// SetAdmissionRate stores the release rate computed for an event
+	SetAdmissionRate(ctx context.Context, eventID string, rate *AdmissionRate) error
+
+	// GetAdmissionRate returns the release rate last computed for an event,
+	// or nil when the worker has not computed one recently
+	GetAdmissionRate(ctx context.Context, eventID string) (*AdmissionRate, error)
+
+	// RecordRelease records users released from an event's queue, and from
+	// the lane they queued in unless lane is ""
+	RecordRelease(ctx context.Context, eventID, lane string, count int) error
+
+	// RecordPassUsed records a queue pass spent on a reservation
+	RecordPassUsed(ctx context.Context, eventID string) error
+
+	// GetQueueThroughput returns an event's per-bucket throughput over the
+	// trailing window (at most five minutes), releases counted for one lane
+	// unless lane is ""
+	GetQueueThroughput(ctx context.Context, eventID, lane string, window time.Duration) (*QueueThroughput, error)
+}
+
+// RedisAdmissionHealthRepository implements AdmissionHealthRepository using Redis
+type RedisAdmissionHealthRepository struct {
+	client *pkgredis.Client
+	now    func() time.Time
+}
+
+// NewRedisAdmissionHealthRepository creates a new Redis admission health repository
+func NewRedisAdmissionHealthRepository(client *pkgredis.Client) *RedisAdmissionHealthRepository {
+	return &RedisAdmissionHealthRepository{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// bucketKey returns the counters of the bucket holding t.
+// Format: admission:health:{event_id}:{bucket}
+func (r *RedisAdmissionHealthRepository) bucketKey(eventID string, t time.Time) string {
+	return fmt.Sprintf("admission:health:%s:%d", eventID, t.UnixNano()/int64(admissionBucket))
+}
+
+// zonesKey returns the set of zones an event has sold from
+func (r *RedisAdmissionHealthRepository) zonesKey(eventID string) string {
+	return fmt.Sprintf("admission:zones:%s", eventID)
+}
+
+// rateKey returns the hash holding an event's computed release rate
+func (r *RedisAdmissionHealthRepository) rateKey(eventID string) string {
+	return fmt.Sprintf("admission:rate:%s", eventID)
+}
+
+// RecordReserve records a reservation attempt in the current bucket
+func (r *RedisAdmissionHealthRepository) RecordReserve(ctx context.Context, eventID, zoneID string, success bool, latency time.Duration) error {
+	key := r.bucketKey(eventID, r.now())
+	field := "failed"
+	if success {
+		field = "reserved"
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.HIncrBy(ctx, key, field, 1)
+	pipe.HIncrBy(ctx, key, "latency_us", latency.Microseconds())
+	pipe.Expire(ctx, key, admissionBucketTTL)
+	if success && zoneID != "" {
+		pipe.SAdd(ctx, r.zonesKey(eventID), zoneID)
+		pipe.Expire(ctx, r.zonesKey(eventID), admissionZonesTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record reservation health: %w", err)
+	}
+	return nil
+}
+
+// RecordConfirm records a paid booking in the current bucket
+func (r *RedisAdmissionHealthRepository) RecordConfirm(ctx context.Context, eventID string) error {
+	return r.incrBucket(ctx, eventID, 1, "confirmed")
+}
+
+// GetAdmissionHealth reads every bucket of the window in one pipeline
+func (r *RedisAdmissionHealthRepository) GetAdmissionHealth(ctx context.Context, eventID string, window time.Duration) (*AdmissionHealth, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.admission.get_health")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	if window > admissionBucketTTL {
+		window = admissionBucketTTL
+	}
+	buckets := int(window / admissionBucket)
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	now := r.now()
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, buckets)
+	for i := range cmds {
+		cmds[i] = pipe.HGetAll(ctx, r.bucketKey(eventID, now.Add(-time.Duration(i)*admissionBucket)))
+	}
+	zones := pipe.SMembers(ctx, r.zonesKey(eventID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get admission health: %w", err)
+	}
+
+	health := &AdmissionHealth{ZoneIDs: zones.Val()}
+	for _, cmd := range cmds {
+		fields := cmd.Val()
+		health.Reserved += parseCounter(fields["reserved"])
+		health.Failed += parseCounter(fields["failed"])
+		health.Confirmed += parseCounter(fields["confirmed"])
+		health.Latency += time.Duration(parseCounter(fields["latency_us"])) * time.Microsecond
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return health, nil
+}
+
+// SetAdmissionRate stores an event's computed release rate
+func (r *RedisAdmissionHealthRepository) SetAdmissionRate(ctx context.Context, eventID string, rate *AdmissionRate) error {
+	key := r.rateKey(eventID)
+
+	pipe := r.client.Pipeline()
+	pipe.HSet(ctx, key,
+		"rate", rate.Rate,
+		"reason", rate.Reason,
+		"updated_at", rate.UpdatedAt.Unix(),
+	)
+	pipe.Expire(ctx, key, admissionRateTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set admission rate: %w", err)
+	}
+	return nil
+}
+
+// GetAdmissionRate returns an event's computed release rate
+func (r *RedisAdmissionHealthRepository) GetAdmissionRate(ctx context.Context, eventID string) (*AdmissionRate, error) {
+	fields, err := r.client.HGetAll(ctx, r.rateKey(eventID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admission rate: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return &AdmissionRate{
+		Rate:      parseCounter(fields["rate"]),
+		Reason:    fields["reason"],
+		UpdatedAt: time.Unix(parseCounter(fields["updated_at"]), 0),
+	}, nil
+}
+
+// RecordRelease records released users in the current bucket
+func (r *RedisAdmissionHealthRepository) RecordRelease(ctx context.Context, eventID, lane string, count int) error {
+	fields := []string{"released"}
+	if lane != "" {
+		fields = append(fields, releasedField(lane))
+	}
+	return r.incrBucket(ctx, eventID, int64(count), fields...)
+}
+
+// releasedField returns the bucket counter of a lane's releases
+func releasedField(lane string) string {
+	if lane == "" {
+		return "released"
+	}
+	return "released:" + lane
+}
+
+// RecordPassUsed records a spent queue pass in the current bucket
+func (r *RedisAdmissionHealthRepository) RecordPassUsed(ctx context.Context, eventID string) error {
+	return r.incrBucket(ctx, eventID, 1, "passes_used")
+}
+
+// incrBucket adds n to counters of the current bucket
+func (r *RedisAdmissionHealthRepository) incrBucket(ctx context.Context, eventID string, n int64, fields ...string) error {
+	key := r.bucketKey(eventID, r.now())
+
+	pipe := r.client.Pipeline()
+	for _, field := range fields {
+		pipe.HIncrBy(ctx, key, field, n)
+	}
+	pipe.Expire(ctx, key, admissionBucketTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record %s: %w", fields[0], err)
+	}
+	return nil
+}
+
+// GetQueueThroughput reads the complete buckets of the window in one pipeline
+func (r *RedisAdmissionHealthRepository) GetQueueThroughput(ctx context.Context, eventID, lane string, window time.Duration) (*QueueThroughput, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.admission.get_throughput")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", eventID),
+		attribute.String("lane", lane),
+	)
+
+	// The oldest bucket may already have expired at the TTL
+	if window > admissionBucketTTL-admissionBucket {
+		window = admissionBucketTTL - admissionBucket
+	}
+	buckets := int(window / admissionBucket)
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	now := r.now()
+	pipe := r.client.Pipeline()
+	released := make([]*redis.StringCmd, buckets)
+	used := make([]*redis.StringCmd, buckets)
+	for i := range released {
+		key := r.bucketKey(eventID, now.Add(-time.Duration(i+1)*admissionBucket))
+		released[i] = pipe.HGet(ctx, key, releasedField(lane))
+		used[i] = pipe.HGet(ctx, key, "passes_used")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get queue throughput: %w", err)
+	}
+
+	throughput := &QueueThroughput{
+		Bucket:     admissionBucket,
+		Released:   make([]int64, buckets),
+		PassesUsed: make([]int64, buckets),
+	}
+	for i := range released {
+		throughput.Released[i] = parseCounter(released[i].Val())
+		throughput.PassesUsed[i] = parseCounter(used[i].Val())
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return throughput, nil
+}
+
+// parseCounter parses a counter field; missing or malformed fields count as 0
+func parseCounter(value string) int64 {
+	n, _ := strconv.ParseInt(value, 10, 64)
+	return n
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+func TestRedisAdmissionHealthRepository(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	repo := NewRedisAdmissionHealthRepository(client)
+	ctx := context.Background()
+
+	now := time.Unix(1_700_000_000, 0)
+	repo.now = func() time.Time { return now }
+
+	record := func(zoneID string, success bool, latency time.Duration) {
+		t.Helper()
+		if err := repo.RecordReserve(ctx, "event-1", zoneID, success, latency); err != nil {
+			t.Fatalf("RecordReserve() error = %v", err)
+		}
+	}
+
+	// An old bucket that falls out of a one minute window
+	record("zone-old", true, time.Second)
+	now = now.Add(2 * time.Minute)
+
+	record("zone-1", true, 100*time.Millisecond)
+	record("zone-2", true, 300*time.Millisecond)
+	record("zone-1", false, 200*time.Millisecond)
+	now = now.Add(admissionBucket)
+	if err := repo.RecordConfirm(ctx, "event-1"); err != nil {
+		t.Fatalf("RecordConfirm() error = %v", err)
+	}
+
+	health, err := repo.GetAdmissionHealth(ctx, "event-1", time.Minute)
+	if err != nil {
+		t.Fatalf("GetAdmissionHealth() error = %v", err)
+	}
+	if health.Reserved != 2 || health.Failed != 1 || health.Confirmed != 1 {
+		t.Errorf("health = %+v, want 2 reserved, 1 failed, 1 confirmed", health)
+	}
+	if health.AvgLatency() != 200*time.Millisecond || health.PaymentBacklog() != 1 {
+		t.Errorf("latency = %v, backlog = %d, want 200ms and 1", health.AvgLatency(), health.PaymentBacklog())
+	}
+	// Zones are remembered beyond the window; failed attempts add none
+	sort.Strings(health.ZoneIDs)
+	if len(health.ZoneIDs) != 3 || health.ZoneIDs[0] != "zone-1" || health.ZoneIDs[2] != "zone-old" {
+		t.Errorf("zones = %v, want zone-1, zone-2 and zone-old", health.ZoneIDs)
+	}
+
+	if other, err := repo.GetAdmissionHealth(ctx, "event-2", time.Minute); err != nil || other.Attempts() != 0 || other.SuccessRate() != 1 {
+		t.Errorf("GetAdmissionHealth() other event = %+v, %v, want empty", other, err)
+	}
+
+	if rate, err := repo.GetAdmissionRate(ctx, "event-1"); err != nil || rate != nil {
+		t.Fatalf("GetAdmissionRate() = %+v, %v, want none", rate, err)
+	}
+	if err := repo.SetAdmissionRate(ctx, "event-1", &AdmissionRate{Rate: 40, Reason: "latency", UpdatedAt: now}); err != nil {
+		t.Fatalf("SetAdmissionRate() error = %v", err)
+	}
+	rate, err := repo.GetAdmissionRate(ctx, "event-1")
+	if err != nil || rate.Rate != 40 || rate.Reason != "latency" || !rate.UpdatedAt.Equal(now) {
+		t.Errorf("GetAdmissionRate() = %+v, %v, want 40 for latency", rate, err)
+	}
+}
+
+func TestRedisAdmissionHealthRepository_QueueThroughput(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	repo := NewRedisAdmissionHealthRepository(client)
+	ctx := context.Background()
+
+	now := time.Unix(1_700_000_000, 0)
+	repo.now = func() time.Time { return now }
+
+	if err := repo.RecordRelease(ctx, "event-1", "", 20); err != nil {
+		t.Fatalf("RecordRelease() error = %v", err)
+	}
+	now = now.Add(admissionBucket)
+	if err := repo.RecordRelease(ctx, "event-1", "vip", 5); err != nil {
+		t.Fatalf("RecordRelease() error = %v", err)
+	}
+	if err := repo.RecordPassUsed(ctx, "event-1"); err != nil {
+		t.Fatalf("RecordPassUsed() error = %v", err)
+	}
+	now = now.Add(admissionBucket)
+	// The current bucket is still filling and is not read
+	if err := repo.RecordRelease(ctx, "event-1", "", 7); err != nil {
+		t.Fatalf("RecordRelease() error = %v", err)
+	}
+
+	throughput, err := repo.GetQueueThroughput(ctx, "event-1", "", 3*admissionBucket)
+	if err != nil {
+		t.Fatalf("GetQueueThroughput() error = %v", err)
+	}
+	if throughput.Bucket != admissionBucket || len(throughput.Released) != 3 {
+		t.Fatalf("throughput = %+v, want 3 buckets of %v", throughput, admissionBucket)
+	}
+	wantReleased, wantUsed := []int64{5, 20, 0}, []int64{1, 0, 0}
+	for i := range wantReleased {
+		if throughput.Released[i] != wantReleased[i] || throughput.PassesUsed[i] != wantUsed[i] {
+			t.Errorf("throughput = %v released, %v used, want %v and %v", throughput.Released, throughput.PassesUsed, wantReleased, wantUsed)
+			break
+		}
+	}
+
+	// A lane counts only its own releases
+	vip, err := repo.GetQueueThroughput(ctx, "event-1", "vip", 3*admissionBucket)
+	if err != nil {
+		t.Fatalf("GetQueueThroughput() error = %v", err)
+	}
+	if vip.Released[0] != 5 || vip.Released[1] != 0 || vip.PassesUsed[0] != 1 {
+		t.Errorf("vip throughput = %v released, %v used, want 5 then 0 released", vip.Released, vip.PassesUsed)
+	}
+
+	// A window beyond the bucket TTL is capped
+	long, err := repo.GetQueueThroughput(ctx, "event-1", "", time.Hour)
+	if err != nil {
+		t.Fatalf("GetQueueThroughput() error = %v", err)
+	}
+	if want := int((admissionBucketTTL - admissionBucket) / admissionBucket); len(long.Released) != want {
+		t.Errorf("GetQueueThroughput() long window = %d buckets, want %d", len(long.Released), want)
+	}
+}
@@ -47,3 +47,14 @@ type BookingRepository interface {
 	// GetTenantIDByShowID retrieves tenant_id from shows table
 	GetTenantIDByShowID(ctx context.Context, showID string) (string, error)
 }
+
+// CompBookingStore writes complimentary bookings. It is kept apart from
+// BookingRepository so only the comp service depends on it.
+type CompBookingStore interface {
+	// CreateComps inserts confirmed comp bookings in one transaction and calls
+	// beforeCommit before committing; nothing is written if it fails
+	CreateComps(ctx context.Context, bookings []*domain.Booking, beforeCommit func(ctx context.Context) error) error
+
+	// GetTenantIDByShowID retrieves tenant_id from shows table
+	GetTenantIDByShowID(ctx context.Context, showID string) (string, error)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Fallback reservation statuses and origins
+const (
+	FallbackStatusReserved  = "reserved"
+	FallbackStatusConfirmed = "confirmed"
+	FallbackStatusReleased  = "released"
+
+	// FallbackOriginFallback marks a reservation made in Postgres
+	FallbackOriginFallback = "fallback"
+	// FallbackOriginRedis marks a Redis reservation confirmed or released in Postgres
+	FallbackOriginRedis = "redis"
+)
+
+// FallbackReservation is a reservation reserved, confirmed or released while
+// its zone was served from Postgres
+type FallbackReservation struct {
+	BookingID string
+	ZoneID    string
+	UserID    string
+	EventID   string
+	Quantity  int64
+	UnitPrice float64
+	Status    string
+	Origin    string
+	PaymentID string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// FallbackReservationStore serves reservations from Postgres while Redis is
+// unavailable. A zone must be activated before it is served; activation seeds
+// its seat counter from the booking rows, which the Redis path writes too.
+type FallbackReservationStore interface {
+	// ActivateZone starts serving a zone with sellableSeats minus its held and sold
+	// bookings; it does nothing if the zone is already active
+	ActivateZone(ctx context.Context, zoneID string, sellableSeats int64) error
+
+	// ReserveSeats takes seats from an active zone; ErrorCode is
+	// ZONE_NOT_ACTIVE when the zone is not active
+	ReserveSeats(ctx context.Context, params ReserveParams) (*ReserveResult, error)
+
+	// ConfirmBooking confirms a reservation of an active zone
+	ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*ConfirmResult, error)
+
+	// ReleaseSeats returns a reservation's seats to an active zone
+	ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*ReleaseResult, error)
+
+	// GetZoneAvailabilities returns the available seats of the given active zones
+	GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error)
+
+	// ReleaseExpired returns the seats of Postgres reservations past their
+	// deadline and reports how many seats were returned
+	ReleaseExpired(ctx context.Context, now time.Time) (int64, error)
+
+	// ListActiveZones returns the zones currently served from Postgres
+	ListActiveZones(ctx context.Context) ([]string, error)
+
+	// ResyncZone locks an active zone, passes its available seats and the
+	// reservations to carry over to apply, and deactivates the zone once apply
+	// succeeds. Reservations still held in Postgres come first with status
+	// reserved; Redis reservations confirmed or released during the outage
+	// follow. It does nothing if the zone is not active.
+	ResyncZone(ctx context.Context, zoneID string, apply func(ctx context.Context, available int64, reservations []FallbackReservation) error) error
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// maxIdentityClaims is the number of identities identity_claim.lua checks in one call
+const maxIdentityClaims = 4
+
+// IdentityClaim charges a booking's seats to one identity of the buyer
+type IdentityClaim struct {
+	Kind  string // phone, card, device or ip_subnet
+	Value string // Fingerprint of the identity, never the raw value
+	Max   int    // Seats the identity may hold for the event (0 = unlimited)
+}
+
+// IdentityClaimResult represents the result of charging seats to identities
+type IdentityClaimResult struct {
+	Success bool
+	// AlreadyClaimed is set when the claim existed and nothing was charged
+	AlreadyClaimed bool
+	// Exceeded is the claim that would go over its limit when Success is false
+	Exceeded *IdentityClaim
+	// Current is the seats the exceeded identity already holds
+	Current int64
+}
+
+// IdentityLimitStore counts seats per buyer identity so that purchase limits
+// hold across accounts that share a phone, card, device or network
+type IdentityLimitStore interface {
+	// ClaimIdentities atomically charges quantity seats to every claim, or to
+	// none of them if one would exceed its limit. claimID names the charge
+	// so it can be released; claiming the same ID again charges nothing.
+	ClaimIdentities(ctx context.Context, eventID, claimID string, quantity int, claims []IdentityClaim, window time.Duration) (*IdentityClaimResult, error)
+
+	// ReleaseIdentities gives back the seats charged under claimIDs
+	ReleaseIdentities(ctx context.Context, eventID string, claimIDs ...string) error
+
+	// GetIdentityLimits returns an event's per-kind limit overrides
+	GetIdentityLimits(ctx context.Context, eventID string) (map[string]int, error)
+
+	// SetIdentityLimits replaces an event's per-kind limit overrides; kinds
+	// left out fall back to the configured defaults
+	SetIdentityLimits(ctx context.Context, eventID string, limits map[string]int) error
+}
+
+// PaymentClaimID returns the claim ID of the identities charged when a
+// booking is paid, alongside those charged when it was reserved
+func PaymentClaimID(bookingID string) string {
+	return bookingID + ":payment"
+}
+
+// WithIdentityLimits releases a booking's identity claims whenever its
+// reservation is released or expires, so cancelled holds stop counting
+// against the buyer's identities.
+func (r *RedisReservationRepository) WithIdentityLimits(enabled bool) *RedisReservationRepository {
+	r.identityLimits = enabled
+	return r
+}
+
+// ClaimIdentities charges seats to a booking's identities through identity_claim.lua
+func (r *RedisReservationRepository) ClaimIdentities(ctx context.Context, eventID, claimID string, quantity int, claims []IdentityClaim, window time.Duration) (*IdentityClaimResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.claim_identities")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_id", eventID),
+		attribute.String("claim_id", claimID),
+		attribute.Int("identities", len(claims)),
+	)
+
+	if len(claims) == 0 {
+		return &IdentityClaimResult{Success: true}, nil
+	}
+	if len(claims) > maxIdentityClaims {
+		return nil, fmt.Errorf("too many identity claims: %d (max %d)", len(claims), maxIdentityClaims)
+	}
+
+	keys := []string{r.keys.identityClaim(eventID, claimID)}
+	args := []interface{}{quantity, int(window.Seconds())}
+	for _, claim := range claims {
+		keys = append(keys, r.keys.identityTally(eventID, claim.Kind, claim.Value))
+		args = append(args, claim.Max)
+	}
+
+	values, err := r.client.Scripts().Run(ctx, scriptIdentityClaim, keys, args...).Slice()
+	if err != nil {
+		err = fmt.Errorf("failed to execute identity_claim script: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if len(values) < 2 {
+		return nil, fmt.Errorf("unexpected identity_claim result length: %d", len(values))
+	}
+
+	if success, _ := toInt64(values[0]); success == 1 {
+		return &IdentityClaimResult{Success: true}, nil
+	}
+	errorCode, _ := values[1].(string)
+	switch errorCode {
+	case "ALREADY_CLAIMED":
+		return &IdentityClaimResult{Success: true, AlreadyClaimed: true}, nil
+	case "IDENTITY_LIMIT_EXCEEDED":
+		if len(values) < 5 {
+			return nil, fmt.Errorf("unexpected identity_claim result length: %d", len(values))
+		}
+		index, _ := toInt64(values[3])
+		current, _ := toInt64(values[4])
+		if index < 1 || int(index) > len(claims) {
+			return nil, fmt.Errorf("identity_claim returned identity %d of %d", index, len(claims))
+		}
+		exceeded := claims[index-1]
+		span.SetStatus(codes.Error, errorCode)
+		return &IdentityClaimResult{Exceeded: &exceeded, Current: current}, nil
+	default:
+		message, _ := values[2].(string)
+		return nil, fmt.Errorf("identity_claim failed: %s: %s", errorCode, message)
+	}
+}
+
+// ReleaseIdentities gives back seats through identity_release.lua
+func (r *RedisReservationRepository) ReleaseIdentities(ctx context.Context, eventID string, claimIDs ...string) error {
+	if len(claimIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, len(claimIDs))
+	for i, claimID := range claimIDs {
+		keys[i] = r.keys.identityClaim(eventID, claimID)
+	}
+	if err := r.client.Scripts().Run(ctx, scriptIdentityRelease, keys).Err(); err != nil {
+		return fmt.Errorf("failed to execute identity_release script: %w", err)
+	}
+	return nil
+}
+
+// releaseBookingIdentities releases the identities a booking was charged to
+// once its reservation is gone. A failed release only holds the identities'
+// seats until the limit window ends, so it is recorded but not returned.
+func (r *RedisReservationRepository) releaseBookingIdentities(ctx context.Context, eventID, bookingID string) {
+	if !r.identityLimits || eventID == "" {
+		return
+	}
+	if err := r.ReleaseIdentities(ctx, eventID, bookingID, PaymentClaimID(bookingID)); err != nil {
+		telemetry.SpanFromContext(ctx).RecordError(err)
+	}
+}
+
+// GetIdentityLimits returns an event's per-kind limit overrides
+func (r *RedisReservationRepository) GetIdentityLimits(ctx context.Context, eventID string) (map[string]int, error) {
+	fields, err := r.client.HGetAll(ctx, r.keys.identityLimits(eventID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity limits: %w", err)
+	}
+	limits := make(map[string]int, len(fields))
+	for kind, value := range fields {
+		max, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		limits[kind] = max
+	}
+	return limits, nil
+}
+
+// SetIdentityLimits replaces an event's per-kind limit overrides
+func (r *RedisReservationRepository) SetIdentityLimits(ctx context.Context, eventID string, limits map[string]int) error {
+	key := r.keys.identityLimits(eventID)
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(limits) > 0 {
+		fields := make(map[string]interface{}, len(limits))
+		for kind, max := range limits {
+			fields[kind] = max
+		}
+		pipe.HSet(ctx, key, fields)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set identity limits: %w", err)
+	}
+	return nil
+}
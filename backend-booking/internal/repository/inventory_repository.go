@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// HeldSeats is a booking that holds seats without having sold them
+type HeldSeats struct {
+	BookingID string
+	ZoneID    string
+	Quantity  int64
+}
+
+// BookingInventoryReader reads the booking rows that consume zone inventory.
+// It is kept apart from BookingRepository so the reconciler can depend on
+// these reads alone.
+type BookingInventoryReader interface {
+	// ListHeldSeats returns bookings still in reserved status for the given zones
+	ListHeldSeats(ctx context.Context, zoneIDs []string) ([]HeldSeats, error)
+
+	// SumSoldSeats returns the confirmed seat count per zone for the given zones
+	SumSoldSeats(ctx context.Context, zoneIDs []string) (map[string]int64, error)
+}
+
+// ZoneInventoryStore reads and adjusts live zone inventory in Redis
+type ZoneInventoryStore interface {
+	// ScanHeldSeats returns every reservation hash still in reserved status
+	ScanHeldSeats(ctx context.Context) ([]HeldSeats, error)
+
+	// GetZoneAvailabilities returns the available seats of the given zones;
+	// zones without an availability key are omitted
+	GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error)
+
+	// AdjustZoneAvailability adds delta to a zone's available seats and returns
+	// the new value; on a sharded zone a negative delta is taken from the pool
+	// and the shards, and no counter goes below zero
+	AdjustZoneAvailability(ctx context.Context, zoneID string, delta int64) (int64, error)
+
+	// RecoverShardTransfers puts back seats of shard transfers that stopped
+	// halfway and returns how many seats it put back
+	RecoverShardTransfers(ctx context.Context, zoneIDs []string) (int64, error)
+
+	// AcquireReconcileLock takes the cluster-wide reconciliation lock for at
+	// most ttl and returns its owner token ("" = held by another replica)
+	AcquireReconcileLock(ctx context.Context, ttl time.Duration) (string, error)
+
+	// ReleaseReconcileLock releases the reconciliation lock taken under token
+	ReleaseReconcileLock(ctx context.Context, token string) error
+}
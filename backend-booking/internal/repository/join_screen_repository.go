@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// JoinScreenStore counts the signals queue joins are screened for bots on.
+// Sources and devices are fingerprints, never raw addresses or IDs.
+type JoinScreenStore interface {
+	// CountJoin counts a join of an event's queue from one source (kind is
+	// ip or asn) and returns the joins from it in the current window
+	CountJoin(ctx context.Context, eventID, kind, source string, window time.Duration) (int64, error)
+
+	// AddDeviceUser records that a user joined an event's queue from a
+	// device and returns how many users have joined from it
+	AddDeviceUser(ctx context.Context, eventID, device, userID string, ttl time.Duration) (int64, error)
+}
+
+// joinCountKey returns the counter of an event's joins from one source
+func (r *RedisQueueRepository) joinCountKey(eventID, kind, source string) string {
+	return fmt.Sprintf("queue:screen:%s:%s:%s", r.client.ClusterTag(eventID), kind, source)
+}
+
+// deviceUsersKey returns the set of users who joined an event's queue from a device
+func (r *RedisQueueRepository) deviceUsersKey(eventID, device string) string {
+	return fmt.Sprintf("queue:screen:%s:device:%s", r.client.ClusterTag(eventID), device)
+}
+
+// CountJoin counts a join in a fixed window that starts with the first join
+func (r *RedisQueueRepository) CountJoin(ctx context.Context, eventID, kind, source string, window time.Duration) (int64, error) {
+	key := r.joinCountKey(eventID, kind, source)
+	pipe := r.client.Pipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count join: %w", err)
+	}
+	return count.Val(), nil
+}
+
+// AddDeviceUser adds a user to a device's set, which lives ttl from the
+// device's first join
+func (r *RedisQueueRepository) AddDeviceUser(ctx context.Context, eventID, device, userID string, ttl time.Duration) (int64, error) {
+	key := r.deviceUsersKey(eventID, device)
+	pipe := r.client.Pipeline()
+	pipe.SAdd(ctx, key, userID)
+	users := pipe.SCard(ctx, key)
+	pipe.ExpireNX(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to record device user: %w", err)
+	}
+	return users.Val(), nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	_ "embed"
+
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+//go:embed scripts/reserve_seats.lua
+var reserveSeatsScript string
+
+//go:embed scripts/release_seats.lua
+var releaseSeatsScript string
+
+//go:embed scripts/confirm_booking.lua
+var confirmBookingScript string
+
+//go:embed scripts/user_tally.lua
+var userTallyScript string
+
+//go:embed scripts/shard_transfer.lua
+var shardTransferScript string
+
+//go:embed scripts/shard_transfer_out.lua
+var shardTransferOutScript string
+
+//go:embed scripts/shard_transfer_in.lua
+var shardTransferInScript string
+
+//go:embed scripts/identity_claim.lua
+var identityClaimScript string
+
+//go:embed scripts/identity_release.lua
+var identityReleaseScript string
+
+//go:embed scripts/queue_pass_spend.lua
+var queuePassSpendScript string
+
+//go:embed scripts/lock_release.lua
+var lockReleaseScript string
+
+//go:embed scripts/adjust_zone_availability.lua
+var adjustZoneAvailabilityScript string
+
+//go:embed scripts/join_queue.lua
+var joinQueueScript string
+
+//go:embed scripts/lottery_draw.lua
+var lotteryDrawScript string
+
+// Script names for caching
+const (
+	scriptReserveSeats     = "reserve_seats"
+	scriptReleaseSeats     = "release_seats"
+	scriptConfirmBooking   = "confirm_booking"
+	scriptUserTally        = "user_tally"
+	scriptShardTransfer    = "shard_transfer"
+	scriptShardTransferOut = "shard_transfer_out"
+	scriptShardTransferIn  = "shard_transfer_in"
+	scriptIdentityClaim    = "identity_claim"
+	scriptIdentityRelease  = "identity_release"
+	scriptQueuePassSpend   = "queue_pass_spend"
+	scriptLockRelease      = "lock_release"
+	scriptAdjustZone       = "adjust_zone_availability"
+	scriptJoinQueue        = "join_queue"
+	scriptLotteryDraw      = "lottery_draw"
+)
+
+// reservationScripts are the scripts RedisReservationRepository runs. The
+// SHAs pin the embedded bodies: editing a script means updating its pin, and
+// changing its KEYS or ARGV layout means bumping its version as well.
+var reservationScripts = []pkgredis.ScriptSpec{
+	{
+		Name:    scriptReserveSeats,
+		Version: 2,
+		Source:  reserveSeatsScript,
+		Keys:    5,
+		Args:    []string{"quantity", "max_per_user", "user_id", "booking_id", "zone_id", "event_id", "show_id", "unit_price", "ttl_seconds", "pass_id", "binding"},
+		SHA:     "fdc1591f9fea04ea4e9dcc56fa7dbf9e43b81e98",
+	},
+	{
+		Name:    scriptReleaseSeats,
+		Version: 2,
+		Source:  releaseSeatsScript,
+		Keys:    5,
+		Args:    []string{"booking_id", "user_id", "due_by", "stream_max_len"},
+		SHA:     "442e594656f29bad56c1bc30ad64f1aa66087ca3",
+	},
+	{
+		Name:    scriptConfirmBooking,
+		Version: 1,
+		Source:  confirmBookingScript,
+		Keys:    2,
+		Args:    []string{"booking_id", "user_id", "payment_id"},
+		SHA:     "d90e40a438212565b7171a61596182d6487a0e3b",
+	},
+	{
+		Name:    scriptUserTally,
+		Version: 1,
+		Source:  userTallyScript,
+		Keys:    1,
+		Args:    []string{"delta", "max_per_user", "ttl_seconds"},
+		SHA:     "aff251414b6c5ec100ad618d6c0c36b71e545a0c",
+	},
+	{
+		Name:    scriptShardTransfer,
+		Version: 2,
+		Source:  shardTransferScript,
+		Keys:    2,
+		Args:    []string{"needed", "divisor"},
+		SHA:     "cbfa16bb9e66e681f87e391e65a1d6e0b8f7d87c",
+	},
+	{
+		Name:    scriptShardTransferOut,
+		Version: 1,
+		Source:  shardTransferOutScript,
+		Keys:    2,
+		Args:    []string{"needed", "divisor", "transfer_id", "target", "now_ms"},
+		SHA:     "9e505752070673a3d0a320be90014858f1d8b093",
+	},
+	{
+		Name:    scriptShardTransferIn,
+		Version: 1,
+		Source:  shardTransferInScript,
+		Keys:    2,
+		Args:    []string{"seats", "marker_ttl_ms"},
+		SHA:     "ef31f7b133b006f8ab310f4ac402436cf902718b",
+	},
+	{
+		Name:    scriptIdentityClaim,
+		Version: 1,
+		Source:  identityClaimScript,
+		Keys:    5,
+		Args:    []string{"quantity", "ttl_seconds", "max_1", "max_2", "max_3", "max_4"},
+		SHA:     "ee460ef3287c171e856740973006c361dd64b2ec",
+	},
+	{
+		Name:    scriptIdentityRelease,
+		Version: 1,
+		Source:  identityReleaseScript,
+		Keys:    2,
+		SHA:     "c2eb75c458ba54009ba2de680796b911899e9c83",
+	},
+	{
+		Name:    scriptQueuePassSpend,
+		Version: 1,
+		Source:  queuePassSpendScript,
+		Keys:    1,
+		Args:    []string{"pass_id", "quantity", "binding"},
+		SHA:     "8126bb72338b5adb830b5780a1f797ff0487c4b5",
+	},
+	{
+		Name:    scriptLockRelease,
+		Version: 1,
+		Source:  lockReleaseScript,
+		Keys:    1,
+		Args:    []string{"token"},
+		SHA:     "31552a7d031c49548cbfd00350e338a842b12577",
+	},
+	{
+		Name:    scriptAdjustZone,
+		Version: 1,
+		Source:  adjustZoneAvailabilityScript,
+		Keys:    1,
+		Args:    []string{"delta"},
+		SHA:     "e2ac555896afb5bf29cc52bfe2f751c3cd30bca4",
+	},
+}
+
+// queueScripts are the scripts RedisQueueRepository runs
+var queueScripts = []pkgredis.ScriptSpec{
+	{
+		Name:    scriptJoinQueue,
+		Version: 4,
+		Source:  joinQueueScript,
+		Keys:    9,
+		Args:    []string{"user_id", "event_id", "token", "ttl_seconds", "max_queue_size", "opens_at", "lane", "lane_index"},
+		SHA:     "fa80b3f83e4b01d45e1273760b8fa5a0e24a2dae",
+	},
+	{
+		Name:    scriptLotteryDraw,
+		Version: 1,
+		Source:  lotteryDrawScript,
+		Keys:    2,
+		Args:    []string{"opens_at", "seed"},
+		SHA:     "36e2435b8fe76af63eb63786ee01036e56e6a469",
+	},
+}
+
+// scriptNames returns the names of specs
+func scriptNames(specs []pkgredis.ScriptSpec) []string {
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+	}
+	return names
+}
@@ -0,0 +1,696 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+// scriptSpec returns the registered spec of a booking script
+func scriptSpec(t *testing.T, name string) pkgredis.ScriptSpec {
+	t.Helper()
+	for _, spec := range append(append([]pkgredis.ScriptSpec{}, reservationScripts...), queueScripts...) {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	t.Fatalf("script %s is not declared", name)
+	return pkgredis.ScriptSpec{}
+}
+
+// seedReservation stores a reservation hash with its deadline
+func seedReservation(tb testing.TB, mr *miniredis.Miniredis, status string, deadline float64) {
+	tb.Helper()
+	mr.HSet("reservation:b1",
+		"booking_id", "b1",
+		"user_id", "u1",
+		"zone_id", "z1",
+		"event_id", "e1",
+		"quantity", "2",
+		"status", status,
+	)
+	if _, err := mr.ZAdd("expiry:reservations", deadline, "reservation:b1"); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// passBudgetKey is the budget of user u1's queue pass p1 for event e1
+var passBudgetKey = queuePassBudgetKey("e1", "u1")
+
+// seedPassBudget stores a budget of two reservations for pass p1, with a
+// seat cap and binding unless empty
+func seedPassBudget(mr *miniredis.Miniredis, seats, binding string) {
+	mr.HSet(passBudgetKey, "pass_id", "p1", "reservations", "2")
+	if seats != "" {
+		mr.HSet(passBudgetKey, "seats", seats)
+	}
+	if binding != "" {
+		mr.HSet(passBudgetKey, "binding", binding)
+	}
+}
+
+// wantString asserts a key's string value
+func wantString(tb testing.TB, mr *miniredis.Miniredis, key, want string) {
+	tb.Helper()
+	if got, _ := mr.Get(key); got != want {
+		tb.Errorf("%s = %q, want %q", key, got, want)
+	}
+}
+
+func TestLuaScripts_Declarations(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	if err := client.Scripts().Register(reservationScripts...); err != nil {
+		t.Fatalf("reservation scripts: %v", err)
+	}
+	if err := client.Scripts().Register(queueScripts...); err != nil {
+		t.Fatalf("queue scripts: %v", err)
+	}
+}
+
+func TestLuaScript_ReserveSeats(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:z1", "reservation:b1", "expiry:reservations", "user:reservations:u1:e1"}
+	args := func(quantity, maxPerUser int) []interface{} {
+		return []interface{}{quantity, maxPerUser, "u1", "b1", "z1", "e1", "s1", "100.00", 600}
+	}
+	zone := func(seats string) func(testing.TB, *miniredis.Miniredis) {
+		return func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:availability:z1", seats) }
+	}
+	passKeys := append(append([]string{}, keys...), passBudgetKey)
+	passArgs := func(quantity int, passID, binding string) []interface{} {
+		return append(args(quantity, 4), passID, binding)
+	}
+	withPass := func(seats, binding string) func(testing.TB, *miniredis.Miniredis) {
+		return func(tb testing.TB, mr *miniredis.Miniredis) {
+			zone("10")(tb, mr)
+			seedPassBudget(mr, seats, binding)
+		}
+	}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptReserveSeats), []redistest.ScriptCase{
+		{
+			Name:  "reserves",
+			Setup: zone("10"),
+			Keys:  keys,
+			Args:  args(3, 4),
+			Want:  []interface{}{int64(1), int64(7), int64(3)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "user:reservations:u1:e1", "3")
+				if status := mr.HGet("reservation:b1", "status"); status != "reserved" {
+					tb.Errorf("status = %q, want reserved", status)
+				}
+				if ok, _ := mr.ZMembers("expiry:reservations"); len(ok) != 1 {
+					tb.Errorf("deadline index = %v, want the reservation", ok)
+				}
+			},
+		},
+		{
+			Name:  "cluster mode without user key",
+			Setup: zone("10"),
+			Keys:  keys[:3],
+			Args:  args(3, 1),
+			Want:  []interface{}{int64(1), int64(7), int64(0)},
+		},
+		{Name: "zero quantity", Setup: zone("10"), Keys: keys, Args: args(0, 4), WantCode: "INVALID_QUANTITY"},
+		{Name: "missing zone", Keys: keys, Args: args(1, 4), WantCode: "ZONE_NOT_FOUND"},
+		{Name: "sold out", Setup: zone("2"), Keys: keys, Args: args(3, 4), WantCode: "INSUFFICIENT_STOCK",
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "zone:availability:z1", "2")
+			}},
+		{
+			Name: "over user limit",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				zone("10")(tb, mr)
+				mr.Set("user:reservations:u1:e1", "3")
+			},
+			Keys:     keys,
+			Args:     args(2, 4),
+			WantCode: "USER_LIMIT_EXCEEDED",
+		},
+		{
+			Name:  "spends the queue pass",
+			Setup: withPass("3", ""),
+			Keys:  passKeys,
+			Args:  passArgs(2, "p1", "device-1"),
+			Want:  []interface{}{int64(1), int64(8), int64(2), int64(1)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if got := mr.HGet(passBudgetKey, "seats"); got != "1" {
+					tb.Errorf("seats left = %q, want 1", got)
+				}
+				if got := mr.HGet(passBudgetKey, "binding"); got != "device-1" {
+					tb.Errorf("binding = %q, want device-1", got)
+				}
+			},
+		},
+		{Name: "pass used up by its seats", Setup: withPass("2", ""), Keys: passKeys, Args: passArgs(2, "p1", ""),
+			Want: []interface{}{int64(1), int64(8), int64(2), int64(0)}},
+		{Name: "other pass", Setup: withPass("", ""), Keys: passKeys, Args: passArgs(1, "p2", ""), WantCode: "QUEUE_PASS_NOT_FOUND"},
+		{Name: "too many seats for the pass", Setup: withPass("1", ""), Keys: passKeys, Args: passArgs(2, "p1", ""), WantCode: "QUEUE_PASS_EXHAUSTED",
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "zone:availability:z1", "10")
+			}},
+		{Name: "pass bound to another device", Setup: withPass("", "device-1"), Keys: passKeys, Args: passArgs(1, "p1", "device-2"),
+			WantCode: "QUEUE_PASS_DEVICE_MISMATCH"},
+		{Name: "too few keys", Keys: keys[:2], Args: args(1, 4), WantErr: true},
+		{Name: "too few arguments", Keys: keys, Args: args(1, 4)[:8], WantErr: true},
+	})
+}
+
+func TestLuaScript_ReleaseSeats(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:z1", "reservation:b1", "expiry:reservations", "user:reservations:u1:e1"}
+	reserved := func(tb testing.TB, mr *miniredis.Miniredis) {
+		mr.Set("zone:availability:z1", "5")
+		mr.Set("user:reservations:u1:e1", "2")
+		seedReservation(tb, mr, "reserved", 1000)
+	}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptReleaseSeats), []redistest.ScriptCase{
+		{
+			Name:  "releases",
+			Setup: reserved,
+			Keys:  keys,
+			Args:  []interface{}{"b1", "u1"},
+			Want:  []interface{}{int64(1), int64(7), int64(0)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if mr.Exists("reservation:b1") || mr.Exists("user:reservations:u1:e1") {
+					tb.Error("reservation or user count left behind")
+				}
+			},
+		},
+		{Name: "expires when due", Setup: reserved, Keys: keys, Args: []interface{}{"b1", "u1", 1000}},
+		{
+			Name:  "expires into the stream",
+			Setup: reserved,
+			Keys:  append(keys[:4:4], "stream:reservation:expired"),
+			Args:  []interface{}{"b1", "u1", 1000, 100},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				entries, err := mr.Stream("stream:reservation:expired")
+				if err != nil || len(entries) != 1 {
+					tb.Fatalf("stream = %v, %v, want one entry", entries, err)
+				}
+				want := []string{"booking_id", "b1", "zone_id", "z1", "user_id", "u1", "event_id", "e1", "quantity", "2", "expired_at", "1000"}
+				if got := entries[0].Values; strings.Join(got, ",") != strings.Join(want, ",") {
+					tb.Errorf("entry = %v, want %v", got, want)
+				}
+			},
+		},
+		{Name: "not due", Setup: reserved, Keys: keys, Args: []interface{}{"b1", "u1", 999}, WantCode: "NOT_DUE"},
+		{Name: "missing", Keys: keys, Args: []interface{}{"b1", "u1"}, WantCode: "RESERVATION_NOT_FOUND"},
+		{Name: "other booking", Setup: reserved, Keys: keys, Args: []interface{}{"b2", "u1"}, WantCode: "INVALID_BOOKING_ID"},
+		{Name: "other user", Setup: reserved, Keys: keys, Args: []interface{}{"b1", "u2"}, WantCode: "INVALID_USER_ID"},
+		{
+			Name:     "confirmed",
+			Setup:    func(tb testing.TB, mr *miniredis.Miniredis) { seedReservation(tb, mr, "confirmed", 1000) },
+			Keys:     keys,
+			Args:     []interface{}{"b1", "u1"},
+			WantCode: "ALREADY_RELEASED",
+		},
+		{
+			Name: "bad quantity",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				seedReservation(tb, mr, "reserved", 1000)
+				mr.HSet("reservation:b1", "quantity", "0")
+			},
+			Keys:     keys,
+			Args:     []interface{}{"b1", "u1"},
+			WantCode: "INVALID_QUANTITY",
+		},
+	})
+}
+
+func TestLuaScript_ConfirmBooking(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"reservation:b1", "expiry:reservations"}
+	seed := func(status string) func(testing.TB, *miniredis.Miniredis) {
+		return func(tb testing.TB, mr *miniredis.Miniredis) { seedReservation(tb, mr, status, 1000) }
+	}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptConfirmBooking), []redistest.ScriptCase{
+		{
+			Name:  "confirms",
+			Setup: seed("reserved"),
+			Keys:  keys,
+			Args:  []interface{}{"b1", "u1", "pay-1"},
+			Want:  []interface{}{int64(1), "CONFIRMED"},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if got := mr.HGet("reservation:b1", "payment_id"); got != "pay-1" {
+					tb.Errorf("payment_id = %q, want pay-1", got)
+				}
+				if members, _ := mr.ZMembers("expiry:reservations"); len(members) != 0 {
+					tb.Errorf("deadline index = %v, want empty", members)
+				}
+			},
+		},
+		{Name: "without payment", Setup: seed("reserved"), Keys: keys, Args: []interface{}{"b1", "u1"}},
+		{Name: "missing", Keys: keys, Args: []interface{}{"b1", "u1"}, WantCode: "RESERVATION_NOT_FOUND"},
+		{Name: "other booking", Setup: seed("reserved"), Keys: keys, Args: []interface{}{"b2", "u1"}, WantCode: "INVALID_BOOKING_ID"},
+		{Name: "other user", Setup: seed("reserved"), Keys: keys, Args: []interface{}{"b1", "u2"}, WantCode: "INVALID_USER_ID"},
+		{Name: "confirmed", Setup: seed("confirmed"), Keys: keys, Args: []interface{}{"b1", "u1"}, WantCode: "ALREADY_CONFIRMED"},
+		{Name: "released", Setup: seed("released"), Keys: keys, Args: []interface{}{"b1", "u1"}, WantCode: "INVALID_STATUS"},
+	})
+}
+
+func TestLuaScript_LockRelease(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"inventory:reconcile:lock"}
+	held := func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("inventory:reconcile:lock", "t1") }
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptLockRelease), []redistest.ScriptCase{
+		{
+			Name:  "releases",
+			Setup: held,
+			Keys:  keys,
+			Args:  []interface{}{"t1"},
+			Want:  []interface{}{int64(1), "RELEASED"},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if mr.Exists("inventory:reconcile:lock") {
+					tb.Error("lock left behind")
+				}
+			},
+		},
+		{
+			Name:     "other owner",
+			Setup:    held,
+			Keys:     keys,
+			Args:     []interface{}{"t2"},
+			WantCode: "LOCK_NOT_HELD",
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "inventory:reconcile:lock", "t1")
+			},
+		},
+		{Name: "expired", Keys: keys, Args: []interface{}{"t1"}, WantCode: "LOCK_NOT_HELD"},
+	})
+}
+
+func TestLuaScript_AdjustZoneAvailability(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:z1"}
+	stocked := func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:availability:z1", "10") }
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptAdjustZone), []redistest.ScriptCase{
+		{
+			Name:  "adds seats",
+			Setup: stocked,
+			Keys:  keys,
+			Args:  []interface{}{5},
+			Want:  []interface{}{int64(1), int64(5), int64(15)},
+		},
+		{
+			Name:  "takes seats",
+			Setup: stocked,
+			Keys:  keys,
+			Args:  []interface{}{-4},
+			Want:  []interface{}{int64(1), int64(-4), int64(6)},
+		},
+		{
+			Name:  "takes at most the seats held",
+			Setup: stocked,
+			Keys:  keys,
+			Args:  []interface{}{-25},
+			Want:  []interface{}{int64(1), int64(-10), int64(0)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "zone:availability:z1", "0")
+			},
+		},
+		{
+			Name:     "missing zone",
+			Keys:     keys,
+			Args:     []interface{}{5},
+			WantCode: "ZONE_NOT_FOUND",
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if mr.Exists("zone:availability:z1") {
+					tb.Error("zone initialized by a correction")
+				}
+			},
+		},
+	})
+}
+
+func TestLuaScript_UserTally(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"user:reservations:u1:e1"}
+	current := func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("user:reservations:u1:e1", "3") }
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptUserTally), []redistest.ScriptCase{
+		{Name: "adds", Setup: current, Keys: keys, Args: []interface{}{1, 4, 660}, Want: []interface{}{int64(1), int64(4)}},
+		{Name: "over limit", Setup: current, Keys: keys, Args: []interface{}{2, 4, 660}, Want: []interface{}{int64(0), int64(3)}},
+		{
+			Name:  "gives back",
+			Setup: current,
+			Keys:  keys,
+			Args:  []interface{}{-3, 0, 660},
+			Want:  []interface{}{int64(1), int64(0)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if mr.Exists("user:reservations:u1:e1") {
+					tb.Error("empty tally left behind")
+				}
+			},
+		},
+	})
+}
+
+func TestLuaScript_QueuePassSpend(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{passBudgetKey}
+	budget := func(seats, binding string) func(testing.TB, *miniredis.Miniredis) {
+		return func(tb testing.TB, mr *miniredis.Miniredis) { seedPassBudget(mr, seats, binding) }
+	}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptQueuePassSpend), []redistest.ScriptCase{
+		{
+			Name:  "spends",
+			Setup: budget("", ""),
+			Keys:  keys,
+			Args:  []interface{}{"p1", 2, "device-1"},
+			Want:  []interface{}{int64(1), int64(1)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if got := mr.HGet(passBudgetKey, "binding"); got != "device-1" {
+					tb.Errorf("binding = %q, want device-1", got)
+				}
+			},
+		},
+		{
+			Name:  "gives back",
+			Setup: budget("1", "device-1"),
+			Keys:  keys,
+			Args:  []interface{}{"p1", -2, "device-1"},
+			Want:  []interface{}{int64(1), int64(3)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if got := mr.HGet(passBudgetKey, "seats"); got != "3" {
+					tb.Errorf("seats = %q, want 3", got)
+				}
+			},
+		},
+		{Name: "other pass", Setup: budget("", ""), Keys: keys, Args: []interface{}{"p2", 1, ""}, WantCode: "QUEUE_PASS_NOT_FOUND"},
+		{Name: "expired budget", Keys: keys, Args: []interface{}{"p1", 1, ""}, WantCode: "QUEUE_PASS_NOT_FOUND"},
+		{Name: "too many seats", Setup: budget("1", ""), Keys: keys, Args: []interface{}{"p1", 2, ""}, WantCode: "QUEUE_PASS_EXHAUSTED"},
+		{Name: "other device", Setup: budget("", "device-1"), Keys: keys, Args: []interface{}{"p1", 1, ""}, WantCode: "QUEUE_PASS_DEVICE_MISMATCH"},
+	})
+}
+
+func TestLuaScript_ShardTransfer(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:z1", "zone:availability:z1:shard:0"}
+	pool := func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:availability:z1", "40") }
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptShardTransfer), []redistest.ScriptCase{
+		{
+			Name:  "takes a share of the source",
+			Setup: pool,
+			Keys:  keys,
+			Args:  []interface{}{2, 4},
+			Want:  []interface{}{int64(10)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "zone:availability:z1", "30")
+				wantString(tb, mr, "zone:availability:z1:shard:0", "10")
+			},
+		},
+		{Name: "empty source", Setup: func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:availability:z1", "0") },
+			Keys: keys, Args: []interface{}{2, 4}, Want: []interface{}{int64(0)}},
+		{Name: "missing source", Keys: keys, Args: []interface{}{2, 4}, Want: []interface{}{int64(-1)}},
+	})
+}
+
+func TestLuaScript_ShardTransferOut(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:{z1}", "zone:transfers:{z1}"}
+	pool := func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:availability:{z1}", "40") }
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptShardTransferOut), []redistest.ScriptCase{
+		{
+			Name:  "journals the seats it takes",
+			Setup: pool,
+			Keys:  keys,
+			Args:  []interface{}{2, 4, "t1", "zone:availability:{z1:shard:0}", 1000},
+			Want:  []interface{}{int64(10)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "zone:availability:{z1}", "30")
+				if got := mr.HGet("zone:transfers:{z1}", "t1"); got != "10:1000:zone:availability:{z1:shard:0}" {
+					tb.Errorf("journal entry = %q", got)
+				}
+			},
+		},
+		{Name: "empty source", Setup: func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:availability:{z1}", "0") },
+			Keys: keys, Args: []interface{}{2, 4, "t1", "zone:availability:{z1:shard:0}", 1000}, Want: []interface{}{int64(0)}},
+		{Name: "missing source", Keys: keys, Args: []interface{}{2, 4, "t1", "zone:availability:{z1:shard:0}", 1000}, Want: []interface{}{int64(-1)}},
+	})
+}
+
+func TestLuaScript_ShardTransferIn(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:{z1:shard:0}", "zone:transfer:{z1:shard:0}:t1"}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptShardTransferIn), []redistest.ScriptCase{
+		{
+			Name: "adds the seats",
+			Keys: keys,
+			Args: []interface{}{10, 60000},
+			Want: []interface{}{int64(1)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "zone:availability:{z1:shard:0}", "10")
+			},
+		},
+		{
+			Name:  "applied already",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("zone:transfer:{z1:shard:0}:t1", "10") },
+			Keys:  keys,
+			Args:  []interface{}{10, 60000},
+			Want:  []interface{}{int64(0)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if mr.Exists("zone:availability:{z1:shard:0}") {
+					tb.Error("seats added twice")
+				}
+			},
+		},
+	})
+}
+
+func TestLuaScript_IdentityClaim(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"identity:claim:e1:b1", "identity:seats:e1:phone:p1", "identity:seats:e1:device:d1"}
+	held := func(tb testing.TB, mr *miniredis.Miniredis) { mr.Set("identity:seats:e1:device:d1", "3") }
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptIdentityClaim), []redistest.ScriptCase{
+		{
+			Name:  "claims every identity",
+			Setup: held,
+			Keys:  keys,
+			Args:  []interface{}{2, 600, 4, 6},
+			Want:  []interface{}{int64(1), int64(2)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "identity:seats:e1:phone:p1", "2")
+				wantString(tb, mr, "identity:seats:e1:device:d1", "5")
+				if got := mr.HGet("identity:claim:e1:b1", "identity:seats:e1:device:d1"); got != "2" {
+					tb.Errorf("claimed device seats = %q, want 2", got)
+				}
+				if ttl := mr.TTL("identity:claim:e1:b1"); ttl <= 0 {
+					tb.Errorf("claim TTL = %v, want the window", ttl)
+				}
+			},
+		},
+		{Name: "unlimited identity", Setup: held, Keys: keys, Args: []interface{}{5, 600, 0, 0}},
+		{
+			Name:     "one identity at its limit charges none",
+			Setup:    held,
+			Keys:     keys,
+			Args:     []interface{}{2, 600, 4, 4},
+			WantCode: "IDENTITY_LIMIT_EXCEEDED",
+			Want:     []interface{}{int64(0), "IDENTITY_LIMIT_EXCEEDED"},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, reply interface{}) {
+				values := reply.([]interface{})
+				if values[3] != int64(2) || values[4] != int64(3) {
+					tb.Errorf("reply = %v, want identity 2 holding 3", values)
+				}
+				if mr.Exists("identity:seats:e1:phone:p1") || mr.Exists("identity:claim:e1:b1") {
+					tb.Error("phone charged although the device is at its limit")
+				}
+			},
+		},
+		{
+			Name: "claimed twice",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				mr.HSet("identity:claim:e1:b1", "identity:seats:e1:phone:p1", "2")
+			},
+			Keys:     keys,
+			Args:     []interface{}{2, 600, 4, 6},
+			WantCode: "ALREADY_CLAIMED",
+		},
+		{Name: "zero quantity", Keys: keys, Args: []interface{}{0, 600, 4, 6}, WantCode: "INVALID_QUANTITY"},
+	})
+}
+
+func TestLuaScript_IdentityRelease(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"identity:claim:e1:b1", "identity:claim:e1:b1:payment"}
+	claimed := func(tb testing.TB, mr *miniredis.Miniredis) {
+		mr.Set("identity:seats:e1:phone:p1", "2")
+		mr.Set("identity:seats:e1:card:c1", "5")
+		mr.HSet("identity:claim:e1:b1", "identity:seats:e1:phone:p1", "2")
+		mr.HSet("identity:claim:e1:b1:payment", "identity:seats:e1:card:c1", "2")
+	}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptIdentityRelease), []redistest.ScriptCase{
+		{
+			Name:  "releases every claim",
+			Setup: claimed,
+			Keys:  keys,
+			Want:  []interface{}{int64(1), int64(2)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				wantString(tb, mr, "identity:seats:e1:card:c1", "3")
+				if mr.Exists("identity:seats:e1:phone:p1") || mr.Exists("identity:claim:e1:b1") || mr.Exists("identity:claim:e1:b1:payment") {
+					tb.Error("empty tally or claim left behind")
+				}
+			},
+		},
+		{Name: "missing claim", Keys: keys[:1], Want: []interface{}{int64(1), int64(0)}},
+	})
+}
+
+func TestLuaScript_JoinQueue(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"queue:e1", "queue:user:e1:u1"}
+	args := []interface{}{"u1", "e1", "token-1", 1800, 2}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptJoinQueue), []redistest.ScriptCase{
+		{
+			Name: "joins",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				if _, err := mr.ZAdd("queue:e1", 1, "u0"); err != nil {
+					tb.Fatal(err)
+				}
+			},
+			Keys: keys,
+			Args: args,
+			Want: []interface{}{int64(1), int64(2), int64(2)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if got := mr.HGet("queue:user:e1:u1", "token"); got != "token-1" {
+					tb.Errorf("token = %q, want token-1", got)
+				}
+			},
+		},
+		{
+			Name: "already queued",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				if _, err := mr.ZAdd("queue:e1", 1, "u1"); err != nil {
+					tb.Fatal(err)
+				}
+			},
+			Keys:     keys,
+			Args:     args,
+			WantCode: "ALREADY_IN_QUEUE",
+		},
+		{
+			Name: "full",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				mr.ZAdd("queue:e1", 1, "u2")
+				mr.ZAdd("queue:e1", 2, "u3")
+			},
+			Keys:     keys,
+			Args:     args,
+			WantCode: "QUEUE_FULL",
+		},
+	})
+}
+
+func TestLuaScript_JoinQueue_Lottery(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"queue:e1", "queue:user:e1:u1", "queue:lottery:e1"}
+	opensAt := time.Now().Add(time.Hour).Unix()
+	args := []interface{}{"u1", "e1", "token-1", 1800, 3, opensAt}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptJoinQueue), []redistest.ScriptCase{
+		{
+			Name:  "pools before opening",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) { mr.SetAdd("queue:lottery:e1", "u0") },
+			Keys:  keys,
+			Args:  args,
+			Want:  []interface{}{int64(1), int64(0), int64(2)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if ok, _ := mr.SIsMember("queue:lottery:e1", "u1"); !ok {
+					tb.Error("u1 not in the lottery pool")
+				}
+				if mr.Exists("queue:e1") {
+					tb.Error("u1 queued before the draw")
+				}
+				if ttl := mr.TTL("queue:user:e1:u1"); ttl <= time.Hour {
+					tb.Errorf("entry TTL = %v, want past the draw", ttl)
+				}
+			},
+		},
+		{
+			Name:  "queues by join time after opening",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) { mr.SetAdd("queue:lottery:e1", "u0") },
+			Keys:  keys,
+			Args:  []interface{}{"u1", "e1", "token-1", 1800, 3, time.Now().Add(-time.Minute).Unix()},
+			Want:  []interface{}{int64(1), int64(1), int64(2)},
+		},
+		{
+			Name:     "already in the lottery",
+			Setup:    func(tb testing.TB, mr *miniredis.Miniredis) { mr.SetAdd("queue:lottery:e1", "u1") },
+			Keys:     keys,
+			Args:     args,
+			WantCode: "ALREADY_IN_QUEUE",
+		},
+		{
+			Name: "pool counts toward the size",
+			Setup: func(tb testing.TB, mr *miniredis.Miniredis) {
+				mr.SetAdd("queue:lottery:e1", "u2", "u3")
+				mr.ZAdd("queue:e1", 1, "u4")
+			},
+			Keys:     keys,
+			Args:     args,
+			WantCode: "QUEUE_FULL",
+		},
+	})
+}
+
+func TestLuaScript_LotteryDraw(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"queue:e1", "queue:lottery:e1"}
+	opened := time.Now().Add(-time.Minute).Unix()
+	pool := []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8"}
+	pooled := func(tb testing.TB, mr *miniredis.Miniredis) {
+		mr.SetAdd("queue:lottery:e1", pool...)
+		mr.ZAdd("queue:e1", float64(opened+30), "late")
+	}
+
+	drawOrder := func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+		members, err := mr.ZMembers("queue:e1")
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if len(members) != len(pool)+1 || members[len(members)-1] != "late" {
+			tb.Fatalf("queue = %v, want the pool ahead of late", members)
+		}
+		if mr.Exists("queue:lottery:e1") {
+			tb.Error("lottery pool left after the draw")
+		}
+		for i, member := range members[:len(pool)] {
+			if score, _ := mr.ZScore("queue:e1", member); score != float64(i+1) {
+				tb.Errorf("%s scored %v, want position %d", member, score, i+1)
+			}
+		}
+	}
+
+	redistest.RunScriptCases(t, client, mr, scriptSpec(t, scriptLotteryDraw), []redistest.ScriptCase{
+		{Name: "draws", Setup: pooled, Keys: keys, Args: []interface{}{opened, 42}, Want: []interface{}{int64(1), int64(8), int64(9)}, Check: drawOrder},
+		{Name: "empty pool", Keys: keys, Args: []interface{}{opened, 42}, Want: []interface{}{int64(1), int64(0), int64(0)}},
+		{
+			Name:     "before opening",
+			Setup:    pooled,
+			Keys:     keys,
+			Args:     []interface{}{time.Now().Add(time.Hour).Unix(), 42},
+			WantCode: "NOT_OPEN",
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if members, _ := mr.Members("queue:lottery:e1"); len(members) != len(pool) {
+					tb.Errorf("pool = %v, want untouched", members)
+				}
+			},
+		},
+	})
+}
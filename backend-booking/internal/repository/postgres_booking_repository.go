@@ -762,5 +762,168 @@ func (r *PostgresBookingRepository) GetTenantIDByShowID(ctx context.Context, sho
 	return tenantID, nil
 }
 
-// Ensure PostgresBookingRepository implements BookingRepository
-var _ BookingRepository = (*PostgresBookingRepository)(nil)
+// ListHeldSeats returns bookings still in reserved status for the given zones
+func (r *PostgresBookingRepository) ListHeldSeats(ctx context.Context, zoneIDs []string) ([]HeldSeats, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.booking.list_held_seats")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("zones", len(zoneIDs)))
+
+	query := `
+		SELECT id::text, zone_id::text, quantity
+		FROM bookings
+		WHERE status = 'reserved' AND zone_id = ANY($1::uuid[])
+	`
+
+	rows, err := r.pool.Query(ctx, query, zoneIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to list held seats: %w", err)
+	}
+	defer rows.Close()
+
+	var held []HeldSeats
+	for rows.Next() {
+		var h HeldSeats
+		if err := rows.Scan(&h.BookingID, &h.ZoneID, &h.Quantity); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to scan held seats: %w", err)
+		}
+		held = append(held, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("error iterating held seats: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("count", len(held)))
+	span.SetStatus(codes.Ok, "")
+	return held, nil
+}
+
+// SumSoldSeats returns the confirmed seat count per zone for the given zones
+func (r *PostgresBookingRepository) SumSoldSeats(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.booking.sum_sold_seats")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("zones", len(zoneIDs)))
+
+	query := `
+		SELECT zone_id::text, COALESCE(SUM(quantity), 0)
+		FROM bookings
+		WHERE status = 'confirmed' AND zone_id = ANY($1::uuid[])
+		GROUP BY zone_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, zoneIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to sum sold seats: %w", err)
+	}
+	defer rows.Close()
+
+	sold := make(map[string]int64, len(zoneIDs))
+	for rows.Next() {
+		var zoneID string
+		var seats int64
+		if err := rows.Scan(&zoneID, &seats); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to scan sold seats: %w", err)
+		}
+		sold[zoneID] = seats
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("error iterating sold seats: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return sold, nil
+}
+
+// CreateComps inserts confirmed comp bookings in one transaction. beforeCommit
+// runs once every row is written, so a failed insert never reaches it and a
+// failure in it rolls the rows back.
+func (r *PostgresBookingRepository) CreateComps(ctx context.Context, bookings []*domain.Booking, beforeCommit func(ctx context.Context) error) error {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.booking.create_comps")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("bookings", len(bookings)))
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO bookings (
+			id, tenant_id, user_id, event_id, show_id, zone_id,
+			quantity, unit_price, total_amount, currency, status, status_reason,
+			reserved_at, confirmed_at, confirmation_code, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6,
+			$7, $8, $9, $10, $11, $12,
+			$13, $14, $15, $16, $17
+		)
+	`
+
+	for _, booking := range bookings {
+		_, err := tx.Exec(ctx, query,
+			booking.ID,
+			booking.TenantID,
+			booking.UserID,
+			booking.EventID,
+			booking.ShowID,
+			booking.ZoneID,
+			booking.Quantity,
+			booking.UnitPrice,
+			booking.TotalPrice,
+			booking.Currency,
+			booking.Status.String(),
+			nullString(booking.StatusReason),
+			booking.ReservedAt,
+			booking.ConfirmedAt,
+			booking.ConfirmationCode,
+			booking.CreatedAt,
+			booking.UpdatedAt,
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to create comp booking: %w", err)
+		}
+	}
+
+	if err := beforeCommit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to commit comp bookings: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// Ensure PostgresBookingRepository implements BookingRepository, BookingInventoryReader and CompBookingStore
+var (
+	_ BookingRepository      = (*PostgresBookingRepository)(nil)
+	_ BookingInventoryReader = (*PostgresBookingRepository)(nil)
+	_ CompBookingStore       = (*PostgresBookingRepository)(nil)
+)
@@ -0,0 +1,631 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// PostgresFallbackReservationRepository implements FallbackReservationStore.
+// Seats are taken with a guarded UPDATE on the zone row, so concurrent
+// reservations serialize on that row instead of on a Redis counter.
+type PostgresFallbackReservationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresFallbackReservationRepository creates a new PostgresFallbackReservationRepository
+func NewPostgresFallbackReservationRepository(pool *pgxpool.Pool) *PostgresFallbackReservationRepository {
+	return &PostgresFallbackReservationRepository{pool: pool}
+}
+
+// ActivateZone starts serving a zone with sellableSeats minus its held and sold
+// bookings; it does nothing if the zone is already active
+func (r *PostgresFallbackReservationRepository) ActivateZone(ctx context.Context, zoneID string, sellableSeats int64) error {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.activate_zone")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("zone_id", zoneID),
+		attribute.Int64("sellable_seats", sellableSeats),
+	)
+
+	query := `
+		INSERT INTO fallback_zone_inventory (zone_id, available_seats, active, activated_at, updated_at)
+		SELECT $1, GREATEST($2 - COALESCE(SUM(quantity), 0), 0), TRUE, NOW(), NOW()
+		FROM bookings
+		WHERE zone_id = $1 AND status IN ('reserved', 'confirmed')
+		ON CONFLICT (zone_id) DO UPDATE SET
+			available_seats = EXCLUDED.available_seats,
+			active = TRUE,
+			activated_at = NOW(),
+			updated_at = NOW()
+		WHERE fallback_zone_inventory.active = FALSE
+	`
+
+	if _, err := r.pool.Exec(ctx, query, zoneID, sellableSeats); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to activate fallback zone: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// ReserveSeats takes seats from an active zone
+func (r *PostgresFallbackReservationRepository) ReserveSeats(ctx context.Context, params ReserveParams) (*ReserveResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.reserve_seats")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("zone_id", params.ZoneID),
+		attribute.String("user_id", params.UserID),
+		attribute.Int("quantity", params.Quantity),
+	)
+
+	if params.Quantity <= 0 {
+		return &ReserveResult{ErrorCode: "INVALID_QUANTITY", ErrorMessage: "Quantity must be positive"}, nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Per-user limit: booking rows plus Postgres reservations whose booking
+	// row has not been written yet
+	var userReserved int64
+	if params.MaxPerUser > 0 {
+		query := `
+			SELECT
+				(SELECT COALESCE(SUM(quantity), 0) FROM bookings
+					WHERE user_id = $1 AND event_id = $2 AND status IN ('reserved', 'confirmed'))
+				+
+				(SELECT COALESCE(SUM(f.quantity), 0) FROM fallback_reservations f
+					WHERE f.user_id = $1 AND f.event_id = $2 AND f.status = 'reserved'
+						AND NOT EXISTS (SELECT 1 FROM bookings b WHERE b.id = f.booking_id))
+		`
+		if err := tx.QueryRow(ctx, query, params.UserID, params.EventID).Scan(&userReserved); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to count user reservations: %w", err)
+		}
+		if userReserved+int64(params.Quantity) > int64(params.MaxPerUser) {
+			span.SetStatus(codes.Error, "USER_LIMIT_EXCEEDED")
+			return &ReserveResult{
+				ErrorCode: "USER_LIMIT_EXCEEDED",
+				ErrorMessage: fmt.Sprintf("User limit exceeded. Current: %d, Requested: %d, Max: %d",
+					userReserved, params.Quantity, params.MaxPerUser),
+			}, nil
+		}
+	}
+
+	var available int64
+	err = tx.QueryRow(ctx, `
+		UPDATE fallback_zone_inventory
+		SET available_seats = available_seats - $2, updated_at = NOW()
+		WHERE zone_id = $1 AND active AND available_seats >= $2
+		RETURNING available_seats
+	`, params.ZoneID, params.Quantity).Scan(&available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		result, err := r.zoneShortfall(ctx, tx, params.ZoneID, params.Quantity)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		span.SetStatus(codes.Error, result.ErrorCode)
+		return result, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to take fallback seats: %w", err)
+	}
+
+	bookingID := params.BookingID
+	if bookingID == "" {
+		bookingID = uuid.New().String()
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO fallback_reservations (
+			booking_id, zone_id, user_id, event_id, quantity, unit_price,
+			status, origin, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, bookingID, params.ZoneID, params.UserID, params.EventID, params.Quantity, params.Price,
+		FallbackStatusReserved, FallbackOriginFallback,
+		time.Now().Add(time.Duration(params.TTLSeconds)*time.Second))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to record fallback reservation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.Int64("available_seats", available),
+	)
+	span.SetStatus(codes.Ok, "")
+	return &ReserveResult{
+		Success:        true,
+		BookingID:      bookingID,
+		AvailableSeats: available,
+		UserReserved:   userReserved + int64(params.Quantity),
+	}, nil
+}
+
+// zoneShortfall explains why seats could not be taken from a zone
+func (r *PostgresFallbackReservationRepository) zoneShortfall(ctx context.Context, tx pgx.Tx, zoneID string, quantity int) (*ReserveResult, error) {
+	var available int64
+	var active bool
+	err := tx.QueryRow(ctx, `SELECT available_seats, active FROM fallback_zone_inventory WHERE zone_id = $1`, zoneID).Scan(&available, &active)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !active) {
+		return &ReserveResult{ErrorCode: "ZONE_NOT_ACTIVE", ErrorMessage: "Zone is not served from the fallback store"}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fallback zone: %w", err)
+	}
+	return &ReserveResult{
+		ErrorCode:    "INSUFFICIENT_STOCK",
+		ErrorMessage: fmt.Sprintf("Not enough seats available. Available: %d, Requested: %d", available, quantity),
+	}, nil
+}
+
+// lockActiveZone locks an active zone row for the rest of tx
+func (r *PostgresFallbackReservationRepository) lockActiveZone(ctx context.Context, tx pgx.Tx, zoneID string) (bool, error) {
+	var active bool
+	err := tx.QueryRow(ctx, `SELECT active FROM fallback_zone_inventory WHERE zone_id = $1 FOR UPDATE`, zoneID).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock fallback zone: %w", err)
+	}
+	return active, nil
+}
+
+// fallbackEntry is the state of a booking as seen from the fallback store:
+// its fallback row if it has one, otherwise its booking row
+type fallbackEntry struct {
+	userID    string
+	eventID   string
+	quantity  int64
+	unitPrice float64
+	status    string
+	recorded  bool // Has a fallback_reservations row
+}
+
+// getEntry reads a booking's fallback row, or its booking row if it was
+// reserved in Redis, locking it for the rest of tx
+func (r *PostgresFallbackReservationRepository) getEntry(ctx context.Context, tx pgx.Tx, bookingID string) (*fallbackEntry, error) {
+	e := &fallbackEntry{recorded: true}
+	err := tx.QueryRow(ctx, `
+		SELECT user_id::text, event_id::text, quantity, unit_price, status
+		FROM fallback_reservations WHERE booking_id = $1 FOR UPDATE
+	`, bookingID).Scan(&e.userID, &e.eventID, &e.quantity, &e.unitPrice, &e.status)
+	if err == nil {
+		return e, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get fallback reservation: %w", err)
+	}
+
+	e = &fallbackEntry{}
+	err = tx.QueryRow(ctx, `
+		SELECT user_id::text, event_id::text, quantity, unit_price, status::text
+		FROM bookings WHERE id = $1 FOR UPDATE
+	`, bookingID).Scan(&e.userID, &e.eventID, &e.quantity, &e.unitPrice, &e.status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	return e, nil
+}
+
+// recordRedisEntry records what happened to a Redis reservation during the outage
+func (r *PostgresFallbackReservationRepository) recordRedisEntry(ctx context.Context, tx pgx.Tx, bookingID, zoneID string, e *fallbackEntry, status, paymentID string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO fallback_reservations (
+			booking_id, zone_id, user_id, event_id, quantity, unit_price,
+			status, origin, payment_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
+	`, bookingID, zoneID, e.userID, e.eventID, e.quantity, e.unitPrice, status, FallbackOriginRedis, paymentID)
+	if err != nil {
+		return fmt.Errorf("failed to record redis reservation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmBooking confirms a reservation of an active zone
+func (r *PostgresFallbackReservationRepository) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*ConfirmResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.confirm")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.String("zone_id", zoneID),
+	)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	active, err := r.lockActiveZone(ctx, tx, zoneID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if !active {
+		return &ConfirmResult{ErrorCode: "ZONE_NOT_ACTIVE", ErrorMessage: "Zone is not served from the fallback store"}, nil
+	}
+
+	e, err := r.getEntry(ctx, tx, bookingID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	switch {
+	case e == nil:
+		return &ConfirmResult{ErrorCode: "RESERVATION_NOT_FOUND", ErrorMessage: "Reservation does not exist or has expired"}, nil
+	case e.userID != userID:
+		return &ConfirmResult{ErrorCode: "INVALID_USER_ID", ErrorMessage: "User ID does not match"}, nil
+	case e.status == FallbackStatusConfirmed:
+		return &ConfirmResult{ErrorCode: "ALREADY_CONFIRMED", ErrorMessage: "Reservation is already confirmed"}, nil
+	case e.status != FallbackStatusReserved:
+		return &ConfirmResult{ErrorCode: "INVALID_STATUS", ErrorMessage: fmt.Sprintf("Reservation status is '%s', expected 'reserved'", e.status)}, nil
+	}
+
+	if e.recorded {
+		_, err = tx.Exec(ctx, `
+			UPDATE fallback_reservations
+			SET status = $2, payment_id = NULLIF($3, ''), updated_at = NOW()
+			WHERE booking_id = $1
+		`, bookingID, FallbackStatusConfirmed, paymentID)
+		if err != nil {
+			err = fmt.Errorf("failed to confirm fallback reservation: %w", err)
+		}
+	} else {
+		err = r.recordRedisEntry(ctx, tx, bookingID, zoneID, e, FallbackStatusConfirmed, paymentID)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return &ConfirmResult{
+		Success:     true,
+		Status:      "CONFIRMED",
+		ConfirmedAt: strconv.FormatInt(time.Now().Unix(), 10),
+	}, nil
+}
+
+// ReleaseSeats returns a reservation's seats to an active zone
+func (r *PostgresFallbackReservationRepository) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*ReleaseResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.release_seats")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("booking_id", bookingID),
+		attribute.String("zone_id", zoneID),
+	)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	active, err := r.lockActiveZone(ctx, tx, zoneID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if !active {
+		return &ReleaseResult{ErrorCode: "ZONE_NOT_ACTIVE", ErrorMessage: "Zone is not served from the fallback store"}, nil
+	}
+
+	e, err := r.getEntry(ctx, tx, bookingID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	switch {
+	case e == nil:
+		return &ReleaseResult{ErrorCode: "RESERVATION_NOT_FOUND", ErrorMessage: "Reservation does not exist or has expired"}, nil
+	case e.userID != userID:
+		return &ReleaseResult{ErrorCode: "INVALID_USER_ID", ErrorMessage: "User ID does not match"}, nil
+	case e.status != FallbackStatusReserved:
+		return &ReleaseResult{ErrorCode: "ALREADY_RELEASED", ErrorMessage: fmt.Sprintf("Reservation status is '%s', cannot release", e.status)}, nil
+	}
+
+	if e.recorded {
+		_, err = tx.Exec(ctx, `
+			UPDATE fallback_reservations SET status = $2, updated_at = NOW() WHERE booking_id = $1
+		`, bookingID, FallbackStatusReleased)
+		if err != nil {
+			err = fmt.Errorf("failed to release fallback reservation: %w", err)
+		}
+	} else {
+		err = r.recordRedisEntry(ctx, tx, bookingID, zoneID, e, FallbackStatusReleased, "")
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	var available int64
+	err = tx.QueryRow(ctx, `
+		UPDATE fallback_zone_inventory
+		SET available_seats = available_seats + $2, updated_at = NOW()
+		WHERE zone_id = $1
+		RETURNING available_seats
+	`, zoneID, e.quantity).Scan(&available)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to return fallback seats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("available_seats", available))
+	span.SetStatus(codes.Ok, "")
+	return &ReleaseResult{
+		Success:        true,
+		AvailableSeats: available,
+	}, nil
+}
+
+// GetZoneAvailabilities returns the available seats of the given active zones
+func (r *PostgresFallbackReservationRepository) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.get_zone_availabilities")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("zones", len(zoneIDs)))
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT zone_id::text, available_seats
+		FROM fallback_zone_inventory
+		WHERE active AND zone_id = ANY($1::uuid[])
+	`, zoneIDs)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get fallback availability: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int64, len(zoneIDs))
+	for rows.Next() {
+		var zoneID string
+		var seats int64
+		if err := rows.Scan(&zoneID, &seats); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to scan fallback availability: %w", err)
+		}
+		result[zoneID] = seats
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to iterate fallback availability: %w", err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
+
+// ReleaseExpired returns the seats of Postgres reservations past their
+// deadline and reports how many seats were returned
+func (r *PostgresFallbackReservationRepository) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.release_expired")
+	defer span.End()
+
+	query := `
+		WITH released AS (
+			UPDATE fallback_reservations
+			SET status = 'released', updated_at = NOW()
+			WHERE origin = 'fallback' AND status = 'reserved' AND expires_at < $1
+			RETURNING zone_id, quantity
+		), totals AS (
+			SELECT zone_id, SUM(quantity) AS seats FROM released GROUP BY zone_id
+		), returned AS (
+			UPDATE fallback_zone_inventory z
+			SET available_seats = z.available_seats + totals.seats, updated_at = NOW()
+			FROM totals
+			WHERE z.zone_id = totals.zone_id
+			RETURNING z.zone_id
+		)
+		SELECT COALESCE(SUM(quantity), 0) FROM released
+	`
+
+	var seats int64
+	if err := r.pool.QueryRow(ctx, query, now).Scan(&seats); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to release expired fallback reservations: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("seats", seats))
+	span.SetStatus(codes.Ok, "")
+	return seats, nil
+}
+
+// ListActiveZones returns the zones currently served from Postgres
+func (r *PostgresFallbackReservationRepository) ListActiveZones(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT zone_id::text FROM fallback_zone_inventory WHERE active`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fallback zones: %w", err)
+	}
+	defer rows.Close()
+
+	var zones []string
+	for rows.Next() {
+		var zoneID string
+		if err := rows.Scan(&zoneID); err != nil {
+			return nil, fmt.Errorf("failed to scan fallback zone: %w", err)
+		}
+		zones = append(zones, zoneID)
+	}
+	return zones, rows.Err()
+}
+
+// ResyncZone locks an active zone, hands its state to apply and deactivates
+// the zone once apply succeeds. Postgres reservations whose booking is no
+// longer reserved or whose deadline has passed are released first, so only
+// live reservations are carried over.
+func (r *PostgresFallbackReservationRepository) ResyncZone(ctx context.Context, zoneID string, apply func(ctx context.Context, available int64, reservations []FallbackReservation) error) error {
+	ctx, span := telemetry.StartSpan(ctx, "repo.postgres.fallback.resync_zone")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("zone_id", zoneID))
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var available int64
+	err = tx.QueryRow(ctx, `
+		SELECT available_seats FROM fallback_zone_inventory
+		WHERE zone_id = $1 AND active
+		FOR UPDATE
+	`, zoneID).Scan(&available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		span.SetStatus(codes.Ok, "")
+		return nil // Resynced by another replica
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to lock fallback zone: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT
+			f.booking_id::text, f.zone_id::text, f.user_id::text, f.event_id::text,
+			f.quantity, f.unit_price, f.status, f.origin,
+			COALESCE(f.payment_id, ''), f.created_at, COALESCE(f.expires_at, f.created_at),
+			COALESCE(b.status::text, '')
+		FROM fallback_reservations f
+		LEFT JOIN bookings b ON b.id = f.booking_id
+		WHERE f.zone_id = $1
+		ORDER BY (f.status = 'reserved') DESC, f.created_at
+	`, zoneID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to list fallback reservations: %w", err)
+	}
+
+	now := time.Now()
+	var reservations []FallbackReservation
+	for rows.Next() {
+		var res FallbackReservation
+		var bookingStatus string
+		if err := rows.Scan(
+			&res.BookingID, &res.ZoneID, &res.UserID, &res.EventID,
+			&res.Quantity, &res.UnitPrice, &res.Status, &res.Origin,
+			&res.PaymentID, &res.CreatedAt, &res.ExpiresAt,
+			&bookingStatus,
+		); err != nil {
+			rows.Close()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to scan fallback reservation: %w", err)
+		}
+
+		if res.Origin == FallbackOriginFallback {
+			if res.Status != FallbackStatusReserved {
+				continue // Settled in Postgres; only the counter carries over
+			}
+			// A missing booking row may still be in flight, so only the
+			// booking's own status or the deadline end the hold
+			if (bookingStatus != "" && bookingStatus != "reserved") || now.After(res.ExpiresAt) {
+				available += res.Quantity
+				continue
+			}
+		}
+		reservations = append(reservations, res)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to iterate fallback reservations: %w", err)
+	}
+
+	if err := apply(ctx, available, reservations); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM fallback_reservations WHERE zone_id = $1`, zoneID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to clear fallback reservations: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE fallback_zone_inventory SET active = FALSE, available_seats = $2, updated_at = NOW() WHERE zone_id = $1
+	`, zoneID, available)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to deactivate fallback zone: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	span.SetAttributes(
+		attribute.Int64("available_seats", available),
+		attribute.Int("reservations", len(reservations)),
+	)
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// drainBatchSize is how many users DrainQueue removes per round trip
+const drainBatchSize = 500
+
+// queueControlKey returns the hash holding the state of an event's queue
+func queueControlKey(eventID string) string {
+	return fmt.Sprintf("queue:control:%s", eventID)
+}
+
+// GetQueueControl gets the state operators have put an event's queue in
+func (r *RedisQueueRepository) GetQueueControl(ctx context.Context, eventID string) (*QueueControl, error) {
+	result, err := r.client.HGetAll(ctx, queueControlKey(eventID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue control: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, nil // Never paused or drained
+	}
+
+	control := &QueueControl{
+		State:     result["state"],
+		Message:   result["message"],
+		UpdatedBy: result["updated_by"],
+	}
+	if control.State == "" {
+		control.State = domain.QueueStateActive
+	}
+	if val, err := strconv.ParseInt(result["updated_at"], 10, 64); err == nil {
+		control.UpdatedAt = time.Unix(val, 0)
+	}
+	return control, nil
+}
+
+// SetQueueControl sets the state of an event's queue
+func (r *RedisQueueRepository) SetQueueControl(ctx context.Context, eventID string, control *QueueControl) error {
+	err := r.client.HSet(ctx, queueControlKey(eventID),
+		"state", control.State,
+		"message", control.Message,
+		"updated_by", control.UpdatedBy,
+		"updated_at", control.UpdatedAt.Unix(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set queue control: %w", err)
+	}
+	return nil
+}
+
+// DrainQueue pops every lane of an event's queue in batches, as the queue
+// release worker does, then empties its lottery pool. Users who join while
+// it runs are popped too, as long as they join before their lane is found
+// empty.
+func (r *RedisQueueRepository) DrainQueue(ctx context.Context, eventID string) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.queue.drain")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	lanes, err := r.client.SMembers(ctx, r.lanesKey(eventID)).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to get queue lanes: %w", err)
+	}
+	drainLanes := []string{"", domain.QueueLaneParked}
+	for _, lane := range lanes {
+		if lane != domain.QueueLaneParked {
+			drainLanes = append(drainLanes, lane)
+		}
+	}
+
+	var drained int64
+	for _, lane := range drainLanes {
+		for {
+			userIDs, err := r.PopUsersFromLane(ctx, eventID, lane, drainBatchSize)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return drained, err
+			}
+			drained += int64(len(userIDs))
+			if len(userIDs) < drainBatchSize {
+				break
+			}
+		}
+	}
+
+	pooled, err := r.client.SMembers(ctx, r.lotteryKey(eventID)).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return drained, fmt.Errorf("failed to get lottery pool: %w", err)
+	}
+	for start := 0; start < len(pooled); start += drainBatchSize {
+		batch := pooled[start:min(start+drainBatchSize, len(pooled))]
+		removed, err := r.client.SRem(ctx, r.lotteryKey(eventID), stringSliceToInterface(batch)...).Result()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return drained, fmt.Errorf("failed to drain lottery: %w", err)
+		}
+		if err := r.deleteUserQueueInfo(ctx, eventID, batch); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return drained, err
+		}
+		drained += removed
+	}
+
+	if err := r.client.Del(ctx, r.lanesKey(eventID)).Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return drained, fmt.Errorf("failed to delete queue lanes: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("drained", drained))
+	span.SetStatus(codes.Ok, "")
+	return drained, nil
+}
+
+// deleteUserQueueInfo deletes the queue info of users no longer queued
+func (r *RedisQueueRepository) deleteUserQueueInfo(ctx context.Context, eventID string, userIDs []string) error {
+	pipe := r.client.Pipeline()
+	for _, userID := range userIDs {
+		pipe.Del(ctx, r.userQueueKey(eventID, userID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete user queue info: %w", err)
+	}
+	return nil
+}
+
+// ExpireQueuePasses finds an event's passes the way CountActiveQueuePasses
+// counts them and deletes each with its budget, so they fail validation
+// and free their booking slots at once
+func (r *RedisQueueRepository) ExpireQueuePasses(ctx context.Context, eventID string) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.queue.expire_passes")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	prefix := fmt.Sprintf("queue:pass:%s:", eventID)
+	var expired int64
+	err := r.client.ScanKeys(ctx, prefix+"*", 100, func(keys []string) error {
+		pipe := r.client.Pipeline()
+		deleted := make([]*redis.IntCmd, 0, len(keys))
+		for _, key := range keys {
+			deleted = append(deleted, pipe.Del(ctx, key))
+			// Deleted separately: on a cluster the budget is in another slot
+			pipe.Del(ctx, queuePassBudgetKey(eventID, strings.TrimPrefix(key, prefix)))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		for _, cmd := range deleted {
+			expired += cmd.Val()
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return expired, fmt.Errorf("failed to expire queue passes: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("expired", expired))
+	span.SetStatus(codes.Ok, "")
+	return expired, nil
+}
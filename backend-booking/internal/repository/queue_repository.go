@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 )
 
 // JoinQueueResult represents the result of joining a queue
@@ -10,6 +13,7 @@ type JoinQueueResult struct {
 	Position     int64
 	TotalInQueue int64
 	JoinedAt     float64
+	InLottery    bool // Awaiting the lottery draw; Position is 0 until then
 	ErrorCode    string
 	ErrorMessage string
 }
@@ -19,6 +23,12 @@ type QueuePositionResult struct {
 	Position     int64
 	TotalInQueue int64
 	IsInQueue    bool
+	InLottery    bool // Awaiting the lottery draw; Position is 0 until then
+	// Lane is the user's lane once users have joined priority lanes
+	// (domain.QueueLaneGeneral for everyone else; "" without lanes).
+	// Position and TotalInLane count within the lane.
+	Lane        string
+	TotalInLane int64
 }
 
 // QueueRepository defines the interface for Redis-based queue operations
@@ -38,8 +48,8 @@ type QueueRepository interface {
 	// GetUserQueueInfo gets the user's queue info (token, joined_at, etc.)
 	GetUserQueueInfo(ctx context.Context, eventID, userID string) (map[string]string, error)
 
-	// StoreQueuePass stores the queue pass token in Redis with TTL
-	StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, ttl int) error
+	// StoreQueuePass stores the queue pass token and its usage budget in Redis with TTL
+	StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, budget QueuePassBudget, ttl int) error
 
 	// GetQueuePass retrieves the queue pass for a user (if exists)
 	GetQueuePass(ctx context.Context, eventID, userID string) (string, error)
@@ -47,12 +57,18 @@ type QueueRepository interface {
 	// ValidateQueuePass validates if the queue pass is valid and not expired
 	ValidateQueuePass(ctx context.Context, eventID, userID, queuePass string) (bool, error)
 
-	// DeleteQueuePass deletes the queue pass after successful booking
+	// DeleteQueuePass deletes the queue pass and its budget once it is used up
 	DeleteQueuePass(ctx context.Context, eventID, userID string) error
 
 	// PopUsersFromQueue pops the first N users from the queue (for batch release)
 	PopUsersFromQueue(ctx context.Context, eventID string, count int64) ([]string, error)
 
+	// PopUsersFromLane pops the first N users from a priority lane ("" = general)
+	PopUsersFromLane(ctx context.Context, eventID, lane string, count int64) ([]string, error)
+
+	// GetQueueLanes returns the priority lanes users have joined an event's queue in
+	GetQueueLanes(ctx context.Context, eventID string) ([]string, error)
+
 	// GetAllQueueEventIDs returns all event IDs that have active queues
 	GetAllQueueEventIDs(ctx context.Context) ([]string, error)
 
@@ -67,12 +83,101 @@ type QueueRepository interface {
 
 	// SetEventQueueConfig sets the queue configuration for an event in Redis cache
 	SetEventQueueConfig(ctx context.Context, eventID string, config *EventQueueConfig) error
+
+	// DrawLottery moves the users awaiting an event's lottery into the queue
+	// in a random order and returns how many were drawn.
+	// domain.ErrQueueNotOpen if the lottery opens after Redis' clock.
+	DrawLottery(ctx context.Context, eventID string, opensAt int64) (int64, error)
+
+	// GetQueueControl gets the state operators have put an event's queue in
+	// (nil = never set, the queue is active)
+	GetQueueControl(ctx context.Context, eventID string) (*QueueControl, error)
+
+	// SetQueueControl sets the state of an event's queue
+	SetQueueControl(ctx context.Context, eventID string, control *QueueControl) error
+
+	// DrainQueue removes every user waiting in an event's queue, in any lane
+	// or the lottery pool, and returns how many were removed
+	DrainQueue(ctx context.Context, eventID string) (int64, error)
+
+	// ExpireQueuePasses deletes every outstanding queue pass of an event,
+	// with its budget, and returns how many were deleted
+	ExpireQueuePasses(ctx context.Context, eventID string) (int64, error)
+}
+
+// QueueControl is the state operators or the queue scheduler have put an
+// event's queue in
+type QueueControl struct {
+	// State is domain.QueueStateActive, QueueStatePaused, QueueStateDrained
+	// or QueueStateScheduled
+	State string `json:"state"`
+	// Message is shown to the users waiting in the queue
+	Message   string    `json:"message,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsPaused reports whether the queue holds its users in place
+func (c *QueueControl) IsPaused() bool {
+	return c != nil && c.State == domain.QueueStatePaused
+}
+
+// IsDrained reports whether the queue has been closed and emptied
+func (c *QueueControl) IsDrained() bool {
+	return c != nil && c.State == domain.QueueStateDrained
+}
+
+// IsScheduled reports whether the queue's waiting room has not opened yet
+func (c *QueueControl) IsScheduled() bool {
+	return c != nil && c.State == domain.QueueStateScheduled
 }
 
 // EventQueueConfig holds queue configuration for an event
 type EventQueueConfig struct {
 	MaxConcurrentBookings int `json:"max_concurrent_bookings"`
 	QueuePassTTLMinutes   int `json:"queue_pass_ttl_minutes"`
+	// Mode is domain.QueueModeFIFO (default) or domain.QueueModeLottery
+	Mode string `json:"mode"`
+	// LotteryOpensAt is when a lottery queue opens (unix seconds). Users who
+	// join before it get random positions at that moment.
+	LotteryOpensAt int64 `json:"lottery_opens_at"`
+	// ReleaseRateOverride fixes the users released per interval, bypassing
+	// the adaptive release rate (0 = adaptive)
+	ReleaseRateOverride int `json:"release_rate_override"`
+	// Lanes are the queue's priority lanes, in the order users are matched
+	// against them; everyone else queues in the general lane
+	Lanes []domain.QueueLane `json:"lanes,omitempty"`
+	// PassReservations and PassSeats budget each queue pass: the successful
+	// reservations it may be spent on (0 = domain.DefaultQueuePassReservations)
+	// and the seats across them (0 = only the per-user limit applies)
+	PassReservations int `json:"pass_reservations"`
+	PassSeats        int `json:"pass_seats"`
+}
+
+// PassBudget returns the budget of a queue pass released for the event
+func (c *EventQueueConfig) PassBudget(passID string) QueuePassBudget {
+	budget := QueuePassBudget{PassID: passID, Reservations: domain.DefaultQueuePassReservations}
+	if c != nil && c.PassReservations > 0 {
+		budget.Reservations = c.PassReservations
+	}
+	if c != nil {
+		budget.Seats = c.PassSeats
+	}
+	return budget
+}
+
+// QueuePassBudget is what a queue pass may be spent on. reserve_seats.lua
+// (queue_pass_spend.lua on a cluster) takes from it on every reservation and
+// binds the pass to the device that first spends it.
+type QueuePassBudget struct {
+	PassID       string // The pass JWT's ID; only that pass may spend the budget
+	Reservations int
+	Seats        int // 0 = no seat cap
+}
+
+// IsLottery reports whether the queue admits by lottery
+func (c *EventQueueConfig) IsLottery() bool {
+	return c != nil && c.Mode == domain.QueueModeLottery && c.LotteryOpensAt > 0
 }
 
 // JoinQueueParams contains parameters for joining a queue
@@ -82,4 +187,11 @@ type JoinQueueParams struct {
 	Token        string
 	TTLSeconds   int
 	MaxQueueSize int64
+	// LotteryOpensAt is set for lottery queues (unix seconds); joins before
+	// it enter the lottery pool (0 = FIFO)
+	LotteryOpensAt int64
+	// Lane is the priority lane to join ("" = general). Lanes names every
+	// priority lane of the event, so the queue size limit counts them all.
+	Lane  string
+	Lanes []string
 }
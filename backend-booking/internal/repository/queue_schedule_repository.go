@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+)
+
+// queueSchedulesKey is the set of events whose queues follow a schedule
+const queueSchedulesKey = "queue:schedules"
+
+// QueueSchedule is when an event's queue moves through its phases, derived
+// from the sale window the ticket service publishes
+type QueueSchedule struct {
+	EventID  string
+	OpensAt  time.Time // The waiting room opens
+	StartsAt time.Time // The lottery is drawn and release starts
+	EndsAt   time.Time // The queue is drained (zero = never)
+	// Phase is the last phase the queue scheduler applied (domain.QueuePhase*)
+	Phase string
+	// UpdatedAt is when the ticket service published the sale window
+	UpdatedAt time.Time
+}
+
+// PhaseAt returns the phase the queue should be in at now
+func (s *QueueSchedule) PhaseAt(now time.Time) string {
+	return domain.QueuePhaseAt(now, s.OpensAt, s.StartsAt, s.EndsAt)
+}
+
+// QueueScheduleStore keeps the schedules the queue scheduler drives queues by
+type QueueScheduleStore interface {
+	// GetQueueSchedule gets an event's queue schedule (nil = unscheduled)
+	GetQueueSchedule(ctx context.Context, eventID string) (*QueueSchedule, error)
+
+	// SetQueueSchedule creates or replaces an event's queue schedule
+	SetQueueSchedule(ctx context.Context, schedule *QueueSchedule) error
+
+	// DeleteQueueSchedule stops an event's queue from following a schedule
+	DeleteQueueSchedule(ctx context.Context, eventID string) error
+
+	// ListQueueSchedules lists every event's queue schedule
+	ListQueueSchedules(ctx context.Context) ([]*QueueSchedule, error)
+}
+
+// queueScheduleKey returns the hash holding an event's queue schedule
+func queueScheduleKey(eventID string) string {
+	return fmt.Sprintf("queue:schedule:%s", eventID)
+}
+
+// GetQueueSchedule gets an event's queue schedule
+func (r *RedisQueueRepository) GetQueueSchedule(ctx context.Context, eventID string) (*QueueSchedule, error) {
+	result, err := r.client.HGetAll(ctx, queueScheduleKey(eventID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue schedule: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return &QueueSchedule{
+		EventID:   eventID,
+		OpensAt:   parseUnixField(result["opens_at"]),
+		StartsAt:  parseUnixField(result["starts_at"]),
+		EndsAt:    parseUnixField(result["ends_at"]),
+		Phase:     result["phase"],
+		UpdatedAt: time.UnixMilli(parseIntField(result["updated_at"])),
+	}, nil
+}
+
+// SetQueueSchedule stores an event's queue schedule and indexes it
+func (r *RedisQueueRepository) SetQueueSchedule(ctx context.Context, schedule *QueueSchedule) error {
+	var endsAt int64
+	if !schedule.EndsAt.IsZero() {
+		endsAt = schedule.EndsAt.Unix()
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.HSet(ctx, queueScheduleKey(schedule.EventID),
+		"opens_at", schedule.OpensAt.Unix(),
+		"starts_at", schedule.StartsAt.Unix(),
+		"ends_at", endsAt,
+		"phase", schedule.Phase,
+		"updated_at", schedule.UpdatedAt.UnixMilli(),
+	)
+	pipe.SAdd(ctx, queueSchedulesKey, schedule.EventID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set queue schedule: %w", err)
+	}
+	return nil
+}
+
+// DeleteQueueSchedule deletes an event's queue schedule
+func (r *RedisQueueRepository) DeleteQueueSchedule(ctx context.Context, eventID string) error {
+	pipe := r.client.Pipeline()
+	pipe.Del(ctx, queueScheduleKey(eventID))
+	pipe.SRem(ctx, queueSchedulesKey, eventID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete queue schedule: %w", err)
+	}
+	return nil
+}
+
+// ListQueueSchedules lists the indexed queue schedules, dropping index
+// entries whose schedule is gone
+func (r *RedisQueueRepository) ListQueueSchedules(ctx context.Context) ([]*QueueSchedule, error) {
+	eventIDs, err := r.client.SMembers(ctx, queueSchedulesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue schedules: %w", err)
+	}
+
+	schedules := make([]*QueueSchedule, 0, len(eventIDs))
+	for _, eventID := range eventIDs {
+		schedule, err := r.GetQueueSchedule(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		if schedule == nil {
+			r.client.SRem(ctx, queueSchedulesKey, eventID)
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// parseIntField parses an integer hash field (0 if absent)
+func parseIntField(val string) int64 {
+	n, _ := strconv.ParseInt(val, 10, 64)
+	return n
+}
+
+// parseUnixField parses a unix seconds hash field (zero time if absent or 0)
+func parseUnixField(val string) time.Time {
+	if n := parseIntField(val); n > 0 {
+		return time.Unix(n, 0)
+	}
+	return time.Time{}
+}
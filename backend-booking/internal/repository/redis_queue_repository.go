@@ -2,23 +2,21 @@ package repository
 
 import (
 	"context"
-	_ "embed"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
-//go:embed scripts/join_queue.lua
-var joinQueueScript string
-
-// Script name for caching
-const scriptJoinQueue = "join_queue"
-
 // RedisQueueRepository implements QueueRepository using Redis
 type RedisQueueRepository struct {
 	client *pkgredis.Client
@@ -26,22 +24,49 @@ type RedisQueueRepository struct {
 
 // NewRedisQueueRepository creates a new RedisQueueRepository
 func NewRedisQueueRepository(client *pkgredis.Client) *RedisQueueRepository {
+	client.Scripts().MustRegister(queueScripts...)
 	return &RedisQueueRepository{client: client}
 }
 
-// LoadScripts loads all queue Lua scripts into Redis
-func (r *RedisQueueRepository) LoadScripts(ctx context.Context) error {
-	scripts := map[string]string{
-		scriptJoinQueue: joinQueueScript,
-	}
+// queueKey returns the sorted set holding an event's queue. On Redis Cluster
+// the event ID is a hash tag so join_queue.lua keeps both keys in one slot.
+func (r *RedisQueueRepository) queueKey(eventID string) string {
+	return fmt.Sprintf("queue:%s", r.client.ClusterTag(eventID))
+}
 
-	for name, script := range scripts {
-		if _, err := r.client.LoadScript(ctx, name, script); err != nil {
-			return fmt.Errorf("failed to load script %s: %w", name, err)
-		}
+// userQueueKey returns a user's queue entry for an event
+func (r *RedisQueueRepository) userQueueKey(eventID, userID string) string {
+	return fmt.Sprintf("queue:user:%s:%s", r.client.ClusterTag(eventID), userID)
+}
+
+// lotteryKey returns the set of users awaiting an event's lottery draw, in
+// the queue's slot so join_queue.lua and lottery_draw.lua can use both
+func (r *RedisQueueRepository) lotteryKey(eventID string) string {
+	return fmt.Sprintf("queue:lottery:%s", r.client.ClusterTag(eventID))
+}
+
+// laneKey returns the sorted set of one of an event's priority lanes
+func (r *RedisQueueRepository) laneKey(eventID, lane string) string {
+	return fmt.Sprintf("queue:lane:%s:%s", r.client.ClusterTag(eventID), lane)
+}
+
+// lanesKey returns the set of priority lanes users have joined an event's
+// queue in, so its size and release cover lanes since removed from its config
+func (r *RedisQueueRepository) lanesKey(eventID string) string {
+	return fmt.Sprintf("queue:lanes:%s", r.client.ClusterTag(eventID))
+}
+
+// joinKey returns the sorted set a user queues in: their lane's or the queue's
+func (r *RedisQueueRepository) joinKey(eventID, lane string) string {
+	if lane == "" {
+		return r.queueKey(eventID)
 	}
+	return r.laneKey(eventID, lane)
+}
 
-	return nil
+// LoadScripts loads all queue Lua scripts into Redis
+func (r *RedisQueueRepository) LoadScripts(ctx context.Context) error {
+	return r.client.Scripts().Load(ctx, scriptNames(queueScripts)...)
 }
 
 // JoinQueue adds a user to the queue using Sorted Set
@@ -55,8 +80,8 @@ func (r *RedisQueueRepository) JoinQueue(ctx context.Context, params JoinQueuePa
 	)
 
 	// Build Redis keys
-	queueKey := fmt.Sprintf("queue:%s", params.EventID)
-	userQueueKey := fmt.Sprintf("queue:user:%s:%s", params.EventID, params.UserID)
+	queueKey := r.queueKey(params.EventID)
+	userQueueKey := r.userQueueKey(params.EventID, params.UserID)
 
 	keys := []string{queueKey, userQueueKey}
 	args := []interface{}{
@@ -66,8 +91,26 @@ func (r *RedisQueueRepository) JoinQueue(ctx context.Context, params JoinQueuePa
 		params.TTLSeconds,   // ARGV[4]: ttl_seconds
 		params.MaxQueueSize, // ARGV[5]: max_queue_size
 	}
+	if params.LotteryOpensAt > 0 {
+		keys = append(keys, r.lotteryKey(params.EventID))
+		args = append(args, params.LotteryOpensAt) // ARGV[6]: opens_at
+	} else if len(params.Lanes) > 0 {
+		keys = append(keys, r.lotteryKey(params.EventID), r.lanesKey(params.EventID))
+		laneIndex := 0
+		for i, lane := range params.Lanes {
+			keys = append(keys, r.laneKey(params.EventID, lane))
+			if lane == params.Lane {
+				laneIndex = i + 1
+			}
+		}
+		args = append(args,
+			0,           // ARGV[6]: opens_at
+			params.Lane, // ARGV[7]: lane
+			laneIndex,   // ARGV[8]: lane_index
+		)
+	}
 
-	result := r.client.EvalWithFallback(ctx, scriptJoinQueue, joinQueueScript, keys, args...)
+	result := r.client.Scripts().Run(ctx, scriptJoinQueue, keys, args...)
 	if result.Err() != nil {
 		span.RecordError(result.Err())
 		span.SetStatus(codes.Error, result.Err().Error())
@@ -102,6 +145,7 @@ func (r *RedisQueueRepository) JoinQueue(ctx context.Context, params JoinQueuePa
 			Position:     position,
 			TotalInQueue: totalInQueue,
 			JoinedAt:     joinedAt,
+			InLottery:    position == 0, // Only lottery joins wait for a position
 		}, nil
 	}
 
@@ -127,19 +171,14 @@ func (r *RedisQueueRepository) GetPosition(ctx context.Context, eventID, userID
 		attribute.String("user_id", userID),
 	)
 
-	queueKey := fmt.Sprintf("queue:%s", eventID)
+	queueKey := r.queueKey(eventID)
 
 	// Get user's rank in sorted set (0-indexed)
 	rank, err := r.client.ZRank(ctx, queueKey, userID).Result()
 	if err != nil {
 		// User not in queue
 		if err.Error() == "redis: nil" {
-			span.SetStatus(codes.Ok, "not in queue")
-			return &QueuePositionResult{
-				Position:     0,
-				TotalInQueue: 0,
-				IsInQueue:    false,
-			}, nil
+			return r.getLanePosition(ctx, eventID, userID)
 		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -147,22 +186,114 @@ func (r *RedisQueueRepository) GetPosition(ctx context.Context, eventID, userID
 	}
 
 	// Get total count
-	total, err := r.client.ZCard(ctx, queueKey).Result()
+	sizes, err := r.queueSizes(ctx, eventID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to get queue size: %w", err)
+		return nil, err
 	}
 
 	span.SetAttributes(
 		attribute.Int64("position", rank+1),
+		attribute.Int64("total_in_queue", sizes.total()),
+	)
+	span.SetStatus(codes.Ok, "")
+	result := &QueuePositionResult{
+		Position:     rank + 1, // Convert to 1-indexed
+		TotalInQueue: sizes.total(),
+		IsInQueue:    true,
+	}
+	if len(sizes.lanes) > 0 {
+		result.Lane = domain.QueueLaneGeneral
+		result.TotalInLane = sizes.general
+	}
+	return result, nil
+}
+
+// getLanePosition reports a user who is not in the general lane: either
+// queued in a priority lane, or in the lottery pool or not queued at all
+func (r *RedisQueueRepository) getLanePosition(ctx context.Context, eventID, userID string) (*QueuePositionResult, error) {
+	span := telemetry.SpanFromContext(ctx)
+
+	lane, err := r.client.HGet(ctx, r.userQueueKey(eventID, userID), "lane").Result()
+	if err != nil && err.Error() != "redis: nil" {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get user queue lane: %w", err)
+	}
+	if lane == "" {
+		return r.getLotteryPosition(ctx, eventID, userID)
+	}
+
+	rank, err := r.client.ZRank(ctx, r.laneKey(eventID, lane), userID).Result()
+	if err != nil {
+		if err.Error() == "redis: nil" {
+			span.SetStatus(codes.Ok, "not in queue")
+			return &QueuePositionResult{}, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get lane position: %w", err)
+	}
+
+	sizes, err := r.queueSizes(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("lane", lane),
+		attribute.Int64("position", rank+1),
+		attribute.Int64("total_in_queue", sizes.total()),
+	)
+	span.SetStatus(codes.Ok, "")
+	return &QueuePositionResult{
+		Position:     rank + 1,
+		TotalInQueue: sizes.total(),
+		IsInQueue:    true,
+		Lane:         lane,
+		TotalInLane:  sizes.lanes[lane],
+	}, nil
+}
+
+// getLotteryPosition reports a user who is not in the queue proper: either
+// awaiting the lottery draw, or not queued at all
+func (r *RedisQueueRepository) getLotteryPosition(ctx context.Context, eventID, userID string) (*QueuePositionResult, error) {
+	span := telemetry.SpanFromContext(ctx)
+
+	inLottery, err := r.client.SIsMember(ctx, r.lotteryKey(eventID), userID).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to check lottery entry: %w", err)
+	}
+	if !inLottery {
+		span.SetStatus(codes.Ok, "not in queue")
+		return &QueuePositionResult{
+			Position:     0,
+			TotalInQueue: 0,
+			IsInQueue:    false,
+		}, nil
+	}
+
+	total, err := r.GetQueueSize(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Bool("in_lottery", true),
 		attribute.Int64("total_in_queue", total),
 	)
 	span.SetStatus(codes.Ok, "")
 	return &QueuePositionResult{
-		Position:     rank + 1, // Convert to 1-indexed
 		TotalInQueue: total,
 		IsInQueue:    true,
+		InLottery:    true,
 	}, nil
 }
 
@@ -177,32 +308,42 @@ func (r *RedisQueueRepository) LeaveQueue(ctx context.Context, eventID, userID,
 	)
 
 	// First verify the token
-	userQueueKey := fmt.Sprintf("queue:user:%s:%s", eventID, userID)
-	storedToken, err := r.client.HGet(ctx, userQueueKey, "token").Result()
+	userQueueKey := r.userQueueKey(eventID, userID)
+	entry, err := r.client.HGetAll(ctx, userQueueKey).Result()
 	if err != nil {
-		if err.Error() == "redis: nil" {
-			span.SetStatus(codes.Error, "not in queue")
-			return domain.ErrNotInQueue
-		}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to get user queue info: %w", err)
 	}
+	storedToken, lane := entry["token"], entry["lane"]
+	if storedToken == "" {
+		span.SetStatus(codes.Error, "not in queue")
+		return domain.ErrNotInQueue
+	}
 
 	if storedToken != token {
 		span.SetStatus(codes.Error, "invalid token")
 		return domain.ErrInvalidQueueToken
 	}
 
-	// Remove from sorted set
-	queueKey := fmt.Sprintf("queue:%s", eventID)
-	removed, err := r.client.ZRem(ctx, queueKey, userID).Result()
+	// Remove from the sorted set of the user's lane
+	removed, err := r.client.ZRem(ctx, r.joinKey(eventID, lane), userID).Result()
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to remove from queue: %w", err)
 	}
 
+	if removed == 0 && lane == "" {
+		// Users awaiting the lottery draw are in the pool instead
+		removed, err = r.client.SRem(ctx, r.lotteryKey(eventID), userID).Result()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return fmt.Errorf("failed to remove from lottery: %w", err)
+		}
+	}
+
 	if removed == 0 {
 		span.SetStatus(codes.Error, "not in queue")
 		return domain.ErrNotInQueue
@@ -222,22 +363,68 @@ func (r *RedisQueueRepository) GetQueueSize(ctx context.Context, eventID string)
 
 	span.SetAttributes(attribute.String("event_id", eventID))
 
-	queueKey := fmt.Sprintf("queue:%s", eventID)
-	count, err := r.client.ZCard(ctx, queueKey).Result()
+	sizes, err := r.queueSizes(ctx, eventID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return 0, fmt.Errorf("failed to get queue size: %w", err)
+		return 0, err
 	}
+	count := sizes.total()
 
 	span.SetAttributes(attribute.Int64("count", count))
 	span.SetStatus(codes.Ok, "")
 	return count, nil
 }
 
+// queueSizes counts an event's queue by lane
+type queueSizes struct {
+	general int64
+	lottery int64
+	lanes   map[string]int64 // Priority lanes users have joined
+}
+
+// total returns the users in the queue; those awaiting a lottery draw count as queued
+func (s *queueSizes) total() int64 {
+	total := s.general + s.lottery
+	for _, size := range s.lanes {
+		total += size
+	}
+	return total
+}
+
+// queueSizes counts the general lane, the lottery pool and each priority
+// lane; events without lanes take a single round trip
+func (r *RedisQueueRepository) queueSizes(ctx context.Context, eventID string) (*queueSizes, error) {
+	pipe := r.client.Pipeline()
+	queued := pipe.ZCard(ctx, r.queueKey(eventID))
+	pooled := pipe.SCard(ctx, r.lotteryKey(eventID))
+	laneNames := pipe.SMembers(ctx, r.lanesKey(eventID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get queue size: %w", err)
+	}
+	sizes := &queueSizes{general: queued.Val(), lottery: pooled.Val()}
+	if len(laneNames.Val()) == 0 {
+		return sizes, nil
+	}
+
+	pipe = r.client.Pipeline()
+	laneSizes := make(map[string]*redis.IntCmd, len(laneNames.Val()))
+	for _, lane := range laneNames.Val() {
+		laneSizes[lane] = pipe.ZCard(ctx, r.laneKey(eventID, lane))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get lane sizes: %w", err)
+	}
+	sizes.lanes = make(map[string]int64, len(laneSizes))
+	for lane, size := range laneSizes {
+		sizes.lanes[lane] = size.Val()
+	}
+	return sizes, nil
+}
+
 // GetUserQueueInfo gets the user's queue info (token, joined_at, etc.)
 func (r *RedisQueueRepository) GetUserQueueInfo(ctx context.Context, eventID, userID string) (map[string]string, error) {
-	userQueueKey := fmt.Sprintf("queue:user:%s:%s", eventID, userID)
+	userQueueKey := r.userQueueKey(eventID, userID)
 	result, err := r.client.HGetAll(ctx, userQueueKey).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user queue info: %w", err)
@@ -279,18 +466,35 @@ func (r *RedisQueueRepository) GetQueuePass(ctx context.Context, eventID, userID
 	return queuePass, nil
 }
 
-// StoreQueuePass stores the queue pass token in Redis with TTL
-func (r *RedisQueueRepository) StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, ttl int) error {
+// StoreQueuePass stores the queue pass token in Redis with TTL, next to the
+// budget the reservation scripts spend it from
+func (r *RedisQueueRepository) StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, budget QueuePassBudget, ttl int) error {
 	key := fmt.Sprintf("queue:pass:%s:%s", eventID, userID)
 	ttlDuration := time.Duration(ttl) * time.Second
-	err := r.client.Set(ctx, key, queuePass, ttlDuration).Err()
-	if err != nil {
+
+	budgetKey := queuePassBudgetKey(eventID, userID)
+	pipe := r.client.Pipeline()
+	pipe.Del(ctx, budgetKey) // A new pass starts unbound
+	pipe.HSet(ctx, budgetKey, "pass_id", budget.PassID, "reservations", budget.Reservations)
+	if budget.Seats > 0 {
+		pipe.HSet(ctx, budgetKey, "seats", budget.Seats)
+	}
+	pipe.Expire(ctx, budgetKey, ttlDuration)
+	pipe.Set(ctx, key, queuePass, ttlDuration)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to store queue pass: %w", err)
 	}
 
 	return nil
 }
 
+// queuePassBudgetKey returns the hash of a queue pass's usage budget. It is
+// outside "queue:pass:{event_id}:*" so CountActiveQueuePasses does not count
+// it, and the reservation repository spends it under the same name.
+func queuePassBudgetKey(eventID, userID string) string {
+	return fmt.Sprintf("queue:pass:budget:%s:%s", eventID, userID)
+}
+
 // ValidateQueuePass validates if the queue pass is valid and not expired
 func (r *RedisQueueRepository) ValidateQueuePass(ctx context.Context, eventID, userID, queuePass string) (bool, error) {
 	key := fmt.Sprintf("queue:pass:%s:%s", eventID, userID)
@@ -305,19 +509,28 @@ func (r *RedisQueueRepository) ValidateQueuePass(ctx context.Context, eventID, u
 	return storedPass == queuePass, nil
 }
 
-// DeleteQueuePass deletes the queue pass after successful booking
+// DeleteQueuePass deletes the queue pass and its budget
 func (r *RedisQueueRepository) DeleteQueuePass(ctx context.Context, eventID, userID string) error {
 	key := fmt.Sprintf("queue:pass:%s:%s", eventID, userID)
 	err := r.client.Del(ctx, key).Err()
 	if err != nil {
 		return fmt.Errorf("failed to delete queue pass: %w", err)
 	}
+	// Deleted separately: on a cluster the budget is in another slot
+	if err := r.client.Del(ctx, queuePassBudgetKey(eventID, userID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete queue pass budget: %w", err)
+	}
 	return nil
 }
 
 // PopUsersFromQueue pops the first N users from the queue (lowest scores = earliest joined)
 func (r *RedisQueueRepository) PopUsersFromQueue(ctx context.Context, eventID string, count int64) ([]string, error) {
-	queueKey := fmt.Sprintf("queue:%s", eventID)
+	return r.PopUsersFromLane(ctx, eventID, "", count)
+}
+
+// PopUsersFromLane pops the first N users from a priority lane ("" = general)
+func (r *RedisQueueRepository) PopUsersFromLane(ctx context.Context, eventID, lane string, count int64) ([]string, error) {
+	queueKey := r.joinKey(eventID, lane)
 
 	// Get users with lowest scores (earliest joined)
 	result, err := r.client.ZRange(ctx, queueKey, 0, count-1).Result()
@@ -336,45 +549,53 @@ func (r *RedisQueueRepository) PopUsersFromQueue(ctx context.Context, eventID st
 
 	// Clean up user queue info for each user
 	for _, userID := range result {
-		userQueueKey := fmt.Sprintf("queue:user:%s:%s", eventID, userID)
+		userQueueKey := r.userQueueKey(eventID, userID)
 		r.client.Del(ctx, userQueueKey)
 	}
 
 	return result, nil
 }
 
-// GetAllQueueEventIDs returns all event IDs that have active queues
+// GetAllQueueEventIDs returns all event IDs that have active queues,
+// including lottery queues whose users still await the draw and queues
+// with users only in priority lanes
 func (r *RedisQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]string, error) {
 	// Scan for all queue keys matching pattern "queue:*"
-	// But exclude user-specific keys "queue:user:*" and "queue:pass:*"
+	// But exclude user-specific keys "queue:user:*", "queue:pass:*", the
+	// per-event configs "queue:config:*" and states "queue:control:*", lane
+	// registries "queue:lanes:*", join screening counters "queue:screen:*"
+	// and schedules "queue:schedule:*" with their index "queue:schedules"
 	var eventIDs []string
-	var cursor uint64
-
-	for {
-		keys, nextCursor, err := r.client.Scan(ctx, cursor, "queue:*", 100).Result()
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan queue keys: %w", err)
-		}
-
+	seen := make(map[string]bool)
+	err := r.client.ScanKeys(ctx, "queue:*", 100, func(keys []string) error {
 		for _, key := range keys {
-			// Skip user-specific keys
-			if len(key) > 11 && key[6:10] == "user" {
+			if strings.HasPrefix(key, "queue:user:") ||
+				strings.HasPrefix(key, "queue:pass:") ||
+				strings.HasPrefix(key, "queue:config:") ||
+				strings.HasPrefix(key, "queue:control:") ||
+				strings.HasPrefix(key, "queue:lanes:") ||
+				strings.HasPrefix(key, "queue:screen:") ||
+				strings.HasPrefix(key, "queue:schedule") {
 				continue
 			}
-			if len(key) > 11 && key[6:10] == "pass" {
-				continue
+			// Extract event ID from "queue:{eventID}", "queue:lottery:{eventID}"
+			// or "queue:lane:{eventID}:{lane}"
+			eventID := strings.TrimPrefix(strings.TrimPrefix(key, "queue:"), "lottery:")
+			if laneKey := strings.TrimPrefix(eventID, "lane:"); laneKey != eventID {
+				if i := strings.LastIndex(laneKey, ":"); i > 0 {
+					eventID = laneKey[:i]
+				}
 			}
-			// Extract event ID from "queue:{eventID}"
-			if len(key) > 6 {
-				eventID := key[6:] // Remove "queue:" prefix
+			eventID = strings.Trim(eventID, "{}") // Remove hash tag
+			if eventID != "" && !seen[eventID] {
+				seen[eventID] = true
 				eventIDs = append(eventIDs, eventID)
 			}
 		}
-
-		cursor = nextCursor
-		if cursor == 0 {
-			break
-		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan queue keys: %w", err)
 	}
 
 	return eventIDs, nil
@@ -382,13 +603,25 @@ func (r *RedisQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]strin
 
 // RemoveUserFromQueue removes a user from the queue without token verification
 func (r *RedisQueueRepository) RemoveUserFromQueue(ctx context.Context, eventID, userID string) error {
-	queueKey := fmt.Sprintf("queue:%s", eventID)
-	userQueueKey := fmt.Sprintf("queue:user:%s:%s", eventID, userID)
+	queueKey := r.queueKey(eventID)
+	userQueueKey := r.userQueueKey(eventID, userID)
 
-	// Remove from sorted set
+	// Remove from sorted set, the user's lane and the lottery pool
 	if _, err := r.client.ZRem(ctx, queueKey, userID).Result(); err != nil {
 		return fmt.Errorf("failed to remove from queue: %w", err)
 	}
+	lane, err := r.client.HGet(ctx, userQueueKey, "lane").Result()
+	if err != nil && err.Error() != "redis: nil" {
+		return fmt.Errorf("failed to get user queue lane: %w", err)
+	}
+	if lane != "" {
+		if _, err := r.client.ZRem(ctx, r.laneKey(eventID, lane), userID).Result(); err != nil {
+			return fmt.Errorf("failed to remove from lane: %w", err)
+		}
+	}
+	if _, err := r.client.SRem(ctx, r.lotteryKey(eventID), userID).Result(); err != nil {
+		return fmt.Errorf("failed to remove from lottery: %w", err)
+	}
 
 	// Remove user queue info
 	r.client.Del(ctx, userQueueKey)
@@ -396,6 +629,15 @@ func (r *RedisQueueRepository) RemoveUserFromQueue(ctx context.Context, eventID,
 	return nil
 }
 
+// GetQueueLanes returns the priority lanes users have joined an event's queue in
+func (r *RedisQueueRepository) GetQueueLanes(ctx context.Context, eventID string) ([]string, error) {
+	lanes, err := r.client.SMembers(ctx, r.lanesKey(eventID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue lanes: %w", err)
+	}
+	return lanes, nil
+}
+
 // Helper function to convert []string to []interface{} for ZRem
 func stringSliceToInterface(s []string) []interface{} {
 	result := make([]interface{}, len(s))
@@ -409,20 +651,12 @@ func stringSliceToInterface(s []string) []interface{} {
 func (r *RedisQueueRepository) CountActiveQueuePasses(ctx context.Context, eventID string) (int64, error) {
 	pattern := fmt.Sprintf("queue:pass:%s:*", eventID)
 	var count int64
-	var cursor uint64
-
-	for {
-		keys, nextCursor, err := r.client.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			return 0, fmt.Errorf("failed to scan queue passes: %w", err)
-		}
-
+	err := r.client.ScanKeys(ctx, pattern, 100, func(keys []string) error {
 		count += int64(len(keys))
-		cursor = nextCursor
-
-		if cursor == 0 {
-			break
-		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan queue passes: %w", err)
 	}
 
 	return count, nil
@@ -447,6 +681,24 @@ func (r *RedisQueueRepository) GetEventQueueConfig(ctx context.Context, eventID
 	if val, ok := result["queue_pass_ttl_minutes"]; ok {
 		fmt.Sscanf(val, "%d", &config.QueuePassTTLMinutes)
 	}
+	config.Mode = result["mode"]
+	if val, ok := result["lottery_opens_at"]; ok {
+		fmt.Sscanf(val, "%d", &config.LotteryOpensAt)
+	}
+	if val, ok := result["release_rate_override"]; ok {
+		fmt.Sscanf(val, "%d", &config.ReleaseRateOverride)
+	}
+	if val, ok := result["pass_reservations"]; ok {
+		fmt.Sscanf(val, "%d", &config.PassReservations)
+	}
+	if val, ok := result["pass_seats"]; ok {
+		fmt.Sscanf(val, "%d", &config.PassSeats)
+	}
+	if val := result["lanes"]; val != "" {
+		if err := json.Unmarshal([]byte(val), &config.Lanes); err != nil {
+			return nil, fmt.Errorf("failed to parse queue lanes: %w", err)
+		}
+	}
 
 	return config, nil
 }
@@ -454,9 +706,23 @@ func (r *RedisQueueRepository) GetEventQueueConfig(ctx context.Context, eventID
 // SetEventQueueConfig sets the queue configuration for an event in Redis cache
 func (r *RedisQueueRepository) SetEventQueueConfig(ctx context.Context, eventID string, config *EventQueueConfig) error {
 	key := fmt.Sprintf("queue:config:%s", eventID)
+	lanes := ""
+	if len(config.Lanes) > 0 {
+		data, err := json.Marshal(config.Lanes)
+		if err != nil {
+			return fmt.Errorf("failed to encode queue lanes: %w", err)
+		}
+		lanes = string(data)
+	}
 	err := r.client.HSet(ctx, key,
 		"max_concurrent_bookings", config.MaxConcurrentBookings,
 		"queue_pass_ttl_minutes", config.QueuePassTTLMinutes,
+		"mode", config.Mode,
+		"lottery_opens_at", config.LotteryOpensAt,
+		"release_rate_override", config.ReleaseRateOverride,
+		"lanes", lanes,
+		"pass_reservations", config.PassReservations,
+		"pass_seats", config.PassSeats,
 	).Err()
 	if err != nil {
 		return fmt.Errorf("failed to set event queue config: %w", err)
@@ -464,5 +730,57 @@ func (r *RedisQueueRepository) SetEventQueueConfig(ctx context.Context, eventID
 	return nil
 }
 
+// DrawLottery shuffles an event's lottery pool into the queue via lottery_draw.lua
+func (r *RedisQueueRepository) DrawLottery(ctx context.Context, eventID string, opensAt int64) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.queue.draw_lottery")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	seed, err := lotterySeed()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	keys := []string{r.queueKey(eventID), r.lotteryKey(eventID)}
+	values, err := r.client.Scripts().Run(ctx, scriptLotteryDraw, keys, opensAt, seed).Slice()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to execute lottery_draw script: %w", err)
+	}
+	if len(values) < 3 {
+		span.SetStatus(codes.Error, "unexpected result length")
+		return 0, fmt.Errorf("unexpected script result length: %d", len(values))
+	}
+
+	if success, _ := toInt64(values[0]); success != 1 {
+		errorCode, _ := values[1].(string)
+		span.SetStatus(codes.Error, errorCode)
+		if errorCode == "NOT_OPEN" {
+			return 0, domain.ErrQueueNotOpen
+		}
+		errorMessage, _ := values[2].(string)
+		return 0, fmt.Errorf("lottery_draw failed: %s: %s", errorCode, errorMessage)
+	}
+
+	drawn, _ := toInt64(values[1])
+	span.SetAttributes(attribute.Int64("drawn", drawn))
+	span.SetStatus(codes.Ok, "")
+	return drawn, nil
+}
+
+// lotterySeed returns a random seed for lottery_draw.lua. Redis seeds its
+// Lua PRNG with 32 bits, so that is all the seed carries.
+func lotterySeed() (int64, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("failed to generate lottery seed: %w", err)
+	}
+	return int64(binary.BigEndian.Uint32(b[:]) >> 1), nil
+}
+
 // Ensure RedisQueueRepository implements QueueRepository
 var _ QueueRepository = (*RedisQueueRepository)(nil)
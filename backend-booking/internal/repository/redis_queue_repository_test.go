@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+func TestRedisQueueRepository_Lottery(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	repo := NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	opensAt := time.Now().Add(time.Hour).Unix()
+	join := func(userID string) *JoinQueueResult {
+		t.Helper()
+		result, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID:         userID,
+			EventID:        "event-1",
+			Token:          "token-" + userID,
+			TTLSeconds:     1800,
+			LotteryOpensAt: opensAt,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("JoinQueue(%s) = %+v, %v", userID, result, err)
+		}
+		return result
+	}
+
+	for _, userID := range []string{"u1", "u2", "u3", "u4"} {
+		if result := join(userID); !result.InLottery || result.Position != 0 {
+			t.Fatalf("JoinQueue(%s) = %+v, want a lottery entry", userID, result)
+		}
+	}
+	if err := repo.LeaveQueue(ctx, "event-1", "u4", "token-u4"); err != nil {
+		t.Fatalf("LeaveQueue() error = %v", err)
+	}
+
+	position, err := repo.GetPosition(ctx, "event-1", "u1")
+	if err != nil || !position.IsInQueue || !position.InLottery || position.TotalInQueue != 3 {
+		t.Fatalf("GetPosition() = %+v, %v, want u1 awaiting the draw of 3", position, err)
+	}
+	if ids, err := repo.GetAllQueueEventIDs(ctx); err != nil || len(ids) != 1 || ids[0] != "event-1" {
+		t.Fatalf("GetAllQueueEventIDs() = %v, %v, want the pooled event", ids, err)
+	}
+
+	if _, err := repo.DrawLottery(ctx, "event-1", opensAt); !errors.Is(err, domain.ErrQueueNotOpen) {
+		t.Fatalf("DrawLottery() before opening error = %v, want %v", err, domain.ErrQueueNotOpen)
+	}
+
+	// At opening the pool is drawn ahead of anyone who joins afterwards
+	mr.SetTime(time.Unix(opensAt, 0))
+	if result := join("late"); result.InLottery {
+		t.Fatalf("JoinQueue(late) = %+v, want a FIFO entry after opening", result)
+	}
+	drawn, err := repo.DrawLottery(ctx, "event-1", opensAt)
+	if err != nil || drawn != 3 {
+		t.Fatalf("DrawLottery() = %d, %v, want 3", drawn, err)
+	}
+
+	released, err := repo.PopUsersFromQueue(ctx, "event-1", 4)
+	if err != nil || len(released) != 4 || released[3] != "late" {
+		t.Fatalf("PopUsersFromQueue() = %v, %v, want the drawn users then late", released, err)
+	}
+	winners := append([]string{}, released[:3]...)
+	sort.Strings(winners)
+	if winners[0] != "u1" || winners[1] != "u2" || winners[2] != "u3" {
+		t.Errorf("drawn users = %v, want u1, u2 and u3", released[:3])
+	}
+}
+
+func TestRedisQueueRepository_Lanes(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	repo := NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	lanes := []string{"access", "vip"}
+	join := func(userID, lane string) *JoinQueueResult {
+		t.Helper()
+		result, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID:     userID,
+			EventID:    "event-1",
+			Token:      "token-" + userID,
+			TTLSeconds: 1800,
+			Lane:       lane,
+			Lanes:      lanes,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("JoinQueue(%s) = %+v, %v", userID, result, err)
+		}
+		return result
+	}
+
+	join("g1", "")
+	join("g2", "")
+	if result := join("v1", "vip"); result.Position != 1 || result.TotalInQueue != 3 {
+		t.Fatalf("JoinQueue(v1) = %+v, want position 1 of the lane, 3 queued", result)
+	}
+	join("v2", "vip")
+
+	// A user queues in one lane only
+	again, err := repo.JoinQueue(ctx, JoinQueueParams{
+		UserID: "v1", EventID: "event-1", Token: "again", TTLSeconds: 1800, Lanes: lanes,
+	})
+	if err != nil || again.ErrorCode != "ALREADY_IN_QUEUE" {
+		t.Fatalf("JoinQueue(v1) again = %+v, %v, want ALREADY_IN_QUEUE", again, err)
+	}
+
+	position, err := repo.GetPosition(ctx, "event-1", "v2")
+	if err != nil || position.Lane != "vip" || position.Position != 2 || position.TotalInLane != 2 || position.TotalInQueue != 4 {
+		t.Fatalf("GetPosition(v2) = %+v, %v, want 2 of 2 in vip, 4 queued", position, err)
+	}
+	position, err = repo.GetPosition(ctx, "event-1", "g2")
+	if err != nil || position.Lane != domain.QueueLaneGeneral || position.Position != 2 || position.TotalInLane != 2 {
+		t.Fatalf("GetPosition(g2) = %+v, %v, want 2 of 2 in the general lane", position, err)
+	}
+
+	if joined, err := repo.GetQueueLanes(ctx, "event-1"); err != nil || len(joined) != 1 || joined[0] != "vip" {
+		t.Fatalf("GetQueueLanes() = %v, %v, want vip", joined, err)
+	}
+
+	if err := repo.LeaveQueue(ctx, "event-1", "v1", "token-v1"); err != nil {
+		t.Fatalf("LeaveQueue() error = %v", err)
+	}
+	released, err := repo.PopUsersFromLane(ctx, "event-1", "vip", 5)
+	if err != nil || len(released) != 1 || released[0] != "v2" {
+		t.Fatalf("PopUsersFromLane(vip) = %v, %v, want v2", released, err)
+	}
+	if size, err := repo.GetQueueSize(ctx, "event-1"); err != nil || size != 2 {
+		t.Fatalf("GetQueueSize() = %d, %v, want the 2 general users", size, err)
+	}
+}
+
+func TestRedisQueueRepository_ParkedJoins(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	repo := NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	// Every configured lane plus the parked lane fits join_queue.lua
+	lanes := []string{"l1", "l2", "l3", "l4", domain.QueueLaneParked}
+	join := func(userID, lane string) *JoinQueueResult {
+		t.Helper()
+		result, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID: userID, EventID: "event-1", Token: "token-" + userID, TTLSeconds: 1800, Lane: lane, Lanes: lanes,
+		})
+		if err != nil {
+			t.Fatalf("JoinQueue(%s) error = %v", userID, err)
+		}
+		return result
+	}
+
+	join("bot", domain.QueueLaneParked)
+	join("fan", "")
+	if result := join("bot", ""); result.ErrorCode != "ALREADY_IN_QUEUE" {
+		t.Fatalf("JoinQueue(bot) again = %+v, want ALREADY_IN_QUEUE", result)
+	}
+	position, err := repo.GetPosition(ctx, "event-1", "bot")
+	if err != nil || position.Lane != domain.QueueLaneParked || position.Position != 1 || position.TotalInQueue != 2 {
+		t.Fatalf("GetPosition(bot) = %+v, %v, want 1 of the parked lane, 2 queued", position, err)
+	}
+}
+
+func TestRedisQueueRepository_JoinScreenStore(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	repo := NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		if count, err := repo.CountJoin(ctx, "event-1", "ip", "fp-1", time.Minute); err != nil || count != want {
+			t.Fatalf("CountJoin() = %d, %v, want %d", count, err, want)
+		}
+	}
+	// The window runs from the first join
+	mr.FastForward(time.Minute)
+	if count, err := repo.CountJoin(ctx, "event-1", "ip", "fp-1", time.Minute); err != nil || count != 1 {
+		t.Fatalf("CountJoin() in a new window = %d, %v, want 1", count, err)
+	}
+
+	for _, tt := range []struct {
+		userID string
+		want   int64
+	}{{"u1", 1}, {"u2", 2}, {"u1", 2}} {
+		if users, err := repo.AddDeviceUser(ctx, "event-1", "device-1", tt.userID, time.Hour); err != nil || users != tt.want {
+			t.Fatalf("AddDeviceUser(%s) = %d, %v, want %d", tt.userID, users, err, tt.want)
+		}
+	}
+}
+
+func TestRedisQueueRepository_QueueControl(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	repo := NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	if control, err := repo.GetQueueControl(ctx, "event-1"); err != nil || control != nil {
+		t.Fatalf("GetQueueControl() = %+v, %v, want nil before any is set", control, err)
+	}
+	updatedAt := time.Unix(1_700_000_000, 0)
+	if err := repo.SetQueueControl(ctx, "event-1", &QueueControl{State: domain.QueueStatePaused, Message: "Back soon", UpdatedBy: "admin-1", UpdatedAt: updatedAt}); err != nil {
+		t.Fatalf("SetQueueControl() error = %v", err)
+	}
+	control, err := repo.GetQueueControl(ctx, "event-1")
+	if err != nil || !control.IsPaused() || control.Message != "Back soon" || !control.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("GetQueueControl() = %+v, %v, want paused with its message", control, err)
+	}
+
+	t.Run("drain empties every lane and the lottery pool", func(t *testing.T) {
+		lanes := []string{"vip", domain.QueueLaneParked}
+		for userID, lane := range map[string]string{"fan": "", "vip-1": "vip", "bot": domain.QueueLaneParked} {
+			if _, err := repo.JoinQueue(ctx, JoinQueueParams{
+				UserID: userID, EventID: "event-1", Token: "token-" + userID, TTLSeconds: 1800, Lane: lane, Lanes: lanes,
+			}); err != nil {
+				t.Fatalf("JoinQueue(%s) error = %v", userID, err)
+			}
+		}
+		if _, err := repo.JoinQueue(ctx, JoinQueueParams{
+			UserID: "early", EventID: "event-2", Token: "token", TTLSeconds: 1800, LotteryOpensAt: time.Now().Add(time.Hour).Unix(),
+		}); err != nil {
+			t.Fatalf("JoinQueue(early) error = %v", err)
+		}
+
+		for eventID, want := range map[string]int64{"event-1": 3, "event-2": 1} {
+			if drained, err := repo.DrainQueue(ctx, eventID); err != nil || drained != want {
+				t.Fatalf("DrainQueue(%s) = %d, %v, want %d", eventID, drained, err, want)
+			}
+			if size, err := repo.GetQueueSize(ctx, eventID); err != nil || size != 0 {
+				t.Fatalf("GetQueueSize(%s) = %d, %v, want 0", eventID, size, err)
+			}
+		}
+		if info, _ := repo.GetUserQueueInfo(ctx, "event-1", "vip-1"); len(info) != 0 {
+			t.Errorf("GetUserQueueInfo(vip-1) = %v, want it deleted", info)
+		}
+	})
+
+	t.Run("expire passes deletes them with their budgets", func(t *testing.T) {
+		for _, pass := range []struct{ eventID, userID string }{{"event-1", "u1"}, {"event-1", "u2"}, {"event-2", "u1"}} {
+			if err := repo.StoreQueuePass(ctx, pass.eventID, pass.userID, "pass", QueuePassBudget{PassID: "p", Reservations: 1}, 300); err != nil {
+				t.Fatalf("StoreQueuePass() error = %v", err)
+			}
+		}
+
+		if expired, err := repo.ExpireQueuePasses(ctx, "event-1"); err != nil || expired != 2 {
+			t.Fatalf("ExpireQueuePasses() = %d, %v, want 2", expired, err)
+		}
+		if mr.Exists(queuePassBudgetKey("event-1", "u1")) {
+			t.Error("budget of an expired pass was kept")
+		}
+		for eventID, want := range map[string]int64{"event-1": 0, "event-2": 1} {
+			if count, err := repo.CountActiveQueuePasses(ctx, eventID); err != nil || count != want {
+				t.Errorf("CountActiveQueuePasses(%s) = %d, %v, want %d", eventID, count, err, want)
+			}
+		}
+	})
+
+	t.Run("control and screening keys are not queues", func(t *testing.T) {
+		if _, err := repo.CountJoin(ctx, "event-3", "ip", "fp-1", time.Minute); err != nil {
+			t.Fatalf("CountJoin() error = %v", err)
+		}
+		eventIDs, err := repo.GetAllQueueEventIDs(ctx)
+		if err != nil || len(eventIDs) != 0 {
+			t.Errorf("GetAllQueueEventIDs() = %v, %v, want none", eventIDs, err)
+		}
+	})
+}
+
+func TestRedisQueueRepository_QueueSchedule(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	repo := NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	if schedule, err := repo.GetQueueSchedule(ctx, "event-1"); err != nil || schedule != nil {
+		t.Fatalf("GetQueueSchedule() = %+v, %v, want nil before any is set", schedule, err)
+	}
+
+	startsAt := time.Unix(1_800_000_000, 0)
+	want := &QueueSchedule{
+		EventID:   "event-1",
+		OpensAt:   startsAt.Add(-30 * time.Minute),
+		StartsAt:  startsAt,
+		Phase:     domain.QueuePhasePending,
+		UpdatedAt: time.UnixMilli(1_700_000_000_123),
+	}
+	if err := repo.SetQueueSchedule(ctx, want); err != nil {
+		t.Fatalf("SetQueueSchedule() error = %v", err)
+	}
+	got, err := repo.GetQueueSchedule(ctx, "event-1")
+	if err != nil || got == nil {
+		t.Fatalf("GetQueueSchedule() = %+v, %v", got, err)
+	}
+	if !got.OpensAt.Equal(want.OpensAt) || !got.StartsAt.Equal(want.StartsAt) || !got.EndsAt.IsZero() ||
+		got.Phase != want.Phase || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("GetQueueSchedule() = %+v, want %+v", got, want)
+	}
+	if phase := got.PhaseAt(startsAt.Add(-10 * time.Minute)); phase != domain.QueuePhaseWaiting {
+		t.Errorf("PhaseAt(10m before start) = %q, want %q", phase, domain.QueuePhaseWaiting)
+	}
+
+	// A schedule does not make its event look like a queue
+	if eventIDs, err := repo.GetAllQueueEventIDs(ctx); err != nil || len(eventIDs) != 0 {
+		t.Errorf("GetAllQueueEventIDs() = %v, %v, want none", eventIDs, err)
+	}
+
+	schedules, err := repo.ListQueueSchedules(ctx)
+	if err != nil || len(schedules) != 1 || schedules[0].EventID != "event-1" {
+		t.Fatalf("ListQueueSchedules() = %+v, %v, want event-1", schedules, err)
+	}
+	if err := repo.DeleteQueueSchedule(ctx, "event-1"); err != nil {
+		t.Fatalf("DeleteQueueSchedule() error = %v", err)
+	}
+	if schedules, err := repo.ListQueueSchedules(ctx); err != nil || len(schedules) != 0 {
+		t.Errorf("ListQueueSchedules() after delete = %+v, %v, want none", schedules, err)
+	}
+}
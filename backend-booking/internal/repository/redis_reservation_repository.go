@@ -2,58 +2,88 @@ package repository
 
 import (
 	"context"
-	_ "embed"
 	"fmt"
+	"math/rand"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
-)
-
-//go:embed scripts/reserve_seats.lua
-var reserveSeatsScript string
-
-//go:embed scripts/release_seats.lua
-var releaseSeatsScript string
-
-//go:embed scripts/confirm_booking.lua
-var confirmBookingScript string
-
-// Script names for caching
-const (
-	scriptReserveSeats   = "reserve_seats"
-	scriptReleaseSeats   = "release_seats"
-	scriptConfirmBooking = "confirm_booking"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RedisReservationRepository implements ReservationRepository using Redis
 type RedisReservationRepository struct {
 	client *pkgredis.Client
+	keys   reservationKeys
+
+	// availabilityEvents publishes ZoneAvailabilityChannel after every change
+	// to a zone's seat counters
+	availabilityEvents bool
+
+	// identityLimits releases a booking's identity claims together with its
+	// reservation (see IdentityLimitStore)
+	identityLimits bool
+
+	// registered caches the deadline indexes already listed in
+	// deadlineRegistryKey (Redis Cluster only)
+	registered sync.Map
 }
 
-// NewRedisReservationRepository creates a new RedisReservationRepository
+// NewRedisReservationRepository creates a new RedisReservationRepository.
+// Keys are hash-tagged when the client is connected to a Redis Cluster.
 func NewRedisReservationRepository(client *pkgredis.Client) *RedisReservationRepository {
-	return &RedisReservationRepository{client: client}
+	client.Scripts().MustRegister(reservationScripts...)
+	return &RedisReservationRepository{
+		client: client,
+		keys:   reservationKeys{hashTags: client.IsCluster()},
+	}
 }
 
-// LoadScripts loads all Lua scripts into Redis
-func (r *RedisReservationRepository) LoadScripts(ctx context.Context) error {
-	scripts := map[string]string{
-		scriptReserveSeats:   reserveSeatsScript,
-		scriptReleaseSeats:   releaseSeatsScript,
-		scriptConfirmBooking: confirmBookingScript,
-	}
+// WithZoneShards splits every zone's seats across n counters so reservations
+// on a hot zone do not all serialize on one key. A booking reserves from the
+// shard its ID maps to and refills it from the zone pool or sibling shards
+// when it runs short. n <= 1 keeps a single counter per zone.
+func (r *RedisReservationRepository) WithZoneShards(n int) *RedisReservationRepository {
+	r.keys.shards = n
+	return r
+}
 
-	for name, script := range scripts {
-		if _, err := r.client.LoadScript(ctx, name, script); err != nil {
-			return fmt.Errorf("failed to load script %s: %w", name, err)
-		}
+// WithAvailabilityEvents publishes the zone ID to ZoneAvailabilityChannel
+// whenever a reservation, release, expiry or correction changes a zone's
+// available seats, so availability streams can push the new count.
+func (r *RedisReservationRepository) WithAvailabilityEvents(enabled bool) *RedisReservationRepository {
+	r.availabilityEvents = enabled
+	return r
+}
+
+// ZoneAvailabilityChannel returns the pub/sub channel notified when a zone's
+// available seats change. The message is the zone ID; subscribers read the
+// count themselves, so bursts of changes can be coalesced.
+func ZoneAvailabilityChannel(zoneID string) string {
+	return "zone:availability:changed:" + zoneID
+}
+
+// notifyAvailability publishes a zone's availability change. Failures only
+// delay the pushed update, so they are recorded but not returned.
+func (r *RedisReservationRepository) notifyAvailability(ctx context.Context, zoneID string) {
+	if !r.availabilityEvents {
+		return
+	}
+	if err := r.client.Publish(ctx, ZoneAvailabilityChannel(zoneID), zoneID).Err(); err != nil {
+		telemetry.SpanFromContext(ctx).RecordError(err)
 	}
+}
 
-	return nil
+// LoadScripts loads all Lua scripts into Redis
+func (r *RedisReservationRepository) LoadScripts(ctx context.Context) error {
+	return r.client.Scripts().Load(ctx, scriptNames(reservationScripts)...)
 }
 
 // ReserveSeats atomically reserves seats using Lua script
@@ -69,14 +99,59 @@ func (r *RedisReservationRepository) ReserveSeats(ctx context.Context, params Re
 	)
 
 	// Generate booking ID if not provided
-	bookingID := uuid.New().String()
+	bookingID := params.BookingID
+	if bookingID == "" {
+		bookingID = uuid.New().String()
+	}
 
 	// Build Redis keys
-	zoneAvailabilityKey := fmt.Sprintf("zone:availability:%s", params.ZoneID)
-	userReservationsKey := fmt.Sprintf("user:reservations:%s:%s", params.UserID, params.EventID)
-	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
+	zoneAvailabilityKey := r.keys.seatCounter(params.ZoneID, bookingID)
+	userReservationsKey := r.keys.userReservations(params.UserID, params.EventID)
+	reservationKey := r.keys.reservation(params.ZoneID, bookingID)
+	deadlinesKey := r.keys.deadlines(params.ZoneID, bookingID)
+
+	keys := []string{zoneAvailabilityKey, reservationKey, deadlinesKey, userReservationsKey}
+	passBudgetKey := queuePassBudgetKey(params.EventID, params.UserID)
+
+	// Redis Cluster: the user count and the pass budget live in other slots,
+	// so they are taken first and given back if the reservation itself fails
+	var userReserved int64
+	if r.keys.hashTags {
+		tallied, current, err := r.adjustUserTally(ctx, userReservationsKey, int64(params.Quantity), params.MaxPerUser, params.TTLSeconds+60)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if !tallied {
+			span.SetStatus(codes.Error, "USER_LIMIT_EXCEEDED")
+			return &ReserveResult{
+				Success:   false,
+				ErrorCode: "USER_LIMIT_EXCEEDED",
+				ErrorMessage: fmt.Sprintf("User limit exceeded. Current: %d, Requested: %d, Max: %d",
+					current, params.Quantity, params.MaxPerUser),
+			}, nil
+		}
+		userReserved = current
+		keys = keys[:3]
+	}
+	passSpent, passLeft := false, int64(-1)
+	if r.keys.hashTags && params.QueuePassID != "" {
+		values, err := r.spendQueuePass(ctx, passBudgetKey, params, int64(params.Quantity))
+		if err != nil {
+			r.releaseUserTally(ctx, userReservationsKey, int64(params.Quantity))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if success, _ := toInt64(values[0]); success != 1 {
+			r.releaseUserTally(ctx, userReservationsKey, int64(params.Quantity))
+			return r.failedReserve(span, values), nil
+		}
+		passSpent = true
+		passLeft, _ = toInt64(values[1])
+	}
 
-	keys := []string{zoneAvailabilityKey, userReservationsKey, reservationKey}
 	args := []interface{}{
 		params.Quantity,    // ARGV[1]: quantity
 		params.MaxPerUser,  // ARGV[2]: max_per_user
@@ -88,45 +163,66 @@ func (r *RedisReservationRepository) ReserveSeats(ctx context.Context, params Re
 		params.Price,       // ARGV[8]: unit_price
 		params.TTLSeconds,  // ARGV[9]: ttl_seconds
 	}
-
-	result := r.client.EvalWithFallback(ctx, scriptReserveSeats, reserveSeatsScript, keys, args...)
-	if result.Err() != nil {
-		span.RecordError(result.Err())
-		span.SetStatus(codes.Error, result.Err().Error())
-		return nil, fmt.Errorf("failed to execute reserve_seats script: %w", result.Err())
+	if !r.keys.hashTags && params.QueuePassID != "" {
+		keys = append(keys, passBudgetKey)
+		args = append(args,
+			params.QueuePassID,      // ARGV[10]: pass_id
+			params.QueuePassBinding, // ARGV[11]: binding
+		)
 	}
 
-	// Parse result
-	values, err := result.Slice()
+	values, err := r.evalReserve(ctx, keys, args)
+
+	// Sharded zone: the booking's shard ran short, so refill it from the pool
+	// and sibling shards and try once more
+	if err == nil && r.keys.sharded() {
+		values, err = r.refillAndRetryReserve(ctx, params, bookingID, keys, args, values)
+	}
 	if err != nil {
+		r.releaseUserTally(ctx, userReservationsKey, int64(params.Quantity))
+		r.refundQueuePass(ctx, passBudgetKey, params, passSpent)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		return nil, fmt.Errorf("failed to parse script result: %w", err)
-	}
-
-	if len(values) < 3 {
-		span.SetStatus(codes.Error, "unexpected result length")
-		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
+		return nil, err
 	}
 
 	success, _ := toInt64(values[0])
 	if success == 1 {
 		availableSeats, _ := toInt64(values[1])
-		userReserved, _ := toInt64(values[2])
+		if !r.keys.hashTags {
+			userReserved, _ = toInt64(values[2])
+			if params.QueuePassID != "" && len(values) > 3 {
+				passSpent = true
+				passLeft, _ = toInt64(values[3])
+			}
+		} else if err := r.registerDeadlines(ctx, deadlinesKey); err != nil {
+			span.RecordError(err)
+		}
 		span.SetAttributes(
 			attribute.String("booking_id", bookingID),
 			attribute.Int64("available_seats", availableSeats),
 		)
+		r.notifyAvailability(ctx, params.ZoneID)
 		span.SetStatus(codes.Ok, "")
 		return &ReserveResult{
 			Success:        true,
 			BookingID:      bookingID,
 			AvailableSeats: availableSeats,
 			UserReserved:   userReserved,
+			QueuePassSpent: passSpent,
+			QueuePassLeft:  passLeft,
 		}, nil
 	}
 
 	// Error case
+	r.releaseUserTally(ctx, userReservationsKey, int64(params.Quantity))
+	r.refundQueuePass(ctx, passBudgetKey, params, passSpent)
+	return r.failedReserve(span, values), nil
+}
+
+// failedReserve converts a script's {0, error_code, error_message} result to
+// a failed ReserveResult
+func (r *RedisReservationRepository) failedReserve(span trace.Span, values []interface{}) *ReserveResult {
 	errorCode, _ := values[1].(string)
 	errorMessage, _ := values[2].(string)
 	span.SetAttributes(attribute.String("error_code", errorCode))
@@ -135,11 +231,226 @@ func (r *RedisReservationRepository) ReserveSeats(ctx context.Context, params Re
 		Success:      false,
 		ErrorCode:    errorCode,
 		ErrorMessage: errorMessage,
-	}, nil
+	}
+}
+
+// evalReserve runs reserve_seats.lua and returns its result values
+func (r *RedisReservationRepository) evalReserve(ctx context.Context, keys []string, args []interface{}) ([]interface{}, error) {
+	result := r.client.Scripts().Run(ctx, scriptReserveSeats, keys, args...)
+	if result.Err() != nil {
+		return nil, fmt.Errorf("failed to execute reserve_seats script: %w", result.Err())
+	}
+
+	// Parse result
+	values, err := result.Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script result: %w", err)
+	}
+
+	if len(values) < 3 {
+		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
+	}
+
+	return values, nil
+}
+
+// refillAndRetryReserve handles a reservation that failed on its shard for
+// lack of seats: it refills the shard and runs the reservation once more
+func (r *RedisReservationRepository) refillAndRetryReserve(ctx context.Context, params ReserveParams, bookingID string, keys []string, args []interface{}, values []interface{}) ([]interface{}, error) {
+	if success, _ := toInt64(values[0]); success == 1 {
+		return values, nil
+	}
+	errorCode, _ := values[1].(string)
+	if errorCode != "INSUFFICIENT_STOCK" && errorCode != "ZONE_NOT_FOUND" {
+		return values, nil
+	}
+
+	retry, found, err := r.refillShard(ctx, params.ZoneID, r.keys.shardOf(bookingID), int64(params.Quantity))
+	if err != nil {
+		return nil, err
+	}
+	if retry {
+		return r.evalReserve(ctx, keys, args)
+	}
+	if errorCode == "ZONE_NOT_FOUND" && found {
+		// The shard was never used, but the zone exists and is sold out
+		return []interface{}{int64(0), "INSUFFICIENT_STOCK",
+			fmt.Sprintf("Not enough seats available. Available: 0, Requested: %d", params.Quantity)}, nil
+	}
+	return values, nil
+}
+
+// refillShard moves seats into a zone shard until it holds quantity seats,
+// taking a fair share of the pool first and then half of each sibling shard
+// in random order. It reports whether the reservation is worth retrying and
+// whether any counter of the zone exists at all.
+func (r *RedisReservationRepository) refillShard(ctx context.Context, zoneID string, shard int, quantity int64) (bool, bool, error) {
+	target := r.keys.zoneShard(zoneID, shard)
+	have, err := r.client.Get(ctx, target).Int64()
+	if err != nil && err != redis.Nil {
+		return false, false, fmt.Errorf("failed to read zone shard: %w", err)
+	}
+	found := err == nil
+
+	need := quantity - have
+	if need <= 0 {
+		return true, true, nil // Refilled by a concurrent release
+	}
+
+	var moved int64
+	sources := []string{r.keys.zoneAvailability(zoneID)}
+	for _, i := range rand.Perm(r.keys.shards) {
+		if i != shard {
+			sources = append(sources, r.keys.zoneShard(zoneID, i))
+		}
+	}
+	for i, source := range sources {
+		divisor := 2
+		if i == 0 {
+			divisor = r.keys.shards
+		}
+
+		n, err := r.transferSeats(ctx, source, target, need, divisor)
+		if err != nil {
+			return false, found, err
+		}
+		if n < 0 {
+			continue // Source counter does not exist
+		}
+		found = true
+		moved += n
+		need -= n
+		if need <= 0 {
+			break
+		}
+	}
+
+	return moved > 0, found, nil
+}
+
+const (
+	// shardTransferMarkerTTL bounds how long a transfer's applied marker
+	// outlives a caller that could not clear it; journaled transfers must be
+	// replayed within it
+	shardTransferMarkerTTL = 24 * time.Hour
+
+	// shardTransferRecoverAfter is how old a journaled transfer must be
+	// before RecoverShardTransfers replays it, so transfers still in flight
+	// are left to their callers
+	shardTransferRecoverAfter = 30 * time.Second
+)
+
+// transferSeats moves seats from source to target and returns how many
+// moved, or -1 if source does not exist. On a single node shard_transfer.lua
+// moves them in one step. On a cluster the two counters are in different
+// slots: shard_transfer_out.lua takes the seats and journals them in the
+// source's slot, and shard_transfer_in.lua adds them to the target. Seats of
+// a transfer that stopped in between stay journaled until
+// RecoverShardTransfers replays it.
+func (r *RedisReservationRepository) transferSeats(ctx context.Context, source, target string, need int64, divisor int) (int64, error) {
+	if !r.keys.hashTags {
+		moved, err := r.client.Scripts().Run(ctx, scriptShardTransfer, []string{source, target}, need, divisor).Int64()
+		if err != nil {
+			return 0, fmt.Errorf("failed to execute shard_transfer script: %w", err)
+		}
+		return moved, nil
+	}
+
+	transferID := uuid.New().String()
+	journal := r.keys.shardTransfers(source)
+	moved, err := r.client.Scripts().Run(ctx, scriptShardTransferOut, []string{source, journal},
+		need, divisor, transferID, target, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute shard_transfer_out script: %w", err)
+	}
+	if moved <= 0 {
+		return moved, nil
+	}
+	if err := r.applyShardTransfer(ctx, journal, transferID, target, moved); err != nil {
+		return 0, fmt.Errorf("failed to add %d transferred seats to %s (journaled for recovery): %w", moved, target, err)
+	}
+	return moved, nil
+}
+
+// applyShardTransfer adds a journaled transfer's seats to its target, at
+// most once, and then clears the transfer from the journal
+func (r *RedisReservationRepository) applyShardTransfer(ctx context.Context, journal, transferID, target string, seats int64) error {
+	marker := r.keys.shardTransferMarker(target, transferID)
+	if err := r.client.Scripts().Run(ctx, scriptShardTransferIn, []string{target, marker},
+		seats, shardTransferMarkerTTL.Milliseconds()).Err(); err != nil {
+		return err
+	}
+
+	// The marker only has to outlive the journal entry; once the entry is
+	// gone nothing replays the transfer
+	if err := r.client.HDel(ctx, journal, transferID).Err(); err != nil {
+		return nil // Replayed harmlessly by RecoverShardTransfers
+	}
+	_ = r.client.Del(ctx, marker).Err()
+	return nil
+}
+
+// RecoverShardTransfers finishes cluster shard transfers of the given zones
+// that took seats off their source but never added them to their target,
+// e.g. because the process died in between. It returns how many seats were
+// put back. Single-node transfers are atomic and never need recovery.
+func (r *RedisReservationRepository) RecoverShardTransfers(ctx context.Context, zoneIDs []string) (int64, error) {
+	if !r.keys.hashTags || !r.keys.sharded() {
+		return 0, nil
+	}
+
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.recover_shard_transfers")
+	defer span.End()
+
+	var journals []string
+	for _, zoneID := range zoneIDs {
+		journals = append(journals, r.keys.shardTransfers(r.keys.zoneAvailability(zoneID)))
+		for shard := 0; shard < r.keys.shards; shard++ {
+			journals = append(journals, r.keys.shardTransfers(r.keys.zoneShard(zoneID, shard)))
+		}
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(journals))
+	for i, journal := range journals {
+		cmds[i] = pipe.HGetAll(ctx, journal)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to read shard transfer journals: %w", err)
+	}
+
+	cutoff := time.Now().Add(-shardTransferRecoverAfter).UnixMilli()
+	var recovered int64
+	for i, cmd := range cmds {
+		for transferID, entry := range cmd.Val() {
+			// Entry: "{seats}:{now_ms}:{target}"
+			parts := strings.SplitN(entry, ":", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			seats, err1 := strconv.ParseInt(parts[0], 10, 64)
+			at, err2 := strconv.ParseInt(parts[1], 10, 64)
+			if err1 != nil || err2 != nil || at > cutoff {
+				continue // Unreadable, or still in flight
+			}
+			if err := r.applyShardTransfer(ctx, journals[i], transferID, parts[2], seats); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return recovered, fmt.Errorf("failed to recover shard transfer %s: %w", transferID, err)
+			}
+			recovered += seats
+		}
+	}
+
+	span.SetAttributes(attribute.Int64("seats_recovered", recovered))
+	span.SetStatus(codes.Ok, "")
+	return recovered, nil
 }
 
 // ConfirmBooking confirms a reservation and makes it permanent
-func (r *RedisReservationRepository) ConfirmBooking(ctx context.Context, bookingID, userID, paymentID string) (*ConfirmResult, error) {
+func (r *RedisReservationRepository) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*ConfirmResult, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.confirm")
 	defer span.End()
 
@@ -148,11 +459,11 @@ func (r *RedisReservationRepository) ConfirmBooking(ctx context.Context, booking
 		attribute.String("user_id", userID),
 	)
 
-	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
-	keys := []string{reservationKey}
+	reservationKey := r.keys.reservation(zoneID, bookingID)
+	keys := []string{reservationKey, r.keys.deadlines(zoneID, bookingID)}
 	args := []interface{}{bookingID, userID, paymentID}
 
-	result := r.client.EvalWithFallback(ctx, scriptConfirmBooking, confirmBookingScript, keys, args...)
+	result := r.client.Scripts().Run(ctx, scriptConfirmBooking, keys, args...)
 	if result.Err() != nil {
 		span.RecordError(result.Err())
 		span.SetStatus(codes.Error, result.Err().Error())
@@ -197,7 +508,7 @@ func (r *RedisReservationRepository) ConfirmBooking(ctx context.Context, booking
 }
 
 // ReleaseSeats releases reserved seats back to inventory
-func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID, userID string) (*ReleaseResult, error) {
+func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*ReleaseResult, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.release_seats")
 	defer span.End()
 
@@ -207,7 +518,7 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 	)
 
 	// First, get the reservation to find the zone_id and event_id
-	reservationKey := fmt.Sprintf("reservation:%s", bookingID)
+	reservationKey := r.keys.reservation(zoneID, bookingID)
 	reservationData, err := r.client.HGetAll(ctx, reservationKey).Result()
 	if err != nil {
 		span.RecordError(err)
@@ -224,7 +535,7 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 		}, nil
 	}
 
-	zoneID := reservationData["zone_id"]
+	zoneID = reservationData["zone_id"]
 	eventID := reservationData["event_id"]
 
 	span.SetAttributes(
@@ -232,30 +543,62 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 		attribute.String("event_id", eventID),
 	)
 
-	// Build Redis keys
-	zoneAvailabilityKey := fmt.Sprintf("zone:availability:%s", zoneID)
-	userReservationsKey := fmt.Sprintf("user:reservations:%s:%s", userID, eventID)
+	result, err := r.releaseReservation(ctx, bookingID, userID, reservationData, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if result.Success {
+		span.SetAttributes(attribute.Int64("available_seats", result.AvailableSeats))
+		span.SetStatus(codes.Ok, "")
+		return result, nil
+	}
+
+	span.SetAttributes(attribute.String("error_code", result.ErrorCode))
+	span.SetStatus(codes.Error, result.ErrorCode)
+	return result, nil
+}
+
+// releaseReservation runs release_seats.lua for a reservation read from
+// Redis. dueBy, when set, makes the script refuse to release a reservation
+// whose deadline is later than it.
+func (r *RedisReservationRepository) releaseReservation(ctx context.Context, bookingID, userID string, reservationData map[string]string, dueBy *time.Time) (*ReleaseResult, error) {
+	zoneID := reservationData["zone_id"]
+	zoneAvailabilityKey := r.keys.seatCounter(zoneID, bookingID)
+	userReservationsKey := r.keys.userReservations(userID, reservationData["event_id"])
 
-	keys := []string{zoneAvailabilityKey, userReservationsKey, reservationKey}
+	keys := []string{
+		zoneAvailabilityKey,
+		r.keys.reservation(zoneID, bookingID),
+		r.keys.deadlines(zoneID, bookingID),
+	}
 	args := []interface{}{bookingID, userID}
+	if dueBy != nil {
+		args = append(args, dueBy.Unix())
+	}
+	if !r.keys.hashTags {
+		keys = append(keys, userReservationsKey)
+		// Expiry publishes the reservation in the same step (see expireReservation)
+		if dueBy != nil {
+			keys = append(keys, expiredReservationsStream)
+			args = append(args, expiredReservationsMaxLen)
+		}
+	}
 
-	result := r.client.EvalWithFallback(ctx, scriptReleaseSeats, releaseSeatsScript, keys, args...)
+	result := r.client.Scripts().Run(ctx, scriptReleaseSeats, keys, args...)
 	if result.Err() != nil {
-		span.RecordError(result.Err())
-		span.SetStatus(codes.Error, result.Err().Error())
 		return nil, fmt.Errorf("failed to execute release_seats script: %w", result.Err())
 	}
 
 	// Parse result
 	values, err := result.Slice()
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to parse script result: %w", err)
 	}
 
 	if len(values) < 3 {
-		span.SetStatus(codes.Error, "unexpected result length")
 		return nil, fmt.Errorf("unexpected script result length: %d", len(values))
 	}
 
@@ -263,8 +606,12 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 	if success == 1 {
 		availableSeats, _ := toInt64(values[1])
 		userReserved, _ := toInt64(values[2])
-		span.SetAttributes(attribute.Int64("available_seats", availableSeats))
-		span.SetStatus(codes.Ok, "")
+		if r.keys.hashTags {
+			quantity, _ := toInt64(reservationData["quantity"])
+			userReserved = r.releaseUserTally(ctx, userReservationsKey, quantity)
+		}
+		r.releaseBookingIdentities(ctx, reservationData["event_id"], bookingID)
+		r.notifyAvailability(ctx, zoneID)
 		return &ReleaseResult{
 			Success:        true,
 			AvailableSeats: availableSeats,
@@ -275,8 +622,6 @@ func (r *RedisReservationRepository) ReleaseSeats(ctx context.Context, bookingID
 	// Error case
 	errorCode, _ := values[1].(string)
 	errorMessage, _ := values[2].(string)
-	span.SetAttributes(attribute.String("error_code", errorCode))
-	span.SetStatus(codes.Error, errorCode)
 	return &ReleaseResult{
 		Success:      false,
 		ErrorCode:    errorCode,
@@ -291,7 +636,20 @@ func (r *RedisReservationRepository) GetZoneAvailability(ctx context.Context, zo
 
 	span.SetAttributes(attribute.String("zone_id", zoneID))
 
-	key := fmt.Sprintf("zone:availability:%s", zoneID)
+	// Sharded zone: the total is the pool plus every shard
+	if r.keys.sharded() {
+		seats, err := r.GetZoneAvailabilities(ctx, []string{zoneID})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return 0, err
+		}
+		span.SetAttributes(attribute.Int64("available_seats", seats[zoneID]))
+		span.SetStatus(codes.Ok, "")
+		return seats[zoneID], nil // Zone not found returns 0
+	}
+
+	key := r.keys.zoneAvailability(zoneID)
 	result, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err.Error() == "redis: nil" {
@@ -325,26 +683,52 @@ func (r *RedisReservationRepository) SetZoneAvailability(ctx context.Context, zo
 		attribute.Int64("seats", seats),
 	)
 
-	key := fmt.Sprintf("zone:availability:%s", zoneID)
-	err := r.client.Set(ctx, key, seats, 0).Err()
+	key := r.keys.zoneAvailability(zoneID)
+	var err error
+	if r.keys.sharded() {
+		err = r.resetZoneShards(ctx, zoneID, seats)
+	} else {
+		err = r.client.Set(ctx, key, seats, 0).Err()
+	}
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to set zone availability: %w", err)
 	}
 
+	r.notifyAvailability(ctx, zoneID)
 	span.SetStatus(codes.Ok, "")
 	return nil
 }
 
+// resetZoneShards puts all seats of a sharded zone into its pool and clears
+// the shards, which refill from the pool on demand. Shards live in other
+// slots on a cluster, so the reset is atomic on a single node only.
+func (r *RedisReservationRepository) resetZoneShards(ctx context.Context, zoneID string, seats int64) error {
+	pipe := r.client.Pipeline()
+	if !r.keys.hashTags {
+		pipe = r.client.TxPipeline()
+	}
+	// Transfers journaled before the reset must not be replayed on top of it
+	pool := r.keys.zoneAvailability(zoneID)
+	pipe.Set(ctx, pool, seats, 0)
+	pipe.Del(ctx, r.keys.shardTransfers(pool))
+	for i := 0; i < r.keys.shards; i++ {
+		shard := r.keys.zoneShard(zoneID, i)
+		pipe.Del(ctx, shard, r.keys.shardTransfers(shard))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
 // GetReservation gets a reservation by booking ID
-func (r *RedisReservationRepository) GetReservation(ctx context.Context, bookingID string) (map[string]string, error) {
+func (r *RedisReservationRepository) GetReservation(ctx context.Context, bookingID, zoneID string) (map[string]string, error) {
 	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get")
 	defer span.End()
 
 	span.SetAttributes(attribute.String("booking_id", bookingID))
 
-	key := fmt.Sprintf("reservation:%s", bookingID)
+	key := r.keys.reservation(zoneID, bookingID)
 	result, err := r.client.HGetAll(ctx, key).Result()
 	if err != nil {
 		span.RecordError(err)
@@ -366,7 +750,7 @@ func (r *RedisReservationRepository) GetUserReservedCount(ctx context.Context, u
 		attribute.String("event_id", eventID),
 	)
 
-	key := fmt.Sprintf("user:reservations:%s:%s", userID, eventID)
+	key := r.keys.userReservations(userID, eventID)
 	result, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err.Error() == "redis: nil" {
@@ -390,6 +774,541 @@ func (r *RedisReservationRepository) GetUserReservedCount(ctx context.Context, u
 	return count, nil
 }
 
+// ScanHeldSeats returns every reservation hash still in reserved status
+func (r *RedisReservationRepository) ScanHeldSeats(ctx context.Context) ([]HeldSeats, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.scan_held")
+	defer span.End()
+
+	var held []HeldSeats
+	err := r.client.ScanKeys(ctx, "reservation:*", 500, func(keys []string) error {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.SliceCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipe.HMGet(ctx, key, "booking_id", "zone_id", "quantity", "status")
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to read reservations: %w", err)
+		}
+
+		for _, cmd := range cmds {
+			vals, err := cmd.Result()
+			if err != nil || len(vals) != 4 || vals[3] != "reserved" {
+				continue // Expired between SCAN and HMGET, or not a holding reservation
+			}
+			bookingID, _ := vals[0].(string)
+			zoneID, _ := vals[1].(string)
+			quantity, ok := toInt64(vals[2])
+			if bookingID == "" || zoneID == "" || !ok {
+				continue
+			}
+			held = append(held, HeldSeats{
+				BookingID: bookingID,
+				ZoneID:    zoneID,
+				Quantity:  quantity,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to scan reservations: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("count", len(held)))
+	span.SetStatus(codes.Ok, "")
+	return held, nil
+}
+
+// GetZoneAvailabilities returns the available seats of the given zones;
+// zones without an availability key are omitted
+func (r *RedisReservationRepository) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.get_zone_availabilities")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("zones", len(zoneIDs)))
+
+	result := make(map[string]int64, len(zoneIDs))
+	if len(zoneIDs) == 0 {
+		return result, nil
+	}
+
+	// One counter per zone, or the pool plus every shard when sharded
+	counters := 1
+	if r.keys.sharded() {
+		counters += r.keys.shards
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([][]*redis.StringCmd, len(zoneIDs))
+	for i, zoneID := range zoneIDs {
+		cmds[i] = make([]*redis.StringCmd, 0, counters)
+		cmds[i] = append(cmds[i], pipe.Get(ctx, r.keys.zoneAvailability(zoneID)))
+		for shard := 0; shard < counters-1; shard++ {
+			cmds[i] = append(cmds[i], pipe.Get(ctx, r.keys.zoneShard(zoneID, shard)))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get zone availabilities: %w", err)
+	}
+
+	for i, zoneCmds := range cmds {
+		var seats int64
+		found := false
+		for _, cmd := range zoneCmds {
+			v, err := cmd.Int64()
+			if err != nil {
+				continue // Counter not initialized in Redis
+			}
+			seats += v
+			found = true
+		}
+		if found {
+			result[zoneIDs[i]] = seats
+		}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
+
+// AdjustZoneAvailability adds delta to a zone's available seats and returns
+// the new value. No counter is taken below zero. On a sharded zone seats are
+// added to the pool, and taken from the pool first, then from the shards, so
+// a correction never leaves the shards selling seats the pool has given up.
+func (r *RedisReservationRepository) AdjustZoneAvailability(ctx context.Context, zoneID string, delta int64) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.adjust_zone_availability")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("zone_id", zoneID),
+		attribute.Int64("delta", delta),
+	)
+
+	counters := []string{r.keys.zoneAvailability(zoneID)}
+	if r.keys.sharded() && delta < 0 {
+		for i := 0; i < r.keys.shards; i++ {
+			counters = append(counters, r.keys.zoneShard(zoneID, i))
+		}
+	}
+
+	// Each counter is adjusted on its own: shards live in other slots on a
+	// cluster. A correction cut short is picked up by the next reconciliation.
+	remaining := delta
+	var seats int64
+	for i, counter := range counters {
+		values, err := r.client.Scripts().Run(ctx, scriptAdjustZone, []string{counter}, remaining).Slice()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return 0, fmt.Errorf("failed to adjust zone availability: %w", err)
+		}
+		if success, _ := toInt64(values[0]); success != 1 {
+			if i > 0 {
+				continue // Shard not seeded yet
+			}
+			err := fmt.Errorf("failed to adjust zone availability: %v", values[1])
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return 0, err
+		}
+		applied, _ := toInt64(values[1])
+		remaining -= applied
+		seats, _ = toInt64(values[2])
+		if remaining == 0 {
+			break
+		}
+	}
+
+	if r.keys.sharded() {
+		total, err := r.GetZoneAvailabilities(ctx, []string{zoneID})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return 0, err
+		}
+		seats = total[zoneID]
+	}
+
+	r.notifyAvailability(ctx, zoneID)
+	span.SetAttributes(
+		attribute.Int64("available_seats", seats),
+		attribute.Int64("delta_not_applied", remaining),
+	)
+	span.SetStatus(codes.Ok, "")
+	return seats, nil
+}
+
+// reconcileLockKey serializes inventory reconciliation across booking service replicas
+const reconcileLockKey = "inventory:reconcile:lock"
+
+// AcquireReconcileLock takes the cluster-wide reconciliation lock for at most
+// ttl. It returns the owner token to release the lock with, or "" when another
+// replica holds the lock.
+func (r *RedisReservationRepository) AcquireReconcileLock(ctx context.Context, ttl time.Duration) (string, error) {
+	token := uuid.New().String()
+	ok, err := r.client.SetNX(ctx, reconcileLockKey, token, ttl).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire reconcile lock: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+	return token, nil
+}
+
+// ReleaseReconcileLock releases the reconciliation lock taken under token.
+// A lock that expired and was taken by another replica is left alone.
+func (r *RedisReservationRepository) ReleaseReconcileLock(ctx context.Context, token string) error {
+	values, err := r.client.Scripts().Run(ctx, scriptLockRelease, []string{reconcileLockKey}, token).Slice()
+	if err != nil {
+		return fmt.Errorf("failed to release reconcile lock: %w", err)
+	}
+	if success, _ := toInt64(values[0]); success != 1 {
+		return fmt.Errorf("reconcile lock expired before it was released")
+	}
+	return nil
+}
+
+const (
+	// deadlineRegistryKey lists every per-slot deadline index on a Redis Cluster
+	deadlineRegistryKey = "expiry:reservations:index"
+
+	// expiredReservationsStream carries reservations whose seats were returned
+	// by the deadline sweep to the consumers that expire their bookings
+	expiredReservationsStream = "stream:reservation:expired"
+
+	// expiredReservationsMaxLen caps the stream; entries are acknowledged
+	// long before this many pile up
+	expiredReservationsMaxLen = 100000
+)
+
+// registerDeadlines lists a per-slot deadline index in deadlineRegistryKey
+// so ExpireDueReservations can find it
+func (r *RedisReservationRepository) registerDeadlines(ctx context.Context, key string) error {
+	if _, ok := r.registered.Load(key); ok {
+		return nil
+	}
+	if err := r.client.SAdd(ctx, deadlineRegistryKey, key).Err(); err != nil {
+		return fmt.Errorf("failed to register deadline index %s: %w", key, err)
+	}
+	r.registered.Store(key, struct{}{})
+	return nil
+}
+
+// deadlineIndexes returns the deadline indexes to sweep
+func (r *RedisReservationRepository) deadlineIndexes(ctx context.Context) ([]string, error) {
+	if !r.keys.hashTags {
+		return []string{r.keys.deadlines("", "")}, nil
+	}
+	keys, err := r.client.SMembers(ctx, deadlineRegistryKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deadline indexes: %w", err)
+	}
+	return keys, nil
+}
+
+// ExpireDueReservations returns the seats of up to limit reservations per
+// deadline index whose deadline is at or before now, and appends each one to
+// the expiry stream. release_seats.lua checks the deadline and deletes the
+// reservation atomically, so concurrent sweeps on several replicas return a
+// reservation's seats exactly once. It returns the number of reservations
+// expired.
+func (r *RedisReservationRepository) ExpireDueReservations(ctx context.Context, now time.Time, limit int) (int, error) {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.expire_due")
+	defer span.End()
+
+	indexes, err := r.deadlineIndexes(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	expired := 0
+	for _, index := range indexes {
+		members, err := r.client.ZRangeByScore(ctx, index, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(now.Unix(), 10),
+			Count: int64(limit),
+		}).Result()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return expired, fmt.Errorf("failed to read deadline index %s: %w", index, err)
+		}
+
+		for _, reservationKey := range members {
+			ok, err := r.expireReservation(ctx, index, reservationKey, now)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return expired, err
+			}
+			if ok {
+				expired++
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("expired", expired))
+	span.SetStatus(codes.Ok, "")
+	return expired, nil
+}
+
+// expireReservation releases one due reservation and publishes it to the
+// expiry stream. Index entries whose reservation is gone or no longer held
+// are dropped.
+//
+// On a single node release_seats.lua appends the stream entry itself. On a
+// Redis Cluster the stream lives in another slot than the reservation, so it
+// is appended here after the script has returned the seats; a crash or error
+// in between loses the entry. Its booking then stays reserved until the
+// ExpiryWorker's database scan expires it (the seats are not returned twice:
+// the reservation is already gone).
+func (r *RedisReservationRepository) expireReservation(ctx context.Context, index, reservationKey string, now time.Time) (bool, error) {
+	data, err := r.client.HGetAll(ctx, reservationKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to get reservation %s: %w", reservationKey, err)
+	}
+	if len(data) == 0 {
+		return false, r.client.ZRem(ctx, index, reservationKey).Err()
+	}
+
+	bookingID := data["booking_id"]
+	result, err := r.releaseReservation(ctx, bookingID, data["user_id"], data, &now)
+	if err != nil {
+		return false, err
+	}
+	if !result.Success {
+		if result.ErrorCode == "ALREADY_RELEASED" {
+			return false, r.client.ZRem(ctx, index, reservationKey).Err()
+		}
+		return false, nil // NOT_DUE: extended after the index was read
+	}
+	if !r.keys.hashTags {
+		return true, nil // Published by the script
+	}
+
+	err = r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: expiredReservationsStream,
+		MaxLen: expiredReservationsMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"booking_id": bookingID,
+			"zone_id":    data["zone_id"],
+			"user_id":    data["user_id"],
+			"event_id":   data["event_id"],
+			"quantity":   data["quantity"],
+			"expired_at": now.Unix(),
+		},
+	}).Err()
+	if err != nil {
+		// The seats are back; the booking row is left to the database scan
+		return true, fmt.Errorf("failed to publish expired reservation %s: %w", bookingID, err)
+	}
+	return true, nil
+}
+
+// EnsureExpiryGroup creates the consumer group of the expiry stream
+func (r *RedisReservationRepository) EnsureExpiryGroup(ctx context.Context, group string) error {
+	err := r.client.XGroupCreateMkStream(ctx, expiredReservationsStream, group, "0").Err()
+	if err != nil && !pkgredis.IsBusyGroupError(err) {
+		return fmt.Errorf("failed to create expiry consumer group: %w", err)
+	}
+	return nil
+}
+
+// ReadExpiredReservations reads up to count new expired reservations for a
+// consumer of group, blocking for at most block when there are none
+func (r *RedisReservationRepository) ReadExpiredReservations(ctx context.Context, group, consumer string, count int, block time.Duration) ([]ExpiredReservation, error) {
+	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{expiredReservationsStream, ">"},
+		Count:    int64(count),
+		Block:    block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expiry stream: %w", err)
+	}
+
+	var expired []ExpiredReservation
+	for _, stream := range streams {
+		expired = append(expired, toExpiredReservations(stream.Messages)...)
+	}
+	return expired, nil
+}
+
+// ClaimExpiredReservations takes over up to count expired reservations that
+// another consumer read but has not acknowledged for at least minIdle
+func (r *RedisReservationRepository) ClaimExpiredReservations(ctx context.Context, group, consumer string, minIdle time.Duration, count int) ([]ExpiredReservation, error) {
+	messages, _, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   expiredReservationsStream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    "0",
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim expiry stream entries: %w", err)
+	}
+	return toExpiredReservations(messages), nil
+}
+
+// AckExpiredReservations acknowledges processed expiry stream entries
+func (r *RedisReservationRepository) AckExpiredReservations(ctx context.Context, group string, streamIDs ...string) error {
+	if err := r.client.XAck(ctx, expiredReservationsStream, group, streamIDs...).Err(); err != nil {
+		return fmt.Errorf("failed to ack expiry stream entries: %w", err)
+	}
+	return nil
+}
+
+// toExpiredReservations converts expiry stream entries
+func toExpiredReservations(messages []redis.XMessage) []ExpiredReservation {
+	expired := make([]ExpiredReservation, 0, len(messages))
+	for _, msg := range messages {
+		str := func(field string) string {
+			v, _ := msg.Values[field].(string)
+			return v
+		}
+		quantity, _ := strconv.ParseInt(str("quantity"), 10, 64)
+		expiredAt, _ := strconv.ParseInt(str("expired_at"), 10, 64)
+		expired = append(expired, ExpiredReservation{
+			StreamID:  msg.ID,
+			BookingID: str("booking_id"),
+			ZoneID:    str("zone_id"),
+			UserID:    str("user_id"),
+			EventID:   str("event_id"),
+			Quantity:  quantity,
+			ExpiredAt: time.Unix(expiredAt, 0),
+		})
+	}
+	return expired
+}
+
+// Ping checks that Redis answers
+func (r *RedisReservationRepository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx)
+}
+
+// RestoreReservation recreates a reservation made while Redis was unavailable:
+// the hash, its deadline and the user's reserved count. The seat counter is
+// left alone; the caller sets it once every reservation of the zone is back.
+func (r *RedisReservationRepository) RestoreReservation(ctx context.Context, res *FallbackReservation) error {
+	ctx, span := telemetry.StartSpan(ctx, "repo.redis.reservation.restore")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("booking_id", res.BookingID),
+		attribute.String("zone_id", res.ZoneID),
+	)
+
+	reservationKey := r.keys.reservation(res.ZoneID, res.BookingID)
+	deadlinesKey := r.keys.deadlines(res.ZoneID, res.BookingID)
+
+	// Both keys share a slot, so this is a single-node transaction on a cluster too
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, reservationKey,
+		"booking_id", res.BookingID,
+		"user_id", res.UserID,
+		"zone_id", res.ZoneID,
+		"event_id", res.EventID,
+		"show_id", "",
+		"quantity", res.Quantity,
+		"unit_price", res.UnitPrice,
+		"status", "reserved",
+		"created_at", res.CreatedAt.Unix(),
+		"expires_at", res.ExpiresAt.Unix(),
+	)
+	pipe.ZAdd(ctx, deadlinesKey, redis.Z{Score: float64(res.ExpiresAt.Unix()), Member: reservationKey})
+	if _, err := pipe.Exec(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to restore reservation %s: %w", res.BookingID, err)
+	}
+	if r.keys.hashTags {
+		if err := r.registerDeadlines(ctx, deadlinesKey); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	ttl := int(time.Until(res.ExpiresAt).Seconds()) + 60
+	if ttl < 60 {
+		ttl = 60
+	}
+	userKey := r.keys.userReservations(res.UserID, res.EventID)
+	if _, _, err := r.adjustUserTally(ctx, userKey, res.Quantity, 0, ttl); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// adjustUserTally applies delta to a user's reserved count through user_tally.lua.
+// It reports false with the current count when adding would exceed maxPerUser.
+func (r *RedisReservationRepository) adjustUserTally(ctx context.Context, key string, delta int64, maxPerUser, ttlSeconds int) (bool, int64, error) {
+	values, err := r.client.Scripts().Run(ctx, scriptUserTally, []string{key}, delta, maxPerUser, ttlSeconds).Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to execute user_tally script: %w", err)
+	}
+	if len(values) < 2 {
+		return false, 0, fmt.Errorf("unexpected user_tally result length: %d", len(values))
+	}
+	ok, _ := toInt64(values[0])
+	count, _ := toInt64(values[1])
+	return ok == 1, count, nil
+}
+
+// releaseUserTally gives seats back to a user's reserved count in cluster mode
+// and returns the new count. In single-node mode the reserve and release
+// scripts maintain the count themselves, so it does nothing.
+func (r *RedisReservationRepository) releaseUserTally(ctx context.Context, key string, quantity int64) int64 {
+	if !r.keys.hashTags || quantity <= 0 {
+		return 0
+	}
+	// A failed give-back is not fatal: the count expires with the
+	// reservation TTL, so it only holds the user's quota until then
+	_, count, _ := r.adjustUserTally(ctx, key, -quantity, 0, 0)
+	return count
+}
+
+// spendQueuePass takes a reservation of quantity seats from a queue pass's
+// budget through queue_pass_spend.lua, or gives it back for a negative quantity
+func (r *RedisReservationRepository) spendQueuePass(ctx context.Context, key string, params ReserveParams, quantity int64) ([]interface{}, error) {
+	values, err := r.client.Scripts().Run(ctx, scriptQueuePassSpend, []string{key},
+		params.QueuePassID, quantity, params.QueuePassBinding).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute queue_pass_spend script: %w", err)
+	}
+	if len(values) < 2 {
+		return nil, fmt.Errorf("unexpected queue_pass_spend result length: %d", len(values))
+	}
+	return values, nil
+}
+
+// refundQueuePass gives a failed reservation back to the queue pass it was
+// taken from in cluster mode. On a single node reserve_seats.lua only spends
+// the pass when it succeeds, so there is nothing to give back.
+func (r *RedisReservationRepository) refundQueuePass(ctx context.Context, key string, params ReserveParams, spent bool) {
+	if !r.keys.hashTags || !spent {
+		return
+	}
+	// A failed give-back is not fatal: the budget expires with the pass
+	_, _ = r.spendQueuePass(ctx, key, params, -int64(params.Quantity))
+}
+
 // Helper function to convert interface{} to int64
 func toInt64(v interface{}) (int64, bool) {
 	switch val := v.(type) {
@@ -411,4 +1330,7 @@ func toInt64(v interface{}) (int64, bool) {
 }
 
 // Ensure RedisReservationRepository implements ReservationRepository
-var _ ReservationRepository = (*RedisReservationRepository)(nil)
+var (
+	_ ReservationRepository = (*RedisReservationRepository)(nil)
+	_ ZoneInventoryStore    = (*RedisReservationRepository)(nil)
+)
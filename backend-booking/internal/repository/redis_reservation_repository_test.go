@@ -7,6 +7,7 @@ import (
 	"time"
 
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
 )
 
 // skipIfNoIntegration skips the test if INTEGRATION_TEST env var is not set
@@ -248,7 +249,7 @@ func TestRedisReservationRepository_ReleaseSeats(t *testing.T) {
 	}
 
 	// Release seats
-	releaseResult, err := repo.ReleaseSeats(ctx, reserveResult.BookingID, "user-release")
+	releaseResult, err := repo.ReleaseSeats(ctx, reserveResult.BookingID, zoneID, "user-release")
 	if err != nil {
 		t.Fatalf("ReleaseSeats() error = %v", err)
 	}
@@ -302,7 +303,7 @@ func TestRedisReservationRepository_ConfirmBooking(t *testing.T) {
 	}
 
 	// Confirm booking
-	confirmResult, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, "user-confirm", "payment-123")
+	confirmResult, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, zoneID, "user-confirm", "payment-123")
 	if err != nil {
 		t.Fatalf("ConfirmBooking() error = %v", err)
 	}
@@ -316,7 +317,7 @@ func TestRedisReservationRepository_ConfirmBooking(t *testing.T) {
 	}
 
 	// Try to confirm again - should fail
-	confirmAgain, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, "user-confirm", "payment-456")
+	confirmAgain, err := repo.ConfirmBooking(ctx, reserveResult.BookingID, zoneID, "user-confirm", "payment-456")
 	if err != nil {
 		t.Fatalf("ConfirmBooking() error = %v", err)
 	}
@@ -445,3 +446,270 @@ func TestRedisReservationRepository_ConcurrentReservations(t *testing.T) {
 		t.Errorf("Final availability = %d, want 0", available)
 	}
 }
+
+func TestRedisReservationRepository_QueuePassBudget(t *testing.T) {
+	for _, cluster := range []bool{false, true} {
+		name := "single node"
+		if cluster {
+			name = "cluster keys"
+		}
+		t.Run(name, func(t *testing.T) {
+			client, _ := redistest.NewClient(t)
+			repo := NewRedisReservationRepository(client)
+			repo.keys.hashTags = cluster // Spends the pass through queue_pass_spend.lua
+			queue := NewRedisQueueRepository(client)
+			ctx := context.Background()
+
+			if err := repo.SetZoneAvailability(ctx, "zone-1", 10); err != nil {
+				t.Fatalf("SetZoneAvailability() error = %v", err)
+			}
+			budget := QueuePassBudget{PassID: "pass-1", Reservations: 2, Seats: 3}
+			if err := queue.StoreQueuePass(ctx, "event-1", "user-1", "token", budget, 300); err != nil {
+				t.Fatalf("StoreQueuePass() error = %v", err)
+			}
+
+			reserve := func(quantity int, passID, binding string) *ReserveResult {
+				t.Helper()
+				result, err := repo.ReserveSeats(ctx, ReserveParams{
+					ZoneID:           "zone-1",
+					UserID:           "user-1",
+					EventID:          "event-1",
+					Quantity:         quantity,
+					MaxPerUser:       10,
+					TTLSeconds:       600,
+					QueuePassID:      passID,
+					QueuePassBinding: binding,
+				})
+				if err != nil {
+					t.Fatalf("ReserveSeats() error = %v", err)
+				}
+				return result
+			}
+
+			if result := reserve(1, "pass-1", "device-1"); !result.Success || !result.QueuePassSpent || result.QueuePassLeft != 1 {
+				t.Fatalf("ReserveSeats() = %+v, want the pass spent with 1 reservation left", result)
+			}
+			if result := reserve(1, "pass-1", "device-2"); result.ErrorCode != "QUEUE_PASS_DEVICE_MISMATCH" {
+				t.Fatalf("ReserveSeats() from another device = %+v, want QUEUE_PASS_DEVICE_MISMATCH", result)
+			}
+			if result := reserve(1, "pass-2", "device-1"); result.ErrorCode != "QUEUE_PASS_NOT_FOUND" {
+				t.Fatalf("ReserveSeats() with another pass = %+v, want QUEUE_PASS_NOT_FOUND", result)
+			}
+			// A reservation that fails gives the pass back
+			if result := reserve(4, "pass-1", "device-1"); result.ErrorCode != "QUEUE_PASS_EXHAUSTED" {
+				t.Fatalf("ReserveSeats() over the seat budget = %+v, want QUEUE_PASS_EXHAUSTED", result)
+			}
+			if result := reserve(2, "pass-1", "device-1"); !result.Success || result.QueuePassLeft != 0 {
+				t.Fatalf("ReserveSeats() = %+v, want the pass used up", result)
+			}
+			if result := reserve(1, "pass-1", "device-1"); result.ErrorCode != "QUEUE_PASS_EXHAUSTED" {
+				t.Fatalf("ReserveSeats() on a used pass = %+v, want QUEUE_PASS_EXHAUSTED", result)
+			}
+
+			if available, err := repo.GetZoneAvailability(ctx, "zone-1"); err != nil || available != 7 {
+				t.Fatalf("GetZoneAvailability() = %d, %v, want 7", available, err)
+			}
+		})
+	}
+}
+
+func TestRedisReservationRepository_RecoverShardTransfers(t *testing.T) {
+	ctx := context.Background()
+	client, mr := redistest.NewClient(t)
+	repo := NewRedisReservationRepository(client).WithZoneShards(4)
+	repo.keys.hashTags = true // Shards in their own slots
+	if err := repo.SetZoneAvailability(ctx, "zone-1", 100); err != nil {
+		t.Fatalf("SetZoneAvailability() error = %v", err)
+	}
+
+	pool := repo.keys.zoneAvailability("zone-1")
+	target := repo.keys.zoneShard("zone-1", 2)
+	moved, err := repo.transferSeats(ctx, pool, target, 5, 4)
+	if err != nil || moved != 25 {
+		t.Fatalf("transferSeats() = %d, %v, want 25 seats", moved, err)
+	}
+	if n, _ := client.HGetAll(ctx, repo.keys.shardTransfers(pool)).Result(); len(n) != 0 {
+		t.Errorf("journal = %v, want a finished transfer cleared", n)
+	}
+
+	// The process dies after taking 10 seats off the pool, before adding them
+	// to the shard: the seats are missing from every counter
+	stale := time.Now().Add(-time.Minute).UnixMilli()
+	if err := client.Scripts().Run(ctx, scriptShardTransferOut, []string{pool, repo.keys.shardTransfers(pool)},
+		10, 100, "t-lost", target, stale).Err(); err != nil {
+		t.Fatalf("shard_transfer_out error = %v", err)
+	}
+	// A transfer still in flight is left to its caller
+	if err := client.Scripts().Run(ctx, scriptShardTransferOut, []string{pool, repo.keys.shardTransfers(pool)},
+		1, 100, "t-live", target, time.Now().UnixMilli()).Err(); err != nil {
+		t.Fatalf("shard_transfer_out error = %v", err)
+	}
+	if got, _ := repo.GetZoneAvailability(ctx, "zone-1"); got != 89 {
+		t.Fatalf("GetZoneAvailability() = %d, want 89 while 11 seats are in transfer", got)
+	}
+
+	for run := 0; run < 2; run++ {
+		recovered, err := repo.RecoverShardTransfers(ctx, []string{"zone-1"})
+		if err != nil {
+			t.Fatalf("RecoverShardTransfers() error = %v", err)
+		}
+		if want := []int64{10, 0}[run]; recovered != want {
+			t.Errorf("run %d: RecoverShardTransfers() = %d, want %d", run, recovered, want)
+		}
+	}
+	if got, _ := repo.GetZoneAvailability(ctx, "zone-1"); got != 99 {
+		t.Errorf("GetZoneAvailability() = %d, want 99", got)
+	}
+	if got, _ := mr.Get(target); got != "35" {
+		t.Errorf("target shard = %q, want 35", got)
+	}
+	if !mr.Exists(repo.keys.shardTransfers(pool)) {
+		t.Error("the in-flight transfer was dropped from the journal")
+	}
+}
+
+func TestRedisReservationRepository_AdjustZoneAvailability_Sharded(t *testing.T) {
+	ctx := context.Background()
+	client, mr := redistest.NewClient(t)
+	repo := NewRedisReservationRepository(client).WithZoneShards(4)
+	repo.keys.hashTags = true // Shards in their own slots
+	if err := repo.SetZoneAvailability(ctx, "zone-1", 100); err != nil {
+		t.Fatalf("SetZoneAvailability() error = %v", err)
+	}
+	pool := repo.keys.zoneAvailability("zone-1")
+	shard := repo.keys.zoneShard("zone-1", 2)
+	if moved, err := repo.transferSeats(ctx, pool, shard, 5, 4); err != nil || moved != 25 {
+		t.Fatalf("transferSeats() = %d, %v, want 25 seats", moved, err)
+	}
+
+	// The pool holds 75 seats, so the shard gives up the other 5
+	seats, err := repo.AdjustZoneAvailability(ctx, "zone-1", -80)
+	if err != nil || seats != 20 {
+		t.Fatalf("AdjustZoneAvailability(-80) = %d, %v, want 20", seats, err)
+	}
+	if got, _ := mr.Get(pool); got != "0" {
+		t.Errorf("pool = %q, want 0", got)
+	}
+	if got, _ := mr.Get(shard); got != "20" {
+		t.Errorf("shard = %q, want 20", got)
+	}
+
+	// Seats are added to the pool
+	if seats, err := repo.AdjustZoneAvailability(ctx, "zone-1", 3); err != nil || seats != 23 {
+		t.Fatalf("AdjustZoneAvailability(3) = %d, %v, want 23", seats, err)
+	}
+	if got, _ := mr.Get(pool); got != "3" {
+		t.Errorf("pool = %q, want 3", got)
+	}
+
+	// A correction larger than the zone empties it without going negative
+	if seats, err := repo.AdjustZoneAvailability(ctx, "zone-1", -50); err != nil || seats != 0 {
+		t.Fatalf("AdjustZoneAvailability(-50) = %d, %v, want 0", seats, err)
+	}
+	for i := 0; i < 4; i++ {
+		key := repo.keys.zoneShard("zone-1", i)
+		if got, _ := mr.Get(key); got != "" && got != "0" {
+			t.Errorf("shard %d = %q, want 0", i, got)
+		}
+	}
+
+	if _, err := repo.AdjustZoneAvailability(ctx, "zone-2", -1); err == nil {
+		t.Error("AdjustZoneAvailability() on an unknown zone error = nil, want ZONE_NOT_FOUND")
+	}
+}
+
+func TestRedisReservationRepository_ExpireDueReservations(t *testing.T) {
+	for _, cluster := range []bool{false, true} {
+		name := "single node"
+		if cluster {
+			name = "cluster keys"
+		}
+		t.Run(name, func(t *testing.T) {
+			client, _ := redistest.NewClient(t)
+			repo := NewRedisReservationRepository(client)
+			repo.keys.hashTags = cluster // Publishes to the stream after the script
+			ctx := context.Background()
+
+			if err := repo.EnsureExpiryGroup(ctx, "expiry"); err != nil {
+				t.Fatalf("EnsureExpiryGroup() error = %v", err)
+			}
+			if err := repo.SetZoneAvailability(ctx, "zone-1", 10); err != nil {
+				t.Fatalf("SetZoneAvailability() error = %v", err)
+			}
+			result, err := repo.ReserveSeats(ctx, ReserveParams{
+				BookingID:  "booking-1",
+				ZoneID:     "zone-1",
+				UserID:     "user-1",
+				EventID:    "event-1",
+				Quantity:   3,
+				MaxPerUser: 10,
+				TTLSeconds: 60,
+			})
+			if err != nil || !result.Success {
+				t.Fatalf("ReserveSeats() = %+v, %v", result, err)
+			}
+
+			if expired, err := repo.ExpireDueReservations(ctx, time.Now(), 10); err != nil || expired != 0 {
+				t.Fatalf("ExpireDueReservations() before the deadline = %d, %v, want 0", expired, err)
+			}
+			due := time.Now().Add(2 * time.Minute)
+			for run := 0; run < 2; run++ {
+				expired, err := repo.ExpireDueReservations(ctx, due, 10)
+				if err != nil {
+					t.Fatalf("ExpireDueReservations() error = %v", err)
+				}
+				if want := []int{1, 0}[run]; expired != want {
+					t.Errorf("run %d: ExpireDueReservations() = %d, want %d", run, expired, want)
+				}
+			}
+			if available, err := repo.GetZoneAvailability(ctx, "zone-1"); err != nil || available != 10 {
+				t.Errorf("GetZoneAvailability() = %d, %v, want 10", available, err)
+			}
+
+			entries, err := repo.ReadExpiredReservations(ctx, "expiry", "c1", 10, 10*time.Millisecond)
+			if err != nil {
+				t.Fatalf("ReadExpiredReservations() error = %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("ReadExpiredReservations() = %+v, want one entry", entries)
+			}
+			if got := entries[0]; got.BookingID != "booking-1" || got.ZoneID != "zone-1" || got.Quantity != 3 || got.ExpiredAt.Unix() != due.Unix() {
+				t.Errorf("entry = %+v, want booking-1 of zone-1 with 3 seats expired at %d", got, due.Unix())
+			}
+		})
+	}
+}
+
+func TestRedisReservationRepository_ReconcileLock(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	repo := NewRedisReservationRepository(client)
+	ctx := context.Background()
+
+	a, err := repo.AcquireReconcileLock(ctx, time.Second)
+	if err != nil || a == "" {
+		t.Fatalf("AcquireReconcileLock() = %q, %v, want a token", a, err)
+	}
+	if b, err := repo.AcquireReconcileLock(ctx, time.Second); err != nil || b != "" {
+		t.Fatalf("AcquireReconcileLock() while held = %q, %v, want none", b, err)
+	}
+
+	// a runs past its TTL and b takes the lock; a's release leaves it alone
+	mr.FastForward(2 * time.Second)
+	b, err := repo.AcquireReconcileLock(ctx, time.Second)
+	if err != nil || b == "" {
+		t.Fatalf("AcquireReconcileLock() after expiry = %q, %v, want a token", b, err)
+	}
+	if err := repo.ReleaseReconcileLock(ctx, a); err == nil {
+		t.Error("ReleaseReconcileLock(a) error = nil, want the lock reported lost")
+	}
+	if got, _ := mr.Get(reconcileLockKey); got != b {
+		t.Fatalf("lock = %q, want still held by b", got)
+	}
+
+	if err := repo.ReleaseReconcileLock(ctx, b); err != nil {
+		t.Fatalf("ReleaseReconcileLock(b) error = %v", err)
+	}
+	if mr.Exists(reconcileLockKey) {
+		t.Error("lock left behind after release")
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// reservationKeys builds the Redis key names used for seat reservations.
+// The zone availability key must match pkgredis.Client.ClusterTag, which the
+// zone syncers use when they seed it.
+//
+// With hash tags (Redis Cluster) a zone's availability counter and all of its
+// reservation hashes carry the zone ID as hash tag, so the reserve and release
+// scripts only ever touch one slot. The per-user count spans every zone of an
+// event and therefore keeps its own slot; it is maintained by user_tally.lua.
+//
+// With shards, a zone's seats live in a pool (the plain availability key) plus
+// shards counters. A booking is pinned to one shard by its ID; on a cluster
+// the shard is the hash tag, so shards of one zone spread across nodes and a
+// booking's reservation hash sits in its shard's slot.
+type reservationKeys struct {
+	hashTags bool
+	shards   int
+}
+
+// sharded reports whether zone inventory is split across shard counters
+func (k reservationKeys) sharded() bool {
+	return k.shards > 1
+}
+
+// zoneAvailability returns the available seat counter of a zone. In sharded
+// mode this is the pool that shards are refilled from.
+func (k reservationKeys) zoneAvailability(zoneID string) string {
+	if k.hashTags {
+		return "zone:availability:" + pkgredis.HashTag(zoneID)
+	}
+	return fmt.Sprintf("zone:availability:%s", zoneID)
+}
+
+// zoneShard returns the counter of one shard of a zone
+func (k reservationKeys) zoneShard(zoneID string, shard int) string {
+	if k.hashTags {
+		return "zone:availability:" + pkgredis.HashTag(fmt.Sprintf("%s:shard:%d", zoneID, shard))
+	}
+	return fmt.Sprintf("zone:availability:%s:shard:%d", zoneID, shard)
+}
+
+// shardTransfers returns the journal of seats taken off a counter by
+// transfers still on their way to another shard. It shares the counter's
+// slot on a cluster.
+func (k reservationKeys) shardTransfers(counter string) string {
+	return "zone:transfers:" + strings.TrimPrefix(counter, "zone:availability:")
+}
+
+// shardTransferMarker returns the key marking a transfer as added to its
+// target counter, in the target's slot on a cluster
+func (k reservationKeys) shardTransferMarker(target, transferID string) string {
+	return "zone:transfer:" + strings.TrimPrefix(target, "zone:availability:") + ":" + transferID
+}
+
+// shardOf returns the shard a booking draws its seats from
+func (k reservationKeys) shardOf(bookingID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(bookingID))
+	return int(h.Sum32() % uint32(k.shards))
+}
+
+// seatCounter returns the counter a booking reserves from and releases to
+func (k reservationKeys) seatCounter(zoneID, bookingID string) string {
+	if k.sharded() {
+		return k.zoneShard(zoneID, k.shardOf(bookingID))
+	}
+	return k.zoneAvailability(zoneID)
+}
+
+// slotTag returns the hash tag shared by a booking's counter, reservation
+// hash and deadline index on a cluster
+func (k reservationKeys) slotTag(zoneID, bookingID string) string {
+	if k.sharded() {
+		return pkgredis.HashTag(fmt.Sprintf("%s:shard:%d", zoneID, k.shardOf(bookingID)))
+	}
+	return pkgredis.HashTag(zoneID)
+}
+
+// reservation returns the reservation hash of a booking in a zone
+func (k reservationKeys) reservation(zoneID, bookingID string) string {
+	if !k.hashTags {
+		return fmt.Sprintf("reservation:%s", bookingID)
+	}
+	return fmt.Sprintf("reservation:%s:%s", k.slotTag(zoneID, bookingID), bookingID)
+}
+
+// deadlines returns the sorted set indexing a booking's reservation by its
+// expiry deadline. A single node has one index; a cluster has one per slot
+// tag, listed in deadlineRegistryKey so the expiry worker can find them.
+func (k reservationKeys) deadlines(zoneID, bookingID string) string {
+	if !k.hashTags {
+		return "expiry:reservations"
+	}
+	return "expiry:reservations:" + k.slotTag(zoneID, bookingID)
+}
+
+// userReservations returns a user's reserved seat count for an event
+func (k reservationKeys) userReservations(userID, eventID string) string {
+	return fmt.Sprintf("user:reservations:%s:%s", userID, eventID)
+}
+
+// identityTag returns the part of an identity key that places every identity
+// key of an event in one slot on a cluster
+func (k reservationKeys) identityTag(eventID string) string {
+	if k.hashTags {
+		return pkgredis.HashTag(eventID)
+	}
+	return eventID
+}
+
+// identityTally returns the seats an identity holds for an event. value is
+// the identity's fingerprint, never the raw phone number or card.
+func (k reservationKeys) identityTally(eventID, kind, value string) string {
+	return fmt.Sprintf("identity:seats:%s:%s:%s", k.identityTag(eventID), kind, value)
+}
+
+// identityClaim returns the hash of seats one booking charged to identities
+func (k reservationKeys) identityClaim(eventID, claimID string) string {
+	return fmt.Sprintf("identity:claim:%s:%s", k.identityTag(eventID), claimID)
+}
+
+// identityLimits returns the hash of an event's per-kind identity limits
+func (k reservationKeys) identityLimits(eventID string) string {
+	return fmt.Sprintf("identity:limits:%s", k.identityTag(eventID))
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+func TestReservationKeys_Legacy(t *testing.T) {
+	k := reservationKeys{}
+
+	if got := k.zoneAvailability("zone-1"); got != "zone:availability:zone-1" {
+		t.Errorf("zoneAvailability() = %q", got)
+	}
+	if got := k.reservation("zone-1", "booking-1"); got != "reservation:booking-1" {
+		t.Errorf("reservation() = %q", got)
+	}
+	if got := k.userReservations("user-1", "event-1"); got != "user:reservations:user-1:event-1" {
+		t.Errorf("userReservations() = %q", got)
+	}
+}
+
+func TestReservationKeys_ClusterShareSlot(t *testing.T) {
+	k := reservationKeys{hashTags: true}
+
+	zoneKey := k.zoneAvailability("zone-1")
+	reservationKey := k.reservation("zone-1", "booking-1")
+	if zoneKey != "zone:availability:{zone-1}" || reservationKey != "reservation:{zone-1}:booking-1" {
+		t.Fatalf("keys = %q, %q", zoneKey, reservationKey)
+	}
+	if pkgredis.KeySlot(zoneKey) != pkgredis.KeySlot(reservationKey) {
+		t.Error("zone availability and reservation keys map to different slots")
+	}
+	if pkgredis.KeySlot(reservationKey) == pkgredis.KeySlot(k.reservation("zone-2", "booking-1")) {
+		t.Error("reservations of different zones share a slot")
+	}
+}
+
+func TestReservationKeys_Sharded(t *testing.T) {
+	k := reservationKeys{shards: 4}
+
+	if got := k.zoneShard("zone-1", 2); got != "zone:availability:zone-1:shard:2" {
+		t.Errorf("zoneShard() = %q", got)
+	}
+	shard := k.shardOf("booking-1")
+	if shard < 0 || shard >= 4 || k.shardOf("booking-1") != shard {
+		t.Fatalf("shardOf() = %d, want a stable shard in [0, 4)", shard)
+	}
+	if got := k.seatCounter("zone-1", "booking-1"); got != k.zoneShard("zone-1", shard) {
+		t.Errorf("seatCounter() = %q, want shard %d", got, shard)
+	}
+	if got := k.reservation("zone-1", "booking-1"); got != "reservation:booking-1" {
+		t.Errorf("reservation() = %q, want legacy key", got)
+	}
+
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		seen[k.shardOf(fmt.Sprintf("booking-%d", i))] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("bookings spread over %d shards, want 4", len(seen))
+	}
+}
+
+func TestReservationKeys_ShardedClusterShareSlot(t *testing.T) {
+	k := reservationKeys{hashTags: true, shards: 4}
+
+	counter := k.seatCounter("zone-1", "booking-1")
+	reservationKey := k.reservation("zone-1", "booking-1")
+	if pkgredis.KeySlot(counter) != pkgredis.KeySlot(reservationKey) {
+		t.Errorf("shard %q and reservation %q map to different slots", counter, reservationKey)
+	}
+
+	slots := make(map[int]bool)
+	for i := 0; i < 4; i++ {
+		slots[pkgredis.KeySlot(k.zoneShard("zone-1", i))] = true
+	}
+	if len(slots) < 2 {
+		t.Error("all shards of a zone map to one slot")
+	}
+}
+
+func TestReservationKeys_Unsharded(t *testing.T) {
+	k := reservationKeys{shards: 1}
+
+	if k.sharded() {
+		t.Error("sharded() = true for a single shard")
+	}
+	if got := k.seatCounter("zone-1", "booking-1"); got != k.zoneAvailability("zone-1") {
+		t.Errorf("seatCounter() = %q, want the zone counter", got)
+	}
+}
+
+func TestReservationKeys_Deadlines(t *testing.T) {
+	if got := (reservationKeys{}).deadlines("zone-1", "booking-1"); got != "expiry:reservations" {
+		t.Errorf("deadlines() = %q, want the single index", got)
+	}
+
+	for _, k := range []reservationKeys{{hashTags: true}, {hashTags: true, shards: 4}} {
+		index := k.deadlines("zone-1", "booking-1")
+		if pkgredis.KeySlot(index) != pkgredis.KeySlot(k.reservation("zone-1", "booking-1")) {
+			t.Errorf("shards=%d: deadline index %q and reservation map to different slots", k.shards, index)
+		}
+		if pkgredis.KeySlot(index) != pkgredis.KeySlot(k.seatCounter("zone-1", "booking-1")) {
+			t.Errorf("shards=%d: deadline index %q and seat counter map to different slots", k.shards, index)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 )
 
 // ReserveResult represents the result of a seat reservation
@@ -12,6 +13,10 @@ type ReserveResult struct {
 	UserReserved     int64
 	ErrorCode        string
 	ErrorMessage     string
+	// QueuePassLeft is the reservations the spent queue pass has left
+	// (valid when QueuePassSpent)
+	QueuePassSpent bool
+	QueuePassLeft  int64
 }
 
 // ConfirmResult represents the result of confirming a booking
@@ -32,26 +37,44 @@ type ReleaseResult struct {
 	ErrorMessage    string
 }
 
+// ExpiredReservation is a reservation whose seats the deadline sweep has
+// returned to inventory, as read back from the expiry stream
+type ExpiredReservation struct {
+	StreamID  string
+	BookingID string
+	ZoneID    string
+	UserID    string
+	EventID   string
+	Quantity  int64
+	ExpiredAt time.Time
+}
+
 // ReservationRepository defines the interface for Redis-based reservation operations
 type ReservationRepository interface {
 	// ReserveSeats atomically reserves seats using Lua script
 	ReserveSeats(ctx context.Context, params ReserveParams) (*ReserveResult, error)
 
-	// ConfirmBooking confirms a reservation and makes it permanent
-	ConfirmBooking(ctx context.Context, bookingID, userID, paymentID string) (*ConfirmResult, error)
+	// ConfirmBooking confirms a reservation and makes it permanent.
+	// zoneID locates the reservation when keys are hash-tagged by zone.
+	ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*ConfirmResult, error)
 
 	// ReleaseSeats releases reserved seats back to inventory
-	ReleaseSeats(ctx context.Context, bookingID, userID string) (*ReleaseResult, error)
+	ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*ReleaseResult, error)
 
 	// GetZoneAvailability gets the current available seats for a zone
 	GetZoneAvailability(ctx context.Context, zoneID string) (int64, error)
 
+	// GetZoneAvailabilities gets the available seats of several zones;
+	// zones not initialized in Redis are omitted
+	GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error)
+
 	// SetZoneAvailability sets the available seats for a zone (for initialization)
 	SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error
 }
 
 // ReserveParams contains parameters for seat reservation
 type ReserveParams struct {
+	BookingID   string // Optional; generated when empty
 	ZoneID      string
 	UserID      string
 	EventID     string
@@ -59,4 +82,8 @@ type ReserveParams struct {
 	MaxPerUser  int
 	TTLSeconds  int
 	Price       float64
+	// QueuePassID spends the budget of the user's queue pass with this ID
+	// ("" = no pass); QueuePassBinding is the device or session spending it
+	QueuePassID      string
+	QueuePassBinding string
 }
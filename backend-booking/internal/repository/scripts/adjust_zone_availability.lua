@@ -0,0 +1,38 @@
+--[[
+    Adjust Zone Availability Lua Script
+    ===================================
+    Version: 1
+
+    Applies an inventory correction to one availability counter. The counter
+    must exist, so a correction never initializes a zone with a bare delta,
+    and a negative delta takes at most the seats the counter holds. On a
+    sharded zone the caller runs it against the pool and then each shard
+    until the whole delta is applied.
+
+    Key Structure:
+    - KEYS[1]: zone:availability:{zone_id}[:shard:{n}] - Available seats (string)
+
+    Arguments:
+    - ARGV[1]: delta             - Seats to add (negative to take seats away)
+
+    Returns:
+    - Success: {1, applied_delta, available_seats}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - ZONE_NOT_FOUND: The counter does not exist
+--]]
+
+local availability_key = KEYS[1]
+local delta = tonumber(ARGV[1]) or 0
+
+if redis.call("EXISTS", availability_key) == 0 then
+    return {0, "ZONE_NOT_FOUND", "Zone availability not found"}
+end
+
+if delta < 0 then
+    local available = math.max(tonumber(redis.call("GET", availability_key)) or 0, 0)
+    delta = math.max(delta, -available)
+end
+
+return {1, delta, redis.call("INCRBY", availability_key, delta)}
@@ -1,10 +1,13 @@
 --[[
     Confirm Booking Lua Script
     ==========================
+    Version: 1
+
     Atomically confirms a reservation, making it permanent.
 
     Key Structure:
     - KEYS[1]: reservation:{booking_id}              - Reservation record (hash)
+    - KEYS[2]: expiry:reservations                   - Deadline index (sorted set)
 
     Arguments:
     - ARGV[1]: booking_id        - Booking ID (for validation)
@@ -24,6 +27,7 @@
 --]]
 
 local reservation_key = KEYS[1]
+local deadlines_key = KEYS[2]
 
 local booking_id = ARGV[1]
 local user_id = ARGV[2]
@@ -74,8 +78,9 @@ redis.call("HSET", reservation_key,
     "payment_id", payment_id
 )
 
--- 2. Remove TTL - make reservation permanent
+-- 2. Remove TTL and deadline - make reservation permanent
 redis.call("PERSIST", reservation_key)
+redis.call("ZREM", deadlines_key, reservation_key)
 
 -- Return success with confirmation timestamp
 return {1, "CONFIRMED", confirmed_at}
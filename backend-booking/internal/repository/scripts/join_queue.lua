@@ -1,11 +1,24 @@
 --[[
     Join Queue Lua Script
     =====================
-    Atomically adds a user to the virtual queue using Sorted Set.
+    Version: 4
+
+    Atomically adds a user to the virtual queue using Sorted Set. For a
+    lottery queue, users who join before it opens go into an unordered pool
+    instead; lottery_draw.lua gives them random positions at opening. Users
+    of a priority lane queue in the lane's own Sorted Set; so do joins that
+    bot screening parks at the back of the queue.
 
     Key Structure:
     - KEYS[1]: queue:{event_id}              - Sorted Set (score = timestamp, member = user_id)
     - KEYS[2]: queue:user:{event_id}:{user_id} - Hash with user queue info
+    - KEYS[3]: queue:lottery:{event_id}      - Set of users awaiting the draw (optional, lottery queues and lanes)
+    - KEYS[4]: queue:lanes:{event_id}        - Set of lanes users have joined (optional, lanes)
+    - KEYS[5]: queue:lane:{event_id}:{lane}  - Sorted Set of a priority lane (optional, lanes)
+    - KEYS[6]: queue:lane:{event_id}:{lane}  - Sorted Set of a priority lane (optional, lanes)
+    - KEYS[7]: queue:lane:{event_id}:{lane}  - Sorted Set of a priority lane (optional, lanes)
+    - KEYS[8]: queue:lane:{event_id}:{lane}  - Sorted Set of a priority lane (optional, lanes)
+    - KEYS[9]: queue:lane:{event_id}:parked  - Sorted Set of parked joins (optional, lanes)
 
     Arguments:
     - ARGV[1]: user_id           - User ID
@@ -13,9 +26,14 @@
     - ARGV[3]: token             - Unique queue token
     - ARGV[4]: ttl_seconds       - TTL for queue entry (default 1800 = 30 min)
     - ARGV[5]: max_queue_size    - Maximum queue size (0 = unlimited)
+    - ARGV[6]: opens_at          - Unix time the lottery is drawn (optional, with KEYS[3]; 0 with lanes)
+    - ARGV[7]: lane              - Priority lane to join (optional, "" = general)
+    - ARGV[8]: lane_index        - Lane's index after KEYS[4] (optional, 0 = general)
 
     Returns:
     - Success: {1, position, total_in_queue, joined_at_timestamp}
+      (position 0 while the user awaits the lottery draw; within the lane
+      for priority lanes)
     - Error: {0, error_code, error_message}
 
     Error Codes:
@@ -25,12 +43,27 @@
 
 local queue_key = KEYS[1]
 local user_queue_key = KEYS[2]
+local lottery_key = KEYS[3]
+local lanes_key = KEYS[4]
 
 local user_id = ARGV[1]
 local event_id = ARGV[2]
 local token = ARGV[3]
 local ttl_seconds = tonumber(ARGV[4]) or 1800
 local max_queue_size = tonumber(ARGV[5]) or 0
+local opens_at = tonumber(ARGV[6]) or 0
+local lane = ARGV[7] or ""
+local lane_index = tonumber(ARGV[8]) or 0
+
+-- KEYS[5] onwards are the priority lanes; users join one by its index
+local lane_keys = {}
+for i = 5, #KEYS do
+    lane_keys[#lane_keys + 1] = KEYS[i]
+end
+local join_key = queue_key
+if lane_index > 0 then
+    join_key = lane_keys[lane_index]
+end
 
 -- Check if user is already in queue
 local existing_score = redis.call("ZSCORE", queue_key, user_id)
@@ -40,10 +73,30 @@ if existing_score then
     local total = redis.call("ZCARD", queue_key)
     return {0, "ALREADY_IN_QUEUE", "User is already in queue at position " .. (position + 1)}
 end
+for _, key in ipairs(lane_keys) do
+    if redis.call("ZSCORE", key, user_id) then
+        return {0, "ALREADY_IN_QUEUE", "User is already in a priority lane"}
+    end
+end
+if lottery_key and redis.call("SISMEMBER", lottery_key, user_id) == 1 then
+    return {0, "ALREADY_IN_QUEUE", "User is already in the lottery"}
+end
+
+-- Queue size counts users awaiting the lottery draw and every lane
+local function queue_size()
+    local size = redis.call("ZCARD", queue_key)
+    if lottery_key then
+        size = size + redis.call("SCARD", lottery_key)
+    end
+    for _, key in ipairs(lane_keys) do
+        size = size + redis.call("ZCARD", key)
+    end
+    return size
+end
 
 -- Check queue size limit
 if max_queue_size > 0 then
-    local current_size = redis.call("ZCARD", queue_key)
+    local current_size = queue_size()
     if current_size >= max_queue_size then
         return {0, "QUEUE_FULL", "Queue has reached maximum capacity of " .. max_queue_size}
     end
@@ -53,12 +106,37 @@ end
 local timestamp = redis.call("TIME")
 local joined_at = tonumber(timestamp[1]) + (tonumber(timestamp[2]) / 1000000)
 
--- Add user to queue with timestamp as score
-redis.call("ZADD", queue_key, joined_at, user_id)
+-- Before a lottery opens, join the pool; the position is drawn at opening.
+-- Deciding by server time keeps joins and the draw on one clock.
+if lottery_key and tonumber(timestamp[1]) < opens_at then
+    redis.call("SADD", lottery_key, user_id)
+    local total = queue_size()
+
+    -- Keep the entry until the queue TTL after the draw
+    local lottery_ttl = opens_at - tonumber(timestamp[1]) + ttl_seconds
+    redis.call("HSET", user_queue_key,
+        "user_id", user_id,
+        "event_id", event_id,
+        "token", token,
+        "joined_at", joined_at,
+        "expires_at", opens_at + ttl_seconds,
+        "position", 0,
+        "lottery", 1
+    )
+    redis.call("EXPIRE", user_queue_key, lottery_ttl)
+
+    return {1, 0, total, joined_at}
+end
+
+-- Add user to their lane with timestamp as score
+redis.call("ZADD", join_key, joined_at, user_id)
+if lane_index > 0 then
+    redis.call("SADD", lanes_key, lane)
+end
 
 -- Get user's position (0-indexed, so add 1 for human-readable)
-local position = redis.call("ZRANK", queue_key, user_id)
-local total = redis.call("ZCARD", queue_key)
+local position = redis.call("ZRANK", join_key, user_id)
+local total = queue_size()
 
 -- Store user queue info
 local expires_at = timestamp[1] + ttl_seconds
@@ -70,6 +148,9 @@ redis.call("HSET", user_queue_key,
     "expires_at", expires_at,
     "position", position + 1
 )
+if lane_index > 0 then
+    redis.call("HSET", user_queue_key, "lane", lane)
+end
 redis.call("EXPIRE", user_queue_key, ttl_seconds)
 
 -- Return success with position (1-indexed) and total
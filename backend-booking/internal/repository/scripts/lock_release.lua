@@ -0,0 +1,32 @@
+--[[
+    Lock Release Lua Script
+    =======================
+    Version: 1
+
+    Deletes a lock only while it still holds the owner token it was taken
+    with, so a holder that ran past the lock's TTL cannot release the lock
+    another replica has taken since.
+
+    Key Structure:
+    - KEYS[1]: inventory:reconcile:lock - Lock holding its owner token (string)
+
+    Arguments:
+    - ARGV[1]: token             - Owner token the lock was taken with
+
+    Returns:
+    - Success: {1, "RELEASED"}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - LOCK_NOT_HELD: The lock expired or is held under another token
+--]]
+
+local lock_key = KEYS[1]
+local token = ARGV[1]
+
+if redis.call("GET", lock_key) ~= token then
+    return {0, "LOCK_NOT_HELD", "Lock expired or is held by another owner"}
+end
+
+redis.call("DEL", lock_key)
+return {1, "RELEASED"}
@@ -0,0 +1,64 @@
+--[[
+    Lottery Draw Lua Script
+    =======================
+    Version: 1
+
+    Atomically moves everyone in a lottery queue's pre-queue pool into the
+    queue in a random order. Drawn users get scores 1..n, ahead of anyone who
+    joined after opening (scored by join time), so QueueReleaseWorker
+    releases the lottery winners first and FIFO joiners after them.
+
+    Key Structure:
+    - KEYS[1]: queue:{event_id}              - Sorted Set (score = position or timestamp, member = user_id)
+    - KEYS[2]: queue:lottery:{event_id}      - Set of users awaiting the draw
+
+    Arguments:
+    - ARGV[1]: opens_at          - Unix time the lottery opens
+    - ARGV[2]: seed              - Random seed for the shuffle, chosen by the caller
+
+    Returns:
+    - Success: {1, drawn, total_in_queue}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - NOT_OPEN: The lottery does not open until opens_at
+--]]
+
+local queue_key = KEYS[1]
+local lottery_key = KEYS[2]
+
+local opens_at = tonumber(ARGV[1]) or 0
+local seed = tonumber(ARGV[2]) or 0
+
+local now = tonumber(redis.call("TIME")[1])
+if now < opens_at then
+    return {0, "NOT_OPEN", "Lottery opens in " .. (opens_at - now) .. " seconds"}
+end
+
+local members = redis.call("SMEMBERS", lottery_key)
+local drawn = #members
+if drawn == 0 then
+    return {1, 0, redis.call("ZCARD", queue_key)}
+end
+
+-- Sort first so the order depends on the seed alone, then Fisher-Yates shuffle
+table.sort(members)
+math.randomseed(seed)
+for i = drawn, 2, -1 do
+    local j = math.random(i)
+    members[i], members[j] = members[j], members[i]
+end
+
+-- ZADD in chunks to stay within Lua's argument limit
+local chunk = 500
+for first = 1, drawn, chunk do
+    local args = {}
+    for i = first, math.min(first + chunk - 1, drawn) do
+        args[#args + 1] = i
+        args[#args + 1] = members[i]
+    end
+    redis.call("ZADD", queue_key, unpack(args))
+end
+redis.call("DEL", lottery_key)
+
+return {1, drawn, redis.call("ZCARD", queue_key)}
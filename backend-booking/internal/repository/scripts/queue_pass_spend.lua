@@ -0,0 +1,66 @@
+--[[
+    Queue Pass Spend Lua Script
+    ===========================
+    Version: 1
+
+    Spends a queue pass's usage budget on a reservation, or gives it back
+    when the reservation fails. Used in Redis Cluster mode, where the budget
+    cannot share a slot with the zone and reservation keys; on a single node
+    reserve_seats.lua spends it itself.
+
+    Key Structure:
+    - KEYS[1]: queue:pass:budget:{event_id}:{user_id} - Queue pass usage budget (hash)
+
+    Arguments:
+    - ARGV[1]: pass_id           - ID of the queue pass spent
+    - ARGV[2]: quantity          - Seats reserved (positive) or given back (negative)
+    - ARGV[3]: binding           - Device or session the pass is bound to (optional)
+
+    Returns:
+    - Success: {1, reservations_left} (0 once the pass's seats are spent)
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - QUEUE_PASS_NOT_FOUND: The pass budget expired or belongs to another pass
+    - QUEUE_PASS_EXHAUSTED: The pass has no reservations or seats left
+    - QUEUE_PASS_DEVICE_MISMATCH: The pass was first spent from another device
+--]]
+
+local pass_budget_key = KEYS[1]
+
+local pass_id = ARGV[1]
+local quantity = tonumber(ARGV[2]) or 0
+local binding = ARGV[3] or ""
+
+local pass = redis.call("HMGET", pass_budget_key, "pass_id", "reservations", "seats", "binding")
+if pass[1] ~= pass_id then
+    return {0, "QUEUE_PASS_NOT_FOUND", "Queue pass budget not found"}
+end
+
+-- Give back a failed reservation; the binding stays
+if quantity < 0 then
+    local left = redis.call("HINCRBY", pass_budget_key, "reservations", 1)
+    if pass[3] then
+        redis.call("HINCRBY", pass_budget_key, "seats", -quantity)
+    end
+    return {1, left}
+end
+
+-- A pass without a seats field has no seat cap
+local seats_left = tonumber(pass[3])
+if (tonumber(pass[2]) or 0) < 1 or (seats_left and seats_left < quantity) then
+    return {0, "QUEUE_PASS_EXHAUSTED", "Queue pass has no reservations or seats left"}
+end
+if pass[4] and pass[4] ~= binding then
+    return {0, "QUEUE_PASS_DEVICE_MISMATCH", "Queue pass is bound to another device"}
+end
+
+local left = redis.call("HINCRBY", pass_budget_key, "reservations", -1)
+if pass[3] and redis.call("HINCRBY", pass_budget_key, "seats", -quantity) <= 0 then
+    left = 0 -- No seats left to spend it on
+end
+if not pass[4] and binding ~= "" then
+    redis.call("HSET", pass_budget_key, "binding", binding)
+end
+
+return {1, left}
@@ -1,12 +1,26 @@
 --[[
     Reserve Seats Lua Script
     ========================
-    Atomically reserves seats for a booking.
+    Version: 2
+
+    Atomically reserves seats for a booking, spending the queue pass the
+    user was admitted with when one is given.
     
     Key Structure:
     - KEYS[1]: zone:availability:{zone_id}      - Available seats count (string/integer)
-    - KEYS[2]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
-    - KEYS[3]: reservation:{booking_id}         - Reservation record (hash)
+    - KEYS[2]: reservation:{booking_id}         - Reservation record (hash)
+    - KEYS[3]: expiry:reservations              - Deadline index (sorted set, score = expires_at)
+    - KEYS[4]: user:reservations:{user_id}:{event_id} - User's total reserved for this event (optional)
+    - KEYS[5]: queue:pass:budget:{event_id}:{user_id} - Queue pass usage budget (hash, optional)
+
+    Redis Cluster mode omits KEYS[4] and KEYS[5]: the other keys are
+    hash-tagged with the zone so they share a slot, while the per-user count
+    and the pass budget live in other slots and are taken by user_tally.lua
+    and queue_pass_spend.lua before this script runs.
+
+    The reservation hash has no TTL. It lives until it is released, confirmed
+    or expired through the deadline index, so Redis never drops it before its
+    seats are returned.
     
     Arguments:
     - ARGV[1]: quantity           - Number of seats to reserve
@@ -18,9 +32,12 @@
     - ARGV[7]: show_id            - Show ID
     - ARGV[8]: unit_price         - Price per seat
     - ARGV[9]: ttl_seconds        - Reservation TTL (default 600 = 10 min)
+    - ARGV[10]: pass_id           - ID of the queue pass spent (optional, with KEYS[5])
+    - ARGV[11]: binding           - Device or session the pass is bound to (optional)
     
     Returns:
-    - Success: {1, remaining_seats, total_user_reserved}
+    - Success: {1, remaining_seats, total_user_reserved, pass_reservations_left}
+      (pass_reservations_left is -1 without KEYS[5], 0 once its seats are spent)
     - Error: {0, error_code, error_message}
     
     Error Codes:
@@ -28,11 +45,16 @@
     - USER_LIMIT_EXCEEDED: User has reached max reservation limit
     - INVALID_QUANTITY: Quantity must be positive
     - ZONE_NOT_FOUND: Zone availability key not found
+    - QUEUE_PASS_NOT_FOUND: The pass budget expired or belongs to another pass
+    - QUEUE_PASS_EXHAUSTED: The pass has no reservations or seats left
+    - QUEUE_PASS_DEVICE_MISMATCH: The pass was first spent from another device
 --]]
 
 local zone_availability_key = KEYS[1]
-local user_reservations_key = KEYS[2]
-local reservation_key = KEYS[3]
+local reservation_key = KEYS[2]
+local deadlines_key = KEYS[3]
+local user_reservations_key = KEYS[4]
+local pass_budget_key = KEYS[5]
 
 local quantity = tonumber(ARGV[1])
 local max_per_user = tonumber(ARGV[2])
@@ -43,12 +65,31 @@ local event_id = ARGV[6]
 local show_id = ARGV[7]
 local unit_price = ARGV[8]
 local ttl_seconds = tonumber(ARGV[9]) or 600
+local pass_id = ARGV[10] or ""
+local binding = ARGV[11] or ""
 
 -- Validate quantity
 if not quantity or quantity <= 0 then
     return {0, "INVALID_QUANTITY", "Quantity must be a positive number"}
 end
 
+-- Check the queue pass budget
+local pass = nil
+if pass_budget_key then
+    pass = redis.call("HMGET", pass_budget_key, "pass_id", "reservations", "seats", "binding")
+    if pass[1] ~= pass_id then
+        return {0, "QUEUE_PASS_NOT_FOUND", "Queue pass budget not found"}
+    end
+    -- A pass without a seats field has no seat cap
+    local seats_left = tonumber(pass[3])
+    if (tonumber(pass[2]) or 0) < 1 or (seats_left and seats_left < quantity) then
+        return {0, "QUEUE_PASS_EXHAUSTED", "Queue pass has no reservations or seats left"}
+    end
+    if pass[4] and pass[4] ~= binding then
+        return {0, "QUEUE_PASS_DEVICE_MISMATCH", "Queue pass is bound to another device"}
+    end
+end
+
 -- Get current available seats
 local available = redis.call("GET", zone_availability_key)
 if not available then
@@ -62,11 +103,13 @@ if available < quantity then
 end
 
 -- Get user's current reservations for this event
-local user_reserved = redis.call("GET", user_reservations_key)
-user_reserved = tonumber(user_reserved) or 0
+local user_reserved = 0
+if user_reservations_key then
+    user_reserved = tonumber(redis.call("GET", user_reservations_key)) or 0
+end
 
 -- Check user limit
-if max_per_user and max_per_user > 0 then
+if user_reservations_key and max_per_user and max_per_user > 0 then
     if (user_reserved + quantity) > max_per_user then
         return {0, "USER_LIMIT_EXCEEDED", "User limit exceeded. Current: " .. user_reserved .. ", Requested: " .. quantity .. ", Max: " .. max_per_user}
     end
@@ -78,10 +121,13 @@ end
 local remaining = redis.call("DECRBY", zone_availability_key, quantity)
 
 -- 2. Increment user's reserved count for this event
-local new_user_reserved = redis.call("INCRBY", user_reservations_key, quantity)
+local new_user_reserved = 0
+if user_reservations_key then
+    new_user_reserved = redis.call("INCRBY", user_reservations_key, quantity)
 
--- 3. Set expiry on user reservation key (same as booking TTL + buffer)
-redis.call("EXPIRE", user_reservations_key, ttl_seconds + 60)
+    -- 3. Set expiry on user reservation key (same as booking TTL + buffer)
+    redis.call("EXPIRE", user_reservations_key, ttl_seconds + 60)
+end
 
 -- 4. Create reservation record
 local timestamp = redis.call("TIME")
@@ -100,8 +146,20 @@ redis.call("HSET", reservation_key,
     "expires_at", timestamp[1] + ttl_seconds
 )
 
--- 5. Set TTL on reservation
-redis.call("EXPIRE", reservation_key, ttl_seconds)
+-- 5. Index the reservation by its deadline for the expiry worker
+redis.call("ZADD", deadlines_key, timestamp[1] + ttl_seconds, reservation_key)
+
+-- 6. Spend the queue pass and bind it to the device that first spends it
+local pass_left = -1
+if pass then
+    pass_left = redis.call("HINCRBY", pass_budget_key, "reservations", -1)
+    if pass[3] and redis.call("HINCRBY", pass_budget_key, "seats", -quantity) <= 0 then
+        pass_left = 0 -- No seats left to spend it on
+    end
+    if not pass[4] and binding ~= "" then
+        redis.call("HSET", pass_budget_key, "binding", binding)
+    end
+end
 
--- Return success with remaining seats and user's total reserved
-return {1, remaining, new_user_reserved}
+-- Return success with remaining seats, user's total reserved and the pass's reservations left
+return {1, remaining, new_user_reserved, pass_left}
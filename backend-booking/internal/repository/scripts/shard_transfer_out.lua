@@ -0,0 +1,60 @@
+--[[
+    Shard Transfer Out Lua Script
+    =============================
+    Version: 1
+
+    First half of a shard transfer on Redis Cluster, where the source and
+    target counters sit in different slots: takes seats off the source and
+    records them in the source slot's transfer journal in the same step, so
+    seats whose second half never ran can be put into the target later.
+
+    Key Structure:
+    - KEYS[1]: source counter  - zone:availability:{zone_id} (pool) or a sibling shard
+    - KEYS[2]: transfer journal - zone:transfers:{source tag} (hash)
+
+    Arguments:
+    - ARGV[1]: needed            - Seats the target is short of
+    - ARGV[2]: divisor           - Take at least 1/divisor of the source so
+                                   the target is not drained again at once
+    - ARGV[3]: transfer_id       - Unique ID of this transfer
+    - ARGV[4]: target            - Target counter key the seats are moving to
+    - ARGV[5]: now_ms            - Current time in milliseconds
+
+    Journal entry:
+    - transfer_id -> "{seats}:{now_ms}:{target}"
+
+    Returns:
+    - Seats taken (0 when the source is empty), or -1 when the source key
+      does not exist
+--]]
+
+local source_key = KEYS[1]
+local journal_key = KEYS[2]
+
+local needed = tonumber(ARGV[1]) or 0
+local divisor = tonumber(ARGV[2]) or 1
+local transfer_id = ARGV[3]
+local target_key = ARGV[4]
+local now_ms = ARGV[5]
+if divisor < 1 then
+    divisor = 1
+end
+
+local available = redis.call("GET", source_key)
+if not available then
+    return -1
+end
+available = tonumber(available) or 0
+if available <= 0 then
+    return 0
+end
+
+local take = math.max(needed, math.floor(available / divisor))
+if take > available then
+    take = available
+end
+
+redis.call("DECRBY", source_key, take)
+redis.call("HSET", journal_key, transfer_id, take .. ":" .. now_ms .. ":" .. target_key)
+
+return take
@@ -0,0 +1,46 @@
+--[[
+    User Tally Lua Script
+    =====================
+    Version: 1
+
+    Adjusts a user's reserved seat count for an event and enforces the
+    per-user limit. Used in Redis Cluster mode, where the count cannot share
+    a slot with the zone and reservation keys of every zone in the event.
+
+    Key Structure:
+    - KEYS[1]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
+
+    Arguments:
+    - ARGV[1]: delta             - Seats to add (positive) or give back (negative)
+    - ARGV[2]: max_per_user      - Limit checked when adding (0 = unlimited)
+    - ARGV[3]: ttl_seconds       - Expiry set when adding; giving back keeps the current expiry
+
+    Returns:
+    - Success: {1, new_user_reserved}
+    - Limit exceeded: {0, current_user_reserved}
+--]]
+
+local user_reservations_key = KEYS[1]
+
+local delta = tonumber(ARGV[1]) or 0
+local max_per_user = tonumber(ARGV[2]) or 0
+local ttl_seconds = tonumber(ARGV[3]) or 660
+
+local current = tonumber(redis.call("GET", user_reservations_key)) or 0
+
+-- Check user limit
+if delta > 0 and max_per_user > 0 and (current + delta) > max_per_user then
+    return {0, current}
+end
+
+local new_user_reserved = current + delta
+if new_user_reserved > 0 and delta > 0 then
+    redis.call("SET", user_reservations_key, new_user_reserved, "EX", ttl_seconds)
+elseif new_user_reserved > 0 then
+    redis.call("SET", user_reservations_key, new_user_reserved, "KEEPTTL")
+else
+    new_user_reserved = 0
+    redis.call("DEL", user_reservations_key)
+end
+
+return {1, new_user_reserved}
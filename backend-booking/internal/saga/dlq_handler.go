@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 )
 
@@ -32,15 +33,21 @@ type DLQMessage struct {
 	Headers        map[string]string      `json:"headers,omitempty"`
 }
 
+// DeadLetterStore is the subset of pkgsaga.PostgresStore the DLQ handler uses
+type DeadLetterStore interface {
+	SaveDeadLetter(ctx context.Context, dl *pkgsaga.DeadLetter) error
+	GetUnprocessedDeadLetters(ctx context.Context, limit int) ([]*pkgsaga.DeadLetter, error)
+}
+
 // DLQHandler handles dead letter queue operations
 type DLQHandler struct {
 	producer SagaProducer
-	store    *pkgsaga.PostgresStore // For DB-based DLQ storage
+	store    DeadLetterStore // For DB-based DLQ storage
 	logger   Logger
 }
 
 // NewDLQHandler creates a new DLQ handler
-func NewDLQHandler(producer SagaProducer, store *pkgsaga.PostgresStore, logger Logger) *DLQHandler {
+func NewDLQHandler(producer SagaProducer, store DeadLetterStore, logger Logger) *DLQHandler {
 	if logger == nil {
 		logger = &NoOpLogger{}
 	}
@@ -72,6 +79,9 @@ func (h *DLQHandler) HandleFailedMessage(ctx context.Context, originalTopic stri
 		sagaID = id
 	}
 
+	// Extract the error code of a failure event (see NewSagaFailureEvent)
+	errorCode, _ := parsedValue["error_code"].(string)
+
 	dlqMsg := &DLQMessage{
 		ID:            fmt.Sprintf("%d", time.Now().UnixNano()),
 		OriginalTopic: originalTopic,
@@ -79,6 +89,7 @@ func (h *DLQHandler) HandleFailedMessage(ctx context.Context, originalTopic stri
 		MessageKey:    messageKey,
 		MessageValue:  parsedValue,
 		ErrorMessage:  err.Error(),
+		ErrorCode:     errorCode,
 		RetryCount:    retryCount,
 		FirstFailedAt: time.Now(),
 		LastFailedAt:  time.Now(),
@@ -92,6 +103,7 @@ func (h *DLQHandler) HandleFailedMessage(ctx context.Context, originalTopic stri
 			MessageKey:   messageKey,
 			MessageValue: parsedValue,
 			ErrorMessage: err.Error(),
+			ErrorCode:    errorCode,
 			RetryCount:   retryCount,
 		}
 		if storeErr := h.store.SaveDeadLetter(ctx, deadLetter); storeErr != nil {
@@ -123,6 +135,16 @@ func (h *DLQHandler) HandleFailedMessage(ctx context.Context, originalTopic stri
 	return nil
 }
 
+// ErrorHandler returns a kafka.ErrorHandler that sends records whose handler
+// failed to the DLQ. The runner marks a record handled only once it is in the
+// DLQ; when sending fails the record is retried instead of being dropped.
+// Handlers are expected to have retried (retryCount times) before failing.
+func (h *DLQHandler) ErrorHandler(retryCount int) kafka.ErrorHandler {
+	return func(ctx context.Context, record *kafka.Record, err error) error {
+		return h.HandleFailedMessage(ctx, record.Topic, string(record.Key), record.Value, err, retryCount)
+	}
+}
+
 // ShouldRetry determines if a message should be retried
 func (h *DLQHandler) ShouldRetry(retryCount int, err error) bool {
 	// Check if we've exceeded max retries
@@ -0,0 +1,84 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+// recordingDeadLetterStore is a DeadLetterStore that keeps what it saves
+type recordingDeadLetterStore struct {
+	saved []*pkgsaga.DeadLetter
+}
+
+func (s *recordingDeadLetterStore) SaveDeadLetter(ctx context.Context, dl *pkgsaga.DeadLetter) error {
+	s.saved = append(s.saved, dl)
+	return nil
+}
+
+func (s *recordingDeadLetterStore) GetUnprocessedDeadLetters(ctx context.Context, limit int) ([]*pkgsaga.DeadLetter, error) {
+	return s.saved, nil
+}
+
+func TestDLQHandler_HandleFailedMessageKeepsErrorCode(t *testing.T) {
+	ctx := context.Background()
+	store := &recordingDeadLetterStore{}
+	producer := NewMockSagaProducer()
+	handler := NewDLQHandler(producer, store, nil)
+
+	now := time.Now()
+	event := NewSagaFailureEvent("saga-1", BookingSagaName, "send-notification", 3, "smtp down", "NOTIFY_FAILED", now, now)
+	value, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if err := handler.HandleFailedMessage(ctx, TopicSagaSendNotificationCommand, "saga-1", value, errors.New("smtp down"), MaxRetryAttempts); err != nil {
+		t.Fatalf("HandleFailedMessage() error = %v", err)
+	}
+
+	if len(store.saved) != 1 {
+		t.Fatalf("saved %d dead letters, want 1", len(store.saved))
+	}
+	if got := store.saved[0]; got.SagaID != "saga-1" || got.ErrorCode != "NOTIFY_FAILED" {
+		t.Errorf("dead letter = saga %q code %q, want saga-1 NOTIFY_FAILED", got.SagaID, got.ErrorCode)
+	}
+
+	if len(producer.PublishedMessages) != 1 {
+		t.Fatalf("published %d DLQ messages, want 1", len(producer.PublishedMessages))
+	}
+	var published DLQMessage
+	if err := json.Unmarshal(producer.PublishedMessages[0].Value, &published); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if published.ErrorCode != "NOTIFY_FAILED" {
+		t.Errorf("published ErrorCode = %q, want NOTIFY_FAILED", published.ErrorCode)
+	}
+}
+
+func TestDLQHandler_ErrorHandlerSavesFailedRecord(t *testing.T) {
+	store := &recordingDeadLetterStore{}
+	handler := NewDLQHandler(nil, store, nil)
+
+	record := &kafka.Record{
+		Topic: TopicSagaReserveSeatsCommand,
+		Key:   []byte("saga-2"),
+		Value: []byte(`{"saga_id":"saga-2"}`),
+	}
+	if err := handler.ErrorHandler(3)(context.Background(), record, errors.New("boom")); err != nil {
+		t.Fatalf("ErrorHandler() error = %v", err)
+	}
+
+	if len(store.saved) != 1 {
+		t.Fatalf("saved %d dead letters, want 1", len(store.saved))
+	}
+	got := store.saved[0]
+	if got.SagaID != "saga-2" || got.Topic != TopicSagaReserveSeatsCommand || got.MessageKey != "saga-2" || got.RetryCount != 3 {
+		t.Errorf("dead letter = %+v, want saga-2 on %s retried 3 times", got, TopicSagaReserveSeatsCommand)
+	}
+}
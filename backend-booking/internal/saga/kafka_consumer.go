@@ -20,7 +20,7 @@ type SagaEventHandler interface {
 
 // SagaConsumer consumes saga events from Kafka and advances the saga
 type SagaConsumer struct {
-	consumer     *kafka.Consumer
+	consumer     kafka.MessageConsumer
 	orchestrator *pkgsaga.Orchestrator
 	store        pkgsaga.Store
 	producer     SagaProducer
@@ -45,6 +45,10 @@ type SagaConsumerConfig struct {
 	Handler          SagaEventHandler
 	SessionTimeout   time.Duration
 	RebalanceTimeout time.Duration
+
+	// Consumer, when set, is used instead of joining GroupID on Brokers
+	// (e.g. a kafkatest consumer in tests); Topics is then ignored
+	Consumer kafka.MessageConsumer
 }
 
 // NewSagaConsumer creates a new saga consumer
@@ -56,16 +60,20 @@ func NewSagaConsumer(ctx context.Context, cfg *SagaConsumerConfig) (*SagaConsume
 		topics = append(topics, "saga.booking.timeout-check")
 	}
 
-	consumer, err := kafka.NewConsumer(ctx, &kafka.ConsumerConfig{
-		Brokers:          cfg.Brokers,
-		GroupID:          cfg.GroupID,
-		Topics:           topics,
-		ClientID:         cfg.ClientID,
-		SessionTimeout:   cfg.SessionTimeout,
-		RebalanceTimeout: cfg.RebalanceTimeout,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+	consumer := cfg.Consumer
+	if consumer == nil {
+		c, err := kafka.NewConsumer(ctx, &kafka.ConsumerConfig{
+			Brokers:          cfg.Brokers,
+			GroupID:          cfg.GroupID,
+			Topics:           topics,
+			ClientID:         cfg.ClientID,
+			SessionTimeout:   cfg.SessionTimeout,
+			RebalanceTimeout: cfg.RebalanceTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+		}
+		consumer = c
 	}
 
 	logger := cfg.Logger
@@ -37,7 +37,7 @@ type SagaProducer interface {
 
 // KafkaSagaProducer implements SagaProducer using Kafka
 type KafkaSagaProducer struct {
-	producer *kafka.Producer
+	producer kafka.MessageProducer
 	logger   Logger
 }
 
@@ -48,18 +48,26 @@ type KafkaSagaProducerConfig struct {
 	MaxRetries    int
 	RetryInterval time.Duration
 	Logger        Logger
+
+	// Producer, when set, is used instead of connecting to Brokers
+	// (e.g. a kafkatest producer in tests)
+	Producer kafka.MessageProducer
 }
 
 // NewKafkaSagaProducer creates a new Kafka saga producer
 func NewKafkaSagaProducer(ctx context.Context, cfg *KafkaSagaProducerConfig) (*KafkaSagaProducer, error) {
-	producer, err := kafka.NewProducer(ctx, &kafka.ProducerConfig{
-		Brokers:       cfg.Brokers,
-		ClientID:      cfg.ClientID,
-		MaxRetries:    cfg.MaxRetries,
-		RetryInterval: cfg.RetryInterval,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+	producer := cfg.Producer
+	if producer == nil {
+		p, err := kafka.NewProducer(ctx, &kafka.ProducerConfig{
+			Brokers:       cfg.Brokers,
+			ClientID:      cfg.ClientID,
+			MaxRetries:    cfg.MaxRetries,
+			RetryInterval: cfg.RetryInterval,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+		}
+		producer = p
 	}
 
 	logger := cfg.Logger
@@ -2,13 +2,20 @@ package saga
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
 	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 )
 
+// timeoutClaimTTL is how long a replica keeps the claim on compensating a
+// timed out step; it is not given back, so a replica whose check fires later
+// finds the step claimed
+const timeoutClaimTTL = time.Minute
+
 // TimeoutHandler manages step timeouts for sagas
 type TimeoutHandler struct {
 	store         pkgsaga.Store
@@ -16,6 +23,8 @@ type TimeoutHandler struct {
 	orchestrator  *pkgsaga.Orchestrator
 	logger        Logger
 	checkInterval time.Duration
+	leases        *lease.Manager
+	holder        string
 	stopCh        chan struct{}
 	wg            sync.WaitGroup
 	mu            sync.RWMutex
@@ -33,6 +42,11 @@ type TimeoutHandlerConfig struct {
 	Orchestrator  *pkgsaga.Orchestrator
 	Logger        Logger
 	CheckInterval time.Duration
+	// Leases, when set, let one replica compensate a timed out step when
+	// several registered a timeout for it (a redelivered step command)
+	Leases *lease.Manager
+	// Holder identifies this replica to Leases (default: hostname and process ID)
+	Holder string
 }
 
 // NewTimeoutHandler creates a new timeout handler
@@ -47,12 +61,19 @@ func NewTimeoutHandler(cfg *TimeoutHandlerConfig) *TimeoutHandler {
 		logger = &NoOpLogger{}
 	}
 
+	holder := cfg.Holder
+	if holder == "" {
+		holder = lease.DefaultHolder()
+	}
+
 	return &TimeoutHandler{
 		store:           cfg.Store,
 		producer:        cfg.Producer,
 		orchestrator:    cfg.Orchestrator,
 		logger:          logger,
 		checkInterval:   checkInterval,
+		leases:          cfg.Leases,
+		holder:          holder,
 		stopCh:          make(chan struct{}),
 		pendingTimeouts: make(map[string]*TimeoutCheck),
 	}
@@ -164,6 +185,10 @@ func (h *TimeoutHandler) handleExpiredTimeout(ctx context.Context, check *Timeou
 		"saga_id", check.SagaID,
 		"step_name", check.StepName)
 
+	if !h.claimTimeout(ctx, check) {
+		return
+	}
+
 	// Get saga instance to check current status
 	instance, err := h.store.Get(ctx, check.SagaID)
 	if err != nil {
@@ -195,6 +220,30 @@ func (h *TimeoutHandler) handleExpiredTimeout(ctx context.Context, check *Timeou
 	h.triggerTimeoutCompensation(ctx, check, instance)
 }
 
+// claimTimeout reports whether this replica should handle a timed out step,
+// which is the case unless another replica claimed it first
+func (h *TimeoutHandler) claimTimeout(ctx context.Context, check *TimeoutCheck) bool {
+	if h.leases == nil {
+		return true
+	}
+
+	name := "saga-timeout:" + h.timeoutKey(check.SagaID, check.StepName)
+	if err := h.leases.Claim(ctx, name, h.holder, timeoutClaimTTL); err != nil {
+		if errors.Is(err, lease.ErrHeld) {
+			h.logger.InfoContext(ctx, "Timeout handled by another replica, skipping",
+				"saga_id", check.SagaID,
+				"step_name", check.StepName)
+		} else {
+			h.logger.ErrorContext(ctx, "Failed to claim timeout",
+				"saga_id", check.SagaID,
+				"step_name", check.StepName,
+				"error", err)
+		}
+		return false
+	}
+	return true
+}
+
 func (h *TimeoutHandler) triggerTimeoutCompensation(ctx context.Context, check *TimeoutCheck, instance *pkgsaga.Instance) {
 	// Update saga status to compensating
 	instance.SetStatus(pkgsaga.StatusCompensating)
@@ -0,0 +1,49 @@
+package saga
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+func TestTimeoutHandler_ClaimsTimeouts(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	leases := lease.NewManager(client)
+	store := pkgsaga.NewMemoryStore()
+
+	instance := pkgsaga.NewInstance(BookingSagaName, nil)
+	instance.SetStatus(pkgsaga.StatusRunning)
+	if err := store.Save(ctx, instance); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A redelivered step command registered the same timeout on two
+	// replicas, and replica-a is compensating it already
+	if err := leases.Claim(ctx, "saga-timeout:"+instance.ID+":reserve-seats", "replica-a", time.Minute); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	producer := NewMockSagaProducer()
+	handler := NewTimeoutHandler(&TimeoutHandlerConfig{Store: store, Producer: producer, Leases: leases, Holder: "replica-b"})
+	handler.RegisterTimeout(NewTimeoutCheck(instance.ID, BookingSagaName, "reserve-seats", 0, time.Now().Add(-time.Second), 1))
+	handler.checkTimeouts(ctx)
+
+	if got := len(producer.FailureEvents); got != 0 {
+		t.Errorf("replica-b sent %d failure events, want the timeout left to replica-a", got)
+	}
+	stored, err := store.Get(ctx, instance.ID)
+	if err != nil || stored.Status != pkgsaga.StatusRunning {
+		t.Errorf("Get() = %v, %v, want the saga left running", stored, err)
+	}
+
+	// The first replica to claim a timeout compensates it
+	handler.RegisterTimeout(NewTimeoutCheck(instance.ID, BookingSagaName, "confirm-booking", 2, time.Now().Add(-time.Second), 1))
+	handler.checkTimeouts(ctx)
+	if got := len(producer.FailureEvents); got != 1 {
+		t.Errorf("replica-b sent %d failure events, want 1", got)
+	}
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrShowNotFound is returned when the ticket service does not know a show
+	ErrShowNotFound = errors.New("show not found")
+	// ErrAvailabilityHubStopped is returned when subscribing before Run or after it returned
+	ErrAvailabilityHubStopped = errors.New("availability hub is not running")
+)
+
+// ShowZoneLister lists the zones of a show from ticket service
+type ShowZoneLister interface {
+	// ListShowZones lists the zones of a show
+	ListShowZones(ctx context.Context, showID string) ([]*ZoneInfo, error)
+}
+
+// ZoneAvailabilityReader reads live zone availability
+type ZoneAvailabilityReader interface {
+	// GetZoneAvailabilities returns the available seats of the given zones;
+	// zones that are not on sale are omitted
+	GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error)
+}
+
+// AvailabilityHub pushes zone availability changes to per-show streams.
+// Reservation scripts notify repository.ZoneAvailabilityChannel on every
+// change; the hub listens only to zones of shows that have subscribers and
+// pushes each show at most once per Interval, however many seats moved.
+type AvailabilityHub interface {
+	// Subscribe opens a stream of a show's availability. The subscription's
+	// Snapshot holds every zone of the show; Updates receives the zones that
+	// changed since. Close must be called when the client goes away.
+	Subscribe(ctx context.Context, showID string) (*AvailabilitySubscription, error)
+	// Run listens for changes and pushes them every Interval until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+// AvailabilityHubConfig holds configuration for AvailabilityHub
+type AvailabilityHubConfig struct {
+	// Interval between pushes to a show's streams (default: 500ms, i.e. 2/s)
+	Interval time.Duration
+}
+
+// AvailabilitySubscription is one client's stream of a show's availability
+type AvailabilitySubscription struct {
+	ShowID   string
+	Snapshot *dto.ShowAvailabilityEvent
+
+	hub       *availabilityHub
+	updates   chan *dto.ShowAvailabilityEvent
+	closeOnce sync.Once
+}
+
+// Updates returns the stream's pending changes. A client that falls behind
+// receives one merged event with the latest count of every changed zone.
+func (s *AvailabilitySubscription) Updates() <-chan *dto.ShowAvailabilityEvent {
+	return s.updates
+}
+
+// Close stops the stream and closes Updates
+func (s *AvailabilitySubscription) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.unsubscribe(s)
+		close(s.updates)
+	})
+}
+
+// push delivers an event without blocking, merging it into the event the
+// client has not read yet. Only the hub sends, under its lock, so the
+// channel always has room after the pending event is taken.
+func (s *AvailabilitySubscription) push(event *dto.ShowAvailabilityEvent) {
+	select {
+	case pending := <-s.updates:
+		event = mergeAvailabilityEvents(pending, event)
+	default:
+	}
+	s.updates <- event
+}
+
+// mergeAvailabilityEvents combines two events of a show; later counts win
+func mergeAvailabilityEvents(older, newer *dto.ShowAvailabilityEvent) *dto.ShowAvailabilityEvent {
+	zones := make(map[string]dto.ZoneAvailability, len(older.Zones)+len(newer.Zones))
+	for _, zone := range older.Zones {
+		zones[zone.ZoneID] = zone
+	}
+	for _, zone := range newer.Zones {
+		zones[zone.ZoneID] = zone
+	}
+
+	merged := &dto.ShowAvailabilityEvent{
+		ShowID:    newer.ShowID,
+		Zones:     make([]dto.ZoneAvailability, 0, len(zones)),
+		Timestamp: newer.Timestamp,
+	}
+	for _, zone := range zones {
+		merged.Zones = append(merged.Zones, zone)
+	}
+	sortZoneAvailability(merged.Zones)
+	return merged
+}
+
+// showStreams tracks the subscribers of one show
+type showStreams struct {
+	zones       []*ZoneInfo
+	subscribers map[*AvailabilitySubscription]struct{}
+}
+
+// zoneIDs returns the IDs of the show's zones
+func (s *showStreams) zoneIDs() []string {
+	ids := make([]string, len(s.zones))
+	for i, zone := range s.zones {
+		ids[i] = zone.ID
+	}
+	return ids
+}
+
+// availabilityHub implements AvailabilityHub
+type availabilityHub struct {
+	client *pkgredis.Client
+	zones  ShowZoneLister
+	reader ZoneAvailabilityReader
+	cfg    *AvailabilityHubConfig
+
+	mu       sync.Mutex
+	pubsub   *redis.PubSub           // nil unless Run is running
+	shows    map[string]*showStreams // Shows with at least one subscriber
+	zoneShow map[string]string       // Zone ID -> show ID of watched zones
+	dirty    map[string]struct{}     // Zones changed since the last push
+}
+
+// NewAvailabilityHub creates a new availability hub
+func NewAvailabilityHub(
+	client *pkgredis.Client,
+	zones ShowZoneLister,
+	reader ZoneAvailabilityReader,
+	cfg *AvailabilityHubConfig,
+) AvailabilityHub {
+	if cfg == nil {
+		cfg = &AvailabilityHubConfig{}
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 500 * time.Millisecond
+	}
+
+	return &availabilityHub{
+		client:   client,
+		zones:    zones,
+		reader:   reader,
+		cfg:      cfg,
+		shows:    make(map[string]*showStreams),
+		zoneShow: make(map[string]string),
+		dirty:    make(map[string]struct{}),
+	}
+}
+
+// Run listens for changes and pushes them every Interval until ctx is
+// cancelled. One pub/sub connection serves every stream of the replica.
+func (h *availabilityHub) Run(ctx context.Context) {
+	pubsub := h.client.Subscribe(ctx)
+	h.mu.Lock()
+	h.pubsub = pubsub
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.pubsub = nil
+		h.mu.Unlock()
+		pubsub.Close()
+	}()
+
+	messages := pubsub.Channel()
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			h.markDirty(msg.Payload)
+		case <-ticker.C:
+			h.flush(ctx)
+		}
+	}
+}
+
+// Subscribe opens a stream of a show's availability
+func (h *availabilityHub) Subscribe(ctx context.Context, showID string) (*AvailabilitySubscription, error) {
+	h.mu.Lock()
+	show, tracked := h.shows[showID]
+	h.mu.Unlock()
+
+	// The first subscriber of a show loads its zones; later ones reuse them
+	var zones []*ZoneInfo
+	if tracked {
+		zones = show.zones
+	} else {
+		var err error
+		zones, err = h.zones.ListShowZones(ctx, showID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &AvailabilitySubscription{
+		ShowID:  showID,
+		hub:     h,
+		updates: make(chan *dto.ShowAvailabilityEvent, 1),
+	}
+	show, err := h.addSubscriber(ctx, sub, zones)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the snapshot after listening, so no change falls in between
+	seats, err := h.reader.GetZoneAvailabilities(ctx, show.zoneIDs())
+	if err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to read zone availability: %w", err)
+	}
+
+	sub.Snapshot = &dto.ShowAvailabilityEvent{
+		ShowID:    showID,
+		Zones:     make([]dto.ZoneAvailability, 0, len(show.zones)),
+		Timestamp: time.Now(),
+	}
+	for _, zone := range show.zones {
+		// Zones not on sale yet report the ticket service's count
+		available, ok := seats[zone.ID]
+		if !ok {
+			available = zone.AvailableSeats
+		}
+		sub.Snapshot.Zones = append(sub.Snapshot.Zones, newZoneAvailability(zone.ID, available))
+	}
+	sortZoneAvailability(sub.Snapshot.Zones)
+	return sub, nil
+}
+
+// addSubscriber registers a subscriber and starts listening to the show's
+// zones if it is the show's first
+func (h *availabilityHub) addSubscriber(ctx context.Context, sub *AvailabilitySubscription, zones []*ZoneInfo) (*showStreams, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.pubsub == nil {
+		return nil, ErrAvailabilityHubStopped
+	}
+
+	show, ok := h.shows[sub.ShowID]
+	if !ok {
+		show = &showStreams{
+			zones:       zones,
+			subscribers: make(map[*AvailabilitySubscription]struct{}),
+		}
+		channels := make([]string, len(zones))
+		for i, zone := range zones {
+			channels[i] = repository.ZoneAvailabilityChannel(zone.ID)
+		}
+		if len(channels) > 0 {
+			if err := h.pubsub.Subscribe(ctx, channels...); err != nil {
+				return nil, fmt.Errorf("failed to subscribe to zone availability: %w", err)
+			}
+		}
+		for _, zone := range zones {
+			h.zoneShow[zone.ID] = sub.ShowID
+		}
+		h.shows[sub.ShowID] = show
+	}
+	show.subscribers[sub] = struct{}{}
+	return show, nil
+}
+
+// unsubscribe removes a subscriber and stops listening to the show's zones
+// once it was the last
+func (h *availabilityHub) unsubscribe(sub *AvailabilitySubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	show, ok := h.shows[sub.ShowID]
+	if !ok {
+		return
+	}
+	delete(show.subscribers, sub)
+	if len(show.subscribers) > 0 {
+		return
+	}
+
+	delete(h.shows, sub.ShowID)
+	channels := make([]string, len(show.zones))
+	for i, zone := range show.zones {
+		channels[i] = repository.ZoneAvailabilityChannel(zone.ID)
+		delete(h.zoneShow, zone.ID)
+		delete(h.dirty, zone.ID)
+	}
+	if h.pubsub != nil && len(channels) > 0 {
+		if err := h.pubsub.Unsubscribe(context.Background(), channels...); err != nil {
+			logger.Get().Warn(fmt.Sprintf("Availability hub: failed to unsubscribe show %s: %v", sub.ShowID, err))
+		}
+	}
+}
+
+// markDirty records a zone change to be pushed at the next flush
+func (h *availabilityHub) markDirty(zoneID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.zoneShow[zoneID]; ok {
+		h.dirty[zoneID] = struct{}{}
+	}
+}
+
+// flush reads the zones changed since the last flush and pushes one event
+// per show to its subscribers
+func (h *availabilityHub) flush(ctx context.Context) {
+	h.mu.Lock()
+	if len(h.dirty) == 0 {
+		h.mu.Unlock()
+		return
+	}
+	zoneIDs := make([]string, 0, len(h.dirty))
+	for zoneID := range h.dirty {
+		zoneIDs = append(zoneIDs, zoneID)
+	}
+	h.dirty = make(map[string]struct{})
+	h.mu.Unlock()
+
+	seats, err := h.reader.GetZoneAvailabilities(ctx, zoneIDs)
+	if err != nil {
+		// Retry the same zones at the next flush
+		logger.Get().Warn(fmt.Sprintf("Availability hub: failed to read %d zones: %v", len(zoneIDs), err))
+		for _, zoneID := range zoneIDs {
+			h.markDirty(zoneID)
+		}
+		return
+	}
+
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	changed := make(map[string][]dto.ZoneAvailability)
+	for _, zoneID := range zoneIDs {
+		showID, watched := h.zoneShow[zoneID]
+		available, ok := seats[zoneID]
+		if !watched || !ok {
+			continue
+		}
+		changed[showID] = append(changed[showID], newZoneAvailability(zoneID, available))
+	}
+
+	for showID, zones := range changed {
+		sortZoneAvailability(zones)
+		event := &dto.ShowAvailabilityEvent{
+			ShowID:    showID,
+			Zones:     zones,
+			Timestamp: now,
+		}
+		for sub := range h.shows[showID].subscribers {
+			sub.push(event)
+		}
+	}
+}
+
+// newZoneAvailability builds the pushed state of a zone
+func newZoneAvailability(zoneID string, available int64) dto.ZoneAvailability {
+	if available < 0 {
+		available = 0
+	}
+	return dto.ZoneAvailability{
+		ZoneID:         zoneID,
+		AvailableSeats: available,
+		SoldOut:        available == 0,
+	}
+}
+
+// sortZoneAvailability orders zones by ID so events are stable
+func sortZoneAvailability(zones []dto.ZoneAvailability) {
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].ZoneID < zones[j].ZoneID
+	})
+}
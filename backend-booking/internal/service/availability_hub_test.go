@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+// staticShowZoneLister lists fixed zones per show and counts the calls
+type staticShowZoneLister struct {
+	zones map[string][]*ZoneInfo
+	calls int
+}
+
+func (l *staticShowZoneLister) ListShowZones(ctx context.Context, showID string) ([]*ZoneInfo, error) {
+	l.calls++
+	zones, ok := l.zones[showID]
+	if !ok {
+		return nil, ErrShowNotFound
+	}
+	return zones, nil
+}
+
+// newTestAvailabilityHub returns a hub over miniredis with show-1 holding
+// zone-1 (on sale, 10 seats in Redis) and zone-2 (not on sale yet)
+func newTestAvailabilityHub(t *testing.T) (*availabilityHub, *repository.RedisReservationRepository, *staticShowZoneLister, *miniredis.Miniredis) {
+	t.Helper()
+
+	client, mr := redistest.NewClient(t)
+	repo := repository.NewRedisReservationRepository(client).WithAvailabilityEvents(true)
+	if err := repo.SetZoneAvailability(context.Background(), "zone-1", 10); err != nil {
+		t.Fatalf("SetZoneAvailability() error = %v", err)
+	}
+
+	lister := &staticShowZoneLister{zones: map[string][]*ZoneInfo{
+		"show-1": {
+			{ID: "zone-2", ShowID: "show-1", AvailableSeats: 50},
+			{ID: "zone-1", ShowID: "show-1", AvailableSeats: 10},
+		},
+	}}
+	hub := NewAvailabilityHub(client, lister, repo, &AvailabilityHubConfig{Interval: 50 * time.Millisecond}).(*availabilityHub)
+	return hub, repo, lister, mr
+}
+
+// runAvailabilityHub runs the hub until the test ends and waits until it
+// accepts subscribers
+func runAvailabilityHub(t *testing.T, hub *availabilityHub) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		hub.mu.Lock()
+		running := hub.pubsub != nil
+		hub.mu.Unlock()
+		if running {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("availability hub did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAvailabilityHub_StreamsReservations(t *testing.T) {
+	hub, repo, lister, mr := newTestAvailabilityHub(t)
+	runAvailabilityHub(t, hub)
+	ctx := context.Background()
+
+	sub, err := hub.Subscribe(ctx, "show-1")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer sub.Close()
+
+	want := []dto.ZoneAvailability{
+		{ZoneID: "zone-1", AvailableSeats: 10},
+		{ZoneID: "zone-2", AvailableSeats: 50},
+	}
+	if len(sub.Snapshot.Zones) != len(want) {
+		t.Fatalf("snapshot = %+v, want %+v", sub.Snapshot.Zones, want)
+	}
+	for i, zone := range sub.Snapshot.Zones {
+		if zone != want[i] {
+			t.Errorf("snapshot zone %d = %+v, want %+v", i, zone, want[i])
+		}
+	}
+
+	// A second viewer of the show reuses the zone list
+	second, err := hub.Subscribe(ctx, "show-1")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer second.Close()
+	if lister.calls != 1 {
+		t.Errorf("listed show zones %d times, want 1", lister.calls)
+	}
+
+	// Wait until the subscription reached the server, then sell the zone out
+	channel := repository.ZoneAvailabilityChannel("zone-1")
+	for deadline := time.Now().Add(2 * time.Second); mr.PubSubNumSub(channel)[channel] == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("hub did not subscribe to zone-1")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		result, err := repo.ReserveSeats(ctx, repository.ReserveParams{
+			ZoneID:     "zone-1",
+			UserID:     "user-1",
+			EventID:    "event-1",
+			Quantity:   2,
+			MaxPerUser: 10,
+			Price:      100,
+			TTLSeconds: 600,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("ReserveSeats() = %+v, %v", result, err)
+		}
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.Updates():
+			if len(event.Zones) != 1 || event.Zones[0].ZoneID != "zone-1" {
+				t.Fatalf("update = %+v, want zone-1 only", event.Zones)
+			}
+			if event.Zones[0].AvailableSeats == 0 {
+				if !event.Zones[0].SoldOut {
+					t.Errorf("update = %+v, want sold out", event.Zones[0])
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no update with zone-1 sold out")
+		}
+	}
+}
+
+func TestAvailabilityHub_CoalescesChanges(t *testing.T) {
+	hub, repo, _, _ := newTestAvailabilityHub(t)
+	ctx := context.Background()
+
+	if _, err := hub.Subscribe(ctx, "show-1"); !errors.Is(err, ErrAvailabilityHubStopped) {
+		t.Fatalf("Subscribe() before Run error = %v, want %v", err, ErrAvailabilityHubStopped)
+	}
+	// Flush by hand instead of running the hub, so no ticker interleaves
+	pubsub := hub.client.Subscribe(ctx)
+	defer pubsub.Close()
+	hub.pubsub = pubsub
+
+	if _, err := hub.Subscribe(ctx, "show-2"); !errors.Is(err, ErrShowNotFound) {
+		t.Fatalf("Subscribe() unknown show error = %v, want %v", err, ErrShowNotFound)
+	}
+
+	sub, err := hub.Subscribe(ctx, "show-1")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// The client reads nothing while the zone changes across three flushes;
+	// it then finds one event with the latest count
+	for _, delta := range []int64{-1, -2, -3} {
+		if _, err := repo.AdjustZoneAvailability(ctx, "zone-1", delta); err != nil {
+			t.Fatalf("AdjustZoneAvailability() error = %v", err)
+		}
+		hub.markDirty("zone-1")
+		hub.markDirty("zone-1")
+		hub.flush(ctx)
+	}
+
+	select {
+	case event := <-sub.Updates():
+		if len(event.Zones) != 1 || event.Zones[0].AvailableSeats != 4 {
+			t.Errorf("update = %+v, want zone-1 with 4 seats", event.Zones)
+		}
+	default:
+		t.Fatal("no pending update")
+	}
+	select {
+	case event := <-sub.Updates():
+		t.Errorf("extra update = %+v, want the changes merged into one", event.Zones)
+	default:
+	}
+
+	// Zones of shows nobody watches are ignored
+	hub.markDirty("zone-9")
+	hub.mu.Lock()
+	dirty := len(hub.dirty)
+	hub.mu.Unlock()
+	if dirty != 0 {
+		t.Errorf("dirty zones = %d, want 0", dirty)
+	}
+
+	// The last subscriber stops the show's stream
+	sub.Close()
+	hub.mu.Lock()
+	shows, zones := len(hub.shows), len(hub.zoneShow)
+	hub.mu.Unlock()
+	if shows != 0 || zones != 0 {
+		t.Errorf("after Close: %d shows, %d zones watched, want none", shows, zones)
+	}
+	if _, ok := <-sub.Updates(); ok {
+		t.Error("Updates() still open after Close")
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"time"
 
@@ -56,6 +57,8 @@ type bookingService struct {
 	reservationTTL  time.Duration
 	maxPerUser      int
 	defaultCurrency string
+	identityLimiter IdentityLimiter
+	admissionHealth repository.AdmissionHealthRepository
 }
 
 // BookingServiceConfig contains configuration for booking service
@@ -63,6 +66,11 @@ type BookingServiceConfig struct {
 	ReservationTTL  time.Duration
 	MaxPerUser      int
 	DefaultCurrency string
+	// IdentityLimiter enforces per-identity purchase limits (nil = disabled)
+	IdentityLimiter IdentityLimiter
+	// AdmissionHealth receives reservation outcomes and payments for the
+	// queue's adaptive release rate (nil = not reported)
+	AdmissionHealth repository.AdmissionHealthRepository
 }
 
 // NewBookingService creates a new booking service
@@ -76,6 +84,8 @@ func NewBookingService(
 	ttl := 10 * time.Minute
 	maxPerUser := 10
 	currency := "THB"
+	var identityLimiter IdentityLimiter
+	var admissionHealth repository.AdmissionHealthRepository
 	if cfg != nil {
 		if cfg.ReservationTTL > 0 {
 			ttl = cfg.ReservationTTL
@@ -86,6 +96,8 @@ func NewBookingService(
 		if cfg.DefaultCurrency != "" {
 			currency = cfg.DefaultCurrency
 		}
+		identityLimiter = cfg.IdentityLimiter
+		admissionHealth = cfg.AdmissionHealth
 	}
 	// Use NoOpEventPublisher if none provided
 	if eventPublisher == nil {
@@ -99,6 +111,8 @@ func NewBookingService(
 		reservationTTL:  ttl,
 		maxPerUser:      maxPerUser,
 		defaultCurrency: currency,
+		identityLimiter: identityLimiter,
+		admissionHealth: admissionHealth,
 	}
 }
 
@@ -176,8 +190,29 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 	}
 	totalPrice := unitPrice * float64(req.Quantity)
 
+	// Charge the seats to the buyer's phone, device and network first; the
+	// claim is given back unless the reservation succeeds
+	bookingID := uuid.New().String()
+	reserved := false
+	if s.identityLimiter != nil {
+		claimed, err := s.identityLimiter.ClaimReservation(ctx, bookingID, userID, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if claimed {
+			defer func() {
+				if !reserved {
+					s.identityLimiter.Release(ctx, req.EventID, bookingID)
+				}
+			}()
+		}
+	}
+
 	// Reserve seats in Redis atomically
 	params := repository.ReserveParams{
+		BookingID:  bookingID,
 		ZoneID:     req.ZoneID,
 		UserID:     userID,
 		EventID:    req.EventID,
@@ -186,9 +221,15 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 		TTLSeconds: int(s.reservationTTL.Seconds()),
 		Price:      unitPrice,
 	}
+	if req.QueuePassID != "" {
+		params.QueuePassID = req.QueuePassID
+		params.QueuePassBinding = queuePassBinding(req.Identity)
+	}
 
+	reserveStarted := time.Now()
 	result, err := s.reservationRepo.ReserveSeats(ctx, params)
 	if err != nil {
+		s.recordReserve(ctx, req.EventID, req.ZoneID, false, reserveStarted)
 		return nil, err
 	}
 
@@ -198,6 +239,8 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 			return nil, domain.ErrInsufficientSeats
 		case "USER_LIMIT_EXCEEDED":
 			return nil, domain.ErrMaxTicketsExceeded
+		case "QUEUE_PASS_NOT_FOUND", "QUEUE_PASS_EXHAUSTED", "QUEUE_PASS_DEVICE_MISMATCH":
+			return nil, queuePassError(result.ErrorCode)
 		case "ZONE_NOT_FOUND":
 			// Auto-sync zone from ticket service and retry once
 			if s.zoneSyncer != nil {
@@ -205,6 +248,7 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 					// Retry the reservation after sync
 					retryResult, retryErr := s.reservationRepo.ReserveSeats(ctx, params)
 					if retryErr != nil {
+						s.recordReserve(ctx, req.EventID, req.ZoneID, false, reserveStarted)
 						return nil, retryErr
 					}
 					if retryResult.Success {
@@ -218,6 +262,8 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 						return nil, domain.ErrInsufficientSeats
 					case "USER_LIMIT_EXCEEDED":
 						return nil, domain.ErrMaxTicketsExceeded
+					case "QUEUE_PASS_NOT_FOUND", "QUEUE_PASS_EXHAUSTED", "QUEUE_PASS_DEVICE_MISMATCH":
+						return nil, queuePassError(retryResult.ErrorCode)
 					default:
 						return nil, domain.ErrZoneNotFound
 					}
@@ -232,6 +278,8 @@ func (s *bookingService) ReserveSeats(ctx context.Context, userID string, req *d
 	}
 
 createBooking:
+	// From here on the reservation's expiry or release gives the claim back
+	reserved = true
 
 	// Create booking record in PostgreSQL
 	now := time.Now()
@@ -257,10 +305,12 @@ createBooking:
 	if err := s.bookingRepo.Create(ctx, booking); err != nil {
 		// If PostgreSQL insert fails, we should release Redis reservation
 		// But for now, let Redis TTL handle cleanup
+		s.recordReserve(ctx, req.EventID, req.ZoneID, false, reserveStarted)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	s.recordReserve(ctx, req.EventID, req.ZoneID, true, reserveStarted)
 
 	// Publish booking created event (ProduceAsync is non-blocking, no need for extra goroutine)
 	_ = s.eventPublisher.PublishBookingCreated(ctx, booking)
@@ -280,12 +330,44 @@ createBooking:
 
 	span.SetAttributes(attribute.String("booking_id", booking.ID))
 	span.SetStatus(codes.Ok, "")
-	return &dto.ReserveSeatsResponse{
+	response := &dto.ReserveSeatsResponse{
 		BookingID:  booking.ID,
 		Status:     string(booking.Status),
 		ExpiresAt:  booking.ExpiresAt,
 		TotalPrice: booking.TotalPrice,
-	}, nil
+	}
+	if result.QueuePassSpent {
+		left := result.QueuePassLeft
+		response.QueuePassReservationsLeft = &left
+	}
+	return response, nil
+}
+
+// queuePassError maps a queue pass error code of the reservation scripts
+func queuePassError(code string) error {
+	switch code {
+	case "QUEUE_PASS_EXHAUSTED":
+		return domain.ErrQueuePassExhausted
+	case "QUEUE_PASS_DEVICE_MISMATCH":
+		return domain.ErrQueuePassDeviceMismatch
+	default:
+		return domain.ErrQueuePassExpired
+	}
+}
+
+// queuePassBinding returns what a queue pass is bound to when first spent:
+// the client's device ID, or its user agent when it sends none, so a pass
+// cannot be passed on to another browser. Hashed to keep the budget small.
+func queuePassBinding(identity dto.PurchaseIdentity) string {
+	value := "device:" + identity.DeviceID
+	if identity.DeviceID == "" {
+		if identity.UserAgent == "" {
+			return ""
+		}
+		value = "ua:" + identity.UserAgent
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
 }
 
 // ConfirmBooking confirms a reservation with payment
@@ -341,8 +423,27 @@ func (s *bookingService) ConfirmBooking(ctx context.Context, bookingID, userID s
 		paymentID = req.PaymentID
 	}
 
+	// Re-check the buyer at payment: the card is only known now. The claim
+	// is given back unless Redis confirms the booking.
+	confirmed := false
+	if s.identityLimiter != nil {
+		claimed, err := s.identityLimiter.ClaimPayment(ctx, booking, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if claimed {
+			defer func() {
+				if !confirmed {
+					s.identityLimiter.Release(ctx, booking.EventID, repository.PaymentClaimID(bookingID))
+				}
+			}()
+		}
+	}
+
 	// Confirm in Redis first
-	redisResult, err := s.reservationRepo.ConfirmBooking(ctx, bookingID, userID, paymentID)
+	redisResult, err := s.reservationRepo.ConfirmBooking(ctx, bookingID, booking.ZoneID, userID, paymentID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -368,6 +469,7 @@ func (s *bookingService) ConfirmBooking(ctx context.Context, bookingID, userID s
 			return nil, domain.ErrInvalidBookingStatus
 		}
 	}
+	confirmed = true
 
 	// Update booking in PostgreSQL
 	if err := s.bookingRepo.Confirm(ctx, bookingID, paymentID); err != nil {
@@ -397,6 +499,9 @@ func (s *bookingService) ConfirmBooking(ctx context.Context, bookingID, userID s
 	// Record metrics
 	durationSeconds := now.Sub(booking.ReservedAt).Seconds()
 	metrics.RecordConfirmation(ctx, booking.EventID, userID, durationSeconds)
+	if s.admissionHealth != nil {
+		_ = s.admissionHealth.RecordConfirm(ctx, booking.EventID)
+	}
 
 	// Add span event for booking confirmed
 	span.AddEvent("booking_confirmed", trace.WithAttributes(
@@ -460,7 +565,7 @@ func (s *bookingService) CancelBooking(ctx context.Context, bookingID, userID st
 	}
 
 	// Release seats in Redis
-	releaseResult, err := s.reservationRepo.ReleaseSeats(ctx, bookingID, userID)
+	releaseResult, err := s.reservationRepo.ReleaseSeats(ctx, bookingID, booking.ZoneID, userID)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -739,6 +844,15 @@ func (s *bookingService) ExpireReservations(ctx context.Context, limit int) (int
 	return expiredCount, nil
 }
 
+// recordReserve reports a reservation attempt to the queue's adaptive release
+// rate. Reporting is best effort and never fails the reservation.
+func (s *bookingService) recordReserve(ctx context.Context, eventID, zoneID string, success bool, started time.Time) {
+	if s.admissionHealth == nil {
+		return
+	}
+	_ = s.admissionHealth.RecordReserve(ctx, eventID, zoneID, success, time.Since(started))
+}
+
 // generateConfirmationCode generates a random confirmation code
 func generateConfirmationCode() string {
 	bytes := make([]byte, 4)
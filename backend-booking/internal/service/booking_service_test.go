@@ -122,8 +122,8 @@ func (m *MockBookingRepository) GetTenantIDByShowID(ctx context.Context, showID
 // MockReservationRepository is a mock implementation of ReservationRepository
 type MockReservationRepository struct {
 	ReserveSeatsFunc        func(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error)
-	ConfirmBookingFunc      func(ctx context.Context, bookingID, userID, paymentID string) (*repository.ConfirmResult, error)
-	ReleaseSeatsFunc        func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error)
+	ConfirmBookingFunc      func(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error)
+	ReleaseSeatsFunc        func(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error)
 	GetZoneAvailabilityFunc func(ctx context.Context, zoneID string) (int64, error)
 	SetZoneAvailabilityFunc func(ctx context.Context, zoneID string, seats int64) error
 }
@@ -138,9 +138,9 @@ func (m *MockReservationRepository) ReserveSeats(ctx context.Context, params rep
 	}, nil
 }
 
-func (m *MockReservationRepository) ConfirmBooking(ctx context.Context, bookingID, userID, paymentID string) (*repository.ConfirmResult, error) {
+func (m *MockReservationRepository) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error) {
 	if m.ConfirmBookingFunc != nil {
-		return m.ConfirmBookingFunc(ctx, bookingID, userID, paymentID)
+		return m.ConfirmBookingFunc(ctx, bookingID, zoneID, userID, paymentID)
 	}
 	return &repository.ConfirmResult{
 		Success: true,
@@ -148,9 +148,9 @@ func (m *MockReservationRepository) ConfirmBooking(ctx context.Context, bookingI
 	}, nil
 }
 
-func (m *MockReservationRepository) ReleaseSeats(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+func (m *MockReservationRepository) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error) {
 	if m.ReleaseSeatsFunc != nil {
-		return m.ReleaseSeatsFunc(ctx, bookingID, userID)
+		return m.ReleaseSeatsFunc(ctx, bookingID, zoneID, userID)
 	}
 	return &repository.ReleaseResult{
 		Success: true,
@@ -164,6 +164,18 @@ func (m *MockReservationRepository) GetZoneAvailability(ctx context.Context, zon
 	return 100, nil
 }
 
+func (m *MockReservationRepository) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	result := make(map[string]int64, len(zoneIDs))
+	for _, id := range zoneIDs {
+		seats, err := m.GetZoneAvailability(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		result[id] = seats
+	}
+	return result, nil
+}
+
 func (m *MockReservationRepository) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
 	if m.SetZoneAvailabilityFunc != nil {
 		return m.SetZoneAvailabilityFunc(ctx, zoneID, seats)
@@ -371,7 +383,7 @@ func TestBookingService_ConfirmBooking(t *testing.T) {
 						ExpiresAt: time.Now().Add(10 * time.Minute),
 					}, nil
 				}
-				rr.ConfirmBookingFunc = func(ctx context.Context, bookingID, userID, paymentID string) (*repository.ConfirmResult, error) {
+				rr.ConfirmBookingFunc = func(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error) {
 					return &repository.ConfirmResult{
 						Success: true,
 						Status:  "CONFIRMED",
@@ -511,7 +523,7 @@ func TestBookingService_CancelBooking(t *testing.T) {
 						Status: domain.BookingStatusReserved,
 					}, nil
 				}
-				rr.ReleaseSeatsFunc = func(ctx context.Context, bookingID, userID string) (*repository.ReleaseResult, error) {
+				rr.ReleaseSeatsFunc = func(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error) {
 					return &repository.ReleaseResult{
 						Success: true,
 					}, nil
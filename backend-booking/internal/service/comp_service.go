@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var (
+	// ErrInvalidCompRequest is returned when a comp request fails validation
+	ErrInvalidCompRequest = errors.New("invalid comp request")
+	// ErrCompRecipientNotFound is returned when a recipient email has no active user
+	ErrCompRecipientNotFound = errors.New("comp recipient not found")
+	// ErrCompZoneNotFound is returned when the zone does not exist or belongs to another show
+	ErrCompZoneNotFound = errors.New("zone not found")
+	// ErrCompAllocationShort is returned when the bucket holds fewer seats than requested
+	ErrCompAllocationShort = errors.New("not enough seats in allocation bucket")
+	// ErrCompForbidden is returned when the ticket service rejects the caller
+	ErrCompForbidden = errors.New("not allowed to issue comp tickets")
+)
+
+// AllocationIssue issues seats from a zone's held or comp allocation
+type AllocationIssue struct {
+	ZoneID string `json:"-"`
+	Bucket string `json:"bucket"`
+	Seats  int    `json:"seats"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AllocationIssuer moves issued seats to sold in the ticket service
+type AllocationIssuer interface {
+	// IssueAllocation issues seats on behalf of the caller whose
+	// Authorization header value is authToken
+	IssueAllocation(ctx context.Context, authToken string, issue *AllocationIssue) error
+}
+
+// UserResolver resolves emails to user IDs via the auth service
+type UserResolver interface {
+	// LookupUserIDs maps emails to user IDs; unknown emails are omitted
+	LookupUserIDs(ctx context.Context, emails []string) (map[string]string, error)
+}
+
+// CompService issues complimentary tickets
+type CompService interface {
+	// IssueComps creates confirmed, zero-price bookings for the recipients from
+	// the zone's held or comp allocation and publishes booking.confirmed for each
+	IssueComps(ctx context.Context, req *dto.IssueCompsRequest, authToken string) (*dto.IssueCompsResponse, error)
+}
+
+// CompServiceConfig contains configuration for the comp service
+type CompServiceConfig struct {
+	DefaultCurrency string
+	MaxSeats        int // Max seats issued by one request
+}
+
+// compService implements CompService
+type compService struct {
+	store           repository.CompBookingStore
+	zones           ZoneFetcher
+	issuer          AllocationIssuer
+	users           UserResolver
+	eventPublisher  EventPublisher
+	defaultCurrency string
+	maxSeats        int
+}
+
+// NewCompService creates a new CompService
+func NewCompService(
+	store repository.CompBookingStore,
+	zones ZoneFetcher,
+	issuer AllocationIssuer,
+	users UserResolver,
+	eventPublisher EventPublisher,
+	cfg *CompServiceConfig,
+) CompService {
+	currency := "THB"
+	maxSeats := 500
+	if cfg != nil {
+		if cfg.DefaultCurrency != "" {
+			currency = cfg.DefaultCurrency
+		}
+		if cfg.MaxSeats > 0 {
+			maxSeats = cfg.MaxSeats
+		}
+	}
+	if eventPublisher == nil {
+		eventPublisher = NewNoOpEventPublisher()
+	}
+	return &compService{
+		store:           store,
+		zones:           zones,
+		issuer:          issuer,
+		users:           users,
+		eventPublisher:  eventPublisher,
+		defaultCurrency: currency,
+		maxSeats:        maxSeats,
+	}
+}
+
+// IssueComps creates the comp bookings and issues their seats in one step:
+// the ticket service is called inside the bookings transaction, so seats are
+// only taken from the allocation if the bookings can be written, and the
+// bookings are rolled back if the allocation is short.
+func (s *compService) IssueComps(ctx context.Context, req *dto.IssueCompsRequest, authToken string) (*dto.IssueCompsResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.comp.issue")
+	defer span.End()
+
+	if valid, msg := req.Validate(s.maxSeats); !valid {
+		span.SetStatus(codes.Error, msg)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCompRequest, msg)
+	}
+	seats := req.Seats()
+	span.SetAttributes(
+		attribute.String("zone_id", req.ZoneID),
+		attribute.String("bucket", req.Bucket),
+		attribute.Int("recipients", len(req.Recipients)),
+		attribute.Int("seats", seats),
+	)
+
+	zone, err := s.zones.FetchZone(ctx, req.ZoneID)
+	if err != nil || zone.ShowID != req.ShowID {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.SetStatus(codes.Error, "zone not found")
+		return nil, ErrCompZoneNotFound
+	}
+
+	userIDs, err := s.resolveRecipients(ctx, req.Recipients)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	tenantID := req.TenantID
+	if tenantID == "" {
+		tenantID, err = s.store.GetTenantIDByShowID(ctx, req.ShowID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	bookings := make([]*domain.Booking, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		bookings[i] = &domain.Booking{
+			ID:               uuid.New().String(),
+			TenantID:         tenantID,
+			UserID:           userIDs[i],
+			EventID:          req.EventID,
+			ShowID:           req.ShowID,
+			ZoneID:           req.ZoneID,
+			Quantity:         recipient.Quantity,
+			Currency:         s.defaultCurrency,
+			Status:           domain.BookingStatusConfirmed,
+			StatusReason:     domain.BookingReasonComp,
+			ConfirmationCode: generateConfirmationCode(),
+			ReservedAt:       now,
+			ConfirmedAt:      &now,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+	}
+
+	issue := &AllocationIssue{
+		ZoneID: req.ZoneID,
+		Bucket: req.Bucket,
+		Seats:  seats,
+		Reason: req.Reason,
+	}
+	err = s.store.CreateComps(ctx, bookings, func(ctx context.Context) error {
+		return s.issuer.IssueAllocation(ctx, authToken, issue)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	resp := &dto.IssueCompsResponse{
+		ZoneID: req.ZoneID,
+		Bucket: req.Bucket,
+		Seats:  seats,
+	}
+	for i, booking := range bookings {
+		if err := s.eventPublisher.PublishBookingConfirmed(ctx, booking); err != nil {
+			logger.Get().Error(fmt.Sprintf("Failed to publish booking.confirmed for comp booking %s: %v", booking.ID, err))
+		}
+		resp.Bookings = append(resp.Bookings, &dto.CompBookingResponse{
+			BookingID:        booking.ID,
+			UserID:           booking.UserID,
+			Email:            req.Recipients[i].Email,
+			Quantity:         booking.Quantity,
+			ConfirmationCode: booking.ConfirmationCode,
+		})
+	}
+
+	logger.Get().Info(fmt.Sprintf("Issued comp tickets: zone=%s, bucket=%s, bookings=%d, seats=%d",
+		req.ZoneID, req.Bucket, len(bookings), seats))
+	span.SetStatus(codes.Ok, "")
+	return resp, nil
+}
+
+// resolveRecipients returns the user ID of each recipient, looking emails up
+// in the auth service. Every unknown email is reported in one error.
+func (s *compService) resolveRecipients(ctx context.Context, recipients []dto.CompRecipient) ([]string, error) {
+	var emails []string
+	for _, recipient := range recipients {
+		if recipient.Email != "" {
+			emails = append(emails, recipient.Email)
+		}
+	}
+
+	var found map[string]string
+	if len(emails) > 0 {
+		var err error
+		found, err = s.users.LookupUserIDs(ctx, emails)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve recipients: %w", err)
+		}
+	}
+
+	userIDs := make([]string, len(recipients))
+	missing := make(map[string]bool)
+	for i, recipient := range recipients {
+		if recipient.UserID != "" {
+			userIDs[i] = recipient.UserID
+			continue
+		}
+		userID, ok := found[recipient.Email]
+		if !ok {
+			missing[recipient.Email] = true
+			continue
+		}
+		userIDs[i] = userID
+	}
+
+	if len(missing) > 0 {
+		unknown := make([]string, 0, len(missing))
+		for email := range missing {
+			unknown = append(unknown, email)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: %s", ErrCompRecipientNotFound, strings.Join(unknown, ", "))
+	}
+	return userIDs, nil
+}
+
+// HTTPAllocationIssuer issues allocations via HTTP on the ticket service
+type HTTPAllocationIssuer struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPAllocationIssuer creates a new HTTP allocation issuer
+func NewHTTPAllocationIssuer(ticketServiceURL string) *HTTPAllocationIssuer {
+	return &HTTPAllocationIssuer{
+		baseURL: ticketServiceURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// IssueAllocation calls POST /api/v1/zones/:id/allocations/issue, which
+// requires an organizer or admin token
+func (i *HTTPAllocationIssuer) IssueAllocation(ctx context.Context, authToken string, issue *AllocationIssue) error {
+	url := fmt.Sprintf("%s/api/v1/zones/%s/allocations/issue", i.baseURL, issue.ZoneID)
+
+	body, err := json.Marshal(issue)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to issue allocation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrCompZoneNotFound
+	case http.StatusConflict:
+		return ErrCompAllocationShort
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrCompForbidden
+	case http.StatusBadRequest:
+		var response struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&response)
+		return fmt.Errorf("%w: %s", ErrInvalidCompRequest, response.Error.Message)
+	}
+	return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+}
+
+// HTTPUserResolver resolves users via HTTP on the auth service
+type HTTPUserResolver struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPUserResolver creates a new HTTP user resolver
+func NewHTTPUserResolver(authServiceURL string) *HTTPUserResolver {
+	return &HTTPUserResolver{
+		baseURL: authServiceURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// LookupUserIDs calls POST /internal/users/lookup
+func (r *HTTPUserResolver) LookupUserIDs(ctx context.Context, emails []string) (map[string]string, error) {
+	url := fmt.Sprintf("%s/internal/users/lookup", r.baseURL)
+
+	body, err := json.Marshal(map[string][]string{"emails": emails})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up users: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: invalid recipient email", ErrInvalidCompRequest)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Success bool `json:"success"`
+		Data    []struct {
+			Email  string `json:"email"`
+			UserID string `json:"user_id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("API returned unsuccessful response")
+	}
+
+	userIDs := make(map[string]string, len(response.Data))
+	for _, user := range response.Data {
+		userIDs[user.Email] = user.UserID
+	}
+	return userIDs, nil
+}
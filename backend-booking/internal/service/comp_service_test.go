@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+)
+
+// mockCompStore commits comp bookings only if beforeCommit succeeds
+type mockCompStore struct {
+	tenantID  string
+	committed []*domain.Booking
+}
+
+func (m *mockCompStore) CreateComps(ctx context.Context, bookings []*domain.Booking, beforeCommit func(ctx context.Context) error) error {
+	if err := beforeCommit(ctx); err != nil {
+		return err
+	}
+	m.committed = append(m.committed, bookings...)
+	return nil
+}
+
+func (m *mockCompStore) GetTenantIDByShowID(ctx context.Context, showID string) (string, error) {
+	return m.tenantID, nil
+}
+
+// mockAllocationIssuer takes seats from a single bucket
+type mockAllocationIssuer struct {
+	available int
+	issues    []*AllocationIssue
+	tokens    []string
+}
+
+func (m *mockAllocationIssuer) IssueAllocation(ctx context.Context, authToken string, issue *AllocationIssue) error {
+	if issue.Seats > m.available {
+		return ErrCompAllocationShort
+	}
+	m.available -= issue.Seats
+	m.issues = append(m.issues, issue)
+	m.tokens = append(m.tokens, authToken)
+	return nil
+}
+
+// mockUserResolver resolves emails from a fixed map
+type mockUserResolver struct {
+	users map[string]string
+}
+
+func (m *mockUserResolver) LookupUserIDs(ctx context.Context, emails []string) (map[string]string, error) {
+	found := make(map[string]string)
+	for _, email := range emails {
+		if userID, ok := m.users[email]; ok {
+			found[email] = userID
+		}
+	}
+	return found, nil
+}
+
+// staticZoneFetcher returns the same zone for every ID
+type staticZoneFetcher struct {
+	zone *ZoneInfo
+}
+
+func (f *staticZoneFetcher) FetchZone(ctx context.Context, zoneID string) (*ZoneInfo, error) {
+	return f.zone, nil
+}
+
+func TestCompService_IssueComps(t *testing.T) {
+	newRequest := func() *dto.IssueCompsRequest {
+		return &dto.IssueCompsRequest{
+			EventID: "event-1",
+			ShowID:  "show-1",
+			ZoneID:  "zone-1",
+			Reason:  "sponsor",
+			Recipients: []dto.CompRecipient{
+				{UserID: "user-1", Quantity: 2},
+				{Email: "guest@example.com"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		modify        func(req *dto.IssueCompsRequest)
+		zoneShowID    string
+		available     int
+		wantErr       error
+		wantBookings  int
+		wantAvailable int
+	}{
+		{
+			name:          "issue to user IDs and emails",
+			zoneShowID:    "show-1",
+			available:     10,
+			wantBookings:  2,
+			wantAvailable: 7,
+		},
+		{
+			name:          "unknown email",
+			modify:        func(req *dto.IssueCompsRequest) { req.Recipients[1].Email = "nobody@example.com" },
+			zoneShowID:    "show-1",
+			available:     10,
+			wantErr:       ErrCompRecipientNotFound,
+			wantAvailable: 10,
+		},
+		{
+			name:          "allocation too small",
+			zoneShowID:    "show-1",
+			available:     2,
+			wantErr:       ErrCompAllocationShort,
+			wantAvailable: 2,
+		},
+		{
+			name:          "zone of another show",
+			zoneShowID:    "show-2",
+			available:     10,
+			wantErr:       ErrCompZoneNotFound,
+			wantAvailable: 10,
+		},
+		{
+			name:          "public bucket",
+			modify:        func(req *dto.IssueCompsRequest) { req.Bucket = "public" },
+			zoneShowID:    "show-1",
+			available:     10,
+			wantErr:       ErrInvalidCompRequest,
+			wantAvailable: 10,
+		},
+		{
+			name: "recipient with both user ID and email",
+			modify: func(req *dto.IssueCompsRequest) {
+				req.Recipients[0].Email = "user-1@example.com"
+			},
+			zoneShowID:    "show-1",
+			available:     10,
+			wantErr:       ErrInvalidCompRequest,
+			wantAvailable: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockCompStore{tenantID: "tenant-1"}
+			issuer := &mockAllocationIssuer{available: tt.available}
+			users := &mockUserResolver{users: map[string]string{"guest@example.com": "user-2"}}
+			publisher := NewMockEventPublisher()
+			zones := &staticZoneFetcher{zone: &ZoneInfo{ID: "zone-1", ShowID: tt.zoneShowID}}
+			svc := NewCompService(store, zones, issuer, users, publisher, nil)
+
+			req := newRequest()
+			if tt.modify != nil {
+				tt.modify(req)
+			}
+			resp, err := svc.IssueComps(context.Background(), req, "Bearer organizer-token")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueComps() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(store.committed) != tt.wantBookings {
+				t.Fatalf("committed %d bookings, want %d", len(store.committed), tt.wantBookings)
+			}
+			if issuer.available != tt.wantAvailable {
+				t.Errorf("allocation = %d seats, want %d", issuer.available, tt.wantAvailable)
+			}
+			if len(publisher.confirmedEvents) != tt.wantBookings {
+				t.Errorf("published %d booking.confirmed events, want %d", len(publisher.confirmedEvents), tt.wantBookings)
+			}
+			if err != nil {
+				return
+			}
+
+			if resp.Seats != 3 || resp.Bucket != dto.CompBucketComp || len(resp.Bookings) != 2 {
+				t.Errorf("response = %+v, want 3 seats from comp in 2 bookings", resp)
+			}
+			if issuer.tokens[0] != "Bearer organizer-token" || issuer.issues[0].Reason != "sponsor" {
+				t.Errorf("issue = %+v with token %q, want the caller's token and reason", issuer.issues[0], issuer.tokens[0])
+			}
+			wantUsers := []string{"user-1", "user-2"}
+			for i, booking := range store.committed {
+				if booking.UserID != wantUsers[i] || booking.TenantID != "tenant-1" {
+					t.Errorf("booking %d user/tenant = %s/%s, want %s/tenant-1", i, booking.UserID, booking.TenantID, wantUsers[i])
+				}
+				if !booking.IsConfirmed() || !booking.IsComp() || booking.TotalPrice != 0 || booking.ConfirmationCode == "" {
+					t.Errorf("booking %d = %+v, want a confirmed, zero-price comp with a confirmation code", i, booking)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPAllocationIssuer_StatusCodes(t *testing.T) {
+	tests := []struct {
+		status  int
+		wantErr error
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNotFound, wantErr: ErrCompZoneNotFound},
+		{status: http.StatusConflict, wantErr: ErrCompAllocationShort},
+		{status: http.StatusForbidden, wantErr: ErrCompForbidden},
+		{status: http.StatusBadRequest, wantErr: ErrInvalidCompRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var gotPath, gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"success":false,"error":{"message":"bad bucket"}}`))
+			}))
+			defer server.Close()
+
+			issuer := NewHTTPAllocationIssuer(server.URL)
+			err := issuer.IssueAllocation(context.Background(), "Bearer token", &AllocationIssue{ZoneID: "zone-1", Bucket: "comp", Seats: 2})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("IssueAllocation() error = %v, want %v", err, tt.wantErr)
+			}
+			if gotPath != "/api/v1/zones/zone-1/allocations/issue" || gotAuth != "Bearer token" {
+				t.Errorf("request = %s with %q, want the issue endpoint with the caller's token", gotPath, gotAuth)
+			}
+		})
+	}
+}
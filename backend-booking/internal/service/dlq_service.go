@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/retry"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// ErrDLQNothingSelected is returned when a replay or purge matches no selection criteria
+	ErrDLQNothingSelected = errors.New("no dead letters selected")
+	// ErrDLQEditRequiresSingleID is returned when an edited payload is sent for more than one message
+	ErrDLQEditRequiresSingleID = errors.New("payload override requires exactly one dead letter id")
+	// ErrDLQRawPayload is returned when a dead letter holds a non-JSON payload and no override is given
+	ErrDLQRawPayload = errors.New("dead letter holds a raw (non-JSON) payload, provide an edited payload to replay it")
+)
+
+// DeadLetterStore is the subset of pkgsaga.PostgresStore used for DLQ tooling
+type DeadLetterStore interface {
+	ListDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) ([]*pkgsaga.DeadLetter, error)
+	CountDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error)
+	GetDeadLetter(ctx context.Context, id string) (*pkgsaga.DeadLetter, error)
+	MarkDeadLettersProcessed(ctx context.Context, ids []string) (int64, error)
+	PurgeDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error)
+}
+
+// DeadLetterPage is a page of dead letters with the total matching count
+type DeadLetterPage struct {
+	Items []*pkgsaga.DeadLetter `json:"items"`
+	Total int64                 `json:"total"`
+}
+
+// DLQReplayRequest selects dead letters to replay
+type DLQReplayRequest struct {
+	// IDs selects dead letters explicitly; if empty, Filter is used
+	IDs []string
+	// Filter selects dead letters when IDs is empty
+	Filter *pkgsaga.DeadLetterFilter
+	// Topic overrides the original topic as replay destination
+	Topic string
+	// Payload replaces the stored payload (only with a single ID)
+	Payload json.RawMessage
+	// KeepUnprocessed leaves replayed dead letters unprocessed (default marks them processed)
+	KeepUnprocessed bool
+}
+
+// DLQReplayResponse summarizes a replay
+type DLQReplayResponse struct {
+	*retry.ReplayResult
+	Selected      int   `json:"selected"`
+	MarkProcessed int64 `json:"marked_processed"`
+}
+
+// DLQService lists, inspects, replays and purges saga dead letters
+type DLQService interface {
+	ListDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (*DeadLetterPage, error)
+	GetDeadLetter(ctx context.Context, id string) (*pkgsaga.DeadLetter, error)
+	Replay(ctx context.Context, req *DLQReplayRequest) (*DLQReplayResponse, error)
+	MarkProcessed(ctx context.Context, ids []string) (int64, error)
+	Purge(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error)
+}
+
+// DLQServiceConfig holds configuration for DLQService
+type DLQServiceConfig struct {
+	// MaxReplayBatch caps how many dead letters one replay request may select
+	MaxReplayBatch int
+	// Replay configures replay rate limiting
+	Replay *retry.ReplayConfig
+}
+
+// dlqService implements DLQService on top of the saga dead letter table
+type dlqService struct {
+	store          DeadLetterStore
+	replayer       *retry.DLQReplayer
+	maxReplayBatch int
+}
+
+// NewDLQService creates a new DLQ service
+func NewDLQService(store DeadLetterStore, publisher retry.KafkaPublisher, cfg *DLQServiceConfig) DLQService {
+	if cfg == nil {
+		cfg = &DLQServiceConfig{}
+	}
+	if cfg.MaxReplayBatch <= 0 {
+		cfg.MaxReplayBatch = 500
+	}
+	if cfg.Replay == nil {
+		cfg.Replay = retry.DefaultReplayConfig()
+	}
+
+	return &dlqService{
+		store:          store,
+		replayer:       retry.NewDLQReplayer(publisher, cfg.Replay),
+		maxReplayBatch: cfg.MaxReplayBatch,
+	}
+}
+
+// ListDeadLetters returns a page of dead letters and the total matching count
+func (s *dlqService) ListDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (*DeadLetterPage, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.dlq.list")
+	defer span.End()
+
+	items, err := s.store.ListDeadLetters(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	total, err := s.store.CountDeadLetters(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if items == nil {
+		items = []*pkgsaga.DeadLetter{}
+	}
+
+	span.SetAttributes(attribute.Int64("total", total))
+	return &DeadLetterPage{Items: items, Total: total}, nil
+}
+
+// GetDeadLetter returns a single dead letter including its payload
+func (s *dlqService) GetDeadLetter(ctx context.Context, id string) (*pkgsaga.DeadLetter, error) {
+	return s.store.GetDeadLetter(ctx, id)
+}
+
+// Replay republishes the selected dead letters and marks the successful ones processed
+func (s *dlqService) Replay(ctx context.Context, req *DLQReplayRequest) (*DLQReplayResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.dlq.replay")
+	defer span.End()
+
+	if len(req.Payload) > 0 && len(req.IDs) != 1 {
+		return nil, ErrDLQEditRequiresSingleID
+	}
+
+	deadLetters, err := s.selectDeadLetters(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	msgs := make([]*retry.DLQMessage, 0, len(deadLetters))
+	result := &retry.ReplayResult{Errors: make(map[string]string)}
+	for _, dl := range deadLetters {
+		msg, err := deadLetterToDLQMessage(dl)
+		if err != nil && len(req.Payload) == 0 {
+			result.Failed++
+			result.Errors[dl.ID] = err.Error()
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+
+	replayed := s.replayer.ReplayAll(ctx, msgs, &retry.ReplayOptions{
+		Topic:   req.Topic,
+		Payload: req.Payload,
+	})
+	result.Replayed = replayed.Replayed
+	result.Failed += replayed.Failed
+	for id, msg := range replayed.Errors {
+		result.Errors[id] = msg
+	}
+
+	resp := &DLQReplayResponse{
+		ReplayResult: result,
+		Selected:     len(deadLetters),
+	}
+
+	if !req.KeepUnprocessed {
+		succeeded := make([]string, 0, result.Replayed)
+		for _, msg := range msgs {
+			if _, failed := result.Errors[msg.ID]; !failed {
+				succeeded = append(succeeded, msg.ID)
+			}
+		}
+		marked, err := s.store.MarkDeadLettersProcessed(ctx, succeeded)
+		if err != nil {
+			logger.Get().Error(fmt.Sprintf("Failed to mark replayed dead letters as processed: %v", err))
+		}
+		resp.MarkProcessed = marked
+	}
+
+	span.SetAttributes(
+		attribute.Int("selected", resp.Selected),
+		attribute.Int("replayed", result.Replayed),
+		attribute.Int("failed", result.Failed),
+	)
+	logger.Get().Info(fmt.Sprintf("DLQ replay finished: selected=%d, replayed=%d, failed=%d, topic_override=%q",
+		resp.Selected, result.Replayed, result.Failed, req.Topic))
+
+	return resp, nil
+}
+
+// MarkProcessed marks dead letters as processed without replaying them
+func (s *dlqService) MarkProcessed(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, ErrDLQNothingSelected
+	}
+	return s.store.MarkDeadLettersProcessed(ctx, ids)
+}
+
+// Purge deletes dead letters matching the filter
+func (s *dlqService) Purge(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error) {
+	if filter == nil {
+		return 0, ErrDLQNothingSelected
+	}
+	// Refuse an unbounded purge; operators must narrow it down. IncludeProcessed
+	// widens a selection rather than making one, so it does not count.
+	if len(filter.IDs) == 0 && filter.Topic == "" && filter.ErrorCode == "" &&
+		filter.SagaID == "" && filter.From.IsZero() && filter.To.IsZero() {
+		return 0, ErrDLQNothingSelected
+	}
+
+	purged, err := s.store.PurgeDeadLetters(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Get().Info(fmt.Sprintf("DLQ purge finished: purged=%d", purged))
+	return purged, nil
+}
+
+// selectDeadLetters resolves a replay request to the dead letters it targets
+func (s *dlqService) selectDeadLetters(ctx context.Context, req *DLQReplayRequest) ([]*pkgsaga.DeadLetter, error) {
+	filter := req.Filter
+	if len(req.IDs) > 0 {
+		filter = &pkgsaga.DeadLetterFilter{IDs: req.IDs, IncludeProcessed: true}
+	}
+	if filter == nil {
+		return nil, ErrDLQNothingSelected
+	}
+
+	if filter.Limit <= 0 || filter.Limit > s.maxReplayBatch {
+		filter.Limit = s.maxReplayBatch
+	}
+
+	deadLetters, err := s.store.ListDeadLetters(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(deadLetters) == 0 {
+		return nil, ErrDLQNothingSelected
+	}
+	return deadLetters, nil
+}
+
+// deadLetterToDLQMessage converts a stored dead letter into a replayable DLQ message.
+// The message is always returned so an edited payload can still be replayed.
+func deadLetterToDLQMessage(dl *pkgsaga.DeadLetter) (*retry.DLQMessage, error) {
+	msg := &retry.DLQMessage{
+		ID:            dl.ID,
+		OriginalTopic: dl.Topic,
+		OriginalKey:   dl.MessageKey,
+		Error:         dl.ErrorMessage,
+		ErrorCode:     dl.ErrorCode,
+		Attempts:      dl.RetryCount,
+		MovedToDLQAt:  dl.CreatedAt,
+	}
+
+	// DLQHandler wraps unparseable messages as {"raw_value": "..."}
+	if _, isRaw := dl.MessageValue["raw_value"]; isRaw && len(dl.MessageValue) == 1 {
+		return msg, ErrDLQRawPayload
+	}
+
+	payload, err := json.Marshal(dl.MessageValue)
+	if err != nil {
+		return msg, fmt.Errorf("failed to marshal dead letter payload: %w", err)
+	}
+	msg.Payload = payload
+
+	return msg, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+// mockDeadLetterStore is an in-memory DeadLetterStore
+type mockDeadLetterStore struct {
+	deadLetters []*pkgsaga.DeadLetter
+	marked      []string
+	purgeFilter *pkgsaga.DeadLetterFilter
+}
+
+func (m *mockDeadLetterStore) ListDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) ([]*pkgsaga.DeadLetter, error) {
+	var result []*pkgsaga.DeadLetter
+	for _, dl := range m.deadLetters {
+		if len(filter.IDs) > 0 && !containsString(filter.IDs, dl.ID) {
+			continue
+		}
+		if filter.Topic != "" && dl.Topic != filter.Topic {
+			continue
+		}
+		result = append(result, dl)
+	}
+	return result, nil
+}
+
+func (m *mockDeadLetterStore) CountDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error) {
+	items, _ := m.ListDeadLetters(ctx, filter)
+	return int64(len(items)), nil
+}
+
+func (m *mockDeadLetterStore) GetDeadLetter(ctx context.Context, id string) (*pkgsaga.DeadLetter, error) {
+	for _, dl := range m.deadLetters {
+		if dl.ID == id {
+			return dl, nil
+		}
+	}
+	return nil, pkgsaga.ErrDeadLetterNotFound
+}
+
+func (m *mockDeadLetterStore) MarkDeadLettersProcessed(ctx context.Context, ids []string) (int64, error) {
+	m.marked = append(m.marked, ids...)
+	return int64(len(ids)), nil
+}
+
+func (m *mockDeadLetterStore) PurgeDeadLetters(ctx context.Context, filter *pkgsaga.DeadLetterFilter) (int64, error) {
+	m.purgeFilter = filter
+	return 1, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingPublisher records replayed messages
+type recordingPublisher struct {
+	topics   []string
+	payloads []string
+	fail     bool
+}
+
+func (p *recordingPublisher) PublishJSON(ctx context.Context, topic string, key string, data interface{}, headers map[string]string) error {
+	if p.fail {
+		return errors.New("kafka unavailable")
+	}
+	raw, _ := json.Marshal(data)
+	p.topics = append(p.topics, topic)
+	p.payloads = append(p.payloads, string(raw))
+	return nil
+}
+
+func newTestDeadLetters() []*pkgsaga.DeadLetter {
+	return []*pkgsaga.DeadLetter{
+		{ID: "dl-1", Topic: "saga.booking.reserve-seats.command", MessageValue: map[string]interface{}{"saga_id": "s1"}},
+		{ID: "dl-2", Topic: "saga.booking.reserve-seats.command", MessageValue: map[string]interface{}{"saga_id": "s2"}},
+		{ID: "dl-raw", Topic: "saga.booking.process-payment.command", MessageValue: map[string]interface{}{"raw_value": "garbage"}},
+	}
+}
+
+func TestDLQService_ReplayByFilter(t *testing.T) {
+	store := &mockDeadLetterStore{deadLetters: newTestDeadLetters()}
+	publisher := &recordingPublisher{}
+	svc := NewDLQService(store, publisher, nil)
+
+	resp, err := svc.Replay(context.Background(), &DLQReplayRequest{
+		Filter: &pkgsaga.DeadLetterFilter{Topic: "saga.booking.reserve-seats.command"},
+	})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if resp.Replayed != 2 || resp.Failed != 0 {
+		t.Errorf("Replayed = %d, Failed = %d, want 2, 0", resp.Replayed, resp.Failed)
+	}
+	if len(publisher.topics) != 2 || publisher.topics[0] != "saga.booking.reserve-seats.command" {
+		t.Errorf("published topics = %v", publisher.topics)
+	}
+	if len(store.marked) != 2 {
+		t.Errorf("marked = %v, want both replayed dead letters", store.marked)
+	}
+}
+
+func TestDLQService_ReplayRawPayload(t *testing.T) {
+	store := &mockDeadLetterStore{deadLetters: newTestDeadLetters()}
+	publisher := &recordingPublisher{}
+	svc := NewDLQService(store, publisher, nil)
+
+	resp, err := svc.Replay(context.Background(), &DLQReplayRequest{IDs: []string{"dl-raw"}})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if resp.Failed != 1 || len(publisher.topics) != 0 {
+		t.Errorf("raw payload should not be replayed without an edit, got failed=%d published=%d", resp.Failed, len(publisher.topics))
+	}
+	if len(store.marked) != 0 {
+		t.Errorf("failed replay should not be marked processed, got %v", store.marked)
+	}
+
+	// With an edited payload the raw dead letter can be replayed
+	resp, err = svc.Replay(context.Background(), &DLQReplayRequest{
+		IDs:     []string{"dl-raw"},
+		Payload: json.RawMessage(`{"saga_id":"fixed"}`),
+	})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if resp.Replayed != 1 || publisher.payloads[0] != `{"saga_id":"fixed"}` {
+		t.Errorf("edited replay: replayed=%d payloads=%v", resp.Replayed, publisher.payloads)
+	}
+}
+
+func TestDLQService_ReplayEditRequiresSingleID(t *testing.T) {
+	svc := NewDLQService(&mockDeadLetterStore{deadLetters: newTestDeadLetters()}, &recordingPublisher{}, nil)
+
+	_, err := svc.Replay(context.Background(), &DLQReplayRequest{
+		IDs:     []string{"dl-1", "dl-2"},
+		Payload: json.RawMessage(`{}`),
+	})
+	if !errors.Is(err, ErrDLQEditRequiresSingleID) {
+		t.Errorf("error = %v, want ErrDLQEditRequiresSingleID", err)
+	}
+}
+
+func TestDLQService_ReplayPublishFailure(t *testing.T) {
+	store := &mockDeadLetterStore{deadLetters: newTestDeadLetters()}
+	svc := NewDLQService(store, &recordingPublisher{fail: true}, nil)
+
+	resp, err := svc.Replay(context.Background(), &DLQReplayRequest{IDs: []string{"dl-1"}})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if resp.Failed != 1 || resp.Errors["dl-1"] == "" {
+		t.Errorf("expected dl-1 to fail, got %+v", resp.ReplayResult)
+	}
+	if len(store.marked) != 0 {
+		t.Errorf("failed replay should not be marked processed, got %v", store.marked)
+	}
+}
+
+func TestDLQService_ReplayNothingSelected(t *testing.T) {
+	svc := NewDLQService(&mockDeadLetterStore{}, &recordingPublisher{}, nil)
+
+	if _, err := svc.Replay(context.Background(), &DLQReplayRequest{}); !errors.Is(err, ErrDLQNothingSelected) {
+		t.Errorf("error = %v, want ErrDLQNothingSelected", err)
+	}
+}
+
+func TestDLQService_PurgeRequiresSelector(t *testing.T) {
+	store := &mockDeadLetterStore{}
+	svc := NewDLQService(store, &recordingPublisher{}, nil)
+
+	if _, err := svc.Purge(context.Background(), &pkgsaga.DeadLetterFilter{}); !errors.Is(err, ErrDLQNothingSelected) {
+		t.Errorf("error = %v, want ErrDLQNothingSelected", err)
+	}
+	if _, err := svc.Purge(context.Background(), &pkgsaga.DeadLetterFilter{IncludeProcessed: true}); !errors.Is(err, ErrDLQNothingSelected) {
+		t.Errorf("error = %v, want ErrDLQNothingSelected for include_processed alone", err)
+	}
+	if store.purgeFilter != nil {
+		t.Error("store should not be called for an unbounded purge")
+	}
+
+	purged, err := svc.Purge(context.Background(), &pkgsaga.DeadLetterFilter{Topic: "saga.booking.reserve-seats.command"})
+	if err != nil || purged != 1 {
+		t.Errorf("Purge() = %d, %v", purged, err)
+	}
+}
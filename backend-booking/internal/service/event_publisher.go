@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -32,7 +31,8 @@ type EventPublisher interface {
 
 // KafkaEventPublisher implements EventPublisher using Kafka
 type KafkaEventPublisher struct {
-	producer    *kafka.Producer
+	producer    kafka.MessageProducer
+	codecs      *kafka.CodecRegistry
 	topic       string
 	serviceName string
 	logger      Logger
@@ -51,6 +51,8 @@ type EventPublisherConfig struct {
 	ServiceName string
 	ClientID    string
 	Logger      Logger
+	// Codecs selects the payload encoding for the topic (default: JSON)
+	Codecs *kafka.CodecRegistry
 }
 
 // NewKafkaEventPublisher creates a new Kafka event publisher
@@ -90,8 +92,14 @@ func NewKafkaEventPublisher(ctx context.Context, cfg *EventPublisherConfig) (*Ka
 		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
 	}
 
+	codecs := cfg.Codecs
+	if codecs == nil {
+		codecs = kafka.DefaultCodecs
+	}
+
 	return &KafkaEventPublisher{
 		producer:    producer,
+		codecs:      codecs,
 		topic:       topic,
 		serviceName: serviceName,
 		logger:      cfg.Logger,
@@ -131,16 +139,16 @@ func (p *KafkaEventPublisher) publishEvent(ctx context.Context, eventType domain
 	eventID := uuid.New().String()
 	event := domain.NewBookingEvent(eventType, booking, eventID)
 
-	value, err := json.Marshal(event)
+	value, contentType, err := p.codecs.Encode(p.topic, event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
 	headers := map[string]string{
-		"event_type":   string(eventType),
-		"event_id":     eventID,
-		"source":       p.serviceName,
-		"content_type": "application/json",
+		"event_type":            string(eventType),
+		"event_id":              eventID,
+		"source":                p.serviceName,
+		kafka.HeaderContentType: contentType,
 	}
 
 	msg := &kafka.Message{
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Identity limiter errors
+var (
+	ErrInvalidIdentityLimits = errors.New("invalid identity limits")
+	ErrPaymentNotFound       = errors.New("payment not found")
+)
+
+// Stages at which identities are checked, as reported in metrics and audit entries
+const (
+	identityStageReserve = "reserve"
+	identityStagePayment = "payment"
+)
+
+// PaymentCard is the card a payment was made with
+type PaymentCard struct {
+	BookingID    string `json:"booking_id"`
+	CardBrand    string `json:"card_brand"`
+	CardLastFour string `json:"card_last_four"`
+}
+
+// PaymentCardFetcher looks up the card of a payment
+type PaymentCardFetcher interface {
+	// FetchPaymentCard fetches the card of a payment with the caller's token
+	FetchPaymentCard(ctx context.Context, authToken, paymentID string) (*PaymentCard, error)
+}
+
+// IdentityAuditor records limit hits; satisfied by *middleware.AuditLogger
+type IdentityAuditor interface {
+	Log(entry *middleware.AuditEntry)
+}
+
+// IdentityLimiter enforces per-event purchase limits on the identities behind
+// an account (verified phone, payment card, device, IP subnet), so buyers
+// cannot get around the per-user limit by spreading purchases over accounts.
+type IdentityLimiter interface {
+	// ClaimReservation charges a reservation's seats to the buyer's phone,
+	// device and IP subnet; domain.ErrIdentityLimitExceeded if one is at its
+	// limit. It reports whether seats were charged, which the caller must
+	// Release if the reservation fails.
+	ClaimReservation(ctx context.Context, bookingID, userID string, req *dto.ReserveSeatsRequest) (bool, error)
+
+	// ClaimPayment charges a booking's seats to the card it is paid with;
+	// domain.ErrIdentityLimitExceeded if the card is at its limit. It reports
+	// whether seats were charged, like ClaimReservation.
+	ClaimPayment(ctx context.Context, booking *domain.Booking, req *dto.ConfirmBookingRequest) (bool, error)
+
+	// Release gives back the seats charged under claimIDs
+	Release(ctx context.Context, eventID string, claimIDs ...string)
+
+	// GetLimits returns the limits in force for an event
+	GetLimits(ctx context.Context, eventID string) (*dto.IdentityLimitsResponse, error)
+
+	// SetLimits replaces an event's limit overrides
+	SetLimits(ctx context.Context, eventID string, req *dto.SetIdentityLimitsRequest) (*dto.IdentityLimitsResponse, error)
+}
+
+// IdentityLimiterConfig contains configuration for the identity limiter
+type IdentityLimiterConfig struct {
+	// Limits are the default seats per event for each identity kind (0 = unlimited)
+	Limits map[string]int
+	// Window is how long seats count against an identity (default: 7 days)
+	Window time.Duration
+	// Secret keys the fingerprints identities are stored under
+	Secret string
+	// LimitsCacheTTL is how long an event's overrides are cached (default: 30s)
+	LimitsCacheTTL time.Duration
+}
+
+// cachedIdentityLimits is an event's overrides as last read from the store
+type cachedIdentityLimits struct {
+	overrides map[string]int
+	expiresAt time.Time
+}
+
+// identityLimiter implements IdentityLimiter
+type identityLimiter struct {
+	store    repository.IdentityLimitStore
+	payments PaymentCardFetcher
+	auditor  IdentityAuditor
+	defaults map[string]int
+	window   time.Duration
+	secret   []byte
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*cachedIdentityLimits
+}
+
+// NewIdentityLimiter creates a new identity limiter. payments and auditor are
+// optional: without payments cards are not limited, without auditor limit
+// hits are only counted in metrics.
+func NewIdentityLimiter(store repository.IdentityLimitStore, payments PaymentCardFetcher, auditor IdentityAuditor, cfg *IdentityLimiterConfig) IdentityLimiter {
+	l := &identityLimiter{
+		store:    store,
+		payments: payments,
+		auditor:  auditor,
+		defaults: make(map[string]int),
+		window:   7 * 24 * time.Hour,
+		cacheTTL: 30 * time.Second,
+		cache:    make(map[string]*cachedIdentityLimits),
+	}
+	if cfg != nil {
+		for kind, max := range cfg.Limits {
+			if max > 0 {
+				l.defaults[kind] = max
+			}
+		}
+		if cfg.Window > 0 {
+			l.window = cfg.Window
+		}
+		if cfg.LimitsCacheTTL > 0 {
+			l.cacheTTL = cfg.LimitsCacheTTL
+		}
+		l.secret = []byte(cfg.Secret)
+	}
+	return l
+}
+
+// ClaimReservation charges a reservation's seats to the buyer's identities
+func (l *identityLimiter) ClaimReservation(ctx context.Context, bookingID, userID string, req *dto.ReserveSeatsRequest) (bool, error) {
+	identities := map[string]string{
+		dto.IdentityKindPhone:    normalizePhone(req.Identity.Phone),
+		dto.IdentityKindDevice:   strings.TrimSpace(req.Identity.DeviceID),
+		dto.IdentityKindIPSubnet: ipSubnet(req.Identity.IP),
+	}
+	return l.claim(ctx, identityStageReserve, &identityCheck{
+		eventID:    req.EventID,
+		claimID:    bookingID,
+		bookingID:  bookingID,
+		userID:     userID,
+		tenantID:   req.TenantID,
+		quantity:   req.Quantity,
+		identities: identities,
+		identity:   req.Identity,
+	})
+}
+
+// ClaimPayment charges a booking's seats to the card of its payment
+func (l *identityLimiter) ClaimPayment(ctx context.Context, booking *domain.Booking, req *dto.ConfirmBookingRequest) (bool, error) {
+	if l.payments == nil || req == nil || req.PaymentID == "" {
+		return false, nil
+	}
+
+	card, err := l.payments.FetchPaymentCard(ctx, req.AuthToken, req.PaymentID)
+	if err != nil {
+		// Fail open: a payment service hiccup must not block paid bookings
+		logger.Get().Warn(fmt.Sprintf("Identity limits: failed to fetch card of payment %s: %v", req.PaymentID, err))
+		return false, nil
+	}
+	if card.BookingID != "" && card.BookingID != booking.ID {
+		logger.Get().Warn(fmt.Sprintf("Identity limits: payment %s belongs to booking %s, not %s", req.PaymentID, card.BookingID, booking.ID))
+		return false, nil
+	}
+
+	identity := req.Identity
+	identity.CardBrand = card.CardBrand
+	identity.CardLastFour = card.CardLastFour
+	return l.claim(ctx, identityStagePayment, &identityCheck{
+		eventID:    booking.EventID,
+		claimID:    repository.PaymentClaimID(booking.ID),
+		bookingID:  booking.ID,
+		userID:     booking.UserID,
+		tenantID:   booking.TenantID,
+		quantity:   booking.Quantity,
+		identities: map[string]string{dto.IdentityKindCard: normalizeCard(card.CardBrand, card.CardLastFour)},
+		identity:   identity,
+	})
+}
+
+// identityCheck is one charge of a booking's seats to its identities
+type identityCheck struct {
+	eventID    string
+	claimID    string
+	bookingID  string
+	userID     string
+	tenantID   string
+	quantity   int
+	identities map[string]string // kind -> normalized value ("" = unknown)
+	identity   dto.PurchaseIdentity
+}
+
+// claim charges seats to every known identity that has a limit and reports
+// whether it did. Store errors are logged and let the purchase through; the
+// per-user limit still applies.
+func (l *identityLimiter) claim(ctx context.Context, stage string, check *identityCheck) (bool, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.identity_limiter.claim")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("stage", stage),
+		attribute.String("event_id", check.eventID),
+		attribute.String("booking_id", check.bookingID),
+	)
+
+	limits, _, err := l.limits(ctx, check.eventID)
+	if err != nil {
+		span.RecordError(err)
+		logger.Get().Warn(fmt.Sprintf("Identity limits: failed to read limits of event %s: %v", check.eventID, err))
+		return false, nil
+	}
+
+	var claims []repository.IdentityClaim
+	for _, kind := range dto.IdentityKinds {
+		value := check.identities[kind]
+		if value == "" || limits[kind] <= 0 {
+			continue
+		}
+		claims = append(claims, repository.IdentityClaim{
+			Kind:  kind,
+			Value: l.fingerprint(kind, value),
+			Max:   limits[kind],
+		})
+	}
+	span.SetAttributes(attribute.Int("identities", len(claims)))
+	if len(claims) == 0 {
+		return false, nil
+	}
+
+	result, err := l.store.ClaimIdentities(ctx, check.eventID, check.claimID, check.quantity, claims, l.window)
+	if err != nil {
+		span.RecordError(err)
+		logger.Get().Warn(fmt.Sprintf("Identity limits: failed to claim identities for booking %s: %v", check.bookingID, err))
+		return false, nil
+	}
+	if result.Success {
+		span.SetStatus(codes.Ok, "")
+		return !result.AlreadyClaimed, nil
+	}
+
+	exceeded := result.Exceeded
+	span.SetAttributes(attribute.String("exceeded_kind", exceeded.Kind))
+	span.SetStatus(codes.Error, "identity limit exceeded")
+	metrics.RecordIdentityLimitHit(ctx, check.eventID, exceeded.Kind, stage)
+	l.audit(stage, check, exceeded, result.Current)
+	return false, domain.ErrIdentityLimitExceeded
+}
+
+// audit records a limit hit in the audit log
+func (l *identityLimiter) audit(stage string, check *identityCheck, exceeded *repository.IdentityClaim, current int64) {
+	if l.auditor == nil {
+		return
+	}
+
+	action := middleware.AuditActionReserve
+	if stage == identityStagePayment {
+		action = middleware.AuditActionConfirm
+	}
+	entry := &middleware.AuditEntry{
+		ID:           uuid.New().String(),
+		UserID:       optionalString(check.userID),
+		TenantID:     optionalString(check.tenantID),
+		Action:       action,
+		ResourceType: "identity_limit",
+		ResourceID:   optionalString(check.bookingID),
+		IPAddress:    check.identity.IP,
+		UserAgent:    check.identity.UserAgent,
+		RequestID:    check.identity.RequestID,
+		Metadata: map[string]interface{}{
+			"event_id":    check.eventID,
+			"stage":       stage,
+			"kind":        exceeded.Kind,
+			"fingerprint": exceeded.Value,
+			"limit":       exceeded.Max,
+			"current":     current,
+			"requested":   check.quantity,
+		},
+		CreatedAt: time.Now(),
+	}
+	l.auditor.Log(entry)
+}
+
+// Release gives back the seats charged under claimIDs. A failed release only
+// holds the identities' seats until the window ends, so it is logged.
+func (l *identityLimiter) Release(ctx context.Context, eventID string, claimIDs ...string) {
+	if err := l.store.ReleaseIdentities(ctx, eventID, claimIDs...); err != nil {
+		logger.Get().Warn(fmt.Sprintf("Identity limits: failed to release claims %v: %v", claimIDs, err))
+	}
+}
+
+// GetLimits returns the limits in force for an event
+func (l *identityLimiter) GetLimits(ctx context.Context, eventID string) (*dto.IdentityLimitsResponse, error) {
+	limits, overrides, err := l.limits(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.IdentityLimitsResponse{
+		EventID:   eventID,
+		Limits:    limits,
+		Overrides: overrides,
+	}, nil
+}
+
+// SetLimits replaces an event's limit overrides
+func (l *identityLimiter) SetLimits(ctx context.Context, eventID string, req *dto.SetIdentityLimitsRequest) (*dto.IdentityLimitsResponse, error) {
+	if valid, msg := req.Validate(); !valid {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIdentityLimits, msg)
+	}
+	if err := l.store.SetIdentityLimits(ctx, eventID, req.Limits); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	delete(l.cache, eventID)
+	l.mu.Unlock()
+
+	logger.Get().Info(fmt.Sprintf("Identity limits of event %s set to %v", eventID, req.Limits))
+	return l.GetLimits(ctx, eventID)
+}
+
+// limits returns the limits in force for an event and its overrides
+func (l *identityLimiter) limits(ctx context.Context, eventID string) (map[string]int, map[string]int, error) {
+	l.mu.Lock()
+	cached, ok := l.cache[eventID]
+	l.mu.Unlock()
+
+	if !ok || time.Now().After(cached.expiresAt) {
+		overrides, err := l.store.GetIdentityLimits(ctx, eventID)
+		if err != nil {
+			return nil, nil, err
+		}
+		cached = &cachedIdentityLimits{overrides: overrides, expiresAt: time.Now().Add(l.cacheTTL)}
+		l.mu.Lock()
+		l.cache[eventID] = cached
+		l.mu.Unlock()
+	}
+
+	limits := make(map[string]int, len(dto.IdentityKinds))
+	for _, kind := range dto.IdentityKinds {
+		limits[kind] = l.defaults[kind]
+		if max, ok := cached.overrides[kind]; ok {
+			limits[kind] = max
+		}
+	}
+	return limits, cached.overrides, nil
+}
+
+// fingerprint keys an identity value so that raw phone numbers, cards and
+// addresses are never stored in Redis
+func (l *identityLimiter) fingerprint(kind, value string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// normalizePhone keeps the digits of a phone number and its leading +
+func normalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	for i, r := range phone {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalizeCard identifies a card by brand and last four digits
+func normalizeCard(brand, lastFour string) string {
+	lastFour = strings.TrimSpace(lastFour)
+	if len(lastFour) != 4 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(brand)) + ":" + lastFour
+}
+
+// ipSubnet masks an IP to its /24 (IPv4) or /64 (IPv6) network, so that
+// buyers cannot dodge the limit by hopping addresses within one network
+func ipSubnet(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil || parsed.IsLoopback() {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+// optionalString returns nil for an empty string
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// HTTPPaymentCardFetcher fetches payment cards via HTTP from the payment service
+type HTTPPaymentCardFetcher struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPPaymentCardFetcher creates a new HTTP payment card fetcher
+func NewHTTPPaymentCardFetcher(paymentServiceURL string) *HTTPPaymentCardFetcher {
+	return &HTTPPaymentCardFetcher{
+		baseURL: paymentServiceURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// FetchPaymentCard calls GET /api/v1/payments/:id
+func (f *HTTPPaymentCardFetcher) FetchPaymentCard(ctx context.Context, authToken, paymentID string) (*PaymentCard, error) {
+	url := fmt.Sprintf("%s/api/v1/payments/%s", f.baseURL, paymentID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentNotFound, paymentID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Parse response - backend returns { success: true, data: PaymentResponse }
+	var response struct {
+		Success bool        `json:"success"`
+		Data    PaymentCard `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("API returned unsuccessful response")
+	}
+	return &response.Data, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+// recordingAuditor keeps the entries it is given
+type recordingAuditor struct {
+	entries []*middleware.AuditEntry
+}
+
+func (a *recordingAuditor) Log(entry *middleware.AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+// staticPaymentCards returns fixed cards per payment ID
+type staticPaymentCards map[string]*PaymentCard
+
+func (p staticPaymentCards) FetchPaymentCard(ctx context.Context, authToken, paymentID string) (*PaymentCard, error) {
+	card, ok := p[paymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	return card, nil
+}
+
+// failingIdentityStore fails every call
+type failingIdentityStore struct{}
+
+func (failingIdentityStore) ClaimIdentities(ctx context.Context, eventID, claimID string, quantity int, claims []repository.IdentityClaim, window time.Duration) (*repository.IdentityClaimResult, error) {
+	return nil, errors.New("redis down")
+}
+
+func (failingIdentityStore) ReleaseIdentities(ctx context.Context, eventID string, claimIDs ...string) error {
+	return errors.New("redis down")
+}
+
+func (failingIdentityStore) GetIdentityLimits(ctx context.Context, eventID string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (failingIdentityStore) SetIdentityLimits(ctx context.Context, eventID string, limits map[string]int) error {
+	return errors.New("redis down")
+}
+
+func newTestIdentityLimiter(t *testing.T, payments PaymentCardFetcher) (IdentityLimiter, *recordingAuditor) {
+	t.Helper()
+
+	client, _ := redistest.NewClient(t)
+	repo := repository.NewRedisReservationRepository(client).WithIdentityLimits(true)
+	auditor := &recordingAuditor{}
+	limiter := NewIdentityLimiter(repo, payments, auditor, &IdentityLimiterConfig{
+		Limits: map[string]int{
+			dto.IdentityKindPhone:    4,
+			dto.IdentityKindCard:     4,
+			dto.IdentityKindDevice:   4,
+			dto.IdentityKindIPSubnet: 10,
+		},
+		Secret: "test-secret",
+	})
+	return limiter, auditor
+}
+
+func reserveRequest(quantity int, identity dto.PurchaseIdentity) *dto.ReserveSeatsRequest {
+	return &dto.ReserveSeatsRequest{EventID: "event-1", ZoneID: "zone-1", Quantity: quantity, Identity: identity}
+}
+
+func TestIdentityLimiter_ClaimReservation(t *testing.T) {
+	limiter, auditor := newTestIdentityLimiter(t, nil)
+	ctx := context.Background()
+
+	// Two accounts share a phone, spelled differently
+	first := dto.PurchaseIdentity{Phone: "+66 81 234 5678", IP: "203.0.113.10"}
+	second := dto.PurchaseIdentity{Phone: "+66812345678", IP: "198.51.100.7", UserAgent: "bot/1.0", RequestID: "req-2"}
+
+	claimed, err := limiter.ClaimReservation(ctx, "booking-1", "user-1", reserveRequest(3, first))
+	if err != nil || !claimed {
+		t.Fatalf("ClaimReservation() = %v, %v, want claimed", claimed, err)
+	}
+	claimed, err = limiter.ClaimReservation(ctx, "booking-1", "user-1", reserveRequest(3, first))
+	if err != nil || claimed {
+		t.Errorf("ClaimReservation() retry = %v, %v, want nothing charged", claimed, err)
+	}
+
+	if _, err := limiter.ClaimReservation(ctx, "booking-2", "user-2", reserveRequest(2, second)); !errors.Is(err, domain.ErrIdentityLimitExceeded) {
+		t.Fatalf("ClaimReservation() error = %v, want %v", err, domain.ErrIdentityLimitExceeded)
+	}
+	if len(auditor.entries) != 1 {
+		t.Fatalf("audit entries = %d, want 1", len(auditor.entries))
+	}
+	entry := auditor.entries[0]
+	if entry.Action != middleware.AuditActionReserve || entry.Metadata["kind"] != dto.IdentityKindPhone || entry.Metadata["current"] != int64(3) {
+		t.Errorf("audit entry = %+v, want a reserve hit on phone holding 3", entry)
+	}
+	if entry.RequestID != "req-2" || *entry.UserID != "user-2" {
+		t.Errorf("audit entry = %+v, want request req-2 of user-2", entry)
+	}
+	if fingerprint, _ := entry.Metadata["fingerprint"].(string); len(fingerprint) != 32 || fingerprint == "+66812345678" {
+		t.Errorf("audit fingerprint = %q, want an opaque hash", fingerprint)
+	}
+
+	// Releasing the first booking frees the phone for the second
+	limiter.Release(ctx, "event-1", "booking-1")
+	if claimed, err := limiter.ClaimReservation(ctx, "booking-2", "user-2", reserveRequest(2, second)); err != nil || !claimed {
+		t.Errorf("ClaimReservation() after release = %v, %v, want claimed", claimed, err)
+	}
+}
+
+func TestIdentityLimiter_Overrides(t *testing.T) {
+	limiter, _ := newTestIdentityLimiter(t, nil)
+	ctx := context.Background()
+
+	if _, err := limiter.SetLimits(ctx, "event-1", &dto.SetIdentityLimitsRequest{Limits: map[string]int{"email": 2}}); !errors.Is(err, ErrInvalidIdentityLimits) {
+		t.Errorf("SetLimits() unknown kind error = %v, want %v", err, ErrInvalidIdentityLimits)
+	}
+
+	limits, err := limiter.SetLimits(ctx, "event-1", &dto.SetIdentityLimitsRequest{Limits: map[string]int{
+		dto.IdentityKindDevice:   2,
+		dto.IdentityKindIPSubnet: 0,
+	}})
+	if err != nil {
+		t.Fatalf("SetLimits() error = %v", err)
+	}
+	if limits.Limits[dto.IdentityKindDevice] != 2 || limits.Limits[dto.IdentityKindIPSubnet] != 0 || limits.Limits[dto.IdentityKindPhone] != 4 {
+		t.Errorf("limits = %v, want device 2, ip_subnet unlimited, phone default 4", limits.Limits)
+	}
+	if len(limits.Overrides) != 2 {
+		t.Errorf("overrides = %v, want 2", limits.Overrides)
+	}
+
+	identity := dto.PurchaseIdentity{DeviceID: "device-1", IP: "203.0.113.10"}
+	if _, err := limiter.ClaimReservation(ctx, "booking-1", "user-1", reserveRequest(3, identity)); !errors.Is(err, domain.ErrIdentityLimitExceeded) {
+		t.Errorf("ClaimReservation() error = %v, want the device override to apply", err)
+	}
+	// Another event keeps the defaults
+	other := reserveRequest(3, identity)
+	other.EventID = "event-2"
+	if _, err := limiter.ClaimReservation(ctx, "booking-2", "user-1", other); err != nil {
+		t.Errorf("ClaimReservation() other event error = %v", err)
+	}
+}
+
+func TestIdentityLimiter_ClaimPayment(t *testing.T) {
+	limiter, auditor := newTestIdentityLimiter(t, staticPaymentCards{
+		"pay-1": {BookingID: "booking-1", CardBrand: "Visa", CardLastFour: "4242"},
+		"pay-2": {BookingID: "booking-2", CardBrand: "visa", CardLastFour: "4242"},
+		"pay-3": {BookingID: "booking-9", CardBrand: "visa", CardLastFour: "4242"},
+	})
+	ctx := context.Background()
+
+	booking := func(id string) *domain.Booking {
+		return &domain.Booking{ID: id, UserID: "user-" + id, EventID: "event-1", Quantity: 3}
+	}
+
+	claimed, err := limiter.ClaimPayment(ctx, booking("booking-1"), &dto.ConfirmBookingRequest{PaymentID: "pay-1"})
+	if err != nil || !claimed {
+		t.Fatalf("ClaimPayment() = %v, %v, want claimed", claimed, err)
+	}
+	if _, err := limiter.ClaimPayment(ctx, booking("booking-2"), &dto.ConfirmBookingRequest{PaymentID: "pay-2"}); !errors.Is(err, domain.ErrIdentityLimitExceeded) {
+		t.Fatalf("ClaimPayment() same card error = %v, want %v", err, domain.ErrIdentityLimitExceeded)
+	}
+	if len(auditor.entries) != 1 || auditor.entries[0].Action != middleware.AuditActionConfirm {
+		t.Errorf("audit entries = %+v, want one confirm hit", auditor.entries)
+	}
+
+	// Unknown payments and payments of other bookings are let through
+	for _, paymentID := range []string{"pay-3", "pay-404", ""} {
+		if claimed, err := limiter.ClaimPayment(ctx, booking("booking-3"), &dto.ConfirmBookingRequest{PaymentID: paymentID}); err != nil || claimed {
+			t.Errorf("ClaimPayment(%q) = %v, %v, want nothing charged", paymentID, claimed, err)
+		}
+	}
+}
+
+func TestIdentityLimiter_FailsOpen(t *testing.T) {
+	limiter := NewIdentityLimiter(failingIdentityStore{}, nil, nil, &IdentityLimiterConfig{
+		Limits: map[string]int{dto.IdentityKindDevice: 1},
+	})
+
+	claimed, err := limiter.ClaimReservation(context.Background(), "booking-1", "user-1", reserveRequest(5, dto.PurchaseIdentity{DeviceID: "device-1"}))
+	if err != nil || claimed {
+		t.Errorf("ClaimReservation() = %v, %v, want the purchase let through", claimed, err)
+	}
+}
+
+func TestIdentityNormalization(t *testing.T) {
+	subnets := map[string]string{
+		"203.0.113.77":         "203.0.113.0/24",
+		" 198.51.100.1 ":       "198.51.100.0/24",
+		"2001:db8:1:2:3:4:5:6": "2001:db8:1:2::/64",
+		"127.0.0.1":            "",
+		"not-an-ip":            "",
+		"::ffff:203.0.113.200": "203.0.113.0/24",
+	}
+	for ip, want := range subnets {
+		if got := ipSubnet(ip); got != want {
+			t.Errorf("ipSubnet(%q) = %q, want %q", ip, got, want)
+		}
+	}
+
+	if got := normalizePhone(" +66 (81) 234-5678 "); got != "+66812345678" {
+		t.Errorf("normalizePhone() = %q", got)
+	}
+	if got := normalizeCard(" VISA ", "4242"); got != "visa:4242" {
+		t.Errorf("normalizeCard() = %q", got)
+	}
+	if got := normalizeCard("visa", "42"); got != "" {
+		t.Errorf("normalizeCard() short = %q, want empty", got)
+	}
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrReconcileInProgress is returned when another replica holds the reconciliation lock
+var ErrReconcileInProgress = errors.New("inventory reconciliation already in progress")
+
+// Inventory drift sources
+const (
+	InventorySourceRedis    = "redis"
+	InventorySourceTicketDB = "ticket_db"
+)
+
+// Zone reconciliation statuses
+const (
+	ZoneStatusInSync       = "in_sync"
+	ZoneStatusDrift        = "drift"         // Drift seen, not (yet) corrected
+	ZoneStatusCorrected    = "corrected"     // Redis was adjusted in this run
+	ZoneStatusOutOfBounds  = "out_of_bounds" // Drift larger than MaxCorrection, needs an operator
+	ZoneStatusRedisMissing = "redis_missing" // Zone has no availability key in Redis
+)
+
+// ZoneReconciliation is the reconciliation result for one zone.
+// Drift is observed minus expected available seats, so a positive drift
+// means the source would sell more seats than actually remain.
+type ZoneReconciliation struct {
+	ZoneID            string `json:"zone_id"`
+	ShowID            string `json:"show_id"`
+	Name              string `json:"name"`
+	TotalSeats        int64  `json:"total_seats"`
+	AllocatedSeats    int64  `json:"allocated_seats"` // Held and comp allocations, not on sale
+	SoldSeats         int64  `json:"sold_seats"`
+	HeldSeats         int64  `json:"held_seats"`
+	ExpectedAvailable int64  `json:"expected_available"`
+	RedisAvailable    *int64 `json:"redis_available"` // nil when the zone is not in Redis
+	RedisDrift        int64  `json:"redis_drift"`
+	TicketAvailable   int64  `json:"ticket_available"`
+	TicketDrift       int64  `json:"ticket_drift"`
+	Correction        int64  `json:"correction,omitempty"`
+	Status            string `json:"status"`
+}
+
+// InventoryReport summarizes one reconciliation run
+type InventoryReport struct {
+	StartedAt      time.Time             `json:"started_at"`
+	DurationMs     int64                 `json:"duration_ms"`
+	DryRun         bool                  `json:"dry_run"`
+	ZonesChecked   int                   `json:"zones_checked"`
+	ZonesDrifted   int                   `json:"zones_drifted"`
+	ZonesCorrected int                   `json:"zones_corrected"`
+	SeatsCorrected int64                 `json:"seats_corrected"`
+	Zones          []*ZoneReconciliation `json:"zones"`
+}
+
+// InventoryReconciler compares zone availability in Redis and the ticket DB
+// against what booking rows and live reservations imply
+type InventoryReconciler interface {
+	// Reconcile runs one pass; a dry run reports drift without correcting it
+	Reconcile(ctx context.Context, dryRun bool) (*InventoryReport, error)
+	// LastReport returns the most recent report, or nil before the first run
+	LastReport() *InventoryReport
+	// Run reconciles every Interval until ctx is cancelled
+	Run(ctx context.Context)
+}
+
+// InventoryReconcilerConfig holds configuration for InventoryReconciler
+type InventoryReconcilerConfig struct {
+	// Interval between periodic runs (default: 1m)
+	Interval time.Duration
+	// AutoCorrect enables adjusting Redis availability; the ticket DB is never
+	// corrected because InventoryWorker owns seat_zones
+	AutoCorrect bool
+	// MaxCorrection is the largest drift, in seats, corrected automatically (default: 10)
+	MaxCorrection int64
+	// ConfirmRuns is how many consecutive runs must observe the same drift
+	// before it is corrected, so in-flight reservations are not mistaken for
+	// drift (default: 2)
+	ConfirmRuns int
+	// LockTTL bounds how long one run may hold the cross-replica lock (default: 1m)
+	LockTTL time.Duration
+}
+
+// inventoryReconciler implements InventoryReconciler
+type inventoryReconciler struct {
+	zones    ZoneLister
+	bookings repository.BookingInventoryReader
+	store    repository.ZoneInventoryStore
+	cfg      *InventoryReconcilerConfig
+
+	mu      sync.Mutex
+	pending map[string]*pendingDrift // Unconfirmed Redis drift per zone
+	last    *InventoryReport
+}
+
+// pendingDrift tracks a Redis drift across consecutive runs
+type pendingDrift struct {
+	drift int64
+	runs  int
+}
+
+// NewInventoryReconciler creates a new inventory reconciler
+func NewInventoryReconciler(
+	zones ZoneLister,
+	bookings repository.BookingInventoryReader,
+	store repository.ZoneInventoryStore,
+	cfg *InventoryReconcilerConfig,
+) InventoryReconciler {
+	if cfg == nil {
+		cfg = &InventoryReconcilerConfig{}
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.MaxCorrection <= 0 {
+		cfg.MaxCorrection = 10
+	}
+	if cfg.ConfirmRuns <= 0 {
+		cfg.ConfirmRuns = 2
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = time.Minute
+	}
+
+	return &inventoryReconciler{
+		zones:    zones,
+		bookings: bookings,
+		store:    store,
+		cfg:      cfg,
+		pending:  make(map[string]*pendingDrift),
+	}
+}
+
+// Run reconciles every Interval until ctx is cancelled
+func (r *inventoryReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reconcile(ctx, false); err != nil && !errors.Is(err, ErrReconcileInProgress) && ctx.Err() == nil {
+				logger.Get().Error(fmt.Sprintf("Inventory reconciliation failed: %v", err))
+			}
+		}
+	}
+}
+
+// LastReport returns the most recent report, or nil before the first run
+func (r *inventoryReconciler) LastReport() *InventoryReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// Reconcile runs one pass over all active zones
+func (r *inventoryReconciler) Reconcile(ctx context.Context, dryRun bool) (*InventoryReport, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.inventory.reconcile")
+	defer span.End()
+
+	// Only one replica reconciles at a time, so corrections are never applied twice
+	token, err := r.store.AcquireReconcileLock(ctx, r.cfg.LockTTL)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if token == "" {
+		return nil, ErrReconcileInProgress
+	}
+	defer func() {
+		if err := r.store.ReleaseReconcileLock(context.WithoutCancel(ctx), token); err != nil {
+			logger.Get().Warn(err.Error())
+		}
+	}()
+
+	// Serializes runs within this replica and guards pending
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report, err := r.reconcile(ctx, dryRun)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("zones_checked", report.ZonesChecked),
+		attribute.Int("zones_drifted", report.ZonesDrifted),
+		attribute.Int("zones_corrected", report.ZonesCorrected),
+	)
+	r.last = report
+	return report, nil
+}
+
+func (r *inventoryReconciler) reconcile(ctx context.Context, dryRun bool) (*InventoryReport, error) {
+	report := &InventoryReport{StartedAt: time.Now(), DryRun: dryRun}
+
+	zones, err := r.zones.ListActiveZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+	zoneIDs := make([]string, 0, len(zones))
+	for _, z := range zones {
+		zoneIDs = append(zoneIDs, z.ID)
+	}
+
+	// Seats of shard transfers that stopped halfway are missing from every
+	// counter; put them back before they are read as drift
+	if !dryRun {
+		recovered, err := r.store.RecoverShardTransfers(ctx, zoneIDs)
+		if err != nil {
+			logger.Get().Warn(fmt.Sprintf("Inventory reconciliation: failed to recover shard transfers: %v", err))
+		} else if recovered > 0 {
+			logger.Get().Warn(fmt.Sprintf("Inventory reconciliation: recovered %d seats of interrupted shard transfers", recovered))
+		}
+	}
+
+	// Read Redis availability before the authoritative rows: a reservation
+	// landing in between then shows up as transient drift rather than being
+	// missed, and the confirmation runs filter it out
+	available, err := r.store.GetZoneAvailabilities(ctx, zoneIDs)
+	if err != nil {
+		return nil, err
+	}
+	redisHeld, err := r.store.ScanHeldSeats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbHeld, err := r.bookings.ListHeldSeats(ctx, zoneIDs)
+	if err != nil {
+		return nil, err
+	}
+	sold, err := r.bookings.SumSoldSeats(ctx, zoneIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A booking holds seats if either side still has it reserved: Redis holds
+	// seats before the booking row is written, and an expired reservation keeps
+	// its seats until the expiry worker releases them
+	held := make(map[string]int64, len(zones))
+	seen := make(map[string]bool, len(dbHeld)+len(redisHeld))
+	for _, h := range append(dbHeld, redisHeld...) {
+		if seen[h.BookingID] {
+			continue
+		}
+		seen[h.BookingID] = true
+		held[h.ZoneID] += h.Quantity
+	}
+
+	pending := make(map[string]*pendingDrift, len(zones))
+	for _, z := range zones {
+		zr := &ZoneReconciliation{
+			ZoneID:          z.ID,
+			ShowID:          z.ShowID,
+			Name:            z.Name,
+			TotalSeats:      z.TotalSeats,
+			AllocatedSeats:  z.HeldSeats + z.CompSeats,
+			SoldSeats:       sold[z.ID],
+			HeldSeats:       held[z.ID],
+			TicketAvailable: z.AvailableSeats,
+			Status:          ZoneStatusInSync,
+		}
+		zr.ExpectedAvailable = zr.TotalSeats - zr.AllocatedSeats - zr.SoldSeats - zr.HeldSeats
+		zr.TicketDrift = zr.TicketAvailable - zr.ExpectedAvailable
+		metrics.RecordInventoryDrift(ctx, z.ID, InventorySourceTicketDB, zr.TicketDrift)
+
+		seats, ok := available[z.ID]
+		switch {
+		case !ok:
+			zr.Status = ZoneStatusRedisMissing
+		default:
+			zr.RedisAvailable = &seats
+			zr.RedisDrift = seats - zr.ExpectedAvailable
+			metrics.RecordInventoryDrift(ctx, z.ID, InventorySourceRedis, zr.RedisDrift)
+			if zr.RedisDrift != 0 {
+				r.resolveDrift(ctx, zr, pending, dryRun)
+			}
+		}
+
+		if zr.RedisDrift != 0 || zr.TicketDrift != 0 {
+			report.ZonesDrifted++
+		}
+		if zr.Status == ZoneStatusCorrected {
+			report.ZonesCorrected++
+			report.SeatsCorrected += abs64(zr.Correction)
+		}
+		report.Zones = append(report.Zones, zr)
+	}
+	if !dryRun {
+		r.pending = pending
+	}
+
+	report.ZonesChecked = len(report.Zones)
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	if report.ZonesDrifted > 0 {
+		logger.Get().Warn(fmt.Sprintf("Inventory reconciliation: %d/%d zones drifted, %d corrected (%d seats)",
+			report.ZonesDrifted, report.ZonesChecked, report.ZonesCorrected, report.SeatsCorrected))
+	}
+	return report, nil
+}
+
+// resolveDrift decides whether a zone's Redis drift is corrected in this run
+// and records it in pending when it still needs confirmation
+func (r *inventoryReconciler) resolveDrift(ctx context.Context, zr *ZoneReconciliation, pending map[string]*pendingDrift, dryRun bool) {
+	zr.Status = ZoneStatusDrift
+	if abs64(zr.RedisDrift) > r.cfg.MaxCorrection {
+		zr.Status = ZoneStatusOutOfBounds
+		return
+	}
+
+	p := &pendingDrift{drift: zr.RedisDrift, runs: 1}
+	if prev, ok := r.pending[zr.ZoneID]; ok && prev.drift == zr.RedisDrift {
+		p.runs = prev.runs + 1
+	}
+	if dryRun || !r.cfg.AutoCorrect || p.runs < r.cfg.ConfirmRuns {
+		pending[zr.ZoneID] = p
+		return
+	}
+
+	// Relative adjustment, so reservations made since the read are preserved
+	seats, err := r.store.AdjustZoneAvailability(ctx, zr.ZoneID, -zr.RedisDrift)
+	if err != nil {
+		logger.Get().Error(fmt.Sprintf("Failed to correct zone %s availability: %v", zr.ZoneID, err))
+		pending[zr.ZoneID] = p
+		return
+	}
+
+	zr.Correction = -zr.RedisDrift
+	zr.Status = ZoneStatusCorrected
+	metrics.RecordInventoryCorrection(ctx, zr.ZoneID, InventorySourceRedis, zr.Correction)
+	logger.Get().Warn(fmt.Sprintf("Corrected zone %s Redis availability by %d seats (now %d)",
+		zr.ZoneID, zr.Correction, seats))
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+)
+
+// mockZoneLister returns a fixed zone catalog
+type mockZoneLister struct {
+	zones []*ZoneInfo
+}
+
+func (m *mockZoneLister) ListActiveZones(ctx context.Context) ([]*ZoneInfo, error) {
+	return m.zones, nil
+}
+
+// mockInventoryReader is an in-memory BookingInventoryReader
+type mockInventoryReader struct {
+	held []repository.HeldSeats
+	sold map[string]int64
+}
+
+func (m *mockInventoryReader) ListHeldSeats(ctx context.Context, zoneIDs []string) ([]repository.HeldSeats, error) {
+	return m.held, nil
+}
+
+func (m *mockInventoryReader) SumSoldSeats(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	return m.sold, nil
+}
+
+// mockInventoryStore is an in-memory ZoneInventoryStore
+type mockInventoryStore struct {
+	held      []repository.HeldSeats
+	available map[string]int64
+	locked    bool
+}
+
+func (m *mockInventoryStore) ScanHeldSeats(ctx context.Context) ([]repository.HeldSeats, error) {
+	return m.held, nil
+}
+
+func (m *mockInventoryStore) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	for _, id := range zoneIDs {
+		if v, ok := m.available[id]; ok {
+			result[id] = v
+		}
+	}
+	return result, nil
+}
+
+func (m *mockInventoryStore) AdjustZoneAvailability(ctx context.Context, zoneID string, delta int64) (int64, error) {
+	m.available[zoneID] += delta
+	return m.available[zoneID], nil
+}
+
+func (m *mockInventoryStore) RecoverShardTransfers(ctx context.Context, zoneIDs []string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockInventoryStore) AcquireReconcileLock(ctx context.Context, ttl time.Duration) (string, error) {
+	if m.locked {
+		return "", nil
+	}
+	m.locked = true
+	return "token", nil
+}
+
+func (m *mockInventoryStore) ReleaseReconcileLock(ctx context.Context, token string) error {
+	m.locked = false
+	return nil
+}
+
+// newReconcilerFixture sets up one zone of 100 seats with 10 sold, a booking
+// held on both sides, one held only in Redis and one held only in the DB
+func newReconcilerFixture(redisAvailable int64, cfg *InventoryReconcilerConfig) (InventoryReconciler, *mockInventoryStore) {
+	zones := &mockZoneLister{zones: []*ZoneInfo{
+		{ID: "zone-1", ShowID: "show-1", Name: "A", TotalSeats: 100, AvailableSeats: 84},
+	}}
+	reader := &mockInventoryReader{
+		held: []repository.HeldSeats{
+			{BookingID: "b-1", ZoneID: "zone-1", Quantity: 2},
+			{BookingID: "b-3", ZoneID: "zone-1", Quantity: 1},
+		},
+		sold: map[string]int64{"zone-1": 10},
+	}
+	store := &mockInventoryStore{
+		held: []repository.HeldSeats{
+			{BookingID: "b-1", ZoneID: "zone-1", Quantity: 2},
+			{BookingID: "b-2", ZoneID: "zone-1", Quantity: 3},
+		},
+		available: map[string]int64{"zone-1": redisAvailable},
+	}
+	return NewInventoryReconciler(zones, reader, store, cfg), store
+}
+
+func TestInventoryReconciler_InSync(t *testing.T) {
+	r, _ := newReconcilerFixture(84, &InventoryReconcilerConfig{AutoCorrect: true})
+
+	report, err := r.Reconcile(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	z := report.Zones[0]
+	if z.HeldSeats != 6 || z.SoldSeats != 10 || z.ExpectedAvailable != 84 {
+		t.Errorf("held/sold/expected = %d/%d/%d, want 6/10/84", z.HeldSeats, z.SoldSeats, z.ExpectedAvailable)
+	}
+	if z.Status != ZoneStatusInSync || report.ZonesDrifted != 0 {
+		t.Errorf("status = %s, drifted = %d, want in_sync and 0", z.Status, report.ZonesDrifted)
+	}
+	if r.LastReport() != report {
+		t.Error("LastReport() did not return the latest report")
+	}
+}
+
+func TestInventoryReconciler_ExcludesAllocations(t *testing.T) {
+	// 15 seats held back and 5 set aside for comps are not on sale
+	zones := &mockZoneLister{zones: []*ZoneInfo{
+		{ID: "zone-1", ShowID: "show-1", Name: "A", TotalSeats: 100, AvailableSeats: 70, HeldSeats: 15, CompSeats: 5},
+	}}
+	reader := &mockInventoryReader{sold: map[string]int64{"zone-1": 10}}
+	store := &mockInventoryStore{
+		held:      []repository.HeldSeats{{BookingID: "b-1", ZoneID: "zone-1", Quantity: 6}},
+		available: map[string]int64{"zone-1": 64},
+	}
+	r := NewInventoryReconciler(zones, reader, store, &InventoryReconcilerConfig{AutoCorrect: true})
+
+	report, err := r.Reconcile(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	z := report.Zones[0]
+	if z.AllocatedSeats != 20 || z.ExpectedAvailable != 64 || z.Status != ZoneStatusInSync {
+		t.Errorf("allocated/expected/status = %d/%d/%s, want 20/64/in_sync", z.AllocatedSeats, z.ExpectedAvailable, z.Status)
+	}
+}
+
+func TestInventoryReconciler_CorrectsConfirmedDrift(t *testing.T) {
+	r, store := newReconcilerFixture(87, &InventoryReconcilerConfig{AutoCorrect: true, ConfirmRuns: 2})
+	ctx := context.Background()
+
+	report, err := r.Reconcile(ctx, false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if z := report.Zones[0]; z.Status != ZoneStatusDrift || z.RedisDrift != 3 {
+		t.Fatalf("first run status = %s, drift = %d, want drift and 3", z.Status, z.RedisDrift)
+	}
+	if store.available["zone-1"] != 87 {
+		t.Fatal("first run corrected drift before it was confirmed")
+	}
+
+	report, err = r.Reconcile(ctx, false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	z := report.Zones[0]
+	if z.Status != ZoneStatusCorrected || z.Correction != -3 || report.SeatsCorrected != 3 {
+		t.Errorf("second run status = %s, correction = %d, seats = %d", z.Status, z.Correction, report.SeatsCorrected)
+	}
+	if store.available["zone-1"] != 84 {
+		t.Errorf("redis availability = %d, want 84", store.available["zone-1"])
+	}
+}
+
+func TestInventoryReconciler_ChangingDriftIsNotCorrected(t *testing.T) {
+	r, store := newReconcilerFixture(87, &InventoryReconcilerConfig{AutoCorrect: true, ConfirmRuns: 2})
+	ctx := context.Background()
+
+	_, _ = r.Reconcile(ctx, false)
+	store.available["zone-1"] = 86 // In-flight operation moved the counter
+	report, _ := r.Reconcile(ctx, false)
+
+	if z := report.Zones[0]; z.Status != ZoneStatusDrift {
+		t.Errorf("status = %s, want drift", z.Status)
+	}
+	if store.available["zone-1"] != 86 {
+		t.Errorf("redis availability = %d, want 86 (uncorrected)", store.available["zone-1"])
+	}
+}
+
+func TestInventoryReconciler_BoundsAndDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	r, store := newReconcilerFixture(120, &InventoryReconcilerConfig{AutoCorrect: true, ConfirmRuns: 1, MaxCorrection: 10})
+	report, _ := r.Reconcile(ctx, false)
+	if z := report.Zones[0]; z.Status != ZoneStatusOutOfBounds || store.available["zone-1"] != 120 {
+		t.Errorf("status = %s, availability = %d, want out_of_bounds and untouched", z.Status, store.available["zone-1"])
+	}
+
+	r, store = newReconcilerFixture(80, &InventoryReconcilerConfig{AutoCorrect: true, ConfirmRuns: 1})
+	report, _ = r.Reconcile(ctx, true)
+	if z := report.Zones[0]; z.Status != ZoneStatusDrift || z.RedisDrift != -4 || store.available["zone-1"] != 80 {
+		t.Errorf("dry run status = %s, drift = %d, availability = %d", z.Status, z.RedisDrift, store.available["zone-1"])
+	}
+}
+
+func TestInventoryReconciler_ReportsTicketDriftAndMissingZone(t *testing.T) {
+	r, store := newReconcilerFixture(84, nil)
+	delete(store.available, "zone-1")
+
+	report, _ := r.Reconcile(context.Background(), false)
+	z := report.Zones[0]
+	if z.Status != ZoneStatusRedisMissing || z.RedisAvailable != nil {
+		t.Errorf("status = %s, want redis_missing", z.Status)
+	}
+	if z.TicketDrift != 0 {
+		t.Errorf("ticket drift = %d, want 0", z.TicketDrift)
+	}
+}
+
+func TestInventoryReconciler_LockHeldElsewhere(t *testing.T) {
+	r, store := newReconcilerFixture(84, nil)
+	store.locked = true
+
+	if _, err := r.Reconcile(context.Background(), false); !errors.Is(err, ErrReconcileInProgress) {
+		t.Errorf("Reconcile() error = %v, want ErrReconcileInProgress", err)
+	}
+}
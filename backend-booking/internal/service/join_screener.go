@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Decisions on a screened queue join
+const (
+	JoinDecisionAllow  = "allow"  // Queue as usual
+	JoinDecisionPark   = "park"   // Queue behind everyone who was allowed
+	JoinDecisionReject = "reject" // Turn away
+)
+
+// Sources join velocity is counted per
+const (
+	joinSourceIP  = "ip"
+	joinSourceASN = "asn"
+)
+
+// JoinAttempt is a queue join as bot screening sees it
+type JoinAttempt struct {
+	EventID string
+	UserID  string
+	Signals dto.JoinSignals
+}
+
+// JoinCheckResult is what one check makes of a join
+type JoinCheckResult struct {
+	Score  int    // How suspicious the join looks (0 = not at all)
+	Reason string // Why, for post-sale review
+}
+
+// JoinCheck is a stage of queue join screening. Checks score joins; the
+// screener adds their scores up and decides.
+type JoinCheck interface {
+	// Name names the check in verdicts and audit entries
+	Name() string
+
+	// Check scores a join
+	Check(ctx context.Context, join *JoinAttempt) (*JoinCheckResult, error)
+}
+
+// JoinVerdict is the screener's decision on a join
+type JoinVerdict struct {
+	Decision string
+	Score    int
+	// Reasons maps the checks that scored the join to their reasons
+	Reasons map[string]string
+}
+
+// JoinAuditor records screening decisions; satisfied by *middleware.AuditLogger
+type JoinAuditor interface {
+	Log(entry *middleware.AuditEntry)
+}
+
+// JoinScreener screens queue joins for bots before they are queued
+type JoinScreener interface {
+	// Screen runs every check on a join and decides whether it is let in,
+	// parked at the back of the queue or rejected
+	Screen(ctx context.Context, join *JoinAttempt) *JoinVerdict
+}
+
+// JoinScreenerConfig contains configuration for the join screener
+type JoinScreenerConfig struct {
+	// ParkScore parks joins scoring at least this much (0 = never park)
+	ParkScore int
+	// RejectScore rejects joins scoring at least this much (0 = never reject)
+	RejectScore int
+}
+
+// joinScreener implements JoinScreener
+type joinScreener struct {
+	checks      []JoinCheck
+	auditor     JoinAuditor
+	parkScore   int
+	rejectScore int
+}
+
+// NewJoinScreener creates a new join screener running checks in order.
+// auditor is optional: without it decisions are only counted in metrics.
+func NewJoinScreener(checks []JoinCheck, auditor JoinAuditor, cfg *JoinScreenerConfig) JoinScreener {
+	s := &joinScreener{
+		checks:      checks,
+		auditor:     auditor,
+		parkScore:   50,
+		rejectScore: 100,
+	}
+	if cfg != nil {
+		s.parkScore = cfg.ParkScore
+		s.rejectScore = cfg.RejectScore
+	}
+	return s
+}
+
+// Screen scores a join with every check. A check that fails is skipped:
+// screening must never keep real fans out of the queue.
+func (s *joinScreener) Screen(ctx context.Context, join *JoinAttempt) *JoinVerdict {
+	ctx, span := telemetry.StartSpan(ctx, "service.join_screener.screen")
+	defer span.End()
+
+	verdict := &JoinVerdict{Decision: JoinDecisionAllow}
+	for _, check := range s.checks {
+		result, err := check.Check(ctx, join)
+		if err != nil {
+			span.RecordError(err)
+			logger.Get().Warn(fmt.Sprintf("Join screening: check %s failed for user %s: %v", check.Name(), join.UserID, err))
+			continue
+		}
+		if result == nil || result.Score <= 0 {
+			continue
+		}
+		verdict.Score += result.Score
+		if verdict.Reasons == nil {
+			verdict.Reasons = make(map[string]string)
+		}
+		verdict.Reasons[check.Name()] = result.Reason
+	}
+
+	switch {
+	case s.rejectScore > 0 && verdict.Score >= s.rejectScore:
+		verdict.Decision = JoinDecisionReject
+	case s.parkScore > 0 && verdict.Score >= s.parkScore:
+		verdict.Decision = JoinDecisionPark
+	}
+
+	span.SetAttributes(
+		attribute.String("event_id", join.EventID),
+		attribute.String("decision", verdict.Decision),
+		attribute.Int("score", verdict.Score),
+	)
+	span.SetStatus(codes.Ok, "")
+	metrics.RecordQueueJoinScreened(ctx, join.EventID, verdict.Decision)
+	if verdict.Score > 0 {
+		s.audit(join, verdict)
+	}
+	return verdict
+}
+
+// audit records a join some check scored, whatever the decision, so that
+// thresholds can be reviewed after the sale
+func (s *joinScreener) audit(join *JoinAttempt, verdict *JoinVerdict) {
+	if s.auditor == nil {
+		return
+	}
+
+	s.auditor.Log(&middleware.AuditEntry{
+		ID:           uuid.New().String(),
+		UserID:       optionalString(join.UserID),
+		Action:       middleware.AuditActionCreate,
+		ResourceType: "queue_join",
+		ResourceID:   optionalString(join.EventID),
+		IPAddress:    join.Signals.IP,
+		UserAgent:    join.Signals.UserAgent,
+		RequestID:    join.Signals.RequestID,
+		Metadata: map[string]interface{}{
+			"event_id":  join.EventID,
+			"decision":  verdict.Decision,
+			"score":     verdict.Score,
+			"reasons":   verdict.Reasons,
+			"asn":       join.Signals.ASN,
+			"challenge": join.Signals.Challenge,
+		},
+		CreatedAt: time.Now(),
+	})
+}
+
+// challengeCheck scores joins on the gateway's proof-of-work verdict
+type challengeCheck struct {
+	missingScore int
+	failedScore  int
+}
+
+// NewChallengeCheck creates a check scoring joins that came without a
+// solved challenge or with a wrong one. Joins the gateway did not
+// challenge at all are not scored.
+func NewChallengeCheck(missingScore, failedScore int) JoinCheck {
+	return &challengeCheck{missingScore: missingScore, failedScore: failedScore}
+}
+
+// Name names the check
+func (c *challengeCheck) Name() string {
+	return "challenge"
+}
+
+// Check scores the join's challenge result
+func (c *challengeCheck) Check(ctx context.Context, join *JoinAttempt) (*JoinCheckResult, error) {
+	switch join.Signals.Challenge {
+	case dto.JoinChallengeFailed:
+		return &JoinCheckResult{Score: c.failedScore, Reason: "challenge failed or reused"}, nil
+	case dto.JoinChallengeMissing:
+		return &JoinCheckResult{Score: c.missingScore, Reason: "no challenge solved"}, nil
+	}
+	return &JoinCheckResult{}, nil
+}
+
+// JoinVelocityConfig contains configuration for the join velocity check
+type JoinVelocityConfig struct {
+	PerIP  int           // Joins per IP per window before scoring (0 = unlimited)
+	PerASN int           // Joins per network per window before scoring (0 = unlimited)
+	Window time.Duration // Default: 1 minute
+	Score  int
+	// Secret keys the fingerprints IPs and networks are counted under
+	Secret string
+}
+
+// velocityCheck scores joins from IPs and networks joining too fast
+type velocityCheck struct {
+	store  repository.JoinScreenStore
+	perIP  int
+	perASN int
+	window time.Duration
+	score  int
+	secret []byte
+}
+
+// NewJoinVelocityCheck creates a check scoring joins from an IP or network
+// (ASN) that has joined an event's queue more often than allowed in the window
+func NewJoinVelocityCheck(store repository.JoinScreenStore, cfg *JoinVelocityConfig) JoinCheck {
+	c := &velocityCheck{store: store, window: time.Minute}
+	if cfg != nil {
+		c.perIP = cfg.PerIP
+		c.perASN = cfg.PerASN
+		if cfg.Window > 0 {
+			c.window = cfg.Window
+		}
+		c.score = cfg.Score
+		c.secret = []byte(cfg.Secret)
+	}
+	return c
+}
+
+// Name names the check
+func (c *velocityCheck) Name() string {
+	return "velocity"
+}
+
+// Check counts the join against its IP and network
+func (c *velocityCheck) Check(ctx context.Context, join *JoinAttempt) (*JoinCheckResult, error) {
+	var reasons []string
+	sources := []struct {
+		kind  string
+		value string
+		limit int
+	}{
+		{joinSourceIP, joinIP(join.Signals.IP), c.perIP},
+		{joinSourceASN, strings.TrimSpace(join.Signals.ASN), c.perASN},
+	}
+	for _, source := range sources {
+		if source.value == "" || source.limit <= 0 {
+			continue
+		}
+		count, err := c.store.CountJoin(ctx, join.EventID, source.kind, screenFingerprint(c.secret, source.kind, source.value), c.window)
+		if err != nil {
+			return nil, err
+		}
+		if count > int64(source.limit) {
+			reasons = append(reasons, fmt.Sprintf("%d joins from %s in %v", count, source.kind, c.window))
+		}
+	}
+	if len(reasons) == 0 {
+		return &JoinCheckResult{}, nil
+	}
+	return &JoinCheckResult{Score: c.score, Reason: strings.Join(reasons, "; ")}, nil
+}
+
+// JoinDeviceConfig contains configuration for the duplicate device check
+type JoinDeviceConfig struct {
+	MaxUsers int           // Users one device may join an event's queue with
+	TTL      time.Duration // How long a device's users are remembered (default: 24 hours)
+	Score    int
+	// Secret keys the fingerprints devices are stored under
+	Secret string
+}
+
+// deviceCheck scores joins from devices many accounts have joined from
+type deviceCheck struct {
+	store    repository.JoinScreenStore
+	maxUsers int
+	ttl      time.Duration
+	score    int
+	secret   []byte
+}
+
+// NewJoinDeviceCheck creates a check scoring joins from a device that more
+// than MaxUsers accounts have joined an event's queue from
+func NewJoinDeviceCheck(store repository.JoinScreenStore, cfg *JoinDeviceConfig) JoinCheck {
+	c := &deviceCheck{store: store, ttl: 24 * time.Hour}
+	if cfg != nil {
+		c.maxUsers = cfg.MaxUsers
+		if cfg.TTL > 0 {
+			c.ttl = cfg.TTL
+		}
+		c.score = cfg.Score
+		c.secret = []byte(cfg.Secret)
+	}
+	return c
+}
+
+// Name names the check
+func (c *deviceCheck) Name() string {
+	return "device"
+}
+
+// Check records the join's user against its device
+func (c *deviceCheck) Check(ctx context.Context, join *JoinAttempt) (*JoinCheckResult, error) {
+	device := strings.TrimSpace(join.Signals.DeviceID)
+	if device == "" || c.maxUsers <= 0 {
+		return &JoinCheckResult{}, nil
+	}
+	users, err := c.store.AddDeviceUser(ctx, join.EventID, screenFingerprint(c.secret, "device", device), join.UserID, c.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if users <= int64(c.maxUsers) {
+		return &JoinCheckResult{}, nil
+	}
+	return &JoinCheckResult{Score: c.score, Reason: fmt.Sprintf("device used by %d accounts", users)}, nil
+}
+
+// joinIP returns the IP a join's velocity is counted under; loopback and
+// unparseable addresses are not counted
+func joinIP(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil || parsed.IsLoopback() {
+		return ""
+	}
+	return parsed.String()
+}
+
+// screenFingerprint keys a signal so that raw addresses and device IDs are
+// never stored in Redis
+func screenFingerprint(secret []byte, kind, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
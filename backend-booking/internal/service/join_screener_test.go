@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+// staticJoinCheck gives every join the same score, or fails
+type staticJoinCheck struct {
+	name  string
+	score int
+	err   error
+}
+
+func (c *staticJoinCheck) Name() string {
+	return c.name
+}
+
+func (c *staticJoinCheck) Check(ctx context.Context, join *JoinAttempt) (*JoinCheckResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &JoinCheckResult{Score: c.score, Reason: c.name + " scored"}, nil
+}
+
+func TestJoinScreener_Screen(t *testing.T) {
+	tests := []struct {
+		name         string
+		checks       []JoinCheck
+		wantDecision string
+		wantScore    int
+		wantAudited  bool
+	}{
+		{"no signal", []JoinCheck{&staticJoinCheck{name: "a"}}, JoinDecisionAllow, 0, false},
+		{"below parking", []JoinCheck{&staticJoinCheck{name: "a", score: 30}}, JoinDecisionAllow, 30, true},
+		{"scores add up", []JoinCheck{&staticJoinCheck{name: "a", score: 30}, &staticJoinCheck{name: "b", score: 30}}, JoinDecisionPark, 60, true},
+		{"rejected", []JoinCheck{&staticJoinCheck{name: "a", score: 60}, &staticJoinCheck{name: "b", score: 60}}, JoinDecisionReject, 120, true},
+		{"failing check is skipped", []JoinCheck{&staticJoinCheck{name: "a", err: errors.New("redis down")}, &staticJoinCheck{name: "b", score: 50}}, JoinDecisionPark, 50, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditor := &recordingAuditor{}
+			screener := NewJoinScreener(tt.checks, auditor, &JoinScreenerConfig{ParkScore: 50, RejectScore: 100})
+
+			verdict := screener.Screen(context.Background(), &JoinAttempt{EventID: "event-1", UserID: "user-1"})
+			if verdict.Decision != tt.wantDecision || verdict.Score != tt.wantScore {
+				t.Fatalf("Screen() = %s at %d, want %s at %d", verdict.Decision, verdict.Score, tt.wantDecision, tt.wantScore)
+			}
+			if audited := len(auditor.entries) == 1; audited != tt.wantAudited {
+				t.Fatalf("audited = %v, want %v", audited, tt.wantAudited)
+			}
+			if tt.wantAudited && auditor.entries[0].Metadata["decision"] != tt.wantDecision {
+				t.Errorf("audit entry = %+v, want decision %s", auditor.entries[0].Metadata, tt.wantDecision)
+			}
+		})
+	}
+}
+
+func TestJoinChecks(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	store := repository.NewRedisQueueRepository(client)
+	ctx := context.Background()
+
+	join := func(userID string, signals dto.JoinSignals) *JoinAttempt {
+		return &JoinAttempt{EventID: "event-1", UserID: userID, Signals: signals}
+	}
+
+	t.Run("challenge", func(t *testing.T) {
+		check := NewChallengeCheck(10, 60)
+		for challenge, want := range map[string]int{
+			dto.JoinChallengePassed:  0,
+			dto.JoinChallengeMissing: 10,
+			dto.JoinChallengeFailed:  60,
+			"":                       0, // The gateway does not challenge joins
+		} {
+			result, err := check.Check(ctx, join("user-1", dto.JoinSignals{Challenge: challenge}))
+			if err != nil || result.Score != want {
+				t.Errorf("Check(%q) = %+v, %v, want score %d", challenge, result, err, want)
+			}
+		}
+	})
+
+	t.Run("velocity", func(t *testing.T) {
+		check := NewJoinVelocityCheck(store, &JoinVelocityConfig{PerIP: 2, PerASN: 3, Score: 50, Secret: "test-secret"})
+		scores := make([]int, 0, 4)
+		for i, ip := range []string{"203.0.113.7", "203.0.113.7", "198.51.100.1", "203.0.113.7"} {
+			result, err := check.Check(ctx, join("user-"+ip, dto.JoinSignals{IP: ip, ASN: "AS64500"}))
+			if err != nil {
+				t.Fatalf("Check(%d) error = %v", i, err)
+			}
+			scores = append(scores, result.Score)
+		}
+		// The ASN goes over on the 4th join, which is also the IP's 3rd
+		if scores[0] != 0 || scores[1] != 0 || scores[2] != 0 || scores[3] != 50 {
+			t.Errorf("scores = %v, want only the 4th join scored", scores)
+		}
+
+		result, err := check.Check(ctx, join("user-local", dto.JoinSignals{IP: "127.0.0.1"}))
+		if err != nil || result.Score != 0 {
+			t.Errorf("Check(loopback) = %+v, %v, want no score", result, err)
+		}
+	})
+
+	t.Run("duplicate device", func(t *testing.T) {
+		check := NewJoinDeviceCheck(store, &JoinDeviceConfig{MaxUsers: 2, Score: 40, Secret: "test-secret"})
+		signals := dto.JoinSignals{DeviceID: "device-1"}
+		for _, userID := range []string{"user-1", "user-2", "user-1"} {
+			if result, err := check.Check(ctx, join(userID, signals)); err != nil || result.Score != 0 {
+				t.Fatalf("Check(%s) = %+v, %v, want no score", userID, result, err)
+			}
+		}
+		result, err := check.Check(ctx, join("user-3", signals))
+		if err != nil || result.Score != 40 {
+			t.Errorf("Check(user-3) = %+v, %v, want score 40", result, err)
+		}
+	})
+}
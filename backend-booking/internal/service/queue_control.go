@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// PauseQueue pauses an event's queue. The queue release worker honors it on
+// its next tick; users keep joining behind those already waiting.
+func (s *queueService) PauseQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.queue.pause")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	// A drained queue is resumed first, so pausing cannot reopen it to joins
+	current, err := s.queueRepo.GetQueueControl(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if current.IsDrained() {
+		span.SetStatus(codes.Error, "queue drained")
+		return nil, domain.ErrQueueDrained
+	}
+
+	control, err := s.setQueueControl(ctx, eventID, domain.QueueStatePaused, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return queueControlResponse(eventID, control), nil
+}
+
+// ResumeQueue makes an event's queue active again
+func (s *queueService) ResumeQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.queue.resume")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	control, err := s.setQueueControl(ctx, eventID, domain.QueueStateActive, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return queueControlResponse(eventID, control), nil
+}
+
+// DrainQueue closes an event's queue before emptying it, so that no join
+// lands after the drain. Joins a replica let in before its cached state
+// caught up are drained by the queue release worker.
+func (s *queueService) DrainQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.queue.drain")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	control, err := s.setQueueControl(ctx, eventID, domain.QueueStateDrained, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	drained, err := s.queueRepo.DrainQueue(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("drained", drained))
+	span.SetStatus(codes.Ok, "")
+	response := queueControlResponse(eventID, control)
+	response.Drained = drained
+	return response, nil
+}
+
+// ExpireQueuePasses deletes an event's outstanding queue passes. Their
+// holders can no longer reserve, and the booking slots they held are
+// released to the queue on the worker's next tick.
+func (s *queueService) ExpireQueuePasses(ctx context.Context, eventID string) (*dto.ExpireQueuePassesResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.queue.expire_passes")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	if eventID == "" {
+		span.SetStatus(codes.Error, "invalid event_id")
+		return nil, domain.ErrInvalidEventID
+	}
+
+	expired, err := s.queueRepo.ExpireQueuePasses(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("expired", expired))
+	span.SetStatus(codes.Ok, "")
+	return &dto.ExpireQueuePassesResponse{EventID: eventID, Expired: expired}, nil
+}
+
+// setQueueControl puts an event's queue in a state and drops this replica's
+// cached copy, as SetQueueConfig does
+func (s *queueService) setQueueControl(ctx context.Context, eventID, state string, req *dto.QueueControlRequest) (*repository.QueueControl, error) {
+	if eventID == "" {
+		return nil, domain.ErrInvalidEventID
+	}
+
+	control := &repository.QueueControl{State: state, UpdatedAt: time.Now()}
+	if req != nil {
+		control.Message = req.Message
+		control.UpdatedBy = req.UpdatedBy
+	}
+	if err := s.queueRepo.SetQueueControl(ctx, eventID, control); err != nil {
+		return nil, err
+	}
+
+	s.configMu.Lock()
+	delete(s.configCache, eventID)
+	s.configMu.Unlock()
+	return control, nil
+}
+
+// queueControl returns the cached state of an event's queue (nil = active)
+func (s *queueService) queueControl(ctx context.Context, eventID string) (*repository.QueueControl, error) {
+	cached, err := s.cachedEventQueueConfig(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return cached.control, nil
+}
+
+// queueControlResponse converts the state of an event's queue to its response
+func queueControlResponse(eventID string, control *repository.QueueControl) *dto.QueueControlResponse {
+	return &dto.QueueControlResponse{
+		EventID:   eventID,
+		State:     control.State,
+		Message:   control.Message,
+		UpdatedBy: control.UpdatedBy,
+		UpdatedAt: control.UpdatedAt.UTC(),
+	}
+}
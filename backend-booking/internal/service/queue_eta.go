@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+)
+
+// throughputCacheTTL is how long an event's observed throughput is reused;
+// every waiting user polls their position, so it must not cost a read each
+const throughputCacheTTL = 5 * time.Second
+
+// Where a wait estimate comes from
+const (
+	etaBasisReleases   = "releases"    // Users the queue released recently
+	etaBasisPassesUsed = "passes_used" // Queue passes spent while releases stalled
+	etaBasisDefault    = "default"     // No throughput observed; fixed seconds per user
+)
+
+// queueRate is an event's observed queue throughput in users per second: the
+// typical rate and the slow and fast ends of its spread
+type queueRate struct {
+	typical, slow, fast float64
+	basis               string
+}
+
+// waitEstimate is the expected wait of a queue position with its range
+type waitEstimate struct {
+	seconds, min, max int64
+	basis             string
+}
+
+// waitRange returns the estimate's range for a response
+func (w waitEstimate) waitRange() *dto.WaitRange {
+	return &dto.WaitRange{MinSeconds: w.min, MaxSeconds: w.max}
+}
+
+// cachedThroughput is an event's throughput cached until expiresAt
+type cachedThroughput struct {
+	rate      *queueRate
+	expiresAt time.Time
+}
+
+// throughputKey identifies the throughput of an event's queue, or of one
+// of its lanes
+type throughputKey struct {
+	eventID, lane string
+}
+
+// estimateWait estimates how long the user at position of a lane waits
+// (lane "" = the whole queue). Without observed throughput it falls back to
+// the configured seconds per user.
+func (s *queueService) estimateWait(ctx context.Context, eventID, lane string, position int64) waitEstimate {
+	rate := s.queueRate(ctx, eventID, lane)
+	if rate == nil {
+		wait := position * s.estimatedWaitPerUser
+		return waitEstimate{seconds: wait, min: wait, max: wait, basis: etaBasisDefault}
+	}
+	return waitEstimate{
+		seconds: secondsAt(position, rate.typical),
+		min:     secondsAt(position, rate.fast),
+		max:     secondsAt(position, rate.slow),
+		basis:   rate.basis,
+	}
+}
+
+// queueRate returns the observed throughput of an event's queue or lane,
+// cached for throughputCacheTTL; nil when nothing has been observed
+func (s *queueService) queueRate(ctx context.Context, eventID, lane string) *queueRate {
+	if s.admissionHealth == nil {
+		return nil
+	}
+
+	key := throughputKey{eventID: eventID, lane: lane}
+	s.throughputMu.Lock()
+	cached, ok := s.throughputCache[key]
+	s.throughputMu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.rate
+	}
+
+	var rate *queueRate
+	throughput, err := s.admissionHealth.GetQueueThroughput(ctx, eventID, lane, s.etaWindow)
+	if err == nil {
+		rate = observedRate(throughput)
+	}
+
+	s.throughputMu.Lock()
+	s.throughputCache[key] = &cachedThroughput{rate: rate, expiresAt: time.Now().Add(throughputCacheTTL)}
+	s.throughputMu.Unlock()
+	return rate
+}
+
+// observedRate derives a queue's rate from its per-bucket throughput. Releases
+// are what moves the queue; while there were none (the worker paused, or
+// every booking slot is held) the rate passes are spent at is what frees
+// slots. The typical rate is the median bucket, so the burst that fills
+// every slot when a queue opens does not count as its pace; the spread is
+// the interquartile range.
+func observedRate(throughput *repository.QueueThroughput) *queueRate {
+	counts, basis := throughput.Released, etaBasisReleases
+	if sumCounts(counts) == 0 {
+		counts, basis = throughput.PassesUsed, etaBasisPassesUsed
+	}
+	total := sumCounts(counts)
+	if total == 0 || throughput.Bucket <= 0 {
+		return nil
+	}
+
+	bucketSeconds := throughput.Bucket.Seconds()
+	rates := make([]float64, len(counts))
+	for i, count := range counts {
+		rates[i] = float64(count) / bucketSeconds
+	}
+	sort.Float64s(rates)
+
+	// Sparse throughput has a median of 0; its mean is the better guess
+	mean := float64(total) / (bucketSeconds * float64(len(counts)))
+	typical := percentile(rates, 0.5)
+	if typical == 0 {
+		typical = mean
+	}
+	return &queueRate{
+		typical: typical,
+		slow:    math.Min(math.Max(percentile(rates, 0.25), mean/2), typical),
+		fast:    math.Max(percentile(rates, 0.75), typical),
+		basis:   basis,
+	}
+}
+
+// secondsAt returns the seconds until position is reached at rate users per second
+func secondsAt(position int64, rate float64) int64 {
+	if position <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(float64(position) / rate))
+}
+
+// percentile returns the p-th percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	return sorted[int(p*float64(len(sorted)-1)+0.5)]
+}
+
+// sumCounts returns the sum of counts
+func sumCounts(counts []int64) int64 {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
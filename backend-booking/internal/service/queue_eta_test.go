@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestObservedRate(t *testing.T) {
+	bucket := 10 * time.Second
+
+	t.Run("the opening burst does not set the pace", func(t *testing.T) {
+		rate := observedRate(&repository.QueueThroughput{
+			Bucket:   bucket,
+			Released: []int64{20, 20, 30, 10, 20, 500},
+		})
+		assert.Equal(t, etaBasisReleases, rate.basis)
+		assert.Equal(t, 2.0, rate.typical)
+		assert.Equal(t, 2.0, rate.slow)
+		assert.Equal(t, 3.0, rate.fast)
+	})
+
+	t.Run("sparse releases use the mean", func(t *testing.T) {
+		rate := observedRate(&repository.QueueThroughput{
+			Bucket:   bucket,
+			Released: []int64{0, 0, 0, 10, 0, 0, 0, 10, 0, 0},
+		})
+		assert.InDelta(t, 0.2, rate.typical, 1e-9)
+		assert.InDelta(t, 0.1, rate.slow, 1e-9)
+		assert.InDelta(t, 0.2, rate.fast, 1e-9)
+	})
+
+	t.Run("spent passes pace a queue that released nobody", func(t *testing.T) {
+		rate := observedRate(&repository.QueueThroughput{
+			Bucket:     bucket,
+			Released:   []int64{0, 0, 0},
+			PassesUsed: []int64{5, 5, 5},
+		})
+		assert.Equal(t, etaBasisPassesUsed, rate.basis)
+		assert.Equal(t, 0.5, rate.typical)
+	})
+
+	t.Run("nothing observed", func(t *testing.T) {
+		assert.Nil(t, observedRate(&repository.QueueThroughput{
+			Bucket:     bucket,
+			Released:   []int64{0, 0},
+			PassesUsed: []int64{0, 0},
+		}))
+	})
+}
+
+// stubThroughput serves fixed queue throughput and counts spent passes
+type stubThroughput struct {
+	repository.AdmissionHealthRepository
+	throughput map[string]*repository.QueueThroughput
+	reads      int
+	passesUsed int
+}
+
+func (s *stubThroughput) GetQueueThroughput(ctx context.Context, eventID, lane string, window time.Duration) (*repository.QueueThroughput, error) {
+	s.reads++
+	if throughput, ok := s.throughput[eventID]; ok {
+		return throughput, nil
+	}
+	return &repository.QueueThroughput{Bucket: 10 * time.Second, Released: []int64{0}, PassesUsed: []int64{0}}, nil
+}
+
+func (s *stubThroughput) RecordPassUsed(ctx context.Context, eventID string) error {
+	s.passesUsed++
+	return nil
+}
+
+func TestQueueService_WaitEstimates(t *testing.T) {
+	health := &stubThroughput{throughput: map[string]*repository.QueueThroughput{
+		"event-123": {Bucket: 10 * time.Second, Released: []int64{20, 10, 20, 20, 30, 30}},
+	}}
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{
+		JWTSecret:            testJWTSecret,
+		EstimatedWaitPerUser: 3,
+		AdmissionHealth:      health,
+	})
+	ctx := context.Background()
+
+	for _, eventID := range []string{"event-fixed", "event-123"} {
+		mockRepo.On("GetEventQueueConfig", mock.Anything, eventID).Return(nil, nil)
+		mockRepo.On("GetQueueControl", mock.Anything, eventID).Return(nil, nil)
+	}
+	mockRepo.On("GetQueueSize", mock.Anything, "event-fixed").Return(int64(9), nil)
+	mockRepo.On("GetQueueSize", mock.Anything, "event-123").Return(int64(59), nil)
+	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-1").Return(&repository.QueuePositionResult{
+		Position:     30,
+		TotalInQueue: 59,
+		IsInQueue:    true,
+	}, nil)
+	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-1").Return(map[string]string{}, nil)
+	mockRepo.On("DeleteQueuePass", mock.Anything, "event-123", "user-1").Return(nil)
+
+	// Nothing released yet: fixed seconds per user
+	status, err := service.GetQueueStatus(ctx, "event-fixed")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), status.EstimatedWait)
+	assert.Equal(t, &dto.WaitRange{MinSeconds: 30, MaxSeconds: 30}, status.EstimatedWaitRange)
+	assert.Equal(t, etaBasisDefault, status.EstimateBasis)
+
+	// 2 users per second, between 2 and 3 per second at the quartiles
+	status, err = service.GetQueueStatus(ctx, "event-123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), status.EstimatedWait)
+	assert.Equal(t, &dto.WaitRange{MinSeconds: 20, MaxSeconds: 30}, status.EstimatedWaitRange)
+	assert.Equal(t, etaBasisReleases, status.EstimateBasis)
+
+	position, err := service.GetPosition(ctx, "user-1", "event-123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), position.EstimatedWait)
+	assert.Equal(t, &dto.WaitRange{MinSeconds: 10, MaxSeconds: 15}, position.EstimatedWaitRange)
+
+	// Throughput is read once per event per cache TTL
+	assert.Equal(t, 2, health.reads)
+
+	assert.NoError(t, service.DeleteQueuePass(ctx, "user-1", "event-123"))
+	assert.Equal(t, 1, health.passesUsed)
+}
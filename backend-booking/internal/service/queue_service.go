@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,6 +19,9 @@ import (
 	"go.opentelemetry.io/otel/codes"
 )
 
+// ErrInvalidQueueConfig is returned when a queue config update is rejected
+var ErrInvalidQueueConfig = errors.New("invalid queue config")
+
 // QueueService defines the interface for queue business logic
 type QueueService interface {
 	// JoinQueue adds a user to the virtual queue for an event
@@ -31,11 +36,85 @@ type QueueService interface {
 	// GetQueueStatus gets the queue status for an event
 	GetQueueStatus(ctx context.Context, eventID string) (*dto.QueueStatusResponse, error)
 
-	// ValidateQueuePass validates the queue pass JWT and checks Redis
-	ValidateQueuePass(ctx context.Context, userID, eventID, queuePass string) error
+	// ValidateQueuePass validates the queue pass JWT and checks Redis,
+	// returning its claims; the pass ID spends its budget on a reservation
+	ValidateQueuePass(ctx context.Context, userID, eventID, queuePass string) (*QueuePassClaims, error)
 
-	// DeleteQueuePass removes the queue pass after successful booking
+	// DeleteQueuePass removes the queue pass once it is used up
 	DeleteQueuePass(ctx context.Context, userID, eventID string) error
+
+	// GetQueueConfig gets an event's queue configuration (admin)
+	GetQueueConfig(ctx context.Context, eventID string) (*dto.QueueConfigResponse, error)
+
+	// SetQueueConfig replaces an event's queue configuration (admin)
+	SetQueueConfig(ctx context.Context, eventID string, req *dto.QueueConfigRequest) (*dto.QueueConfigResponse, error)
+
+	// PauseQueue stops an event's queue releasing users, keeping their positions (admin)
+	PauseQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error)
+
+	// ResumeQueue lets a paused or drained queue release and take joins again (admin)
+	ResumeQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error)
+
+	// DrainQueue closes an event's queue to joins and removes everyone waiting in it (admin)
+	DrainQueue(ctx context.Context, eventID string, req *dto.QueueControlRequest) (*dto.QueueControlResponse, error)
+
+	// ExpireQueuePasses expires every outstanding queue pass of an event (admin)
+	ExpireQueuePasses(ctx context.Context, eventID string) (*dto.ExpireQueuePassesResponse, error)
+}
+
+// queueConfigCacheTTL is how long the service caches an event's queue
+// config and state; a mode change or drain reaches every replica within it
+const queueConfigCacheTTL = 10 * time.Second
+
+// cachedQueueConfig is an event's queue config as last read from Redis
+type cachedQueueConfig struct {
+	config  *repository.EventQueueConfig // nil = defaults
+	control *repository.QueueControl     // nil = active
+	// allowListed maps a user ID to the first lane whose allow-list has it
+	allowListed map[string]int
+	expiresAt   time.Time
+}
+
+// lane returns the priority lane a user joins: the first that admits their
+// claims or lists them ("" = general)
+func (c *cachedQueueConfig) lane(userID, role, tenantID string) string {
+	if c.config == nil {
+		return ""
+	}
+	listed, ok := c.allowListed[userID]
+	for i := range c.config.Lanes {
+		lane := &c.config.Lanes[i]
+		if (ok && listed == i) || lane.MatchesClaims(role, tenantID) {
+			return lane.Name
+		}
+	}
+	return ""
+}
+
+// hasLanes reports whether the event's queue has priority lanes
+func (c *cachedQueueConfig) hasLanes() bool {
+	return c.config != nil && len(c.config.Lanes) > 0
+}
+
+// laneNames returns the names of the event's priority lanes
+func (c *cachedQueueConfig) laneNames() []string {
+	if !c.hasLanes() {
+		return nil
+	}
+	names := make([]string, len(c.config.Lanes))
+	for i, lane := range c.config.Lanes {
+		names[i] = lane.Name
+	}
+	return names
+}
+
+// etaLane returns the lane a user's wait is estimated for: their own when
+// the event has lanes, the whole queue otherwise
+func (c *cachedQueueConfig) etaLane(lane string) string {
+	if lane == "" && c.hasLanes() {
+		return domain.QueueLaneGeneral
+	}
+	return lane
 }
 
 // queueService implements QueueService
@@ -46,6 +125,15 @@ type queueService struct {
 	estimatedWaitPerUser int64 // seconds per user in queue
 	queuePassTTL         time.Duration
 	jwtSecret            string
+	admissionHealth      repository.AdmissionHealthRepository
+	etaWindow            time.Duration
+	screener             JoinScreener
+
+	configMu    sync.Mutex
+	configCache map[string]*cachedQueueConfig
+
+	throughputMu    sync.Mutex
+	throughputCache map[throughputKey]*cachedThroughput
 }
 
 // QueueServiceConfig contains configuration for queue service
@@ -55,6 +143,15 @@ type QueueServiceConfig struct {
 	EstimatedWaitPerUser int64
 	QueuePassTTL         time.Duration // TTL for queue pass token (default: 5 minutes)
 	JWTSecret            string        // Secret for signing queue pass JWT
+	// AdmissionHealth reports the release rate the queue release worker
+	// computes and the queue's throughput that wait estimates are based on
+	// (nil = EstimatedWaitPerUser seconds per position)
+	AdmissionHealth repository.AdmissionHealthRepository
+	// ETAWindow is how much recent throughput wait estimates follow (default: 5 minutes)
+	ETAWindow time.Duration
+	// JoinScreener screens joins for bots, parking or rejecting suspicious
+	// ones (nil = joins are not screened)
+	JoinScreener JoinScreener
 }
 
 // NewQueueService creates a new queue service
@@ -67,6 +164,9 @@ func NewQueueService(
 	estimatedWait := int64(3) // 3 seconds per user
 	queuePassTTL := 5 * time.Minute
 	jwtSecret := "" // Must be provided via config
+	var admissionHealth repository.AdmissionHealthRepository
+	etaWindow := 5 * time.Minute
+	var screener JoinScreener
 
 	if cfg != nil {
 		if cfg.QueueTTL > 0 {
@@ -82,6 +182,11 @@ func NewQueueService(
 			queuePassTTL = cfg.QueuePassTTL
 		}
 		jwtSecret = cfg.JWTSecret
+		admissionHealth = cfg.AdmissionHealth
+		if cfg.ETAWindow > 0 {
+			etaWindow = cfg.ETAWindow
+		}
+		screener = cfg.JoinScreener
 	}
 
 	if jwtSecret == "" {
@@ -95,6 +200,11 @@ func NewQueueService(
 		estimatedWaitPerUser: estimatedWait,
 		queuePassTTL:         queuePassTTL,
 		jwtSecret:            jwtSecret,
+		admissionHealth:      admissionHealth,
+		etaWindow:            etaWindow,
+		screener:             screener,
+		configCache:          make(map[string]*cachedQueueConfig),
+		throughputCache:      make(map[throughputKey]*cachedThroughput),
 	}
 }
 
@@ -122,6 +232,43 @@ func (s *queueService) JoinQueue(ctx context.Context, userID string, req *dto.Jo
 		attribute.String("event_id", req.EventID),
 	)
 
+	// Lottery queues pool everyone who joins before opening; priority lanes
+	// are picked by the user's claims
+	cached, err := s.cachedEventQueueConfig(ctx, req.EventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if cached.control.IsDrained() {
+		span.SetStatus(codes.Error, "queue drained")
+		return nil, domain.ErrQueueDrained
+	}
+	if cached.control.IsScheduled() {
+		span.SetStatus(codes.Error, "waiting room not open")
+		return nil, domain.ErrQueueNotOpen
+	}
+	config := cached.config
+	lane := cached.lane(userID, req.Role, req.TenantID)
+	lanes := cached.laneNames()
+
+	// Suspicious joins are turned away, or parked behind every other lane;
+	// parked users skip the lottery so the draw cannot move them forward
+	parked := false
+	if s.screener != nil {
+		lanes = append(lanes, domain.QueueLaneParked)
+		verdict := s.screener.Screen(ctx, &JoinAttempt{EventID: req.EventID, UserID: userID, Signals: req.Signals})
+		span.SetAttributes(attribute.String("screen_decision", verdict.Decision))
+		switch verdict.Decision {
+		case JoinDecisionReject:
+			span.SetStatus(codes.Error, "join rejected")
+			return nil, domain.ErrQueueJoinRejected
+		case JoinDecisionPark:
+			lane = domain.QueueLaneParked
+			parked = true
+		}
+	}
+
 	// Generate unique queue token
 	token := generateQueueToken()
 
@@ -132,6 +279,11 @@ func (s *queueService) JoinQueue(ctx context.Context, userID string, req *dto.Jo
 		Token:        token,
 		TTLSeconds:   int(s.queueTTL.Seconds()),
 		MaxQueueSize: s.maxQueueSize,
+		Lane:         lane,
+		Lanes:        lanes,
+	}
+	if config.IsLottery() && !parked {
+		params.LotteryOpensAt = config.LotteryOpensAt
 	}
 
 	result, err := s.queueRepo.JoinQueue(ctx, params)
@@ -155,19 +307,48 @@ func (s *queueService) JoinQueue(ctx context.Context, userID string, req *dto.Jo
 		}
 	}
 
-	// Calculate estimated wait time
-	estimatedWait := result.Position * s.estimatedWaitPerUser
-
 	now := time.Now()
-	span.SetAttributes(attribute.Int64("position", result.Position))
+	if result.InLottery {
+		drawAt := time.Unix(config.LotteryOpensAt, 0)
+		span.SetAttributes(attribute.Bool("in_lottery", true))
+		span.SetStatus(codes.Ok, "")
+		return &dto.JoinQueueResponse{
+			Token:         token,
+			JoinedAt:      now,
+			ExpiresAt:     drawAt.Add(s.queueTTL),
+			InLottery:     true,
+			LotteryDrawAt: &drawAt,
+			Message:       "Entered the lottery; queue positions are drawn when the queue opens",
+		}, nil
+	}
+
+	// Parked users are told their place in the whole queue, which is last,
+	// rather than that they were parked
+	position := result.Position
+	if parked {
+		lane = ""
+		position = result.TotalInQueue
+	}
+
+	// Estimate the wait from how fast the user's lane has been moving
+	etaLane := cached.etaLane(lane)
+	wait := s.estimateWait(ctx, req.EventID, etaLane, position)
+
+	span.SetAttributes(
+		attribute.Int64("position", position),
+		attribute.String("lane", etaLane),
+	)
 	span.SetStatus(codes.Ok, "")
 	return &dto.JoinQueueResponse{
-		Position:      result.Position,
-		Token:         token,
-		EstimatedWait: estimatedWait,
-		JoinedAt:      now,
-		ExpiresAt:     now.Add(s.queueTTL),
-		Message:       "Successfully joined the queue",
+		Position:           position,
+		Token:              token,
+		EstimatedWait:      wait.seconds,
+		EstimatedWaitRange: wait.waitRange(),
+		EstimateBasis:      wait.basis,
+		JoinedAt:           now,
+		ExpiresAt:          now.Add(s.queueTTL),
+		Message:            "Successfully joined the queue",
+		Lane:               etaLane,
 	}, nil
 }
 
@@ -218,15 +399,46 @@ func (s *queueService) GetPosition(ctx context.Context, userID, eventID string)
 			}, nil
 		}
 
+		// Users a drain removed are told the queue closed
+		if control, err := s.queueControl(ctx, eventID); err == nil && control.IsDrained() {
+			span.SetStatus(codes.Error, "queue drained")
+			return nil, domain.ErrQueueDrained
+		}
+
 		span.SetStatus(codes.Error, "not in queue")
 		return nil, domain.ErrNotInQueue
 	}
 
-	// Calculate estimated wait time
-	estimatedWait := result.Position * s.estimatedWaitPerUser
+	// Users awaiting the lottery draw have no position yet
+	if result.InLottery {
+		response := &dto.QueuePositionResponse{
+			TotalInQueue: result.TotalInQueue,
+			InLottery:    true,
+		}
+		if config, err := s.eventQueueConfig(ctx, eventID); err == nil && config.IsLottery() {
+			drawAt := time.Unix(config.LotteryOpensAt, 0)
+			response.LotteryDrawAt = &drawAt
+		}
+		span.SetAttributes(attribute.Bool("in_lottery", true))
+		span.SetStatus(codes.Ok, "")
+		return response, nil
+	}
+
+	// Parked users see their place in the whole queue, as when they joined,
+	// in the lane everyone else is reported in
+	if result.Lane == domain.QueueLaneParked {
+		result.Position += result.TotalInQueue - result.TotalInLane
+		result.Lane = domain.QueueLaneGeneral
+		result.TotalInLane = 0
+	}
 
-	// Check if user is ready (position <= some threshold, e.g., position 1)
-	isReady := result.Position <= 1
+	// Estimate the wait from how fast the user's lane has been moving
+	wait := s.estimateWait(ctx, eventID, result.Lane, result.Position)
+
+	// Check if user is ready (position <= some threshold, e.g., position 1);
+	// nobody is while operators have paused the queue
+	control, _ := s.queueControl(ctx, eventID)
+	isReady := result.Position <= 1 && !control.IsPaused()
 
 	// Get expiry info
 	userInfo, _ := s.queueRepo.GetUserQueueInfo(ctx, eventID, userID)
@@ -238,25 +450,39 @@ func (s *queueService) GetPosition(ctx context.Context, userID, eventID string)
 	}
 
 	response := &dto.QueuePositionResponse{
-		Position:      result.Position,
-		TotalInQueue:  result.TotalInQueue,
-		EstimatedWait: estimatedWait,
-		IsReady:       isReady,
-		ExpiresAt:     expiresAt,
+		Position:           result.Position,
+		TotalInQueue:       result.TotalInQueue,
+		EstimatedWait:      wait.seconds,
+		EstimatedWaitRange: wait.waitRange(),
+		EstimateBasis:      wait.basis,
+		IsReady:            isReady,
+		ExpiresAt:          expiresAt,
+		Lane:               result.Lane,
+		TotalInLane:        result.TotalInLane,
+	}
+	if control.IsPaused() {
+		response.QueueState = control.State
+		response.QueueMessage = control.Message
 	}
 
 	// Generate queue pass when user is ready (position = 1)
 	if isReady {
-		queuePass, queuePassExpiresAt, err := s.generateQueuePass(userID, eventID)
+		passID := generateQueueToken()
+		queuePass, queuePassExpiresAt, err := s.generateQueuePass(userID, eventID, passID)
 		if err != nil {
 			// Log error but don't fail the request
 			// The user can still see their position
 			return response, nil
 		}
 
-		// Store queue pass in Redis for validation
+		// Store queue pass in Redis for validation, with the usage budget
+		// the event gives its passes
+		config, err := s.eventQueueConfig(ctx, eventID)
+		if err != nil {
+			return response, nil
+		}
 		ttlSeconds := int(s.queuePassTTL.Seconds())
-		if err := s.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, ttlSeconds); err != nil {
+		if err := s.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, config.PassBudget(passID), ttlSeconds); err != nil {
 			// Log error but don't fail the request
 			return response, nil
 		}
@@ -332,13 +558,202 @@ func (s *queueService) GetQueueStatus(ctx context.Context, eventID string) (*dto
 		return nil, err
 	}
 
+	control, err := s.queueControl(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	// A user joining now waits behind everyone in the queue
+	wait := s.estimateWait(ctx, eventID, "", size+1)
+
 	span.SetAttributes(attribute.Int64("total_in_queue", size))
 	span.SetStatus(codes.Ok, "")
-	return &dto.QueueStatusResponse{
-		EventID:      eventID,
-		TotalInQueue: size,
-		IsOpen:       true, // TODO: Check event status from event service
-	}, nil
+	response := &dto.QueueStatusResponse{
+		EventID:            eventID,
+		TotalInQueue:       size,
+		IsOpen:             !control.IsDrained() && !control.IsScheduled(),
+		EstimatedWait:      wait.seconds,
+		EstimatedWaitRange: wait.waitRange(),
+		EstimateBasis:      wait.basis,
+		State:              domain.QueueStateActive,
+	}
+	if control != nil {
+		response.State = control.State
+		response.Message = control.Message
+	}
+	return response, nil
+}
+
+// GetQueueConfig gets an event's queue configuration
+func (s *queueService) GetQueueConfig(ctx context.Context, eventID string) (*dto.QueueConfigResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.queue.get_config")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	config, err := s.queueRepo.GetEventQueueConfig(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	response := queueConfigResponse(eventID, config)
+
+	control, err := s.queueRepo.GetQueueControl(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if control != nil {
+		response.Control = queueControlResponse(eventID, control)
+	}
+
+	if s.admissionHealth != nil {
+		rate, err := s.admissionHealth.GetAdmissionRate(ctx, eventID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		if rate != nil {
+			response.ReleaseRate = &dto.QueueReleaseRate{
+				Rate:      rate.Rate,
+				Reason:    rate.Reason,
+				UpdatedAt: rate.UpdatedAt.UTC(),
+			}
+		}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return response, nil
+}
+
+// SetQueueConfig replaces an event's queue configuration
+func (s *queueService) SetQueueConfig(ctx context.Context, eventID string, req *dto.QueueConfigRequest) (*dto.QueueConfigResponse, error) {
+	ctx, span := telemetry.StartSpan(ctx, "service.queue.set_config")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("event_id", eventID))
+
+	if valid, msg := req.Validate(); !valid {
+		span.SetStatus(codes.Error, "invalid queue config")
+		return nil, fmt.Errorf("%w: %s", ErrInvalidQueueConfig, msg)
+	}
+
+	config := &repository.EventQueueConfig{
+		MaxConcurrentBookings: req.MaxConcurrentBookings,
+		QueuePassTTLMinutes:   req.QueuePassTTLMinutes,
+		Mode:                  req.Mode,
+		ReleaseRateOverride:   req.ReleaseRateOverride,
+	}
+	for _, lane := range req.Lanes {
+		config.Lanes = append(config.Lanes, domain.QueueLane{
+			Name:    lane.Name,
+			Share:   lane.Share,
+			Roles:   lane.Roles,
+			Tenants: lane.Tenants,
+			UserIDs: lane.UserIDs,
+		})
+	}
+	if config.Mode == "" {
+		config.Mode = domain.QueueModeFIFO
+	}
+	if req.LotteryOpensAt != nil {
+		config.LotteryOpensAt = req.LotteryOpensAt.Unix()
+	}
+
+	if err := s.queueRepo.SetEventQueueConfig(ctx, eventID, config); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	s.configMu.Lock()
+	delete(s.configCache, eventID)
+	s.configMu.Unlock()
+
+	span.SetStatus(codes.Ok, "")
+	return queueConfigResponse(eventID, config), nil
+}
+
+// eventQueueConfig returns an event's queue config (nil = defaults), cached
+// for queueConfigCacheTTL since joins are the queue's hottest path
+func (s *queueService) eventQueueConfig(ctx context.Context, eventID string) (*repository.EventQueueConfig, error) {
+	cached, err := s.cachedEventQueueConfig(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return cached.config, nil
+}
+
+// cachedEventQueueConfig returns an event's cached queue config with its
+// lanes' allow-lists indexed
+func (s *queueService) cachedEventQueueConfig(ctx context.Context, eventID string) (*cachedQueueConfig, error) {
+	s.configMu.Lock()
+	cached, ok := s.configCache[eventID]
+	s.configMu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	config, err := s.queueRepo.GetEventQueueConfig(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	control, err := s.queueRepo.GetQueueControl(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	cached = &cachedQueueConfig{config: config, control: control, expiresAt: time.Now().Add(queueConfigCacheTTL)}
+	if config != nil && len(config.Lanes) > 0 {
+		// Later lanes first, so the first lane listing a user wins
+		cached.allowListed = make(map[string]int)
+		for i := len(config.Lanes) - 1; i >= 0; i-- {
+			for _, userID := range config.Lanes[i].UserIDs {
+				cached.allowListed[userID] = i
+			}
+		}
+	}
+
+	s.configMu.Lock()
+	s.configCache[eventID] = cached
+	s.configMu.Unlock()
+	return cached, nil
+}
+
+// queueConfigResponse converts an event's queue config to its response
+func queueConfigResponse(eventID string, config *repository.EventQueueConfig) *dto.QueueConfigResponse {
+	response := &dto.QueueConfigResponse{
+		EventID: eventID,
+		Mode:    domain.QueueModeFIFO,
+	}
+	if config == nil {
+		return response
+	}
+	response.MaxConcurrentBookings = config.MaxConcurrentBookings
+	response.QueuePassTTLMinutes = config.QueuePassTTLMinutes
+	response.ReleaseRateOverride = config.ReleaseRateOverride
+	if config.Mode != "" {
+		response.Mode = config.Mode
+	}
+	if config.LotteryOpensAt > 0 {
+		opensAt := time.Unix(config.LotteryOpensAt, 0).UTC()
+		response.LotteryOpensAt = &opensAt
+	}
+	for _, lane := range config.Lanes {
+		response.Lanes = append(response.Lanes, dto.QueueLane{
+			Name:    lane.Name,
+			Share:   lane.Share,
+			Roles:   lane.Roles,
+			Tenants: lane.Tenants,
+			UserIDs: lane.UserIDs,
+		})
+	}
+	return response
 }
 
 // generateQueueToken generates a random queue token
@@ -373,8 +788,8 @@ type QueuePassClaims struct {
 	jwt.RegisteredClaims
 }
 
-// generateQueuePass generates a signed JWT queue pass token
-func (s *queueService) generateQueuePass(userID, eventID string) (string, time.Time, error) {
+// generateQueuePass generates a signed JWT queue pass token with ID passID
+func (s *queueService) generateQueuePass(userID, eventID, passID string) (string, time.Time, error) {
 	now := time.Now()
 	expiresAt := now.Add(s.queuePassTTL)
 
@@ -388,7 +803,7 @@ func (s *queueService) generateQueuePass(userID, eventID string) (string, time.T
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "booking-service",
 			Subject:   userID,
-			ID:        generateQueueToken(), // Unique JWT ID
+			ID:        passID, // Unique JWT ID, binding the pass to its budget
 		},
 	}
 
@@ -402,7 +817,7 @@ func (s *queueService) generateQueuePass(userID, eventID string) (string, time.T
 }
 
 // ValidateQueuePass validates the queue pass JWT and checks Redis
-func (s *queueService) ValidateQueuePass(ctx context.Context, userID, eventID, queuePass string) error {
+func (s *queueService) ValidateQueuePass(ctx context.Context, userID, eventID, queuePass string) (*QueuePassClaims, error) {
 	ctx, span := telemetry.StartSpan(ctx, "service.queue.validate_pass")
 	defer span.End()
 
@@ -413,7 +828,7 @@ func (s *queueService) ValidateQueuePass(ctx context.Context, userID, eventID, q
 
 	if queuePass == "" {
 		span.SetStatus(codes.Error, "queue pass required")
-		return domain.ErrQueuePassRequired
+		return nil, domain.ErrQueuePassRequired
 	}
 
 	// Parse and validate JWT
@@ -427,29 +842,29 @@ func (s *queueService) ValidateQueuePass(ctx context.Context, userID, eventID, q
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "invalid queue pass")
-		return domain.ErrInvalidQueuePass
+		return nil, domain.ErrInvalidQueuePass
 	}
 
 	claims, ok := token.Claims.(*QueuePassClaims)
 	if !ok || !token.Valid {
 		span.SetStatus(codes.Error, "invalid queue pass claims")
-		return domain.ErrInvalidQueuePass
+		return nil, domain.ErrInvalidQueuePass
 	}
 
 	// Verify claims match
 	if claims.UserID != userID {
 		span.SetStatus(codes.Error, "queue pass user mismatch")
-		return domain.ErrQueuePassUserMismatch
+		return nil, domain.ErrQueuePassUserMismatch
 	}
 
 	if claims.EventID != eventID {
 		span.SetStatus(codes.Error, "queue pass event mismatch")
-		return domain.ErrQueuePassEventMismatch
+		return nil, domain.ErrQueuePassEventMismatch
 	}
 
 	if claims.Purpose != "queue_pass" {
 		span.SetStatus(codes.Error, "invalid queue pass purpose")
-		return domain.ErrInvalidQueuePass
+		return nil, domain.ErrInvalidQueuePass
 	}
 
 	// Validate against Redis (check if not already used/expired)
@@ -457,19 +872,19 @@ func (s *queueService) ValidateQueuePass(ctx context.Context, userID, eventID, q
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to validate queue pass in redis")
-		return fmt.Errorf("failed to validate queue pass: %w", err)
+		return nil, fmt.Errorf("failed to validate queue pass: %w", err)
 	}
 
 	if !valid {
 		span.SetStatus(codes.Error, "queue pass not found or expired")
-		return domain.ErrQueuePassExpired
+		return nil, domain.ErrQueuePassExpired
 	}
 
 	span.SetStatus(codes.Ok, "")
-	return nil
+	return claims, nil
 }
 
-// DeleteQueuePass removes the queue pass after successful booking
+// DeleteQueuePass removes the queue pass once it is used up
 func (s *queueService) DeleteQueuePass(ctx context.Context, userID, eventID string) error {
 	ctx, span := telemetry.StartSpan(ctx, "service.queue.delete_pass")
 	defer span.End()
@@ -485,6 +900,11 @@ func (s *queueService) DeleteQueuePass(ctx context.Context, userID, eventID stri
 		return err
 	}
 
+	// Spent passes pace wait estimates while the queue releases nobody
+	if s.admissionHealth != nil {
+		_ = s.admissionHealth.RecordPassUsed(ctx, eventID)
+	}
+
 	span.SetStatus(codes.Ok, "")
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -51,8 +52,8 @@ func (m *MockQueueRepository) GetUserQueueInfo(ctx context.Context, eventID, use
 	return args.Get(0).(map[string]string), args.Error(1)
 }
 
-func (m *MockQueueRepository) StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, ttl int) error {
-	args := m.Called(ctx, eventID, userID, queuePass, ttl)
+func (m *MockQueueRepository) StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, budget repository.QueuePassBudget, ttl int) error {
+	args := m.Called(ctx, eventID, userID, queuePass, budget, ttl)
 	return args.Error(0)
 }
 
@@ -74,6 +75,22 @@ func (m *MockQueueRepository) PopUsersFromQueue(ctx context.Context, eventID str
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockQueueRepository) PopUsersFromLane(ctx context.Context, eventID, lane string, count int64) ([]string, error) {
+	args := m.Called(ctx, eventID, lane, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockQueueRepository) GetQueueLanes(ctx context.Context, eventID string) ([]string, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -105,6 +122,34 @@ func (m *MockQueueRepository) SetEventQueueConfig(ctx context.Context, eventID s
 	return args.Error(0)
 }
 
+func (m *MockQueueRepository) DrawLottery(ctx context.Context, eventID string, opensAt int64) (int64, error) {
+	args := m.Called(ctx, eventID, opensAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQueueRepository) GetQueueControl(ctx context.Context, eventID string) (*repository.QueueControl, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.QueueControl), args.Error(1)
+}
+
+func (m *MockQueueRepository) SetQueueControl(ctx context.Context, eventID string, control *repository.QueueControl) error {
+	args := m.Called(ctx, eventID, control)
+	return args.Error(0)
+}
+
+func (m *MockQueueRepository) DrainQueue(ctx context.Context, eventID string) (int64, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQueueRepository) ExpireQueuePasses(ctx context.Context, eventID string) (int64, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockQueueRepository) GetQueuePass(ctx context.Context, eventID, userID string) (string, error) {
 	args := m.Called(ctx, eventID, userID)
 	if args.Get(0) == nil {
@@ -132,6 +177,8 @@ func TestQueueService_JoinQueue_Success(t *testing.T) {
 		JoinedAt:     float64(time.Now().Unix()),
 	}
 
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
 		return params.UserID == "user-123" && params.EventID == "event-123"
 	})).Return(expectedResult, nil)
@@ -161,6 +208,8 @@ func TestQueueService_JoinQueue_AlreadyInQueue(t *testing.T) {
 		ErrorMessage: "User is already in queue",
 	}
 
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("JoinQueue", mock.Anything, mock.Anything).Return(expectedResult, nil)
 
 	req := &dto.JoinQueueRequest{
@@ -185,6 +234,8 @@ func TestQueueService_JoinQueue_QueueFull(t *testing.T) {
 		ErrorMessage: "Queue has reached maximum capacity",
 	}
 
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("JoinQueue", mock.Anything, mock.Anything).Return(expectedResult, nil)
 
 	req := &dto.JoinQueueRequest{
@@ -244,6 +295,8 @@ func TestQueueService_GetPosition_Success(t *testing.T) {
 		"expires_at": "1700000000",
 	}
 
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(expectedResult, nil)
 	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(userInfo, nil)
 
@@ -269,6 +322,8 @@ func TestQueueService_GetPosition_NotInQueue(t *testing.T) {
 		IsInQueue:    false,
 	}
 
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(expectedResult, nil)
 	mockRepo.On("GetQueuePass", mock.Anything, "event-123", "user-123").Return("", nil) // No queue pass
 
@@ -299,7 +354,9 @@ func TestQueueService_GetPosition_IsReady(t *testing.T) {
 
 	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(expectedResult, nil)
 	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(userInfo, nil)
-	mockRepo.On("StoreQueuePass", mock.Anything, "event-123", "user-123", mock.AnythingOfType("string"), 300).Return(nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("StoreQueuePass", mock.Anything, "event-123", "user-123", mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
 
 	result, err := service.GetPosition(context.Background(), "user-123", "event-123")
 
@@ -375,6 +432,8 @@ func TestQueueService_GetQueueStatus_Success(t *testing.T) {
 	mockRepo := new(MockQueueRepository)
 	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
 
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("GetQueueSize", mock.Anything, "event-123").Return(int64(500), nil)
 
 	result, err := service.GetQueueStatus(context.Background(), "event-123")
@@ -412,6 +471,8 @@ func TestQueueService_EstimatedWait_Calculation(t *testing.T) {
 		JoinedAt:     float64(time.Now().Unix()),
 	}
 
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("JoinQueue", mock.Anything, mock.Anything).Return(expectedResult, nil)
 
 	req := &dto.JoinQueueRequest{
@@ -448,7 +509,9 @@ func TestQueueService_GetPosition_QueuePassGeneration(t *testing.T) {
 
 	mockRepo.On("GetPosition", mock.Anything, "event-456", "user-789").Return(expectedResult, nil)
 	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-456", "user-789").Return(userInfo, nil)
-	mockRepo.On("StoreQueuePass", mock.Anything, "event-456", "user-789", mock.AnythingOfType("string"), 300).Return(nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-456").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-456").Return(nil, nil)
+	mockRepo.On("StoreQueuePass", mock.Anything, "event-456", "user-789", mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
 
 	result, err := service.GetPosition(context.Background(), "user-789", "event-456")
 
@@ -481,6 +544,8 @@ func TestQueueService_GetPosition_NoQueuePassWhenNotReady(t *testing.T) {
 
 	userInfo := map[string]string{}
 
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
 	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(expectedResult, nil)
 	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(userInfo, nil)
 
@@ -514,7 +579,9 @@ func TestQueueService_GetPosition_QueuePassStoreFails(t *testing.T) {
 	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(expectedResult, nil)
 	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(userInfo, nil)
 	// Simulate Redis store failure
-	mockRepo.On("StoreQueuePass", mock.Anything, "event-123", "user-123", mock.AnythingOfType("string"), 300).Return(assert.AnError)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("StoreQueuePass", mock.Anything, "event-123", "user-123", mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(assert.AnError)
 
 	result, err := service.GetPosition(context.Background(), "user-123", "event-123")
 
@@ -545,7 +612,9 @@ func TestQueueService_QueuePassJWTFormat(t *testing.T) {
 
 	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(expectedResult, nil)
 	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(userInfo, nil)
-	mockRepo.On("StoreQueuePass", mock.Anything, "event-123", "user-123", mock.AnythingOfType("string"), 300).Return(nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("StoreQueuePass", mock.Anything, "event-123", "user-123", mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
 
 	result, err := service.GetPosition(context.Background(), "user-123", "event-123")
 
@@ -557,3 +626,298 @@ func TestQueueService_QueuePassJWTFormat(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestQueueService_JoinQueue_Lottery(t *testing.T) {
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{
+		QueueTTL:  30 * time.Minute,
+		JWTSecret: testJWTSecret,
+	})
+
+	opensAt := time.Now().Add(time.Hour).Unix()
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(&repository.EventQueueConfig{
+		Mode:           domain.QueueModeLottery,
+		LotteryOpensAt: opensAt,
+	}, nil).Once()
+	mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
+		return params.LotteryOpensAt == opensAt
+	})).Return(&repository.JoinQueueResult{Success: true, TotalInQueue: 7, InLottery: true}, nil)
+	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(&repository.QueuePositionResult{
+		TotalInQueue: 7,
+		IsInQueue:    true,
+		InLottery:    true,
+	}, nil)
+
+	result, err := service.JoinQueue(context.Background(), "user-123", &dto.JoinQueueRequest{EventID: "event-123"})
+	assert.NoError(t, err)
+	assert.True(t, result.InLottery)
+	assert.Equal(t, int64(0), result.Position)
+	assert.Equal(t, opensAt, result.LotteryDrawAt.Unix())
+	assert.Equal(t, opensAt+int64((30*time.Minute).Seconds()), result.ExpiresAt.Unix())
+
+	// Awaiting the draw is never "ready", and the cached config is reused
+	position, err := service.GetPosition(context.Background(), "user-123", "event-123")
+	assert.NoError(t, err)
+	assert.True(t, position.InLottery)
+	assert.False(t, position.IsReady)
+	assert.Empty(t, position.QueuePass)
+	assert.Equal(t, opensAt, position.LotteryDrawAt.Unix())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQueueService_JoinQueue_Lanes(t *testing.T) {
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
+
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(&repository.EventQueueConfig{
+		Lanes: []domain.QueueLane{
+			{Name: "access", Share: 10, UserIDs: []string{"user-both"}},
+			{Name: "fans", Share: 30, Tenants: []string{"fanclub"}, UserIDs: []string{"user-listed", "user-both"}},
+		},
+	}, nil).Once()
+
+	tests := []struct {
+		userID, role, tenantID string
+		wantLane               string
+	}{
+		{"user-1", "", "fanclub", "fans"},
+		{"user-listed", "user", "", "fans"},
+		{"user-both", "", "fanclub", "access"}, // The first lane admitting the user
+		{"user-2", "user", "other", ""},
+	}
+	for _, tt := range tests {
+		mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
+			return params.UserID == tt.userID
+		})).Return(&repository.JoinQueueResult{Success: true, Position: 4, TotalInQueue: 9}, nil).Once()
+
+		result, err := service.JoinQueue(context.Background(), tt.userID, &dto.JoinQueueRequest{
+			EventID:  "event-123",
+			Role:     tt.role,
+			TenantID: tt.tenantID,
+		})
+		assert.NoError(t, err)
+
+		wantLane := tt.wantLane
+		if wantLane == "" {
+			wantLane = domain.QueueLaneGeneral
+		}
+		assert.Equal(t, wantLane, result.Lane, tt.userID)
+		assert.Equal(t, int64(12), result.EstimatedWait, tt.userID)
+	}
+
+	for i, call := range mockRepo.Calls[2:] {
+		params := call.Arguments.Get(1).(repository.JoinQueueParams)
+		assert.Equal(t, tests[i].wantLane, params.Lane, params.UserID)
+		assert.Equal(t, []string{"access", "fans"}, params.Lanes)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+// stubJoinScreener decides every join the same way
+type stubJoinScreener struct {
+	decision string
+}
+
+func (s stubJoinScreener) Screen(ctx context.Context, join *JoinAttempt) *JoinVerdict {
+	return &JoinVerdict{Decision: s.decision}
+}
+
+func TestQueueService_JoinQueue_Screening(t *testing.T) {
+	newService := func(decision string) (QueueService, *MockQueueRepository) {
+		mockRepo := new(MockQueueRepository)
+		mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(&repository.EventQueueConfig{
+			Mode:           domain.QueueModeLottery,
+			LotteryOpensAt: time.Now().Add(time.Hour).Unix(),
+		}, nil).Once()
+		return NewQueueService(mockRepo, &QueueServiceConfig{
+			JWTSecret:    testJWTSecret,
+			JoinScreener: stubJoinScreener{decision: decision},
+		}), mockRepo
+	}
+	req := &dto.JoinQueueRequest{EventID: "event-123"}
+
+	t.Run("parks at the back, outside the lottery", func(t *testing.T) {
+		service, mockRepo := newService(JoinDecisionPark)
+		mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
+			return params.Lane == domain.QueueLaneParked && params.LotteryOpensAt == 0
+		})).Return(&repository.JoinQueueResult{Success: true, Position: 1, TotalInQueue: 40}, nil)
+
+		result, err := service.JoinQueue(context.Background(), "user-123", req)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(40), result.Position)
+		assert.Empty(t, result.Lane)
+
+		params := mockRepo.Calls[2].Arguments.Get(1).(repository.JoinQueueParams)
+		assert.Equal(t, []string{domain.QueueLaneParked}, params.Lanes)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("allowed joins keep the lottery and are checked against the parked lane", func(t *testing.T) {
+		service, mockRepo := newService(JoinDecisionAllow)
+		mockRepo.On("JoinQueue", mock.Anything, mock.MatchedBy(func(params repository.JoinQueueParams) bool {
+			return params.Lane == "" && params.LotteryOpensAt > 0 &&
+				len(params.Lanes) == 1 && params.Lanes[0] == domain.QueueLaneParked
+		})).Return(&repository.JoinQueueResult{Success: true, TotalInQueue: 40, InLottery: true}, nil)
+
+		result, err := service.JoinQueue(context.Background(), "user-123", req)
+		assert.NoError(t, err)
+		assert.True(t, result.InLottery)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		service, mockRepo := newService(JoinDecisionReject)
+
+		_, err := service.JoinQueue(context.Background(), "user-123", req)
+		assert.ErrorIs(t, err, domain.ErrQueueJoinRejected)
+		mockRepo.AssertNotCalled(t, "JoinQueue", mock.Anything, mock.Anything)
+	})
+}
+
+func TestQueueService_GetPosition_Parked(t *testing.T) {
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret, EstimatedWaitPerUser: 2})
+
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetPosition", mock.Anything, "event-123", "user-123").Return(&repository.QueuePositionResult{
+		Position:     3,
+		TotalInQueue: 50,
+		IsInQueue:    true,
+		Lane:         domain.QueueLaneParked,
+		TotalInLane:  5,
+	}, nil)
+	mockRepo.On("GetUserQueueInfo", mock.Anything, "event-123", "user-123").Return(map[string]string{}, nil)
+
+	// Behind the 45 users of other lanes
+	result, err := service.GetPosition(context.Background(), "user-123", "event-123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(48), result.Position)
+	assert.Equal(t, int64(96), result.EstimatedWait)
+	assert.Equal(t, domain.QueueLaneGeneral, result.Lane)
+	assert.Zero(t, result.TotalInLane)
+	assert.False(t, result.IsReady)
+}
+
+func TestQueueService_SetQueueConfig(t *testing.T) {
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret})
+	ctx := context.Background()
+
+	opensAt := time.Date(2026, 12, 1, 10, 0, 0, 0, time.UTC)
+	invalid := []*dto.QueueConfigRequest{
+		{Mode: "random"},
+		{Mode: domain.QueueModeLottery},
+		{Mode: domain.QueueModeFIFO, LotteryOpensAt: &opensAt},
+		{MaxConcurrentBookings: -1},
+		{ReleaseRateOverride: -5},
+		{Lanes: []dto.QueueLane{{Name: "VIP", Share: 10, Roles: []string{"vip"}}}},
+		{Lanes: []dto.QueueLane{{Name: domain.QueueLaneGeneral, Share: 10, Roles: []string{"vip"}}}},
+		{Lanes: []dto.QueueLane{{Name: "vip", Share: 10}}},
+		{Lanes: []dto.QueueLane{{Name: "vip", Share: 60, Roles: []string{"vip"}}, {Name: "fans", Share: 50, Tenants: []string{"t1"}}}},
+		{Mode: domain.QueueModeLottery, LotteryOpensAt: &opensAt, Lanes: []dto.QueueLane{{Name: "vip", Share: 10, Roles: []string{"vip"}}}},
+	}
+	for _, req := range invalid {
+		_, err := service.SetQueueConfig(ctx, "event-123", req)
+		assert.ErrorIs(t, err, ErrInvalidQueueConfig, "request %+v", req)
+	}
+
+	mockRepo.On("SetEventQueueConfig", mock.Anything, "event-123", &repository.EventQueueConfig{
+		MaxConcurrentBookings: 200,
+		Mode:                  domain.QueueModeLottery,
+		LotteryOpensAt:        opensAt.Unix(),
+		ReleaseRateOverride:   25,
+	}).Return(nil)
+
+	result, err := service.SetQueueConfig(ctx, "event-123", &dto.QueueConfigRequest{
+		MaxConcurrentBookings: 200,
+		Mode:                  domain.QueueModeLottery,
+		LotteryOpensAt:        &opensAt,
+		ReleaseRateOverride:   25,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.QueueModeLottery, result.Mode)
+	assert.True(t, opensAt.Equal(*result.LotteryOpensAt))
+	assert.Equal(t, 25, result.ReleaseRateOverride)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestQueueService_GetQueueConfig_ReleaseRate(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	health := repository.NewRedisAdmissionHealthRepository(client)
+	mockRepo := new(MockQueueRepository)
+	service := NewQueueService(mockRepo, &QueueServiceConfig{JWTSecret: testJWTSecret, AdmissionHealth: health})
+	ctx := context.Background()
+
+	mockRepo.On("GetQueueControl", mock.Anything, "event-123").Return(nil, nil)
+	mockRepo.On("GetEventQueueConfig", mock.Anything, "event-123").Return(&repository.EventQueueConfig{MaxConcurrentBookings: 200}, nil)
+
+	result, err := service.GetQueueConfig(ctx, "event-123")
+	assert.NoError(t, err)
+	assert.Nil(t, result.ReleaseRate)
+
+	updatedAt := time.Unix(1_700_000_000, 0)
+	assert.NoError(t, health.SetAdmissionRate(ctx, "event-123", &repository.AdmissionRate{Rate: 50, Reason: "latency", UpdatedAt: updatedAt}))
+
+	result, err = service.GetQueueConfig(ctx, "event-123")
+	assert.NoError(t, err)
+	assert.Equal(t, &dto.QueueReleaseRate{Rate: 50, Reason: "latency", UpdatedAt: updatedAt.UTC()}, result.ReleaseRate)
+}
+
+func TestQueueService_QueueControl(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	service := NewQueueService(repository.NewRedisQueueRepository(client), &QueueServiceConfig{JWTSecret: testJWTSecret})
+	ctx := context.Background()
+	join := func(userID string) error {
+		_, err := service.JoinQueue(ctx, userID, &dto.JoinQueueRequest{EventID: "event-1"})
+		return err
+	}
+
+	assert.NoError(t, join("user-1"))
+	assert.NoError(t, join("user-2"))
+
+	// Paused: positions are kept, but nobody is ready
+	paused, err := service.PauseQueue(ctx, "event-1", &dto.QueueControlRequest{Message: "Back soon", UpdatedBy: "admin-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.QueueStatePaused, paused.State)
+	assert.NoError(t, join("user-3"))
+	position, err := service.GetPosition(ctx, "user-1", "event-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), position.Position)
+	assert.False(t, position.IsReady)
+	assert.Empty(t, position.QueuePass)
+	assert.Equal(t, "Back soon", position.QueueMessage)
+
+	// Drained: everyone is removed and joins are refused until resumed
+	drained, err := service.DrainQueue(ctx, "event-1", &dto.QueueControlRequest{Message: "Sold out"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), drained.Drained)
+	_, err = service.GetPosition(ctx, "user-1", "event-1")
+	assert.ErrorIs(t, err, domain.ErrQueueDrained)
+	assert.ErrorIs(t, join("user-4"), domain.ErrQueueDrained)
+	_, err = service.PauseQueue(ctx, "event-1", nil)
+	assert.ErrorIs(t, err, domain.ErrQueueDrained)
+	status, err := service.GetQueueStatus(ctx, "event-1")
+	assert.NoError(t, err)
+	assert.False(t, status.IsOpen)
+	assert.Equal(t, "Sold out", status.Message)
+
+	_, err = service.ResumeQueue(ctx, "event-1", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, join("user-4"))
+
+	// Scheduled: joins are refused until the waiting room opens
+	repo := repository.NewRedisQueueRepository(client)
+	assert.NoError(t, repo.SetQueueControl(ctx, "event-2", &repository.QueueControl{State: domain.QueueStateScheduled, UpdatedAt: time.Now()}))
+	_, err = service.JoinQueue(ctx, "user-1", &dto.JoinQueueRequest{EventID: "event-2"})
+	assert.ErrorIs(t, err, domain.ErrQueueNotOpen)
+	status, err = service.GetQueueStatus(ctx, "event-2")
+	assert.NoError(t, err)
+	assert.False(t, status.IsOpen)
+	assert.Equal(t, domain.QueueStateScheduled, status.State)
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
+)
+
+// FailoverMode is the store a ReservationFailover currently serves from
+type FailoverMode string
+
+const (
+	// FailoverModeRedis serves every reservation from Redis
+	FailoverModeRedis FailoverMode = "redis"
+	// FailoverModeFallback serves reservations from Postgres while Redis is unavailable
+	FailoverModeFallback FailoverMode = "fallback"
+	// FailoverModeResyncing moves zones served from Postgres back into Redis
+	FailoverModeResyncing FailoverMode = "resyncing"
+)
+
+// FailoverPrimary is the Redis reservation store a ReservationFailover protects
+type FailoverPrimary interface {
+	repository.ReservationRepository
+
+	// RestoreReservation recreates a reservation made in Postgres, leaving the seat counter alone
+	RestoreReservation(ctx context.Context, res *repository.FallbackReservation) error
+
+	// Ping checks that Redis answers
+	Ping(ctx context.Context) error
+}
+
+// ReservationFailover is a ReservationRepository that serves from Redis and
+// falls back to Postgres while Redis is unavailable
+type ReservationFailover interface {
+	repository.ReservationRepository
+
+	// Run releases expired Postgres reservations and probes Redis while in
+	// fallback mode, and resyncs into Redis once it answers, until ctx is cancelled
+	Run(ctx context.Context)
+
+	// Mode returns the store currently serving reservations
+	Mode() FailoverMode
+}
+
+// ReservationFailoverConfig contains configuration for the reservation failover
+type ReservationFailoverConfig struct {
+	// ProbeInterval is the interval between Redis probes while in fallback mode
+	ProbeInterval time.Duration
+}
+
+// reservationFailover implements ReservationFailover.
+//
+// Redis being unreachable (including an open circuit breaker) switches the
+// replica to fallback mode. Each zone is activated in Postgres on first use,
+// seeded from the ticket service's total minus the held and sold booking rows.
+// Once Redis answers again every active zone is resynced under its row lock:
+// Postgres reservations are restored into Redis, Redis reservations confirmed
+// or released meanwhile are replayed, and the zone counter is overwritten
+// with the Postgres one. Replicas that disagree about Redis health can leave
+// drift behind; the inventory reconciler reports it.
+type reservationFailover struct {
+	primary  FailoverPrimary
+	fallback repository.FallbackReservationStore
+	zones    ZoneFetcher
+	cfg      *ReservationFailoverConfig
+	log      *logger.Logger
+
+	mu       sync.RWMutex
+	mode     FailoverMode
+	activate singleflight.Group
+}
+
+// NewReservationFailover creates a new reservation failover
+func NewReservationFailover(
+	primary FailoverPrimary,
+	fallback repository.FallbackReservationStore,
+	zones ZoneFetcher,
+	cfg *ReservationFailoverConfig,
+) ReservationFailover {
+	if cfg == nil {
+		cfg = &ReservationFailoverConfig{}
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = 2 * time.Second
+	}
+
+	return &reservationFailover{
+		primary:  primary,
+		fallback: fallback,
+		zones:    zones,
+		cfg:      cfg,
+		log:      logger.Get(),
+		mode:     FailoverModeRedis,
+	}
+}
+
+// Mode returns the store currently serving reservations
+func (f *reservationFailover) Mode() FailoverMode {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.mode
+}
+
+// setMode switches mode and records it
+func (f *reservationFailover) setMode(ctx context.Context, mode FailoverMode) {
+	f.mu.Lock()
+	f.mode = mode
+	f.mu.Unlock()
+
+	var value int64
+	switch mode {
+	case FailoverModeFallback:
+		value = 1
+	case FailoverModeResyncing:
+		value = 2
+	}
+	metrics.RecordReservationFallbackMode(ctx, value)
+}
+
+// enterFallback switches to fallback mode after Redis failed with err
+func (f *reservationFailover) enterFallback(ctx context.Context, err error) {
+	f.mu.Lock()
+	if f.mode != FailoverModeRedis {
+		f.mu.Unlock()
+		return
+	}
+	f.mode = FailoverModeFallback
+	f.mu.Unlock()
+
+	f.log.Warn(fmt.Sprintf("Redis unavailable, serving reservations from Postgres: %v", err))
+	metrics.RecordReservationFallbackMode(ctx, 1)
+}
+
+// activateZone starts serving a zone from Postgres
+func (f *reservationFailover) activateZone(ctx context.Context, zoneID string) error {
+	_, err, _ := f.activate.Do(zoneID, func() (interface{}, error) {
+		zone, err := f.zones.FetchZone(ctx, zoneID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to activate fallback zone %s: %w", zoneID, err)
+		}
+		return nil, f.fallback.ActivateZone(ctx, zoneID, zone.SellableSeats())
+	})
+	return err
+}
+
+// route runs onRedis, or onFallback while Redis is unavailable. A zone the
+// fallback store does not serve yet is activated and tried again, unless
+// the failover is resyncing, in which case the zone is already back in Redis.
+func route[T any](ctx context.Context, f *reservationFailover, zoneID string,
+	onRedis func() (T, error), onFallback func() (T, error), notActive func(T) bool) (T, error) {
+	if f.Mode() == FailoverModeRedis {
+		result, err := onRedis()
+		if !pkgredis.IsUnavailable(err) {
+			return result, err
+		}
+		f.enterFallback(ctx, err)
+	}
+
+	result, err := onFallback()
+	if err != nil || !notActive(result) {
+		return result, err
+	}
+	if f.Mode() == FailoverModeResyncing {
+		return onRedis()
+	}
+	if err := f.activateZone(ctx, zoneID); err != nil {
+		var zero T
+		return zero, err
+	}
+	return onFallback()
+}
+
+// ReserveSeats reserves seats in Redis, or in Postgres while Redis is unavailable
+func (f *reservationFailover) ReserveSeats(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+	return route(ctx, f, params.ZoneID,
+		func() (*repository.ReserveResult, error) { return f.primary.ReserveSeats(ctx, params) },
+		func() (*repository.ReserveResult, error) { return f.fallback.ReserveSeats(ctx, params) },
+		func(r *repository.ReserveResult) bool { return r.ErrorCode == "ZONE_NOT_ACTIVE" },
+	)
+}
+
+// ConfirmBooking confirms a reservation in Redis, or in Postgres while Redis is unavailable
+func (f *reservationFailover) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error) {
+	return route(ctx, f, zoneID,
+		func() (*repository.ConfirmResult, error) {
+			return f.primary.ConfirmBooking(ctx, bookingID, zoneID, userID, paymentID)
+		},
+		func() (*repository.ConfirmResult, error) {
+			return f.fallback.ConfirmBooking(ctx, bookingID, zoneID, userID, paymentID)
+		},
+		func(r *repository.ConfirmResult) bool { return r.ErrorCode == "ZONE_NOT_ACTIVE" },
+	)
+}
+
+// ReleaseSeats releases a reservation in Redis, or in Postgres while Redis is unavailable
+func (f *reservationFailover) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error) {
+	return route(ctx, f, zoneID,
+		func() (*repository.ReleaseResult, error) {
+			return f.primary.ReleaseSeats(ctx, bookingID, zoneID, userID)
+		},
+		func() (*repository.ReleaseResult, error) {
+			return f.fallback.ReleaseSeats(ctx, bookingID, zoneID, userID)
+		},
+		func(r *repository.ReleaseResult) bool { return r.ErrorCode == "ZONE_NOT_ACTIVE" },
+	)
+}
+
+// GetZoneAvailability gets a zone's available seats from the store serving it
+func (f *reservationFailover) GetZoneAvailability(ctx context.Context, zoneID string) (int64, error) {
+	if f.Mode() == FailoverModeRedis {
+		seats, err := f.primary.GetZoneAvailability(ctx, zoneID)
+		if !pkgredis.IsUnavailable(err) {
+			return seats, err
+		}
+		f.enterFallback(ctx, err)
+	}
+
+	seats, err := f.fallback.GetZoneAvailabilities(ctx, []string{zoneID})
+	if err != nil {
+		return 0, err
+	}
+	if v, ok := seats[zoneID]; ok {
+		return v, nil
+	}
+	if f.Mode() == FailoverModeResyncing {
+		return f.primary.GetZoneAvailability(ctx, zoneID)
+	}
+	return 0, fmt.Errorf("zone %s is not served while Redis is unavailable", zoneID)
+}
+
+// GetZoneAvailabilities gets the available seats of several zones from the
+// store serving them; zones not served are omitted
+func (f *reservationFailover) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	if f.Mode() == FailoverModeRedis {
+		seats, err := f.primary.GetZoneAvailabilities(ctx, zoneIDs)
+		if !pkgredis.IsUnavailable(err) {
+			return seats, err
+		}
+		f.enterFallback(ctx, err)
+	}
+	return f.fallback.GetZoneAvailabilities(ctx, zoneIDs)
+}
+
+// SetZoneAvailability sets a zone's available seats in Redis. Zones are not
+// seeded in Postgres this way; they are activated from the booking rows.
+func (f *reservationFailover) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
+	return f.primary.SetZoneAvailability(ctx, zoneID, seats)
+}
+
+// Run releases expired Postgres reservations and probes Redis while in
+// fallback mode, and resyncs into Redis once it answers. Zones left active
+// by an earlier process are resynced on start.
+func (f *reservationFailover) Run(ctx context.Context) {
+	if zones, err := f.fallback.ListActiveZones(ctx); err == nil && len(zones) > 0 {
+		f.log.Warn(fmt.Sprintf("%d zones still served from Postgres, resyncing into Redis", len(zones)))
+		f.setMode(ctx, FailoverModeFallback)
+	}
+
+	ticker := time.NewTicker(f.cfg.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if f.Mode() == FailoverModeRedis {
+				continue
+			}
+			if seats, err := f.fallback.ReleaseExpired(ctx, time.Now()); err != nil {
+				f.log.Error(fmt.Sprintf("Failed to release expired fallback reservations: %v", err))
+			} else if seats > 0 {
+				f.log.Info(fmt.Sprintf("Released %d seats of expired fallback reservations", seats))
+			}
+			if err := f.primary.Ping(ctx); err != nil {
+				continue
+			}
+			f.resync(ctx)
+		}
+	}
+}
+
+// resync moves every zone served from Postgres back into Redis and returns
+// to Redis mode, or stays in fallback mode if a zone could not be moved
+func (f *reservationFailover) resync(ctx context.Context) {
+	ctx, span := telemetry.StartSpan(ctx, "service.reservation_failover.resync")
+	defer span.End()
+
+	f.setMode(ctx, FailoverModeResyncing)
+
+	zones, err := f.fallback.ListActiveZones(ctx)
+	if err != nil {
+		span.RecordError(err)
+		f.log.Error(fmt.Sprintf("Failed to list fallback zones: %v", err))
+		f.setMode(ctx, FailoverModeFallback)
+		return
+	}
+
+	failed := 0
+	for _, zoneID := range zones {
+		err := f.fallback.ResyncZone(ctx, zoneID, func(ctx context.Context, available int64, reservations []repository.FallbackReservation) error {
+			return f.applyResync(ctx, zoneID, available, reservations)
+		})
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			f.log.Error(fmt.Sprintf("Failed to resync zone %s into Redis: %v", zoneID, err))
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("zones", len(zones)),
+		attribute.Int("failed", failed),
+	)
+	if failed > 0 {
+		f.setMode(ctx, FailoverModeFallback)
+		return
+	}
+
+	f.log.Info(fmt.Sprintf("Redis recovered, resynced %d zones from Postgres", len(zones)))
+	f.setMode(ctx, FailoverModeRedis)
+}
+
+// applyResync writes one zone's fallback state into Redis. Replayed confirms
+// and releases may find the Redis reservation already expired; the counter
+// written last makes the outcome the same either way.
+func (f *reservationFailover) applyResync(ctx context.Context, zoneID string, available int64, reservations []repository.FallbackReservation) error {
+	for i := range reservations {
+		res := &reservations[i]
+		var err error
+		switch {
+		case res.Origin == repository.FallbackOriginFallback:
+			err = f.primary.RestoreReservation(ctx, res)
+		case res.Status == repository.FallbackStatusConfirmed:
+			_, err = f.primary.ConfirmBooking(ctx, res.BookingID, res.ZoneID, res.UserID, res.PaymentID)
+		case res.Status == repository.FallbackStatusReleased:
+			_, err = f.primary.ReleaseSeats(ctx, res.BookingID, res.ZoneID, res.UserID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resync booking %s: %w", res.BookingID, err)
+		}
+	}
+	return f.primary.SetZoneAvailability(ctx, zoneID, available)
+}
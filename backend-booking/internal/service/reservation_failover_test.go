@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// replyError is an error reply from Redis
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+func (e replyError) RedisError()   {}
+
+// mockFailoverPrimary is a FailoverPrimary that fails with err while err is set
+type mockFailoverPrimary struct {
+	err       error
+	reserved  int
+	restored  []string
+	confirmed []string
+	released  []string
+	zoneSeats map[string]int64
+}
+
+func (m *mockFailoverPrimary) ReserveSeats(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.reserved++
+	return &repository.ReserveResult{Success: true, BookingID: "redis-booking"}, nil
+}
+
+func (m *mockFailoverPrimary) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.confirmed = append(m.confirmed, bookingID)
+	return &repository.ConfirmResult{Success: true}, nil
+}
+
+func (m *mockFailoverPrimary) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.released = append(m.released, bookingID)
+	return &repository.ReleaseResult{Success: false, ErrorCode: "RESERVATION_NOT_FOUND"}, nil
+}
+
+func (m *mockFailoverPrimary) GetZoneAvailability(ctx context.Context, zoneID string) (int64, error) {
+	return m.zoneSeats[zoneID], m.err
+}
+
+func (m *mockFailoverPrimary) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	return m.zoneSeats, m.err
+}
+
+func (m *mockFailoverPrimary) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.zoneSeats[zoneID] = seats
+	return nil
+}
+
+func (m *mockFailoverPrimary) RestoreReservation(ctx context.Context, res *repository.FallbackReservation) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.restored = append(m.restored, res.BookingID)
+	return nil
+}
+
+func (m *mockFailoverPrimary) Ping(ctx context.Context) error {
+	return m.err
+}
+
+// mockFallbackStore is an in-memory FallbackReservationStore
+type mockFallbackStore struct {
+	zones        map[string]int64
+	reservations map[string][]repository.FallbackReservation
+	activations  int
+}
+
+func newMockFallbackStore() *mockFallbackStore {
+	return &mockFallbackStore{
+		zones:        map[string]int64{},
+		reservations: map[string][]repository.FallbackReservation{},
+	}
+}
+
+func (m *mockFallbackStore) ActivateZone(ctx context.Context, zoneID string, totalSeats int64) error {
+	if _, ok := m.zones[zoneID]; !ok {
+		m.activations++
+		m.zones[zoneID] = totalSeats
+	}
+	return nil
+}
+
+func (m *mockFallbackStore) ReserveSeats(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+	available, ok := m.zones[params.ZoneID]
+	if !ok {
+		return &repository.ReserveResult{ErrorCode: "ZONE_NOT_ACTIVE"}, nil
+	}
+	available -= int64(params.Quantity)
+	m.zones[params.ZoneID] = available
+	bookingID := "pg-booking"
+	m.reservations[params.ZoneID] = append(m.reservations[params.ZoneID], repository.FallbackReservation{
+		BookingID: bookingID,
+		ZoneID:    params.ZoneID,
+		UserID:    params.UserID,
+		Quantity:  int64(params.Quantity),
+		Status:    repository.FallbackStatusReserved,
+		Origin:    repository.FallbackOriginFallback,
+	})
+	return &repository.ReserveResult{Success: true, BookingID: bookingID, AvailableSeats: available}, nil
+}
+
+func (m *mockFallbackStore) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error) {
+	if _, ok := m.zones[zoneID]; !ok {
+		return &repository.ConfirmResult{ErrorCode: "ZONE_NOT_ACTIVE"}, nil
+	}
+	m.reservations[zoneID] = append(m.reservations[zoneID], repository.FallbackReservation{
+		BookingID: bookingID,
+		ZoneID:    zoneID,
+		UserID:    userID,
+		PaymentID: paymentID,
+		Status:    repository.FallbackStatusConfirmed,
+		Origin:    repository.FallbackOriginRedis,
+	})
+	return &repository.ConfirmResult{Success: true}, nil
+}
+
+func (m *mockFallbackStore) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error) {
+	if _, ok := m.zones[zoneID]; !ok {
+		return &repository.ReleaseResult{ErrorCode: "ZONE_NOT_ACTIVE"}, nil
+	}
+	m.reservations[zoneID] = append(m.reservations[zoneID], repository.FallbackReservation{
+		BookingID: bookingID,
+		ZoneID:    zoneID,
+		UserID:    userID,
+		Status:    repository.FallbackStatusReleased,
+		Origin:    repository.FallbackOriginRedis,
+	})
+	return &repository.ReleaseResult{Success: true}, nil
+}
+
+func (m *mockFallbackStore) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	for _, id := range zoneIDs {
+		if v, ok := m.zones[id]; ok {
+			result[id] = v
+		}
+	}
+	return result, nil
+}
+
+func (m *mockFallbackStore) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockFallbackStore) ListActiveZones(ctx context.Context) ([]string, error) {
+	var zones []string
+	for id := range m.zones {
+		zones = append(zones, id)
+	}
+	return zones, nil
+}
+
+func (m *mockFallbackStore) ResyncZone(ctx context.Context, zoneID string, apply func(ctx context.Context, available int64, reservations []repository.FallbackReservation) error) error {
+	available, ok := m.zones[zoneID]
+	if !ok {
+		return nil
+	}
+	if err := apply(ctx, available, m.reservations[zoneID]); err != nil {
+		return err
+	}
+	delete(m.zones, zoneID)
+	delete(m.reservations, zoneID)
+	return nil
+}
+
+// mockZoneFetcher returns zones with a fixed seat total
+type mockZoneFetcher struct {
+	totalSeats int64
+}
+
+func (m *mockZoneFetcher) FetchZone(ctx context.Context, zoneID string) (*ZoneInfo, error) {
+	return &ZoneInfo{ID: zoneID, TotalSeats: m.totalSeats}, nil
+}
+
+func newFailoverFixture() (*reservationFailover, *mockFailoverPrimary, *mockFallbackStore) {
+	primary := &mockFailoverPrimary{zoneSeats: map[string]int64{}}
+	store := newMockFallbackStore()
+	f := NewReservationFailover(primary, store, &mockZoneFetcher{totalSeats: 100}, nil).(*reservationFailover)
+	return f, primary, store
+}
+
+func TestReservationFailover_FallsBackWhenRedisUnavailable(t *testing.T) {
+	f, primary, store := newFailoverFixture()
+	ctx := context.Background()
+	params := repository.ReserveParams{ZoneID: "zone-1", UserID: "user-1", Quantity: 2}
+
+	primary.err = pkgredis.ErrCircuitOpen
+	result, err := f.ReserveSeats(ctx, params)
+	if err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if !result.Success || result.BookingID != "pg-booking" || result.AvailableSeats != 98 {
+		t.Errorf("ReserveSeats() = %+v, want Postgres reservation with 98 seats left", result)
+	}
+	if f.Mode() != FailoverModeFallback {
+		t.Errorf("Mode() = %s, want fallback", f.Mode())
+	}
+	if store.activations != 1 {
+		t.Errorf("activations = %d, want 1", store.activations)
+	}
+
+	// Later calls go straight to Postgres, even once Redis answers again
+	primary.err = nil
+	if _, err := f.ReserveSeats(ctx, params); err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if primary.reserved != 0 {
+		t.Errorf("primary reserved %d times in fallback mode, want 0", primary.reserved)
+	}
+	if seats, _ := f.GetZoneAvailability(ctx, "zone-1"); seats != 96 {
+		t.Errorf("GetZoneAvailability() = %d, want 96", seats)
+	}
+}
+
+func TestReservationFailover_ReplyErrorsDoNotFallBack(t *testing.T) {
+	f, primary, store := newFailoverFixture()
+
+	primary.err = replyError("ERR Error running script")
+	if _, err := f.ReserveSeats(context.Background(), repository.ReserveParams{ZoneID: "zone-1", Quantity: 1}); err == nil {
+		t.Fatal("ReserveSeats() error = nil, want the reply error")
+	}
+	if f.Mode() != FailoverModeRedis || store.activations != 0 {
+		t.Errorf("Mode() = %s, activations = %d, want redis and 0", f.Mode(), store.activations)
+	}
+}
+
+func TestReservationFailover_ResyncRestoresRedis(t *testing.T) {
+	f, primary, store := newFailoverFixture()
+	ctx := context.Background()
+
+	primary.err = pkgredis.ErrCircuitOpen
+	if _, err := f.ReserveSeats(ctx, repository.ReserveParams{ZoneID: "zone-1", UserID: "user-1", Quantity: 3}); err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if _, err := f.ConfirmBooking(ctx, "redis-1", "zone-1", "user-2", "pay-1"); err != nil {
+		t.Fatalf("ConfirmBooking() error = %v", err)
+	}
+	if _, err := f.ReleaseSeats(ctx, "redis-2", "zone-1", "user-3"); err != nil {
+		t.Fatalf("ReleaseSeats() error = %v", err)
+	}
+
+	// Resync fails while Redis is still down
+	f.resync(ctx)
+	if f.Mode() != FailoverModeFallback || len(store.zones) != 1 {
+		t.Fatalf("Mode() = %s with %d active zones after failed resync, want fallback and 1", f.Mode(), len(store.zones))
+	}
+
+	primary.err = nil
+	f.resync(ctx)
+
+	if f.Mode() != FailoverModeRedis {
+		t.Errorf("Mode() = %s, want redis", f.Mode())
+	}
+	if len(store.zones) != 0 {
+		t.Errorf("%d zones still active, want 0", len(store.zones))
+	}
+	if len(primary.restored) != 1 || primary.restored[0] != "pg-booking" {
+		t.Errorf("restored = %v, want [pg-booking]", primary.restored)
+	}
+	if len(primary.confirmed) != 1 || primary.confirmed[0] != "redis-1" {
+		t.Errorf("confirmed = %v, want [redis-1]", primary.confirmed)
+	}
+	if len(primary.released) != 1 || primary.released[0] != "redis-2" {
+		t.Errorf("released = %v, want [redis-2]", primary.released)
+	}
+	if primary.zoneSeats["zone-1"] != 97 {
+		t.Errorf("zone-1 seats = %d, want 97", primary.zoneSeats["zone-1"])
+	}
+
+	// Back in Redis mode, calls go to Redis
+	if _, err := f.ReserveSeats(ctx, repository.ReserveParams{ZoneID: "zone-1", Quantity: 1}); err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if primary.reserved != 1 {
+		t.Errorf("primary reserved %d times, want 1", primary.reserved)
+	}
+}
@@ -20,15 +20,29 @@ type ZoneInfo struct {
 	Price          float64 `json:"price"`
 	TotalSeats     int64   `json:"total_seats"`
 	AvailableSeats int64   `json:"available_seats"`
+	HeldSeats      int64   `json:"held_seats"` // Held back from sale by the organizer
+	CompSeats      int64   `json:"comp_seats"` // Set aside for complimentary tickets
 	IsActive       bool    `json:"is_active"`
 }
 
+// SellableSeats returns the seats the zone offers for public sale, sold or
+// not: its capacity minus the organizer's held and comp allocations
+func (z *ZoneInfo) SellableSeats() int64 {
+	return z.TotalSeats - z.HeldSeats - z.CompSeats
+}
+
 // ZoneFetcher fetches zone data from ticket service
 type ZoneFetcher interface {
 	// FetchZone fetches zone data by ID from ticket service
 	FetchZone(ctx context.Context, zoneID string) (*ZoneInfo, error)
 }
 
+// ZoneLister lists zones from ticket service
+type ZoneLister interface {
+	// ListActiveZones fetches all active zones from ticket service
+	ListActiveZones(ctx context.Context) ([]*ZoneInfo, error)
+}
+
 // ZoneSyncer handles syncing zone data to Redis with single-flight pattern
 type ZoneSyncer interface {
 	// SyncZone syncs zone availability to Redis (uses single-flight)
@@ -93,6 +107,81 @@ func (f *HTTPZoneFetcher) FetchZone(ctx context.Context, zoneID string) (*ZoneIn
 	return &response.Data, nil
 }
 
+// ListActiveZones fetches all active zones from ticket service via HTTP
+func (f *HTTPZoneFetcher) ListActiveZones(ctx context.Context) ([]*ZoneInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/zones/active", f.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Success bool        `json:"success"`
+		Data    []*ZoneInfo `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("API returned unsuccessful response")
+	}
+
+	return response.Data, nil
+}
+
+// ListShowZones fetches the zones of a show from ticket service via HTTP.
+// Shows have far fewer zones than the page limit, so one page is read.
+func (f *HTTPZoneFetcher) ListShowZones(ctx context.Context, showID string) ([]*ZoneInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/shows/%s/zones?limit=100", f.baseURL, showID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list show zones: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrShowNotFound, showID)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Success bool        `json:"success"`
+		Data    []*ZoneInfo `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("API returned unsuccessful response")
+	}
+
+	return response.Data, nil
+}
+
 // DefaultZoneSyncer implements ZoneSyncer with single-flight pattern
 type DefaultZoneSyncer struct {
 	fetcher         ZoneFetcher
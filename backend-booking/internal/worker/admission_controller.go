@@ -0,0 +1,194 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+)
+
+// Reasons an admission rate was chosen, as reported by the admin API
+const (
+	AdmissionReasonStatic         = "static"          // Adaptive release is off
+	AdmissionReasonOverride       = "override"        // Fixed by an admin
+	AdmissionReasonHealthy        = "healthy"         // Booking flow keeps up; rate grows
+	AdmissionReasonFailures       = "failures"        // Too many reservations fail
+	AdmissionReasonLatency        = "latency"         // Reservations are slow
+	AdmissionReasonPaymentBacklog = "payment_backlog" // Payments fall behind reservations
+	AdmissionReasonSoldOut        = "sold_out"        // Every zone is sold out
+	AdmissionReasonUnknown        = "unknown"         // Health could not be read; rate held
+)
+
+// ZoneSeatReader reads live zone availability
+type ZoneSeatReader interface {
+	// GetZoneAvailabilities returns the available seats of the given zones;
+	// zones that are not on sale are omitted
+	GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error)
+}
+
+// AdmissionControlConfig tunes how the queue release rate follows booking health
+type AdmissionControlConfig struct {
+	// Window is how far back health is judged (default: 2 minutes, max: 5
+	// minutes). It should be longer than buyers usually take to pay, or
+	// reservations awaiting payment look like a backlog.
+	Window time.Duration
+	// TargetSuccessRate is the share of reservations that must succeed (default: 0.95)
+	TargetSuccessRate float64
+	// TargetLatency is the mean reservation latency to stay under (default: 500ms)
+	TargetLatency time.Duration
+	// MaxPaymentBacklog is how many more seats may be reserved than paid for
+	// in the window (default: 0 = the event's max concurrent bookings)
+	MaxPaymentBacklog int64
+	// MinSamples is the reservations needed before failures and latency are
+	// judged (default: 20)
+	MinSamples int64
+	// Backoff multiplies the rate when the booking flow is degraded (default: 0.5)
+	Backoff float64
+	// Cooldown is the least time between two backoffs, so one bad stretch
+	// in the window is not punished on every tick (default: 10 seconds)
+	Cooldown time.Duration
+}
+
+// DefaultAdmissionControlConfig returns default configuration
+func DefaultAdmissionControlConfig() *AdmissionControlConfig {
+	return &AdmissionControlConfig{
+		Window:            2 * time.Minute,
+		TargetSuccessRate: 0.95,
+		TargetLatency:     500 * time.Millisecond,
+		MinSamples:        20,
+		Backoff:           0.5,
+		Cooldown:          10 * time.Second,
+	}
+}
+
+// AdmissionController adapts each event's release rate to the health of the
+// booking flow behind its queue: additive increase while reservations
+// succeed quickly and payments keep up, multiplicative decrease when they do
+// not, and nothing at all once the event is sold out. A healthy event admits
+// at its max concurrent bookings, which is the static release of old.
+type AdmissionController struct {
+	config *AdmissionControlConfig
+
+	mu     sync.Mutex
+	events map[string]*admissionState
+	now    func() time.Time
+}
+
+// admissionState is the rate of one event and when it last backed off
+type admissionState struct {
+	rate      int64
+	backedOff time.Time
+}
+
+// NewAdmissionController creates a new admission controller
+func NewAdmissionController(cfg *AdmissionControlConfig) *AdmissionController {
+	defaults := DefaultAdmissionControlConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.TargetSuccessRate <= 0 || cfg.TargetSuccessRate > 1 {
+		cfg.TargetSuccessRate = defaults.TargetSuccessRate
+	}
+	if cfg.TargetLatency <= 0 {
+		cfg.TargetLatency = defaults.TargetLatency
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaults.MinSamples
+	}
+	if cfg.Backoff <= 0 || cfg.Backoff >= 1 {
+		cfg.Backoff = defaults.Backoff
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaults.Cooldown
+	}
+
+	return &AdmissionController{
+		config: cfg,
+		events: make(map[string]*admissionState),
+		now:    time.Now,
+	}
+}
+
+// Next returns the users to admit per release interval for an event whose
+// max concurrent bookings is ceiling, given its recent health
+func (c *AdmissionController) Next(eventID string, ceiling int64, health *repository.AdmissionHealth, soldOut bool) (int64, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state(eventID, ceiling)
+	reason := c.degradation(ceiling, health)
+	switch {
+	case soldOut:
+		state.rate, reason = 0, AdmissionReasonSoldOut
+	case reason != "":
+		now := c.now()
+		if now.Sub(state.backedOff) < c.config.Cooldown {
+			break // Still backing off from the last decrease
+		}
+		state.backedOff = now
+		state.rate = int64(float64(state.rate) * c.config.Backoff)
+		if state.rate < 1 {
+			state.rate = 1 // Keep admitting a trickle so recovery shows in the health
+		}
+	default:
+		step := ceiling / 10
+		if step < 1 {
+			step = 1
+		}
+		state.rate += step
+		if state.rate > ceiling {
+			state.rate = ceiling
+		}
+		reason = AdmissionReasonHealthy
+	}
+	return state.rate, reason
+}
+
+// Hold returns an event's current rate unchanged, for ticks where its health
+// could not be read
+func (c *AdmissionController) Hold(eventID string, ceiling int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state(eventID, ceiling).rate
+}
+
+// state returns an event's admission state; a new event, or one whose
+// ceiling was lowered below its rate, starts at the ceiling
+func (c *AdmissionController) state(eventID string, ceiling int64) *admissionState {
+	state, ok := c.events[eventID]
+	if !ok {
+		state = &admissionState{rate: ceiling}
+		c.events[eventID] = state
+	}
+	if state.rate > ceiling {
+		state.rate = ceiling
+	}
+	return state
+}
+
+// degradation returns why the booking flow counts as degraded, or "" when it keeps up
+func (c *AdmissionController) degradation(ceiling int64, health *repository.AdmissionHealth) string {
+	maxBacklog := c.config.MaxPaymentBacklog
+	if maxBacklog <= 0 {
+		maxBacklog = ceiling
+	}
+	if health.PaymentBacklog() > maxBacklog {
+		return AdmissionReasonPaymentBacklog
+	}
+
+	if health.Attempts() < c.config.MinSamples {
+		return ""
+	}
+	if health.SuccessRate() < c.config.TargetSuccessRate {
+		return AdmissionReasonFailures
+	}
+	if health.AvgLatency() > c.config.TargetLatency {
+		return AdmissionReasonLatency
+	}
+	return ""
+}
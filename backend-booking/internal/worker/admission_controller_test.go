@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+)
+
+func TestAdmissionController_Next(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	controller := NewAdmissionController(nil)
+	controller.now = func() time.Time { return now }
+
+	healthy := &repository.AdmissionHealth{Reserved: 100, Confirmed: 90, Latency: 100 * 20 * time.Millisecond}
+	slow := &repository.AdmissionHealth{Reserved: 100, Confirmed: 90, Latency: 100 * time.Second}
+	failing := &repository.AdmissionHealth{Reserved: 50, Failed: 50, Confirmed: 50}
+
+	next := func(health *repository.AdmissionHealth, wantRate int64, wantReason string) {
+		t.Helper()
+		rate, reason := controller.Next("event-1", 100, health, false)
+		if rate != wantRate || reason != wantReason {
+			t.Fatalf("Next() = %d, %q, want %d, %q", rate, reason, wantRate, wantReason)
+		}
+	}
+
+	// A healthy event admits at its ceiling, as the static release did
+	next(healthy, 100, AdmissionReasonHealthy)
+
+	// Slow reservations halve the rate, once per cooldown
+	next(slow, 50, AdmissionReasonLatency)
+	next(slow, 50, AdmissionReasonLatency)
+	now = now.Add(10 * time.Second)
+	next(failing, 25, AdmissionReasonFailures)
+
+	// Recovery adds a tenth of the ceiling per tick
+	next(healthy, 35, AdmissionReasonHealthy)
+	next(healthy, 45, AdmissionReasonHealthy)
+
+	// Nothing is released while sold out
+	if rate, reason := controller.Next("event-1", 100, healthy, true); rate != 0 || reason != AdmissionReasonSoldOut {
+		t.Fatalf("Next() sold out = %d, %q, want 0, %q", rate, reason, AdmissionReasonSoldOut)
+	}
+	next(healthy, 10, AdmissionReasonHealthy)
+
+	// Events are controlled independently and follow a lowered ceiling
+	if rate, _ := controller.Next("event-2", 100, healthy, false); rate != 100 {
+		t.Errorf("Next() other event = %d, want 100", rate)
+	}
+	if rate := controller.Hold("event-2", 40); rate != 40 {
+		t.Errorf("Hold() lowered ceiling = %d, want 40", rate)
+	}
+}
+
+func TestAdmissionController_Degradation(t *testing.T) {
+	controller := NewAdmissionController(&AdmissionControlConfig{MaxPaymentBacklog: 30})
+
+	tests := []struct {
+		name   string
+		health *repository.AdmissionHealth
+		want   string
+	}{
+		{"healthy", &repository.AdmissionHealth{Reserved: 40, Confirmed: 20}, ""},
+		{"payments behind", &repository.AdmissionHealth{Reserved: 60, Confirmed: 20}, AdmissionReasonPaymentBacklog},
+		{"failures", &repository.AdmissionHealth{Reserved: 90, Failed: 10, Confirmed: 90}, AdmissionReasonFailures},
+		{"latency", &repository.AdmissionHealth{Reserved: 30, Confirmed: 30, Latency: 30 * time.Second}, AdmissionReasonLatency},
+		// Too few reservations to judge failures or latency
+		{"few samples", &repository.AdmissionHealth{Failed: 5, Latency: 5 * time.Second}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := controller.degradation(100, tt.health); got != tt.want {
+				t.Errorf("degradation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
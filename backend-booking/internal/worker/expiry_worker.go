@@ -2,32 +2,66 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 )
 
 // ExpiryWorkerConfig contains configuration for the expiry worker
 type ExpiryWorkerConfig struct {
-	// ScanInterval is the interval between scanning for expired reservations
+	// ScanInterval is the interval between scanning the database for expired
+	// reservations the Redis path missed
 	ScanInterval time.Duration
 	// BatchSize is the number of reservations to process in each scan
 	BatchSize int
+	// DeadlineInterval is the interval between sweeps of the Redis deadline index
+	DeadlineInterval time.Duration
+	// ConsumerGroup is the expiry stream consumer group shared by all replicas
+	ConsumerGroup string
+	// ConsumerName identifies this replica within the consumer group
+	ConsumerName string
+	// ClaimMinIdle is how long an unacknowledged stream entry stays with a
+	// consumer before another replica takes it over
+	ClaimMinIdle time.Duration
+	// StreamBlock is how long a stream read waits for new entries
+	StreamBlock time.Duration
 }
 
 // DefaultExpiryWorkerConfig returns default configuration
 func DefaultExpiryWorkerConfig() *ExpiryWorkerConfig {
 	return &ExpiryWorkerConfig{
-		ScanInterval: 5 * time.Second, // Scan every 5 seconds
-		BatchSize:    100,
+		ScanInterval:     5 * time.Second, // Scan every 5 seconds
+		BatchSize:        100,
+		DeadlineInterval: time.Second,
+		ConsumerGroup:    "booking-expiry",
+		ConsumerName:     defaultConsumerName(),
+		ClaimMinIdle:     time.Minute,
+		StreamBlock:      2 * time.Second,
 	}
 }
 
-// ExpiryWorker scans and expires stale reservations
+// defaultConsumerName names the consumer after the host, which is unique per pod
+func defaultConsumerName() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "booking-" + uuid.New().String()
+}
+
+// ExpiryWorker expires stale reservations. Every replica sweeps the Redis
+// deadline index, which returns each due reservation's seats exactly once and
+// publishes it to a stream; the replicas share a consumer group on that stream
+// to mark the bookings expired in the database. A slower database scan catches
+// bookings whose stream entry was lost; with WithLeadership only the elected
+// replica runs it.
 type ExpiryWorker struct {
 	bookingRepo       *repository.PostgresBookingRepository
 	transactionalRepo *repository.TransactionalBookingRepository
@@ -38,6 +72,7 @@ type ExpiryWorker struct {
 	wg                sync.WaitGroup
 	mu                sync.Mutex
 	running           bool
+	leadership        lease.Leadership // Gates the database scan (nil = always scan)
 
 	// Stats
 	totalExpired     int64
@@ -53,8 +88,24 @@ func NewExpiryWorker(
 	reservationRepo *repository.RedisReservationRepository,
 	config *ExpiryWorkerConfig,
 ) *ExpiryWorker {
+	defaults := DefaultExpiryWorkerConfig()
 	if config == nil {
-		config = DefaultExpiryWorkerConfig()
+		config = defaults
+	}
+	if config.DeadlineInterval <= 0 {
+		config.DeadlineInterval = defaults.DeadlineInterval
+	}
+	if config.ConsumerGroup == "" {
+		config.ConsumerGroup = defaults.ConsumerGroup
+	}
+	if config.ConsumerName == "" {
+		config.ConsumerName = defaults.ConsumerName
+	}
+	if config.ClaimMinIdle <= 0 {
+		config.ClaimMinIdle = defaults.ClaimMinIdle
+	}
+	if config.StreamBlock <= 0 {
+		config.StreamBlock = defaults.StreamBlock
 	}
 
 	return &ExpiryWorker{
@@ -67,6 +118,13 @@ func NewExpiryWorker(
 	}
 }
 
+// WithLeadership runs the database scan only while leadership reports this
+// replica leads, so replicas do not scan and expire the same bookings at once
+func (w *ExpiryWorker) WithLeadership(leadership lease.Leadership) *ExpiryWorker {
+	w.leadership = leadership
+	return w
+}
+
 // Start starts the expiry worker
 func (w *ExpiryWorker) Start(ctx context.Context) error {
 	w.mu.Lock()
@@ -74,13 +132,22 @@ func (w *ExpiryWorker) Start(ctx context.Context) error {
 		w.mu.Unlock()
 		return fmt.Errorf("expiry worker already running")
 	}
+	w.mu.Unlock()
+
+	if err := w.reservationRepo.EnsureExpiryGroup(ctx, w.config.ConsumerGroup); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
 	w.running = true
 	w.mu.Unlock()
 
-	w.log.Info("Starting expiry worker")
+	w.log.Info(fmt.Sprintf("Starting expiry worker (consumer %s)", w.config.ConsumerName))
 
-	// Start scanner goroutine
-	w.wg.Add(1)
+	// Start deadline sweeper, stream consumer and database scanner goroutines
+	w.wg.Add(3)
+	go w.sweepDeadlines(ctx)
+	go w.consumeExpired(ctx)
 	go w.scanExpiredReservations(ctx)
 
 	return nil
@@ -102,6 +169,118 @@ func (w *ExpiryWorker) Stop() {
 	w.log.Info("Expiry worker stopped")
 }
 
+// sweepDeadlines periodically returns the seats of due reservations
+func (w *ExpiryWorker) sweepDeadlines(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.DeadlineInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			released, err := w.reservationRepo.ExpireDueReservations(ctx, time.Now(), w.config.BatchSize)
+			if err != nil {
+				w.log.Error(fmt.Sprintf("Failed to sweep reservation deadlines: %v", err))
+			}
+			if released > 0 {
+				w.mu.Lock()
+				w.totalReleased += int64(released)
+				w.mu.Unlock()
+			}
+		}
+	}
+}
+
+// consumeExpired marks the bookings of expired reservations as expired. It
+// first takes over entries another replica left unacknowledged, then reads
+// new ones; an entry is only acknowledged once its booking is settled.
+func (w *ExpiryWorker) consumeExpired(ctx context.Context) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopCh:
+			return
+		default:
+		}
+
+		expired, err := w.reservationRepo.ClaimExpiredReservations(ctx, w.config.ConsumerGroup, w.config.ConsumerName, w.config.ClaimMinIdle, w.config.BatchSize)
+		if err == nil && len(expired) == 0 {
+			expired, err = w.reservationRepo.ReadExpiredReservations(ctx, w.config.ConsumerGroup, w.config.ConsumerName, w.config.BatchSize, w.config.StreamBlock)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			w.log.Error(fmt.Sprintf("Failed to read expired reservations: %v", err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.stopCh:
+				return
+			case <-time.After(w.config.StreamBlock):
+			}
+			continue
+		}
+
+		var acked []string
+		for _, reservation := range expired {
+			if err := w.expireStreamedBooking(ctx, reservation); err != nil {
+				w.log.Error(fmt.Sprintf("Failed to expire booking %s: %v", reservation.BookingID, err))
+				continue // Left pending, so it is claimed again after ClaimMinIdle
+			}
+			acked = append(acked, reservation.StreamID)
+		}
+		if len(acked) > 0 {
+			if err := w.reservationRepo.AckExpiredReservations(ctx, w.config.ConsumerGroup, acked...); err != nil {
+				w.log.Error(fmt.Sprintf("Failed to ack expired reservations: %v", err))
+			}
+		}
+	}
+}
+
+// expireStreamedBooking marks the booking of a reservation whose seats the
+// deadline sweep returned as expired. Bookings no longer reserved (confirmed,
+// cancelled or already expired by another path) are left alone.
+func (w *ExpiryWorker) expireStreamedBooking(ctx context.Context, reservation repository.ExpiredReservation) error {
+	booking, err := w.bookingRepo.GetByID(ctx, reservation.BookingID)
+	if errors.Is(err, domain.ErrBookingNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if booking.Status != domain.BookingStatusReserved {
+		return nil
+	}
+
+	booking.Status = domain.BookingStatusExpired
+	booking.StatusReason = "Reservation TTL expired"
+	booking.UpdatedAt = time.Now()
+
+	err = w.transactionalRepo.MarkAsExpiredWithOutbox(ctx, booking.ID, booking)
+	if errors.Is(err, domain.ErrBookingNotFound) {
+		return nil // Changed status since it was read
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark booking as expired in DB: %w", err)
+	}
+
+	w.mu.Lock()
+	w.totalExpired++
+	w.mu.Unlock()
+	w.log.Info(fmt.Sprintf("Successfully expired booking %s (user: %s, event: %s, zone: %s, qty: %d)",
+		booking.ID, booking.UserID, booking.EventID, booking.ZoneID, booking.Quantity))
+	return nil
+}
+
 // scanExpiredReservations periodically scans for expired reservations
 func (w *ExpiryWorker) scanExpiredReservations(ctx context.Context) {
 	defer w.wg.Done()
@@ -126,6 +305,9 @@ func (w *ExpiryWorker) scanExpiredReservations(ctx context.Context) {
 
 // processExpiredReservations fetches and processes expired reservations
 func (w *ExpiryWorker) processExpiredReservations(ctx context.Context) {
+	if w.leadership != nil && !w.leadership.IsLeader() {
+		return
+	}
 	w.lastScanTime = time.Now()
 
 	// Fetch expired reservations from PostgreSQL
@@ -144,27 +326,34 @@ func (w *ExpiryWorker) processExpiredReservations(ctx context.Context) {
 
 	for _, booking := range expired {
 		if err := w.expireBooking(ctx, booking); err != nil {
+			if errors.Is(err, domain.ErrBookingNotFound) {
+				continue // Expired by the stream consumer meanwhile
+			}
 			w.log.Error(fmt.Sprintf("Failed to expire booking %s: %v", booking.ID, err))
 			continue
 		}
+		w.mu.Lock()
 		w.totalExpired++
+		w.mu.Unlock()
 	}
 }
 
 // expireBooking expires a single booking
 func (w *ExpiryWorker) expireBooking(ctx context.Context, booking *domain.Booking) error {
 	// 1. Release seats back to Redis inventory
-	releaseResult, err := w.reservationRepo.ReleaseSeats(ctx, booking.ID, booking.UserID)
+	releaseResult, err := w.reservationRepo.ReleaseSeats(ctx, booking.ID, booking.ZoneID, booking.UserID)
 	if err != nil {
 		// Log error but continue - Redis reservation might have already expired
 		w.log.Warn(fmt.Sprintf("Failed to release seats from Redis for booking %s: %v", booking.ID, err))
 	} else if releaseResult.Success {
+		w.mu.Lock()
 		w.totalReleased++
+		w.mu.Unlock()
 		w.log.Info(fmt.Sprintf("Released %d seats for booking %s, new availability: %d",
 			booking.Quantity, booking.ID, releaseResult.AvailableSeats))
 	} else if releaseResult.ErrorCode == "RESERVATION_NOT_FOUND" {
-		// Redis reservation already expired via TTL - this is expected
-		w.log.Debug(fmt.Sprintf("Redis reservation for booking %s already expired (TTL)", booking.ID))
+		// Seats already returned by the deadline sweep - this is expected
+		w.log.Debug(fmt.Sprintf("Redis reservation for booking %s already released", booking.ID))
 	} else {
 		w.log.Warn(fmt.Sprintf("Could not release seats for booking %s: %s - %s",
 			booking.ID, releaseResult.ErrorCode, releaseResult.ErrorMessage))
@@ -177,6 +366,9 @@ func (w *ExpiryWorker) expireBooking(ctx context.Context, booking *domain.Bookin
 	booking.UpdatedAt = time.Now()
 
 	if err := w.transactionalRepo.MarkAsExpiredWithOutbox(ctx, booking.ID, booking); err != nil {
+		if errors.Is(err, domain.ErrBookingNotFound) {
+			return err
+		}
 		return fmt.Errorf("failed to mark booking as expired in DB: %w", err)
 	}
 
@@ -17,6 +17,22 @@ func TestDefaultExpiryWorkerConfig(t *testing.T) {
 	}
 }
 
+func TestDefaultExpiryWorkerConfig_Stream(t *testing.T) {
+	config := DefaultExpiryWorkerConfig()
+
+	if config.DeadlineInterval != time.Second {
+		t.Errorf("DeadlineInterval = %v, want %v", config.DeadlineInterval, time.Second)
+	}
+
+	if config.ConsumerGroup != "booking-expiry" {
+		t.Errorf("ConsumerGroup = %v, want %v", config.ConsumerGroup, "booking-expiry")
+	}
+
+	if config.ConsumerName == "" {
+		t.Error("ConsumerName should not be empty")
+	}
+}
+
 func TestExpiryWorkerConfig_Custom(t *testing.T) {
 	config := &ExpiryWorkerConfig{
 		ScanInterval: 10 * time.Second,
@@ -79,6 +95,10 @@ func TestNewExpiryWorker_WithCustomConfig(t *testing.T) {
 	if worker.config.BatchSize != 200 {
 		t.Errorf("BatchSize = %v, want %v", worker.config.BatchSize, 200)
 	}
+
+	if worker.config.DeadlineInterval != time.Second || worker.config.ConsumerGroup != "booking-expiry" {
+		t.Errorf("unset stream fields = %v, %q, want defaults", worker.config.DeadlineInterval, worker.config.ConsumerGroup)
+	}
 }
 
 func TestExpiryWorkerStats(t *testing.T) {
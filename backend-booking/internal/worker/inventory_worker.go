@@ -2,17 +2,16 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
-	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 )
 
 // InventoryWorkerConfig holds configuration for the inventory worker
@@ -32,11 +31,11 @@ type ZoneInventoryDelta struct {
 
 // InventoryWorker consumes booking events and syncs inventory to PostgreSQL
 type InventoryWorker struct {
-	config   *InventoryWorkerConfig
-	consumer *kafka.Consumer
-	db       *database.PostgresDB
-	redis    *pkgredis.Client
-	log      *logger.Logger
+	config *InventoryWorkerConfig
+	runner kafka.RecordRunner
+	db     *database.PostgresDB
+	zones  repository.ReservationRepository
+	log    *logger.Logger
 
 	// Batch aggregation
 	mu     sync.Mutex
@@ -46,9 +45,9 @@ type InventoryWorker struct {
 // NewInventoryWorker creates a new inventory worker
 func NewInventoryWorker(
 	cfg *InventoryWorkerConfig,
-	consumer *kafka.Consumer,
+	runner kafka.RecordRunner,
 	db *database.PostgresDB,
-	redis *pkgredis.Client,
+	zones repository.ReservationRepository,
 	log *logger.Logger,
 ) *InventoryWorker {
 	if cfg.BatchInterval <= 0 {
@@ -59,16 +58,17 @@ func NewInventoryWorker(
 	}
 
 	return &InventoryWorker{
-		config:   cfg,
-		consumer: consumer,
-		db:       db,
-		redis:    redis,
-		log:      log,
-		deltas:   make(map[string]*ZoneInventoryDelta),
+		config: cfg,
+		runner: runner,
+		db:     db,
+		zones:  zones,
+		log:    log,
+		deltas: make(map[string]*ZoneInventoryDelta),
 	}
 }
 
-// Start begins consuming events and syncing inventory
+// Start begins consuming events and syncing inventory. It returns once the
+// runner has drained in-flight records and the remaining batch is flushed.
 func (w *InventoryWorker) Start(ctx context.Context) {
 	// Start batch flush ticker
 	ticker := time.NewTicker(w.config.BatchInterval)
@@ -77,12 +77,21 @@ func (w *InventoryWorker) Start(ctx context.Context) {
 	// Channel to trigger batch flush
 	flushCh := make(chan struct{}, 1)
 
-	// Start consumer loop
-	go w.consumeLoop(ctx, flushCh)
+	// Start runner
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		if err := w.runner.Run(ctx, func(_ context.Context, record *kafka.Record) error {
+			return w.handleRecord(record, flushCh)
+		}); err != nil && ctx.Err() == nil {
+			w.log.Error(fmt.Sprintf("Inventory runner stopped: %v", err))
+		}
+	}()
 
 	for {
 		select {
 		case <-ctx.Done():
+			<-runDone
 			w.log.Info("Inventory worker context cancelled, flushing remaining batch...")
 			w.flushBatch(context.Background())
 			return
@@ -94,62 +103,30 @@ func (w *InventoryWorker) Start(ctx context.Context) {
 	}
 }
 
-// consumeLoop continuously polls for new events
-func (w *InventoryWorker) consumeLoop(ctx context.Context, flushCh chan<- struct{}) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			records, err := w.consumer.Poll(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				w.log.Error(fmt.Sprintf("Failed to poll Kafka: %v", err))
-				time.Sleep(time.Second)
-				continue
-			}
-
-			if len(records) == 0 {
-				continue
-			}
-
-			w.processRecords(ctx, records)
-
-			// Commit offsets after processing
-			if err := w.consumer.CommitRecords(ctx, records); err != nil {
-				w.log.Error(fmt.Sprintf("Failed to commit offsets: %v", err))
-			}
-
-			// Check if batch size exceeded
-			w.mu.Lock()
-			batchSize := len(w.deltas)
-			w.mu.Unlock()
-
-			if batchSize >= w.config.MaxBatchSize {
-				select {
-				case flushCh <- struct{}{}:
-				default:
-				}
-			}
-		}
+// handleRecord aggregates a record and asks for a flush once the batch is full
+func (w *InventoryWorker) handleRecord(record *kafka.Record, flushCh chan<- struct{}) error {
+	if err := w.processRecord(record); err != nil {
+		return err
 	}
-}
 
-// processRecords processes a batch of Kafka records
-func (w *InventoryWorker) processRecords(_ context.Context, records []*kafka.Record) {
-	for _, record := range records {
-		if err := w.processRecord(record); err != nil {
-			w.log.Error(fmt.Sprintf("Failed to process record: %v", err))
+	// Check if batch size exceeded
+	w.mu.Lock()
+	batchSize := len(w.deltas)
+	w.mu.Unlock()
+
+	if batchSize >= w.config.MaxBatchSize {
+		select {
+		case flushCh <- struct{}{}:
+		default:
 		}
 	}
+	return nil
 }
 
 // processRecord processes a single Kafka record
 func (w *InventoryWorker) processRecord(record *kafka.Record) error {
 	var event domain.BookingEvent
-	if err := json.Unmarshal(record.Value, &event); err != nil {
+	if err := record.Decode(&event); err != nil {
 		return fmt.Errorf("failed to unmarshal booking event: %w", err)
 	}
 
@@ -163,6 +140,12 @@ func (w *InventoryWorker) processRecord(record *kafka.Record) error {
 
 // aggregateDelta aggregates the inventory delta for a zone
 func (w *InventoryWorker) aggregateDelta(event *domain.BookingEvent) {
+	// Comp tickets are issued from the zone's held or comp allocation, which
+	// the ticket service moves to sold itself; they never reserved seats
+	if event.EventType == domain.BookingEventConfirmed && event.BookingData.StatusReason == domain.BookingReasonComp {
+		return
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -306,9 +289,8 @@ func (w *InventoryWorker) RebuildRedisFromDB(ctx context.Context) error {
 		}
 
 		// Set zone availability in Redis
-		key := fmt.Sprintf("zone:availability:%s", zoneID)
-		if err := w.redis.Set(ctx, key, availableSeats, 0).Err(); err != nil {
-			w.log.Error(fmt.Sprintf("Failed to set Redis key %s: %v", key, err))
+		if err := w.zones.SetZoneAvailability(ctx, zoneID, availableSeats); err != nil {
+			w.log.Error(fmt.Sprintf("Failed to set Redis availability for zone %s: %v", zoneID, err))
 			continue
 		}
 
@@ -77,6 +77,31 @@ func TestAggregateDelta_BookingConfirmed(t *testing.T) {
 	}
 }
 
+func TestAggregateDelta_CompBookingConfirmed(t *testing.T) {
+	worker := &InventoryWorker{
+		config: &InventoryWorkerConfig{
+			BatchInterval: 5 * time.Second,
+			MaxBatchSize:  100,
+		},
+		deltas: make(map[string]*ZoneInventoryDelta),
+	}
+
+	event := &domain.BookingEvent{
+		EventType: domain.BookingEventConfirmed,
+		BookingData: &domain.BookingEventData{
+			ZoneID:       "zone-1",
+			Quantity:     2,
+			StatusReason: domain.BookingReasonComp,
+		},
+	}
+
+	worker.aggregateDelta(event)
+
+	if len(worker.deltas) != 0 {
+		t.Errorf("Expected no delta for a comp booking, got %+v", worker.deltas["zone-1"])
+	}
+}
+
 func TestAggregateDelta_BookingCancelled(t *testing.T) {
 	worker := &InventoryWorker{
 		config: &InventoryWorkerConfig{
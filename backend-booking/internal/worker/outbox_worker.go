@@ -40,7 +40,7 @@ func DefaultOutboxWorkerConfig() *OutboxWorkerConfig {
 // OutboxWorker polls the outbox table and publishes messages to Kafka
 type OutboxWorker struct {
 	outboxRepo *repository.PostgresOutboxRepository
-	producer   *kafka.Producer
+	producer   kafka.MessageProducer
 	config     *OutboxWorkerConfig
 	log        *logger.Logger
 	stopCh     chan struct{}
@@ -52,7 +52,7 @@ type OutboxWorker struct {
 // NewOutboxWorker creates a new outbox worker
 func NewOutboxWorker(
 	outboxRepo *repository.PostgresOutboxRepository,
-	producer *kafka.Producer,
+	producer kafka.MessageProducer,
 	config *OutboxWorkerConfig,
 ) *OutboxWorker {
 	if config == nil {
@@ -225,11 +225,11 @@ func (w *OutboxWorker) publishMessage(ctx context.Context, msg *domain.OutboxMes
 		Key:   []byte(msg.PartitionKey),
 		Value: msg.Payload,
 		Headers: map[string]string{
-			"event_type":     msg.EventType,
-			"aggregate_type": msg.AggregateType,
-			"aggregate_id":   msg.AggregateID,
-			"content_type":   "application/json",
-			"source":         "outbox-worker",
+			"event_type":            msg.EventType,
+			"aggregate_type":        msg.AggregateType,
+			"aggregate_id":          msg.AggregateID,
+			kafka.HeaderContentType: kafka.ContentTypeJSON,
+			"source":                "outbox-worker",
 		},
 		Timestamp: time.Now(),
 	}
@@ -5,13 +5,16 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/metrics"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 )
@@ -73,6 +76,33 @@ type QueueReleaseWorker struct {
 	configCacheMu   sync.RWMutex
 	configCacheTTL  time.Duration
 	configCacheTime map[string]time.Time
+
+	// Adaptive release rate (nil = release every free booking slot)
+	admission       *AdmissionController
+	admissionHealth repository.AdmissionHealthRepository
+	zoneSeats       ZoneSeatReader
+
+	// Priority lanes' shares carried between batches, in hundredths of a user
+	laneCreditMu sync.Mutex
+	laneCredit   map[laneCreditKey]int64
+
+	// Per-event leases splitting queues between replicas (nil = release
+	// every queue). heldLeases is only used by the Start goroutine.
+	leases      *lease.Manager
+	leaseHolder string
+	leaseTTL    time.Duration
+	heldLeases  map[string]*lease.Lease
+}
+
+// laneCreditKey identifies a priority lane of an event
+type laneCreditKey struct {
+	eventID, lane string
+}
+
+// laneBatch is the users popped from one lane of an event's queue
+type laneBatch struct {
+	lane    string // As recorded for wait estimates ("" = the event has no lanes)
+	userIDs []string
 }
 
 // NewQueueReleaseWorker creates a new queue release worker
@@ -106,9 +136,44 @@ func NewQueueReleaseWorker(
 		configCache:     make(map[string]*repository.EventQueueConfig),
 		configCacheTTL:  30 * time.Second, // Cache config for 30 seconds
 		configCacheTime: make(map[string]time.Time),
+		laneCredit:      make(map[laneCreditKey]int64),
 	}
 }
 
+// WithAdmissionControl adapts each event's release rate to the health of its
+// booking flow: reservation success rate and latency and payments reported
+// to health, and the live seats of the zones the event sold from. Without
+// it every free booking slot is filled on each tick. Releases are recorded
+// to health as well, for the queue service's wait estimates.
+func (w *QueueReleaseWorker) WithAdmissionControl(health repository.AdmissionHealthRepository, zoneSeats ZoneSeatReader, cfg *AdmissionControlConfig) *QueueReleaseWorker {
+	w.admission = NewAdmissionController(cfg)
+	w.admissionHealth = health
+	w.zoneSeats = zoneSeats
+	return w
+}
+
+// WithLeases lets several replicas release from the queues at once. Each
+// queue is released by the replica holding its lease, which it extends on
+// every tick and gives up when it stops; a replica that stalls loses its
+// queues to the others after ttl. Queues stay with one replica while it
+// runs, so its lane credit and admission rate carry over between batches.
+func (w *QueueReleaseWorker) WithLeases(manager *lease.Manager, holder string, ttl time.Duration) *QueueReleaseWorker {
+	if holder == "" {
+		holder = lease.DefaultHolder()
+	}
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+	if ttl < 3*w.config.ReleaseInterval {
+		ttl = 3 * w.config.ReleaseInterval
+	}
+	w.leases = manager
+	w.leaseHolder = holder
+	w.leaseTTL = ttl
+	w.heldLeases = make(map[string]*lease.Lease)
+	return w
+}
+
 // Start begins the continuous queue release process
 func (w *QueueReleaseWorker) Start(ctx context.Context) {
 	ticker := time.NewTicker(w.config.ReleaseInterval)
@@ -121,6 +186,7 @@ func (w *QueueReleaseWorker) Start(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			w.log.Info("Queue release worker stopping...")
+			w.releaseLeases()
 			return
 		case <-ticker.C:
 			w.processAllQueues(ctx)
@@ -138,6 +204,7 @@ func (w *QueueReleaseWorker) processAllQueues(ctx context.Context) {
 	}
 
 	if len(eventIDs) == 0 {
+		w.forgetLeases(nil)
 		return
 	}
 
@@ -147,18 +214,130 @@ func (w *QueueReleaseWorker) processAllQueues(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		default:
-			w.releaseFromQueue(ctx, eventID)
+			if w.claimQueue(ctx, eventID) {
+				w.releaseFromQueue(ctx, eventID)
+			}
+		}
+	}
+	w.forgetLeases(eventIDs)
+}
+
+// queueReleaseLeaseName names the lease on releasing from an event's queue
+func queueReleaseLeaseName(eventID string) string {
+	return "queue-release:" + eventID
+}
+
+// claimQueue takes or extends the lease on an event's queue, reporting
+// whether this replica should release from it
+func (w *QueueReleaseWorker) claimQueue(ctx context.Context, eventID string) bool {
+	if w.leases == nil {
+		return true
+	}
+
+	held, err := w.leases.Acquire(ctx, queueReleaseLeaseName(eventID), w.leaseHolder, w.leaseTTL)
+	if err != nil {
+		if _, ok := w.heldLeases[eventID]; ok {
+			w.log.Warn(fmt.Sprintf("Lost lease on queue %s: %v", eventID, err))
+		} else if !errors.Is(err, lease.ErrHeld) {
+			w.log.Error(fmt.Sprintf("Failed to claim queue %s: %v", eventID, err))
 		}
+		delete(w.heldLeases, eventID)
+		return false
+	}
+
+	if previous, ok := w.heldLeases[eventID]; !ok || previous.Token != held.Token {
+		w.log.Info(fmt.Sprintf("Claimed queue %s (fencing token %d)", eventID, held.Token))
+	}
+	w.heldLeases[eventID] = held
+	return true
+}
+
+// holdsQueue reports whether this replica still holds the lease on an
+// event's queue under the fencing token it claimed it with
+func (w *QueueReleaseWorker) holdsQueue(ctx context.Context, eventID string) bool {
+	if w.leases == nil {
+		return true
+	}
+
+	held, ok := w.heldLeases[eventID]
+	if !ok || !held.Valid(time.Now()) {
+		delete(w.heldLeases, eventID)
+		return false
+	}
+	if err := w.leases.Check(ctx, held); err != nil {
+		if errors.Is(err, lease.ErrLost) {
+			w.log.Warn(fmt.Sprintf("Lost lease on queue %s (fencing token %d) before releasing", eventID, held.Token))
+			delete(w.heldLeases, eventID)
+		} else {
+			w.log.Error(fmt.Sprintf("Failed to check lease on queue %s: %v", eventID, err))
+		}
+		return false
+	}
+	return true
+}
+
+// forgetLeases drops the leases of queues that are gone; they expire on their own
+func (w *QueueReleaseWorker) forgetLeases(eventIDs []string) {
+	if w.leases == nil || len(w.heldLeases) == 0 {
+		return
+	}
+	active := make(map[string]bool, len(eventIDs))
+	for _, eventID := range eventIDs {
+		active[eventID] = true
+	}
+	for eventID := range w.heldLeases {
+		if !active[eventID] {
+			delete(w.heldLeases, eventID)
+		}
+	}
+}
+
+// releaseLeases gives up every queue lease, so the other replicas take the
+// queues over at once instead of after the lease TTL
+func (w *QueueReleaseWorker) releaseLeases() {
+	if w.leases == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for eventID, held := range w.heldLeases {
+		if err := w.leases.Release(ctx, held); err != nil && !errors.Is(err, lease.ErrLost) {
+			w.log.Warn(fmt.Sprintf("Failed to release lease on queue %s: %v", eventID, err))
+		}
+		delete(w.heldLeases, eventID)
 	}
 }
 
 // releaseFromQueue releases users from a specific event queue using dynamic capacity
 func (w *QueueReleaseWorker) releaseFromQueue(ctx context.Context, eventID string) {
+	// Paused and drained queues release nobody
+	held, err := w.holdQueue(ctx, eventID)
+	if err != nil {
+		w.log.Error(fmt.Sprintf("Failed to check state of queue %s: %v", eventID, err))
+		return
+	}
+	if held {
+		return
+	}
+
 	// Get event queue config (cached)
 	config := w.getEventConfig(ctx, eventID)
 	maxConcurrent := config.MaxConcurrentBookings
 	queuePassTTL := time.Duration(config.QueuePassTTLMinutes) * time.Minute
 
+	// Lottery queues release nobody until their pool has been drawn
+	open, drawn, err := w.drawLottery(ctx, eventID, config)
+	if err != nil {
+		w.log.Error(fmt.Sprintf("Failed to draw lottery for queue %s: %v", eventID, err))
+		return
+	}
+	if !open {
+		return
+	}
+	if drawn > 0 {
+		w.log.Info(fmt.Sprintf("Drew lottery of %d users for queue %s", drawn, eventID))
+	}
+
 	// Count current active queue passes
 	activeCount, err := w.queueRepo.CountActiveQueuePasses(ctx, eventID)
 	if err != nil {
@@ -166,50 +345,72 @@ func (w *QueueReleaseWorker) releaseFromQueue(ctx context.Context, eventID strin
 		return
 	}
 
-	// Calculate how many users to release
+	// Calculate how many users to release: the free booking slots, no more
+	// than the admission rate allows
+	rate := w.admissionRate(ctx, eventID, config)
 	releaseCount := int64(maxConcurrent) - activeCount
+	if rate < releaseCount {
+		releaseCount = rate
+	}
 	if releaseCount <= 0 {
-		// At capacity, no need to release
+		// At capacity or admission paused, no need to release
+		return
+	}
+
+	// A replica that stalled past its lease TTL must not release alongside
+	// the queue's new holder. Popping is the last step that can be skipped
+	// without losing users, so the lease is checked right before it.
+	if !w.holdsQueue(ctx, eventID) {
 		return
 	}
 
-	// Pop users from queue
-	userIDs, err := w.queueRepo.PopUsersFromQueue(ctx, eventID, releaseCount)
+	// Pop users from queue, each priority lane its share
+	batches, err := w.popUsers(ctx, eventID, config, releaseCount)
 	if err != nil {
 		w.log.Error(fmt.Sprintf("Failed to pop users from queue %s: %v", eventID, err))
 		return
 	}
 
-	if len(userIDs) == 0 {
+	popped := 0
+	for _, batch := range batches {
+		popped += len(batch.userIDs)
+	}
+	if popped == 0 {
 		return
 	}
 
-	w.log.Info(fmt.Sprintf("Releasing %d users from queue %s (active: %d, max: %d)",
-		len(userIDs), eventID, activeCount, maxConcurrent))
+	w.log.Info(fmt.Sprintf("Releasing %d users from queue %s (active: %d, max: %d, rate: %d)",
+		popped, eventID, activeCount, maxConcurrent, rate))
 
 	// Generate and store queue passes for each user
 	releasedCount := 0
 	ttlSeconds := int(queuePassTTL.Seconds())
-	for _, userID := range userIDs {
-		queuePass, expiresAt, err := w.generateQueuePassWithTTL(userID, eventID, queuePassTTL)
-		if err != nil {
-			w.log.Error(fmt.Sprintf("Failed to generate queue pass for user %s: %v", userID, err))
-			continue
-		}
-
-		// Store queue pass in Redis
-		if err := w.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, ttlSeconds); err != nil {
-			w.log.Error(fmt.Sprintf("Failed to store queue pass for user %s: %v", userID, err))
-			continue
+	for _, batch := range batches {
+		released := 0
+		for _, userID := range batch.userIDs {
+			passID := generateUniqueID()
+			queuePass, expiresAt, err := w.generateQueuePassWithTTL(userID, eventID, passID, queuePassTTL)
+			if err != nil {
+				w.log.Error(fmt.Sprintf("Failed to generate queue pass for user %s: %v", userID, err))
+				continue
+			}
+
+			// Store queue pass in Redis
+			if err := w.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, config.PassBudget(passID), ttlSeconds); err != nil {
+				w.log.Error(fmt.Sprintf("Failed to store queue pass for user %s: %v", userID, err))
+				continue
+			}
+
+			// Publish queue pass ready notification via Pub/Sub
+			// This allows SSE clients to receive real-time updates without polling
+			w.publishQueuePassReady(ctx, eventID, userID, queuePass, expiresAt)
+
+			released++
+			w.log.Debug(fmt.Sprintf("Released user %s from queue %s with pass expiring at %v",
+				userID, eventID, expiresAt))
 		}
-
-		// Publish queue pass ready notification via Pub/Sub
-		// This allows SSE clients to receive real-time updates without polling
-		w.publishQueuePassReady(ctx, eventID, userID, queuePass, expiresAt)
-
-		releasedCount++
-		w.log.Debug(fmt.Sprintf("Released user %s from queue %s with pass expiring at %v",
-			userID, eventID, expiresAt))
+		w.recordRelease(ctx, eventID, batch.lane, released)
+		releasedCount += released
 	}
 
 	// Update metrics
@@ -221,8 +422,234 @@ func (w *QueueReleaseWorker) releaseFromQueue(ctx context.Context, eventID strin
 
 	if releasedCount > 0 {
 		w.log.Info(fmt.Sprintf("Successfully released %d/%d users from queue %s",
-			releasedCount, len(userIDs), eventID))
+			releasedCount, popped, eventID))
+	}
+}
+
+// holdQueue reports whether operators have paused or drained an event's
+// queue. The state is read on every tick, uncached, so a pause takes effect
+// at once. Users who joined a drained queue before every booking replica saw
+// the drain are drained here.
+func (w *QueueReleaseWorker) holdQueue(ctx context.Context, eventID string) (bool, error) {
+	control, err := w.queueRepo.GetQueueControl(ctx, eventID)
+	if err != nil {
+		return true, err
+	}
+	switch {
+	case control.IsPaused():
+		return true, nil
+	case control.IsDrained():
+		drained, err := w.queueRepo.DrainQueue(ctx, eventID)
+		if err != nil {
+			return true, err
+		}
+		if drained > 0 && w.log != nil {
+			w.log.Info(fmt.Sprintf("Drained %d late joins from closed queue %s", drained, eventID))
+		}
+		return true, nil
 	}
+	return false, nil
+}
+
+// drawLottery draws a lottery queue's pool into the queue once it opens. It
+// reports whether the queue may release users: a lottery queue does not
+// before it opens, so nobody gets ahead of the draw.
+func (w *QueueReleaseWorker) drawLottery(ctx context.Context, eventID string, config *repository.EventQueueConfig) (bool, int64, error) {
+	if !config.IsLottery() {
+		return true, 0, nil
+	}
+	if time.Now().Unix() < config.LotteryOpensAt {
+		return false, 0, nil
+	}
+
+	drawn, err := w.queueRepo.DrawLottery(ctx, eventID, config.LotteryOpensAt)
+	if errors.Is(err, domain.ErrQueueNotOpen) {
+		// Redis' clock is behind ours; draw on a later tick
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return true, drawn, nil
+}
+
+// admissionRate returns the users an event's queue may release this tick.
+// An admin override wins; otherwise the admission controller follows the
+// event's booking health. The rate is exported as a metric and stored for
+// the queue config admin API.
+func (w *QueueReleaseWorker) admissionRate(ctx context.Context, eventID string, config *repository.EventQueueConfig) int64 {
+	ceiling := int64(config.MaxConcurrentBookings)
+	rate, reason := ceiling, AdmissionReasonStatic
+
+	switch {
+	case config.ReleaseRateOverride > 0:
+		rate, reason = int64(config.ReleaseRateOverride), AdmissionReasonOverride
+	case w.admission != nil:
+		health, err := w.admissionHealth.GetAdmissionHealth(ctx, eventID, w.admission.config.Window)
+		if err != nil {
+			if w.log != nil {
+				w.log.Warn(fmt.Sprintf("Failed to read booking health for queue %s, holding release rate: %v", eventID, err))
+			}
+			rate, reason = w.admission.Hold(eventID, ceiling), AdmissionReasonUnknown
+			break
+		}
+		rate, reason = w.admission.Next(eventID, ceiling, health, w.soldOut(ctx, eventID, health.ZoneIDs))
+	}
+
+	metrics.RecordQueueReleaseRate(ctx, eventID, rate)
+	if w.admissionHealth != nil {
+		if err := w.admissionHealth.SetAdmissionRate(ctx, eventID, &repository.AdmissionRate{
+			Rate:      rate,
+			Reason:    reason,
+			UpdatedAt: time.Now(),
+		}); err != nil && w.log != nil {
+			w.log.Warn(fmt.Sprintf("Failed to store release rate for queue %s: %v", eventID, err))
+		}
+	}
+	return rate
+}
+
+// popUsers pops up to count users from an event's queue. Each priority lane
+// is given its share of the batch first and the general lane the rest; a
+// share a lane cannot fill goes to the general lane, and what the general
+// lane cannot fill goes back to the lanes in order. Lanes users joined
+// before they were removed from the config are drained from what is left,
+// and the lane of parked joins last of all.
+func (w *QueueReleaseWorker) popUsers(ctx context.Context, eventID string, config *repository.EventQueueConfig, count int64) ([]laneBatch, error) {
+	joined, err := w.queueRepo.GetQueueLanes(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Lanes) == 0 && len(joined) == 0 {
+		userIDs, err := w.queueRepo.PopUsersFromQueue(ctx, eventID, count)
+		if err != nil {
+			return nil, err
+		}
+		return []laneBatch{{userIDs: userIDs}}, nil
+	}
+
+	lanes := make([]string, 0, len(config.Lanes)+len(joined))
+	shares := make(map[string]int, cap(lanes))
+	for _, lane := range config.Lanes {
+		lanes = append(lanes, lane.Name)
+		shares[lane.Name] = lane.Share
+	}
+	parked := false
+	for _, lane := range joined {
+		if lane == domain.QueueLaneParked {
+			parked = true
+			continue
+		}
+		if _, ok := shares[lane]; !ok {
+			lanes = append(lanes, lane)
+			shares[lane] = 0
+		}
+	}
+	if parked {
+		lanes = append(lanes, domain.QueueLaneParked)
+		shares[domain.QueueLaneParked] = 0
+	}
+
+	popped := make(map[string][]string, len(lanes)+1)
+	remaining := count
+	pop := func(lane string, n int64) (int64, error) {
+		if n <= 0 {
+			return 0, nil
+		}
+		userIDs, err := w.queueRepo.PopUsersFromLane(ctx, eventID, lane, n)
+		if err != nil {
+			return 0, err
+		}
+		popped[lane] = append(popped[lane], userIDs...)
+		remaining -= int64(len(userIDs))
+		return int64(len(userIDs)), nil
+	}
+
+	for _, lane := range lanes {
+		quota := w.laneQuota(eventID, lane, count, shares[lane])
+		if quota > remaining {
+			quota = remaining
+		}
+		n, err := pop(lane, quota)
+		if err != nil {
+			return nil, err
+		}
+		if n < quota {
+			// A lane that runs dry does not bank its share
+			w.resetLaneCredit(eventID, lane)
+		}
+	}
+	if _, err := pop("", remaining); err != nil {
+		return nil, err
+	}
+	for _, lane := range lanes {
+		if _, err := pop(lane, remaining); err != nil {
+			return nil, err
+		}
+	}
+
+	batches := []laneBatch{{lane: domain.QueueLaneGeneral, userIDs: popped[""]}}
+	for _, lane := range lanes {
+		if len(popped[lane]) > 0 {
+			batches = append(batches, laneBatch{lane: lane, userIDs: popped[lane]})
+		}
+	}
+	return batches, nil
+}
+
+// laneQuota returns a lane's share of a batch of count users. The fraction
+// of a user it is owed carries over to later batches, so a lane gets its
+// share even when batches are small.
+func (w *QueueReleaseWorker) laneQuota(eventID, lane string, count int64, share int) int64 {
+	key := laneCreditKey{eventID: eventID, lane: lane}
+
+	w.laneCreditMu.Lock()
+	defer w.laneCreditMu.Unlock()
+	credit := w.laneCredit[key] + count*int64(share)
+	w.laneCredit[key] = credit % 100
+	return credit / 100
+}
+
+// resetLaneCredit drops the share a lane has been owed
+func (w *QueueReleaseWorker) resetLaneCredit(eventID, lane string) {
+	w.laneCreditMu.Lock()
+	delete(w.laneCredit, laneCreditKey{eventID: eventID, lane: lane})
+	w.laneCreditMu.Unlock()
+}
+
+// recordRelease records released users for the queue's wait estimates
+func (w *QueueReleaseWorker) recordRelease(ctx context.Context, eventID, lane string, count int) {
+	if w.admissionHealth == nil || count == 0 {
+		return
+	}
+	if err := w.admissionHealth.RecordRelease(ctx, eventID, lane, count); err != nil && w.log != nil {
+		w.log.Warn(fmt.Sprintf("Failed to record releases for queue %s: %v", eventID, err))
+	}
+}
+
+// soldOut reports whether every zone an event has sold from has no seats
+// left. Zones it has not sold from yet are not known to the queue, and an
+// event that has sold nothing is never sold out.
+func (w *QueueReleaseWorker) soldOut(ctx context.Context, eventID string, zoneIDs []string) bool {
+	if w.zoneSeats == nil || len(zoneIDs) == 0 {
+		return false
+	}
+	seats, err := w.zoneSeats.GetZoneAvailabilities(ctx, zoneIDs)
+	if err != nil {
+		if w.log != nil {
+			w.log.Warn(fmt.Sprintf("Failed to read zone availability for queue %s: %v", eventID, err))
+		}
+		return false
+	}
+	if len(seats) == 0 {
+		return false
+	}
+	for _, available := range seats {
+		if available > 0 {
+			return false
+		}
+	}
+	return true
 }
 
 // getEventConfig gets event queue config with caching
@@ -272,8 +699,9 @@ type QueuePassClaims struct {
 	jwt.RegisteredClaims
 }
 
-// generateQueuePassWithTTL generates a signed JWT queue pass token with custom TTL
-func (w *QueueReleaseWorker) generateQueuePassWithTTL(userID, eventID string, ttl time.Duration) (string, time.Time, error) {
+// generateQueuePassWithTTL generates a signed JWT queue pass token with ID
+// passID and a custom TTL
+func (w *QueueReleaseWorker) generateQueuePassWithTTL(userID, eventID, passID string, ttl time.Duration) (string, time.Time, error) {
 	now := time.Now()
 	expiresAt := now.Add(ttl)
 
@@ -287,7 +715,7 @@ func (w *QueueReleaseWorker) generateQueuePassWithTTL(userID, eventID string, tt
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "queue-release-worker",
 			Subject:   userID,
-			ID:        generateUniqueID(),
+			ID:        passID, // Binds the pass to its budget
 		},
 	}
 
@@ -302,7 +730,7 @@ func (w *QueueReleaseWorker) generateQueuePassWithTTL(userID, eventID string, tt
 
 // generateQueuePass generates a signed JWT queue pass token with default TTL
 func (w *QueueReleaseWorker) generateQueuePass(userID, eventID string) (string, time.Time, error) {
-	return w.generateQueuePassWithTTL(userID, eventID, w.config.DefaultQueuePassTTL)
+	return w.generateQueuePassWithTTL(userID, eventID, generateUniqueID(), w.config.DefaultQueuePassTTL)
 }
 
 // generateUniqueID generates a unique ID for JWT
@@ -323,11 +751,29 @@ func (w *QueueReleaseWorker) GetMetrics() (totalReleased int64, lastReleaseTime
 
 // ReleaseFromQueueOnce releases users from a specific queue using dynamic capacity (for testing)
 func (w *QueueReleaseWorker) ReleaseFromQueueOnce(ctx context.Context, eventID string) ([]ReleasedUser, error) {
+	// Paused and drained queues release nobody
+	held, err := w.holdQueue(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check queue state: %w", err)
+	}
+	if held {
+		return []ReleasedUser{}, nil
+	}
+
 	// Get event queue config (cached)
 	config := w.getEventConfig(ctx, eventID)
 	maxConcurrent := config.MaxConcurrentBookings
 	queuePassTTL := time.Duration(config.QueuePassTTLMinutes) * time.Minute
 
+	// Lottery queues release nobody until their pool has been drawn
+	open, _, err := w.drawLottery(ctx, eventID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw lottery: %w", err)
+	}
+	if !open {
+		return []ReleasedUser{}, nil
+	}
+
 	// Count current active queue passes
 	activeCount, err := w.queueRepo.CountActiveQueuePasses(ctx, eventID)
 	if err != nil {
@@ -336,38 +782,51 @@ func (w *QueueReleaseWorker) ReleaseFromQueueOnce(ctx context.Context, eventID s
 
 	// Calculate how many users to release
 	releaseCount := int64(maxConcurrent) - activeCount
+	if rate := w.admissionRate(ctx, eventID, config); rate < releaseCount {
+		releaseCount = rate
+	}
 	if releaseCount <= 0 {
 		return []ReleasedUser{}, nil // At capacity
 	}
 
-	// Pop users from queue
-	userIDs, err := w.queueRepo.PopUsersFromQueue(ctx, eventID, releaseCount)
+	// Pop users from queue, each priority lane its share
+	batches, err := w.popUsers(ctx, eventID, config, releaseCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to pop users from queue: %w", err)
 	}
 
-	if len(userIDs) == 0 {
+	popped := 0
+	for _, batch := range batches {
+		popped += len(batch.userIDs)
+	}
+	if popped == 0 {
 		return []ReleasedUser{}, nil
 	}
 
 	var releasedUsers []ReleasedUser
 	ttlSeconds := int(queuePassTTL.Seconds())
-	for _, userID := range userIDs {
-		queuePass, expiresAt, err := w.generateQueuePassWithTTL(userID, eventID, queuePassTTL)
-		if err != nil {
-			continue
+	for _, batch := range batches {
+		released := 0
+		for _, userID := range batch.userIDs {
+			passID := generateUniqueID()
+			queuePass, expiresAt, err := w.generateQueuePassWithTTL(userID, eventID, passID, queuePassTTL)
+			if err != nil {
+				continue
+			}
+
+			if err := w.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, config.PassBudget(passID), ttlSeconds); err != nil {
+				continue
+			}
+
+			releasedUsers = append(releasedUsers, ReleasedUser{
+				UserID:           userID,
+				EventID:          eventID,
+				QueuePass:        queuePass,
+				QueuePassExpires: expiresAt,
+			})
+			released++
 		}
-
-		if err := w.queueRepo.StoreQueuePass(ctx, eventID, userID, queuePass, ttlSeconds); err != nil {
-			continue
-		}
-
-		releasedUsers = append(releasedUsers, ReleasedUser{
-			UserID:           userID,
-			EventID:          eventID,
-			QueuePass:        queuePass,
-			QueuePassExpires: expiresAt,
-		})
+		w.recordRelease(ctx, eventID, batch.lane, released)
 	}
 
 	// Update metrics
@@ -402,6 +861,36 @@ func QueuePassChannelKey(eventID, userID string) string {
 	return fmt.Sprintf("queue:pass:%s:%s", eventID, userID)
 }
 
+// QueueBroadcastMessage is published to everyone waiting in an event's queue
+// when operators pause, resume or drain it
+type QueueBroadcastMessage struct {
+	EventID string `json:"event_id"`
+	State   string `json:"state"` // domain.QueueState*
+	Message string `json:"message,omitempty"`
+	SentAt  int64  `json:"sent_at"` // Unix timestamp
+}
+
+// QueueBroadcastChannelKey returns the Redis Pub/Sub channel key for messages
+// to everyone in an event's queue
+// Format: queue:broadcast:{event_id} (per-event channel)
+// SSE clients subscribe to it next to their per-user queue pass channel
+func QueueBroadcastChannelKey(eventID string) string {
+	return fmt.Sprintf("queue:broadcast:%s", eventID)
+}
+
+// PublishQueueBroadcast publishes a message to everyone in an event's queue
+// via Redis Pub/Sub
+func PublishQueueBroadcast(ctx context.Context, client *redis.Client, msg *QueueBroadcastMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue broadcast: %w", err)
+	}
+	if err := client.Publish(ctx, QueueBroadcastChannelKey(msg.EventID), data).Err(); err != nil {
+		return fmt.Errorf("failed to publish queue broadcast: %w", err)
+	}
+	return nil
+}
+
 // publishQueuePassReady publishes a queue pass ready notification via Redis Pub/Sub
 func (w *QueueReleaseWorker) publishQueuePassReady(ctx context.Context, eventID, userID, queuePass string, expiresAt time.Time) {
 	if w.redisClient == nil {
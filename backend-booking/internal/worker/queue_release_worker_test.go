@@ -6,7 +6,11 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -50,8 +54,8 @@ func (m *MockQueueRepository) GetUserQueueInfo(ctx context.Context, eventID, use
 	return args.Get(0).(map[string]string), args.Error(1)
 }
 
-func (m *MockQueueRepository) StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, ttl int) error {
-	args := m.Called(ctx, eventID, userID, queuePass, ttl)
+func (m *MockQueueRepository) StoreQueuePass(ctx context.Context, eventID, userID, queuePass string, budget repository.QueuePassBudget, ttl int) error {
+	args := m.Called(ctx, eventID, userID, queuePass, budget, ttl)
 	return args.Error(0)
 }
 
@@ -78,6 +82,22 @@ func (m *MockQueueRepository) PopUsersFromQueue(ctx context.Context, eventID str
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockQueueRepository) PopUsersFromLane(ctx context.Context, eventID, lane string, count int64) ([]string, error) {
+	args := m.Called(ctx, eventID, lane, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockQueueRepository) GetQueueLanes(ctx context.Context, eventID string) ([]string, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockQueueRepository) GetAllQueueEventIDs(ctx context.Context) ([]string, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -112,6 +132,34 @@ func (m *MockQueueRepository) SetEventQueueConfig(ctx context.Context, eventID s
 	return args.Error(0)
 }
 
+func (m *MockQueueRepository) DrawLottery(ctx context.Context, eventID string, opensAt int64) (int64, error) {
+	args := m.Called(ctx, eventID, opensAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQueueRepository) GetQueueControl(ctx context.Context, eventID string) (*repository.QueueControl, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.QueueControl), args.Error(1)
+}
+
+func (m *MockQueueRepository) SetQueueControl(ctx context.Context, eventID string, control *repository.QueueControl) error {
+	args := m.Called(ctx, eventID, control)
+	return args.Error(0)
+}
+
+func (m *MockQueueRepository) DrainQueue(ctx context.Context, eventID string) (int64, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQueueRepository) ExpireQueuePasses(ctx context.Context, eventID string) (int64, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // testWorkerJWTSecret is a constant secret used for testing only
 const testWorkerJWTSecret = "test-jwt-secret-for-worker-tests"
 
@@ -167,11 +215,13 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		userIDs := []string{"user-1", "user-2", "user-3"}
 
 		// Config not found, use defaults (500 max)
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 		// 100 active, so release 400 (but only 3 in queue)
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(100), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
 		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(400)).Return(userIDs, nil)
-		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), 300).Return(nil)
+		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
 
 		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -188,6 +238,77 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("releases nobody before a lottery opens", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{JWTSecret: "test-secret"}, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{
+			Mode:           domain.QueueModeLottery,
+			LotteryOpensAt: time.Now().Add(time.Hour).Unix(),
+		}, nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 0)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("draws an opened lottery before releasing", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{
+			DefaultMaxConcurrent: 2,
+			JWTSecret:            "test-secret",
+		}, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+		opensAt := time.Now().Add(-time.Second).Unix()
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{
+			Mode:           domain.QueueModeLottery,
+			LotteryOpensAt: opensAt,
+		}, nil)
+		mockRepo.On("DrawLottery", ctx, eventID, opensAt).Return(int64(5), nil)
+		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
+		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(2)).Return([]string{"user-4", "user-2"}, nil)
+		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("waits when Redis has not reached the opening", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{JWTSecret: "test-secret"}, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+		opensAt := time.Now().Unix()
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{
+			Mode:           domain.QueueModeLottery,
+			LotteryOpensAt: opensAt,
+		}, nil)
+		mockRepo.On("DrawLottery", ctx, eventID, opensAt).Return(int64(0), domain.ErrQueueNotOpen)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 0)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("returns empty when at capacity", func(t *testing.T) {
 		mockRepo := new(MockQueueRepository)
 		cfg := &QueueReleaseWorkerConfig{
@@ -200,6 +321,7 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		ctx := context.Background()
 		eventID := "event-123"
 
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 		// At capacity (500 active, 500 max)
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(500), nil)
@@ -224,8 +346,10 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		ctx := context.Background()
 		eventID := "event-123"
 
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
 		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(500)).Return([]string{}, nil)
 
 		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
@@ -248,6 +372,7 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 		ctx := context.Background()
 		eventID := "event-123"
 
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), assert.AnError)
 
@@ -276,12 +401,14 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 			MaxConcurrentBookings: 100,
 			QueuePassTTLMinutes:   10,
 		}
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
 		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(customConfig, nil)
 		// 50 active, so release 50
 		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(50), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
 		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(50)).Return([]string{"user-1"}, nil)
 		// TTL should be 10 min = 600 seconds
-		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), 600).Return(nil)
+		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 600).Return(nil)
 
 		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -290,6 +417,122 @@ func TestQueueReleaseWorker_ReleaseFromQueueOnce(t *testing.T) {
 
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("gives priority lanes their share of the batch", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		cfg := &QueueReleaseWorkerConfig{
+			DefaultMaxConcurrent: 500,
+			DefaultQueuePassTTL:  5 * time.Minute,
+			JWTSecret:            testWorkerJWTSecret,
+		}
+		worker := NewQueueReleaseWorker(cfg, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{
+			MaxConcurrentBookings: 10,
+			Lanes: []domain.QueueLane{
+				{Name: "vip", Share: 20, Roles: []string{"vip"}},
+				{Name: "access", Share: 10, Tenants: []string{"access"}},
+			},
+		}, nil)
+		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string{"vip"}, nil)
+		// Each lane its share first, then the general lane the rest
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "vip", int64(2)).Return([]string{"v1", "v2"}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "access", int64(1)).Return([]string{}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "", int64(8)).Return([]string{"g1", "g2", "g3"}, nil).Once()
+		// What the general lane cannot fill goes back to the lanes
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "vip", int64(5)).Return([]string{"v3"}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "access", int64(4)).Return([]string{}, nil).Once()
+		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		released := make([]string, len(releasedUsers))
+		for i, user := range releasedUsers {
+			released[i] = user.UserID
+		}
+		assert.ElementsMatch(t, []string{"v1", "v2", "v3", "g1", "g2", "g3"}, released)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("drains parked joins last", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		cfg := &QueueReleaseWorkerConfig{
+			DefaultMaxConcurrent: 500,
+			DefaultQueuePassTTL:  5 * time.Minute,
+			JWTSecret:            testWorkerJWTSecret,
+		}
+		worker := NewQueueReleaseWorker(cfg, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{
+			MaxConcurrentBookings: 10,
+			Lanes:                 []domain.QueueLane{{Name: "vip", Share: 20, Roles: []string{"vip"}}},
+		}, nil)
+		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string{domain.QueueLaneParked, "removed"}, nil)
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "vip", int64(2)).Return([]string{"v1", "v2"}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "", int64(8)).Return([]string{"g1"}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "vip", int64(7)).Return([]string{}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, "removed", int64(7)).Return([]string{"r1"}, nil).Once()
+		mockRepo.On("PopUsersFromLane", ctx, eventID, domain.QueueLaneParked, int64(6)).Return([]string{"p1"}, nil).Once()
+		mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 5)
+		var lanes []string
+		for _, call := range mockRepo.Calls {
+			if call.Method == "PopUsersFromLane" {
+				lanes = append(lanes, call.Arguments.String(2))
+			}
+		}
+		assert.Equal(t, domain.QueueLaneParked, lanes[len(lanes)-1])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("holds a paused queue", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{JWTSecret: testWorkerJWTSecret}, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+
+		// Nothing else is read: no lottery draw, no release
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(&repository.QueueControl{State: domain.QueueStatePaused}, nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, releasedUsers)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("drains late joins of a drained queue", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{JWTSecret: testWorkerJWTSecret}, mockRepo, nil, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(&repository.QueueControl{State: domain.QueueStateDrained}, nil)
+		mockRepo.On("DrainQueue", ctx, eventID).Return(int64(2), nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, releasedUsers)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestQueueReleaseWorker_GenerateQueuePass(t *testing.T) {
@@ -332,7 +575,7 @@ func TestQueueReleaseWorker_GenerateQueuePass(t *testing.T) {
 		}
 		worker := NewQueueReleaseWorker(cfg, mockRepo, nil, nil)
 
-		queuePass, expiresAt, err := worker.generateQueuePassWithTTL("user-123", "event-456", 10*time.Minute)
+		queuePass, expiresAt, err := worker.generateQueuePassWithTTL("user-123", "event-456", "pass-1", 10*time.Minute)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, queuePass)
@@ -360,10 +603,12 @@ func TestQueueReleaseWorker_GetMetrics(t *testing.T) {
 	eventID := "event-123"
 	userIDs := []string{"user-1", "user-2"}
 
+	mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
 	mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(nil, nil)
 	mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+	mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
 	mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(500)).Return(userIDs, nil)
-	mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), 300).Return(nil)
+	mockRepo.On("StoreQueuePass", ctx, eventID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
 
 	_, _ = worker.ReleaseFromQueueOnce(ctx, eventID)
 
@@ -392,3 +637,153 @@ func TestGenerateUniqueID(t *testing.T) {
 	assert.NotEqual(t, id1, id2) // Should be unique
 	assert.Len(t, id1, 32)       // 16 bytes = 32 hex chars
 }
+
+// staticZoneSeats returns fixed zone availability
+type staticZoneSeats map[string]int64
+
+func (s staticZoneSeats) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	seats := make(map[string]int64, len(zoneIDs))
+	for _, zoneID := range zoneIDs {
+		if available, ok := s[zoneID]; ok {
+			seats[zoneID] = available
+		}
+	}
+	return seats, nil
+}
+
+func TestQueueReleaseWorker_AdmissionControl(t *testing.T) {
+	newWorker := func(t *testing.T, mockRepo *MockQueueRepository, zoneSeats staticZoneSeats) (*QueueReleaseWorker, *repository.RedisAdmissionHealthRepository) {
+		client, _ := redistest.NewClient(t)
+		health := repository.NewRedisAdmissionHealthRepository(client)
+		worker := NewQueueReleaseWorker(&QueueReleaseWorkerConfig{JWTSecret: testWorkerJWTSecret}, mockRepo, nil, nil).
+			WithAdmissionControl(health, zoneSeats, nil)
+		return worker, health
+	}
+
+	t.Run("backs off while reservations fail", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker, health := newWorker(t, mockRepo, nil)
+
+		ctx := context.Background()
+		eventID := "event-123"
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, health.RecordReserve(ctx, eventID, "zone-1", i%2 == 0, 10*time.Millisecond))
+		}
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{MaxConcurrentBookings: 10}, nil)
+		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
+		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(5)).Return([]string{"user-1"}, nil)
+		mockRepo.On("StoreQueuePass", ctx, eventID, "user-1", mock.AnythingOfType("string"), mock.AnythingOfType("repository.QueuePassBudget"), 300).Return(nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 1)
+		rate, err := health.GetAdmissionRate(ctx, eventID)
+		assert.NoError(t, err)
+		assert.Equal(t, &repository.AdmissionRate{Rate: 5, Reason: AdmissionReasonFailures, UpdatedAt: rate.UpdatedAt}, rate)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops releasing once the event is sold out", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker, health := newWorker(t, mockRepo, staticZoneSeats{"zone-1": 0, "zone-2": 0})
+
+		ctx := context.Background()
+		eventID := "event-123"
+		assert.NoError(t, health.RecordReserve(ctx, eventID, "zone-1", true, time.Millisecond))
+		assert.NoError(t, health.RecordReserve(ctx, eventID, "zone-2", true, time.Millisecond))
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{MaxConcurrentBookings: 10}, nil)
+		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 0)
+		rate, _ := health.GetAdmissionRate(ctx, eventID)
+		assert.Equal(t, int64(0), rate.Rate)
+		assert.Equal(t, AdmissionReasonSoldOut, rate.Reason)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("an override fixes the rate", func(t *testing.T) {
+		mockRepo := new(MockQueueRepository)
+		worker, health := newWorker(t, mockRepo, staticZoneSeats{"zone-1": 0})
+
+		ctx := context.Background()
+		eventID := "event-123"
+		assert.NoError(t, health.RecordReserve(ctx, eventID, "zone-1", true, time.Millisecond))
+
+		mockRepo.On("GetQueueControl", ctx, eventID).Return(nil, nil)
+		mockRepo.On("GetEventQueueConfig", ctx, eventID).Return(&repository.EventQueueConfig{
+			MaxConcurrentBookings: 10,
+			ReleaseRateOverride:   2,
+		}, nil)
+		mockRepo.On("CountActiveQueuePasses", ctx, eventID).Return(int64(0), nil)
+		mockRepo.On("GetQueueLanes", ctx, eventID).Return([]string(nil), nil)
+		mockRepo.On("PopUsersFromQueue", ctx, eventID, int64(2)).Return([]string{}, nil)
+
+		releasedUsers, err := worker.ReleaseFromQueueOnce(ctx, eventID)
+
+		assert.NoError(t, err)
+		assert.Len(t, releasedUsers, 0)
+		rate, _ := health.GetAdmissionRate(ctx, eventID)
+		assert.Equal(t, AdmissionReasonOverride, rate.Reason)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestQueueReleaseWorker_Leases(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	queueRepo := repository.NewRedisQueueRepository(client)
+	leases := lease.NewManager(client)
+	newWorker := func(holder string) *QueueReleaseWorker {
+		return NewQueueReleaseWorker(&QueueReleaseWorkerConfig{JWTSecret: testWorkerJWTSecret}, queueRepo, nil, logger.Get()).
+			WithLeases(leases, holder, time.Minute)
+	}
+	ctx := context.Background()
+	join := func(userIDs ...string) {
+		t.Helper()
+		for _, userID := range userIDs {
+			_, err := queueRepo.JoinQueue(ctx, repository.JoinQueueParams{UserID: userID, EventID: "event-1", Token: userID, TTLSeconds: 1800})
+			assert.NoError(t, err)
+		}
+	}
+	released := func(w *QueueReleaseWorker) int64 {
+		total, _, _ := w.GetMetrics()
+		return total
+	}
+
+	a, b := newWorker("replica-a"), newWorker("replica-b")
+	join("user-1", "user-2")
+	a.processAllQueues(ctx)
+	assert.Equal(t, int64(2), released(a))
+
+	// The queue stays with the replica holding its lease
+	join("user-3")
+	b.processAllQueues(ctx)
+	assert.Equal(t, int64(0), released(b))
+	a.processAllQueues(ctx)
+	assert.Equal(t, int64(3), released(a))
+
+	// Once that replica stops, the other takes the queue over
+	join("user-4")
+	a.releaseLeases()
+	b.processAllQueues(ctx)
+	assert.Equal(t, int64(1), released(b))
+
+	// b stalls past its lease TTL between claiming the queue and popping
+	// from it, and a takes the queue over meanwhile: b's stale token keeps it
+	// from releasing alongside a
+	join("user-5")
+	mr.FastForward(2 * time.Minute)
+	assert.True(t, a.claimQueue(ctx, "event-1"))
+	b.releaseFromQueue(ctx, "event-1")
+	assert.Equal(t, int64(1), released(b))
+	a.releaseFromQueue(ctx, "event-1")
+	assert.Equal(t, int64(4), released(a))
+}
@@ -0,0 +1,313 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/lease"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// queueSchedulerActor is recorded as who last changed a scheduled queue's state
+const queueSchedulerActor = "queue-scheduler"
+
+// closedScheduleRetention is how long a closed queue's schedule is kept, so
+// late updates from the ticket service do not reopen it by accident
+const closedScheduleRetention = 24 * time.Hour
+
+// QueueSchedulerConfig holds configuration for the queue scheduler
+type QueueSchedulerConfig struct {
+	// WaitingRoomLead is how long before its sale an event's waiting room
+	// opens (default: 30 minutes)
+	WaitingRoomLead time.Duration
+	// TickInterval is how often queues are moved into the phase they are due
+	// in (default: 5 seconds)
+	TickInterval time.Duration
+}
+
+// QueueScheduler drives event queues through their lifecycle from the sale
+// windows the ticket service publishes. Until WaitingRoomLead before the sale
+// a queue refuses joins; then its waiting room opens and joins enter a
+// lottery, which the queue release worker draws when the sale starts before
+// it releases anyone. The queue is drained when the sale ends.
+//
+// Any replica may apply the windows it consumes; with WithLeadership only the
+// elected one moves queues on the clock.
+type QueueScheduler struct {
+	config      *QueueSchedulerConfig
+	runner      kafka.RecordRunner
+	queueRepo   repository.QueueRepository
+	schedules   repository.QueueScheduleStore
+	redisClient *redis.Client // For Pub/Sub broadcasts (optional)
+	log         *logger.Logger
+	leadership  lease.Leadership // Gates Tick (nil = always tick)
+}
+
+// NewQueueScheduler creates a new queue scheduler
+func NewQueueScheduler(
+	cfg *QueueSchedulerConfig,
+	runner kafka.RecordRunner,
+	queueRepo repository.QueueRepository,
+	schedules repository.QueueScheduleStore,
+	redisClient *redis.Client,
+	log *logger.Logger,
+) *QueueScheduler {
+	if cfg == nil {
+		cfg = &QueueSchedulerConfig{}
+	}
+	if cfg.WaitingRoomLead <= 0 {
+		cfg.WaitingRoomLead = domain.DefaultWaitingRoomLead
+	}
+	if cfg.TickInterval <= 0 {
+		cfg.TickInterval = 5 * time.Second
+	}
+
+	return &QueueScheduler{
+		config:      cfg,
+		runner:      runner,
+		queueRepo:   queueRepo,
+		schedules:   schedules,
+		redisClient: redisClient,
+		log:         log,
+	}
+}
+
+// WithLeadership ticks only while leadership reports this replica leads
+func (s *QueueScheduler) WithLeadership(leadership lease.Leadership) *QueueScheduler {
+	s.leadership = leadership
+	return s
+}
+
+// Start consumes sale windows and moves queues through their phases until
+// ctx is cancelled, then waits for the consumed windows to be committed
+func (s *QueueScheduler) Start(ctx context.Context) {
+	if s.runner != nil {
+		runDone := make(chan struct{})
+		go func() {
+			defer close(runDone)
+			if err := s.runner.Run(ctx, s.handleRecord); err != nil && ctx.Err() == nil {
+				s.log.Error(fmt.Sprintf("Event schedule runner stopped: %v", err))
+			}
+		}()
+		defer func() { <-runDone }()
+	}
+
+	ticker := time.NewTicker(s.config.TickInterval)
+	defer ticker.Stop()
+
+	s.Tick(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Tick(ctx, time.Now())
+		}
+	}
+}
+
+// handleRecord applies a consumed sale window. A window that fails to apply
+// is returned as an error, so the runner retries it before committing.
+func (s *QueueScheduler) handleRecord(ctx context.Context, record *kafka.Record) error {
+	var schedule domain.EventSchedule
+	if err := record.Decode(&schedule); err != nil {
+		s.log.Error(fmt.Sprintf("Failed to unmarshal event schedule: %v", err))
+		return nil
+	}
+	if err := s.HandleSchedule(ctx, &schedule, time.Now()); err != nil {
+		if errors.Is(err, domain.ErrInvalidEventID) {
+			s.log.Error(fmt.Sprintf("Skipping event schedule without an event ID at offset %d", record.Offset))
+			return nil
+		}
+		return fmt.Errorf("failed to schedule queue %s: %w", schedule.EventID, err)
+	}
+	return nil
+}
+
+// HandleSchedule applies a sale window the ticket service published. Events
+// that are not published, or have no sale start, stop being scheduled.
+func (s *QueueScheduler) HandleSchedule(ctx context.Context, msg *domain.EventSchedule, now time.Time) error {
+	if msg.EventID == "" {
+		return domain.ErrInvalidEventID
+	}
+
+	current, err := s.schedules.GetQueueSchedule(ctx, msg.EventID)
+	if err != nil {
+		return err
+	}
+	if current != nil && msg.UpdatedAt.Before(current.UpdatedAt) {
+		return nil // Superseded by a window already applied
+	}
+
+	if !msg.IsScheduled() {
+		if current == nil {
+			return nil
+		}
+		return s.unschedule(ctx, current)
+	}
+
+	schedule := &repository.QueueSchedule{
+		EventID:   msg.EventID,
+		OpensAt:   msg.SaleStartAt.Add(-s.config.WaitingRoomLead),
+		StartsAt:  *msg.SaleStartAt,
+		UpdatedAt: msg.UpdatedAt,
+	}
+	if msg.SaleEndAt != nil {
+		schedule.EndsAt = *msg.SaleEndAt
+	}
+	if current != nil {
+		schedule.Phase = current.Phase
+	}
+
+	// Joins before the sale starts are pooled and drawn in random order
+	if now.Before(schedule.StartsAt) {
+		if err := s.scheduleLottery(ctx, schedule); err != nil {
+			return err
+		}
+	}
+
+	if err := s.schedules.SetQueueSchedule(ctx, schedule); err != nil {
+		return err
+	}
+	return s.advance(ctx, schedule, now)
+}
+
+// Tick moves every scheduled queue into the phase it is due in
+func (s *QueueScheduler) Tick(ctx context.Context, now time.Time) {
+	if s.leadership != nil && !s.leadership.IsLeader() {
+		return
+	}
+	schedules, err := s.schedules.ListQueueSchedules(ctx)
+	if err != nil {
+		s.log.Error(fmt.Sprintf("Failed to list queue schedules: %v", err))
+		return
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Phase == domain.QueuePhaseClosed && now.Sub(schedule.EndsAt) > closedScheduleRetention {
+			if err := s.schedules.DeleteQueueSchedule(ctx, schedule.EventID); err != nil {
+				s.log.Error(fmt.Sprintf("Failed to delete queue schedule %s: %v", schedule.EventID, err))
+			}
+			continue
+		}
+		if err := s.advance(ctx, schedule, now); err != nil {
+			s.log.Error(fmt.Sprintf("Failed to advance queue %s: %v", schedule.EventID, err))
+		}
+	}
+}
+
+// advance moves a queue into the phase it is due in at now, if it is not
+// there yet. Each phase is applied once, so operators can still pause,
+// resume or drain a scheduled queue in between.
+func (s *QueueScheduler) advance(ctx context.Context, schedule *repository.QueueSchedule, now time.Time) error {
+	phase := schedule.PhaseAt(now)
+	if phase == schedule.Phase {
+		return nil
+	}
+
+	switch phase {
+	case domain.QueuePhasePending:
+		message := fmt.Sprintf("The waiting room opens at %s", schedule.OpensAt.UTC().Format(time.RFC3339))
+		if err := s.setState(ctx, schedule.EventID, domain.QueueStateScheduled, message); err != nil {
+			return err
+		}
+	case domain.QueuePhaseWaiting, domain.QueuePhaseOnSale:
+		// Only the scheduler's own states are lifted: a queue operators paused
+		// or drained stays so, unless it was drained because the sale ended
+		// and the sale has since been extended
+		control, err := s.queueRepo.GetQueueControl(ctx, schedule.EventID)
+		if err != nil {
+			return err
+		}
+		if control.IsScheduled() || (control.IsDrained() && schedule.Phase == domain.QueuePhaseClosed) {
+			if err := s.setState(ctx, schedule.EventID, domain.QueueStateActive, ""); err != nil {
+				return err
+			}
+		}
+	case domain.QueuePhaseClosed:
+		if err := s.setState(ctx, schedule.EventID, domain.QueueStateDrained, "The sale has ended"); err != nil {
+			return err
+		}
+		if _, err := s.queueRepo.DrainQueue(ctx, schedule.EventID); err != nil {
+			return err
+		}
+	}
+
+	schedule.Phase = phase
+	if err := s.schedules.SetQueueSchedule(ctx, schedule); err != nil {
+		return err
+	}
+	s.log.Info(fmt.Sprintf("Queue %s moved to phase %s", schedule.EventID, phase))
+	return nil
+}
+
+// scheduleLottery makes a queue a lottery drawn when its sale starts,
+// keeping the rest of its configuration
+func (s *QueueScheduler) scheduleLottery(ctx context.Context, schedule *repository.QueueSchedule) error {
+	config, err := s.queueRepo.GetEventQueueConfig(ctx, schedule.EventID)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		config = &repository.EventQueueConfig{}
+	}
+	if config.Mode == domain.QueueModeLottery && config.LotteryOpensAt == schedule.StartsAt.Unix() {
+		return nil
+	}
+
+	config.Mode = domain.QueueModeLottery
+	config.LotteryOpensAt = schedule.StartsAt.Unix()
+	return s.queueRepo.SetEventQueueConfig(ctx, schedule.EventID, config)
+}
+
+// unschedule stops a queue from following its schedule. A queue whose
+// waiting room has not opened goes back to opening on the first join; one
+// already open runs on as operators leave it.
+func (s *QueueScheduler) unschedule(ctx context.Context, schedule *repository.QueueSchedule) error {
+	if schedule.Phase == "" || schedule.Phase == domain.QueuePhasePending {
+		config, err := s.queueRepo.GetEventQueueConfig(ctx, schedule.EventID)
+		if err != nil {
+			return err
+		}
+		if config != nil && config.Mode == domain.QueueModeLottery && config.LotteryOpensAt == schedule.StartsAt.Unix() {
+			config.Mode = domain.QueueModeFIFO
+			config.LotteryOpensAt = 0
+			if err := s.queueRepo.SetEventQueueConfig(ctx, schedule.EventID, config); err != nil {
+				return err
+			}
+		}
+		if err := s.setState(ctx, schedule.EventID, domain.QueueStateActive, ""); err != nil {
+			return err
+		}
+	}
+	return s.schedules.DeleteQueueSchedule(ctx, schedule.EventID)
+}
+
+// setState puts a queue in a state and tells everyone waiting in it, as the
+// queue admin endpoints do
+func (s *QueueScheduler) setState(ctx context.Context, eventID, state, message string) error {
+	now := time.Now()
+	control := &repository.QueueControl{
+		State:     state,
+		Message:   message,
+		UpdatedBy: queueSchedulerActor,
+		UpdatedAt: now,
+	}
+	if err := s.queueRepo.SetQueueControl(ctx, eventID, control); err != nil {
+		return err
+	}
+
+	if s.redisClient != nil {
+		msg := &QueueBroadcastMessage{EventID: eventID, State: state, Message: message, SentAt: now.Unix()}
+		if err := PublishQueueBroadcast(ctx, s.redisClient, msg); err != nil {
+			s.log.Warn(fmt.Sprintf("Failed to broadcast queue state: %v", err))
+		}
+	}
+	return nil
+}
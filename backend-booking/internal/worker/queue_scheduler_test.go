@@ -0,0 +1,227 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka/kafkatest"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+func TestQueueScheduler_Lifecycle(t *testing.T) {
+	newScheduler := func(t *testing.T) (*QueueScheduler, *repository.RedisQueueRepository) {
+		client, _ := redistest.NewClient(t)
+		queueRepo := repository.NewRedisQueueRepository(client)
+		scheduler := NewQueueScheduler(&QueueSchedulerConfig{WaitingRoomLead: 30 * time.Minute}, nil, queueRepo, queueRepo, client, logger.Get())
+		return scheduler, queueRepo
+	}
+	ctx := context.Background()
+	saleStart := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	saleEnd := saleStart.Add(24 * time.Hour)
+	window := func(updatedAt time.Time) *domain.EventSchedule {
+		return &domain.EventSchedule{
+			EventID:     "event-1",
+			Status:      "published",
+			SaleStartAt: &saleStart,
+			SaleEndAt:   &saleEnd,
+			UpdatedAt:   updatedAt,
+		}
+	}
+	state := func(t *testing.T, queueRepo *repository.RedisQueueRepository) string {
+		t.Helper()
+		control, err := queueRepo.GetQueueControl(ctx, "event-1")
+		if err != nil {
+			t.Fatalf("GetQueueControl() error = %v", err)
+		}
+		if control == nil {
+			return domain.QueueStateActive
+		}
+		return control.State
+	}
+
+	t.Run("moves a queue through its phases", func(t *testing.T) {
+		scheduler, queueRepo := newScheduler(t)
+		now := saleStart.Add(-time.Hour)
+
+		if err := scheduler.HandleSchedule(ctx, window(now), now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+		if got := state(t, queueRepo); got != domain.QueueStateScheduled {
+			t.Fatalf("state before the waiting room = %q, want %q", got, domain.QueueStateScheduled)
+		}
+		config, err := queueRepo.GetEventQueueConfig(ctx, "event-1")
+		if err != nil || !config.IsLottery() || config.LotteryOpensAt != saleStart.Unix() {
+			t.Fatalf("GetEventQueueConfig() = %+v, %v, want a lottery drawn at the sale start", config, err)
+		}
+
+		// The waiting room opens 30 minutes ahead
+		scheduler.Tick(ctx, saleStart.Add(-29*time.Minute))
+		if got := state(t, queueRepo); got != domain.QueueStateActive {
+			t.Fatalf("state in the waiting room = %q, want %q", got, domain.QueueStateActive)
+		}
+		if _, err := queueRepo.JoinQueue(ctx, repository.JoinQueueParams{
+			UserID: "fan", EventID: "event-1", Token: "token", TTLSeconds: 1800, LotteryOpensAt: config.LotteryOpensAt,
+		}); err != nil {
+			t.Fatalf("JoinQueue() error = %v", err)
+		}
+
+		scheduler.Tick(ctx, saleStart.Add(time.Minute))
+		schedule, err := queueRepo.GetQueueSchedule(ctx, "event-1")
+		if err != nil || schedule.Phase != domain.QueuePhaseOnSale {
+			t.Fatalf("GetQueueSchedule() = %+v, %v, want on sale", schedule, err)
+		}
+
+		// The sale ending drains the queue, lottery pool included
+		scheduler.Tick(ctx, saleEnd)
+		if got := state(t, queueRepo); got != domain.QueueStateDrained {
+			t.Fatalf("state after the sale = %q, want %q", got, domain.QueueStateDrained)
+		}
+		if info, _ := queueRepo.GetUserQueueInfo(ctx, "event-1", "fan"); len(info) != 0 {
+			t.Errorf("GetUserQueueInfo(fan) = %v, want the pooled user drained", info)
+		}
+	})
+
+	t.Run("leaves a queue operators paused", func(t *testing.T) {
+		scheduler, queueRepo := newScheduler(t)
+		now := saleStart.Add(-time.Hour)
+		if err := scheduler.HandleSchedule(ctx, window(now), now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+		if err := queueRepo.SetQueueControl(ctx, "event-1", &repository.QueueControl{State: domain.QueueStatePaused, UpdatedAt: now}); err != nil {
+			t.Fatalf("SetQueueControl() error = %v", err)
+		}
+
+		scheduler.Tick(ctx, saleStart.Add(-10*time.Minute))
+		if got := state(t, queueRepo); got != domain.QueueStatePaused {
+			t.Errorf("state = %q, want the operator's pause kept", got)
+		}
+	})
+
+	t.Run("ignores a stale window", func(t *testing.T) {
+		scheduler, queueRepo := newScheduler(t)
+		now := saleStart.Add(-time.Hour)
+		if err := scheduler.HandleSchedule(ctx, window(now), now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+
+		stale := window(now.Add(-time.Minute))
+		stale.Status = "draft"
+		if err := scheduler.HandleSchedule(ctx, stale, now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+		if schedule, err := queueRepo.GetQueueSchedule(ctx, "event-1"); err != nil || schedule == nil {
+			t.Errorf("GetQueueSchedule() = %+v, %v, want the newer window kept", schedule, err)
+		}
+	})
+
+	t.Run("unscheduling before the waiting room reopens the queue", func(t *testing.T) {
+		scheduler, queueRepo := newScheduler(t)
+		now := saleStart.Add(-time.Hour)
+		if err := scheduler.HandleSchedule(ctx, window(now), now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+
+		deleted := &domain.EventSchedule{EventID: "event-1", Deleted: true, UpdatedAt: now.Add(time.Second)}
+		if err := scheduler.HandleSchedule(ctx, deleted, now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+		if got := state(t, queueRepo); got != domain.QueueStateActive {
+			t.Errorf("state = %q, want %q", got, domain.QueueStateActive)
+		}
+		if config, _ := queueRepo.GetEventQueueConfig(ctx, "event-1"); config.IsLottery() {
+			t.Errorf("GetEventQueueConfig() = %+v, want the scheduled lottery removed", config)
+		}
+		if schedule, _ := queueRepo.GetQueueSchedule(ctx, "event-1"); schedule != nil {
+			t.Errorf("GetQueueSchedule() = %+v, want it deleted", schedule)
+		}
+	})
+
+	t.Run("only the leader ticks", func(t *testing.T) {
+		scheduler, queueRepo := newScheduler(t)
+		leader := staticLeadership(false)
+		scheduler.WithLeadership(&leader)
+		now := saleStart.Add(-time.Hour)
+		if err := scheduler.HandleSchedule(ctx, window(now), now); err != nil {
+			t.Fatalf("HandleSchedule() error = %v", err)
+		}
+
+		scheduler.Tick(ctx, saleStart.Add(-10*time.Minute))
+		if got := state(t, queueRepo); got != domain.QueueStateScheduled {
+			t.Errorf("state on a follower = %q, want %q", got, domain.QueueStateScheduled)
+		}
+		leader = true
+		scheduler.Tick(ctx, saleStart.Add(-10*time.Minute))
+		if got := state(t, queueRepo); got != domain.QueueStateActive {
+			t.Errorf("state on the leader = %q, want %q", got, domain.QueueStateActive)
+		}
+	})
+
+	t.Run("consumes windows from Kafka", func(t *testing.T) {
+		client, _ := redistest.NewClient(t)
+		queueRepo := repository.NewRedisQueueRepository(client)
+		broker := kafkatest.NewBroker()
+		runner := broker.NewRunner(kafkatest.RunnerConfig{GroupID: "queue-scheduler", Topics: []string{domain.EventScheduleTopic}})
+		scheduler := NewQueueScheduler(&QueueSchedulerConfig{TickInterval: time.Hour}, runner, queueRepo, queueRepo, nil, logger.Get())
+
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go scheduler.Start(runCtx)
+
+		if err := broker.NewProducer(nil).ProduceJSON(ctx, domain.EventScheduleTopic, "event-1", window(time.Now()), nil); err != nil {
+			t.Fatalf("ProduceJSON() error = %v", err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for state(t, queueRepo) != domain.QueueStateScheduled {
+			if time.Now().After(deadline) {
+				t.Fatal("queue was not scheduled from the consumed window")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("retries windows that fail to apply", func(t *testing.T) {
+		client, mr := redistest.NewClient(t)
+		queueRepo := repository.NewRedisQueueRepository(client)
+		broker := kafkatest.NewBroker()
+		runner := broker.NewRunner(kafkatest.RunnerConfig{
+			GroupID: "queue-scheduler",
+			Topics:  []string{domain.EventScheduleTopic},
+			ErrorHandler: func(ctx context.Context, record *kafka.Record, err error) error {
+				return err
+			},
+		})
+		scheduler := NewQueueScheduler(&QueueSchedulerConfig{TickInterval: time.Hour}, runner, queueRepo, queueRepo, nil, logger.Get())
+
+		mr.SetError("LOADING Redis is loading the dataset in memory")
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go scheduler.Start(runCtx)
+
+		if err := broker.NewProducer(nil).ProduceJSON(ctx, domain.EventScheduleTopic, "event-1", window(time.Now()), nil); err != nil {
+			t.Fatalf("ProduceJSON() error = %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if lag := broker.Lag("queue-scheduler", domain.EventScheduleTopic); lag != 1 {
+			t.Fatalf("lag = %d while the window fails to apply, want 1 (uncommitted)", lag)
+		}
+
+		mr.SetError("")
+		deadline := time.Now().Add(5 * time.Second)
+		for state(t, queueRepo) != domain.QueueStateScheduled {
+			if time.Now().After(deadline) {
+				t.Fatal("queue was not scheduled once the window could be applied")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+// staticLeadership leads while set
+type staticLeadership bool
+
+func (l *staticLeadership) IsLeader() bool { return bool(*l) }
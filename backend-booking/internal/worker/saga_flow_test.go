@@ -0,0 +1,388 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka/kafkatest"
+	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
+)
+
+// memBookingRepository is an in-memory BookingRepository for saga flow tests
+type memBookingRepository struct {
+	repository.BookingRepository
+
+	mu       sync.Mutex
+	bookings map[string]*domain.Booking
+}
+
+func newMemBookingRepository() *memBookingRepository {
+	return &memBookingRepository{bookings: make(map[string]*domain.Booking)}
+}
+
+func (r *memBookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := *booking
+	r.bookings[booking.ID] = &b
+	return nil
+}
+
+func (r *memBookingRepository) GetByID(ctx context.Context, id string) (*domain.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.bookings[id]
+	if !ok {
+		return nil, domain.ErrBookingNotFound
+	}
+	c := *b
+	return &c, nil
+}
+
+func (r *memBookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
+	return r.Create(ctx, booking)
+}
+
+// memReservationRepository is an in-memory ReservationRepository for saga flow tests
+type memReservationRepository struct {
+	mu        sync.Mutex
+	available map[string]int64
+	held      map[string]repository.ReserveParams
+	released  chan string
+	nextID    int
+}
+
+func newMemReservationRepository(zoneID string, seats int64) *memReservationRepository {
+	return &memReservationRepository{
+		available: map[string]int64{zoneID: seats},
+		held:      make(map[string]repository.ReserveParams),
+		released:  make(chan string, 10),
+	}
+}
+
+func (r *memReservationRepository) ReserveSeats(ctx context.Context, params repository.ReserveParams) (*repository.ReserveResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.available[params.ZoneID] < int64(params.Quantity) {
+		return &repository.ReserveResult{ErrorCode: "INSUFFICIENT_SEATS", ErrorMessage: "not enough seats"}, nil
+	}
+	r.available[params.ZoneID] -= int64(params.Quantity)
+	r.nextID++
+	id := fmt.Sprintf("booking-%04d", r.nextID)
+	r.held[id] = params
+	return &repository.ReserveResult{Success: true, BookingID: id, AvailableSeats: r.available[params.ZoneID]}, nil
+}
+
+func (r *memReservationRepository) ConfirmBooking(ctx context.Context, bookingID, zoneID, userID, paymentID string) (*repository.ConfirmResult, error) {
+	return &repository.ConfirmResult{Success: true, Status: "confirmed"}, nil
+}
+
+func (r *memReservationRepository) ReleaseSeats(ctx context.Context, bookingID, zoneID, userID string) (*repository.ReleaseResult, error) {
+	r.mu.Lock()
+	params, ok := r.held[bookingID]
+	if ok {
+		delete(r.held, bookingID)
+		r.available[params.ZoneID] += int64(params.Quantity)
+	}
+	r.mu.Unlock()
+
+	r.released <- bookingID
+	return &repository.ReleaseResult{Success: ok}, nil
+}
+
+func (r *memReservationRepository) GetZoneAvailability(ctx context.Context, zoneID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.available[zoneID], nil
+}
+
+func (r *memReservationRepository) GetZoneAvailabilities(ctx context.Context, zoneIDs []string) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make(map[string]int64)
+	for _, id := range zoneIDs {
+		if v, ok := r.available[id]; ok {
+			result[id] = v
+		}
+	}
+	return result, nil
+}
+
+func (r *memReservationRepository) SetZoneAvailability(ctx context.Context, zoneID string, seats int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.available[zoneID] = seats
+	return nil
+}
+
+// sagaFlow wires the booking saga against an in-memory broker: the
+// orchestrator consumer, the booking step worker and a stand-in for the
+// payment service's saga worker
+type sagaFlow struct {
+	broker       *kafkatest.Broker
+	store        *pkgsaga.MemoryStore
+	bookings     *memBookingRepository
+	reservations *memReservationRepository
+	service      *service.KafkaSagaService
+}
+
+// paymentDecision decides the outcome of a process-payment command;
+// a non-empty error message fails the step
+type paymentDecision func(data *saga.BookingSagaData) (errorMessage string)
+
+func startSagaFlow(t *testing.T, ctx context.Context, decide paymentDecision) *sagaFlow {
+	t.Helper()
+
+	f := &sagaFlow{
+		broker:       kafkatest.NewBroker(),
+		store:        pkgsaga.NewMemoryStore(),
+		bookings:     newMemBookingRepository(),
+		reservations: newMemReservationRepository("zone-a", 10),
+	}
+
+	producer, err := saga.NewKafkaSagaProducer(ctx, &saga.KafkaSagaProducerConfig{
+		Producer: f.broker.NewProducer(nil),
+	})
+	if err != nil {
+		t.Fatalf("NewKafkaSagaProducer() error = %v", err)
+	}
+
+	orchestrator := pkgsaga.NewOrchestrator(&pkgsaga.OrchestratorConfig{Store: f.store})
+	consumer, err := saga.NewSagaConsumer(ctx, &saga.SagaConsumerConfig{
+		Store:    f.store,
+		Producer: producer,
+		Handler:  saga.NewOrchestratorEventHandler(orchestrator, producer, f.store),
+		Consumer: f.broker.NewConsumer("saga-orchestrator", saga.GetAllEventTopics()...),
+	})
+	if err != nil {
+		t.Fatalf("NewSagaConsumer() error = %v", err)
+	}
+
+	stepWorker := NewSagaStepWorker(
+		f.broker.NewRunner(kafkatest.RunnerConfig{
+			GroupID: "saga-step-worker",
+			Topics: []string{
+				saga.TopicSagaReserveSeatsCommand,
+				saga.TopicSagaReleaseSeatsCommand,
+				saga.TopicSagaConfirmBookingCommand,
+				saga.TopicSagaSendNotificationCommand,
+			},
+		}),
+		producer,
+		f.bookings,
+		f.reservations,
+		nil,
+		&SagaStepWorkerConfig{RetryAttempts: 1, RetryDelay: time.Millisecond},
+	)
+
+	payments := f.broker.NewRunner(kafkatest.RunnerConfig{
+		GroupID: "saga-payment-worker",
+		Topics:  []string{saga.TopicSagaProcessPaymentCommand},
+	})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = stepWorker.Start(runCtx)
+	}()
+	go func() {
+		defer wg.Done()
+		_ = payments.Run(runCtx, func(ctx context.Context, record *kafka.Record) error {
+			return handlePaymentCommand(ctx, producer, record, decide)
+		})
+	}()
+	if err := consumer.Start(runCtx); err != nil {
+		t.Fatalf("SagaConsumer.Start() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		_ = consumer.Stop()
+		wg.Wait()
+	})
+
+	f.service = service.NewKafkaSagaService(producer, f.store, nil)
+	return f
+}
+
+// handlePaymentCommand mirrors the payment service's saga worker
+func handlePaymentCommand(ctx context.Context, producer saga.SagaProducer, record *kafka.Record, decide paymentDecision) error {
+	var command saga.SagaCommand
+	if err := json.Unmarshal(record.Value, &command); err != nil {
+		return err
+	}
+	data := &saga.BookingSagaData{}
+	data.FromMap(command.Data)
+
+	now := time.Now()
+	if msg := decide(data); msg != "" {
+		return producer.SendStepFailureEvent(ctx, saga.NewSagaFailureEvent(
+			command.SagaID, command.SagaName, command.StepName, command.StepIndex,
+			msg, "PAYMENT_FAILED", now, now,
+		))
+	}
+	return producer.SendStepSuccessEvent(ctx, saga.NewSagaSuccessEvent(
+		command.SagaID, command.SagaName, command.StepName, command.StepIndex,
+		map[string]interface{}{"payment_id": "pay-" + data.BookingID},
+		now, now,
+	))
+}
+
+func (f *sagaFlow) waitForLifecycle(t *testing.T, ctx context.Context, topic, sagaID string) *saga.SagaLifecycleEvent {
+	t.Helper()
+
+	record, err := f.broker.WaitFor(ctx, topic, func(r *kafka.Record) bool {
+		return string(r.Key) == sagaID
+	})
+	if err != nil {
+		t.Fatalf("waiting for %s: %v", topic, err)
+	}
+	var event saga.SagaLifecycleEvent
+	if err := record.Decode(&event); err != nil {
+		t.Fatalf("decode %s: %v", topic, err)
+	}
+	return &event
+}
+
+func newSagaData() *saga.BookingSagaData {
+	return &saga.BookingSagaData{
+		UserID:     "user-1",
+		TenantID:   "tenant-1",
+		EventID:    "event-1",
+		ShowID:     "show-1",
+		ZoneID:     "zone-a",
+		Quantity:   2,
+		TotalPrice: 200,
+		Currency:   "THB",
+	}
+}
+
+func TestBookingSagaFlow_PaymentSucceeds(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	f := startSagaFlow(t, ctx, func(*saga.BookingSagaData) string { return "" })
+
+	sagaID, err := f.service.StartBookingSaga(ctx, newSagaData())
+	if err != nil {
+		t.Fatalf("StartBookingSaga() error = %v", err)
+	}
+
+	event := f.waitForLifecycle(t, ctx, saga.TopicSagaCompletedEvent, sagaID)
+	if event.Status != string(pkgsaga.StatusCompleted) {
+		t.Errorf("completed event status = %s", event.Status)
+	}
+
+	instance, err := f.store.Get(ctx, sagaID)
+	if err != nil {
+		t.Fatalf("store.Get() error = %v", err)
+	}
+	if instance.Status != pkgsaga.StatusCompleted {
+		t.Errorf("saga status = %s, want %s", instance.Status, pkgsaga.StatusCompleted)
+	}
+	if len(instance.StepResults) != 4 {
+		t.Errorf("saga has %d step results, want 4", len(instance.StepResults))
+	}
+
+	bookingID, _ := instance.Data["booking_id"].(string)
+	booking, err := f.bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		t.Fatalf("booking %q not stored: %v", bookingID, err)
+	}
+	if booking.Status != domain.BookingStatusConfirmed {
+		t.Errorf("booking status = %s, want %s", booking.Status, domain.BookingStatusConfirmed)
+	}
+	if booking.PaymentID != "pay-"+bookingID {
+		t.Errorf("booking payment = %s, want pay-%s", booking.PaymentID, bookingID)
+	}
+
+	if seats, _ := f.reservations.GetZoneAvailability(ctx, "zone-a"); seats != 8 {
+		t.Errorf("available seats = %d, want 8", seats)
+	}
+
+	// Every step ran exactly once, in order
+	for _, topic := range []string{
+		saga.TopicSagaReserveSeatsCommand,
+		saga.TopicSagaProcessPaymentCommand,
+		saga.TopicSagaConfirmBookingCommand,
+		saga.TopicSagaSendNotificationCommand,
+	} {
+		if n := len(f.broker.Records(topic)); n != 1 {
+			t.Errorf("%s has %d commands, want 1", topic, n)
+		}
+	}
+	if n := len(f.broker.Records(saga.TopicSagaReleaseSeatsCommand)); n != 0 {
+		t.Errorf("release-seats sent %d times on success", n)
+	}
+}
+
+func TestBookingSagaFlow_PaymentFailureReleasesSeats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	f := startSagaFlow(t, ctx, func(*saga.BookingSagaData) string { return "card declined" })
+
+	sagaID, err := f.service.StartBookingSaga(ctx, newSagaData())
+	if err != nil {
+		t.Fatalf("StartBookingSaga() error = %v", err)
+	}
+
+	event := f.waitForLifecycle(t, ctx, saga.TopicSagaCompensatedEvent, sagaID)
+	if event.ErrorMessage != "card declined" {
+		t.Errorf("compensated event error = %q, want %q", event.ErrorMessage, "card declined")
+	}
+
+	select {
+	case <-f.reservations.released:
+	case <-ctx.Done():
+		t.Fatal("seats were not released")
+	}
+
+	instance, err := f.store.Get(ctx, sagaID)
+	if err != nil {
+		t.Fatalf("store.Get() error = %v", err)
+	}
+	if instance.Status != pkgsaga.StatusCompensated {
+		t.Errorf("saga status = %s, want %s", instance.Status, pkgsaga.StatusCompensated)
+	}
+	if seats, _ := f.reservations.GetZoneAvailability(ctx, "zone-a"); seats != 10 {
+		t.Errorf("available seats = %d, want 10", seats)
+	}
+	if n := len(f.broker.Records(saga.TopicSagaConfirmBookingCommand)); n != 0 {
+		t.Errorf("confirm-booking sent %d times after payment failure", n)
+	}
+}
+
+func TestBookingSagaFlow_ReservationFailureCompensatesNothing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	f := startSagaFlow(t, ctx, func(*saga.BookingSagaData) string { return "" })
+
+	data := newSagaData()
+	data.Quantity = 20 // more than the zone holds
+	sagaID, err := f.service.StartBookingSaga(ctx, data)
+	if err != nil {
+		t.Fatalf("StartBookingSaga() error = %v", err)
+	}
+
+	f.waitForLifecycle(t, ctx, saga.TopicSagaCompensatedEvent, sagaID)
+
+	if n := len(f.broker.Records(saga.TopicSagaProcessPaymentCommand)); n != 0 {
+		t.Errorf("process-payment sent %d times after reservation failure", n)
+	}
+	if n := len(f.broker.Records(saga.TopicSagaReleaseSeatsCommand)); n != 0 {
+		t.Errorf("release-seats sent %d times with nothing reserved", n)
+	}
+}
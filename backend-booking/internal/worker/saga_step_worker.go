@@ -14,26 +14,28 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 )
 
-// SagaStepWorkerConfig contains configuration for the saga step worker
+// SagaStepWorkerConfig contains configuration for the saga step worker.
+// Step concurrency is configured on the kafka.Runner (RunnerConfig.Concurrency).
 type SagaStepWorkerConfig struct {
-	WorkerCount   int
 	RetryAttempts int
 	RetryDelay    time.Duration
 }
 
-// SagaStepWorker consumes saga commands and executes steps
+// SagaStepWorker consumes saga commands and executes steps.
+// Offsets are committed by the runner once a handler returns.
 type SagaStepWorker struct {
-	consumer        *kafka.Consumer
+	runner          kafka.RecordRunner
 	producer        saga.SagaProducer
 	bookingRepo     repository.BookingRepository
 	reservationRepo repository.ReservationRepository
 	dlqHandler      *saga.DLQHandler
 	config          *SagaStepWorkerConfig
+	admissionHealth repository.AdmissionHealthRepository
 }
 
 // NewSagaStepWorker creates a new saga step worker
 func NewSagaStepWorker(
-	consumer *kafka.Consumer,
+	runner kafka.RecordRunner,
 	producer saga.SagaProducer,
 	bookingRepo repository.BookingRepository,
 	reservationRepo repository.ReservationRepository,
@@ -42,13 +44,12 @@ func NewSagaStepWorker(
 ) *SagaStepWorker {
 	if config == nil {
 		config = &SagaStepWorkerConfig{
-			WorkerCount:   5,
 			RetryAttempts: 3,
 			RetryDelay:    time.Second,
 		}
 	}
 	return &SagaStepWorker{
-		consumer:        consumer,
+		runner:          runner,
 		producer:        producer,
 		bookingRepo:     bookingRepo,
 		reservationRepo: reservationRepo,
@@ -57,61 +58,17 @@ func NewSagaStepWorker(
 	}
 }
 
-// Start starts the worker
-func (w *SagaStepWorker) Start(ctx context.Context) error {
-	log := logger.Get()
-	log.Info(fmt.Sprintf("Starting saga step worker with %d workers", w.config.WorkerCount))
-
-	recordsCh := make(chan *kafka.Record, w.config.WorkerCount*10)
-
-	// Start worker goroutines
-	for i := 0; i < w.config.WorkerCount; i++ {
-		go w.worker(ctx, i, recordsCh)
-	}
-
-	// Poll for messages
-	return w.poll(ctx, recordsCh)
-}
-
-func (w *SagaStepWorker) poll(ctx context.Context, recordsCh chan<- *kafka.Record) error {
-	log := logger.Get()
-
-	for {
-		select {
-		case <-ctx.Done():
-			close(recordsCh)
-			return ctx.Err()
-		default:
-			records, err := w.consumer.Poll(ctx)
-			if err != nil {
-				log.Error(fmt.Sprintf("Failed to poll messages: %v", err))
-				time.Sleep(time.Second)
-				continue
-			}
-
-			for _, record := range records {
-				select {
-				case recordsCh <- record:
-				case <-ctx.Done():
-					close(recordsCh)
-					return ctx.Err()
-				}
-			}
-		}
-	}
+// WithAdmissionHealth reports confirmed bookings to the queue's adaptive
+// release rate, which slows admission while payments fall behind
+func (w *SagaStepWorker) WithAdmissionHealth(health repository.AdmissionHealthRepository) *SagaStepWorker {
+	w.admissionHealth = health
+	return w
 }
 
-func (w *SagaStepWorker) worker(ctx context.Context, id int, recordsCh <-chan *kafka.Record) {
-	log := logger.Get()
-	log.Info(fmt.Sprintf("Worker %d started", id))
-
-	for record := range recordsCh {
-		if err := w.processRecord(ctx, record); err != nil {
-			log.Error(fmt.Sprintf("Worker %d failed to process record: %v", id, err))
-		}
-	}
-
-	log.Info(fmt.Sprintf("Worker %d stopped", id))
+// Start runs the worker until ctx is cancelled, draining in-flight steps before returning
+func (w *SagaStepWorker) Start(ctx context.Context) error {
+	logger.Get().Info("Starting saga step worker")
+	return w.runner.Run(ctx, w.processRecord)
 }
 
 func (w *SagaStepWorker) processRecord(ctx context.Context, record *kafka.Record) error {
@@ -131,7 +88,7 @@ func (w *SagaStepWorker) processRecord(ctx context.Context, record *kafka.Record
 		return w.handleSendNotification(ctx, record)
 	default:
 		log.Warn(fmt.Sprintf("Unknown topic: %s", topic))
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 }
 
@@ -143,7 +100,7 @@ func (w *SagaStepWorker) handleReserveSeats(ctx context.Context, record *kafka.R
 	var command saga.SagaCommand
 	if err := json.Unmarshal(record.Value, &command); err != nil {
 		log.Error(fmt.Sprintf("Failed to unmarshal command: %v", err))
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 
 	log.Info(fmt.Sprintf("Processing reserve-seats: saga_id=%s", command.SagaID))
@@ -254,7 +211,7 @@ func (w *SagaStepWorker) handleReserveSeats(ctx context.Context, record *kafka.R
 		}
 	}
 
-	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
 
 // handleReleaseSeats handles the release-seats compensation step
@@ -264,7 +221,7 @@ func (w *SagaStepWorker) handleReleaseSeats(ctx context.Context, record *kafka.R
 	var command saga.CompensationCommand
 	if err := json.Unmarshal(record.Value, &command); err != nil {
 		log.Error(fmt.Sprintf("Failed to unmarshal compensation command: %v", err))
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 
 	log.Info(fmt.Sprintf("Processing release-seats compensation: saga_id=%s", command.SagaID))
@@ -274,14 +231,14 @@ func (w *SagaStepWorker) handleReleaseSeats(ctx context.Context, record *kafka.R
 	data.FromMap(command.OriginalStepData)
 
 	// Execute release
-	_, err := w.reservationRepo.ReleaseSeats(ctx, data.BookingID, data.UserID)
+	_, err := w.reservationRepo.ReleaseSeats(ctx, data.BookingID, data.ZoneID, data.UserID)
 	if err != nil {
 		log.Error(fmt.Sprintf("Failed to release seats: %v", err))
 	} else {
 		log.Info(fmt.Sprintf("Released seats: booking_id=%s", data.BookingID))
 	}
 
-	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
 
 // handleConfirmBooking handles the confirm-booking step
@@ -293,7 +250,7 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 	var command saga.SagaCommand
 	if err := json.Unmarshal(record.Value, &command); err != nil {
 		log.Error(fmt.Sprintf("Failed to unmarshal command: %v", err))
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 
 	log.Info(fmt.Sprintf("Processing confirm-booking: saga_id=%s, saga_name=%s", command.SagaID, command.SagaName))
@@ -318,7 +275,7 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 
 	if bookingID == "" {
 		log.Error("booking_id is empty in confirm-booking command")
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 
 	log.Info(fmt.Sprintf("Confirming booking: booking_id=%s, payment_id=%s", bookingID, paymentID))
@@ -340,7 +297,7 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 
 		// Step 2: Confirm in Redis (remove TTL - make reservation permanent)
 		if userID != "" {
-			redisResult, redisErr := w.reservationRepo.ConfirmBooking(ctx, bookingID, userID, paymentID)
+			redisResult, redisErr := w.reservationRepo.ConfirmBooking(ctx, bookingID, booking.ZoneID, userID, paymentID)
 			if redisErr != nil {
 				log.Warn(fmt.Sprintf("Failed to confirm in Redis (may have expired): %v", redisErr))
 				// Continue anyway - PostgreSQL is the final source of truth
@@ -372,6 +329,11 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 			execErr = fmt.Errorf("failed to update booking status: %w", err)
 		} else {
 			log.Info(fmt.Sprintf("Confirmed booking in PostgreSQL: booking_id=%s, confirmation_code=%s", bookingID, confirmationCode))
+			if w.admissionHealth != nil {
+				if err := w.admissionHealth.RecordConfirm(ctx, booking.EventID); err != nil {
+					log.Warn(fmt.Sprintf("Failed to record confirmation health: %v", err))
+				}
+			}
 			resultData = map[string]interface{}{
 				"booking_id":        bookingID,
 				"confirmation_code": confirmationCode,
@@ -414,7 +376,7 @@ func (w *SagaStepWorker) handleConfirmBooking(ctx context.Context, record *kafka
 		}
 	}
 
-	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
 
 // handleSendNotification handles the send-notification step (NON-CRITICAL)
@@ -427,7 +389,7 @@ func (w *SagaStepWorker) handleSendNotification(ctx context.Context, record *kaf
 	var command saga.SagaCommand
 	if err := json.Unmarshal(record.Value, &command); err != nil {
 		log.Error(fmt.Sprintf("Failed to unmarshal notification command: %v", err))
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 
 	log.Info(fmt.Sprintf("Processing send-notification (NON-CRITICAL): saga_id=%s", command.SagaID))
@@ -525,5 +487,5 @@ func (w *SagaStepWorker) handleSendNotification(ctx context.Context, record *kaf
 		}
 	}
 
-	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
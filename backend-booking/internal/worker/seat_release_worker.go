@@ -23,16 +23,17 @@ type SeatReleaseEvent struct {
 	Timestamp   string `json:"timestamp"`
 }
 
-// SeatReleaseWorkerConfig contains configuration for the seat release worker
+// SeatReleaseWorkerConfig contains configuration for the seat release worker.
+// Concurrency is configured on the kafka.Runner (RunnerConfig.Concurrency).
 type SeatReleaseWorkerConfig struct {
-	WorkerCount   int
 	RetryAttempts int
 	RetryDelay    time.Duration
 }
 
-// SeatReleaseWorker consumes seat release events and releases seats
+// SeatReleaseWorker consumes seat release events and releases seats.
+// Offsets are committed by the runner once a handler returns.
 type SeatReleaseWorker struct {
-	consumer        *kafka.Consumer
+	runner          kafka.RecordRunner
 	bookingRepo     repository.BookingRepository
 	reservationRepo repository.ReservationRepository
 	config          *SeatReleaseWorkerConfig
@@ -40,94 +41,41 @@ type SeatReleaseWorker struct {
 
 // NewSeatReleaseWorker creates a new seat release worker
 func NewSeatReleaseWorker(
-	consumer *kafka.Consumer,
+	runner kafka.RecordRunner,
 	bookingRepo repository.BookingRepository,
 	reservationRepo repository.ReservationRepository,
 	config *SeatReleaseWorkerConfig,
 ) *SeatReleaseWorker {
 	if config == nil {
 		config = &SeatReleaseWorkerConfig{
-			WorkerCount:   5,
 			RetryAttempts: 3,
 			RetryDelay:    time.Second,
 		}
 	}
 	return &SeatReleaseWorker{
-		consumer:        consumer,
+		runner:          runner,
 		bookingRepo:     bookingRepo,
 		reservationRepo: reservationRepo,
 		config:          config,
 	}
 }
 
-// Start starts the worker and begins consuming messages
+// Start runs the worker until ctx is cancelled, draining in-flight releases before returning
 func (w *SeatReleaseWorker) Start(ctx context.Context) error {
-	log := logger.Get()
-	log.Info(fmt.Sprintf("Starting seat release worker with %d workers", w.config.WorkerCount))
-
-	recordsCh := make(chan *kafka.Record, w.config.WorkerCount*10)
-
-	// Start worker goroutines
-	for i := 0; i < w.config.WorkerCount; i++ {
-		go w.worker(ctx, i, recordsCh)
-	}
-
-	// Poll for messages
-	return w.poll(ctx, recordsCh)
-}
-
-// poll continuously polls for messages from Kafka
-func (w *SeatReleaseWorker) poll(ctx context.Context, recordsCh chan<- *kafka.Record) error {
-	log := logger.Get()
-
-	for {
-		select {
-		case <-ctx.Done():
-			close(recordsCh)
-			return ctx.Err()
-		default:
-			records, err := w.consumer.Poll(ctx)
-			if err != nil {
-				log.Error(fmt.Sprintf("Failed to poll messages: %v", err))
-				time.Sleep(time.Second)
-				continue
-			}
-
-			for _, record := range records {
-				select {
-				case recordsCh <- record:
-				case <-ctx.Done():
-					close(recordsCh)
-					return ctx.Err()
-				}
-			}
-		}
-	}
-}
-
-// worker processes messages from the channel
-func (w *SeatReleaseWorker) worker(ctx context.Context, id int, recordsCh <-chan *kafka.Record) {
-	log := logger.Get()
-	log.Info(fmt.Sprintf("Worker %d started", id))
-
-	for record := range recordsCh {
-		if err := w.processRecord(ctx, record); err != nil {
-			log.Error(fmt.Sprintf("Worker %d failed to process record: %v", id, err))
-		}
-	}
-
-	log.Info(fmt.Sprintf("Worker %d stopped", id))
+	logger.Get().Info("Starting seat release worker")
+	return w.runner.Run(ctx, w.processRecord)
 }
 
-// processRecord processes a single Kafka record
+// processRecord processes a single Kafka record. A release that still fails
+// after its retries is returned to the runner's ErrorHandler.
 func (w *SeatReleaseWorker) processRecord(ctx context.Context, record *kafka.Record) error {
 	log := logger.Get()
 
 	var event SeatReleaseEvent
 	if err := json.Unmarshal(record.Value, &event); err != nil {
 		log.Error(fmt.Sprintf("Failed to unmarshal event: %v", err))
-		// Commit the record to avoid reprocessing malformed messages
-		return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		// Skip malformed messages rather than reprocessing them
+		return nil
 	}
 
 	log.Info(fmt.Sprintf("Processing seat release: booking_id=%s, reason=%s", event.BookingID, event.Reason))
@@ -146,13 +94,11 @@ func (w *SeatReleaseWorker) processRecord(ctx context.Context, record *kafka.Rec
 	}
 
 	if lastErr != nil {
-		log.Error(fmt.Sprintf("Failed to release seats after %d attempts: booking_id=%s, error=%v", w.config.RetryAttempts, event.BookingID, lastErr))
-		// Still commit to avoid infinite loop, but log the failure for manual investigation
-	} else {
-		log.Info(fmt.Sprintf("Successfully released seats: booking_id=%s", event.BookingID))
+		return fmt.Errorf("failed to release seats after %d attempts: booking_id=%s: %w", w.config.RetryAttempts, event.BookingID, lastErr)
 	}
 
-	return w.consumer.CommitRecords(ctx, []*kafka.Record{record})
+	log.Info(fmt.Sprintf("Successfully released seats: booking_id=%s", event.BookingID))
+	return nil
 }
 
 // releaseSeats releases the seats for a booking
@@ -177,7 +123,7 @@ func (w *SeatReleaseWorker) releaseSeats(ctx context.Context, event *SeatRelease
 	}
 
 	// Release seats in Redis
-	_, err = w.reservationRepo.ReleaseSeats(ctx, booking.ID, booking.UserID)
+	_, err = w.reservationRepo.ReleaseSeats(ctx, booking.ID, booking.ZoneID, booking.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to release seats in Redis: %w", err)
 	}
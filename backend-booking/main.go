@@ -14,15 +14,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/di"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/handler"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/saga"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-booking/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/retry"
 	pkgsaga "github.com/prohmpiriya/booking-rush-10k-rps/pkg/saga"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 )
@@ -105,6 +108,7 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      500, // Large pool for 10k RPS
 		MinIdleConns:  100, // Keep connections ready
 		MaxRetries:    3,
@@ -115,6 +119,10 @@ func main() {
 		PoolTimeout:   4 * time.Second,
 		EnableTracing: cfg.OTel.Enabled,
 		ServiceName:   "booking-service",
+		CircuitBreaker: &pkgredis.BreakerConfig{
+			FailureThreshold: cfg.Redis.BreakerFailureThreshold,
+			OpenTimeout:      cfg.Redis.BreakerOpenTimeout,
+		},
 	}
 	redisClient, err = pkgredis.NewClient(ctx, redisCfg)
 	if err != nil {
@@ -123,6 +131,12 @@ func main() {
 	defer redisClient.Close()
 	appLog.Info(fmt.Sprintf("Redis connected (pool: %d, minIdle: %d)", redisCfg.PoolSize, redisCfg.MinIdleConns))
 
+	// Initialize Kafka payload codecs (per-topic encoding)
+	codecs, err := kafka.NewCodecRegistryFromNames(cfg.Kafka.Encoding, cfg.Kafka.TopicEncodings)
+	if err != nil {
+		appLog.Fatal(fmt.Sprintf("Invalid Kafka encoding config: %v", err))
+	}
+
 	// Initialize Kafka event publisher
 	var eventPublisher service.EventPublisher
 	eventPubCfg := &service.EventPublisherConfig{
@@ -131,6 +145,7 @@ func main() {
 		ServiceName: "booking-service",
 		ClientID:    cfg.Kafka.ClientID,
 		Logger:      service.NewZapLoggerAdapter(appLog),
+		Codecs:      codecs,
 	}
 	eventPublisher, err = service.NewKafkaEventPublisher(ctx, eventPubCfg)
 	if err != nil {
@@ -159,11 +174,35 @@ func main() {
 		appLog.Info("Saga store initialized (PostgreSQL)")
 	}
 
+	// Initialize DLQ replay producer for admin DLQ tooling (replays dead letters to original topics)
+	var dlqPublisher retry.KafkaPublisher
+	dlqProducer, err := kafka.NewProducer(ctx, &kafka.ProducerConfig{
+		Brokers:       cfg.Kafka.Brokers,
+		ClientID:      "booking-service-dlq-replay",
+		MaxRetries:    3,
+		RetryInterval: time.Second,
+	})
+	if err != nil {
+		appLog.Warn(fmt.Sprintf("DLQ replay producer init failed, DLQ admin API disabled: %v", err))
+	} else {
+		defer dlqProducer.Close()
+		dlqPublisher = &retry.KafkaProducerAdapter{Producer: dlqProducer}
+	}
+
 	// Initialize repositories
 	bookingRepo := repository.NewPostgresBookingRepository(db.Pool())
-	reservationRepo := repository.NewRedisReservationRepository(redisClient)
+	reservationRepo := repository.NewRedisReservationRepository(redisClient).
+		WithZoneShards(cfg.Booking.ZoneInventoryShards).
+		WithAvailabilityEvents(cfg.Booking.AvailabilityStreamEnabled).
+		WithIdentityLimits(cfg.Booking.IdentityLimitsEnabled)
 	queueRepo := repository.NewRedisQueueRepository(redisClient)
 
+	// Postgres fallback store for reservations while the Redis circuit breaker is open
+	var fallbackStore repository.FallbackReservationStore
+	if cfg.Booking.ReservationFallbackEnabled {
+		fallbackStore = repository.NewPostgresFallbackReservationRepository(db.Pool())
+	}
+
 	// Pre-load Lua scripts into Redis
 	if err := reservationRepo.LoadScripts(ctx); err != nil {
 		appLog.Warn(fmt.Sprintf("Failed to pre-load reservation Lua scripts: %v", err))
@@ -199,6 +238,84 @@ func main() {
 	requireQueuePass := cfg.Booking.RequireQueuePass
 	appLog.Info(fmt.Sprintf("Virtual Queue: RequireQueuePass=%v", requireQueuePass))
 
+	// Zone availability streams for event pages
+	var availabilityConfig *service.AvailabilityHubConfig
+	if cfg.Booking.AvailabilityStreamEnabled {
+		availabilityConfig = &service.AvailabilityHubConfig{
+			Interval: cfg.Booking.AvailabilityStreamInterval,
+		}
+	}
+
+	// Queue positions over WebSockets, next to the SSE stream
+	var queueSocketConfig *handler.QueueSocketConfig
+	if cfg.Booking.QueueSocketEnabled {
+		queueSocketConfig = &handler.QueueSocketConfig{
+			MaxConnections: cfg.Booking.QueueSocketMaxConnections,
+			MaxEvents:      cfg.Booking.QueueSocketMaxEvents,
+		}
+	}
+
+	// Per-identity purchase limits; hits are written to the audit log
+	// Booking health drives the queue release worker's adaptive release rate
+	var admissionHealth repository.AdmissionHealthRepository
+	if cfg.Booking.AdmissionHealthEnabled {
+		admissionHealth = repository.NewRedisAdmissionHealthRepository(redisClient)
+	}
+
+	fingerprintSecret := cfg.Booking.IdentityFingerprintSecret
+	if fingerprintSecret == "" {
+		fingerprintSecret = cfg.JWT.Secret
+	}
+	var auditLogger *middleware.AuditLogger
+	if cfg.Booking.IdentityLimitsEnabled || cfg.Booking.JoinScreeningEnabled {
+		auditLogger = middleware.NewAuditLogger(middleware.DefaultAuditConfig(db.Pool()))
+		defer auditLogger.Close()
+	}
+
+	var identityConfig *service.IdentityLimiterConfig
+	var identityAuditor service.IdentityAuditor
+	if cfg.Booking.IdentityLimitsEnabled {
+		identityConfig = &service.IdentityLimiterConfig{
+			Limits: map[string]int{
+				dto.IdentityKindPhone:    cfg.Booking.IdentityLimitPhone,
+				dto.IdentityKindCard:     cfg.Booking.IdentityLimitCard,
+				dto.IdentityKindDevice:   cfg.Booking.IdentityLimitDevice,
+				dto.IdentityKindIPSubnet: cfg.Booking.IdentityLimitIPSubnet,
+			},
+			Window: cfg.Booking.IdentityLimitWindow,
+			Secret: fingerprintSecret,
+		}
+		identityAuditor = auditLogger
+		appLog.Info(fmt.Sprintf("Identity limits: phone=%d, card=%d, device=%d, ip_subnet=%d, window=%v",
+			cfg.Booking.IdentityLimitPhone, cfg.Booking.IdentityLimitCard, cfg.Booking.IdentityLimitDevice,
+			cfg.Booking.IdentityLimitIPSubnet, cfg.Booking.IdentityLimitWindow))
+	}
+
+	// Bot screening of queue joins; every join a check scores is written to
+	// the audit log for review after the sale
+	var joinScreener service.JoinScreener
+	if cfg.Booking.JoinScreeningEnabled {
+		joinScreener = service.NewJoinScreener([]service.JoinCheck{
+			service.NewChallengeCheck(cfg.Booking.JoinChallengeMissingScore, cfg.Booking.JoinChallengeFailedScore),
+			service.NewJoinVelocityCheck(queueRepo, &service.JoinVelocityConfig{
+				PerIP:  cfg.Booking.JoinVelocityPerIP,
+				PerASN: cfg.Booking.JoinVelocityPerASN,
+				Window: cfg.Booking.JoinVelocityWindow,
+				Score:  cfg.Booking.JoinVelocityScore,
+				Secret: fingerprintSecret,
+			}),
+			service.NewJoinDeviceCheck(queueRepo, &service.JoinDeviceConfig{
+				MaxUsers: cfg.Booking.JoinDeviceMaxUsers,
+				Score:    cfg.Booking.JoinDeviceScore,
+				Secret:   fingerprintSecret,
+			}),
+		}, auditLogger, &service.JoinScreenerConfig{
+			ParkScore:   cfg.Booking.JoinScreenParkScore,
+			RejectScore: cfg.Booking.JoinScreenRejectScore,
+		})
+		appLog.Info(fmt.Sprintf("Join screening: park at %d, reject at %d", cfg.Booking.JoinScreenParkScore, cfg.Booking.JoinScreenRejectScore))
+	}
+
 	container := di.NewContainer(&di.ContainerConfig{
 		DB:              db,
 		Redis:           redisClient,
@@ -215,8 +332,10 @@ func main() {
 			MaxQueueSize:         0, // Unlimited
 			EstimatedWaitPerUser: 3, // 3 seconds per user
 			JWTSecret:            cfg.JWT.Secret,
+			JoinScreener:         joinScreener,
 		},
 		TicketServiceURL: cfg.Services.TicketServiceURL, // For auto-sync zone on ZONE_NOT_FOUND
+		AuthServiceURL:   cfg.Services.AuthServiceURL,   // For resolving comp ticket recipients
 		SagaProducer:     sagaProducer,                 // For post-payment saga
 		SagaStore:        sagaStore,                    // For saga state persistence
 		SagaServiceConfig: &service.SagaServiceConfig{
@@ -226,14 +345,73 @@ func main() {
 		BookingHandlerConfig: &handler.BookingHandlerConfig{
 			RequireQueuePass: requireQueuePass,
 		},
+		DeadLetterStore: pkgsaga.NewPostgresStore(db.Pool()),
+		DLQPublisher:    dlqPublisher,
+		DLQServiceConfig: &service.DLQServiceConfig{
+			MaxReplayBatch: 500,
+			Replay: &retry.ReplayConfig{
+				RatePerSecond: 50, // Keep replays from flooding consumers that just recovered
+				Source:        "booking-service-admin",
+			},
+		},
+		InventoryReader: bookingRepo,
+		InventoryStore:  reservationRepo,
+		ReconcilerConfig: &service.InventoryReconcilerConfig{
+			Interval:      cfg.Booking.InventoryReconcileInterval,
+			AutoCorrect:   cfg.Booking.InventoryReconcileAutoCorrect,
+			MaxCorrection: cfg.Booking.InventoryReconcileMaxCorrect,
+		},
+		FallbackStore: fallbackStore,
+		LuaScriptsDir: cfg.Booking.LuaScriptsDir,
+		CompStore:     bookingRepo,
+		CompServiceConfig: &service.CompServiceConfig{
+			MaxSeats: cfg.Booking.CompMaxSeatsPerRequest,
+		},
+		AvailabilityConfig: availabilityConfig,
+		QueueSocketConfig:  queueSocketConfig,
+		IdentityLimitStore: reservationRepo,
+		IdentityConfig:     identityConfig,
+		IdentityAuditor:    identityAuditor,
+		PaymentServiceURL:  cfg.Services.PaymentServiceURL,
+		AdmissionHealth:    admissionHealth,
 	})
 
+	// Start periodic inventory reconciliation (replicas coordinate through a Redis lock)
+	reconcileCtx, stopReconcile := context.WithCancel(context.Background())
+	defer stopReconcile()
+	if container.InventoryReconciler != nil && cfg.Booking.InventoryReconcileInterval > 0 {
+		go container.InventoryReconciler.Run(reconcileCtx)
+		appLog.Info(fmt.Sprintf("Inventory reconciler started: interval=%v, auto_correct=%v, max_correct=%d",
+			cfg.Booking.InventoryReconcileInterval, cfg.Booking.InventoryReconcileAutoCorrect, cfg.Booking.InventoryReconcileMaxCorrect))
+	}
+
+	// Start the reservation failover (probes Redis and resyncs zones served from Postgres)
+	failoverCtx, stopFailover := context.WithCancel(context.Background())
+	defer stopFailover()
+	if container.ReservationFailover != nil {
+		go container.ReservationFailover.Run(failoverCtx)
+		appLog.Info("Reservation failover started: Postgres serves reservations while Redis is unavailable")
+	}
+
+	// Start the availability hub (pushes coalesced zone availability to SSE streams)
+	availabilityCtx, stopAvailability := context.WithCancel(context.Background())
+	defer stopAvailability()
+	if container.AvailabilityHub != nil {
+		go container.AvailabilityHub.Run(availabilityCtx)
+		appLog.Info(fmt.Sprintf("Availability hub started: interval=%v", cfg.Booking.AvailabilityStreamInterval))
+	}
+
 	// Setup Gin with optimized settings
 	gin.SetMode(gin.ReleaseMode) // Always use release mode for performance
 	gin.DisableConsoleColor()
 
 	router := gin.New()
 
+	// Believe the gateway's X-Client-IP only from SERVER_TRUSTED_PROXIES
+	if err := handler.TrustGatewayClientIP(router, cfg.Server.TrustedProxies); err != nil {
+		appLog.Fatal(fmt.Sprintf("Invalid SERVER_TRUSTED_PROXIES: %v", err))
+	}
+
 	// Use minimal middleware for performance
 	router.Use(gin.Recovery())
 
@@ -308,6 +486,11 @@ func main() {
 			// Stream position updates via SSE (reduces polling overhead by 50x)
 			queue.GET("/position/:event_id/stream", container.QueueHandler.StreamPosition)
 
+			// Stream positions of several queues over one WebSocket
+			if container.QueueSocketHandler != nil {
+				queue.GET("/ws", container.QueueSocketHandler.StreamPositions)
+			}
+
 			// Leave queue
 			queue.DELETE("/leave", container.QueueHandler.LeaveQueue)
 
@@ -315,6 +498,15 @@ func main() {
 			queue.GET("/status/:event_id", container.QueueHandler.GetQueueStatus)
 		}
 
+		// Availability routes - public live seat counts for event pages
+		if container.AvailabilityHandler != nil {
+			availability := v1.Group("/availability")
+			{
+				// Stream zone availability of a show via SSE (snapshot, then coalesced changes)
+				availability.GET("/shows/:show_id/stream", container.AvailabilityHandler.StreamShowAvailability)
+			}
+		}
+
 		// Admin routes - for managing inventory sync
 		admin := v1.Group("/admin")
 		{
@@ -323,6 +515,44 @@ func main() {
 
 			// Get inventory status (PostgreSQL vs Redis)
 			admin.GET("/inventory-status", container.AdminHandler.GetInventoryStatus)
+
+			// Inventory drift reconciliation (last report, on-demand run)
+			admin.GET("/inventory-reconciliation", container.AdminHandler.GetInventoryReconciliation)
+			admin.POST("/inventory-reconciliation/run", container.AdminHandler.RunInventoryReconciliation)
+
+			// Per-event virtual queue config, including FIFO or lottery admission
+			admin.GET("/events/:event_id/queue-config", container.QueueAdminHandler.GetQueueConfig)
+			admin.PUT("/events/:event_id/queue-config", container.QueueAdminHandler.SetQueueConfig)
+
+			// Operator controls: pause/resume release, drain with a broadcast, bulk-expire passes
+			admin.POST("/events/:event_id/queue/pause", container.QueueAdminHandler.PauseQueue)
+			admin.POST("/events/:event_id/queue/resume", container.QueueAdminHandler.ResumeQueue)
+			admin.POST("/events/:event_id/queue/drain", container.QueueAdminHandler.DrainQueue)
+			admin.POST("/events/:event_id/queue/expire-passes", container.QueueAdminHandler.ExpireQueuePasses)
+
+			// Lua script versions and hot reload (per replica)
+			admin.GET("/scripts", container.ScriptHandler.ListScripts)
+			admin.POST("/scripts/reload", container.ScriptHandler.ReloadScripts)
+
+			// Dead letter queue browsing, replay and purge
+			if container.DLQHandler != nil {
+				admin.GET("/dlq", container.DLQHandler.ListDeadLetters)
+				admin.GET("/dlq/:id", container.DLQHandler.GetDeadLetter)
+				admin.POST("/dlq/replay", container.DLQHandler.ReplayDeadLetters)
+				admin.POST("/dlq/mark-processed", container.DLQHandler.MarkProcessed)
+				admin.POST("/dlq/purge", container.DLQHandler.PurgeDeadLetters)
+			}
+
+			// Complimentary tickets issued from a zone's held or comp allocation
+			if container.CompHandler != nil {
+				admin.POST("/comps", middleware.IdempotencyMiddleware(idempotencyConfig), container.CompHandler.IssueComps)
+			}
+
+			// Per-event purchase limits on phone, card, device and IP subnet
+			if container.IdentityHandler != nil {
+				admin.GET("/events/:event_id/identity-limits", container.IdentityHandler.GetIdentityLimits)
+				admin.PUT("/events/:event_id/identity-limits", container.IdentityHandler.SetIdentityLimits)
+			}
 		}
 
 		// Saga routes - async booking via saga pattern
@@ -372,6 +602,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	appLog.Info("Shutting down server...")
+	stopReconcile()
 
 	// Give outstanding requests 30 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
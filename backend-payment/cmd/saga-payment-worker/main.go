@@ -17,6 +17,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/retry"
 )
 
 const (
@@ -132,8 +133,27 @@ func main() {
 		Currency: "THB",
 	})
 
-	// Initialize Kafka consumer
-	consumerCfg := &kafka.ConsumerConfig{
+	// Initialize Kafka producer
+	producerCfg := &kafka.ProducerConfig{
+		Brokers:  cfg.Kafka.Brokers,
+		ClientID: "saga-payment-worker-producer",
+	}
+	producer, err := kafka.NewProducer(ctx, producerCfg)
+	if err != nil {
+		appLog.Fatal(fmt.Sprintf("Failed to create Kafka producer: %v", err))
+	}
+	defer producer.Close()
+	appLog.Info("Kafka producer connected")
+
+	// Commands that fail go to <topic>.dlq before their offsets are committed
+	dlqPublisher := retry.NewKafkaDLQPublisher(&retry.KafkaProducerAdapter{Producer: producer}, &retry.DLQConfig{
+		TopicSuffix: ".dlq",
+		Source:      "saga-payment-worker",
+	})
+
+	// Initialize Kafka runner. Commands are keyed by saga ID, so per-key
+	// ordering keeps each saga's payment and refund in order.
+	runnerCfg := &kafka.RunnerConfig{
 		Brokers: cfg.Kafka.Brokers,
 		GroupID: "saga-payment-worker",
 		Topics: []string{
@@ -144,44 +164,27 @@ func main() {
 		MaxRetries:     3,
 		RetryInterval:  2 * time.Second,
 		SessionTimeout: 30 * time.Second,
+		Ordering:       kafka.OrderByKey,
+		Concurrency:    5,
+		DrainTimeout:   20 * time.Second,
+		ErrorHandler:   dlqErrorHandler(dlqPublisher, appLog),
 	}
-	consumer, err := kafka.NewConsumer(ctx, consumerCfg)
+	runner, err := kafka.NewRunner(ctx, runnerCfg)
 	if err != nil {
-		appLog.Fatal(fmt.Sprintf("Failed to create Kafka consumer: %v", err))
+		appLog.Fatal(fmt.Sprintf("Failed to create Kafka runner: %v", err))
 	}
-	defer consumer.Close()
-	appLog.Info("Kafka consumer connected")
-
-	// Initialize Kafka producer
-	producerCfg := &kafka.ProducerConfig{
-		Brokers:  cfg.Kafka.Brokers,
-		ClientID: "saga-payment-worker-producer",
-	}
-	producer, err := kafka.NewProducer(ctx, producerCfg)
-	if err != nil {
-		appLog.Fatal(fmt.Sprintf("Failed to create Kafka producer: %v", err))
-	}
-	defer producer.Close()
-	appLog.Info("Kafka producer connected")
+	defer runner.Close()
+	appLog.Info("Kafka consumer runner connected")
 
 	// Start worker
+	workerDone := make(chan struct{})
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				records, err := consumer.Poll(ctx)
-				if err != nil {
-					appLog.Error(fmt.Sprintf("Failed to poll: %v", err))
-					time.Sleep(time.Second)
-					continue
-				}
-
-				for _, record := range records {
-					processRecord(ctx, record, paymentService, producer, consumer, appLog)
-				}
-			}
+		defer close(workerDone)
+		err := runner.Run(ctx, func(ctx context.Context, record *kafka.Record) error {
+			return processRecord(ctx, record, paymentService, producer, appLog)
+		})
+		if err != nil && err != context.Canceled {
+			appLog.Error(fmt.Sprintf("Worker error: %v", err))
 		}
 	}()
 
@@ -195,30 +198,56 @@ func main() {
 	appLog.Info("Shutting down worker...")
 	cancel()
 
-	time.Sleep(2 * time.Second)
+	// Wait for in-flight commands to drain and their offsets to be committed
+	<-workerDone
 	appLog.Info("Worker exited gracefully")
 }
 
-func processRecord(ctx context.Context, record *kafka.Record, paymentService service.PaymentService, producer *kafka.Producer, consumer *kafka.Consumer, appLog *logger.Logger) {
+// dlqErrorHandler returns a kafka.ErrorHandler that sends records whose
+// handler failed to the DLQ. A record is marked handled only once it is in
+// the DLQ; when sending fails it is retried instead of being dropped.
+func dlqErrorHandler(publisher retry.DLQPublisher, appLog *logger.Logger) kafka.ErrorHandler {
+	return func(ctx context.Context, record *kafka.Record, err error) error {
+		appLog.Error(fmt.Sprintf("[ALERT] Command failed, sending to DLQ: topic=%s, key=%s, error=%v",
+			record.Topic, string(record.Key), err))
+
+		payload := json.RawMessage(record.Value)
+		if !json.Valid(record.Value) {
+			payload, _ = json.Marshal(string(record.Value))
+		}
+		now := time.Now()
+		return publisher.PublishToDLQ(ctx, &retry.DLQMessage{
+			ID:             fmt.Sprintf("%s-%d-%d", record.Topic, record.Partition, record.Offset),
+			OriginalTopic:  record.Topic,
+			OriginalKey:    string(record.Key),
+			Payload:        payload,
+			Headers:        record.Headers,
+			Error:          err.Error(),
+			Attempts:       1,
+			FirstAttemptAt: now,
+			LastAttemptAt:  now,
+		})
+	}
+}
+
+func processRecord(ctx context.Context, record *kafka.Record, paymentService service.PaymentService, producer kafka.MessageProducer, appLog *logger.Logger) error {
 	switch record.Topic {
 	case TopicProcessPaymentCommand:
-		handleProcessPayment(ctx, record, paymentService, producer, consumer, appLog)
+		return handleProcessPayment(ctx, record, paymentService, producer, appLog)
 	case TopicRefundPaymentCommand:
-		handleRefundPayment(ctx, record, paymentService, producer, consumer, appLog)
+		return handleRefundPayment(ctx, record, paymentService, appLog)
 	default:
 		appLog.Warn(fmt.Sprintf("Unknown topic: %s", record.Topic))
-		consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 }
 
-func handleProcessPayment(ctx context.Context, record *kafka.Record, paymentService service.PaymentService, producer *kafka.Producer, consumer *kafka.Consumer, appLog *logger.Logger) {
+func handleProcessPayment(ctx context.Context, record *kafka.Record, paymentService service.PaymentService, producer kafka.MessageProducer, appLog *logger.Logger) error {
 	startTime := time.Now()
 
 	var command SagaCommand
 	if err := json.Unmarshal(record.Value, &command); err != nil {
-		appLog.Error(fmt.Sprintf("Failed to unmarshal command: %v", err))
-		consumer.CommitRecords(ctx, []*kafka.Record{record})
-		return
+		return fmt.Errorf("failed to unmarshal command: %w", err)
 	}
 
 	appLog.Info(fmt.Sprintf("Processing payment: saga_id=%s", command.SagaID))
@@ -305,33 +334,27 @@ func handleProcessPayment(ctx context.Context, record *kafka.Record, paymentServ
 	}
 
 	if err := producer.ProduceJSON(ctx, topic, command.SagaID, event, nil); err != nil {
-		appLog.Error(fmt.Sprintf("Failed to send event: %v", err))
+		return fmt.Errorf("failed to send event: saga_id=%s: %w", command.SagaID, err)
 	}
-
-	consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
 
-func handleRefundPayment(ctx context.Context, record *kafka.Record, paymentService service.PaymentService, producer *kafka.Producer, consumer *kafka.Consumer, appLog *logger.Logger) {
+func handleRefundPayment(ctx context.Context, record *kafka.Record, paymentService service.PaymentService, appLog *logger.Logger) error {
 	var command CompensationCommand
 	if err := json.Unmarshal(record.Value, &command); err != nil {
-		appLog.Error(fmt.Sprintf("Failed to unmarshal command: %v", err))
-		consumer.CommitRecords(ctx, []*kafka.Record{record})
-		return
+		return fmt.Errorf("failed to unmarshal command: %w", err)
 	}
 
 	appLog.Info(fmt.Sprintf("Processing refund: saga_id=%s", command.SagaID))
 
 	paymentID := getString(command.OriginalStepData, "payment_id")
 	if paymentID != "" {
-		_, err := paymentService.RefundPayment(ctx, paymentID, command.Reason)
-		if err != nil {
-			appLog.Error(fmt.Sprintf("Failed to refund payment: %v", err))
-		} else {
-			appLog.Info(fmt.Sprintf("Payment refunded: payment_id=%s", paymentID))
+		if _, err := paymentService.RefundPayment(ctx, paymentID, command.Reason); err != nil {
+			return fmt.Errorf("failed to refund payment: payment_id=%s: %w", paymentID, err)
 		}
+		appLog.Info(fmt.Sprintf("Payment refunded: payment_id=%s", paymentID))
 	}
-
-	consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
 
 func getString(data map[string]interface{}, key string) string {
@@ -2,7 +2,7 @@ package consumer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -12,19 +12,25 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-payment/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/retry"
 )
 
-// BookingConsumer consumes booking events from Kafka
+// BookingConsumer consumes booking events from Kafka.
+// Offsets are committed by the runner once a handler returns.
 type BookingConsumer struct {
-	consumer       *kafka.Consumer
-	producer       *kafka.Producer
+	runner         kafka.RecordRunner
+	producer       kafka.MessageProducer
 	paymentService service.PaymentService
 	logger         *logger.Logger
 	config         *BookingConsumerConfig
 	wg             sync.WaitGroup
-	stopCh         chan struct{}
+	cancel         context.CancelFunc
 	mu             sync.RWMutex
 	running        bool
+
+	// failures counts failed attempts per record still being retried
+	failuresMu sync.Mutex
+	failures   map[string]int
 }
 
 // BookingConsumerConfig contains configuration for the booking consumer
@@ -36,7 +42,8 @@ type BookingConsumerConfig struct {
 	MaxRetries     int
 	RetryInterval  time.Duration
 	ProcessTimeout time.Duration
-	WorkerCount    int
+	// WorkerCount is the runner's concurrency (RunnerConfig.Concurrency)
+	WorkerCount int
 }
 
 // DefaultBookingConsumerConfig returns default configuration
@@ -64,19 +71,33 @@ func NewBookingConsumer(
 		cfg = DefaultBookingConsumerConfig()
 	}
 
-	// Create Kafka consumer
-	consumerCfg := &kafka.ConsumerConfig{
+	c := &BookingConsumer{
+		paymentService: paymentService,
+		logger:         log,
+		config:         cfg,
+		failures:       make(map[string]int),
+	}
+
+	// Create Kafka runner. Events are keyed by booking ID, so per-key ordering
+	// keeps each booking's events in order. A booking whose payment event could
+	// not be published is retried up to MaxRetries times; permanent failures
+	// are skipped (see handleError).
+	runnerCfg := &kafka.RunnerConfig{
 		Brokers:       cfg.Brokers,
 		GroupID:       cfg.GroupID,
 		Topics:        []string{cfg.Topic},
 		ClientID:      "payment-service-consumer",
 		MaxRetries:    cfg.MaxRetries,
 		RetryInterval: cfg.RetryInterval,
+		Ordering:      kafka.OrderByKey,
+		Concurrency:   cfg.WorkerCount,
+		RetryBackoff:  cfg.RetryInterval,
+		ErrorHandler:  c.handleError,
 	}
 
-	consumer, err := kafka.NewConsumer(ctx, consumerCfg)
+	runner, err := kafka.NewRunner(ctx, runnerCfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+		return nil, fmt.Errorf("failed to create kafka runner: %w", err)
 	}
 
 	// Create Kafka producer for payment events
@@ -89,18 +110,13 @@ func NewBookingConsumer(
 
 	producer, err := kafka.NewProducer(ctx, producerCfg)
 	if err != nil {
-		consumer.Close()
+		runner.Close()
 		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
 	}
 
-	return &BookingConsumer{
-		consumer:       consumer,
-		producer:       producer,
-		paymentService: paymentService,
-		logger:         log,
-		config:         cfg,
-		stopCh:         make(chan struct{}),
-	}, nil
+	c.runner = runner
+	c.producer = producer
+	return c, nil
 }
 
 // Start starts the consumer
@@ -111,113 +127,102 @@ func (c *BookingConsumer) Start(ctx context.Context) error {
 		return fmt.Errorf("consumer is already running")
 	}
 	c.running = true
+	runCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
 	c.mu.Unlock()
 
 	c.logger.Info("Starting booking consumer...")
 
-	// Start worker goroutines
-	recordsCh := make(chan *kafka.Record, c.config.WorkerCount*10)
-
-	// Start workers
-	for i := 0; i < c.config.WorkerCount; i++ {
-		c.wg.Add(1)
-		go c.worker(ctx, i, recordsCh)
-	}
-
-	// Start polling goroutine
 	c.wg.Add(1)
-	go c.poll(ctx, recordsCh)
+	go func() {
+		defer c.wg.Done()
+		if err := c.runner.Run(runCtx, c.handleRecord); err != nil && runCtx.Err() == nil {
+			c.logger.Error(fmt.Sprintf("Booking consumer runner stopped: %v", err))
+		}
+	}()
 
 	return nil
 }
 
-// poll continuously polls for new records
-func (c *BookingConsumer) poll(ctx context.Context, recordsCh chan<- *kafka.Record) {
-	defer c.wg.Done()
-	defer close(recordsCh)
-
-	for {
-		select {
-		case <-ctx.Done():
-			c.logger.InfoContext(ctx, "Consumer context cancelled, stopping poll...")
-			return
-		case <-c.stopCh:
-			c.logger.Info("Consumer stop signal received, stopping poll...")
-			return
-		default:
-			records, err := c.consumer.Poll(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				c.logger.ErrorContext(ctx, fmt.Sprintf("Failed to poll records: %v", err))
-				time.Sleep(time.Second)
-				continue
-			}
-
-			for _, record := range records {
-				select {
-				case recordsCh <- record:
-				case <-ctx.Done():
-					return
-				case <-c.stopCh:
-					return
-				}
-			}
-		}
+// handleRecord processes a record and forgets its failed attempts once it succeeds
+func (c *BookingConsumer) handleRecord(ctx context.Context, record *kafka.Record) error {
+	err := c.processRecord(ctx, record)
+	if err == nil {
+		c.failuresMu.Lock()
+		delete(c.failures, recordPosition(record))
+		c.failuresMu.Unlock()
 	}
+	return err
 }
 
-// worker processes records from the channel
-func (c *BookingConsumer) worker(ctx context.Context, id int, recordsCh <-chan *kafka.Record) {
-	defer c.wg.Done()
+// handleError is the runner's error policy: permanent errors are logged and
+// skipped, transient ones are retried until MaxRetries attempts have failed
+func (c *BookingConsumer) handleError(ctx context.Context, record *kafka.Record, err error) error {
+	var permErr *retry.PermanentError
+	if errors.As(err, &permErr) {
+		c.logger.ErrorContext(ctx, fmt.Sprintf("Skipping booking event at %s: %v", recordPosition(record), err))
+		return nil
+	}
 
-	c.logger.InfoContext(ctx, fmt.Sprintf("Worker %d started", id))
+	position := recordPosition(record)
+	c.failuresMu.Lock()
+	c.failures[position]++
+	attempts := c.failures[position]
+	if attempts >= c.config.MaxRetries {
+		delete(c.failures, position)
+	}
+	c.failuresMu.Unlock()
 
-	for record := range recordsCh {
-		if err := c.processRecord(ctx, record); err != nil {
-			c.logger.ErrorContext(ctx, fmt.Sprintf("Worker %d failed to process record: %v", id, err))
-		}
+	if attempts < c.config.MaxRetries {
+		return err
 	}
+	c.logger.ErrorContext(ctx, fmt.Sprintf("Giving up on booking event at %s after %d attempts: %v", position, attempts, err))
+	return nil
+}
 
-	c.logger.InfoContext(ctx, fmt.Sprintf("Worker %d stopped", id))
+// recordPosition identifies a record by its topic, partition and offset
+func recordPosition(record *kafka.Record) string {
+	return fmt.Sprintf("%s/%d/%d", record.Topic, record.Partition, record.Offset)
 }
 
 // processRecord processes a single Kafka record
 func (c *BookingConsumer) processRecord(ctx context.Context, record *kafka.Record) error {
 	// Parse booking event
 	var event BookingEvent
-	if err := json.Unmarshal(record.Value, &event); err != nil {
+	if err := record.Decode(&event); err != nil {
 		c.logger.ErrorContext(ctx, fmt.Sprintf("Failed to unmarshal booking event: %v", err))
-		// Commit the record anyway to avoid reprocessing invalid messages
-		return c.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		// Skip invalid messages rather than reprocessing them
+		return nil
 	}
 
+	bookingID := ""
+	if event.BookingData != nil {
+		bookingID = event.BookingData.BookingID
+	}
 	c.logger.InfoContext(ctx, fmt.Sprintf("Received booking event: type=%s, booking_id=%s",
-		event.EventType, event.BookingData.BookingID))
+		event.EventType, bookingID))
 
 	// Only process booking.created events
 	if event.EventType != BookingEventCreated {
 		c.logger.InfoContext(ctx, fmt.Sprintf("Skipping event type: %s", event.EventType))
-		return c.consumer.CommitRecords(ctx, []*kafka.Record{record})
+		return nil
 	}
 
 	// Process the booking event
 	if err := c.handleBookingCreated(ctx, &event); err != nil {
 		c.logger.ErrorContext(ctx, fmt.Sprintf("Failed to handle booking.created event: %v", err))
-		// Don't commit on error - let it be reprocessed
+		// Don't mark it handled on error - let it be reprocessed
 		return err
 	}
 
-	// Commit the record
-	return c.consumer.CommitRecords(ctx, []*kafka.Record{record})
+	return nil
 }
 
 // handleBookingCreated handles a booking.created event
 func (c *BookingConsumer) handleBookingCreated(ctx context.Context, event *BookingEvent) error {
 	data := event.BookingData
 	if data == nil {
-		return fmt.Errorf("booking data is nil")
+		return retry.Permanent(fmt.Errorf("booking data is nil"))
 	}
 
 	c.logger.InfoContext(ctx, fmt.Sprintf("Processing booking.created: booking_id=%s, user_id=%s, amount=%.2f %s",
@@ -328,13 +333,13 @@ func (c *BookingConsumer) Stop() error {
 	c.logger.Info("Stopping booking consumer...")
 
 	// Signal stop
-	close(c.stopCh)
+	c.cancel()
 
-	// Wait for goroutines to finish
+	// Wait for in-flight records to drain and their offsets to be committed
 	c.wg.Wait()
 
 	// Close connections
-	c.consumer.Close()
+	c.runner.Close()
 	c.producer.Close()
 
 	c.logger.Info("Booking consumer stopped")
@@ -3,6 +3,7 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-payment/internal/gateway"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-payment/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-payment/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 )
 
 // mockPaymentService implements service.PaymentService for testing
@@ -293,3 +296,48 @@ func TestPaymentServiceFailure(t *testing.T) {
 		t.Error("Expected error message to be set")
 	}
 }
+
+func TestBookingConsumer_HandleError(t *testing.T) {
+	c := &BookingConsumer{
+		logger:   logger.Get(),
+		config:   &BookingConsumerConfig{MaxRetries: 3},
+		failures: make(map[string]int),
+	}
+	ctx := context.Background()
+	record := &kafka.Record{Topic: "booking-events", Partition: 1, Offset: 42, Value: []byte(`{"event_type":"booking.created"}`)}
+
+	// A booking event without booking data can never succeed
+	err := c.processRecord(ctx, record)
+	if err == nil {
+		t.Fatal("Expected an error for an event without booking data")
+	}
+	if got := c.handleError(ctx, record, err); got != nil {
+		t.Errorf("Expected a permanent error to be skipped, got %v", got)
+	}
+
+	// Transient errors are retried until MaxRetries attempts have failed
+	transient := errors.New("broker unavailable")
+	for attempt := 1; attempt < 3; attempt++ {
+		if got := c.handleError(ctx, record, transient); got == nil {
+			t.Fatalf("Expected attempt %d to be retried", attempt)
+		}
+	}
+	if got := c.handleError(ctx, record, transient); got != nil {
+		t.Errorf("Expected the record to be given up after 3 attempts, got %v", got)
+	}
+	if len(c.failures) != 0 {
+		t.Errorf("Expected failure counts to be cleared, got %v", c.failures)
+	}
+
+	// A success forgets earlier failures
+	if got := c.handleError(ctx, record, transient); got == nil {
+		t.Fatal("Expected the first attempt to be retried")
+	}
+	skipped := &kafka.Record{Topic: "booking-events", Partition: 1, Offset: 42, Value: []byte(`{"event_type":"booking.cancelled"}`)}
+	if err := c.handleRecord(ctx, skipped); err != nil {
+		t.Fatalf("handleRecord() error = %v", err)
+	}
+	if len(c.failures) != 0 {
+		t.Errorf("Expected failure counts to be cleared after success, got %v", c.failures)
+	}
+}
@@ -0,0 +1,82 @@
+package consumer
+
+import (
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// UnmarshalProto decodes a booking event encoded with the
+// booking.events.v1.BookingEvent schema (backend-booking/api/proto/booking_event.proto)
+func (e *BookingEvent) UnmarshalProto(data []byte) error {
+	*e = BookingEvent{}
+	return kafka.RangeProtoFields(data, func(num protowire.Number, f kafka.ProtoField) error {
+		var err error
+		switch num {
+		case 1:
+			e.EventID = f.String()
+		case 2:
+			e.EventType = BookingEventType(f.String())
+		case 3:
+			e.OccurredAt, err = f.Timestamp()
+		case 4:
+			e.Version = int(f.Int64())
+		case 5:
+			e.BookingData = &BookingEventData{}
+			err = e.BookingData.unmarshalProto(f.Bytes())
+		}
+		return err
+	})
+}
+
+func (d *BookingEventData) unmarshalProto(data []byte) error {
+	return kafka.RangeProtoFields(data, func(num protowire.Number, f kafka.ProtoField) error {
+		var err error
+		switch num {
+		case 1:
+			d.BookingID = f.String()
+		case 2:
+			d.TenantID = f.String()
+		case 3:
+			d.UserID = f.String()
+		case 4:
+			d.EventID = f.String()
+		case 5:
+			d.ShowID = f.String()
+		case 6:
+			d.ZoneID = f.String()
+		case 7:
+			d.Quantity = int(f.Int64())
+		case 8:
+			d.UnitPrice = f.Double()
+		case 9:
+			d.TotalPrice = f.Double()
+		case 10:
+			d.Currency = f.String()
+		case 11:
+			d.Status = f.String()
+		case 12:
+			d.PaymentID = f.String()
+		case 13:
+			d.ConfirmationCode = f.String()
+		case 14:
+			d.ReservedAt, err = f.Timestamp()
+		case 15:
+			d.ConfirmedAt, err = optionalTimestamp(f)
+		case 16:
+			d.CancelledAt, err = optionalTimestamp(f)
+		case 17:
+			d.ExpiresAt, err = f.Timestamp()
+		}
+		return err
+	})
+}
+
+func optionalTimestamp(f kafka.ProtoField) (*time.Time, error) {
+	t, err := f.Timestamp()
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
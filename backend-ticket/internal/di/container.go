@@ -5,14 +5,16 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/repository"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/service"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 )
 
 // Container holds all dependencies for the ticket service
 type Container struct {
 	// Infrastructure
-	DB    *database.PostgresDB
-	Redis *redis.Client
+	DB       *database.PostgresDB
+	Redis    *redis.Client
+	Producer kafka.MessageProducer
 
 	// Repositories
 	EventRepo    repository.EventRepository
@@ -24,6 +26,7 @@ type Container struct {
 
 	// Services
 	ZoneSyncer      service.ZoneSyncer
+	Schedules       service.SchedulePublisher
 	EventService    service.EventService
 	ShowService     service.ShowService
 	ShowZoneService service.ShowZoneService
@@ -43,13 +46,20 @@ type Container struct {
 type ContainerConfig struct {
 	DB    *database.PostgresDB
 	Redis *redis.Client
+	// Producer publishes sale windows to the booking service's queue
+	// scheduler (optional)
+	Producer kafka.MessageProducer
+	// ZoneShards is the booking service's ZONE_INVENTORY_SHARDS, whose
+	// shard counters zone syncs clear
+	ZoneShards int
 }
 
 // NewContainer creates a new dependency injection container
 func NewContainer(cfg *ContainerConfig) *Container {
 	c := &Container{
-		DB:    cfg.DB,
-		Redis: cfg.Redis,
+		DB:       cfg.DB,
+		Redis:    cfg.Redis,
+		Producer: cfg.Producer,
 	}
 
 	// Initialize repositories
@@ -68,9 +78,12 @@ func NewContainer(cfg *ContainerConfig) *Container {
 	// c.TicketTypeRepo = repository.NewPostgresTicketTypeRepository(c.DB.Pool())
 
 	// Initialize services
-	c.ZoneSyncer = service.NewZoneSyncer(c.ShowZoneRepo, c.ShowRepo, c.Redis)
-	c.EventService = service.NewEventService(c.EventRepo)
-	c.ShowService = service.NewShowService(c.ShowRepo, c.EventRepo, c.ZoneSyncer)
+	c.ZoneSyncer = service.NewZoneSyncer(c.ShowZoneRepo, c.ShowRepo, c.Redis, cfg.ZoneShards)
+	if c.Producer != nil {
+		c.Schedules = service.NewKafkaSchedulePublisher(c.EventRepo, c.ShowRepo, c.Producer)
+	}
+	c.EventService = service.NewEventService(c.EventRepo, c.Schedules)
+	c.ShowService = service.NewShowService(c.ShowRepo, c.EventRepo, c.ZoneSyncer, c.Schedules)
 	c.ShowZoneService = service.NewShowZoneService(c.ShowZoneRepo, c.ShowRepo, c.ZoneSyncer)
 	// c.TicketService = service.NewTicketService(c.TicketTypeRepo, c.EventRepo)
 	// c.VenueService = service.NewVenueService(c.VenueRepo, c.ZoneRepo, c.SeatRepo)
@@ -153,6 +153,39 @@ const (
 	ShowStatusCompleted = "completed"
 )
 
+// Allocation buckets a zone's unsold seats are split into
+const (
+	AllocationPublic = "public" // On sale (AvailableSeats), mirrored to zone:availability in Redis
+	AllocationHeld   = "held"   // Held back by the organizer (artist guests, sponsors, press)
+	AllocationComp   = "comp"   // Set aside for complimentary tickets
+)
+
+// AllocationIssued is not a bucket but the target of moves that issue held or
+// comp seats as complimentary tickets; issued seats count as sold
+const AllocationIssued = "issued"
+
+// IsValidAllocation reports whether bucket is a known allocation bucket
+func IsValidAllocation(bucket string) bool {
+	switch bucket {
+	case AllocationPublic, AllocationHeld, AllocationComp:
+		return true
+	}
+	return false
+}
+
+// AllocationMove records seats moved between allocation buckets of a zone
+// (maps to zone_allocation_moves table)
+type AllocationMove struct {
+	ID        string    `json:"id"`
+	ZoneID    string    `json:"zone_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Seats     int       `json:"seats"`
+	Reason    string    `json:"reason,omitempty"`
+	MovedBy   string    `json:"moved_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // ShowZone represents a zone/section for a specific show (maps to seat_zones table)
 // This allows different pricing and availability per show
 type ShowZone struct {
@@ -167,6 +200,8 @@ type ShowZone struct {
 	AvailableSeats int        `json:"available_seats"` // Seats still available
 	ReservedSeats  int        `json:"reserved_seats"`  // Currently reserved seats
 	SoldSeats      int        `json:"sold_seats"`      // Already sold seats
+	HeldSeats      int        `json:"held_seats"`      // Held back by the organizer, not on sale
+	CompSeats      int        `json:"comp_seats"`      // Set aside for complimentary tickets
 	MinPerOrder    int        `json:"min_per_order"`   // Min tickets per order
 	MaxPerOrder    int        `json:"max_per_order"`   // Max tickets per order
 	IsActive       bool       `json:"is_active"`       // Whether zone is active for sale
@@ -177,3 +212,16 @@ type ShowZone struct {
 	UpdatedAt      time.Time  `json:"updated_at"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
 }
+
+// AllocationSeats returns the seats in an allocation bucket
+func (z *ShowZone) AllocationSeats(bucket string) int {
+	switch bucket {
+	case AllocationPublic:
+		return z.AvailableSeats
+	case AllocationHeld:
+		return z.HeldSeats
+	case AllocationComp:
+		return z.CompSeats
+	}
+	return 0
+}
@@ -122,3 +122,73 @@ func TestTicketType_IsAvailable(t *testing.T) {
 		})
 	}
 }
+
+func TestNewEventSchedule(t *testing.T) {
+	base := time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := base.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		event     *Event
+		shows     []*Show
+		wantStart *time.Time
+		wantEnd   *time.Time
+	}{
+		{
+			name:      "event window only",
+			event:     &Event{ID: "event-1", BookingStartAt: at(0), BookingEndAt: at(48)},
+			wantStart: at(0),
+			wantEnd:   at(48),
+		},
+		{
+			name:  "earliest show sale start wins",
+			event: &Event{ID: "event-1", BookingStartAt: at(2), BookingEndAt: at(48)},
+			shows: []*Show{
+				{SaleStartAt: at(1), SaleEndAt: at(72)},
+				{SaleStartAt: at(3)},
+			},
+			wantStart: at(1),
+			wantEnd:   at(48),
+		},
+		{
+			name:  "latest show sale end without booking end",
+			event: &Event{ID: "event-1"},
+			shows: []*Show{
+				{SaleStartAt: at(1), SaleEndAt: at(24)},
+				{SaleStartAt: at(2), SaleEndAt: at(36)},
+			},
+			wantStart: at(1),
+			wantEnd:   at(36),
+		},
+		{
+			name:  "cancelled shows do not count",
+			event: &Event{ID: "event-1", BookingStartAt: at(2)},
+			shows: []*Show{
+				{SaleStartAt: at(1), SaleEndAt: at(24), Status: ShowStatusCancelled},
+			},
+			wantStart: at(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := NewEventSchedule(tt.event, tt.shows)
+			if !sameTime(schedule.SaleStartAt, tt.wantStart) {
+				t.Errorf("SaleStartAt = %v, want %v", schedule.SaleStartAt, tt.wantStart)
+			}
+			if !sameTime(schedule.SaleEndAt, tt.wantEnd) {
+				t.Errorf("SaleEndAt = %v, want %v", schedule.SaleEndAt, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
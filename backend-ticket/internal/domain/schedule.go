@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// EventScheduleTopic carries events' sale windows to the booking service,
+// whose queue scheduler opens and closes each event's queue by them
+const EventScheduleTopic = "event-schedules"
+
+// EventSchedule is the sale window of an event across its shows
+type EventSchedule struct {
+	EventID string `json:"event_id"`
+	Status  string `json:"status"`
+	// SaleStartAt is the earlier of the event's booking start and its
+	// shows' sale starts
+	SaleStartAt *time.Time `json:"sale_start_at,omitempty"`
+	// SaleEndAt is the event's booking end, or the latest of its shows'
+	// sale ends when it has none
+	SaleEndAt *time.Time `json:"sale_end_at,omitempty"`
+	// Deleted is set once the event is gone
+	Deleted   bool      `json:"deleted,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewEventSchedule works out an event's sale window from the event and its
+// shows; cancelled shows do not count
+func NewEventSchedule(event *Event, shows []*Show) *EventSchedule {
+	schedule := &EventSchedule{
+		EventID:     event.ID,
+		Status:      event.Status,
+		SaleStartAt: event.BookingStartAt,
+		SaleEndAt:   event.BookingEndAt,
+		UpdatedAt:   time.Now(),
+	}
+
+	var showsEndAt *time.Time
+	for _, show := range shows {
+		if show.Status == ShowStatusCancelled {
+			continue
+		}
+		if show.SaleStartAt != nil && (schedule.SaleStartAt == nil || show.SaleStartAt.Before(*schedule.SaleStartAt)) {
+			schedule.SaleStartAt = show.SaleStartAt
+		}
+		if show.SaleEndAt != nil && (showsEndAt == nil || show.SaleEndAt.After(*showsEndAt)) {
+			showsEndAt = show.SaleEndAt
+		}
+	}
+	if schedule.SaleEndAt == nil {
+		schedule.SaleEndAt = showsEndAt
+	}
+	return schedule
+}
+
+// NewDeletedEventSchedule returns the schedule of an event that is gone
+func NewDeletedEventSchedule(eventID string) *EventSchedule {
+	return &EventSchedule{EventID: eventID, Deleted: true, UpdatedAt: time.Now()}
+}
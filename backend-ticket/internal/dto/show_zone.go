@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
+
 // CreateShowZoneRequest represents the request to create a new show zone
 type CreateShowZoneRequest struct {
 	ShowID      string  `json:"-"` // Set from URL param
@@ -61,6 +63,8 @@ type ShowZoneResponse struct {
 	AvailableSeats int     `json:"available_seats"`
 	ReservedSeats  int     `json:"reserved_seats"`
 	SoldSeats      int     `json:"sold_seats"`
+	HeldSeats      int     `json:"held_seats"`
+	CompSeats      int     `json:"comp_seats"`
 	MinPerOrder    int     `json:"min_per_order"`
 	MaxPerOrder    int     `json:"max_per_order"`
 	IsActive       bool    `json:"is_active"`
@@ -71,6 +75,71 @@ type ShowZoneResponse struct {
 	UpdatedAt      string  `json:"updated_at"`
 }
 
+// MoveAllocationRequest represents the request to move seats between
+// allocation buckets (public, held, comp) of a zone
+type MoveAllocationRequest struct {
+	From   string `json:"from" binding:"required"`
+	To     string `json:"to" binding:"required"`
+	Seats  int    `json:"seats" binding:"required,gt=0"`
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// Validate validates the MoveAllocationRequest
+func (r *MoveAllocationRequest) Validate() (bool, string) {
+	if !domain.IsValidAllocation(r.From) || !domain.IsValidAllocation(r.To) {
+		return false, "Buckets must be one of public, held, comp"
+	}
+	if r.From == r.To {
+		return false, "Source and target buckets must differ"
+	}
+	if r.Seats <= 0 {
+		return false, "Seats must be greater than 0"
+	}
+	return true, ""
+}
+
+// IssueAllocationRequest represents the request to issue seats from the held
+// or comp bucket of a zone as complimentary tickets
+type IssueAllocationRequest struct {
+	Bucket string `json:"bucket" binding:"required"`
+	Seats  int    `json:"seats" binding:"required,gt=0"`
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// Validate validates the IssueAllocationRequest
+func (r *IssueAllocationRequest) Validate() (bool, string) {
+	if r.Bucket != domain.AllocationHeld && r.Bucket != domain.AllocationComp {
+		return false, "Bucket must be one of held, comp"
+	}
+	if r.Seats <= 0 {
+		return false, "Seats must be greater than 0"
+	}
+	return true, ""
+}
+
+// AllocationMoveResponse represents a recorded allocation move
+type AllocationMoveResponse struct {
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Seats     int    `json:"seats"`
+	Reason    string `json:"reason,omitempty"`
+	MovedBy   string `json:"moved_by,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ZoneAllocationResponse represents how a zone's seats are allocated
+type ZoneAllocationResponse struct {
+	ZoneID        string                    `json:"zone_id"`
+	TotalSeats    int                       `json:"total_seats"`
+	Public        int                       `json:"public"`
+	Held          int                       `json:"held"`
+	Comp          int                       `json:"comp"`
+	ReservedSeats int                       `json:"reserved_seats"`
+	SoldSeats     int                       `json:"sold_seats"`
+	Moves         []*AllocationMoveResponse `json:"moves,omitempty"`
+}
+
 // ShowZoneListResponse represents a list of show zones
 type ShowZoneListResponse struct {
 	Zones  []*ShowZoneResponse `json:"zones"`
@@ -8,6 +8,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/dto"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/service"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/response"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
@@ -265,6 +266,171 @@ func (h *ShowZoneHandler) ListActive(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Success(zoneResponses))
 }
 
+// GetAllocations handles GET /zones/:id/allocations - shows how a zone's seats
+// are allocated between public sale, held and comp, with recent moves
+func (h *ShowZoneHandler) GetAllocations(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.show_zone.GetAllocations")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("zone_id", id))
+
+	zone, moves, err := h.showZoneService.GetAllocations(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, service.ErrShowZoneNotFound) {
+			span.SetStatus(codes.Error, "Zone not found")
+			c.JSON(http.StatusNotFound, response.NotFound("Zone not found"))
+			return
+		}
+		span.SetStatus(codes.Error, "Failed to get allocations")
+		c.JSON(http.StatusInternalServerError, response.InternalError("Failed to get allocations"))
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, response.Success(toZoneAllocationResponse(zone, moves)))
+}
+
+// MoveAllocation handles POST /zones/:id/allocations/move - moves seats
+// between allocation buckets, e.g. holds seats back from public sale or
+// releases held seats to it
+func (h *ShowZoneHandler) MoveAllocation(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.show_zone.MoveAllocation")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("zone_id", id))
+
+	var req dto.MoveAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid request body")
+		c.JSON(http.StatusBadRequest, response.BadRequest("Invalid request body"))
+		return
+	}
+
+	// Validate request
+	if valid, msg := req.Validate(); !valid {
+		span.RecordError(errors.New(msg))
+		span.SetStatus(codes.Error, msg)
+		c.JSON(http.StatusBadRequest, response.BadRequest(msg))
+		return
+	}
+	span.SetAttributes(
+		attribute.String("from", req.From),
+		attribute.String("to", req.To),
+		attribute.Int("seats", req.Seats),
+	)
+
+	userID, _ := middleware.GetUserID(c)
+	zone, err := h.showZoneService.MoveAllocation(ctx, id, &req, userID)
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case errors.Is(err, service.ErrShowZoneNotFound):
+			span.SetStatus(codes.Error, "Zone not found")
+			c.JSON(http.StatusNotFound, response.NotFound("Zone not found"))
+		case errors.Is(err, service.ErrInsufficientAllocation):
+			span.SetStatus(codes.Error, "Not enough seats in bucket")
+			c.JSON(http.StatusConflict, response.InsufficientStock("Not enough seats in the "+req.From+" bucket"))
+		case errors.Is(err, service.ErrInvalidAllocation):
+			span.SetStatus(codes.Error, "Invalid allocation move")
+			c.JSON(http.StatusBadRequest, response.BadRequest(err.Error()))
+		default:
+			span.SetStatus(codes.Error, "Failed to move allocation")
+			c.JSON(http.StatusInternalServerError, response.InternalError("Failed to move allocation"))
+		}
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, response.Success(toZoneAllocationResponse(zone, nil)))
+}
+
+// IssueAllocation handles POST /zones/:id/allocations/issue - issues seats
+// from the held or comp bucket as complimentary tickets. Called by the
+// booking service, which creates the matching confirmed bookings.
+func (h *ShowZoneHandler) IssueAllocation(c *gin.Context) {
+	ctx, span := telemetry.StartSpan(c.Request.Context(), "handler.show_zone.IssueAllocation")
+	defer span.End()
+	c.Request = c.Request.WithContext(ctx)
+
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("zone_id", id))
+
+	var req dto.IssueAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid request body")
+		c.JSON(http.StatusBadRequest, response.BadRequest("Invalid request body"))
+		return
+	}
+
+	// Validate request
+	if valid, msg := req.Validate(); !valid {
+		span.RecordError(errors.New(msg))
+		span.SetStatus(codes.Error, msg)
+		c.JSON(http.StatusBadRequest, response.BadRequest(msg))
+		return
+	}
+	span.SetAttributes(
+		attribute.String("bucket", req.Bucket),
+		attribute.Int("seats", req.Seats),
+	)
+
+	userID, _ := middleware.GetUserID(c)
+	zone, err := h.showZoneService.IssueAllocation(ctx, id, &req, userID)
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case errors.Is(err, service.ErrShowZoneNotFound):
+			span.SetStatus(codes.Error, "Zone not found")
+			c.JSON(http.StatusNotFound, response.NotFound("Zone not found"))
+		case errors.Is(err, service.ErrInsufficientAllocation):
+			span.SetStatus(codes.Error, "Not enough seats in bucket")
+			c.JSON(http.StatusConflict, response.InsufficientStock("Not enough seats in the "+req.Bucket+" bucket"))
+		case errors.Is(err, service.ErrInvalidAllocation):
+			span.SetStatus(codes.Error, "Invalid allocation issue")
+			c.JSON(http.StatusBadRequest, response.BadRequest(err.Error()))
+		default:
+			span.SetStatus(codes.Error, "Failed to issue allocation")
+			c.JSON(http.StatusInternalServerError, response.InternalError("Failed to issue allocation"))
+		}
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+	c.JSON(http.StatusOK, response.Success(toZoneAllocationResponse(zone, nil)))
+}
+
+// toZoneAllocationResponse converts a zone and its moves to the allocation DTO
+func toZoneAllocationResponse(zone *domain.ShowZone, moves []*domain.AllocationMove) *dto.ZoneAllocationResponse {
+	resp := &dto.ZoneAllocationResponse{
+		ZoneID:        zone.ID,
+		TotalSeats:    zone.TotalSeats,
+		Public:        zone.AvailableSeats,
+		Held:          zone.HeldSeats,
+		Comp:          zone.CompSeats,
+		ReservedSeats: zone.ReservedSeats,
+		SoldSeats:     zone.SoldSeats,
+	}
+	for _, move := range moves {
+		resp.Moves = append(resp.Moves, &dto.AllocationMoveResponse{
+			ID:        move.ID,
+			From:      move.From,
+			To:        move.To,
+			Seats:     move.Seats,
+			Reason:    move.Reason,
+			MovedBy:   move.MovedBy,
+			CreatedAt: move.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	return resp
+}
+
 // toShowZoneResponse converts a domain show zone to response DTO
 func toShowZoneResponse(zone *domain.ShowZone) *dto.ShowZoneResponse {
 	resp := &dto.ShowZoneResponse{
@@ -279,6 +445,8 @@ func toShowZoneResponse(zone *domain.ShowZone) *dto.ShowZoneResponse {
 		AvailableSeats: zone.AvailableSeats,
 		ReservedSeats:  zone.ReservedSeats,
 		SoldSeats:      zone.SoldSeats,
+		HeldSeats:      zone.HeldSeats,
+		CompSeats:      zone.CompSeats,
 		MinPerOrder:    zone.MinPerOrder,
 		MaxPerOrder:    zone.MaxPerOrder,
 		IsActive:       zone.IsActive,
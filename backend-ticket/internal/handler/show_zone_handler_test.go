@@ -100,6 +100,49 @@ func (m *MockShowZoneService) ListActiveZones(ctx context.Context) ([]*domain.Sh
 	return zones, nil
 }
 
+func (m *MockShowZoneService) GetAllocations(ctx context.Context, zoneID string) (*domain.ShowZone, []*domain.AllocationMove, error) {
+	zone, ok := m.zones[zoneID]
+	if !ok {
+		return nil, nil, service.ErrShowZoneNotFound
+	}
+	return zone, nil, nil
+}
+
+func (m *MockShowZoneService) MoveAllocation(ctx context.Context, zoneID string, req *dto.MoveAllocationRequest, movedBy string) (*domain.ShowZone, error) {
+	zone, ok := m.zones[zoneID]
+	if !ok {
+		return nil, service.ErrShowZoneNotFound
+	}
+	if zone.AllocationSeats(req.From) < req.Seats {
+		return nil, service.ErrInsufficientAllocation
+	}
+	buckets := map[string]*int{
+		domain.AllocationPublic: &zone.AvailableSeats,
+		domain.AllocationHeld:   &zone.HeldSeats,
+		domain.AllocationComp:   &zone.CompSeats,
+	}
+	*buckets[req.From] -= req.Seats
+	*buckets[req.To] += req.Seats
+	return zone, nil
+}
+
+func (m *MockShowZoneService) IssueAllocation(ctx context.Context, zoneID string, req *dto.IssueAllocationRequest, issuedBy string) (*domain.ShowZone, error) {
+	zone, ok := m.zones[zoneID]
+	if !ok {
+		return nil, service.ErrShowZoneNotFound
+	}
+	if zone.AllocationSeats(req.Bucket) < req.Seats {
+		return nil, service.ErrInsufficientAllocation
+	}
+	if req.Bucket == domain.AllocationHeld {
+		zone.HeldSeats -= req.Seats
+	} else {
+		zone.CompSeats -= req.Seats
+	}
+	zone.SoldSeats += req.Seats
+	return zone, nil
+}
+
 func (m *MockShowZoneService) AddZone(zone *domain.ShowZone) {
 	m.zones[zone.ID] = zone
 }
@@ -158,6 +201,8 @@ func setupShowZoneRouter(h *ShowZoneHandler) *gin.Engine {
 		zones.GET("/:id", h.GetByID)
 		zones.PUT("/:id", h.Update)
 		zones.DELETE("/:id", h.Delete)
+		zones.GET("/:id/allocations", h.GetAllocations)
+		zones.POST("/:id/allocations/move", h.MoveAllocation)
 	}
 
 	return router
@@ -456,3 +501,90 @@ func TestShowZoneHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestShowZoneHandler_MoveAllocation(t *testing.T) {
+	mockZoneSvc := NewMockShowZoneService()
+	mockShowSvc := NewMockShowServiceForZone()
+	handler := NewShowZoneHandler(mockZoneSvc, mockShowSvc)
+	router := setupShowZoneRouter(handler)
+
+	// Add test zone
+	now := time.Now()
+	mockZoneSvc.AddZone(&domain.ShowZone{
+		ID:             "zone-1",
+		ShowID:         "show-1",
+		Name:           "VIP",
+		TotalSeats:     50,
+		AvailableSeats: 50,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+
+	tests := []struct {
+		name       string
+		id         string
+		body       map[string]interface{}
+		wantStatus int
+		wantHeld   int
+	}{
+		{
+			name:       "hold seats for press",
+			id:         "zone-1",
+			body:       map[string]interface{}{"from": "public", "to": "held", "seats": 10, "reason": "press"},
+			wantStatus: http.StatusOK,
+			wantHeld:   10,
+		},
+		{
+			name:       "release more than held",
+			id:         "zone-1",
+			body:       map[string]interface{}{"from": "held", "to": "public", "seats": 11},
+			wantStatus: http.StatusConflict,
+			wantHeld:   10,
+		},
+		{
+			name:       "unknown bucket",
+			id:         "zone-1",
+			body:       map[string]interface{}{"from": "public", "to": "vip", "seats": 1},
+			wantStatus: http.StatusBadRequest,
+			wantHeld:   10,
+		},
+		{
+			name:       "missing seats",
+			id:         "zone-1",
+			body:       map[string]interface{}{"from": "public", "to": "comp"},
+			wantStatus: http.StatusBadRequest,
+			wantHeld:   10,
+		},
+		{
+			name:       "non-existent zone",
+			id:         "non-existent",
+			body:       map[string]interface{}{"from": "public", "to": "held", "seats": 1},
+			wantStatus: http.StatusNotFound,
+			wantHeld:   10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/zones/"+tt.id+"/allocations/move", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, resp.Code, resp.Body.String())
+			}
+			if held := mockZoneSvc.zones["zone-1"].HeldSeats; held != tt.wantHeld {
+				t.Errorf("expected %d held seats, got %d", tt.wantHeld, held)
+			}
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/zones/zone-1/allocations", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	if !bytes.Contains(resp.Body.Bytes(), []byte(`"public":40`)) || !bytes.Contains(resp.Body.Bytes(), []byte(`"held":10`)) {
+		t.Errorf("allocations = %s, want public 40 and held 10", resp.Body.String())
+	}
+}
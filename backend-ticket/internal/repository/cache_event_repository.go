@@ -242,13 +242,21 @@ func (r *CachedEventRepository) invalidateListCaches(ctx context.Context) {
 	}
 
 	for _, pattern := range patterns {
-		iter := r.cache.Client().Scan(ctx, 0, pattern, 100).Iterator()
-		for iter.Next(ctx) {
-			r.cache.Del(ctx, iter.Val())
-		}
+		r.deleteMatching(ctx, pattern)
 	}
 }
 
+// deleteMatching deletes keys matching pattern on every node, one key at a
+// time so it also works when the keys live in different cluster slots
+func (r *CachedEventRepository) deleteMatching(ctx context.Context, pattern string) {
+	_ = r.cache.ScanKeys(ctx, pattern, 100, func(keys []string) error {
+		for _, key := range keys {
+			r.cache.Del(ctx, key)
+		}
+		return nil
+	})
+}
+
 // InvalidateAll invalidates all event caches (useful for admin operations)
 func (r *CachedEventRepository) InvalidateAll(ctx context.Context) error {
 	patterns := []string{
@@ -258,10 +266,7 @@ func (r *CachedEventRepository) InvalidateAll(ctx context.Context) error {
 	}
 
 	for _, pattern := range patterns {
-		iter := r.cache.Client().Scan(ctx, 0, pattern, 100).Iterator()
-		for iter.Next(ctx) {
-			r.cache.Del(ctx, iter.Val())
-		}
+		r.deleteMatching(ctx, pattern)
 	}
 
 	return nil
@@ -132,4 +132,9 @@ type ShowZoneRepository interface {
 	UpdateAvailableSeats(ctx context.Context, id string, availableSeats int) error
 	// ListActive retrieves all active zones (for inventory sync)
 	ListActive(ctx context.Context) ([]*domain.ShowZone, error)
+	// MoveAllocation moves seats between allocation buckets, or from one to
+	// issued, and records the move
+	MoveAllocation(ctx context.Context, move *domain.AllocationMove) (*domain.ShowZone, error)
+	// ListAllocationMoves retrieves the most recent allocation moves of a zone
+	ListAllocationMoves(ctx context.Context, zoneID string, limit int) ([]*domain.AllocationMove, error)
 }
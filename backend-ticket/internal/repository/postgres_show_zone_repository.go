@@ -15,11 +15,25 @@ import (
 const seatZoneColumns = `id, show_id, name, COALESCE(description, '') as description,
 	COALESCE(color, '') as color, price, COALESCE(currency, 'THB') as currency,
 	total_seats, available_seats, COALESCE(reserved_seats, 0) as reserved_seats,
-	COALESCE(sold_seats, 0) as sold_seats, COALESCE(min_per_order, 1) as min_per_order,
+	COALESCE(sold_seats, 0) as sold_seats, COALESCE(held_seats, 0) as held_seats,
+	COALESCE(comp_seats, 0) as comp_seats, COALESCE(min_per_order, 1) as min_per_order,
 	COALESCE(max_per_order, 10) as max_per_order, COALESCE(is_active, true) as is_active,
 	COALESCE(sort_order, 0) as sort_order, sale_start_at, sale_end_at,
 	created_at, updated_at, deleted_at`
 
+// ErrInsufficientAllocation is returned when an allocation bucket holds fewer
+// seats than a move takes from it
+var ErrInsufficientAllocation = errors.New("not enough seats in allocation bucket")
+
+// allocationColumns maps allocation buckets, and the issued target, to their
+// seat_zones columns
+var allocationColumns = map[string]string{
+	domain.AllocationPublic: "available_seats",
+	domain.AllocationHeld:   "held_seats",
+	domain.AllocationComp:   "comp_seats",
+	domain.AllocationIssued: "sold_seats",
+}
+
 // PostgresShowZoneRepository implements ShowZoneRepository using PostgreSQL
 type PostgresShowZoneRepository struct {
 	pool *pgxpool.Pool
@@ -45,6 +59,8 @@ func (r *PostgresShowZoneRepository) scanZone(row pgx.Row) (*domain.ShowZone, er
 		&zone.AvailableSeats,
 		&zone.ReservedSeats,
 		&zone.SoldSeats,
+		&zone.HeldSeats,
+		&zone.CompSeats,
 		&zone.MinPerOrder,
 		&zone.MaxPerOrder,
 		&zone.IsActive,
@@ -148,6 +164,8 @@ func (r *PostgresShowZoneRepository) GetByShowID(ctx context.Context, showID str
 			&zone.AvailableSeats,
 			&zone.ReservedSeats,
 			&zone.SoldSeats,
+			&zone.HeldSeats,
+			&zone.CompSeats,
 			&zone.MinPerOrder,
 			&zone.MaxPerOrder,
 			&zone.IsActive,
@@ -235,6 +253,87 @@ func (r *PostgresShowZoneRepository) UpdateAvailableSeats(ctx context.Context, i
 	return nil
 }
 
+// MoveAllocation moves seats between two allocation buckets of a zone and
+// records the move, in one transaction. It returns nil if the zone does not
+// exist and ErrInsufficientAllocation if the source bucket is short.
+func (r *PostgresShowZoneRepository) MoveAllocation(ctx context.Context, move *domain.AllocationMove) (*domain.ShowZone, error) {
+	from, okFrom := allocationColumns[move.From]
+	to, okTo := allocationColumns[move.To]
+	if !okFrom || !okTo || from == to || move.From == domain.AllocationIssued {
+		return nil, fmt.Errorf("invalid allocation move from %q to %q", move.From, move.To)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+		UPDATE seat_zones
+		SET %[1]s = %[1]s - $2, %[2]s = %[2]s + $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL AND %[1]s >= $2
+		RETURNING `+seatZoneColumns, from, to)
+	now := time.Now()
+	zone, err := r.scanZone(tx.QueryRow(ctx, query, move.ZoneID, move.Seats, now))
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		var exists bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM seat_zones WHERE id = $1 AND deleted_at IS NULL)`, move.ZoneID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, nil
+		}
+		return nil, ErrInsufficientAllocation
+	}
+
+	move.CreatedAt = now
+	_, err = tx.Exec(ctx, `
+		INSERT INTO zone_allocation_moves (id, zone_id, from_bucket, to_bucket, seats, reason, moved_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid, $8)
+	`, move.ID, move.ZoneID, move.From, move.To, move.Seats, move.Reason, move.MovedBy, move.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// ListAllocationMoves retrieves the most recent allocation moves of a zone
+func (r *PostgresShowZoneRepository) ListAllocationMoves(ctx context.Context, zoneID string, limit int) ([]*domain.AllocationMove, error) {
+	query := `
+		SELECT id, zone_id, from_bucket, to_bucket, seats, COALESCE(reason, ''),
+			COALESCE(moved_by::text, ''), created_at
+		FROM zone_allocation_moves
+		WHERE zone_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, zoneID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var moves []*domain.AllocationMove
+	for rows.Next() {
+		move := &domain.AllocationMove{}
+		if err := rows.Scan(&move.ID, &move.ZoneID, &move.From, &move.To, &move.Seats,
+			&move.Reason, &move.MovedBy, &move.CreatedAt); err != nil {
+			return nil, err
+		}
+		moves = append(moves, move)
+	}
+	return moves, rows.Err()
+}
+
 // ListActive retrieves all active zones for inventory sync
 func (r *PostgresShowZoneRepository) ListActive(ctx context.Context) ([]*domain.ShowZone, error) {
 	query := `SELECT ` + seatZoneColumns + ` FROM seat_zones
@@ -261,6 +360,8 @@ func (r *PostgresShowZoneRepository) ListActive(ctx context.Context) ([]*domain.
 			&zone.AvailableSeats,
 			&zone.ReservedSeats,
 			&zone.SoldSeats,
+			&zone.HeldSeats,
+			&zone.CompSeats,
 			&zone.MinPerOrder,
 			&zone.MaxPerOrder,
 			&zone.IsActive,
@@ -25,12 +25,15 @@ var (
 // eventService implements EventService
 type eventService struct {
 	eventRepo repository.EventRepository
+	schedules SchedulePublisher
 }
 
-// NewEventService creates a new EventService
-func NewEventService(eventRepo repository.EventRepository) EventService {
+// NewEventService creates a new EventService. schedules may be nil, in which
+// case event queues are not told about sale window changes.
+func NewEventService(eventRepo repository.EventRepository, schedules SchedulePublisher) EventService {
 	return &eventService{
 		eventRepo: eventRepo,
+		schedules: schedules,
 	}
 }
 
@@ -256,6 +259,7 @@ func (s *eventService) UpdateEvent(ctx context.Context, id string, req *dto.Upda
 		return nil, err
 	}
 
+	s.publishSchedule(ctx, event.ID)
 	return event, nil
 }
 
@@ -270,7 +274,12 @@ func (s *eventService) DeleteEvent(ctx context.Context, id string) error {
 		return ErrEventNotFound
 	}
 
-	return s.eventRepo.Delete(ctx, id)
+	if err := s.eventRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.publishSchedule(ctx, id)
+	return nil
 }
 
 // PublishEvent publishes an event
@@ -296,9 +305,19 @@ func (s *eventService) PublishEvent(ctx context.Context, id string) (*domain.Eve
 		return nil, err
 	}
 
+	s.publishSchedule(ctx, event.ID)
 	return event, nil
 }
 
+// publishSchedule tells the booking service an event's sale window may have
+// changed. Like zone syncing it is best effort: a failed publish is made up
+// for by the event's next change.
+func (s *eventService) publishSchedule(ctx context.Context, eventID string) {
+	if s.schedules != nil {
+		_ = s.schedules.PublishSchedule(ctx, eventID)
+	}
+}
+
 // generateSlug generates a URL-friendly slug from a string
 func generateSlug(s string) string {
 	// Convert to lowercase
@@ -119,7 +119,7 @@ func (m *MockEventRepository) SlugExists(ctx context.Context, slug string) (bool
 
 func TestEventService_CreateEvent(t *testing.T) {
 	eventRepo := NewMockEventRepository()
-	svc := NewEventService(eventRepo)
+	svc := NewEventService(eventRepo, nil)
 
 	ctx := context.Background()
 
@@ -177,7 +177,7 @@ func TestEventService_CreateEvent(t *testing.T) {
 
 func TestEventService_GetEventByID(t *testing.T) {
 	eventRepo := NewMockEventRepository()
-	svc := NewEventService(eventRepo)
+	svc := NewEventService(eventRepo, nil)
 
 	ctx := context.Background()
 
@@ -233,7 +233,7 @@ func TestEventService_GetEventByID(t *testing.T) {
 
 func TestEventService_GetEventBySlug(t *testing.T) {
 	eventRepo := NewMockEventRepository()
-	svc := NewEventService(eventRepo)
+	svc := NewEventService(eventRepo, nil)
 
 	ctx := context.Background()
 
@@ -289,7 +289,7 @@ func TestEventService_GetEventBySlug(t *testing.T) {
 
 func TestEventService_UpdateEvent(t *testing.T) {
 	eventRepo := NewMockEventRepository()
-	svc := NewEventService(eventRepo)
+	svc := NewEventService(eventRepo, nil)
 
 	ctx := context.Background()
 
@@ -361,7 +361,7 @@ func TestEventService_UpdateEvent(t *testing.T) {
 
 func TestEventService_DeleteEvent(t *testing.T) {
 	eventRepo := NewMockEventRepository()
-	svc := NewEventService(eventRepo)
+	svc := NewEventService(eventRepo, nil)
 
 	ctx := context.Background()
 
@@ -414,7 +414,7 @@ func TestEventService_DeleteEvent(t *testing.T) {
 
 func TestEventService_PublishEvent(t *testing.T) {
 	eventRepo := NewMockEventRepository()
-	svc := NewEventService(eventRepo)
+	svc := NewEventService(eventRepo, nil)
 
 	ctx := context.Background()
 
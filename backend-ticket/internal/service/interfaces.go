@@ -87,4 +87,10 @@ type ShowZoneService interface {
 	DeleteShowZone(ctx context.Context, id string) error
 	// ListActiveZones lists all active zones for inventory sync
 	ListActiveZones(ctx context.Context) ([]*domain.ShowZone, error)
+	// GetAllocations retrieves a zone with its most recent allocation moves
+	GetAllocations(ctx context.Context, zoneID string) (*domain.ShowZone, []*domain.AllocationMove, error)
+	// MoveAllocation moves seats between allocation buckets of a zone
+	MoveAllocation(ctx context.Context, zoneID string, req *dto.MoveAllocationRequest, movedBy string) (*domain.ShowZone, error)
+	// IssueAllocation issues seats from the held or comp bucket as sold complimentary tickets
+	IssueAllocation(ctx context.Context, zoneID string, req *dto.IssueAllocationRequest, issuedBy string) (*domain.ShowZone, error)
 }
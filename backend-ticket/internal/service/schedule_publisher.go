@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/repository"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+)
+
+// maxScheduledShows bounds the shows read to work out an event's sale window
+const maxScheduledShows = 1000
+
+// SchedulePublisher tells the booking service when an event's sale opens and
+// closes, so its queue can open and close with it
+type SchedulePublisher interface {
+	// PublishSchedule publishes an event's current sale window, or that the
+	// event is gone
+	PublishSchedule(ctx context.Context, eventID string) error
+}
+
+// kafkaSchedulePublisher implements SchedulePublisher using Kafka
+type kafkaSchedulePublisher struct {
+	eventRepo repository.EventRepository
+	showRepo  repository.ShowRepository
+	producer  kafka.MessageProducer
+}
+
+// NewKafkaSchedulePublisher creates a SchedulePublisher that produces to
+// domain.EventScheduleTopic, keyed by event so an event's windows stay in order
+func NewKafkaSchedulePublisher(eventRepo repository.EventRepository, showRepo repository.ShowRepository, producer kafka.MessageProducer) SchedulePublisher {
+	return &kafkaSchedulePublisher{
+		eventRepo: eventRepo,
+		showRepo:  showRepo,
+		producer:  producer,
+	}
+}
+
+// PublishSchedule reads the event and its shows and publishes their window
+func (p *kafkaSchedulePublisher) PublishSchedule(ctx context.Context, eventID string) error {
+	event, err := p.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to get event %s: %w", eventID, err)
+	}
+
+	schedule := domain.NewDeletedEventSchedule(eventID)
+	if event != nil {
+		shows, _, err := p.showRepo.GetByEventID(ctx, eventID, maxScheduledShows, 0)
+		if err != nil {
+			return fmt.Errorf("failed to get shows for event %s: %w", eventID, err)
+		}
+		schedule = domain.NewEventSchedule(event, shows)
+	}
+
+	headers := map[string]string{"source": "ticket-service"}
+	if err := p.producer.ProduceJSON(ctx, domain.EventScheduleTopic, eventID, schedule, headers); err != nil {
+		return fmt.Errorf("failed to publish schedule for event %s: %w", eventID, err)
+	}
+	return nil
+}
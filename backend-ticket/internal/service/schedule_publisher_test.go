@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka/kafkatest"
+)
+
+func TestSchedulePublisher_PublishesSaleWindow(t *testing.T) {
+	ctx := context.Background()
+	broker := kafkatest.NewBroker()
+	eventRepo := NewMockEventRepository()
+	showRepo := NewMockShowRepository()
+	schedules := NewKafkaSchedulePublisher(eventRepo, showRepo, broker.NewProducer(nil))
+
+	bookingStart := time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC)
+	bookingEnd := bookingStart.Add(48 * time.Hour)
+	showSaleStart := bookingStart.Add(-time.Hour)
+	eventRepo.Create(ctx, &domain.Event{
+		ID:             "event-1",
+		Slug:           "event-1",
+		Status:         domain.EventStatusDraft,
+		BookingStartAt: &bookingStart,
+		BookingEndAt:   &bookingEnd,
+	})
+	showRepo.Create(ctx, &domain.Show{ID: "show-1", EventID: "event-1", SaleStartAt: &showSaleStart})
+
+	svc := NewEventService(eventRepo, schedules)
+	if _, err := svc.PublishEvent(ctx, "event-1"); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+
+	records := broker.Records(domain.EventScheduleTopic)
+	if len(records) != 1 {
+		t.Fatalf("published %d schedules, want 1", len(records))
+	}
+	if string(records[0].Key) != "event-1" {
+		t.Errorf("key = %q, want event-1", records[0].Key)
+	}
+	var schedule domain.EventSchedule
+	if err := records[0].Decode(&schedule); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if schedule.Status != domain.EventStatusPublished {
+		t.Errorf("Status = %q, want %q", schedule.Status, domain.EventStatusPublished)
+	}
+	if schedule.SaleStartAt == nil || !schedule.SaleStartAt.Equal(showSaleStart) {
+		t.Errorf("SaleStartAt = %v, want the show's %v", schedule.SaleStartAt, showSaleStart)
+	}
+	if schedule.SaleEndAt == nil || !schedule.SaleEndAt.Equal(bookingEnd) {
+		t.Errorf("SaleEndAt = %v, want %v", schedule.SaleEndAt, bookingEnd)
+	}
+
+	// Moving a show's sale republishes the window
+	later := bookingStart.Add(time.Hour)
+	shows := NewShowService(showRepo, eventRepo, nil, schedules)
+	if _, err := shows.UpdateShow(ctx, "show-1", &dto.UpdateShowRequest{SaleStartAt: &later}); err != nil {
+		t.Fatalf("UpdateShow() error = %v", err)
+	}
+	records = broker.Records(domain.EventScheduleTopic)
+	if len(records) != 2 {
+		t.Fatalf("published %d schedules, want 2", len(records))
+	}
+	if err := records[1].Decode(&schedule); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !schedule.SaleStartAt.Equal(bookingStart) {
+		t.Errorf("SaleStartAt = %v, want the booking start %v", schedule.SaleStartAt, bookingStart)
+	}
+}
+
+func TestSchedulePublisher_PublishesDeletedEvent(t *testing.T) {
+	ctx := context.Background()
+	broker := kafkatest.NewBroker()
+	eventRepo := NewMockEventRepository()
+	schedules := NewKafkaSchedulePublisher(eventRepo, NewMockShowRepository(), broker.NewProducer(nil))
+
+	eventRepo.Create(ctx, &domain.Event{ID: "event-1", Slug: "event-1", Status: domain.EventStatusPublished})
+	svc := NewEventService(eventRepo, schedules)
+	if err := svc.DeleteEvent(ctx, "event-1"); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+
+	records := broker.Records(domain.EventScheduleTopic)
+	if len(records) != 1 {
+		t.Fatalf("published %d schedules, want 1", len(records))
+	}
+	var schedule domain.EventSchedule
+	if err := records[0].Decode(&schedule); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !schedule.Deleted || schedule.EventID != "event-1" {
+		t.Errorf("schedule = %+v, want event-1 deleted", schedule)
+	}
+}
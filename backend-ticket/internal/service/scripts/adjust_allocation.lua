@@ -0,0 +1,63 @@
+--[[
+    Adjust Allocation Lua Script
+    ============================
+    Version: 1
+
+    Applies a move between the allocation buckets of a zone. The public
+    bucket is the availability counter reservations draw from, so it is only
+    adjusted relatively and seats held by running reservations are never
+    overwritten. Held and comp seats are owned by Postgres and mirrored into
+    the allocation hash as they stand after the move.
+
+    With a sharded booking service, public seats parked in shards are not
+    counted; taking seats off sale only takes what is left in the pool.
+
+    Key Structure:
+    - KEYS[1]: zone:availability:{zone_id}  - Available (public) seats count (string/integer)
+    - KEYS[2]: zone:allocations:{zone_id}   - Held and comp seats (hash: held, comp)
+
+    Arguments:
+    - ARGV[1]: public_delta      - Seats put on (positive) or taken off (negative) sale
+    - ARGV[2]: held              - Held seats after the move
+    - ARGV[3]: comp              - Comp seats after the move
+
+    Returns:
+    - Success: {1, available_seats, held_seats, comp_seats}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - INVALID_QUANTITY: A value is not an integer, or held/comp is negative
+    - ZONE_NOT_FOUND: Zone availability is not in Redis
+    - INSUFFICIENT_SEATS: Fewer public seats are left than are taken off sale
+--]]
+
+local availability_key = KEYS[1]
+local allocations_key = KEYS[2]
+
+local delta = tonumber(ARGV[1])
+local held = tonumber(ARGV[2])
+local comp = tonumber(ARGV[3])
+
+local function is_integer(n)
+    return n ~= nil and n == math.floor(n)
+end
+if not is_integer(delta) or not is_integer(held) or not is_integer(comp) or held < 0 or comp < 0 then
+    return {0, "INVALID_QUANTITY", "Allocation values must be integers, held and comp non-negative"}
+end
+
+local available = redis.call("GET", availability_key)
+if not available then
+    return {0, "ZONE_NOT_FOUND", "Zone availability not found"}
+end
+available = tonumber(available) or 0
+
+if available + delta < 0 then
+    return {0, "INSUFFICIENT_SEATS", "Only " .. available .. " public seats left"}
+end
+
+if delta ~= 0 then
+    available = redis.call("INCRBY", availability_key, delta)
+end
+redis.call("HSET", allocations_key, "held", held, "comp", comp)
+
+return {1, available, held, comp}
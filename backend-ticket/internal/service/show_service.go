@@ -21,14 +21,16 @@ type showService struct {
 	showRepo   repository.ShowRepository
 	eventRepo  repository.EventRepository
 	zoneSyncer ZoneSyncer
+	schedules  SchedulePublisher
 }
 
-// NewShowService creates a new ShowService
-func NewShowService(showRepo repository.ShowRepository, eventRepo repository.EventRepository, zoneSyncer ZoneSyncer) ShowService {
+// NewShowService creates a new ShowService. zoneSyncer and schedules may be nil.
+func NewShowService(showRepo repository.ShowRepository, eventRepo repository.EventRepository, zoneSyncer ZoneSyncer, schedules SchedulePublisher) ShowService {
 	return &showService{
 		showRepo:   showRepo,
 		eventRepo:  eventRepo,
 		zoneSyncer: zoneSyncer,
+		schedules:  schedules,
 	}
 }
 
@@ -117,6 +119,7 @@ func (s *showService) CreateShow(ctx context.Context, req *dto.CreateShowRequest
 		return nil, err
 	}
 
+	s.publishSchedule(ctx, show.EventID)
 	return show, nil
 }
 
@@ -227,6 +230,7 @@ func (s *showService) UpdateShow(ctx context.Context, id string, req *dto.Update
 		}
 	}
 
+	s.publishSchedule(ctx, show.EventID)
 	return show, nil
 }
 
@@ -241,5 +245,18 @@ func (s *showService) DeleteShow(ctx context.Context, id string) error {
 		return ErrShowNotFound
 	}
 
-	return s.showRepo.Delete(ctx, id)
+	if err := s.showRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.publishSchedule(ctx, show.EventID)
+	return nil
+}
+
+// publishSchedule tells the booking service the sale window of a show's
+// event may have changed (best effort, as in eventService)
+func (s *showService) publishSchedule(ctx context.Context, eventID string) {
+	if s.schedules != nil {
+		_ = s.schedules.PublishSchedule(ctx, eventID)
+	}
 }
@@ -146,11 +146,15 @@ func (m *MockZoneSyncerForShow) RemoveZone(ctx context.Context, zoneID string) e
 	return nil
 }
 
+func (m *MockZoneSyncerForShow) AdjustAllocation(ctx context.Context, zone *domain.ShowZone, publicDelta int) error {
+	return nil
+}
+
 func TestShowService_CreateShow(t *testing.T) {
 	mockShowRepo := NewMockShowRepository()
 	mockEventRepo := NewMockEventRepoForShow()
 	mockZoneSyncer := &MockZoneSyncerForShow{}
-	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer)
+	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer, nil)
 
 	// Add test event
 	now := time.Now()
@@ -243,7 +247,7 @@ func TestShowService_GetShowByID(t *testing.T) {
 	mockShowRepo := NewMockShowRepository()
 	mockEventRepo := NewMockEventRepoForShow()
 	mockZoneSyncer := &MockZoneSyncerForShow{}
-	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer)
+	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer, nil)
 
 	// Add test show
 	now := time.Now()
@@ -299,7 +303,7 @@ func TestShowService_ListShowsByEvent(t *testing.T) {
 	mockShowRepo := NewMockShowRepository()
 	mockEventRepo := NewMockEventRepoForShow()
 	mockZoneSyncer := &MockZoneSyncerForShow{}
-	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer)
+	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer, nil)
 
 	// Add test event
 	now := time.Now()
@@ -382,7 +386,7 @@ func TestShowService_UpdateShow(t *testing.T) {
 	mockShowRepo := NewMockShowRepository()
 	mockEventRepo := NewMockEventRepoForShow()
 	mockZoneSyncer := &MockZoneSyncerForShow{}
-	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer)
+	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer, nil)
 
 	// Add test show
 	now := time.Now()
@@ -445,7 +449,7 @@ func TestShowService_DeleteShow(t *testing.T) {
 	mockShowRepo := NewMockShowRepository()
 	mockEventRepo := NewMockEventRepoForShow()
 	mockZoneSyncer := &MockZoneSyncerForShow{}
-	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer)
+	svc := NewShowService(mockShowRepo, mockEventRepo, mockZoneSyncer, nil)
 
 	// Add test show
 	now := time.Now()
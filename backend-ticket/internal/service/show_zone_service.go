@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,9 +14,14 @@ import (
 
 // ShowZoneService errors
 var (
-	ErrShowZoneNotFound = errors.New("show zone not found")
+	ErrShowZoneNotFound       = errors.New("show zone not found")
+	ErrInvalidAllocation      = errors.New("invalid allocation move")
+	ErrInsufficientAllocation = repository.ErrInsufficientAllocation
 )
 
+// allocationMovesLimit is how many recent moves GetAllocations returns
+const allocationMovesLimit = 50
+
 // showZoneService implements the ShowZoneService interface
 type showZoneService struct {
 	showZoneRepo repository.ShowZoneRepository
@@ -182,3 +188,166 @@ func (s *showZoneService) DeleteShowZone(ctx context.Context, id string) error {
 func (s *showZoneService) ListActiveZones(ctx context.Context) ([]*domain.ShowZone, error) {
 	return s.showZoneRepo.ListActive(ctx)
 }
+
+// GetAllocations retrieves a zone with its most recent allocation moves
+func (s *showZoneService) GetAllocations(ctx context.Context, zoneID string) (*domain.ShowZone, []*domain.AllocationMove, error) {
+	zone, err := s.GetShowZoneByID(ctx, zoneID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	moves, err := s.showZoneRepo.ListAllocationMoves(ctx, zoneID, allocationMovesLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return zone, moves, nil
+}
+
+// MoveAllocation moves seats between allocation buckets of a zone. While the
+// zone is on sale the move is applied to Redis as well; seats leave a bucket
+// where it is guarded (public seats in Redis, where reservations draw from
+// them, held and comp seats in Postgres), and the other side is reverted if
+// the second write fails.
+func (s *showZoneService) MoveAllocation(ctx context.Context, zoneID string, req *dto.MoveAllocationRequest, movedBy string) (*domain.ShowZone, error) {
+	if valid, msg := req.Validate(); !valid {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAllocation, msg)
+	}
+
+	zone, err := s.GetShowZoneByID(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if zone.AllocationSeats(req.From) < req.Seats {
+		return nil, ErrInsufficientAllocation
+	}
+
+	move := &domain.AllocationMove{
+		ID:      uuid.New().String(),
+		ZoneID:  zoneID,
+		From:    req.From,
+		To:      req.To,
+		Seats:   req.Seats,
+		Reason:  req.Reason,
+		MovedBy: movedBy,
+	}
+	publicDelta := allocationDelta(req, domain.AllocationPublic)
+	live := s.isOnSale(ctx, zone)
+
+	if live && publicDelta < 0 {
+		after := *zone
+		after.AvailableSeats += publicDelta
+		after.HeldSeats += allocationDelta(req, domain.AllocationHeld)
+		after.CompSeats += allocationDelta(req, domain.AllocationComp)
+		if err := s.adjustRedis(ctx, &after, publicDelta); err != nil {
+			return nil, err
+		}
+
+		updated, err := s.showZoneRepo.MoveAllocation(ctx, move)
+		if err == nil && updated == nil {
+			err = ErrShowZoneNotFound
+		}
+		if err != nil {
+			// Put the seats back on sale
+			if rerr := s.adjustRedis(ctx, zone, -publicDelta); rerr != nil {
+				return nil, fmt.Errorf("%w (reverting redis failed: %v)", err, rerr)
+			}
+			return nil, err
+		}
+		return updated, nil
+	}
+
+	updated, err := s.showZoneRepo.MoveAllocation(ctx, move)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrShowZoneNotFound
+	}
+
+	if live {
+		if err := s.adjustRedis(ctx, updated, publicDelta); err != nil {
+			revert := &domain.AllocationMove{
+				ID:     uuid.New().String(),
+				ZoneID: zoneID,
+				From:   req.To,
+				To:     req.From,
+				Seats:  req.Seats,
+				Reason: "revert: redis sync failed",
+			}
+			if _, rerr := s.showZoneRepo.MoveAllocation(ctx, revert); rerr != nil {
+				return nil, fmt.Errorf("%w (reverting move failed: %v)", err, rerr)
+			}
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// IssueAllocation issues seats from the held or comp bucket as complimentary
+// tickets, moving them to sold. Public seats are untouched, so Redis only
+// needs its mirror of the buckets refreshed, which is best-effort.
+func (s *showZoneService) IssueAllocation(ctx context.Context, zoneID string, req *dto.IssueAllocationRequest, issuedBy string) (*domain.ShowZone, error) {
+	if valid, msg := req.Validate(); !valid {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAllocation, msg)
+	}
+
+	zone, err := s.GetShowZoneByID(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if zone.AllocationSeats(req.Bucket) < req.Seats {
+		return nil, ErrInsufficientAllocation
+	}
+
+	updated, err := s.showZoneRepo.MoveAllocation(ctx, &domain.AllocationMove{
+		ID:      uuid.New().String(),
+		ZoneID:  zoneID,
+		From:    req.Bucket,
+		To:      domain.AllocationIssued,
+		Seats:   req.Seats,
+		Reason:  req.Reason,
+		MovedBy: issuedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrShowZoneNotFound
+	}
+
+	if s.isOnSale(ctx, updated) {
+		_ = s.adjustRedis(ctx, updated, 0)
+	}
+	return updated, nil
+}
+
+// isOnSale reports whether a zone's inventory is live in Redis
+func (s *showZoneService) isOnSale(ctx context.Context, zone *domain.ShowZone) bool {
+	if s.zoneSyncer == nil || !zone.IsActive {
+		return false
+	}
+	show, err := s.showRepo.GetByID(ctx, zone.ShowID)
+	return err == nil && show != nil && show.Status == domain.ShowStatusOnSale
+}
+
+// adjustRedis applies an allocation move to Redis. A zone that is not in
+// Redis yet is skipped: the booking service loads it from Postgres, which
+// already reflects the move, on its first reservation.
+func (s *showZoneService) adjustRedis(ctx context.Context, zone *domain.ShowZone, publicDelta int) error {
+	if err := s.zoneSyncer.AdjustAllocation(ctx, zone, publicDelta); err != nil && !errors.Is(err, ErrZoneNotSynced) {
+		return err
+	}
+	return nil
+}
+
+// allocationDelta returns how many seats a move adds to (or takes from) bucket
+func allocationDelta(req *dto.MoveAllocationRequest, bucket string) int {
+	switch bucket {
+	case req.To:
+		return req.Seats
+	case req.From:
+		return -req.Seats
+	}
+	return 0
+}
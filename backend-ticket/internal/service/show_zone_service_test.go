@@ -2,16 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/dto"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/repository"
 )
 
 // MockShowZoneRepository is a mock implementation of ShowZoneRepository
 type MockShowZoneRepository struct {
-	zones map[string]*domain.ShowZone
+	zones     map[string]*domain.ShowZone
+	moves     []*domain.AllocationMove
+	failMoves bool
 }
 
 func NewMockShowZoneRepository() *MockShowZoneRepository {
@@ -84,6 +88,40 @@ func (m *MockShowZoneRepository) ListActive(ctx context.Context) ([]*domain.Show
 	return zones, nil
 }
 
+func (m *MockShowZoneRepository) MoveAllocation(ctx context.Context, move *domain.AllocationMove) (*domain.ShowZone, error) {
+	if m.failMoves {
+		return nil, errors.New("database unavailable")
+	}
+	zone, ok := m.zones[move.ZoneID]
+	if !ok {
+		return nil, nil
+	}
+	buckets := map[string]*int{
+		domain.AllocationPublic: &zone.AvailableSeats,
+		domain.AllocationHeld:   &zone.HeldSeats,
+		domain.AllocationComp:   &zone.CompSeats,
+		domain.AllocationIssued: &zone.SoldSeats,
+	}
+	if *buckets[move.From] < move.Seats {
+		return nil, repository.ErrInsufficientAllocation
+	}
+	*buckets[move.From] -= move.Seats
+	*buckets[move.To] += move.Seats
+	m.moves = append(m.moves, move)
+	updated := *zone
+	return &updated, nil
+}
+
+func (m *MockShowZoneRepository) ListAllocationMoves(ctx context.Context, zoneID string, limit int) ([]*domain.AllocationMove, error) {
+	var moves []*domain.AllocationMove
+	for _, move := range m.moves {
+		if move.ZoneID == zoneID {
+			moves = append(moves, move)
+		}
+	}
+	return moves, nil
+}
+
 func (m *MockShowZoneRepository) AddZone(zone *domain.ShowZone) {
 	m.zones[zone.ID] = zone
 }
@@ -517,3 +555,269 @@ func TestShowZoneService_DeleteShowZone(t *testing.T) {
 		})
 	}
 }
+
+// MockAllocationSyncer records allocation adjustments applied to Redis
+type MockAllocationSyncer struct {
+	MockZoneSyncerForShow
+	available int // Public seats left in Redis
+	deltas    []int
+	err       error
+}
+
+func (m *MockAllocationSyncer) AdjustAllocation(ctx context.Context, zone *domain.ShowZone, publicDelta int) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.available+publicDelta < 0 {
+		return ErrInsufficientAllocation
+	}
+	m.available += publicDelta
+	m.deltas = append(m.deltas, publicDelta)
+	return nil
+}
+
+func TestShowZoneService_MoveAllocation(t *testing.T) {
+	newZone := func() *domain.ShowZone {
+		return &domain.ShowZone{
+			ID:             "zone-1",
+			ShowID:         "show-1",
+			TotalSeats:     100,
+			AvailableSeats: 80,
+			ReservedSeats:  10,
+			SoldSeats:      10,
+			IsActive:       true,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		showStatus  string
+		redisSeats  int
+		redisErr    error
+		failDB      bool
+		req         dto.MoveAllocationRequest
+		wantErr     error
+		wantPublic  int
+		wantHeld    int
+		wantComp    int
+		wantDeltas  []int
+		wantRedisAt int
+	}{
+		{
+			name:        "hold public seats while on sale",
+			showStatus:  domain.ShowStatusOnSale,
+			redisSeats:  70, // 10 seats in reservations Postgres has not seen yet
+			req:         dto.MoveAllocationRequest{From: "public", To: "held", Seats: 20},
+			wantPublic:  60,
+			wantHeld:    20,
+			wantDeltas:  []int{-20},
+			wantRedisAt: 50,
+		},
+		{
+			name:        "public seats taken by reservations cannot be held",
+			showStatus:  domain.ShowStatusOnSale,
+			redisSeats:  5,
+			req:         dto.MoveAllocationRequest{From: "public", To: "held", Seats: 20},
+			wantErr:     ErrInsufficientAllocation,
+			wantPublic:  80,
+			wantRedisAt: 5,
+		},
+		{
+			name:        "failed database write puts seats back on sale",
+			showStatus:  domain.ShowStatusOnSale,
+			redisSeats:  70,
+			failDB:      true,
+			req:         dto.MoveAllocationRequest{From: "public", To: "comp", Seats: 20},
+			wantErr:     errors.New("database unavailable"),
+			wantPublic:  80,
+			wantDeltas:  []int{-20, 20},
+			wantRedisAt: 70,
+		},
+		{
+			name:        "not on sale skips redis",
+			showStatus:  domain.ShowStatusScheduled,
+			redisSeats:  0,
+			req:         dto.MoveAllocationRequest{From: "public", To: "comp", Seats: 30},
+			wantPublic:  50,
+			wantComp:    30,
+			wantRedisAt: 0,
+		},
+		{
+			name:        "more seats than the bucket holds",
+			showStatus:  domain.ShowStatusOnSale,
+			redisSeats:  80,
+			req:         dto.MoveAllocationRequest{From: "held", To: "public", Seats: 1},
+			wantErr:     ErrInsufficientAllocation,
+			wantPublic:  80,
+			wantRedisAt: 80,
+		},
+		{
+			name:       "same bucket",
+			showStatus: domain.ShowStatusOnSale,
+			req:        dto.MoveAllocationRequest{From: "held", To: "held", Seats: 1},
+			wantErr:    ErrInvalidAllocation,
+			wantPublic: 80,
+		},
+		{
+			name:       "zone not in redis yet",
+			showStatus: domain.ShowStatusOnSale,
+			redisErr:   ErrZoneNotSynced,
+			req:        dto.MoveAllocationRequest{From: "public", To: "held", Seats: 10},
+			wantPublic: 70,
+			wantHeld:   10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zoneRepo := NewMockShowZoneRepository()
+			zoneRepo.AddZone(newZone())
+			zoneRepo.failMoves = tt.failDB
+			showRepo := NewMockShowRepoForZone()
+			showRepo.AddShow(&domain.Show{ID: "show-1", Status: tt.showStatus})
+			syncer := &MockAllocationSyncer{available: tt.redisSeats, err: tt.redisErr}
+			svc := NewShowZoneService(zoneRepo, showRepo, syncer)
+
+			_, err := svc.MoveAllocation(context.Background(), "zone-1", &tt.req, "organizer-1")
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("MoveAllocation() error = %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Fatalf("MoveAllocation() error = nil, want %v", tt.wantErr)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error():
+				t.Fatalf("MoveAllocation() error = %v, want %v", err, tt.wantErr)
+			}
+
+			zone := zoneRepo.zones["zone-1"]
+			if zone.AvailableSeats != tt.wantPublic || zone.HeldSeats != tt.wantHeld || zone.CompSeats != tt.wantComp {
+				t.Errorf("buckets = public %d, held %d, comp %d, want %d, %d, %d",
+					zone.AvailableSeats, zone.HeldSeats, zone.CompSeats, tt.wantPublic, tt.wantHeld, tt.wantComp)
+			}
+			if len(syncer.deltas) != len(tt.wantDeltas) {
+				t.Fatalf("redis deltas = %v, want %v", syncer.deltas, tt.wantDeltas)
+			}
+			for i := range tt.wantDeltas {
+				if syncer.deltas[i] != tt.wantDeltas[i] {
+					t.Errorf("redis deltas = %v, want %v", syncer.deltas, tt.wantDeltas)
+				}
+			}
+			if tt.redisErr == nil && syncer.available != tt.wantRedisAt {
+				t.Errorf("redis available = %d, want %d", syncer.available, tt.wantRedisAt)
+			}
+		})
+	}
+}
+
+func TestShowZoneService_MoveAllocation_RevertsWhenRedisFails(t *testing.T) {
+	zoneRepo := NewMockShowZoneRepository()
+	zoneRepo.AddZone(&domain.ShowZone{
+		ID:             "zone-1",
+		ShowID:         "show-1",
+		TotalSeats:     100,
+		AvailableSeats: 60,
+		HeldSeats:      40,
+		IsActive:       true,
+	})
+	showRepo := NewMockShowRepoForZone()
+	showRepo.AddShow(&domain.Show{ID: "show-1", Status: domain.ShowStatusOnSale})
+	redisErr := errors.New("redis unavailable")
+	svc := NewShowZoneService(zoneRepo, showRepo, &MockAllocationSyncer{err: redisErr})
+
+	req := &dto.MoveAllocationRequest{From: "held", To: "public", Seats: 15}
+	if _, err := svc.MoveAllocation(context.Background(), "zone-1", req, ""); !errors.Is(err, redisErr) {
+		t.Fatalf("MoveAllocation() error = %v, want %v", err, redisErr)
+	}
+
+	zone := zoneRepo.zones["zone-1"]
+	if zone.AvailableSeats != 60 || zone.HeldSeats != 40 {
+		t.Errorf("buckets = public %d, held %d, want 60, 40", zone.AvailableSeats, zone.HeldSeats)
+	}
+	_, moves, err := svc.GetAllocations(context.Background(), "zone-1")
+	if err != nil {
+		t.Fatalf("GetAllocations() error = %v", err)
+	}
+	if len(moves) != 2 || moves[1].From != "public" || moves[1].To != "held" {
+		t.Errorf("moves = %+v, want the move and its revert", moves)
+	}
+}
+
+func TestShowZoneService_IssueAllocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        dto.IssueAllocationRequest
+		wantErr    error
+		wantComp   int
+		wantHeld   int
+		wantSold   int
+		wantPublic int
+	}{
+		{
+			name:       "issue comp seats",
+			req:        dto.IssueAllocationRequest{Bucket: "comp", Seats: 4, Reason: "sponsor"},
+			wantComp:   6,
+			wantHeld:   5,
+			wantSold:   14,
+			wantPublic: 70,
+		},
+		{
+			name:       "issue held seats",
+			req:        dto.IssueAllocationRequest{Bucket: "held", Seats: 5},
+			wantComp:   10,
+			wantSold:   15,
+			wantPublic: 70,
+		},
+		{
+			name:       "more seats than the bucket holds",
+			req:        dto.IssueAllocationRequest{Bucket: "comp", Seats: 11},
+			wantErr:    ErrInsufficientAllocation,
+			wantComp:   10,
+			wantHeld:   5,
+			wantSold:   10,
+			wantPublic: 70,
+		},
+		{
+			name:       "public seats cannot be issued",
+			req:        dto.IssueAllocationRequest{Bucket: "public", Seats: 1},
+			wantErr:    ErrInvalidAllocation,
+			wantComp:   10,
+			wantHeld:   5,
+			wantSold:   10,
+			wantPublic: 70,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zoneRepo := NewMockShowZoneRepository()
+			zoneRepo.AddZone(&domain.ShowZone{
+				ID:             "zone-1",
+				ShowID:         "show-1",
+				TotalSeats:     100,
+				AvailableSeats: 70,
+				SoldSeats:      10,
+				HeldSeats:      5,
+				CompSeats:      10,
+				IsActive:       true,
+			})
+			showRepo := NewMockShowRepoForZone()
+			showRepo.AddShow(&domain.Show{ID: "show-1", Status: domain.ShowStatusOnSale})
+			syncer := &MockAllocationSyncer{available: 70}
+			svc := NewShowZoneService(zoneRepo, showRepo, syncer)
+
+			_, err := svc.IssueAllocation(context.Background(), "zone-1", &tt.req, "organizer-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueAllocation() error = %v, want %v", err, tt.wantErr)
+			}
+
+			zone := zoneRepo.zones["zone-1"]
+			if zone.CompSeats != tt.wantComp || zone.HeldSeats != tt.wantHeld || zone.SoldSeats != tt.wantSold || zone.AvailableSeats != tt.wantPublic {
+				t.Errorf("zone = comp %d, held %d, sold %d, public %d, want %d, %d, %d, %d",
+					zone.CompSeats, zone.HeldSeats, zone.SoldSeats, zone.AvailableSeats,
+					tt.wantComp, tt.wantHeld, tt.wantSold, tt.wantPublic)
+			}
+			if syncer.available != 70 {
+				t.Errorf("redis available = %d, want public seats untouched", syncer.available)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	_ "embed"
+	"errors"
 	"fmt"
 
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
@@ -9,6 +11,23 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
 )
 
+//go:embed scripts/adjust_allocation.lua
+var adjustAllocationScript string
+
+// adjustAllocationSpec pins the allocation script; editing it means updating
+// the SHA, and changing its KEYS or ARGV layout means bumping the version
+var adjustAllocationSpec = redis.ScriptSpec{
+	Name:    "adjust_allocation",
+	Version: 1,
+	Source:  adjustAllocationScript,
+	Keys:    2,
+	Args:    []string{"public_delta", "held", "comp"},
+	SHA:     "eadbd877704c894c4281511377631269ac143b37",
+}
+
+// ErrZoneNotSynced is returned when a zone's availability is not in Redis
+var ErrZoneNotSynced = errors.New("zone availability not in redis")
+
 // ZoneSyncer handles syncing zone inventory to Redis
 type ZoneSyncer interface {
 	// SyncByShowID syncs all zones for a show to Redis (when show goes on_sale)
@@ -19,6 +38,10 @@ type ZoneSyncer interface {
 	SyncZone(ctx context.Context, zone *domain.ShowZone) error
 	// RemoveZone removes a single zone from Redis
 	RemoveZone(ctx context.Context, zoneID string) error
+	// AdjustAllocation applies an allocation move to Redis: publicDelta seats
+	// are added to (or taken from) zone availability, and the zone's held and
+	// comp seats are mirrored as they stand after the move
+	AdjustAllocation(ctx context.Context, zone *domain.ShowZone, publicDelta int) error
 }
 
 // zoneSyncer implements ZoneSyncer
@@ -26,14 +49,22 @@ type zoneSyncer struct {
 	showZoneRepo repository.ShowZoneRepository
 	showRepo     repository.ShowRepository
 	redis        *redis.Client
+	// zoneShards is the booking service's ZONE_INVENTORY_SHARDS; its shard
+	// counters are cleared whenever a zone's availability is written
+	zoneShards int
 }
 
-// NewZoneSyncer creates a new ZoneSyncer
-func NewZoneSyncer(showZoneRepo repository.ShowZoneRepository, showRepo repository.ShowRepository, redisClient *redis.Client) ZoneSyncer {
+// NewZoneSyncer creates a new ZoneSyncer. zoneShards must match the number of
+// shard counters the booking service splits each zone into (0 or 1 = none).
+func NewZoneSyncer(showZoneRepo repository.ShowZoneRepository, showRepo repository.ShowRepository, redisClient *redis.Client, zoneShards int) ZoneSyncer {
+	if redisClient != nil {
+		redisClient.Scripts().MustRegister(adjustAllocationSpec)
+	}
 	return &zoneSyncer{
 		showZoneRepo: showZoneRepo,
 		showRepo:     showRepo,
 		redis:        redisClient,
+		zoneShards:   zoneShards,
 	}
 }
 
@@ -88,8 +119,22 @@ func (s *zoneSyncer) SyncZone(ctx context.Context, zone *domain.ShowZone) error
 		return nil
 	}
 
-	key := fmt.Sprintf("zone:availability:%s", zone.ID)
-	return s.redis.Set(ctx, key, zone.AvailableSeats, 0).Err()
+	// The whole count goes to the pool, so seats left in the booking
+	// service's shard counters (and shard transfers still journaled) must go
+	// in the same write or they would be sold twice. Shards live in other
+	// slots on a cluster, so the write is atomic on a single node only, as
+	// the booking service's own reset is.
+	pipe := s.redis.TxPipeline()
+	if s.redis.IsCluster() && s.zoneShards > 1 {
+		pipe = s.redis.Pipeline()
+	}
+	pipe.Set(ctx, s.availabilityKey(zone.ID), zone.AvailableSeats, 0)
+	pipe.HSet(ctx, s.allocationsKey(zone.ID), domain.AllocationHeld, zone.HeldSeats, domain.AllocationComp, zone.CompSeats)
+	for _, key := range s.shardKeys(zone.ID) {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 // RemoveZone removes a single zone from Redis
@@ -98,6 +143,65 @@ func (s *zoneSyncer) RemoveZone(ctx context.Context, zoneID string) error {
 		return nil
 	}
 
-	key := fmt.Sprintf("zone:availability:%s", zoneID)
-	return s.redis.Del(ctx, key).Err()
+	if err := s.redis.Del(ctx, s.availabilityKey(zoneID), s.allocationsKey(zoneID)).Err(); err != nil {
+		return err
+	}
+	for _, key := range s.shardKeys(zoneID) {
+		if err := s.redis.Del(ctx, key).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdjustAllocation applies an allocation move to Redis
+func (s *zoneSyncer) AdjustAllocation(ctx context.Context, zone *domain.ShowZone, publicDelta int) error {
+	if s.redis == nil {
+		return nil
+	}
+
+	keys := []string{s.availabilityKey(zone.ID), s.allocationsKey(zone.ID)}
+	result, err := s.redis.Scripts().Run(ctx, adjustAllocationSpec.Name, keys, publicDelta, zone.HeldSeats, zone.CompSeats).Slice()
+	if err != nil {
+		return fmt.Errorf("failed to adjust allocation of zone %s: %w", zone.ID, err)
+	}
+	if status, _ := result[0].(int64); status == 1 {
+		return nil
+	}
+
+	code, _ := result[1].(string)
+	switch code {
+	case "ZONE_NOT_FOUND":
+		return ErrZoneNotSynced
+	case "INSUFFICIENT_SEATS":
+		return ErrInsufficientAllocation
+	}
+	return fmt.Errorf("failed to adjust allocation of zone %s: %s: %v", zone.ID, code, result[2])
+}
+
+// availabilityKey returns the zone's public availability counter key; the
+// booking service reserves seats from it
+func (s *zoneSyncer) availabilityKey(zoneID string) string {
+	return fmt.Sprintf("zone:availability:%s", s.redis.ClusterTag(zoneID))
+}
+
+// allocationsKey returns the zone's held/comp hash key, in the same slot as
+// its availability counter
+func (s *zoneSyncer) allocationsKey(zoneID string) string {
+	return fmt.Sprintf("zone:allocations:%s", s.redis.ClusterTag(zoneID))
+}
+
+// shardKeys returns the booking service's shard counters of a zone and the
+// shard transfer journals of the pool and every shard (see the booking
+// service's reservationKeys)
+func (s *zoneSyncer) shardKeys(zoneID string) []string {
+	if s.zoneShards <= 1 {
+		return nil
+	}
+	keys := []string{fmt.Sprintf("zone:transfers:%s", s.redis.ClusterTag(zoneID))}
+	for i := 0; i < s.zoneShards; i++ {
+		shard := s.redis.ClusterTag(fmt.Sprintf("%s:shard:%d", zoneID, i))
+		keys = append(keys, "zone:availability:"+shard, "zone:transfers:"+shard)
+	}
+	return keys
 }
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/domain"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+func TestAdjustAllocationScript(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	keys := []string{"zone:availability:zone-1", "zone:allocations:zone-1"}
+	seed := func(available string) func(testing.TB, *miniredis.Miniredis) {
+		return func(tb testing.TB, mr *miniredis.Miniredis) {
+			mr.Set("zone:availability:zone-1", available)
+			mr.HSet("zone:allocations:zone-1", "held", "5", "comp", "0")
+		}
+	}
+
+	redistest.RunScriptCases(t, client, mr, adjustAllocationSpec, []redistest.ScriptCase{
+		{
+			Name:  "take seats off sale",
+			Setup: seed("40"),
+			Keys:  keys,
+			Args:  []interface{}{-10, 15, 0},
+			Want:  []interface{}{int64(1), int64(30), int64(15), int64(0)},
+		},
+		{
+			Name:  "put seats on sale",
+			Setup: seed("40"),
+			Keys:  keys,
+			Args:  []interface{}{5, 0, 0},
+			Want:  []interface{}{int64(1), int64(45), int64(0), int64(0)},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if held := mr.HGet("zone:allocations:zone-1", "held"); held != "0" {
+					tb.Errorf("held = %q, want 0", held)
+				}
+			},
+		},
+		{
+			Name:  "move between held and comp",
+			Setup: seed("40"),
+			Keys:  keys,
+			Args:  []interface{}{0, 3, 2},
+			Want:  []interface{}{int64(1), int64(40), int64(3), int64(2)},
+		},
+		{
+			Name:     "seats already reserved",
+			Setup:    seed("8"),
+			Keys:     keys,
+			Args:     []interface{}{-10, 15, 0},
+			WantCode: "INSUFFICIENT_SEATS",
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if got, _ := mr.Get("zone:availability:zone-1"); got != "8" {
+					tb.Errorf("available = %q, want 8", got)
+				}
+			},
+		},
+		{
+			Name:     "zone not synced",
+			Keys:     keys,
+			Args:     []interface{}{-1, 1, 0},
+			WantCode: "ZONE_NOT_FOUND",
+		},
+		{
+			Name:     "negative held",
+			Setup:    seed("40"),
+			Keys:     keys,
+			Args:     []interface{}{0, -1, 0},
+			WantCode: "INVALID_QUANTITY",
+		},
+		{
+			Name:    "missing argument",
+			Keys:    keys,
+			Args:    []interface{}{0, 1},
+			WantErr: true,
+		},
+	})
+}
+
+func TestZoneSyncer_AdjustAllocation(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	syncer := NewZoneSyncer(nil, nil, client, 0)
+	ctx := context.Background()
+
+	zone := &domain.ShowZone{ID: "zone-1", AvailableSeats: 50, HeldSeats: 0}
+	if err := syncer.AdjustAllocation(ctx, zone, -10); err != ErrZoneNotSynced {
+		t.Fatalf("AdjustAllocation() before sync error = %v, want %v", err, ErrZoneNotSynced)
+	}
+
+	if err := syncer.SyncZone(ctx, zone); err != nil {
+		t.Fatalf("SyncZone() error = %v", err)
+	}
+	// A reservation takes seats while the organizer holds some back
+	mr.Set("zone:availability:zone-1", "45")
+
+	zone.HeldSeats = 10
+	if err := syncer.AdjustAllocation(ctx, zone, -10); err != nil {
+		t.Fatalf("AdjustAllocation() error = %v", err)
+	}
+	if got, _ := mr.Get("zone:availability:zone-1"); got != "35" {
+		t.Errorf("available = %s, want 35 (reservation kept)", got)
+	}
+	if held := mr.HGet("zone:allocations:zone-1", "held"); held != "10" {
+		t.Errorf("held = %s, want 10", held)
+	}
+
+	zone.HeldSeats = 50
+	if err := syncer.AdjustAllocation(ctx, zone, -40); err != ErrInsufficientAllocation {
+		t.Errorf("AdjustAllocation() past availability error = %v, want %v", err, ErrInsufficientAllocation)
+	}
+
+	if err := syncer.RemoveZone(ctx, "zone-1"); err != nil {
+		t.Fatalf("RemoveZone() error = %v", err)
+	}
+	if mr.Exists("zone:allocations:zone-1") {
+		t.Error("allocations hash survived RemoveZone()")
+	}
+}
+
+func TestZoneSyncer_SyncZoneClearsShards(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	syncer := NewZoneSyncer(nil, nil, client, 2)
+	ctx := context.Background()
+
+	// The booking service moved seats into its shards and has a transfer
+	// journaled; a zone update then writes the whole count to the pool
+	mr.Set("zone:availability:zone-1", "20")
+	mr.Set("zone:availability:zone-1:shard:0", "15")
+	mr.Set("zone:availability:zone-1:shard:1", "15")
+	mr.HSet("zone:transfers:zone-1:shard:1", "t1", "5:1000:zone:availability:zone-1:shard:0")
+
+	if err := syncer.SyncZone(ctx, &domain.ShowZone{ID: "zone-1", AvailableSeats: 60}); err != nil {
+		t.Fatalf("SyncZone() error = %v", err)
+	}
+	if got, _ := mr.Get("zone:availability:zone-1"); got != "60" {
+		t.Errorf("pool = %s, want 60", got)
+	}
+	for _, key := range []string{"zone:availability:zone-1:shard:0", "zone:availability:zone-1:shard:1", "zone:transfers:zone-1:shard:1"} {
+		if mr.Exists(key) {
+			t.Errorf("%s survived SyncZone(), its seats would be counted twice", key)
+		}
+	}
+
+	mr.Set("zone:availability:zone-1:shard:0", "3")
+	if err := syncer.RemoveZone(ctx, "zone-1"); err != nil {
+		t.Fatalf("RemoveZone() error = %v", err)
+	}
+	if mr.Exists("zone:availability:zone-1:shard:0") {
+		t.Error("shard survived RemoveZone()")
+	}
+}
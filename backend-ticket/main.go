@@ -14,6 +14,7 @@ import (
 	"github.com/prohmpiriya/booking-rush-10k-rps/backend-ticket/internal/di"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/config"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/database"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/middleware"
 	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
@@ -94,6 +95,7 @@ func main() {
 		Port:          cfg.Redis.Port,
 		Password:      cfg.Redis.Password,
 		DB:            cfg.Redis.DB,
+		ClusterAddrs:  cfg.Redis.ClusterAddrs,
 		PoolSize:      cfg.Redis.PoolSize,
 		MinIdleConns:  cfg.Redis.MinIdleConns,
 		DialTimeout:   cfg.Redis.DialTimeout,
@@ -113,11 +115,35 @@ func main() {
 		appLog.Info(fmt.Sprintf("Redis connected (%s)", redisCfg.Addr()))
 	}
 
+	// Initialize Kafka producer (optional - event queues will not follow sale
+	// windows if it fails)
+	var producer *kafka.Producer
+	if cfg.Booking.QueueScheduleEnabled {
+		producer, err = kafka.NewProducer(ctx, &kafka.ProducerConfig{
+			Brokers:       cfg.Kafka.Brokers,
+			ClientID:      "ticket-service-producer",
+			MaxRetries:    3,
+			RetryInterval: 2 * time.Second,
+		})
+		if err != nil {
+			appLog.Warn(fmt.Sprintf("Kafka connection failed (queue schedules disabled): %v", err))
+			producer = nil
+		} else {
+			defer producer.Close()
+			appLog.Info("Kafka producer connected")
+		}
+	}
+
 	// Build dependency injection container
-	container := di.NewContainer(&di.ContainerConfig{
-		DB:    db,
-		Redis: redisClient,
-	})
+	containerCfg := &di.ContainerConfig{
+		DB:         db,
+		Redis:      redisClient,
+		ZoneShards: cfg.Booking.ZoneInventoryShards,
+	}
+	if producer != nil {
+		containerCfg.Producer = producer
+	}
+	container := di.NewContainer(containerCfg)
 
 	// Setup Gin
 	if cfg.IsDevelopment() {
@@ -208,6 +234,9 @@ func main() {
 			{
 				protectedZones.PUT("/:id", container.ShowZoneHandler.Update)
 				protectedZones.DELETE("/:id", container.ShowZoneHandler.Delete)
+				protectedZones.GET("/:id/allocations", container.ShowZoneHandler.GetAllocations)
+				protectedZones.POST("/:id/allocations/move", container.ShowZoneHandler.MoveAllocation)
+				protectedZones.POST("/:id/allocations/issue", container.ShowZoneHandler.IssueAllocation)
 			}
 		}
 
@@ -30,6 +30,84 @@ type BookingServiceConfig struct {
 	MaxTicketsPerUser     int  `mapstructure:"max_tickets_per_user"`    // Maximum tickets per user per event (0 = unlimited)
 	ReservationTTLMinutes int  `mapstructure:"reservation_ttl_minutes"` // Reservation TTL in minutes
 	RequireQueuePass      bool `mapstructure:"require_queue_pass"`      // Require queue pass for booking (virtual queue enforcement)
+
+	// Inventory reconciliation (drift between Redis, ticket DB and booking rows)
+	InventoryReconcileInterval    time.Duration `mapstructure:"inventory_reconcile_interval"`     // Interval between runs (0 disables periodic runs)
+	InventoryReconcileAutoCorrect bool          `mapstructure:"inventory_reconcile_auto_correct"` // Adjust drifted Redis availability automatically
+	InventoryReconcileMaxCorrect  int64         `mapstructure:"inventory_reconcile_max_correct"`  // Largest drift (seats) corrected automatically
+
+	// Sharded zone inventory: each zone's seats are split across this many
+	// Redis counters (0 or 1 = single counter). Must be the same for every
+	// process that reserves or releases seats.
+	ZoneInventoryShards int `mapstructure:"zone_inventory_shards"`
+
+	// Serve reservations from Postgres while Redis is unavailable and resync
+	// them into Redis once it recovers
+	ReservationFallbackEnabled bool `mapstructure:"reservation_fallback_enabled"`
+
+	// Directory the admin API reloads Lua scripts from (<name>.lua); empty
+	// disables reload and the embedded scripts are always used
+	LuaScriptsDir string `mapstructure:"lua_scripts_dir"`
+
+	// Most seats one comp ticket request may issue
+	CompMaxSeatsPerRequest int `mapstructure:"comp_max_seats_per_request"`
+
+	// Push zone availability changes to event pages over SSE; each stream
+	// receives at most one coalesced update per interval
+	AvailabilityStreamEnabled  bool          `mapstructure:"availability_stream_enabled"`
+	AvailabilityStreamInterval time.Duration `mapstructure:"availability_stream_interval"`
+
+	// Per-event purchase limits on the identities behind accounts (verified
+	// phone, payment card, device, IP /24) so scalpers cannot spread one
+	// buyer's purchases over many accounts. 0 = unlimited for that kind;
+	// events can override the limits through the admin API.
+	IdentityLimitsEnabled     bool          `mapstructure:"identity_limits_enabled"`
+	IdentityLimitPhone        int           `mapstructure:"identity_limit_phone"`
+	IdentityLimitCard         int           `mapstructure:"identity_limit_card"`
+	IdentityLimitDevice       int           `mapstructure:"identity_limit_device"`
+	IdentityLimitIPSubnet     int           `mapstructure:"identity_limit_ip_subnet"`
+	IdentityLimitWindow       time.Duration `mapstructure:"identity_limit_window"`       // How long seats count against an identity
+	IdentityFingerprintSecret string        `mapstructure:"identity_fingerprint_secret"` // Keys identity fingerprints (default: JWT secret)
+
+	// Report reservation success, latency and payments per event so the
+	// queue release worker can adapt how fast it admits users
+	AdmissionHealthEnabled bool `mapstructure:"admission_health_enabled"`
+
+	// Screen queue joins for bots: each signal adds its score and joins are
+	// parked at the back of the queue or rejected at the thresholds. Scores
+	// of 0 turn a signal off; decisions are written to the audit log.
+	JoinScreeningEnabled      bool          `mapstructure:"join_screening_enabled"`
+	JoinScreenParkScore       int           `mapstructure:"join_screen_park_score"`
+	JoinScreenRejectScore     int           `mapstructure:"join_screen_reject_score"`
+	JoinChallengeMissingScore int           `mapstructure:"join_challenge_missing_score"` // No proof-of-work from the gateway
+	JoinChallengeFailedScore  int           `mapstructure:"join_challenge_failed_score"`
+	JoinVelocityPerIP         int           `mapstructure:"join_velocity_per_ip"`  // Joins per IP per window before scoring
+	JoinVelocityPerASN        int           `mapstructure:"join_velocity_per_asn"` // Joins per network (ASN) per window before scoring
+	JoinVelocityWindow        time.Duration `mapstructure:"join_velocity_window"`
+	JoinVelocityScore         int           `mapstructure:"join_velocity_score"`
+	JoinDeviceMaxUsers        int           `mapstructure:"join_device_max_users"` // Accounts one device may join an event's queue with
+	JoinDeviceScore           int           `mapstructure:"join_device_score"`
+
+	// Drive event queues by the sale windows the ticket service publishes:
+	// the waiting room opens QueueWaitingRoomLead before the sale starts,
+	// joins until then are drawn by lottery, and the queue is drained when
+	// the sale ends
+	QueueScheduleEnabled bool          `mapstructure:"queue_schedule_enabled"`
+	QueueWaitingRoomLead time.Duration `mapstructure:"queue_waiting_room_lead"`
+
+	// Share singleton worker duties between replicas through Redis leases:
+	// queue releases are split per event, and the expiry scan and queue
+	// scheduler run on one elected leader. A stalled holder loses its lease
+	// after WorkerLeaseTTL.
+	WorkerLeasesEnabled bool          `mapstructure:"worker_leases_enabled"`
+	WorkerLeaseTTL      time.Duration `mapstructure:"worker_lease_ttl"`
+
+	// Serve queue positions over WebSockets (GET /queue/ws) next to SSE; one
+	// socket follows up to QueueSocketMaxEvents queues, and each instance
+	// refuses sockets past QueueSocketMaxConnections
+	QueueSocketEnabled        bool `mapstructure:"queue_ws_enabled"`
+	QueueSocketMaxConnections int  `mapstructure:"queue_ws_max_connections"`
+	QueueSocketMaxEvents      int  `mapstructure:"queue_ws_max_events"`
 }
 
 // ServicesConfig holds URLs of other microservices
@@ -54,6 +132,9 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	// TrustedProxies lists the proxy IPs/CIDRs whose forwarded client IP
+	// headers are believed; empty means the connection address is used
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // DatabaseConfig holds PostgreSQL connection settings
@@ -89,6 +170,14 @@ type RedisConfig struct {
 	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+
+	// ClusterAddrs lists Redis Cluster seed nodes; empty means single-node mode
+	ClusterAddrs []string `mapstructure:"cluster_addrs"`
+
+	// Circuit breaker: consecutive connection failures that open it and how
+	// long it stays open before probing Redis again
+	BreakerFailureThreshold int           `mapstructure:"breaker_failure_threshold"`
+	BreakerOpenTimeout      time.Duration `mapstructure:"breaker_open_timeout"`
 }
 
 // Addr returns the Redis address
@@ -98,9 +187,11 @@ func (r *RedisConfig) Addr() string {
 
 // KafkaConfig holds Kafka/Redpanda connection settings
 type KafkaConfig struct {
-	Brokers       []string `mapstructure:"brokers"`
-	ConsumerGroup string   `mapstructure:"consumer_group"`
-	ClientID      string   `mapstructure:"client_id"`
+	Brokers        []string          `mapstructure:"brokers"`
+	ConsumerGroup  string            `mapstructure:"consumer_group"`
+	ClientID       string            `mapstructure:"client_id"`
+	Encoding       string            `mapstructure:"encoding"`        // Default payload codec: json, protobuf, msgpack
+	TopicEncodings map[string]string `mapstructure:"topic_encodings"` // Per-topic codec overrides (topic=codec,...)
 }
 
 // MongoDBConfig holds MongoDB connection settings
@@ -268,11 +359,15 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("REDIS_DIAL_TIMEOUT", "5s")
 	v.SetDefault("REDIS_READ_TIMEOUT", "3s")
 	v.SetDefault("REDIS_WRITE_TIMEOUT", "3s")
+	v.SetDefault("REDIS_BREAKER_FAILURE_THRESHOLD", 5)
+	v.SetDefault("REDIS_BREAKER_OPEN_TIMEOUT", "5s")
 
 	// Kafka defaults
 	v.SetDefault("KAFKA_BROKERS", "localhost:9092")
 	v.SetDefault("KAFKA_CONSUMER_GROUP", "booking-rush")
 	v.SetDefault("KAFKA_CLIENT_ID", "booking-rush")
+	v.SetDefault("KAFKA_ENCODING", "json")
+	v.SetDefault("KAFKA_TOPIC_ENCODINGS", "")
 
 	// MongoDB defaults
 	v.SetDefault("MONGODB_URI", "mongodb://localhost:27017")
@@ -295,6 +390,41 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("MAX_TICKETS_PER_USER", 10)        // Default 10 tickets per user per event
 	v.SetDefault("RESERVATION_TTL_MINUTES", 10)    // Default 10 minutes reservation TTL
 	v.SetDefault("REQUIRE_QUEUE_PASS", false)      // Default: don't require queue pass (for backward compatibility)
+	v.SetDefault("INVENTORY_RECONCILE_INTERVAL", "1m")
+	v.SetDefault("INVENTORY_RECONCILE_AUTO_CORRECT", false) // Report-only until enabled
+	v.SetDefault("INVENTORY_RECONCILE_MAX_CORRECT", 10)
+	v.SetDefault("ZONE_INVENTORY_SHARDS", 0) // Single counter per zone
+	v.SetDefault("RESERVATION_FALLBACK_ENABLED", false)
+	v.SetDefault("LUA_SCRIPTS_DIR", "")
+	v.SetDefault("COMP_MAX_SEATS_PER_REQUEST", 500)
+	v.SetDefault("AVAILABILITY_STREAM_ENABLED", true)
+	v.SetDefault("AVAILABILITY_STREAM_INTERVAL", "500ms") // 2 updates/s per show
+	v.SetDefault("IDENTITY_LIMITS_ENABLED", false)
+	v.SetDefault("IDENTITY_LIMIT_PHONE", 10)
+	v.SetDefault("IDENTITY_LIMIT_CARD", 10)
+	v.SetDefault("IDENTITY_LIMIT_DEVICE", 10)
+	v.SetDefault("IDENTITY_LIMIT_IP_SUBNET", 40) // Shared networks (offices, campuses) hold many buyers
+	v.SetDefault("IDENTITY_LIMIT_WINDOW", "168h")
+	v.SetDefault("IDENTITY_FINGERPRINT_SECRET", "")
+	v.SetDefault("ADMISSION_HEALTH_ENABLED", true)
+	v.SetDefault("JOIN_SCREENING_ENABLED", false)
+	v.SetDefault("JOIN_SCREEN_PARK_SCORE", 50)
+	v.SetDefault("JOIN_SCREEN_REJECT_SCORE", 100)
+	v.SetDefault("JOIN_CHALLENGE_MISSING_SCORE", 0) // Raise once clients solve challenges
+	v.SetDefault("JOIN_CHALLENGE_FAILED_SCORE", 60)
+	v.SetDefault("JOIN_VELOCITY_PER_IP", 20)
+	v.SetDefault("JOIN_VELOCITY_PER_ASN", 2000)
+	v.SetDefault("JOIN_VELOCITY_WINDOW", "1m")
+	v.SetDefault("JOIN_VELOCITY_SCORE", 50)
+	v.SetDefault("JOIN_DEVICE_MAX_USERS", 3)
+	v.SetDefault("JOIN_DEVICE_SCORE", 50)
+	v.SetDefault("QUEUE_SCHEDULE_ENABLED", true)
+	v.SetDefault("QUEUE_WAITING_ROOM_LEAD", "30m")
+	v.SetDefault("WORKER_LEASES_ENABLED", true)
+	v.SetDefault("WORKER_LEASE_TTL", "15s")
+	v.SetDefault("QUEUE_WS_ENABLED", true)
+	v.SetDefault("QUEUE_WS_MAX_CONNECTIONS", 10000)
+	v.SetDefault("QUEUE_WS_MAX_EVENTS", 5)
 }
 
 func bindConfig(v *viper.Viper, cfg *Config) error {
@@ -310,6 +440,14 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.Server.ReadTimeout = v.GetDuration("SERVER_READ_TIMEOUT")
 	cfg.Server.WriteTimeout = v.GetDuration("SERVER_WRITE_TIMEOUT")
 	cfg.Server.IdleTimeout = v.GetDuration("SERVER_IDLE_TIMEOUT")
+	if proxies := v.GetString("SERVER_TRUSTED_PROXIES"); proxies != "" {
+		cfg.Server.TrustedProxies = strings.Split(proxies, ",")
+	}
+
+	// Services
+	cfg.Services.TicketServiceURL = v.GetString("TICKET_SERVICE_URL")
+	cfg.Services.AuthServiceURL = v.GetString("AUTH_SERVICE_URL")
+	cfg.Services.PaymentServiceURL = v.GetString("PAYMENT_SERVICE_URL")
 
 	// ==========================================================================
 	// Per-Service Database Bindings (No fallback - true microservice)
@@ -373,12 +511,19 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.Redis.DialTimeout = v.GetDuration("REDIS_DIAL_TIMEOUT")
 	cfg.Redis.ReadTimeout = v.GetDuration("REDIS_READ_TIMEOUT")
 	cfg.Redis.WriteTimeout = v.GetDuration("REDIS_WRITE_TIMEOUT")
+	if addrs := v.GetString("REDIS_CLUSTER_ADDRS"); addrs != "" {
+		cfg.Redis.ClusterAddrs = strings.Split(addrs, ",")
+	}
+	cfg.Redis.BreakerFailureThreshold = v.GetInt("REDIS_BREAKER_FAILURE_THRESHOLD")
+	cfg.Redis.BreakerOpenTimeout = v.GetDuration("REDIS_BREAKER_OPEN_TIMEOUT")
 
 	// Kafka
 	brokersStr := v.GetString("KAFKA_BROKERS")
 	cfg.Kafka.Brokers = strings.Split(brokersStr, ",")
 	cfg.Kafka.ConsumerGroup = v.GetString("KAFKA_CONSUMER_GROUP")
 	cfg.Kafka.ClientID = v.GetString("KAFKA_CLIENT_ID")
+	cfg.Kafka.Encoding = v.GetString("KAFKA_ENCODING")
+	cfg.Kafka.TopicEncodings = parseKeyValueList(v.GetString("KAFKA_TOPIC_ENCODINGS"))
 
 	// MongoDB
 	cfg.MongoDB.URI = v.GetString("MONGODB_URI")
@@ -401,6 +546,41 @@ func bindConfig(v *viper.Viper, cfg *Config) error {
 	cfg.Booking.MaxTicketsPerUser = v.GetInt("MAX_TICKETS_PER_USER")
 	cfg.Booking.ReservationTTLMinutes = v.GetInt("RESERVATION_TTL_MINUTES")
 	cfg.Booking.RequireQueuePass = v.GetBool("REQUIRE_QUEUE_PASS")
+	cfg.Booking.InventoryReconcileInterval = v.GetDuration("INVENTORY_RECONCILE_INTERVAL")
+	cfg.Booking.InventoryReconcileAutoCorrect = v.GetBool("INVENTORY_RECONCILE_AUTO_CORRECT")
+	cfg.Booking.InventoryReconcileMaxCorrect = v.GetInt64("INVENTORY_RECONCILE_MAX_CORRECT")
+	cfg.Booking.ZoneInventoryShards = v.GetInt("ZONE_INVENTORY_SHARDS")
+	cfg.Booking.ReservationFallbackEnabled = v.GetBool("RESERVATION_FALLBACK_ENABLED")
+	cfg.Booking.LuaScriptsDir = v.GetString("LUA_SCRIPTS_DIR")
+	cfg.Booking.CompMaxSeatsPerRequest = v.GetInt("COMP_MAX_SEATS_PER_REQUEST")
+	cfg.Booking.AvailabilityStreamEnabled = v.GetBool("AVAILABILITY_STREAM_ENABLED")
+	cfg.Booking.AvailabilityStreamInterval = v.GetDuration("AVAILABILITY_STREAM_INTERVAL")
+	cfg.Booking.IdentityLimitsEnabled = v.GetBool("IDENTITY_LIMITS_ENABLED")
+	cfg.Booking.IdentityLimitPhone = v.GetInt("IDENTITY_LIMIT_PHONE")
+	cfg.Booking.IdentityLimitCard = v.GetInt("IDENTITY_LIMIT_CARD")
+	cfg.Booking.IdentityLimitDevice = v.GetInt("IDENTITY_LIMIT_DEVICE")
+	cfg.Booking.IdentityLimitIPSubnet = v.GetInt("IDENTITY_LIMIT_IP_SUBNET")
+	cfg.Booking.IdentityLimitWindow = v.GetDuration("IDENTITY_LIMIT_WINDOW")
+	cfg.Booking.IdentityFingerprintSecret = v.GetString("IDENTITY_FINGERPRINT_SECRET")
+	cfg.Booking.AdmissionHealthEnabled = v.GetBool("ADMISSION_HEALTH_ENABLED")
+	cfg.Booking.JoinScreeningEnabled = v.GetBool("JOIN_SCREENING_ENABLED")
+	cfg.Booking.JoinScreenParkScore = v.GetInt("JOIN_SCREEN_PARK_SCORE")
+	cfg.Booking.JoinScreenRejectScore = v.GetInt("JOIN_SCREEN_REJECT_SCORE")
+	cfg.Booking.JoinChallengeMissingScore = v.GetInt("JOIN_CHALLENGE_MISSING_SCORE")
+	cfg.Booking.JoinChallengeFailedScore = v.GetInt("JOIN_CHALLENGE_FAILED_SCORE")
+	cfg.Booking.JoinVelocityPerIP = v.GetInt("JOIN_VELOCITY_PER_IP")
+	cfg.Booking.JoinVelocityPerASN = v.GetInt("JOIN_VELOCITY_PER_ASN")
+	cfg.Booking.JoinVelocityWindow = v.GetDuration("JOIN_VELOCITY_WINDOW")
+	cfg.Booking.JoinVelocityScore = v.GetInt("JOIN_VELOCITY_SCORE")
+	cfg.Booking.JoinDeviceMaxUsers = v.GetInt("JOIN_DEVICE_MAX_USERS")
+	cfg.Booking.JoinDeviceScore = v.GetInt("JOIN_DEVICE_SCORE")
+	cfg.Booking.QueueScheduleEnabled = v.GetBool("QUEUE_SCHEDULE_ENABLED")
+	cfg.Booking.QueueWaitingRoomLead = v.GetDuration("QUEUE_WAITING_ROOM_LEAD")
+	cfg.Booking.WorkerLeasesEnabled = v.GetBool("WORKER_LEASES_ENABLED")
+	cfg.Booking.WorkerLeaseTTL = v.GetDuration("WORKER_LEASE_TTL")
+	cfg.Booking.QueueSocketEnabled = v.GetBool("QUEUE_WS_ENABLED")
+	cfg.Booking.QueueSocketMaxConnections = v.GetInt("QUEUE_WS_MAX_CONNECTIONS")
+	cfg.Booking.QueueSocketMaxEvents = v.GetInt("QUEUE_WS_MAX_EVENTS")
 
 	return nil
 }
@@ -480,3 +660,16 @@ func (c *Config) IsProduction() bool {
 func (c *Config) IsDevelopment() bool {
 	return c.App.Environment == "development"
 }
+
+// parseKeyValueList parses "k1=v1,k2=v2" into a map, skipping malformed entries
+func parseKeyValueList(s string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return result
+}
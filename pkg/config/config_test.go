@@ -364,3 +364,14 @@ func TestConfig_IsDevelopment(t *testing.T) {
 		t.Error("IsDevelopment() = true, want false")
 	}
 }
+
+func TestParseKeyValueList(t *testing.T) {
+	got := parseKeyValueList(" booking-events = protobuf ,payment-events=msgpack,broken,=json,")
+
+	if len(got) != 2 {
+		t.Fatalf("parseKeyValueList() = %v, want 2 entries", got)
+	}
+	if got["booking-events"] != "protobuf" || got["payment-events"] != "msgpack" {
+		t.Errorf("parseKeyValueList() = %v", got)
+	}
+}
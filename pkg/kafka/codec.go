@@ -0,0 +1,254 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// HeaderContentType is the record header carrying the payload encoding
+const HeaderContentType = "content_type"
+
+// Content types written to HeaderContentType
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeMsgPack  = "application/msgpack"
+)
+
+var (
+	// ErrUnknownContentType is returned when a record's content type has no registered codec
+	ErrUnknownContentType = errors.New("unknown content type")
+	// ErrCodecUnsupportedType is returned when a codec cannot encode or decode a Go type
+	ErrCodecUnsupportedType = errors.New("type not supported by codec")
+)
+
+// Codec encodes and decodes message payloads
+type Codec interface {
+	// Name is the short name used in configuration (json, protobuf, msgpack)
+	Name() string
+	// ContentType is the value written to the content type header
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes payloads with encoding/json
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string        { return "json" }
+func (JSONCodec) ContentType() string { return ContentTypeJSON }
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtoMarshaler is implemented by types with a hand-written protobuf encoding
+type ProtoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+// ProtoUnmarshaler is implemented by types with a hand-written protobuf decoding
+type ProtoUnmarshaler interface {
+	UnmarshalProto(data []byte) error
+}
+
+// ProtobufCodec encodes generated proto.Message types and types implementing
+// ProtoMarshaler / ProtoUnmarshaler
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Name() string        { return "protobuf" }
+func (ProtobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case proto.Message:
+		return proto.Marshal(m)
+	case ProtoMarshaler:
+		return m.MarshalProto()
+	default:
+		return nil, fmt.Errorf("%w: protobuf cannot marshal %T", ErrCodecUnsupportedType, v)
+	}
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	switch m := v.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, m)
+	case ProtoUnmarshaler:
+		return m.UnmarshalProto(data)
+	default:
+		return fmt.Errorf("%w: protobuf cannot unmarshal into %T", ErrCodecUnsupportedType, v)
+	}
+}
+
+// msgpackHandle is shared by all MsgPackCodec values; a configured handle is
+// safe for concurrent use. Struct fields use their json tag names.
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.WriteExt = true
+	h.RawToString = true
+	return h
+}()
+
+// MsgPackCodec encodes payloads as MessagePack using json struct tags for field names
+type MsgPackCodec struct{}
+
+func (MsgPackCodec) Name() string        { return "msgpack" }
+func (MsgPackCodec) ContentType() string { return ContentTypeMsgPack }
+
+func (MsgPackCodec) Marshal(v interface{}) ([]byte, error) {
+	var b []byte
+	if err := codec.NewEncoderBytes(&b, msgpackHandle).Encode(v); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (MsgPackCodec) Unmarshal(data []byte, v interface{}) error {
+	return codec.NewDecoderBytes(data, msgpackHandle).Decode(v)
+}
+
+// CodecByName returns a built-in codec by its configuration name
+func CodecByName(name string) (Codec, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "json":
+		return JSONCodec{}, nil
+	case "protobuf", "proto":
+		return ProtobufCodec{}, nil
+	case "msgpack", "messagepack":
+		return MsgPackCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec %q", name)
+	}
+}
+
+// CodecRegistry selects the codec used to encode each topic and resolves the
+// codec for incoming records from their content type header
+type CodecRegistry struct {
+	mu            sync.RWMutex
+	defaultCodec  Codec
+	topics        map[string]Codec
+	byContentType map[string]Codec
+}
+
+// NewCodecRegistry creates a registry that encodes with defaultCodec unless a
+// topic overrides it. All built-in codecs are registered for decoding.
+func NewCodecRegistry(defaultCodec Codec) *CodecRegistry {
+	if defaultCodec == nil {
+		defaultCodec = JSONCodec{}
+	}
+	r := &CodecRegistry{
+		defaultCodec:  defaultCodec,
+		topics:        make(map[string]Codec),
+		byContentType: make(map[string]Codec),
+	}
+	r.Register(JSONCodec{})
+	r.Register(ProtobufCodec{})
+	r.Register(MsgPackCodec{})
+	r.Register(defaultCodec)
+	return r
+}
+
+// NewCodecRegistryFromNames builds a registry from configuration names,
+// e.g. default "json" and {"booking-events": "protobuf"}
+func NewCodecRegistryFromNames(defaultName string, topicCodecs map[string]string) (*CodecRegistry, error) {
+	defaultCodec, err := CodecByName(defaultName)
+	if err != nil {
+		return nil, err
+	}
+	r := NewCodecRegistry(defaultCodec)
+	for topic, name := range topicCodecs {
+		c, err := CodecByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		r.SetTopicCodec(topic, c)
+	}
+	return r, nil
+}
+
+// Register makes a codec available for decoding by its content type
+func (r *CodecRegistry) Register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byContentType[c.ContentType()] = c
+}
+
+// SetTopicCodec sets the codec used to encode messages for a topic
+func (r *CodecRegistry) SetTopicCodec(topic string, c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics[topic] = c
+	r.byContentType[c.ContentType()] = c
+}
+
+// ForTopic returns the codec used to encode messages for a topic
+func (r *CodecRegistry) ForTopic(topic string) Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.topics[topic]; ok {
+		return c
+	}
+	return r.defaultCodec
+}
+
+// ForContentType returns the codec registered for a content type.
+// An empty content type resolves to JSON, which is what unlabelled
+// messages produced before codecs existed contain.
+func (r *CodecRegistry) ForContentType(contentType string) (Codec, error) {
+	if contentType == "" {
+		return JSONCodec{}, nil
+	}
+	// Ignore parameters such as "; charset=utf-8"
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.byContentType[contentType]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownContentType, contentType)
+}
+
+// Encode encodes v with the topic's codec and returns the content type to send with it
+func (r *CodecRegistry) Encode(topic string, v interface{}) ([]byte, string, error) {
+	c := r.ForTopic(topic)
+	data, err := c.Marshal(v)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode %s payload: %w", c.Name(), err)
+	}
+	return data, c.ContentType(), nil
+}
+
+// Decode decodes a record into v using the codec named by its content type header
+func (r *CodecRegistry) Decode(record *Record, v interface{}) error {
+	c, err := r.ForContentType(record.Headers[HeaderContentType])
+	if err != nil {
+		return err
+	}
+	if err := c.Unmarshal(record.Value, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", c.Name(), err)
+	}
+	return nil
+}
+
+// DefaultCodecs is the registry used by Record.Decode and by producers
+// created without ProducerConfig.Codecs
+var DefaultCodecs = NewCodecRegistry(JSONCodec{})
+
+// Decode decodes the record value into v based on its content type header
+func (r *Record) Decode(v interface{}) error {
+	return DefaultCodecs.Decode(r, v)
+}
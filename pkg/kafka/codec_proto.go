@@ -0,0 +1,144 @@
+package kafka
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Helpers for types implementing ProtoMarshaler / ProtoUnmarshaler by hand.
+// They follow proto3 semantics: zero values are omitted when encoding and
+// missing fields decode to zero values.
+
+// AppendProtoString appends a string field
+func AppendProtoString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// AppendProtoInt64 appends an int32/int64 field
+func AppendProtoInt64(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// AppendProtoDouble appends a double field
+func AppendProtoDouble(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+// AppendProtoMessage appends an embedded message field; a nil message is omitted
+// while an empty non-nil message is written so it decodes as present
+func AppendProtoMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	if msg == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// AppendProtoTimestamp appends a google.protobuf.Timestamp field; zero times are omitted
+func AppendProtoTimestamp(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var ts []byte
+	ts = AppendProtoInt64(ts, 1, t.Unix())
+	ts = AppendProtoInt64(ts, 2, int64(t.Nanosecond()))
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, ts)
+}
+
+// ProtoField is a decoded field value passed to RangeProtoFields callbacks
+type ProtoField struct {
+	typ     protowire.Type
+	scalar  uint64
+	payload []byte
+}
+
+// String returns a string field value
+func (f ProtoField) String() string {
+	return string(f.payload)
+}
+
+// Bytes returns a bytes or embedded message field value
+func (f ProtoField) Bytes() []byte {
+	return f.payload
+}
+
+// Int64 returns an int32/int64 field value
+func (f ProtoField) Int64() int64 {
+	return int64(f.scalar)
+}
+
+// Double returns a double field value
+func (f ProtoField) Double() float64 {
+	return math.Float64frombits(f.scalar)
+}
+
+// Timestamp decodes a google.protobuf.Timestamp field value as UTC
+func (f ProtoField) Timestamp() (time.Time, error) {
+	var seconds, nanos int64
+	err := RangeProtoFields(f.payload, func(num protowire.Number, field ProtoField) error {
+		switch num {
+		case 1:
+			seconds = field.Int64()
+		case 2:
+			nanos = field.Int64()
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, nanos).UTC(), nil
+}
+
+// RangeProtoFields calls fn for every field in a protobuf message. Unknown
+// fields can simply be ignored by fn, keeping decoders forward compatible.
+func RangeProtoFields(b []byte, fn func(num protowire.Number, field ProtoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf tag: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		field := ProtoField{typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			field.scalar, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			field.scalar, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			field.scalar = uint64(v)
+		case protowire.BytesType:
+			field.payload, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf field %d: %w", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(num, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type codecTestPayload struct {
+	ID        string    `json:"id"`
+	Quantity  int       `json:"quantity"`
+	Price     float64   `json:"price"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// protoTestPayload is a hand-encoded message: 1=id, 2=quantity, 3=price, 4=created_at
+type protoTestPayload struct {
+	codecTestPayload
+}
+
+func (p *protoTestPayload) MarshalProto() ([]byte, error) {
+	var b []byte
+	b = AppendProtoString(b, 1, p.ID)
+	b = AppendProtoInt64(b, 2, int64(p.Quantity))
+	b = AppendProtoDouble(b, 3, p.Price)
+	b = AppendProtoTimestamp(b, 4, p.CreatedAt)
+	return b, nil
+}
+
+func (p *protoTestPayload) UnmarshalProto(data []byte) error {
+	return RangeProtoFields(data, func(num protowire.Number, f ProtoField) error {
+		var err error
+		switch num {
+		case 1:
+			p.ID = f.String()
+		case 2:
+			p.Quantity = int(f.Int64())
+		case 3:
+			p.Price = f.Double()
+		case 4:
+			p.CreatedAt, err = f.Timestamp()
+		}
+		return err
+	})
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	in := codecTestPayload{ID: "b-1", Quantity: 3, Price: 1500.5, CreatedAt: createdAt}
+
+	for _, c := range []Codec{JSONCodec{}, MsgPackCodec{}} {
+		t.Run(c.Name(), func(t *testing.T) {
+			data, err := c.Marshal(&in)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var out codecTestPayload
+			if err := c.Unmarshal(data, &out); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if out.ID != in.ID || out.Quantity != in.Quantity || out.Price != in.Price || !out.CreatedAt.Equal(in.CreatedAt) {
+				t.Errorf("round trip = %+v, want %+v", out, in)
+			}
+		})
+	}
+
+	t.Run("protobuf", func(t *testing.T) {
+		c := ProtobufCodec{}
+		data, err := c.Marshal(&protoTestPayload{in})
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var out protoTestPayload
+		if err := c.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if out.ID != in.ID || out.Quantity != in.Quantity || out.Price != in.Price || !out.CreatedAt.Equal(in.CreatedAt) {
+			t.Errorf("round trip = %+v, want %+v", out.codecTestPayload, in)
+		}
+	})
+}
+
+func TestMsgPackCodec_UsesJSONFieldNames(t *testing.T) {
+	data, err := MsgPackCodec{}.Marshal(codecTestPayload{ID: "b-1"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var generic map[string]interface{}
+	if err := (MsgPackCodec{}).Unmarshal(data, &generic); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if generic["id"] != "b-1" {
+		t.Errorf("decoded map = %v, want key \"id\"", generic)
+	}
+	if _, ok := generic["note"]; ok {
+		t.Error("omitempty field should not be encoded")
+	}
+}
+
+func TestProtobufCodec_GeneratedMessages(t *testing.T) {
+	ts := timestamppb.New(time.Unix(1700000000, 42))
+
+	data, err := ProtobufCodec{}.Marshal(ts)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	// The hand-written timestamp helper must be wire compatible with the well-known type
+	field := ProtoField{payload: data}
+	got, err := field.Timestamp()
+	if err != nil || !got.Equal(ts.AsTime()) {
+		t.Errorf("Timestamp() = %v, %v, want %v", got, err, ts.AsTime())
+	}
+
+	var encoded []byte
+	encoded = AppendProtoInt64(encoded, 1, ts.AsTime().Unix())
+	encoded = AppendProtoInt64(encoded, 2, int64(ts.AsTime().Nanosecond()))
+
+	var out timestamppb.Timestamp
+	if err := (ProtobufCodec{}).Unmarshal(encoded, &out); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !out.AsTime().Equal(ts.AsTime()) {
+		t.Errorf("Unmarshal() = %v, want %v", out.AsTime(), ts.AsTime())
+	}
+}
+
+func TestProtobufCodec_UnsupportedType(t *testing.T) {
+	if _, err := (ProtobufCodec{}).Marshal(codecTestPayload{}); !errors.Is(err, ErrCodecUnsupportedType) {
+		t.Errorf("Marshal() error = %v, want ErrCodecUnsupportedType", err)
+	}
+	if err := (ProtobufCodec{}).Unmarshal(nil, &codecTestPayload{}); !errors.Is(err, ErrCodecUnsupportedType) {
+		t.Errorf("Unmarshal() error = %v, want ErrCodecUnsupportedType", err)
+	}
+}
+
+func TestCodecRegistry_TopicSelectionAndDecode(t *testing.T) {
+	registry, err := NewCodecRegistryFromNames("json", map[string]string{"booking-events": "msgpack"})
+	if err != nil {
+		t.Fatalf("NewCodecRegistryFromNames() error = %v", err)
+	}
+
+	if got := registry.ForTopic("booking-events").Name(); got != "msgpack" {
+		t.Errorf("ForTopic(booking-events) = %s, want msgpack", got)
+	}
+	if got := registry.ForTopic("other").Name(); got != "json" {
+		t.Errorf("ForTopic(other) = %s, want json", got)
+	}
+
+	in := codecTestPayload{ID: "b-2", Quantity: 1}
+	value, contentType, err := registry.Encode("booking-events", in)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if contentType != ContentTypeMsgPack {
+		t.Errorf("content type = %s, want %s", contentType, ContentTypeMsgPack)
+	}
+
+	// Any consumer decodes by header, independent of its own topic settings
+	record := &Record{Value: value, Headers: map[string]string{HeaderContentType: contentType}}
+	var out codecTestPayload
+	if err := record.Decode(&out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out.ID != "b-2" || out.Quantity != 1 {
+		t.Errorf("Decode() = %+v", out)
+	}
+}
+
+func TestCodecRegistry_ContentTypeResolution(t *testing.T) {
+	registry := NewCodecRegistry(nil)
+
+	tests := []struct {
+		contentType string
+		want        string
+		wantErr     bool
+	}{
+		{contentType: "", want: "json"},
+		{contentType: "application/json; charset=utf-8", want: "json"},
+		{contentType: ContentTypeProtobuf, want: "protobuf"},
+		{contentType: ContentTypeMsgPack, want: "msgpack"},
+		{contentType: "text/plain", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			c, err := registry.ForContentType(tt.contentType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownContentType) {
+					t.Errorf("ForContentType() error = %v, want ErrUnknownContentType", err)
+				}
+				return
+			}
+			if err != nil || c.Name() != tt.want {
+				t.Errorf("ForContentType() = %v, %v, want %s", c, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodecByName_Unknown(t *testing.T) {
+	if _, err := CodecByName("avro"); err == nil {
+		t.Error("expected error for unknown codec")
+	}
+	if _, err := NewCodecRegistryFromNames("json", map[string]string{"t": "avro"}); err == nil {
+		t.Error("expected error for unknown topic codec")
+	}
+}
+
+func TestWithContentType_CopiesHeaders(t *testing.T) {
+	headers := map[string]string{"event_type": "booking.created"}
+	out := withContentType(headers, ContentTypeJSON)
+
+	if out[HeaderContentType] != ContentTypeJSON || out["event_type"] != "booking.created" {
+		t.Errorf("withContentType() = %v", out)
+	}
+	if _, ok := headers[HeaderContentType]; ok {
+		t.Error("withContentType() must not modify the caller's headers")
+	}
+}
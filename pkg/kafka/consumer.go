@@ -29,6 +29,9 @@ type ConsumerConfig struct {
 	SessionTimeout  time.Duration
 	RebalanceTimeout time.Duration
 	AutoCommit      bool
+	// ReadCommitted skips records from aborted transactions and waits for
+	// open transactions to finish; use it downstream of transactional producers
+	ReadCommitted bool
 }
 
 // NewConsumer creates a new Kafka consumer
@@ -68,6 +71,10 @@ func NewConsumer(ctx context.Context, cfg *ConsumerConfig) (*Consumer, error) {
 		opts = append(opts, kgo.RebalanceTimeout(cfg.RebalanceTimeout))
 	}
 
+	if cfg.ReadCommitted {
+		opts = append(opts, kgo.FetchIsolationLevel(kgo.ReadCommitted()))
+	}
+
 	var client *kgo.Client
 	var err error
 
@@ -130,25 +137,30 @@ func (c *Consumer) Poll(ctx context.Context) ([]*Record, error) {
 
 	var records []*Record
 	fetches.EachRecord(func(r *kgo.Record) {
-		headers := make(map[string]string)
-		for _, h := range r.Headers {
-			headers[h.Key] = string(h.Value)
-		}
-
-		records = append(records, &Record{
-			Topic:     r.Topic,
-			Partition: r.Partition,
-			Offset:    r.Offset,
-			Key:       r.Key,
-			Value:     r.Value,
-			Headers:   headers,
-			Timestamp: r.Timestamp,
-		})
+		records = append(records, newRecord(r))
 	})
 
 	return records, nil
 }
 
+// newRecord converts a franz-go record into a Record
+func newRecord(r *kgo.Record) *Record {
+	headers := make(map[string]string, len(r.Headers))
+	for _, h := range r.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+
+	return &Record{
+		Topic:     r.Topic,
+		Partition: r.Partition,
+		Offset:    r.Offset,
+		Key:       r.Key,
+		Value:     r.Value,
+		Headers:   headers,
+		Timestamp: r.Timestamp,
+	}
+}
+
 // Record represents a consumed Kafka record
 type Record struct {
 	Topic     string
@@ -0,0 +1,37 @@
+package kafka
+
+import "context"
+
+// MessageProducer is the producing surface of Producer. Code that only needs
+// to publish should depend on it so tests can swap in kafkatest.Producer.
+type MessageProducer interface {
+	Produce(ctx context.Context, msg *Message) error
+	ProduceJSON(ctx context.Context, topic string, key string, data interface{}, headers map[string]string) error
+	ProduceValue(ctx context.Context, topic string, key string, data interface{}, headers map[string]string) error
+	ProduceAsync(ctx context.Context, msg *Message, callback func(error))
+	Flush(ctx context.Context) error
+	Ping(ctx context.Context) error
+	Close()
+}
+
+// MessageConsumer is the polling surface of Consumer
+type MessageConsumer interface {
+	Poll(ctx context.Context) ([]*Record, error)
+	CommitRecords(ctx context.Context, records []*Record) error
+	CommitOffsets(ctx context.Context) error
+	Ping(ctx context.Context) error
+	Close()
+}
+
+// RecordRunner is the surface of Runner used by workers
+type RecordRunner interface {
+	// Run dispatches records to handler until ctx is cancelled
+	Run(ctx context.Context, handler RecordHandler) error
+	Close()
+}
+
+var (
+	_ MessageProducer = (*Producer)(nil)
+	_ MessageConsumer = (*Consumer)(nil)
+	_ RecordRunner    = (*Runner)(nil)
+)
@@ -0,0 +1,375 @@
+// Package kafkatest provides an in-memory Kafka broker for tests.
+//
+// Producers, consumers and runners created from a Broker implement
+// kafka.MessageProducer, kafka.MessageConsumer and kafka.RecordRunner, so
+// services wired against those interfaces can run full event flows inside
+// go test without a cluster. The broker models topics with partitions, keyed
+// partitioning, consumer groups with committed offsets and rebalancing on
+// join/leave, and record headers. Transactions and retention are not modelled.
+package kafkatest
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+)
+
+// DefaultPartitions is the partition count of auto-created topics
+const DefaultPartitions = 3
+
+// maxPollRecords caps records returned by a single Poll
+const maxPollRecords = 500
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type topic struct {
+	partitions [][]*kafka.Record
+	// log keeps every record in produce order across partitions
+	log []*kafka.Record
+	// next round-robins records without a key
+	next uint32
+}
+
+type group struct {
+	committed map[topicPartition]int64
+	members   []*Consumer
+}
+
+// Broker is an in-memory Kafka cluster. The zero value is not usable; create
+// one with NewBroker.
+type Broker struct {
+	mu         sync.Mutex
+	partitions int32
+	topics     map[string]*topic
+	groups     map[string]*group
+	// notify is closed and replaced whenever records are appended
+	notify chan struct{}
+}
+
+// NewBroker creates an empty broker that auto-creates topics with
+// DefaultPartitions partitions
+func NewBroker() *Broker {
+	return &Broker{
+		partitions: DefaultPartitions,
+		topics:     make(map[string]*topic),
+		groups:     make(map[string]*group),
+		notify:     make(chan struct{}),
+	}
+}
+
+// CreateTopic creates a topic with the given number of partitions. It is a
+// no-op if the topic already exists.
+func (b *Broker) CreateTopic(name string, partitions int32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.createTopicLocked(name, partitions)
+}
+
+func (b *Broker) createTopicLocked(name string, partitions int32) *topic {
+	if t, ok := b.topics[name]; ok {
+		return t
+	}
+	if partitions <= 0 {
+		partitions = b.partitions
+	}
+	t := &topic{partitions: make([][]*kafka.Record, partitions)}
+	b.topics[name] = t
+
+	// Members already subscribed to the topic pick up its partitions
+	for _, g := range b.groups {
+		for _, m := range g.members {
+			if m.subscribes(name) {
+				g.rebalance(b)
+				break
+			}
+		}
+	}
+	return t
+}
+
+// append stores a record and wakes up pollers
+func (b *Broker) append(msg *kafka.Message) *kafka.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.createTopicLocked(msg.Topic, 0)
+	partition := t.partitionFor(msg.Key)
+
+	ts := msg.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	rec := &kafka.Record{
+		Topic:     msg.Topic,
+		Partition: partition,
+		Offset:    int64(len(t.partitions[partition])),
+		Key:       cloneBytes(msg.Key),
+		Value:     cloneBytes(msg.Value),
+		Headers:   cloneHeaders(msg.Headers),
+		Timestamp: ts,
+	}
+	t.partitions[partition] = append(t.partitions[partition], rec)
+	t.log = append(t.log, rec)
+
+	close(b.notify)
+	b.notify = make(chan struct{})
+	return rec
+}
+
+// partitionFor hashes keyed records and round-robins records without a key
+func (t *topic) partitionFor(key []byte) int32 {
+	n := uint32(len(t.partitions))
+	if len(key) == 0 {
+		p := t.next % n
+		t.next++
+		return int32(p)
+	}
+	h := fnv.New32a()
+	h.Write(key)
+	return int32(h.Sum32() % n)
+}
+
+// Records returns copies of all records produced to a topic, in produce order
+func (b *Broker) Records(topic string) []*kafka.Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[topic]
+	if !ok {
+		return nil
+	}
+	out := make([]*kafka.Record, len(t.log))
+	for i, r := range t.log {
+		out[i] = cloneRecord(r)
+	}
+	return out
+}
+
+// WaitFor blocks until a record matching match is produced to topic and
+// returns it. Records produced before the call are matched as well.
+func (b *Broker) WaitFor(ctx context.Context, topic string, match func(*kafka.Record) bool) (*kafka.Record, error) {
+	seen := 0
+	for {
+		b.mu.Lock()
+		var log []*kafka.Record
+		if t, ok := b.topics[topic]; ok {
+			log = t.log
+		}
+		wait := b.notify
+		b.mu.Unlock()
+
+		for ; seen < len(log); seen++ {
+			if match == nil || match(log[seen]) {
+				return cloneRecord(log[seen]), nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// WaitForRecords blocks until topic holds at least n records and returns them
+func (b *Broker) WaitForRecords(ctx context.Context, topic string, n int) ([]*kafka.Record, error) {
+	for {
+		b.mu.Lock()
+		count := 0
+		if t, ok := b.topics[topic]; ok {
+			count = len(t.log)
+		}
+		wait := b.notify
+		b.mu.Unlock()
+
+		if count >= n {
+			return b.Records(topic), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Committed returns a group's committed offset for a partition, or -1 if
+// nothing has been committed
+func (b *Broker) Committed(groupID, topic string, partition int32) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[groupID]
+	if !ok {
+		return -1
+	}
+	off, ok := g.committed[topicPartition{topic, partition}]
+	if !ok {
+		return -1
+	}
+	return off
+}
+
+// Lag returns how many records of topic the group has not committed yet
+func (b *Broker) Lag(groupID, topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[topic]
+	if !ok {
+		return 0
+	}
+	var committed map[topicPartition]int64
+	if g, ok := b.groups[groupID]; ok {
+		committed = g.committed
+	}
+
+	var lag int64
+	for p, records := range t.partitions {
+		lag += int64(len(records)) - committed[topicPartition{topic, int32(p)}]
+	}
+	return lag
+}
+
+// Topics returns the names of all topics, sorted
+func (b *Broker) Topics() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	names := make([]string, 0, len(b.topics))
+	for name := range b.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// join adds a consumer to its group and rebalances
+func (b *Broker) join(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range c.topics {
+		b.createTopicLocked(name, 0)
+	}
+
+	g, ok := b.groups[c.groupID]
+	if !ok {
+		g = &group{committed: make(map[topicPartition]int64)}
+		b.groups[c.groupID] = g
+	}
+	g.members = append(g.members, c)
+	g.rebalance(b)
+}
+
+// leave removes a consumer from its group and rebalances
+func (b *Broker) leave(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[c.groupID]
+	if !ok {
+		return
+	}
+	for i, m := range g.members {
+		if m == c {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			break
+		}
+	}
+	c.positions = nil
+	g.rebalance(b)
+}
+
+// rebalance spreads every partition of the subscribed topics round-robin over
+// the members subscribed to it. Partitions a member already owned keep their
+// position; newly assigned ones resume from the committed offset, so records
+// polled but not committed by a previous owner are redelivered.
+func (g *group) rebalance(b *Broker) {
+	assignment := make(map[*Consumer]map[topicPartition]bool, len(g.members))
+	for _, m := range g.members {
+		assignment[m] = make(map[topicPartition]bool)
+	}
+
+	names := make([]string, 0, len(b.topics))
+	for name := range b.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var eligible []*Consumer
+		for _, m := range g.members {
+			if m.subscribes(name) {
+				eligible = append(eligible, m)
+			}
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+		for p := range b.topics[name].partitions {
+			m := eligible[p%len(eligible)]
+			assignment[m][topicPartition{name, int32(p)}] = true
+		}
+	}
+
+	for m, tps := range assignment {
+		positions := make(map[topicPartition]int64, len(tps))
+		for tp := range tps {
+			if pos, ok := m.positions[tp]; ok {
+				positions[tp] = pos
+			} else {
+				positions[tp] = g.committed[tp]
+			}
+		}
+		m.positions = positions
+	}
+}
+
+// commit records the next offset to consume for a partition, never moving it backwards
+func (b *Broker) commit(groupID string, offsets map[topicPartition]int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[groupID]
+	if !ok {
+		return
+	}
+	for tp, off := range offsets {
+		if off > g.committed[tp] {
+			g.committed[tp] = off
+		}
+	}
+}
+
+func cloneRecord(r *kafka.Record) *kafka.Record {
+	c := *r
+	c.Key = cloneBytes(r.Key)
+	c.Value = cloneBytes(r.Value)
+	c.Headers = cloneHeaders(r.Headers)
+	return &c
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+func cloneHeaders(h map[string]string) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,288 @@
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+)
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestProducer_KeyedRecordsShareAPartition(t *testing.T) {
+	ctx := testContext(t)
+	b := NewBroker()
+	p := b.NewProducer(nil)
+
+	for i := 0; i < 10; i++ {
+		if err := p.Produce(ctx, &kafka.Message{Topic: "orders", Key: []byte("user-1"), Value: []byte(fmt.Sprint(i))}); err != nil {
+			t.Fatalf("Produce() error = %v", err)
+		}
+	}
+
+	records := b.Records("orders")
+	if len(records) != 10 {
+		t.Fatalf("got %d records, want 10", len(records))
+	}
+	for i, r := range records {
+		if r.Partition != records[0].Partition {
+			t.Fatalf("record %d on partition %d, want %d", i, r.Partition, records[0].Partition)
+		}
+		if r.Offset != int64(i) {
+			t.Errorf("record %d offset = %d, want %d", i, r.Offset, i)
+		}
+	}
+}
+
+func TestProducer_ProduceJSONSetsContentType(t *testing.T) {
+	ctx := testContext(t)
+	b := NewBroker()
+	p := b.NewProducer(nil)
+
+	headers := map[string]string{"saga_id": "s-1"}
+	if err := p.ProduceJSON(ctx, "events", "k", map[string]string{"a": "b"}, headers); err != nil {
+		t.Fatalf("ProduceJSON() error = %v", err)
+	}
+	if _, ok := headers[kafka.HeaderContentType]; ok {
+		t.Error("ProduceJSON() modified the caller's headers")
+	}
+
+	r := b.Records("events")[0]
+	if r.Headers["saga_id"] != "s-1" || r.Headers[kafka.HeaderContentType] != kafka.ContentTypeJSON {
+		t.Errorf("headers = %v", r.Headers)
+	}
+	var got map[string]string
+	if err := r.Decode(&got); err != nil || got["a"] != "b" {
+		t.Errorf("Decode() = %v, %v", got, err)
+	}
+}
+
+func TestProducer_SetError(t *testing.T) {
+	ctx := testContext(t)
+	b := NewBroker()
+	p := b.NewProducer(nil)
+
+	boom := errors.New("broker down")
+	p.SetError(boom)
+	if err := p.Produce(ctx, &kafka.Message{Topic: "t"}); !errors.Is(err, boom) {
+		t.Fatalf("Produce() error = %v, want %v", err, boom)
+	}
+	p.SetError(nil)
+	if err := p.Produce(ctx, &kafka.Message{Topic: "t"}); err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	if n := len(b.Records("t")); n != 1 {
+		t.Errorf("got %d records, want 1", n)
+	}
+}
+
+func TestConsumer_PollBlocksUntilProduce(t *testing.T) {
+	ctx := testContext(t)
+	b := NewBroker()
+	c := b.NewConsumer("g", "events")
+	defer c.Close()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = b.NewProducer(nil).Produce(ctx, &kafka.Message{Topic: "events", Value: []byte("x")})
+	}()
+
+	records, err := c.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(records) != 1 || string(records[0].Value) != "x" {
+		t.Errorf("Poll() = %v", records)
+	}
+}
+
+func TestConsumer_PollReturnsOnCancelAndClose(t *testing.T) {
+	b := NewBroker()
+	c := b.NewConsumer("g", "events")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Poll(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Poll() error = %v, want deadline exceeded", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		c.Close()
+	}()
+	if _, err := c.Poll(context.Background()); err == nil {
+		t.Error("Poll() on closed consumer returned nil error")
+	}
+}
+
+func TestConsumer_ResumesFromCommittedOffset(t *testing.T) {
+	ctx := testContext(t)
+	b := NewBroker()
+	b.CreateTopic("events", 1)
+	p := b.NewProducer(nil)
+	for i := 0; i < 3; i++ {
+		_ = p.Produce(ctx, &kafka.Message{Topic: "events", Value: []byte(fmt.Sprint(i))})
+	}
+
+	c := b.NewConsumer("g", "events")
+	records, err := c.Poll(ctx)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("Poll() = %d records, %v", len(records), err)
+	}
+	// Commit only the first two records
+	if err := c.CommitRecords(ctx, records[:2]); err != nil {
+		t.Fatalf("CommitRecords() error = %v", err)
+	}
+	c.Close()
+
+	if got := b.Committed("g", "events", 0); got != 2 {
+		t.Errorf("Committed() = %d, want 2", got)
+	}
+	if got := b.Lag("g", "events"); got != 1 {
+		t.Errorf("Lag() = %d, want 1", got)
+	}
+
+	c2 := b.NewConsumer("g", "events")
+	defer c2.Close()
+	records, err = c2.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Offset != 2 {
+		t.Errorf("redelivered %v, want offset 2 only", records)
+	}
+
+	// Another group starts from the beginning
+	other := b.NewConsumer("other", "events")
+	defer other.Close()
+	records, _ = other.Poll(ctx)
+	if len(records) != 3 {
+		t.Errorf("new group got %d records, want 3", len(records))
+	}
+}
+
+func TestConsumer_GroupSplitsAndRebalancesPartitions(t *testing.T) {
+	b := NewBroker()
+	b.CreateTopic("events", 4)
+
+	c1 := b.NewConsumer("g", "events")
+	if got := len(c1.Assigned()["events"]); got != 4 {
+		t.Fatalf("single member owns %d partitions, want 4", got)
+	}
+
+	c2 := b.NewConsumer("g", "events")
+	a1, a2 := c1.Assigned()["events"], c2.Assigned()["events"]
+	if len(a1) != 2 || len(a2) != 2 {
+		t.Fatalf("assignments = %v / %v, want 2 each", a1, a2)
+	}
+	for _, p := range a1 {
+		for _, q := range a2 {
+			if p == q {
+				t.Fatalf("partition %d assigned to both members", p)
+			}
+		}
+	}
+
+	c1.Close()
+	if got := len(c2.Assigned()["events"]); got != 4 {
+		t.Errorf("remaining member owns %d partitions, want 4", got)
+	}
+	c2.Close()
+}
+
+func TestRunner_CommitsAfterHandlerAndRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(testContext(t))
+	defer cancel()
+
+	b := NewBroker()
+	b.CreateTopic("events", 1)
+	p := b.NewProducer(nil)
+	for i := 0; i < 3; i++ {
+		_ = p.Produce(ctx, &kafka.Message{Topic: "events", Value: []byte(fmt.Sprint(i))})
+	}
+
+	var mu sync.Mutex
+	var handled []string
+	failures := 2
+	r := b.NewRunner(RunnerConfig{
+		GroupID: "g",
+		Topics:  []string{"events"},
+		ErrorHandler: func(ctx context.Context, record *kafka.Record, err error) error {
+			return err // always retry
+		},
+	})
+	defer r.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(ctx, func(ctx context.Context, record *kafka.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if string(record.Value) == "1" && failures > 0 {
+				failures--
+				return errors.New("transient")
+			}
+			handled = append(handled, string(record.Value))
+			return nil
+		})
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for b.Committed("g", "events", 0) != 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("committed offset = %d, want 3", b.Committed("g", "events", 0))
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(handled) != "[0 1 2]" {
+		t.Errorf("handled = %v, want [0 1 2]", handled)
+	}
+}
+
+func TestBroker_WaitFor(t *testing.T) {
+	ctx := testContext(t)
+	b := NewBroker()
+	p := b.NewProducer(nil)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			_ = p.Produce(ctx, &kafka.Message{
+				Topic:   "events",
+				Value:   []byte(fmt.Sprint(i)),
+				Headers: map[string]string{"n": fmt.Sprint(i)},
+			})
+		}
+	}()
+
+	r, err := b.WaitFor(ctx, "events", func(r *kafka.Record) bool { return r.Headers["n"] == "2" })
+	if err != nil {
+		t.Fatalf("WaitFor() error = %v", err)
+	}
+	if string(r.Value) != "2" {
+		t.Errorf("WaitFor() value = %s, want 2", r.Value)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := b.WaitFor(short, "events", func(r *kafka.Record) bool { return false }); err == nil {
+		t.Error("WaitFor() without a match returned nil error")
+	}
+}
@@ -0,0 +1,177 @@
+package kafkatest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+)
+
+// Consumer is a consumer group member of a Broker and implements
+// kafka.MessageConsumer. Like a franz-go consumer it starts from the earliest
+// offset when its group has nothing committed.
+type Consumer struct {
+	broker  *Broker
+	groupID string
+	topics  []string
+
+	// positions is the next offset to poll per assigned partition; guarded by broker.mu
+	positions map[topicPartition]int64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewConsumer joins groupID and subscribes to topics, creating missing topics.
+// Joining rebalances the group.
+func (b *Broker) NewConsumer(groupID string, topics ...string) *Consumer {
+	c := &Consumer{
+		broker:  b,
+		groupID: groupID,
+		topics:  topics,
+		done:    make(chan struct{}),
+	}
+	b.join(c)
+	return c
+}
+
+func (c *Consumer) subscribes(topic string) bool {
+	for _, t := range c.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Assigned returns the partitions currently assigned to the consumer
+func (c *Consumer) Assigned() map[string][]int32 {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	out := make(map[string][]int32)
+	for tp := range c.positions {
+		out[tp.topic] = append(out[tp.topic], tp.partition)
+	}
+	for _, ps := range out {
+		sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+	}
+	return out
+}
+
+// Poll returns the next records of the assigned partitions, blocking until
+// records arrive, ctx is cancelled or the consumer is closed
+func (c *Consumer) Poll(ctx context.Context) ([]*kafka.Record, error) {
+	for {
+		select {
+		case <-c.done:
+			return nil, fmt.Errorf("consumer is closed")
+		default:
+		}
+
+		c.broker.mu.Lock()
+		records := c.fetchLocked()
+		wait := c.broker.notify
+		c.broker.mu.Unlock()
+
+		if len(records) > 0 {
+			return records, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.done:
+			return nil, fmt.Errorf("consumer is closed")
+		case <-wait:
+		}
+	}
+}
+
+// fetchLocked collects records past the current positions and advances them
+func (c *Consumer) fetchLocked() []*kafka.Record {
+	tps := make([]topicPartition, 0, len(c.positions))
+	for tp := range c.positions {
+		tps = append(tps, tp)
+	}
+	sort.Slice(tps, func(i, j int) bool {
+		if tps[i].topic != tps[j].topic {
+			return tps[i].topic < tps[j].topic
+		}
+		return tps[i].partition < tps[j].partition
+	})
+
+	var records []*kafka.Record
+	for _, tp := range tps {
+		log := c.broker.topics[tp.topic].partitions[tp.partition]
+		pos := c.positions[tp]
+		for ; pos < int64(len(log)) && len(records) < maxPollRecords; pos++ {
+			records = append(records, cloneRecord(log[pos]))
+		}
+		c.positions[tp] = pos
+		if len(records) >= maxPollRecords {
+			break
+		}
+	}
+	return records
+}
+
+// CommitRecords commits the offsets following the given records
+func (c *Consumer) CommitRecords(ctx context.Context, records []*kafka.Record) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	offsets := make(map[topicPartition]int64, len(records))
+	for _, r := range records {
+		tp := topicPartition{r.Topic, r.Partition}
+		if r.Offset+1 > offsets[tp] {
+			offsets[tp] = r.Offset + 1
+		}
+	}
+	c.broker.commit(c.groupID, offsets)
+	return nil
+}
+
+// CommitOffsets commits the current poll positions of all assigned partitions
+func (c *Consumer) CommitOffsets(ctx context.Context) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	c.broker.mu.Lock()
+	offsets := make(map[topicPartition]int64, len(c.positions))
+	for tp, pos := range c.positions {
+		offsets[tp] = pos
+	}
+	c.broker.mu.Unlock()
+
+	c.broker.commit(c.groupID, offsets)
+	return nil
+}
+
+// Ping fails only if the consumer is closed
+func (c *Consumer) Ping(ctx context.Context) error {
+	return c.check()
+}
+
+// Close leaves the group, which rebalances its partitions to the remaining members
+func (c *Consumer) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.broker.leave(c)
+	})
+}
+
+func (c *Consumer) check() error {
+	select {
+	case <-c.done:
+		return fmt.Errorf("consumer is closed")
+	default:
+		return nil
+	}
+}
+
+var _ kafka.MessageConsumer = (*Consumer)(nil)
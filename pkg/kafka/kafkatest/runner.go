@@ -0,0 +1,99 @@
+package kafkatest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/kafka"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+)
+
+// RunnerConfig contains configuration for an in-memory runner
+type RunnerConfig struct {
+	GroupID string
+	Topics  []string
+	// ErrorHandler has the same semantics as kafka.RunnerConfig.ErrorHandler;
+	// nil skips failed records
+	ErrorHandler kafka.ErrorHandler
+	// RetryBackoff is the delay before retrying a rejected record (default: 10ms)
+	RetryBackoff time.Duration
+}
+
+// Runner processes records one at a time and commits each after its handler
+// returns. It implements kafka.RecordRunner with the same error policy as
+// kafka.Runner but without lanes, so records are handled in poll order.
+type Runner struct {
+	consumer *Consumer
+	cfg      RunnerConfig
+}
+
+// NewRunner joins cfg.GroupID and subscribes to cfg.Topics
+func (b *Broker) NewRunner(cfg RunnerConfig) *Runner {
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 10 * time.Millisecond
+	}
+	return &Runner{
+		consumer: b.NewConsumer(cfg.GroupID, cfg.Topics...),
+		cfg:      cfg,
+	}
+}
+
+// Run dispatches records to handler until ctx is cancelled or the runner is closed
+func (r *Runner) Run(ctx context.Context, handler kafka.RecordHandler) error {
+	for ctx.Err() == nil {
+		records, err := r.consumer.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return err
+		}
+
+		for _, rec := range records {
+			if !r.process(ctx, handler, rec) {
+				return ctx.Err()
+			}
+			if err := r.consumer.CommitRecords(ctx, []*kafka.Record{rec}); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// process runs the handler for one record and reports whether it finished;
+// a record still failing at shutdown is left uncommitted
+func (r *Runner) process(ctx context.Context, handler kafka.RecordHandler, rec *kafka.Record) bool {
+	err := handler(ctx, rec)
+	for err != nil {
+		if r.cfg.ErrorHandler == nil {
+			logger.Get().Error(fmt.Sprintf("Kafka handler failed, skipping record %s/%d@%d: %v",
+				rec.Topic, rec.Partition, rec.Offset, err))
+			return true
+		}
+		if herr := r.cfg.ErrorHandler(ctx, rec, err); herr == nil {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(r.cfg.RetryBackoff):
+		}
+		err = handler(ctx, rec)
+	}
+	return true
+}
+
+// Consumer returns the group member backing the runner
+func (r *Runner) Consumer() *Consumer {
+	return r.consumer
+}
+
+// Close leaves the consumer group
+func (r *Runner) Close() {
+	r.consumer.Close()
+}
+
+var _ kafka.RecordRunner = (*Runner)(nil)
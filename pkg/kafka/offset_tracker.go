@@ -0,0 +1,157 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// topicPartition identifies a single partition of a topic
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// offsetTracker tracks in-flight offsets of one partition so that only the
+// contiguous prefix of successfully processed records is ever committed.
+// Records may complete out of order (e.g. with per-key ordering); the commit
+// point only advances once every earlier record has completed as well.
+type offsetTracker struct {
+	mu sync.Mutex
+
+	// pending holds dispatched offsets in dispatch order
+	pending []int64
+	// done holds completed offsets that are not yet part of the contiguous prefix
+	done map[int64]int32
+
+	// next is the offset after the last contiguously completed record (-1 = none yet)
+	next int64
+	// epoch is the leader epoch of the last contiguously completed record
+	epoch int32
+	// committed is the last next value that was committed (-1 = none yet)
+	committed int64
+
+	closed bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		done:      make(map[int64]int32),
+		next:      -1,
+		committed: -1,
+	}
+}
+
+// add registers a dispatched offset; offsets must be added in increasing order
+func (t *offsetTracker) add(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, offset)
+}
+
+// remove drops an offset that was added but could not be dispatched
+func (t *offsetTracker) remove(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.pending) - 1; i >= 0; i-- {
+		if t.pending[i] == offset {
+			t.pending = append(t.pending[:i], t.pending[i+1:]...)
+			break
+		}
+	}
+	t.advance()
+}
+
+// complete marks an offset as processed and advances the commit point if possible
+func (t *offsetTracker) complete(offset int64, epoch int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.done[offset] = epoch
+	t.advance()
+}
+
+// advance moves the contiguous prefix forward; caller must hold mu
+func (t *offsetTracker) advance() {
+	for len(t.pending) > 0 {
+		head := t.pending[0]
+		epoch, ok := t.done[head]
+		if !ok {
+			return
+		}
+		delete(t.done, head)
+		t.pending = t.pending[1:]
+		t.next = head + 1
+		t.epoch = epoch
+	}
+}
+
+// committable returns the offset to commit if it moved since the last commit
+func (t *offsetTracker) committable() (offset int64, epoch int32, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.next < 0 || t.next == t.committed {
+		return 0, 0, false
+	}
+	return t.next, t.epoch, true
+}
+
+// markCommitted records that offset has been committed
+func (t *offsetTracker) markCommitted(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if offset > t.committed {
+		t.committed = offset
+	}
+}
+
+// position returns the next offset the consumer still has to finish (-1 = unknown)
+func (t *offsetTracker) position() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) > 0 {
+		return t.pending[0]
+	}
+	return t.next
+}
+
+// inFlight returns the number of dispatched records not yet in the committed prefix
+func (t *offsetTracker) inFlight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// close stops tracking; later completions are ignored and queued records are skipped
+func (t *offsetTracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+}
+
+// isClosed reports whether the partition was revoked or lost
+func (t *offsetTracker) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+// waitDrained blocks until every dispatched record completed, the tracker is
+// closed, or the context is done
+func (t *offsetTracker) waitDrained(ctx context.Context) error {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if t.inFlight() == 0 || t.isClosed() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOffsetTracker_CommitsContiguousPrefix(t *testing.T) {
+	tracker := newOffsetTracker()
+	for offset := int64(10); offset < 15; offset++ {
+		tracker.add(offset)
+	}
+
+	if _, _, ok := tracker.committable(); ok {
+		t.Fatal("nothing should be committable before any record completes")
+	}
+
+	// Completing later offsets must not move the commit point past a gap
+	tracker.complete(12, 3)
+	tracker.complete(11, 3)
+	if _, _, ok := tracker.committable(); ok {
+		t.Fatal("offset 10 is still in flight, nothing should be committable")
+	}
+	if got := tracker.position(); got != 10 {
+		t.Errorf("position() = %d, want 10", got)
+	}
+
+	tracker.complete(10, 3)
+	offset, epoch, ok := tracker.committable()
+	if !ok || offset != 13 || epoch != 3 {
+		t.Errorf("committable() = %d, %d, %v, want 13, 3, true", offset, epoch, ok)
+	}
+	if got := tracker.inFlight(); got != 2 {
+		t.Errorf("inFlight() = %d, want 2", got)
+	}
+
+	tracker.markCommitted(13)
+	if _, _, ok := tracker.committable(); ok {
+		t.Error("committable() should be false after markCommitted")
+	}
+}
+
+func TestOffsetTracker_RemoveUndispatched(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.add(0)
+	tracker.add(1)
+	tracker.complete(0, 0)
+	tracker.remove(1)
+
+	offset, _, ok := tracker.committable()
+	if !ok || offset != 1 {
+		t.Errorf("committable() = %d, %v, want 1, true", offset, ok)
+	}
+	if tracker.inFlight() != 0 {
+		t.Errorf("inFlight() = %d, want 0", tracker.inFlight())
+	}
+}
+
+func TestOffsetTracker_CloseIgnoresCompletions(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.add(0)
+	tracker.close()
+	tracker.complete(0, 0)
+
+	if _, _, ok := tracker.committable(); ok {
+		t.Error("closed tracker should not become committable")
+	}
+	if err := tracker.waitDrained(context.Background()); err != nil {
+		t.Errorf("waitDrained() on closed tracker = %v, want nil", err)
+	}
+}
+
+func TestOffsetTracker_WaitDrained(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.add(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tracker.waitDrained(ctx); err == nil {
+		t.Fatal("waitDrained() should time out while a record is in flight")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tracker.complete(0, 0)
+	}()
+	if err := tracker.waitDrained(context.Background()); err != nil {
+		t.Errorf("waitDrained() = %v, want nil", err)
+	}
+}
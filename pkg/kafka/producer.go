@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -11,11 +12,17 @@ import (
 	"github.com/twmb/franz-go/pkg/kgo"
 )
 
+// ErrNotTransactional is returned by transaction methods on a producer
+// created without a TransactionalID
+var ErrNotTransactional = errors.New("producer is not transactional")
+
 // Producer represents a Kafka producer
 type Producer struct {
-	client *kgo.Client
-	mu     sync.RWMutex
-	closed bool
+	client        *kgo.Client
+	codecs        *CodecRegistry
+	transactional bool
+	mu            sync.RWMutex
+	closed        bool
 }
 
 // ProducerConfig contains configuration for the Kafka producer
@@ -26,6 +33,19 @@ type ProducerConfig struct {
 	RetryInterval time.Duration
 	BatchSize     int
 	LingerMs      int
+
+	// TransactionalID enables transactions. A transactional producer can only
+	// produce between BeginTransaction and CommitTransaction/AbortTransaction.
+	// The ID must be stable across restarts of the same logical producer so
+	// Kafka can fence zombie instances.
+	TransactionalID string
+	// TransactionTimeout is how long the coordinator waits before aborting an
+	// open transaction (default: franz-go default of 40s)
+	TransactionTimeout time.Duration
+
+	// Codecs selects the payload encoding per topic for ProduceValue
+	// (default: DefaultCodecs, which encodes JSON)
+	Codecs *CodecRegistry
 }
 
 // Message represents a Kafka message
@@ -65,6 +85,13 @@ func NewProducer(ctx context.Context, cfg *ProducerConfig) (*Producer, error) {
 		opts = append(opts, kgo.ProducerLinger(time.Duration(cfg.LingerMs)*time.Millisecond))
 	}
 
+	if cfg.TransactionalID != "" {
+		opts = append(opts, kgo.TransactionalID(cfg.TransactionalID))
+		if cfg.TransactionTimeout > 0 {
+			opts = append(opts, kgo.TransactionTimeout(cfg.TransactionTimeout))
+		}
+	}
+
 	var client *kgo.Client
 	var err error
 
@@ -103,8 +130,15 @@ func NewProducer(ctx context.Context, cfg *ProducerConfig) (*Producer, error) {
 		return nil, fmt.Errorf("failed to create kafka producer after %d retries: %w", maxRetries, err)
 	}
 
+	codecs := cfg.Codecs
+	if codecs == nil {
+		codecs = DefaultCodecs
+	}
+
 	return &Producer{
-		client: client,
+		client:        client,
+		codecs:        codecs,
+		transactional: cfg.TransactionalID != "",
 	}, nil
 }
 
@@ -127,6 +161,23 @@ func (p *Producer) Produce(ctx context.Context, msg *Message) error {
 	}
 	msg.Headers = telemetry.InjectKafkaHeaders(ctx, msg.Headers)
 
+	result := p.client.ProduceSync(ctx, newKgoRecord(msg))
+	if err := result.FirstErr(); err != nil {
+		telemetry.SetSpanError(ctx, err)
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+
+	// Add partition and offset info to span
+	if len(result) > 0 {
+		r := result[0].Record
+		telemetry.AddKafkaProducerAttributes(span, r.Partition, r.Offset)
+	}
+
+	return nil
+}
+
+// newKgoRecord converts a Message into a franz-go record
+func newKgoRecord(msg *Message) *kgo.Record {
 	record := &kgo.Record{
 		Topic: msg.Topic,
 		Key:   msg.Key,
@@ -144,19 +195,7 @@ func (p *Producer) Produce(ctx context.Context, msg *Message) error {
 		})
 	}
 
-	result := p.client.ProduceSync(ctx, record)
-	if err := result.FirstErr(); err != nil {
-		telemetry.SetSpanError(ctx, err)
-		return fmt.Errorf("failed to produce message: %w", err)
-	}
-
-	// Add partition and offset info to span
-	if len(result) > 0 {
-		r := result[0].Record
-		telemetry.AddKafkaProducerAttributes(span, r.Partition, r.Offset)
-	}
-
-	return nil
+	return record
 }
 
 // ProduceJSON serializes data to JSON and sends it to Kafka
@@ -170,13 +209,47 @@ func (p *Producer) ProduceJSON(ctx context.Context, topic string, key string, da
 		Topic:     topic,
 		Key:       []byte(key),
 		Value:     value,
-		Headers:   headers,
+		Headers:   withContentType(headers, ContentTypeJSON),
+		Timestamp: time.Now(),
+	}
+
+	return p.Produce(ctx, msg)
+}
+
+// ProduceValue encodes data with the codec configured for the topic and sends
+// it to Kafka with a content type header so consumers can decode it with Record.Decode
+func (p *Producer) ProduceValue(ctx context.Context, topic string, key string, data interface{}, headers map[string]string) error {
+	codecs := p.codecs
+	if codecs == nil {
+		codecs = DefaultCodecs
+	}
+
+	value, contentType, err := codecs.Encode(topic, data)
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{
+		Topic:     topic,
+		Key:       []byte(key),
+		Value:     value,
+		Headers:   withContentType(headers, contentType),
 		Timestamp: time.Now(),
 	}
 
 	return p.Produce(ctx, msg)
 }
 
+// withContentType returns a copy of headers with the content type header set
+func withContentType(headers map[string]string, contentType string) map[string]string {
+	out := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		out[k] = v
+	}
+	out[HeaderContentType] = contentType
+	return out
+}
+
 // ProduceAsync sends a message asynchronously
 func (p *Producer) ProduceAsync(ctx context.Context, msg *Message, callback func(error)) {
 	p.mu.RLock()
@@ -189,24 +262,7 @@ func (p *Producer) ProduceAsync(ctx context.Context, msg *Message, callback func
 	}
 	p.mu.RUnlock()
 
-	record := &kgo.Record{
-		Topic: msg.Topic,
-		Key:   msg.Key,
-		Value: msg.Value,
-	}
-
-	if !msg.Timestamp.IsZero() {
-		record.Timestamp = msg.Timestamp
-	}
-
-	for key, value := range msg.Headers {
-		record.Headers = append(record.Headers, kgo.RecordHeader{
-			Key:   key,
-			Value: []byte(value),
-		})
-	}
-
-	p.client.Produce(ctx, record, func(r *kgo.Record, err error) {
+	p.client.Produce(ctx, newKgoRecord(msg), func(r *kgo.Record, err error) {
 		if callback != nil {
 			callback(err)
 		}
@@ -0,0 +1,689 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/logger"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RecordHandler processes a single record. Returning an error hands the record
+// to RunnerConfig.ErrorHandler.
+type RecordHandler func(ctx context.Context, record *Record) error
+
+// ErrorHandler decides what happens to a record whose handler failed.
+// Returning nil marks the record as handled (e.g. after sending it to a DLQ) so
+// its offset can be committed; returning an error retries the handler after
+// RunnerConfig.RetryBackoff, blocking later records of the same ordering lane.
+type ErrorHandler func(ctx context.Context, record *Record, err error) error
+
+// PartitionsHandler is notified about partition assignment changes
+type PartitionsHandler func(ctx context.Context, partitions map[string][]int32)
+
+// OrderingMode controls which records are guaranteed to be processed in order
+type OrderingMode int
+
+const (
+	// OrderByPartition processes records of a partition strictly in offset order
+	OrderByPartition OrderingMode = iota
+	// OrderByKey processes records with the same key in order, allowing records
+	// with different keys of the same partition to run concurrently
+	OrderByKey
+)
+
+// RunnerConfig contains configuration for the consumer runner
+type RunnerConfig struct {
+	Brokers          []string
+	GroupID          string
+	Topics           []string
+	ClientID         string
+	MaxRetries       int
+	RetryInterval    time.Duration
+	SessionTimeout   time.Duration
+	RebalanceTimeout time.Duration
+	// ReadCommitted skips records from aborted transactions
+	ReadCommitted bool
+
+	// Ordering selects per-partition (default) or per-key ordering
+	Ordering OrderingMode
+	// Concurrency is the number of processing lanes (default: 8)
+	Concurrency int
+	// LaneBuffer is the queue depth per lane before polling blocks (default: 64)
+	LaneBuffer int
+	// MaxPollRecords caps records returned per poll (default: 500)
+	MaxPollRecords int
+	// CommitInterval is how often completed offsets are committed (default: 1s)
+	CommitInterval time.Duration
+	// DrainTimeout bounds how long revoke and shutdown wait for in-flight records (default: 30s)
+	DrainTimeout time.Duration
+	// LagInterval is how often consumer lag is exported (default: 10s)
+	LagInterval time.Duration
+	// RetryBackoff is the delay before retrying a record the ErrorHandler rejected (default: 1s)
+	RetryBackoff time.Duration
+
+	// ErrorHandler handles failed records; nil logs the error and skips the record
+	ErrorHandler ErrorHandler
+	// OnAssigned is called after partitions are assigned
+	OnAssigned PartitionsHandler
+	// OnRevoked is called after revoked partitions are drained and committed,
+	// and after lost partitions are dropped (lost partitions are not committed)
+	OnRevoked PartitionsHandler
+}
+
+// applyDefaults fills zero values with defaults
+func (c *RunnerConfig) applyDefaults() {
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.LaneBuffer <= 0 {
+		c.LaneBuffer = 64
+	}
+	if c.MaxPollRecords <= 0 {
+		c.MaxPollRecords = 500
+	}
+	if c.CommitInterval <= 0 {
+		c.CommitInterval = time.Second
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = 30 * time.Second
+	}
+	if c.LagInterval <= 0 {
+		c.LagInterval = 10 * time.Second
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = time.Second
+	}
+}
+
+// Runner consumes a consumer group and dispatches records to a handler with
+// ordered lanes, bounded concurrency and commit-after-contiguous-success.
+//
+// Records are routed to one of Concurrency lanes by partition (or key), so
+// ordering holds within a partition (or key) while different partitions run in
+// parallel. Offsets are committed only up to the first record that has not
+// finished. On revoke the runner drains in-flight records of the revoked
+// partitions and commits them before the partitions move to another member.
+type Runner struct {
+	client *kgo.Client
+	cfg    *RunnerConfig
+
+	mu       sync.Mutex
+	trackers map[topicPartition]*offsetTracker
+	hwm      map[topicPartition]int64
+
+	dispatcher *dispatcher
+	lagGauge   *telemetry.Gauge
+	processed  *telemetry.Counter
+
+	closeOnce sync.Once
+}
+
+// NewRunner creates a new consumer runner and joins the consumer group
+func NewRunner(ctx context.Context, cfg *RunnerConfig) (*Runner, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("runner config is required")
+	}
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("at least one broker is required")
+	}
+	if cfg.GroupID == "" {
+		return nil, fmt.Errorf("consumer group ID is required")
+	}
+	if len(cfg.Topics) == 0 {
+		return nil, fmt.Errorf("at least one topic is required")
+	}
+	cfg.applyDefaults()
+
+	r := newRunner(cfg)
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ConsumerGroup(cfg.GroupID),
+		kgo.ConsumeTopics(cfg.Topics...),
+		kgo.DisableAutoCommit(),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.OnPartitionsAssigned(r.onAssigned),
+		kgo.OnPartitionsRevoked(r.onRevoked),
+		kgo.OnPartitionsLost(r.onLost),
+	}
+	if cfg.ClientID != "" {
+		opts = append(opts, kgo.ClientID(cfg.ClientID))
+	}
+	if cfg.SessionTimeout > 0 {
+		opts = append(opts, kgo.SessionTimeout(cfg.SessionTimeout))
+	}
+	if cfg.RebalanceTimeout > 0 {
+		opts = append(opts, kgo.RebalanceTimeout(cfg.RebalanceTimeout))
+	}
+	if cfg.ReadCommitted {
+		opts = append(opts, kgo.FetchIsolationLevel(kgo.ReadCommitted()))
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	retryInterval := cfg.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = 2 * time.Second
+	}
+
+	var client *kgo.Client
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		client, err = kgo.NewClient(opts...)
+		if err == nil {
+			if pingErr := client.Ping(ctx); pingErr == nil {
+				break
+			} else {
+				client.Close()
+				err = pingErr
+			}
+		}
+
+		if i < maxRetries-1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka runner after %d retries: %w", maxRetries, err)
+	}
+
+	r.client = client
+	return r, nil
+}
+
+// newRunner creates a runner without a client (used by NewRunner and tests)
+func newRunner(cfg *RunnerConfig) *Runner {
+	r := &Runner{
+		cfg:      cfg,
+		trackers: make(map[topicPartition]*offsetTracker),
+		hwm:      make(map[topicPartition]int64),
+	}
+
+	// Metrics are best effort: without a meter provider they are no-ops
+	if gauge, err := telemetry.NewGauge(telemetry.MetricOpts{
+		Name:        "kafka_consumer_lag",
+		Description: "Records between the partition high watermark and the runner's processing position",
+		Unit:        "{record}",
+	}); err == nil {
+		r.lagGauge = gauge
+	}
+	if counter, err := telemetry.NewCounter(telemetry.MetricOpts{
+		Name:        "kafka_consumer_records_total",
+		Description: "Records processed by the consumer runner",
+		Unit:        "{record}",
+	}); err == nil {
+		r.processed = counter
+	}
+
+	return r
+}
+
+// Run polls records and dispatches them to handler until ctx is cancelled.
+// On shutdown it waits up to DrainTimeout for in-flight records and commits them.
+func (r *Runner) Run(ctx context.Context, handler RecordHandler) error {
+	// Handlers keep running on their own context during drain so a shutdown
+	// signal does not abort records that are already in flight
+	procCtx, procCancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer procCancel()
+
+	r.startDispatcher(procCtx, handler)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		r.commitLoop(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		r.lagLoop(ctx)
+	}()
+
+	log := logger.Get()
+	for ctx.Err() == nil {
+		fetches := r.client.PollRecords(ctx, r.cfg.MaxPollRecords)
+		if fetches.IsClientClosed() {
+			break
+		}
+
+		fetches.EachError(func(topic string, partition int32, err error) {
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				log.Error(fmt.Sprintf("Kafka fetch error on topic %s partition %d: %v", topic, partition, err))
+			}
+		})
+
+		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+			r.setHighWatermark(p.Topic, p.Partition, p.HighWatermark)
+			for _, rec := range p.Records {
+				if ctx.Err() != nil {
+					return
+				}
+				r.dispatchRecord(ctx, rec)
+			}
+		})
+
+		r.client.AllowRebalance()
+	}
+	r.client.AllowRebalance()
+
+	// Drain in-flight records, then commit what completed
+	r.drain(procCancel)
+	wg.Wait()
+
+	commitCtx, commitCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	r.commit(commitCtx, r.snapshotTrackers())
+	commitCancel()
+
+	for _, t := range r.allTrackers() {
+		t.close()
+	}
+
+	return ctx.Err()
+}
+
+// startDispatcher starts the lanes that run handler on procCtx
+func (r *Runner) startDispatcher(procCtx context.Context, handler RecordHandler) {
+	r.dispatcher = newDispatcher(r.cfg.Concurrency, r.cfg.LaneBuffer, r.cfg.Ordering, func(tr *trackedRecord) {
+		r.process(procCtx, handler, tr)
+	})
+	r.dispatcher.start()
+}
+
+// drain waits up to DrainTimeout for in-flight records, then cancels the
+// handlers' context and stops the lanes. Records still queued or interrupted
+// by the cancellation are left uncommitted, so they are redelivered.
+func (r *Runner) drain(procCancel context.CancelFunc) {
+	drainCtx, cancel := context.WithTimeout(context.Background(), r.cfg.DrainTimeout)
+	for _, t := range r.allTrackers() {
+		if err := t.waitDrained(drainCtx); err != nil {
+			logger.Get().Warn(fmt.Sprintf("Kafka runner drain timed out with %d records in flight", t.inFlight()))
+			break
+		}
+	}
+	cancel()
+
+	procCancel()
+	r.dispatcher.stop()
+}
+
+// dispatchRecord registers a record with its partition tracker and queues it on its lane
+func (r *Runner) dispatchRecord(ctx context.Context, rec *kgo.Record) {
+	tracker := r.tracker(rec.Topic, rec.Partition)
+	tracker.add(rec.Offset)
+
+	tr := &trackedRecord{
+		record:  newRecord(rec),
+		epoch:   rec.LeaderEpoch,
+		tracker: tracker,
+	}
+	if err := r.dispatcher.dispatch(ctx, tr); err != nil {
+		tracker.remove(rec.Offset)
+	}
+}
+
+// process runs the handler for one record, applying the error policy
+func (r *Runner) process(ctx context.Context, handler RecordHandler, tr *trackedRecord) {
+	// A record still queued when the drain timed out is not started
+	if tr.tracker.isClosed() || ctx.Err() != nil {
+		return
+	}
+
+	rec := tr.record
+	spanCtx, span := rec.StartProcessingSpan(ctx)
+	defer span.End()
+
+	status := "success"
+	err := handler(spanCtx, rec)
+	for err != nil {
+		if ctx.Err() != nil {
+			// Interrupted by shutdown: leave the record uncommitted so it is redelivered
+			return
+		}
+		if r.cfg.ErrorHandler == nil {
+			logger.Get().Error(fmt.Sprintf("Kafka handler failed, skipping record %s/%d@%d: %v",
+				rec.Topic, rec.Partition, rec.Offset, err))
+			status = "skipped"
+			break
+		}
+
+		herr := r.cfg.ErrorHandler(spanCtx, rec, err)
+		if herr == nil {
+			status = "failed"
+			break
+		}
+
+		telemetry.SetSpanError(spanCtx, herr)
+		select {
+		case <-ctx.Done():
+			// Shutting down: leave the record uncommitted so it is redelivered
+			return
+		case <-time.After(r.cfg.RetryBackoff):
+		}
+		if tr.tracker.isClosed() {
+			return
+		}
+		err = handler(spanCtx, rec)
+	}
+
+	if r.processed != nil {
+		r.processed.Inc(ctx,
+			telemetry.KafkaTopicAttr(rec.Topic),
+			telemetry.KafkaGroupAttr(r.cfg.GroupID),
+			attribute.String("status", status),
+		)
+	}
+	tr.tracker.complete(rec.Offset, tr.epoch)
+}
+
+// commitLoop periodically commits the contiguous completed prefix of every partition
+func (r *Runner) commitLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.CommitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.commit(ctx, r.snapshotTrackers())
+		}
+	}
+}
+
+// commit commits completed offsets for the given trackers
+func (r *Runner) commit(ctx context.Context, trackers map[topicPartition]*offsetTracker) {
+	offsets := make(map[string]map[int32]kgo.EpochOffset)
+	for tp, t := range trackers {
+		offset, epoch, ok := t.committable()
+		if !ok {
+			continue
+		}
+		if offsets[tp.topic] == nil {
+			offsets[tp.topic] = make(map[int32]kgo.EpochOffset)
+		}
+		offsets[tp.topic][tp.partition] = kgo.EpochOffset{Epoch: epoch, Offset: offset}
+	}
+	if len(offsets) == 0 {
+		return
+	}
+
+	r.client.CommitOffsetsSync(ctx, offsets, func(_ *kgo.Client, _ *kmsg.OffsetCommitRequest, resp *kmsg.OffsetCommitResponse, err error) {
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Get().Error(fmt.Sprintf("Failed to commit offsets: %v", err))
+			}
+			return
+		}
+		for _, topic := range resp.Topics {
+			for _, p := range topic.Partitions {
+				tp := topicPartition{topic: topic.Topic, partition: p.Partition}
+				if perr := kerr.ErrorForCode(p.ErrorCode); perr != nil {
+					logger.Get().Error(fmt.Sprintf("Failed to commit offset for %s/%d: %v", tp.topic, tp.partition, perr))
+					continue
+				}
+				if t, ok := trackers[tp]; ok {
+					t.markCommitted(offsets[tp.topic][tp.partition].Offset)
+				}
+			}
+		}
+	})
+}
+
+// lagLoop periodically exports consumer lag
+func (r *Runner) lagLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.LagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.lagGauge == nil {
+				continue
+			}
+			for topic, partitions := range r.Lag() {
+				for partition, lag := range partitions {
+					r.lagGauge.Record(ctx, lag,
+						telemetry.KafkaGroupAttr(r.cfg.GroupID),
+						telemetry.KafkaTopicAttr(topic),
+						telemetry.KafkaPartitionAttr(partition),
+					)
+				}
+			}
+		}
+	}
+}
+
+// Lag returns, per assigned partition, how many records sit between the
+// runner's processing position and the last known high watermark
+func (r *Runner) Lag() map[string]map[int32]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lag := make(map[string]map[int32]int64)
+	for tp, t := range r.trackers {
+		hwm, ok := r.hwm[tp]
+		if !ok {
+			continue
+		}
+		position := t.position()
+		if position < 0 {
+			continue
+		}
+		value := hwm - position
+		if value < 0 {
+			value = 0
+		}
+		if lag[tp.topic] == nil {
+			lag[tp.topic] = make(map[int32]int64)
+		}
+		lag[tp.topic][tp.partition] = value
+	}
+	return lag
+}
+
+// onAssigned is the franz-go assignment callback
+func (r *Runner) onAssigned(ctx context.Context, _ *kgo.Client, assigned map[string][]int32) {
+	logger.Get().Info(fmt.Sprintf("Kafka runner %s assigned partitions: %v", r.cfg.GroupID, assigned))
+	if r.cfg.OnAssigned != nil {
+		r.cfg.OnAssigned(ctx, assigned)
+	}
+}
+
+// onRevoked drains in-flight records of revoked partitions and commits them
+// before the partitions are handed to another group member
+func (r *Runner) onRevoked(ctx context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	trackers := r.takeTrackers(revoked)
+
+	drainCtx, cancel := context.WithTimeout(ctx, r.cfg.DrainTimeout)
+	for tp, t := range trackers {
+		if err := t.waitDrained(drainCtx); err != nil {
+			logger.Get().Warn(fmt.Sprintf("Kafka runner revoke drain timed out for %s/%d with %d records in flight",
+				tp.topic, tp.partition, t.inFlight()))
+			break
+		}
+	}
+	cancel()
+
+	r.commit(ctx, trackers)
+	for _, t := range trackers {
+		t.close()
+	}
+
+	logger.Get().Info(fmt.Sprintf("Kafka runner %s revoked partitions: %v", r.cfg.GroupID, revoked))
+	if r.cfg.OnRevoked != nil {
+		r.cfg.OnRevoked(ctx, revoked)
+	}
+}
+
+// onLost drops lost partitions without committing; their records will be redelivered
+func (r *Runner) onLost(ctx context.Context, _ *kgo.Client, lost map[string][]int32) {
+	for _, t := range r.takeTrackers(lost) {
+		t.close()
+	}
+
+	logger.Get().Warn(fmt.Sprintf("Kafka runner %s lost partitions: %v", r.cfg.GroupID, lost))
+	if r.cfg.OnRevoked != nil {
+		r.cfg.OnRevoked(ctx, lost)
+	}
+}
+
+// tracker returns the tracker for a partition, creating it on first use
+func (r *Runner) tracker(topic string, partition int32) *offsetTracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tp := topicPartition{topic: topic, partition: partition}
+	t, ok := r.trackers[tp]
+	if !ok {
+		t = newOffsetTracker()
+		r.trackers[tp] = t
+	}
+	return t
+}
+
+// takeTrackers removes and returns the trackers for the given partitions
+func (r *Runner) takeTrackers(partitions map[string][]int32) map[topicPartition]*offsetTracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := make(map[topicPartition]*offsetTracker)
+	for topic, ps := range partitions {
+		for _, p := range ps {
+			tp := topicPartition{topic: topic, partition: p}
+			if t, ok := r.trackers[tp]; ok {
+				taken[tp] = t
+				delete(r.trackers, tp)
+			}
+			delete(r.hwm, tp)
+		}
+	}
+	return taken
+}
+
+// snapshotTrackers returns a copy of the current tracker map
+func (r *Runner) snapshotTrackers() map[topicPartition]*offsetTracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[topicPartition]*offsetTracker, len(r.trackers))
+	for tp, t := range r.trackers {
+		snapshot[tp] = t
+	}
+	return snapshot
+}
+
+// allTrackers returns the current trackers as a slice
+func (r *Runner) allTrackers() []*offsetTracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackers := make([]*offsetTracker, 0, len(r.trackers))
+	for _, t := range r.trackers {
+		trackers = append(trackers, t)
+	}
+	return trackers
+}
+
+// setHighWatermark records the latest high watermark seen for a partition
+func (r *Runner) setHighWatermark(topic string, partition int32, hwm int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hwm[topicPartition{topic: topic, partition: partition}] = hwm
+}
+
+// Close leaves the consumer group and closes the client
+func (r *Runner) Close() {
+	r.closeOnce.Do(func() {
+		if r.client != nil {
+			r.client.Close()
+		}
+	})
+}
+
+// trackedRecord is a record queued on a lane together with its partition tracker
+type trackedRecord struct {
+	record  *Record
+	epoch   int32
+	tracker *offsetTracker
+}
+
+// dispatcher routes records to a fixed set of sequential lanes
+type dispatcher struct {
+	lanes    []chan *trackedRecord
+	ordering OrderingMode
+	handle   func(*trackedRecord)
+	wg       sync.WaitGroup
+}
+
+func newDispatcher(concurrency, buffer int, ordering OrderingMode, handle func(*trackedRecord)) *dispatcher {
+	d := &dispatcher{
+		lanes:    make([]chan *trackedRecord, concurrency),
+		ordering: ordering,
+		handle:   handle,
+	}
+	for i := range d.lanes {
+		d.lanes[i] = make(chan *trackedRecord, buffer)
+	}
+	return d
+}
+
+// start launches one goroutine per lane
+func (d *dispatcher) start() {
+	for _, lane := range d.lanes {
+		d.wg.Add(1)
+		go func(lane <-chan *trackedRecord) {
+			defer d.wg.Done()
+			for tr := range lane {
+				d.handle(tr)
+			}
+		}(lane)
+	}
+}
+
+// dispatch queues a record on its lane, blocking while the lane is full
+func (d *dispatcher) dispatch(ctx context.Context, tr *trackedRecord) error {
+	select {
+	case d.lanes[d.laneFor(tr.record)] <- tr:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// laneFor picks the lane for a record; records sharing a partition (or key) always share a lane
+func (d *dispatcher) laneFor(rec *Record) int {
+	h := fnv.New32a()
+	h.Write([]byte(rec.Topic))
+	var p [4]byte
+	p[0], p[1], p[2], p[3] = byte(rec.Partition>>24), byte(rec.Partition>>16), byte(rec.Partition>>8), byte(rec.Partition)
+	h.Write(p[:])
+	if d.ordering == OrderByKey {
+		h.Write(rec.Key)
+	}
+	return int(h.Sum32() % uint32(len(d.lanes)))
+}
+
+// stop closes all lanes and waits for queued records to finish
+func (d *dispatcher) stop() {
+	for _, lane := range d.lanes {
+		close(lane)
+	}
+	d.wg.Wait()
+}
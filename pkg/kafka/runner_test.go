@@ -0,0 +1,285 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestNewRunner_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *RunnerConfig
+	}{
+		{name: "nil config", config: nil},
+		{name: "empty brokers", config: &RunnerConfig{GroupID: "g", Topics: []string{"t"}}},
+		{name: "empty group ID", config: &RunnerConfig{Brokers: []string{"localhost:9092"}, Topics: []string{"t"}}},
+		{name: "empty topics", config: &RunnerConfig{Brokers: []string{"localhost:9092"}, GroupID: "g"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRunner(context.Background(), tt.config); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestRunnerConfig_Defaults(t *testing.T) {
+	cfg := &RunnerConfig{}
+	cfg.applyDefaults()
+
+	if cfg.Concurrency != 8 || cfg.LaneBuffer != 64 || cfg.MaxPollRecords != 500 {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if cfg.CommitInterval != time.Second || cfg.DrainTimeout != 30*time.Second {
+		t.Errorf("unexpected interval defaults: %+v", cfg)
+	}
+}
+
+func TestDispatcher_PreservesPartitionOrder(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int32][]int64)
+
+	d := newDispatcher(4, 8, OrderByPartition, func(tr *trackedRecord) {
+		mu.Lock()
+		seen[tr.record.Partition] = append(seen[tr.record.Partition], tr.record.Offset)
+		mu.Unlock()
+	})
+	d.start()
+
+	for offset := int64(0); offset < 100; offset++ {
+		for partition := int32(0); partition < 6; partition++ {
+			rec := &Record{Topic: "orders", Partition: partition, Offset: offset}
+			if err := d.dispatch(context.Background(), &trackedRecord{record: rec}); err != nil {
+				t.Fatalf("dispatch() error = %v", err)
+			}
+		}
+	}
+	d.stop()
+
+	for partition, offsets := range seen {
+		if len(offsets) != 100 {
+			t.Errorf("partition %d processed %d records, want 100", partition, len(offsets))
+		}
+		for i, offset := range offsets {
+			if offset != int64(i) {
+				t.Fatalf("partition %d processed out of order: %v", partition, offsets)
+			}
+		}
+	}
+}
+
+func TestDispatcher_KeyOrderingSpreadsPartition(t *testing.T) {
+	d := newDispatcher(8, 1, OrderByKey, func(*trackedRecord) {})
+
+	lanes := make(map[int]bool)
+	for i := 0; i < 64; i++ {
+		rec := &Record{Topic: "orders", Partition: 0, Key: []byte(fmt.Sprintf("key-%d", i))}
+		lanes[d.laneFor(rec)] = true
+
+		// The same key must always land on the same lane
+		if again := d.laneFor(&Record{Topic: "orders", Partition: 0, Key: rec.Key}); again != d.laneFor(rec) {
+			t.Fatalf("key %s mapped to different lanes", rec.Key)
+		}
+	}
+	if len(lanes) < 2 {
+		t.Errorf("per-key ordering should use several lanes for one partition, got %d", len(lanes))
+	}
+}
+
+func TestDispatcher_BoundedConcurrency(t *testing.T) {
+	var active, peak int32
+	d := newDispatcher(3, 4, OrderByKey, func(*trackedRecord) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	})
+	d.start()
+
+	for i := 0; i < 60; i++ {
+		rec := &Record{Topic: "orders", Key: []byte(fmt.Sprintf("k%d", i))}
+		if err := d.dispatch(context.Background(), &trackedRecord{record: rec}); err != nil {
+			t.Fatalf("dispatch() error = %v", err)
+		}
+	}
+	d.stop()
+
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak)
+	}
+}
+
+func TestDispatcher_DispatchHonorsContext(t *testing.T) {
+	block := make(chan struct{})
+	d := newDispatcher(1, 1, OrderByPartition, func(*trackedRecord) { <-block })
+	d.start()
+	defer func() {
+		close(block)
+		d.stop()
+	}()
+
+	rec := &Record{Topic: "orders"}
+	// First record is picked up by the lane, second fills the buffer
+	_ = d.dispatch(context.Background(), &trackedRecord{record: rec})
+	_ = d.dispatch(context.Background(), &trackedRecord{record: rec})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var err error
+	for i := 0; i < 2 && err == nil; i++ {
+		err = d.dispatch(ctx, &trackedRecord{record: rec})
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("dispatch() on full lane = %v, want deadline exceeded", err)
+	}
+}
+
+func TestRunner_ProcessAppliesErrorPolicy(t *testing.T) {
+	handlerErr := errors.New("boom")
+
+	t.Run("error handler accepts failure", func(t *testing.T) {
+		var handled int32
+		r := newRunner(&RunnerConfig{
+			GroupID: "g",
+			ErrorHandler: func(ctx context.Context, record *Record, err error) error {
+				atomic.AddInt32(&handled, 1)
+				return nil
+			},
+		})
+		r.cfg.applyDefaults()
+
+		tracker := r.tracker("orders", 0)
+		tracker.add(5)
+		r.process(context.Background(), func(context.Context, *Record) error { return handlerErr },
+			&trackedRecord{record: &Record{Topic: "orders", Offset: 5}, tracker: tracker})
+
+		if handled != 1 {
+			t.Errorf("error handler called %d times, want 1", handled)
+		}
+		if offset, _, ok := tracker.committable(); !ok || offset != 6 {
+			t.Errorf("committable() = %d, %v, want 6, true", offset, ok)
+		}
+	})
+
+	t.Run("error handler requests retry", func(t *testing.T) {
+		var calls int32
+		r := newRunner(&RunnerConfig{
+			GroupID:      "g",
+			RetryBackoff: time.Millisecond,
+			ErrorHandler: func(ctx context.Context, record *Record, err error) error {
+				return err
+			},
+		})
+		r.cfg.applyDefaults()
+
+		tracker := r.tracker("orders", 0)
+		tracker.add(0)
+		r.process(context.Background(), func(context.Context, *Record) error {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return handlerErr
+			}
+			return nil
+		}, &trackedRecord{record: &Record{Topic: "orders"}, tracker: tracker})
+
+		if calls != 3 {
+			t.Errorf("handler called %d times, want 3", calls)
+		}
+		if _, _, ok := tracker.committable(); !ok {
+			t.Error("record should be committable after a successful retry")
+		}
+	})
+
+	t.Run("revoked partition is skipped", func(t *testing.T) {
+		r := newRunner(&RunnerConfig{GroupID: "g"})
+		r.cfg.applyDefaults()
+
+		tracker := r.tracker("orders", 0)
+		tracker.add(0)
+		tracker.close()
+
+		called := false
+		r.process(context.Background(), func(context.Context, *Record) error {
+			called = true
+			return nil
+		}, &trackedRecord{record: &Record{Topic: "orders"}, tracker: tracker})
+
+		if called {
+			t.Error("handler should not run for a revoked partition")
+		}
+	})
+}
+
+func TestRunner_DrainTimeoutLeavesQueuedRecordsUncommitted(t *testing.T) {
+	r := newRunner(&RunnerConfig{
+		GroupID:      "g",
+		Concurrency:  1,
+		DrainTimeout: 20 * time.Millisecond,
+		// Skipping failures would commit the interrupted and queued records
+		ErrorHandler: func(context.Context, *Record, error) error { return nil },
+	})
+	r.cfg.applyDefaults()
+
+	started := make(chan struct{})
+	var handled sync.Map
+	procCtx, procCancel := context.WithCancel(context.Background())
+	defer procCancel()
+	r.startDispatcher(procCtx, func(ctx context.Context, rec *Record) error {
+		handled.Store(rec.Offset, true)
+		if rec.Offset == 1 {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	for offset := int64(0); offset < 5; offset++ {
+		r.dispatchRecord(context.Background(), &kgo.Record{Topic: "orders", Offset: offset})
+	}
+	<-started
+	r.drain(procCancel)
+
+	offset, _, ok := r.tracker("orders", 0).committable()
+	if !ok || offset != 1 {
+		t.Errorf("committable() = %d, %v, want 1, true", offset, ok)
+	}
+	for offset := int64(2); offset < 5; offset++ {
+		if _, ok := handled.Load(offset); ok {
+			t.Errorf("queued offset %d reached the handler after the drain timed out", offset)
+		}
+	}
+}
+
+func TestRunner_Lag(t *testing.T) {
+	r := newRunner(&RunnerConfig{GroupID: "g"})
+
+	tracker := r.tracker("orders", 1)
+	tracker.add(40)
+	tracker.add(41)
+	tracker.complete(40, 0)
+	r.setHighWatermark("orders", 1, 50)
+
+	lag := r.Lag()
+	if got := lag["orders"][1]; got != 9 {
+		t.Errorf("Lag() = %d, want 9", got)
+	}
+
+	r.takeTrackers(map[string][]int32{"orders": {1}})
+	if len(r.Lag()) != 0 {
+		t.Error("revoked partitions should not report lag")
+	}
+}
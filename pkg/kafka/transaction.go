@@ -0,0 +1,379 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/telemetry"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// BeginTransaction starts a transaction; records produced until the transaction
+// ends are only visible to read-committed consumers after CommitTransaction
+func (p *Producer) BeginTransaction() error {
+	if err := p.checkTransactional(); err != nil {
+		return err
+	}
+	if err := p.client.BeginTransaction(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return nil
+}
+
+// CommitTransaction flushes buffered records and commits the transaction
+func (p *Producer) CommitTransaction(ctx context.Context) error {
+	if err := p.checkTransactional(); err != nil {
+		return err
+	}
+	if err := p.client.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush transaction: %w", err)
+	}
+	if err := p.client.EndTransaction(ctx, kgo.TryCommit); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// AbortTransaction drops buffered records and aborts the transaction
+func (p *Producer) AbortTransaction(ctx context.Context) error {
+	if err := p.checkTransactional(); err != nil {
+		return err
+	}
+	if err := p.client.AbortBufferedRecords(ctx); err != nil {
+		return fmt.Errorf("failed to abort buffered records: %w", err)
+	}
+	if err := p.client.EndTransaction(ctx, kgo.TryAbort); err != nil {
+		return fmt.Errorf("failed to abort transaction: %w", err)
+	}
+	return nil
+}
+
+// Transact runs fn inside a transaction, committing if fn succeeds and
+// aborting otherwise. fn should produce with p.Produce / p.ProduceJSON.
+func (p *Producer) Transact(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := p.BeginTransaction(); err != nil {
+		return err
+	}
+
+	if err := fn(ctx); err != nil {
+		if abortErr := p.AbortTransaction(ctx); abortErr != nil {
+			return errors.Join(err, abortErr)
+		}
+		return err
+	}
+
+	return p.CommitTransaction(ctx)
+}
+
+// checkTransactional verifies the producer is open and transactional
+func (p *Producer) checkTransactional() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return fmt.Errorf("producer is closed")
+	}
+	if !p.transactional {
+		return ErrNotTransactional
+	}
+	return nil
+}
+
+// TransactSessionConfig contains configuration for a read-process-write session
+type TransactSessionConfig struct {
+	Brokers            []string
+	GroupID            string
+	Topics             []string
+	TransactionalID    string
+	ClientID           string
+	MaxRetries         int
+	RetryInterval      time.Duration
+	SessionTimeout     time.Duration
+	RebalanceTimeout   time.Duration
+	TransactionTimeout time.Duration
+	// MaxPollRecords caps records per transaction (default: 100)
+	MaxPollRecords int
+}
+
+// TransactHandler processes a polled batch inside a transaction. Records it
+// produces through the session are committed atomically with the batch offsets.
+type TransactHandler func(ctx context.Context, records []*Record, session *TransactSession) error
+
+// TransactSession consumes a group and produces in the same Kafka transaction
+// so that output records and consumed offsets are committed atomically
+// (exactly-once within Kafka). A crash between produce and commit aborts the
+// transaction, and the batch is redelivered without duplicate output.
+//
+// If a rebalance happens before the transaction ends, the commit is turned into
+// an abort and the batch is redelivered to the new owner.
+type TransactSession struct {
+	session        *kgo.GroupTransactSession
+	maxPollRecords int
+	mu             sync.RWMutex
+	closed         bool
+}
+
+// NewTransactSession creates a new transactional read-process-write session
+func NewTransactSession(ctx context.Context, cfg *TransactSessionConfig) (*TransactSession, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("transact session config is required")
+	}
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("at least one broker is required")
+	}
+	if cfg.GroupID == "" {
+		return nil, fmt.Errorf("consumer group ID is required")
+	}
+	if len(cfg.Topics) == 0 {
+		return nil, fmt.Errorf("at least one topic is required")
+	}
+	if cfg.TransactionalID == "" {
+		return nil, fmt.Errorf("transactional ID is required")
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ConsumerGroup(cfg.GroupID),
+		kgo.ConsumeTopics(cfg.Topics...),
+		kgo.TransactionalID(cfg.TransactionalID),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+		kgo.RequireStableFetchOffsets(),
+		kgo.ProducerBatchMaxBytes(1024 * 1024), // 1MB
+	}
+	if cfg.ClientID != "" {
+		opts = append(opts, kgo.ClientID(cfg.ClientID))
+	}
+	if cfg.SessionTimeout > 0 {
+		opts = append(opts, kgo.SessionTimeout(cfg.SessionTimeout))
+	}
+	if cfg.RebalanceTimeout > 0 {
+		opts = append(opts, kgo.RebalanceTimeout(cfg.RebalanceTimeout))
+	}
+	if cfg.TransactionTimeout > 0 {
+		opts = append(opts, kgo.TransactionTimeout(cfg.TransactionTimeout))
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	retryInterval := cfg.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = 2 * time.Second
+	}
+	maxPollRecords := cfg.MaxPollRecords
+	if maxPollRecords <= 0 {
+		maxPollRecords = 100
+	}
+
+	var session *kgo.GroupTransactSession
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		session, err = kgo.NewGroupTransactSession(opts...)
+		if err == nil {
+			if pingErr := session.Client().Ping(ctx); pingErr == nil {
+				break
+			} else {
+				session.Close()
+				err = pingErr
+			}
+		}
+
+		if i < maxRetries-1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka transact session after %d retries: %w", maxRetries, err)
+	}
+
+	return &TransactSession{
+		session:        session,
+		maxPollRecords: maxPollRecords,
+	}, nil
+}
+
+// Poll fetches the next batch of committed records. When some partitions
+// fail to fetch, the records of the others are still returned, together with
+// an error naming the failed partitions: the session's fetch positions have
+// moved past those records already, so they must be processed and committed
+// like any other batch, or they are skipped.
+func (s *TransactSession) Poll(ctx context.Context) ([]*Record, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	return fetchedRecords(s.session.PollRecords(ctx, s.maxPollRecords))
+}
+
+// fetchedRecords returns the records of fetches, and the errors of the
+// partitions that failed to fetch
+func fetchedRecords(fetches kgo.Fetches) ([]*Record, error) {
+	var errs []error
+	for _, err := range fetches.Errors() {
+		errs = append(errs, fmt.Errorf("poll error on topic %s partition %d: %w", err.Topic, err.Partition, err.Err))
+	}
+
+	var records []*Record
+	fetches.EachRecord(func(r *kgo.Record) {
+		records = append(records, newRecord(r))
+	})
+	return records, errors.Join(errs...)
+}
+
+// Begin starts a transaction for the records returned by the last Poll
+func (s *TransactSession) Begin() error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+	if err := s.session.Begin(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return nil
+}
+
+// End commits (or aborts) the transaction together with the offsets of all
+// polled records. committed is false if a commit was requested but had to be
+// aborted, e.g. because of a rebalance; the batch will then be redelivered.
+func (s *TransactSession) End(ctx context.Context, commit bool) (committed bool, err error) {
+	if err := s.checkOpen(); err != nil {
+		return false, err
+	}
+	committed, err = s.session.End(ctx, kgo.TransactionEndTry(commit))
+	if err != nil {
+		return committed, fmt.Errorf("failed to end transaction: %w", err)
+	}
+	return committed, nil
+}
+
+// Produce sends a message as part of the current transaction
+func (s *TransactSession) Produce(ctx context.Context, msg *Message) error {
+	if err := s.checkOpen(); err != nil {
+		return err
+	}
+
+	ctx, span := telemetry.StartProducerSpan(ctx, msg.Topic, string(msg.Key))
+	defer span.End()
+
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string)
+	}
+	msg.Headers = telemetry.InjectKafkaHeaders(ctx, msg.Headers)
+
+	result := s.session.ProduceSync(ctx, newKgoRecord(msg))
+	if err := result.FirstErr(); err != nil {
+		telemetry.SetSpanError(ctx, err)
+		return fmt.Errorf("failed to produce message: %w", err)
+	}
+
+	if len(result) > 0 {
+		r := result[0].Record
+		telemetry.AddKafkaProducerAttributes(span, r.Partition, r.Offset)
+	}
+
+	return nil
+}
+
+// ProduceJSON serializes data to JSON and sends it as part of the current transaction
+func (s *TransactSession) ProduceJSON(ctx context.Context, topic string, key string, data interface{}, headers map[string]string) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return s.Produce(ctx, &Message{
+		Topic:     topic,
+		Key:       []byte(key),
+		Value:     value,
+		Headers:   withContentType(headers, ContentTypeJSON),
+		Timestamp: time.Now(),
+	})
+}
+
+// Process polls one batch and runs handler inside a transaction. The
+// transaction is committed if handler succeeds and aborted otherwise.
+// It returns whether a batch was committed.
+func (s *TransactSession) Process(ctx context.Context, handler TransactHandler) (bool, error) {
+	// Records polled alongside partition errors are processed first; the
+	// errors are returned once their transaction has ended
+	records, pollErr := s.Poll(ctx)
+	if len(records) == 0 {
+		return false, pollErr
+	}
+	committed, err := s.process(ctx, records, handler)
+	if pollErr != nil {
+		return committed, errors.Join(err, pollErr)
+	}
+	return committed, err
+}
+
+// process runs handler on polled records inside a transaction
+func (s *TransactSession) process(ctx context.Context, records []*Record, handler TransactHandler) (bool, error) {
+	if err := s.Begin(); err != nil {
+		return false, err
+	}
+
+	handlerErr := handler(ctx, records, s)
+
+	// End with a context that survives shutdown: cancelling EndTxn midway
+	// leaves the transaction outcome unknown
+	endCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	committed, err := s.End(endCtx, handlerErr == nil)
+	if handlerErr != nil {
+		if err != nil {
+			return false, errors.Join(handlerErr, err)
+		}
+		return false, handlerErr
+	}
+	return committed, err
+}
+
+// Run calls Process until ctx is cancelled, backing off for a second after errors
+func (s *TransactSession) Run(ctx context.Context, handler TransactHandler, onError func(error)) error {
+	for ctx.Err() == nil {
+		_, err := s.Process(ctx, handler)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		if onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+	return ctx.Err()
+}
+
+// checkOpen returns an error if the session is closed
+func (s *TransactSession) checkOpen() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("transact session is closed")
+	}
+	return nil
+}
+
+// Close leaves the group and closes the session
+func (s *TransactSession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.session.Close()
+}
@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestProducer_TransactionRequiresTransactionalID(t *testing.T) {
+	p := &Producer{}
+	ctx := context.Background()
+
+	if err := p.BeginTransaction(); !errors.Is(err, ErrNotTransactional) {
+		t.Errorf("BeginTransaction() error = %v, want ErrNotTransactional", err)
+	}
+	if err := p.CommitTransaction(ctx); !errors.Is(err, ErrNotTransactional) {
+		t.Errorf("CommitTransaction() error = %v, want ErrNotTransactional", err)
+	}
+	if err := p.AbortTransaction(ctx); !errors.Is(err, ErrNotTransactional) {
+		t.Errorf("AbortTransaction() error = %v, want ErrNotTransactional", err)
+	}
+
+	called := false
+	err := p.Transact(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrNotTransactional) || called {
+		t.Errorf("Transact() error = %v, called = %v; want ErrNotTransactional without calling fn", err, called)
+	}
+}
+
+func TestProducer_TransactionOnClosedProducer(t *testing.T) {
+	p := &Producer{transactional: true, closed: true}
+
+	if err := p.BeginTransaction(); err == nil || errors.Is(err, ErrNotTransactional) {
+		t.Errorf("BeginTransaction() error = %v, want closed error", err)
+	}
+}
+
+func TestTransactSessionConfig_Validation(t *testing.T) {
+	valid := func() *TransactSessionConfig {
+		return &TransactSessionConfig{
+			Brokers:         []string{"localhost:9092"},
+			GroupID:         "g",
+			Topics:          []string{"t"},
+			TransactionalID: "worker-1",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(cfg *TransactSessionConfig) *TransactSessionConfig
+	}{
+		{name: "nil config", mutate: func(*TransactSessionConfig) *TransactSessionConfig { return nil }},
+		{name: "empty brokers", mutate: func(c *TransactSessionConfig) *TransactSessionConfig { c.Brokers = nil; return c }},
+		{name: "empty group ID", mutate: func(c *TransactSessionConfig) *TransactSessionConfig { c.GroupID = ""; return c }},
+		{name: "empty topics", mutate: func(c *TransactSessionConfig) *TransactSessionConfig { c.Topics = nil; return c }},
+		{name: "empty transactional ID", mutate: func(c *TransactSessionConfig) *TransactSessionConfig { c.TransactionalID = ""; return c }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTransactSession(context.Background(), tt.mutate(valid())); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestFetchedRecords_KeepsRecordsBesidePartitionErrors(t *testing.T) {
+	// Partition 1 fails while partitions 0 and 2 deliver records the
+	// session's fetch positions have moved past already
+	fetches := kgo.Fetches{{Topics: []kgo.FetchTopic{{
+		Topic: "bookings",
+		Partitions: []kgo.FetchPartition{
+			{Partition: 0, Records: []*kgo.Record{
+				{Topic: "bookings", Partition: 0, Offset: 7},
+				{Topic: "bookings", Partition: 0, Offset: 8},
+			}},
+			{Partition: 1, Err: kerr.NotLeaderForPartition},
+			{Partition: 2, Records: []*kgo.Record{{Topic: "bookings", Partition: 2, Offset: 3}}},
+		},
+	}}}}
+
+	records, err := fetchedRecords(fetches)
+	if !errors.Is(err, kerr.NotLeaderForPartition) {
+		t.Errorf("fetchedRecords() error = %v, want the partition error", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("fetchedRecords() returned %d records, want all 3 that arrived", len(records))
+	}
+	for i, want := range []int64{7, 8, 3} {
+		if records[i].Offset != want {
+			t.Errorf("records[%d].Offset = %d, want %d", i, records[i].Offset, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Leadership tells whether this instance currently leads. Workers that must
+// run on one instance at a time skip their work while it reports false.
+type Leadership interface {
+	IsLeader() bool
+}
+
+// ElectorConfig holds configuration for an elector
+type ElectorConfig struct {
+	// Name is the lease the replicas campaign for, e.g. "queue-worker"
+	Name string
+	// Holder identifies this instance (default: hostname and process ID)
+	Holder string
+	// TTL is how long leadership outlives a leader that stopped renewing
+	// (default: 15 seconds)
+	TTL time.Duration
+	// RenewInterval is how often the leader renews, and followers try to
+	// take over (default: a third of TTL)
+	RenewInterval time.Duration
+}
+
+// DefaultHolder identifies this process among replicas
+func DefaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Elector elects one leader among the replicas campaigning for a lease
+type Elector struct {
+	manager *Manager
+	config  *ElectorConfig
+
+	mu    sync.RWMutex
+	lease *Lease
+}
+
+// NewElector creates an elector campaigning for cfg.Name
+func NewElector(manager *Manager, cfg *ElectorConfig) *Elector {
+	if cfg.Holder == "" {
+		cfg.Holder = DefaultHolder()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 15 * time.Second
+	}
+	if cfg.RenewInterval <= 0 || cfg.RenewInterval >= cfg.TTL {
+		cfg.RenewInterval = cfg.TTL / 3
+	}
+	return &Elector{manager: manager, config: cfg}
+}
+
+// IsLeader reports whether this instance holds the lease and it has not run
+// out, even if Redis cannot be reached to renew it
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lease.Valid(time.Now())
+}
+
+// Lease returns a copy of the lease while this instance leads, nil otherwise
+func (e *Elector) Lease() *Lease {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.lease.Valid(time.Now()) {
+		return nil
+	}
+	lease := *e.lease
+	return &lease
+}
+
+// Run campaigns for leadership until ctx is cancelled. Each time this
+// instance is elected, lead is started with a context that is cancelled once
+// leadership is lost; Run waits for lead to return before campaigning again.
+// A nil lead just holds leadership, for workers that check IsLeader. The
+// lease is given up when Run returns.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context, lease *Lease)) {
+	ticker := time.NewTicker(e.config.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		if lease := e.campaign(ctx); lease != nil {
+			e.hold(ctx, lease, ticker.C, lead)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign tries to take the lease once
+func (e *Elector) campaign(ctx context.Context) *Lease {
+	lease, err := e.manager.Acquire(ctx, e.config.Name, e.config.Holder, e.config.TTL)
+	if err != nil {
+		return nil
+	}
+	e.setLease(lease)
+	return lease
+}
+
+// hold renews an acquired lease and runs lead until leadership is lost or ctx
+// is cancelled
+func (e *Elector) hold(ctx context.Context, lease *Lease, tick <-chan time.Time, lead func(ctx context.Context, lease *Lease)) {
+	leadCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	if lead != nil {
+		current := *lease
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lead(leadCtx, &current)
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+		e.setLease(nil)
+		// Step down at once, unless the lease was lost already
+		releaseCtx, done := context.WithTimeout(context.Background(), e.config.RenewInterval)
+		defer done()
+		_ = e.manager.Release(releaseCtx, lease)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			err := e.manager.Renew(ctx, lease, e.config.TTL)
+			switch {
+			case err == nil:
+				e.setLease(lease)
+			case errors.Is(err, ErrLost):
+				return
+			case !lease.Valid(time.Now().Add(e.config.RenewInterval)):
+				// Renewals keep failing; step down before the lease can run
+				// out under a leader that still thinks it leads
+				return
+			}
+		}
+	}
+}
+
+// setLease records the lease this instance leads under (nil = following)
+func (e *Elector) setLease(lease *Lease) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if lease == nil {
+		e.lease = nil
+		return
+	}
+	current := *lease
+	e.lease = &current
+}
@@ -0,0 +1,202 @@
+// Package lease hands out named, expiring leases held in Redis, so work that
+// must run on one instance at a time can be shared by several replicas.
+//
+// A lease is taken for a TTL and has to be renewed before it runs out. Each
+// time a lease changes hands it gets the next fencing token of its name, so a
+// holder that stalled past its TTL can tell (and can be told) that its lease
+// is gone: Renew, Release and Check fail with ErrLost once the token moved on.
+// Elector builds single-leader election on top of a lease. Claim is the
+// one-off variant for work done once: it takes no fencing token and leaves
+// nothing behind once it expires.
+package lease
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+//go:embed scripts/acquire_lease.lua
+var acquireLeaseScript string
+
+//go:embed scripts/renew_lease.lua
+var renewLeaseScript string
+
+//go:embed scripts/release_lease.lua
+var releaseLeaseScript string
+
+// Script names in the client's script registry
+const (
+	scriptAcquireLease = "acquire_lease"
+	scriptRenewLease   = "renew_lease"
+	scriptReleaseLease = "release_lease"
+)
+
+// leaseScripts pins the lease scripts; editing one means updating its SHA,
+// and changing its KEYS or ARGV layout means bumping its version
+var leaseScripts = []redis.ScriptSpec{
+	{
+		Name:    scriptAcquireLease,
+		Version: 1,
+		Source:  acquireLeaseScript,
+		Keys:    2,
+		Args:    []string{"holder", "ttl_ms"},
+		SHA:     "cbe7834cd54845cef63d605ad1dd8af7e3ac5089",
+	},
+	{
+		Name:    scriptRenewLease,
+		Version: 1,
+		Source:  renewLeaseScript,
+		Keys:    1,
+		Args:    []string{"holder", "token", "ttl_ms"},
+		SHA:     "2834b14dfecbb1044436d8880f9e7c4d41619690",
+	},
+	{
+		Name:    scriptReleaseLease,
+		Version: 1,
+		Source:  releaseLeaseScript,
+		Keys:    1,
+		Args:    []string{"holder", "token"},
+		SHA:     "a0fe8ac44d1ae7ac38806dd3d015a56c049b2c5d",
+	},
+}
+
+var (
+	// ErrHeld is returned when another holder has the lease
+	ErrHeld = errors.New("lease is held by another holder")
+	// ErrLost is returned when a lease expired or was taken by another holder
+	ErrLost = errors.New("lease expired or was taken by another holder")
+)
+
+// Lease is a lease as taken by its holder
+type Lease struct {
+	Name   string
+	Holder string
+	// Token is the fencing token of the lease; it grows every time the
+	// lease changes hands
+	Token int64
+	// ExpiresAt is when the lease runs out unless renewed, as seen by the
+	// holder (measured from before the request, so never late)
+	ExpiresAt time.Time
+}
+
+// Valid reports whether the lease has not run out at now
+func (l *Lease) Valid(now time.Time) bool {
+	return l != nil && now.Before(l.ExpiresAt)
+}
+
+// Manager takes, renews and gives up leases
+type Manager struct {
+	client *redis.Client
+}
+
+// NewManager creates a lease manager and registers its scripts on client
+func NewManager(client *redis.Client) *Manager {
+	client.Scripts().MustRegister(leaseScripts...)
+	return &Manager{client: client}
+}
+
+// leaseKey returns the key holding a lease's holder and token
+func (m *Manager) leaseKey(name string) string {
+	return fmt.Sprintf("lease:%s", m.client.ClusterTag(name))
+}
+
+// fenceKey returns the key counting a lease's fencing tokens. It shares the
+// lease's slot and never expires, so tokens keep growing across holders.
+func (m *Manager) fenceKey(name string) string {
+	return fmt.Sprintf("lease:%s:fence", m.client.ClusterTag(name))
+}
+
+// Acquire takes the named lease for ttl, or extends it when holder has it
+// already. It returns ErrHeld when another holder has it.
+func (m *Manager) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, error) {
+	start := time.Now()
+	values, err := m.run(ctx, scriptAcquireLease, []string{m.leaseKey(name), m.fenceKey(name)},
+		holder, ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	if code, _ := values[1].(string); code == "LEASE_HELD" {
+		other, _ := values[3].(string)
+		return nil, fmt.Errorf("%w: %s is held by %s", ErrHeld, name, other)
+	}
+
+	token, _ := values[1].(int64)
+	return &Lease{Name: name, Holder: holder, Token: token, ExpiresAt: start.Add(ttl)}, nil
+}
+
+// claimKey returns the key of a one-off claim
+func (m *Manager) claimKey(name string) string {
+	return fmt.Sprintf("claim:%s", m.client.ClusterTag(name))
+}
+
+// Claim takes the named claim for ttl with a plain SET NX PX. A claim is not
+// renewed or given back and has no fencing token, so claiming many distinct
+// names leaves no keys behind once their TTLs run out. It returns ErrHeld
+// when another holder has it.
+func (m *Manager) Claim(ctx context.Context, name, holder string, ttl time.Duration) error {
+	ok, err := m.client.SetNX(ctx, m.claimKey(name), holder, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to claim %s: %w", name, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s is claimed", ErrHeld, name)
+	}
+	return nil
+}
+
+// Renew extends a lease for ttl from now. It returns ErrLost when the lease
+// expired or was taken by another holder meanwhile.
+func (m *Manager) Renew(ctx context.Context, lease *Lease, ttl time.Duration) error {
+	start := time.Now()
+	if _, err := m.run(ctx, scriptRenewLease, []string{m.leaseKey(lease.Name)},
+		lease.Holder, lease.Token, ttl.Milliseconds()); err != nil {
+		return err
+	}
+	lease.ExpiresAt = start.Add(ttl)
+	return nil
+}
+
+// Release gives a lease up so another holder can take it at once. It
+// returns ErrLost when the lease was no longer held.
+func (m *Manager) Release(ctx context.Context, lease *Lease) error {
+	if _, err := m.run(ctx, scriptReleaseLease, []string{m.leaseKey(lease.Name)},
+		lease.Holder, lease.Token); err != nil {
+		return err
+	}
+	lease.ExpiresAt = time.Time{}
+	return nil
+}
+
+// Check returns ErrLost unless the lease is still held under its token. It
+// is meant to be called right before work that must not run twice.
+func (m *Manager) Check(ctx context.Context, lease *Lease) error {
+	fields, err := m.client.HGetAll(ctx, m.leaseKey(lease.Name)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check lease %s: %w", lease.Name, err)
+	}
+	if fields["holder"] != lease.Holder || fields["token"] != strconv.FormatInt(lease.Token, 10) {
+		return fmt.Errorf("%w: %s", ErrLost, lease.Name)
+	}
+	return nil
+}
+
+// run runs a lease script and maps its LEASE_LOST error to ErrLost
+func (m *Manager) run(ctx context.Context, script string, keys []string, args ...interface{}) ([]interface{}, error) {
+	values, err := m.client.Scripts().Run(ctx, script, keys, args...).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s script: %w", script, err)
+	}
+	if len(values) < 2 {
+		return nil, fmt.Errorf("unexpected %s result length: %d", script, len(values))
+	}
+	if code, _ := values[1].(string); code == "LEASE_LOST" {
+		return nil, fmt.Errorf("%w: %s", ErrLost, keys[0])
+	}
+	return values, nil
+}
@@ -0,0 +1,165 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis/redistest"
+)
+
+func TestLeaseScripts(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	held := func(holder, token string) func(testing.TB, *miniredis.Miniredis) {
+		return func(tb testing.TB, mr *miniredis.Miniredis) {
+			mr.HSet("lease:jobs", "holder", holder, "token", token)
+			mr.Set("lease:jobs:fence", token)
+		}
+	}
+
+	redistest.RunScriptCases(t, client, mr, leaseScripts[0], []redistest.ScriptCase{
+		{Name: "free", Keys: []string{"lease:jobs", "lease:jobs:fence"}, Args: []interface{}{"a", 1000},
+			Want: []interface{}{int64(1), int64(1)}},
+		{Name: "extends own", Setup: held("a", "4"), Keys: []string{"lease:jobs", "lease:jobs:fence"}, Args: []interface{}{"a", 1000},
+			Want: []interface{}{int64(1), int64(4)}},
+		{Name: "held by another", Setup: held("b", "4"), Keys: []string{"lease:jobs", "lease:jobs:fence"}, Args: []interface{}{"a", 1000},
+			WantCode: "LEASE_HELD"},
+	})
+	redistest.RunScriptCases(t, client, mr, leaseScripts[1], []redistest.ScriptCase{
+		{Name: "held", Setup: held("a", "4"), Keys: []string{"lease:jobs"}, Args: []interface{}{"a", 4, 1000},
+			Want: []interface{}{int64(1), int64(4)}},
+		{Name: "taken again", Setup: held("a", "5"), Keys: []string{"lease:jobs"}, Args: []interface{}{"a", 4, 1000},
+			WantCode: "LEASE_LOST"},
+	})
+	redistest.RunScriptCases(t, client, mr, leaseScripts[2], []redistest.ScriptCase{
+		{Name: "held", Setup: held("a", "4"), Keys: []string{"lease:jobs"}, Args: []interface{}{"a", 4},
+			Check: func(tb testing.TB, mr *miniredis.Miniredis, _ interface{}) {
+				if mr.Exists("lease:jobs") || !mr.Exists("lease:jobs:fence") {
+					tb.Error("want the lease deleted and its fence kept")
+				}
+			}},
+		{Name: "held by another", Setup: held("b", "4"), Keys: []string{"lease:jobs"}, Args: []interface{}{"a", 4},
+			WantCode: "LEASE_LOST"},
+	})
+}
+
+func TestManager_FencesHolders(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	manager := NewManager(client)
+	ctx := context.Background()
+
+	a, err := manager.Acquire(ctx, "jobs", "a", time.Second)
+	if err != nil {
+		t.Fatalf("Acquire(a) error = %v", err)
+	}
+	if _, err := manager.Acquire(ctx, "jobs", "b", time.Second); !errors.Is(err, ErrHeld) {
+		t.Fatalf("Acquire(b) error = %v, want ErrHeld", err)
+	}
+
+	// a stalls past its TTL and b takes over under a newer token
+	mr.FastForward(2 * time.Second)
+	b, err := manager.Acquire(ctx, "jobs", "b", time.Second)
+	if err != nil {
+		t.Fatalf("Acquire(b) error = %v", err)
+	}
+	if b.Token <= a.Token {
+		t.Errorf("b.Token = %d, want more than a's %d", b.Token, a.Token)
+	}
+	if err := manager.Renew(ctx, a, time.Second); !errors.Is(err, ErrLost) {
+		t.Errorf("Renew(a) error = %v, want ErrLost", err)
+	}
+	if err := manager.Check(ctx, a); !errors.Is(err, ErrLost) {
+		t.Errorf("Check(a) error = %v, want ErrLost", err)
+	}
+	if err := manager.Release(ctx, a); !errors.Is(err, ErrLost) {
+		t.Errorf("Release(a) error = %v, want ErrLost", err)
+	}
+
+	if err := manager.Check(ctx, b); err != nil {
+		t.Errorf("Check(b) error = %v", err)
+	}
+	if err := manager.Release(ctx, b); err != nil {
+		t.Fatalf("Release(b) error = %v", err)
+	}
+	if _, err := manager.Acquire(ctx, "jobs", "a", time.Second); err != nil {
+		t.Errorf("Acquire(a) after release error = %v", err)
+	}
+}
+
+func TestManager_Claim(t *testing.T) {
+	client, mr := redistest.NewClient(t)
+	manager := NewManager(client)
+	ctx := context.Background()
+
+	if err := manager.Claim(ctx, "timeout:1", "a", time.Second); err != nil {
+		t.Fatalf("Claim(a) error = %v", err)
+	}
+	if err := manager.Claim(ctx, "timeout:1", "b", time.Second); !errors.Is(err, ErrHeld) {
+		t.Fatalf("Claim(b) error = %v, want ErrHeld", err)
+	}
+	if keys := mr.Keys(); len(keys) != 1 {
+		t.Errorf("keys = %v, want only the claim", keys)
+	}
+
+	// Once the claim expires nothing of it is left
+	mr.FastForward(2 * time.Second)
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys = %v after expiry, want none", keys)
+	}
+	if err := manager.Claim(ctx, "timeout:1", "b", time.Second); err != nil {
+		t.Errorf("Claim(b) after expiry error = %v", err)
+	}
+}
+
+func TestElector_FailsOver(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+	manager := NewManager(client)
+	newElector := func(holder string) *Elector {
+		return NewElector(manager, &ElectorConfig{Name: "jobs", Holder: holder, TTL: 300 * time.Millisecond, RenewInterval: 20 * time.Millisecond})
+	}
+	waitFor := func(t *testing.T, what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	a := newElector("a")
+	led := make(chan *Lease, 1)
+	doneA := make(chan struct{})
+	go func() {
+		defer close(doneA)
+		a.Run(ctxA, func(ctx context.Context, lease *Lease) {
+			led <- lease
+			<-ctx.Done()
+		})
+	}()
+	waitFor(t, "a to lead", a.IsLeader)
+
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	b := newElector("b")
+	go b.Run(ctxB, nil)
+	time.Sleep(50 * time.Millisecond)
+	if b.IsLeader() {
+		t.Fatal("b leads alongside a")
+	}
+
+	// Stopping a steps it down, and b takes over under a newer token
+	stopA()
+	<-doneA
+	if a.IsLeader() {
+		t.Error("a still leads after stopping")
+	}
+	waitFor(t, "b to lead", b.IsLeader)
+	if first := <-led; b.Lease().Token <= first.Token {
+		t.Errorf("b's token = %d, want more than a's %d", b.Lease().Token, first.Token)
+	}
+}
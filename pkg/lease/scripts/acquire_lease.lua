@@ -0,0 +1,45 @@
+--[[
+    Acquire Lease Lua Script
+    ========================
+    Version: 1
+
+    Takes a lease for a holder, or extends it when the holder has it already.
+    Each time the lease changes hands it gets the next fencing token, so work
+    done under a lease that has since been lost can be told apart from work
+    done under the current one.
+
+    Key Structure:
+    - KEYS[1]: lease:{name}       - Holder and fencing token of the lease (hash)
+    - KEYS[2]: lease:{name}:fence - Last fencing token handed out (counter, never expires)
+
+    Arguments:
+    - ARGV[1]: holder            - Who takes the lease
+    - ARGV[2]: ttl_ms            - How long the lease lasts unless renewed
+
+    Returns:
+    - Success: {1, token}
+    - Error: {0, error_code, error_message, holder, token}
+
+    Error Codes:
+    - LEASE_HELD: Another holder has the lease
+--]]
+
+local lease_key = KEYS[1]
+local fence_key = KEYS[2]
+
+local holder = ARGV[1]
+local ttl_ms = tonumber(ARGV[2])
+
+local lease = redis.call("HMGET", lease_key, "holder", "token")
+if lease[1] and lease[1] ~= holder then
+    return {0, "LEASE_HELD", "Lease is held by another holder", lease[1], tonumber(lease[2]) or 0}
+end
+
+local token = tonumber(lease[2])
+if not lease[1] then
+    token = redis.call("INCR", fence_key)
+    redis.call("HSET", lease_key, "holder", holder, "token", token)
+end
+redis.call("PEXPIRE", lease_key, ttl_ms)
+
+return {1, token}
@@ -0,0 +1,36 @@
+--[[
+    Release Lease Lua Script
+    ========================
+    Version: 1
+
+    Gives a lease up while it is still held under the same fencing token, so
+    another holder can take it at once rather than when it expires. The
+    fencing counter is kept, so tokens keep growing.
+
+    Key Structure:
+    - KEYS[1]: lease:{name}       - Holder and fencing token of the lease (hash)
+
+    Arguments:
+    - ARGV[1]: holder            - Who holds the lease
+    - ARGV[2]: token             - Fencing token the lease was acquired with
+
+    Returns:
+    - Success: {1, token}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - LEASE_LOST: The lease expired or was taken by another holder
+--]]
+
+local lease_key = KEYS[1]
+
+local holder = ARGV[1]
+local token = ARGV[2]
+
+local lease = redis.call("HMGET", lease_key, "holder", "token")
+if lease[1] ~= holder or lease[2] ~= token then
+    return {0, "LEASE_LOST", "Lease expired or was taken by another holder"}
+end
+redis.call("DEL", lease_key)
+
+return {1, tonumber(token)}
@@ -0,0 +1,38 @@
+--[[
+    Renew Lease Lua Script
+    ======================
+    Version: 1
+
+    Extends a lease while it is still held under the same fencing token. A
+    lease that expired and was taken again, even by the same holder, carries
+    another token and is not renewed.
+
+    Key Structure:
+    - KEYS[1]: lease:{name}       - Holder and fencing token of the lease (hash)
+
+    Arguments:
+    - ARGV[1]: holder            - Who holds the lease
+    - ARGV[2]: token             - Fencing token the lease was acquired with
+    - ARGV[3]: ttl_ms            - How long the lease lasts from now
+
+    Returns:
+    - Success: {1, token}
+    - Error: {0, error_code, error_message}
+
+    Error Codes:
+    - LEASE_LOST: The lease expired or was taken by another holder
+--]]
+
+local lease_key = KEYS[1]
+
+local holder = ARGV[1]
+local token = ARGV[2]
+local ttl_ms = tonumber(ARGV[3])
+
+local lease = redis.call("HMGET", lease_key, "holder", "token")
+if lease[1] ~= holder or lease[2] ~= token then
+    return {0, "LEASE_LOST", "Lease expired or was taken by another holder"}
+end
+redis.call("PEXPIRE", lease_key, ttl_ms)
+
+return {1, tonumber(token)}
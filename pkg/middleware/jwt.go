@@ -23,6 +23,7 @@ const (
 	ContextKeyEmail    = "email"
 	ContextKeyRole     = "role"
 	ContextKeyTenantID = "tenant_id"
+	ContextKeyPhone    = "phone" // Set only for a verified phone number
 )
 
 // JWTConfig holds configuration for JWT middleware
@@ -111,6 +112,14 @@ func JWTMiddleware(config *JWTConfig) gin.HandlerFunc {
 		c.Set(ContextKeyRole, role)
 		c.Set(ContextKeyTenantID, tenantID)
 
+		// Verified phone number (OIDC claims backend-auth issues for users with
+		// phone_verified set), used for per-identity purchase limits
+		if verified, _ := claims["phone_number_verified"].(bool); verified {
+			if phone, _ := claims["phone_number"].(string); phone != "" {
+				c.Set(ContextKeyPhone, phone)
+			}
+		}
+
 		c.Next()
 	}
 }
@@ -181,3 +190,13 @@ func GetTenantID(c *gin.Context) (string, bool) {
 	t, ok := tenantID.(string)
 	return t, ok
 }
+
+// GetPhone extracts the verified phone number from gin context
+func GetPhone(c *gin.Context) (string, bool) {
+	phone, exists := c.Get(ContextKeyPhone)
+	if !exists {
+		return "", false
+	}
+	p, ok := phone.(string)
+	return p, ok
+}
@@ -30,11 +30,13 @@ func setupTestRouter(config *JWTConfig) *gin.Engine {
 		email, _ := GetEmail(c)
 		role, _ := GetRole(c)
 		tenantID, _ := GetTenantID(c)
+		phone, _ := GetPhone(c)
 		c.JSON(http.StatusOK, gin.H{
 			"user_id":   userID,
 			"email":     email,
 			"role":      role,
 			"tenant_id": tenantID,
+			"phone":     phone,
 		})
 	})
 	router.GET("/skip", func(c *gin.Context) {
@@ -235,6 +237,28 @@ func TestJWTMiddleware(t *testing.T) {
 			t.Errorf("expected tenant_id in response, got %s", body)
 		}
 	})
+
+	t.Run("phone only when verified", func(t *testing.T) {
+		router := setupTestRouter(config)
+		for _, verified := range []bool{true, false} {
+			token := generateTestToken(jwt.MapClaims{
+				"user_id":               "user-789",
+				"phone_number":          "+66812345678",
+				"phone_number_verified": verified,
+				"exp":                   time.Now().Add(time.Hour).Unix(),
+			}, testSecret)
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if got := contains(w.Body.String(), "+66812345678"); got != verified {
+				t.Errorf("verified=%v: phone in response = %v, body %s", verified, got, w.Body.String())
+			}
+		}
+	})
 }
 
 func TestRequireRole(t *testing.T) {
@@ -0,0 +1,242 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCircuitOpen is returned for commands rejected while the circuit breaker is open
+var ErrCircuitOpen = errors.New("redis: circuit breaker is open")
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every command through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every command until OpenTimeout has passed
+	BreakerOpen
+	// BreakerHalfOpen lets one probe command through at a time
+	BreakerHalfOpen
+)
+
+// String returns the state name
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures a CircuitBreaker
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a probe is let through
+	OpenTimeout time.Duration
+	// SuccessThreshold is the number of successful probes that closes the circuit
+	SuccessThreshold int
+}
+
+// DefaultBreakerConfig returns default circuit breaker configuration
+func DefaultBreakerConfig() *BreakerConfig {
+	return &BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      5 * time.Second,
+		SuccessThreshold: 1,
+	}
+}
+
+// CircuitBreaker stops sending commands to Redis after repeated connection
+// failures, so callers fail fast (and can fall back) instead of waiting on
+// timeouts. Only failures that mean Redis is unreachable count; error replies
+// such as script errors and redis.Nil show that Redis is up.
+type CircuitBreaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	openedAt  time.Time
+	probing   bool
+	listeners []func(from, to BreakerState)
+}
+
+// NewCircuitBreaker creates a circuit breaker; zero config fields take defaults
+func NewCircuitBreaker(cfg *BreakerConfig) *CircuitBreaker {
+	defaults := DefaultBreakerConfig()
+	if cfg == nil {
+		cfg = defaults
+	}
+	c := *cfg
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaults.FailureThreshold
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaults.OpenTimeout
+	}
+	if c.SuccessThreshold <= 0 {
+		c.SuccessThreshold = defaults.SuccessThreshold
+	}
+	return &CircuitBreaker{cfg: c, now: time.Now}
+}
+
+// OnStateChange registers a function called after every state transition
+func (b *CircuitBreaker) OnStateChange(fn func(from, to BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, fn)
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a command may be sent, returning ErrCircuitOpen if not.
+// Every allowed command must be followed by Record.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			b.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.successes = 0
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			b.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	notify := b.transition(from)
+	b.mu.Unlock()
+	notify()
+	return nil
+}
+
+// Record reports the outcome of a command let through by Allow
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	from := b.state
+	wasProbe := b.probing
+	b.probing = false
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		// The caller gave up; says nothing about Redis
+	case IsUnavailable(err):
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+			b.state = BreakerOpen
+			b.openedAt = b.now()
+		}
+	default:
+		b.failures = 0
+		if b.state == BreakerHalfOpen && wasProbe {
+			b.successes++
+			if b.successes >= b.cfg.SuccessThreshold {
+				b.state = BreakerClosed
+			}
+		}
+	}
+
+	notify := b.transition(from)
+	b.mu.Unlock()
+	notify()
+}
+
+// transition resets counters on a state change and returns a function that
+// notifies the listeners; it must be called with mu held and the returned
+// function without it
+func (b *CircuitBreaker) transition(from BreakerState) func() {
+	to := b.state
+	if from == to {
+		return func() {}
+	}
+	if to == BreakerClosed {
+		b.failures = 0
+	}
+	listeners := append([]func(from, to BreakerState){}, b.listeners...)
+	return func() {
+		for _, fn := range listeners {
+			fn(from, to)
+		}
+	}
+}
+
+// IsUnavailable reports whether err means Redis could not be reached, as
+// opposed to Redis answering with an error reply or redis.Nil
+func IsUnavailable(err error) bool {
+	if err == nil || err == redis.Nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var replyErr redis.Error
+	if errors.As(err, &replyErr) {
+		// Replies that mean the node cannot serve commands right now
+		return redis.HasErrorPrefix(err, "LOADING") ||
+			redis.HasErrorPrefix(err, "CLUSTERDOWN") ||
+			redis.HasErrorPrefix(err, "MASTERDOWN")
+	}
+	return true
+}
+
+// breakerHook runs every command and pipeline through a CircuitBreaker
+type breakerHook struct {
+	breaker *CircuitBreaker
+}
+
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.breaker.Allow(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		err := next(ctx, cmd)
+		h.breaker.Record(err)
+		return err
+	}
+}
+
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.breaker.Allow(); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err := next(ctx, cmds)
+		h.breaker.Record(err)
+		return err
+	}
+}
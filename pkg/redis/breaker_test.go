@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// errConnRefused stands in for a network failure
+var errConnRefused = errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")
+
+// newTestBreaker returns a breaker with a controllable clock
+func newTestBreaker(cfg *BreakerConfig) (*CircuitBreaker, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	b := NewCircuitBreaker(cfg)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(&BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Second})
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		b.Record(errConnRefused)
+	}
+	if b.State() != BreakerClosed {
+		t.Fatalf("State() = %s after 2 failures, want closed", b.State())
+	}
+
+	_ = b.Allow()
+	b.Record(errConnRefused)
+	if b.State() != BreakerOpen {
+		t.Fatalf("State() = %s after 3 failures, want open", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(&BreakerConfig{FailureThreshold: 2})
+
+	for _, err := range []error{errConnRefused, nil, errConnRefused, redis.Nil, errConnRefused} {
+		_ = b.Allow()
+		b.Record(err)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("State() = %s, want closed", b.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	b, now := newTestBreaker(&BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second})
+	var transitions []string
+	b.OnStateChange(func(from, to BreakerState) {
+		transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+	})
+
+	_ = b.Allow()
+	b.Record(errConnRefused)
+
+	*now = now.Add(time.Second)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("State() = %s after timeout, want half_open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second Allow() during probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed probe reopens the circuit
+	b.Record(errConnRefused)
+	if b.State() != BreakerOpen {
+		t.Fatalf("State() = %s after failed probe, want open", b.State())
+	}
+
+	*now = now.Add(time.Second)
+	_ = b.Allow()
+	b.Record(nil)
+	if b.State() != BreakerClosed {
+		t.Fatalf("State() = %s after successful probe, want closed", b.State())
+	}
+
+	want := []string{"closed->open", "open->half_open", "half_open->open", "open->half_open", "half_open->closed"}
+	if fmt.Sprint(transitions) != fmt.Sprint(want) {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"nil reply", redis.Nil, false},
+		{"canceled", context.Canceled, false},
+		{"circuit open", fmt.Errorf("reserve: %w", ErrCircuitOpen), true},
+		{"connection refused", errConnRefused, true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUnavailable(tt.err); got != tt.want {
+				t.Errorf("IsUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCircuitBreaker_Defaults(t *testing.T) {
+	b := NewCircuitBreaker(&BreakerConfig{})
+
+	if b.cfg.FailureThreshold != 5 || b.cfg.OpenTimeout != 5*time.Second || b.cfg.SuccessThreshold != 1 {
+		t.Errorf("config = %+v, want defaults", b.cfg)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("State() = %s, want closed", b.State())
+	}
+}
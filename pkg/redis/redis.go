@@ -5,6 +5,7 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,10 +15,15 @@ import (
 
 // Config holds Redis connection configuration
 type Config struct {
-	Host         string
-	Port         int
-	Password     string
-	DB           int
+	Host     string
+	Port     int
+	Password string
+	DB       int
+
+	// ClusterAddrs lists Redis Cluster seed nodes (host:port). When set the
+	// client runs in cluster mode and Host, Port and DB are ignored.
+	ClusterAddrs []string
+
 	PoolSize     int
 	MinIdleConns int
 	DialTimeout  time.Duration
@@ -32,6 +38,10 @@ type Config struct {
 	// Telemetry configuration
 	EnableTracing bool
 	ServiceName   string
+
+	// CircuitBreaker, when set, makes commands fail fast with ErrCircuitOpen
+	// after repeated connection failures (see CircuitBreaker)
+	CircuitBreaker *BreakerConfig
 }
 
 // DefaultConfig returns default Redis configuration
@@ -56,11 +66,20 @@ func (c *Config) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
-// Client wraps redis.Client with additional functionality
+// IsCluster reports whether the config targets a Redis Cluster
+func (c *Config) IsCluster() bool {
+	return len(c.ClusterAddrs) > 0
+}
+
+// Client wraps a single-node or cluster redis client with additional functionality
 type Client struct {
-	client  *redis.Client
+	client  redis.UniversalClient
 	config  *Config
 	scripts sync.Map // map[scriptName]sha
+	breaker *CircuitBreaker
+
+	registryOnce sync.Once
+	registry     *ScriptRegistry
 }
 
 // NewClient creates a new Redis client with retry logic
@@ -69,19 +88,7 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		cfg = DefaultConfig()
 	}
 
-	opts := &redis.Options{
-		Addr:         cfg.Addr(),
-		Password:     cfg.Password,
-		DB:           cfg.DB,
-		PoolSize:     cfg.PoolSize,
-		MinIdleConns: cfg.MinIdleConns,
-		DialTimeout:  cfg.DialTimeout,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
-		PoolTimeout:  cfg.PoolTimeout,
-	}
-
-	client := redis.NewClient(opts)
+	client := newUniversalClient(cfg)
 
 	// Enable OpenTelemetry tracing if configured
 	if cfg.EnableTracing {
@@ -113,10 +120,16 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		}
 
 		if lastErr = client.Ping(ctx).Err(); lastErr == nil {
-			return &Client{
+			c := &Client{
 				client: client,
 				config: cfg,
-			}, nil
+			}
+			// Installed after connecting so startup retries do not trip it
+			if cfg.CircuitBreaker != nil {
+				c.breaker = NewCircuitBreaker(cfg.CircuitBreaker)
+				client.AddHook(breakerHook{breaker: c.breaker})
+			}
+			return c, nil
 		}
 	}
 
@@ -124,11 +137,50 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 	return nil, fmt.Errorf("failed to connect to redis after %d attempts: %w", cfg.MaxRetries+1, lastErr)
 }
 
-// Client returns the underlying redis.Client
-func (c *Client) Client() *redis.Client {
+// newUniversalClient creates a cluster client when ClusterAddrs is set and a single-node client otherwise
+func newUniversalClient(cfg *Config) redis.UniversalClient {
+	if cfg.IsCluster() {
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.ClusterAddrs,
+			Password:     cfg.Password,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			PoolTimeout:  cfg.PoolTimeout,
+		})
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:         cfg.Addr(),
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		PoolTimeout:  cfg.PoolTimeout,
+	})
+}
+
+// Client returns the underlying redis client (*redis.Client or *redis.ClusterClient)
+func (c *Client) Client() redis.UniversalClient {
 	return c.client
 }
 
+// IsCluster reports whether the client is connected to a Redis Cluster
+func (c *Client) IsCluster() bool {
+	_, ok := c.client.(*redis.ClusterClient)
+	return ok
+}
+
+// Breaker returns the client's circuit breaker, or nil if it has none
+func (c *Client) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
 // Ping checks if Redis connection is alive
 func (c *Client) Ping(ctx context.Context) error {
 	return c.client.Ping(ctx).Err()
@@ -177,9 +229,20 @@ func computeSHA1(script string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// LoadScript loads a Lua script into Redis and caches its SHA
+// LoadScript loads a Lua script into Redis and caches its SHA.
+// In cluster mode the script is loaded on every master, since EVALSHA runs
+// on whichever node owns the keys' slot.
 func (c *Client) LoadScript(ctx context.Context, name, script string) (*ScriptInfo, error) {
-	sha, err := c.client.ScriptLoad(ctx, script).Result()
+	var sha string
+	var err error
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		sha = computeSHA1(script)
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return node.ScriptLoad(ctx, script).Err()
+		})
+	} else {
+		sha, err = c.client.ScriptLoad(ctx, script).Result()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to load script %s: %w", name, err)
 	}
@@ -223,7 +286,9 @@ func (c *Client) EvalShaByName(ctx context.Context, name string, keys []string,
 	return c.EvalSha(ctx, sha, keys, args...)
 }
 
-// EvalWithFallback tries EvalSha, falls back to Eval if script not cached
+// EvalWithFallback tries EvalSha, falls back to Eval if script not cached.
+// A NOSCRIPT reply (node restarted, failover, or a master added by
+// resharding) reloads the script on all nodes and retries.
 func (c *Client) EvalWithFallback(ctx context.Context, name, script string, keys []string, args ...interface{}) *redis.Cmd {
 	sha, ok := c.GetScriptSHA(name)
 	if ok {
@@ -333,11 +398,38 @@ func (c *Client) HGetAll(ctx context.Context, key string) *redis.MapStringString
 	return c.client.HGetAll(ctx, key)
 }
 
+// HDel deletes hash fields
+func (c *Client) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
+	return c.client.HDel(ctx, key, fields...)
+}
+
 // HIncrBy increments a hash field
 func (c *Client) HIncrBy(ctx context.Context, key, field string, incr int64) *redis.IntCmd {
 	return c.client.HIncrBy(ctx, key, field, incr)
 }
 
+// --- Set Operations ---
+
+// SAdd adds members to a set
+func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	return c.client.SAdd(ctx, key, members...)
+}
+
+// SMembers gets all members of a set
+func (c *Client) SMembers(ctx context.Context, key string) *redis.StringSliceCmd {
+	return c.client.SMembers(ctx, key)
+}
+
+// SIsMember checks whether member is in a set
+func (c *Client) SIsMember(ctx context.Context, key string, member interface{}) *redis.BoolCmd {
+	return c.client.SIsMember(ctx, key, member)
+}
+
+// SRem removes members from a set
+func (c *Client) SRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	return c.client.SRem(ctx, key, members...)
+}
+
 // --- List Operations ---
 
 // LPush prepends to a list
@@ -416,11 +508,81 @@ func (c *Client) Scan(ctx context.Context, cursor uint64, match string, count in
 	return c.client.Scan(ctx, cursor, match, count)
 }
 
+// ScanKeys iterates over keys matching a pattern and calls fn with each batch.
+// In cluster mode every master is scanned, since SCAN only covers one node.
+func (c *Client) ScanKeys(ctx context.Context, match string, count int64, fn func(keys []string) error) error {
+	scan := func(ctx context.Context, node redis.UniversalClient, emit func(keys []string) error) error {
+		var cursor uint64
+		for {
+			keys, next, err := node.Scan(ctx, cursor, match, count).Result()
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				if err := emit(keys); err != nil {
+					return err
+				}
+			}
+			if cursor = next; cursor == 0 {
+				return nil
+			}
+		}
+	}
+
+	cluster, ok := c.client.(*redis.ClusterClient)
+	if !ok {
+		return scan(ctx, c.client, fn)
+	}
+
+	// Masters are scanned concurrently, so calls to fn are serialized
+	var mu sync.Mutex
+	emit := func(keys []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(keys)
+	}
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scan(ctx, node, emit)
+	})
+}
+
 // Keys returns all keys matching a pattern (use with caution in production)
 func (c *Client) Keys(ctx context.Context, pattern string) *redis.StringSliceCmd {
 	return c.client.Keys(ctx, pattern)
 }
 
+// --- Stream Operations ---
+
+// XAdd appends an entry to a stream
+func (c *Client) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	return c.client.XAdd(ctx, a)
+}
+
+// XGroupCreateMkStream creates a consumer group, creating the stream if needed
+func (c *Client) XGroupCreateMkStream(ctx context.Context, stream, group, start string) *redis.StatusCmd {
+	return c.client.XGroupCreateMkStream(ctx, stream, group, start)
+}
+
+// XReadGroup reads entries for a consumer of a consumer group
+func (c *Client) XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) *redis.XStreamSliceCmd {
+	return c.client.XReadGroup(ctx, a)
+}
+
+// XAck acknowledges entries processed by a consumer group
+func (c *Client) XAck(ctx context.Context, stream, group string, ids ...string) *redis.IntCmd {
+	return c.client.XAck(ctx, stream, group, ids...)
+}
+
+// XAutoClaim transfers entries pending longer than MinIdle to another consumer
+func (c *Client) XAutoClaim(ctx context.Context, a *redis.XAutoClaimArgs) *redis.XAutoClaimCmd {
+	return c.client.XAutoClaim(ctx, a)
+}
+
+// IsBusyGroupError checks if the error reports an already existing consumer group
+func IsBusyGroupError(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP")
+}
+
 // --- Pub/Sub Operations ---
 
 // Publish publishes a message to a channel
@@ -437,3 +599,46 @@ func (c *Client) Subscribe(ctx context.Context, channels ...string) *redis.PubSu
 func (c *Client) PSubscribe(ctx context.Context, patterns ...string) *redis.PubSub {
 	return c.client.PSubscribe(ctx, patterns...)
 }
+
+// --- Cluster Key Helpers ---
+
+// HashTag wraps s in braces so that every key containing the same tag maps
+// to the same Redis Cluster slot, e.g. "zone:availability:" + HashTag(zoneID)
+func HashTag(s string) string {
+	return "{" + s + "}"
+}
+
+// ClusterTag returns HashTag(s) when connected to a Redis Cluster and s
+// unchanged otherwise, so single-node deployments keep their key names
+func (c *Client) ClusterTag(s string) string {
+	if c.IsCluster() {
+		return HashTag(s)
+	}
+	return s
+}
+
+// KeySlot returns the Redis Cluster hash slot of a key, honouring hash tags
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % 16384)
+}
+
+// crc16 implements CRC-16/XMODEM as used by Redis Cluster key hashing
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -110,6 +110,50 @@ func TestIsNoScriptError(t *testing.T) {
 	}
 }
 
+func TestConfig_IsCluster(t *testing.T) {
+	if (&Config{Host: "localhost"}).IsCluster() {
+		t.Error("IsCluster() = true without cluster addrs")
+	}
+	if !(&Config{ClusterAddrs: []string{"node-1:6379"}}).IsCluster() {
+		t.Error("IsCluster() = false with cluster addrs")
+	}
+}
+
+func TestKeySlot(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected int
+	}{
+		{"foo", 12182},
+		{"bar", 5061},
+		{"123456789", 12739},
+		{"{foo}.bar", 12182},
+	}
+
+	for _, tt := range tests {
+		if got := KeySlot(tt.key); got != tt.expected {
+			t.Errorf("KeySlot(%q) = %d, want %d", tt.key, got, tt.expected)
+		}
+	}
+
+	if KeySlot("{user1000}.following") != KeySlot("{user1000}.followers") {
+		t.Error("keys with the same hash tag map to different slots")
+	}
+	if KeySlot("{}foo") == KeySlot("foo") {
+		t.Error("empty hash tag should not be treated as a tag")
+	}
+}
+
+func TestHashTag(t *testing.T) {
+	key := "zone:availability:" + HashTag("zone-1")
+	if key != "zone:availability:{zone-1}" {
+		t.Errorf("HashTag() key = %q", key)
+	}
+	if KeySlot(key) != KeySlot("reservation:"+HashTag("zone-1")+":booking-1") {
+		t.Error("hash-tagged keys of one zone map to different slots")
+	}
+}
+
 // Integration tests - require Redis to be running
 
 func TestNewClient_Integration(t *testing.T) {
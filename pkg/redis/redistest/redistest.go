@@ -0,0 +1,169 @@
+// Package redistest runs pkg/redis clients and Lua scripts against an
+// in-process Redis-compatible server (miniredis) for tests.
+//
+// NewClient starts a server per test. RunScriptCases runs table-driven cases
+// against a registered script, each on an emptied server, and fails when a
+// script declares an error code in its header that no case returns, so new
+// error paths cannot land untested. miniredis implements the commands the
+// booking scripts use; it does not model cluster slots or eviction.
+package redistest
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	pkgredis "github.com/prohmpiriya/booking-rush-10k-rps/pkg/redis"
+)
+
+// NewClient starts a server for the test and returns a client connected to it.
+// Both are closed when the test ends.
+func NewClient(tb testing.TB) (*pkgredis.Client, *miniredis.Miniredis) {
+	tb.Helper()
+
+	mr := miniredis.RunT(tb)
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		tb.Fatalf("redistest: invalid port %q: %v", mr.Port(), err)
+	}
+
+	client, err := pkgredis.NewClient(context.Background(), &pkgredis.Config{
+		Host:         mr.Host(),
+		Port:         port,
+		PoolSize:     4,
+		DialTimeout:  time.Second,
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+	})
+	if err != nil {
+		tb.Fatalf("redistest: failed to connect: %v", err)
+	}
+	tb.Cleanup(func() { client.Close() })
+
+	return client, mr
+}
+
+// ScriptCase is one run of a script
+type ScriptCase struct {
+	Name string
+	// Setup seeds the emptied server before the script runs
+	Setup func(tb testing.TB, mr *miniredis.Miniredis)
+	Keys  []string
+	Args  []interface{}
+
+	// WantCode is the error code of an error reply {0, code, message};
+	// empty expects a success reply {1, ...}
+	WantCode string
+	// Want, when set, must match the leading values of the reply
+	// (or the whole reply when it is not an array)
+	Want []interface{}
+	// WantErr expects the call itself to fail (e.g. wrong arity)
+	WantErr bool
+	// Check asserts on the server state after the script ran
+	Check func(tb testing.TB, mr *miniredis.Miniredis, reply interface{})
+}
+
+// RunScriptCases runs cases against a script registered on client. Every
+// error code listed under "Error Codes:" in the script header must be
+// returned by at least one case.
+func RunScriptCases(t *testing.T, client *pkgredis.Client, mr *miniredis.Miniredis, spec pkgredis.ScriptSpec, cases []ScriptCase) {
+	t.Helper()
+
+	if err := client.Scripts().Register(spec); err != nil {
+		t.Fatalf("Register(%s) error = %v", spec.Name, err)
+	}
+
+	covered := make(map[string]bool)
+	for _, tc := range cases {
+		covered[tc.WantCode] = true
+		t.Run(tc.Name, func(t *testing.T) {
+			mr.FlushAll()
+			if tc.Setup != nil {
+				tc.Setup(t, mr)
+			}
+
+			reply, err := client.Scripts().Run(context.Background(), spec.Name, tc.Keys, tc.Args...).Result()
+			if tc.WantErr {
+				if err == nil {
+					t.Fatalf("Run() = %v, want error", reply)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			checkReply(t, reply, tc)
+			if tc.Check != nil {
+				tc.Check(t, mr, reply)
+			}
+		})
+	}
+
+	for _, code := range ErrorCodes(spec.Source) {
+		if !covered[code] {
+			t.Errorf("%s: error code %s has no case", spec.Name, code)
+		}
+	}
+}
+
+// checkReply compares a script reply with the case's expectations
+func checkReply(tb testing.TB, reply interface{}, tc ScriptCase) {
+	tb.Helper()
+
+	values, isArray := reply.([]interface{})
+	if tc.WantCode != "" || (isArray && tc.Want == nil) {
+		if !isArray || len(values) < 2 {
+			tb.Fatalf("reply = %v, want {status, ...}", reply)
+		}
+		status, _ := values[0].(int64)
+		code, _ := values[1].(string)
+		switch {
+		case tc.WantCode == "" && status != 1:
+			tb.Fatalf("reply = %v, want success", values)
+		case tc.WantCode != "" && (status != 0 || code != tc.WantCode):
+			tb.Fatalf("reply = %v, want error %s", values, tc.WantCode)
+		}
+	}
+
+	if tc.Want == nil {
+		return
+	}
+	if !isArray {
+		values = []interface{}{reply}
+	}
+	if len(values) < len(tc.Want) || !reflect.DeepEqual(values[:len(tc.Want)], tc.Want) {
+		tb.Fatalf("reply = %v, want prefix %v", values, tc.Want)
+	}
+}
+
+var errorCodeRe = regexp.MustCompile(`^\s*-\s*([A-Z][A-Z_]+):`)
+
+// ErrorCodes returns the codes listed under "Error Codes:" in a script header
+func ErrorCodes(source string) []string {
+	var codes []string
+	inList := false
+	for _, line := range strings.Split(source, "\n") {
+		if strings.TrimSpace(line) == "Error Codes:" {
+			inList = true
+			continue
+		}
+		if !inList {
+			continue
+		}
+		m := errorCodeRe.FindStringSubmatch(line)
+		if m == nil {
+			if strings.TrimSpace(line) == "" || strings.Contains(line, "]]") {
+				break
+			}
+			continue
+		}
+		codes = append(codes, m[1])
+	}
+	return codes
+}
@@ -0,0 +1,342 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScriptSpec declares a Lua script and the calling convention the Go code
+// relies on. The script's header comment must agree with it: a "Version: N"
+// line, one "- KEYS[i]:" line per key and one "- ARGV[i]: name" line per
+// argument, in order. Keys and arguments marked "(optional)" may be left off
+// the end of a call.
+type ScriptSpec struct {
+	Name    string
+	Version int
+	// Source is the script body, usually embedded with go:embed
+	Source string
+	// Keys is the number of KEYS the script takes
+	Keys int
+	// Args names the ARGV entries in the order Go passes them
+	Args []string
+	// SHA pins Source; registration fails if Source hashes to anything else
+	SHA string
+}
+
+// ScriptStatus describes a registered script as it is currently served
+type ScriptStatus struct {
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+	SHA      string    `json:"sha"`
+	Pinned   string    `json:"pinned_sha,omitempty"`
+	Origin   string    `json:"origin"`
+	LoadedAt time.Time `json:"loaded_at,omitempty"`
+}
+
+// Script origins reported by ScriptStatus
+const (
+	ScriptOriginEmbedded = "embedded"
+	ScriptOriginReloaded = "reloaded"
+)
+
+// scriptHeader is the calling convention declared in a script's header comment
+type scriptHeader struct {
+	version int
+	keys    int
+	minKeys int
+	args    []string
+	minArgs int
+}
+
+var (
+	headerVersionRe = regexp.MustCompile(`(?m)^\s*Version:\s*(\d+)\s*$`)
+	headerKeyRe     = regexp.MustCompile(`(?m)^\s*-\s*KEYS\[(\d+)\]:(.*)$`)
+	headerArgRe     = regexp.MustCompile(`(?m)^\s*-\s*ARGV\[(\d+)\]:\s*(\S+)(.*)$`)
+)
+
+// parseScriptHeader reads the calling convention from a script's header comment
+func parseScriptHeader(source string) (*scriptHeader, error) {
+	m := headerVersionRe.FindStringSubmatch(source)
+	if m == nil {
+		return nil, fmt.Errorf("header has no Version line")
+	}
+	h := &scriptHeader{minKeys: -1, minArgs: -1}
+	h.version, _ = strconv.Atoi(m[1])
+
+	for i, km := range headerKeyRe.FindAllStringSubmatch(source, -1) {
+		if n, _ := strconv.Atoi(km[1]); n != i+1 {
+			return nil, fmt.Errorf("header lists KEYS[%d] where KEYS[%d] is expected", n, i+1)
+		}
+		if h.minKeys < 0 && strings.Contains(km[2], "optional") {
+			h.minKeys = i
+		}
+		h.keys++
+	}
+	for i, am := range headerArgRe.FindAllStringSubmatch(source, -1) {
+		if n, _ := strconv.Atoi(am[1]); n != i+1 {
+			return nil, fmt.Errorf("header lists ARGV[%d] where ARGV[%d] is expected", n, i+1)
+		}
+		if h.minArgs < 0 && strings.Contains(am[3], "optional") {
+			h.minArgs = i
+		}
+		h.args = append(h.args, am[2])
+	}
+
+	if h.minKeys < 0 {
+		h.minKeys = h.keys
+	}
+	if h.minArgs < 0 {
+		h.minArgs = len(h.args)
+	}
+	return h, nil
+}
+
+// check reports how a script header disagrees with spec
+func (h *scriptHeader) check(spec *ScriptSpec) error {
+	if h.version != spec.Version {
+		return fmt.Errorf("script declares version %d, Go expects %d", h.version, spec.Version)
+	}
+	if h.keys != spec.Keys {
+		return fmt.Errorf("script declares %d KEYS, Go passes %d", h.keys, spec.Keys)
+	}
+	if strings.Join(h.args, ",") != strings.Join(spec.Args, ",") {
+		return fmt.Errorf("script declares ARGV (%s), Go passes (%s)",
+			strings.Join(h.args, ", "), strings.Join(spec.Args, ", "))
+	}
+	return nil
+}
+
+// registeredScript is a script as currently served
+type registeredScript struct {
+	spec     ScriptSpec
+	header   *scriptHeader
+	source   string
+	sha      string
+	origin   string
+	loadedAt time.Time
+}
+
+// ScriptRegistry serves versioned Lua scripts. Each script is registered with
+// the calling convention Go relies on, run by name with its arity checked,
+// and can be replaced at runtime by a body that keeps the same convention.
+type ScriptRegistry struct {
+	client *Client
+
+	mu      sync.RWMutex
+	scripts map[string]*registeredScript
+}
+
+// Scripts returns the client's script registry
+func (c *Client) Scripts() *ScriptRegistry {
+	c.registryOnce.Do(func() {
+		c.registry = &ScriptRegistry{client: c, scripts: make(map[string]*registeredScript)}
+	})
+	return c.registry
+}
+
+// Register adds scripts to the registry without loading them into Redis.
+// Registering the same spec again is a no-op; a different spec under a
+// registered name is an error.
+func (r *ScriptRegistry) Register(specs ...ScriptSpec) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, spec := range specs {
+		sha := computeSHA1(spec.Source)
+		if spec.SHA != "" && spec.SHA != sha {
+			return fmt.Errorf("script %s: source hashes to %s, pinned %s", spec.Name, sha, spec.SHA)
+		}
+		header, err := parseScriptHeader(spec.Source)
+		if err != nil {
+			return fmt.Errorf("script %s: %w", spec.Name, err)
+		}
+		if err := header.check(&spec); err != nil {
+			return fmt.Errorf("script %s: %w", spec.Name, err)
+		}
+
+		if existing, ok := r.scripts[spec.Name]; ok {
+			if existing.spec.Version != spec.Version || computeSHA1(existing.spec.Source) != sha {
+				return fmt.Errorf("script %s is already registered with another version or source", spec.Name)
+			}
+			continue
+		}
+		r.scripts[spec.Name] = &registeredScript{
+			spec:   spec,
+			header: header,
+			source: spec.Source,
+			sha:    sha,
+			origin: ScriptOriginEmbedded,
+		}
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error. It is meant for
+// embedded scripts, whose specs are fixed at build time.
+func (r *ScriptRegistry) MustRegister(specs ...ScriptSpec) {
+	if err := r.Register(specs...); err != nil {
+		panic(err)
+	}
+}
+
+// Load loads the named scripts into Redis
+func (r *ScriptRegistry) Load(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		r.mu.RLock()
+		script, ok := r.scripts[name]
+		r.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("script %s is not registered", name)
+		}
+		if _, err := r.client.LoadScript(ctx, name, script.source); err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		if r.scripts[name] == script {
+			script.loadedAt = time.Now()
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// Run runs a registered script by SHA, loading it first if Redis does not
+// have it (after a restart, failover or reload elsewhere). The number of keys
+// and arguments is checked against the script's header.
+func (r *ScriptRegistry) Run(ctx context.Context, name string, keys []string, args ...interface{}) *redis.Cmd {
+	r.mu.RLock()
+	script, ok := r.scripts[name]
+	r.mu.RUnlock()
+
+	cmd := redis.NewCmd(ctx)
+	switch {
+	case !ok:
+		cmd.SetErr(fmt.Errorf("script %s is not registered", name))
+		return cmd
+	case len(keys) < script.header.minKeys || len(keys) > script.header.keys:
+		cmd.SetErr(fmt.Errorf("script %s v%d takes %d to %d keys, got %d",
+			name, script.spec.Version, script.header.minKeys, script.header.keys, len(keys)))
+		return cmd
+	case len(args) < script.header.minArgs || len(args) > len(script.header.args):
+		cmd.SetErr(fmt.Errorf("script %s v%d takes %d to %d arguments, got %d",
+			name, script.spec.Version, script.header.minArgs, len(script.header.args), len(args)))
+		return cmd
+	}
+
+	result := r.client.EvalSha(ctx, script.sha, keys, args...)
+	if !isNoScriptError(result.Err()) {
+		return result
+	}
+	if _, err := r.client.LoadScript(ctx, name, script.source); err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+	return r.client.EvalSha(ctx, script.sha, keys, args...)
+}
+
+// Reload replaces the bodies of registered scripts. Every new body must keep
+// the registered version, KEYS and ARGV layout; all of them are checked and
+// loaded into Redis before any is switched to, so a bad batch changes nothing.
+// Reload only affects this process; other replicas keep their scripts until
+// reloaded themselves.
+func (r *ScriptRegistry) Reload(ctx context.Context, sources map[string]string) ([]ScriptStatus, error) {
+	type replacement struct {
+		name   string
+		source string
+		sha    string
+	}
+	var batch []replacement
+
+	r.mu.RLock()
+	for name, source := range sources {
+		script, ok := r.scripts[name]
+		if !ok {
+			r.mu.RUnlock()
+			return nil, fmt.Errorf("script %s is not registered", name)
+		}
+		header, err := parseScriptHeader(source)
+		if err == nil {
+			err = header.check(&script.spec)
+		}
+		if err != nil {
+			r.mu.RUnlock()
+			return nil, fmt.Errorf("script %s: %w", name, err)
+		}
+		batch = append(batch, replacement{name: name, source: source, sha: computeSHA1(source)})
+	}
+	r.mu.RUnlock()
+
+	for _, rep := range batch {
+		if _, err := r.client.LoadScript(ctx, rep.name, rep.source); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	for _, rep := range batch {
+		script := *r.scripts[rep.name]
+		script.source = rep.source
+		script.sha = rep.sha
+		script.origin = ScriptOriginReloaded
+		script.loadedAt = now
+		if rep.sha == computeSHA1(script.spec.Source) {
+			script.origin = ScriptOriginEmbedded
+		}
+		r.scripts[rep.name] = &script
+	}
+	r.mu.Unlock()
+
+	return r.Status(), nil
+}
+
+// ReloadDir reloads scripts from <dir>/<name>.lua. Each entry of pins maps a
+// script name to the SHA1 its file must have, so only reviewed bodies are loaded.
+func (r *ScriptRegistry) ReloadDir(ctx context.Context, dir string, pins map[string]string) ([]ScriptStatus, error) {
+	sources := make(map[string]string, len(pins))
+	for name, sha := range pins {
+		if name == "" || filepath.Base(name) != name {
+			return nil, fmt.Errorf("invalid script name %q", name)
+		}
+		body, err := os.ReadFile(filepath.Join(dir, name+".lua"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script %s: %w", name, err)
+		}
+		if got := computeSHA1(string(body)); !strings.EqualFold(got, sha) {
+			return nil, fmt.Errorf("script %s hashes to %s, pinned %s", name, got, sha)
+		}
+		sources[name] = string(body)
+	}
+	return r.Reload(ctx, sources)
+}
+
+// Status lists the registered scripts sorted by name
+func (r *ScriptRegistry) Status() []ScriptStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]ScriptStatus, 0, len(r.scripts))
+	for name, script := range r.scripts {
+		statuses = append(statuses, ScriptStatus{
+			Name:     name,
+			Version:  script.spec.Version,
+			SHA:      script.sha,
+			Pinned:   script.spec.SHA,
+			Origin:   script.origin,
+			LoadedAt: script.loadedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+const testIncrScript = `--[[
+    Incr Script
+    ===========
+    Version: 2
+
+    Key Structure:
+    - KEYS[1]: counter          - Counter (string/integer)
+    - KEYS[2]: audit            - Audit list (optional)
+
+    Arguments:
+    - ARGV[1]: delta            - Amount to add
+    - ARGV[2]: label            - Audit label (optional)
+--]]
+
+local value = redis.call("INCRBY", KEYS[1], tonumber(ARGV[1]))
+if KEYS[2] and ARGV[2] then
+    redis.call("RPUSH", KEYS[2], ARGV[2])
+end
+return value
+`
+
+func testIncrSpec() ScriptSpec {
+	return ScriptSpec{
+		Name:    "incr",
+		Version: 2,
+		Source:  testIncrScript,
+		Keys:    2,
+		Args:    []string{"delta", "label"},
+	}
+}
+
+// newMiniredisClient returns a client connected to an in-process server
+func newMiniredisClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	port, _ := strconv.Atoi(mr.Port())
+	c, err := NewClient(context.Background(), &Config{Host: mr.Host(), Port: port})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, mr
+}
+
+func TestParseScriptHeader(t *testing.T) {
+	h, err := parseScriptHeader(testIncrScript)
+	if err != nil {
+		t.Fatalf("parseScriptHeader() error = %v", err)
+	}
+	if h.version != 2 || h.keys != 2 || h.minKeys != 1 || h.minArgs != 1 {
+		t.Errorf("header = %+v, want version 2, keys 1..2, args 1..2", h)
+	}
+	if strings.Join(h.args, ",") != "delta,label" {
+		t.Errorf("args = %v, want [delta label]", h.args)
+	}
+
+	if _, err := parseScriptHeader("return 1"); err == nil {
+		t.Error("parseScriptHeader() without Version error = nil, want error")
+	}
+	gap := strings.Replace(testIncrScript, "ARGV[2]: label", "ARGV[3]: label", 1)
+	if _, err := parseScriptHeader(gap); err == nil {
+		t.Error("parseScriptHeader() with ARGV gap error = nil, want error")
+	}
+}
+
+func TestScriptRegistry_RegisterChecksConvention(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ScriptSpec)
+	}{
+		{"version", func(s *ScriptSpec) { s.Version = 3 }},
+		{"keys", func(s *ScriptSpec) { s.Keys = 1 }},
+		{"argument order", func(s *ScriptSpec) { s.Args = []string{"label", "delta"} }},
+		{"pinned sha", func(s *ScriptSpec) { s.SHA = computeSHA1("other body") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := testIncrSpec()
+			tt.modify(&spec)
+			r := (&Client{}).Scripts()
+			if err := r.Register(spec); err == nil {
+				t.Error("Register() error = nil, want mismatch error")
+			}
+		})
+	}
+
+	r := (&Client{}).Scripts()
+	spec := testIncrSpec()
+	spec.SHA = computeSHA1(testIncrScript)
+	if err := r.Register(spec, spec); err != nil {
+		t.Fatalf("Register() of a pinned spec twice error = %v", err)
+	}
+	other := testIncrSpec()
+	other.Source += "\n"
+	if err := r.Register(other); err == nil {
+		t.Error("Register() of another source under the same name error = nil, want error")
+	}
+}
+
+func TestScriptRegistry_RunChecksArity(t *testing.T) {
+	c, mr := newMiniredisClient(t)
+	r := c.Scripts()
+	r.MustRegister(testIncrSpec())
+	ctx := context.Background()
+
+	if n, err := r.Run(ctx, "incr", []string{"counter"}, 5).Int64(); err != nil || n != 5 {
+		t.Fatalf("Run() = %d, %v, want 5", n, err)
+	}
+	if n, err := r.Run(ctx, "incr", []string{"counter", "audit"}, 2, "manual").Int64(); err != nil || n != 7 {
+		t.Fatalf("Run() with optional key = %d, %v, want 7", n, err)
+	}
+	if got, _ := mr.List("audit"); len(got) != 1 || got[0] != "manual" {
+		t.Errorf("audit = %v, want [manual]", got)
+	}
+
+	if err := r.Run(ctx, "incr", nil, 1).Err(); err == nil {
+		t.Error("Run() without keys error = nil, want arity error")
+	}
+	if err := r.Run(ctx, "incr", []string{"counter"}, 1, "a", "b").Err(); err == nil {
+		t.Error("Run() with extra argument error = nil, want arity error")
+	}
+	if err := r.Run(ctx, "missing", nil).Err(); err == nil {
+		t.Error("Run() of an unregistered script error = nil, want error")
+	}
+
+	// A flushed script cache is reloaded transparently
+	if err := c.Client().ScriptFlush(ctx).Err(); err != nil {
+		t.Fatalf("ScriptFlush() error = %v", err)
+	}
+	if n, err := r.Run(ctx, "incr", []string{"counter"}, 1).Int64(); err != nil || n != 8 {
+		t.Errorf("Run() after SCRIPT FLUSH = %d, %v, want 8", n, err)
+	}
+}
+
+func TestScriptRegistry_Reload(t *testing.T) {
+	c, _ := newMiniredisClient(t)
+	r := c.Scripts()
+	r.MustRegister(testIncrSpec())
+	ctx := context.Background()
+
+	doubled := strings.Replace(testIncrScript, "tonumber(ARGV[1])", "tonumber(ARGV[1]) * 2", 1)
+	statuses, err := r.Reload(ctx, map[string]string{"incr": doubled})
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if statuses[0].Origin != ScriptOriginReloaded || statuses[0].SHA != computeSHA1(doubled) {
+		t.Errorf("status = %+v, want reloaded with new SHA", statuses[0])
+	}
+	if n, _ := r.Run(ctx, "incr", []string{"counter"}, 3).Int64(); n != 6 {
+		t.Errorf("Run() after reload = %d, want 6", n)
+	}
+
+	// A body that changes the calling convention is refused and changes nothing
+	reordered := strings.Replace(doubled, "ARGV[1]: delta", "ARGV[1]: amount", 1)
+	if _, err := r.Reload(ctx, map[string]string{"incr": reordered}); err == nil {
+		t.Error("Reload() with renamed argument error = nil, want error")
+	}
+	if n, _ := r.Run(ctx, "incr", []string{"counter"}, 1).Int64(); n != 8 {
+		t.Errorf("Run() after refused reload = %d, want 8", n)
+	}
+
+	// Reloading the embedded body reverts to it
+	statuses, err = r.Reload(ctx, map[string]string{"incr": testIncrScript})
+	if err != nil || statuses[0].Origin != ScriptOriginEmbedded {
+		t.Errorf("Reload() of the embedded body = %+v, %v, want embedded origin", statuses, err)
+	}
+}
+
+func TestScriptRegistry_ReloadDir(t *testing.T) {
+	c, _ := newMiniredisClient(t)
+	r := c.Scripts()
+	r.MustRegister(testIncrSpec())
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	body := strings.Replace(testIncrScript, "tonumber(ARGV[1])", "tonumber(ARGV[1]) + 100", 1)
+	if err := os.WriteFile(filepath.Join(dir, "incr.lua"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.ReloadDir(ctx, dir, map[string]string{"incr": computeSHA1(testIncrScript)}); err == nil {
+		t.Error("ReloadDir() with a wrong pin error = nil, want error")
+	}
+	if _, err := r.ReloadDir(ctx, dir, map[string]string{"../incr": computeSHA1(body)}); err == nil {
+		t.Error("ReloadDir() with a path in the name error = nil, want error")
+	}
+	if _, err := r.ReloadDir(ctx, dir, map[string]string{"incr": computeSHA1(body)}); err != nil {
+		t.Fatalf("ReloadDir() error = %v", err)
+	}
+	if n, _ := r.Run(ctx, "incr", []string{"counter"}, 1).Int64(); n != 101 {
+		t.Errorf("Run() after ReloadDir = %d, want 101", n)
+	}
+}
@@ -0,0 +1,216 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DLQFilter selects DLQ messages by original topic, error code and time window
+type DLQFilter struct {
+	// OriginalTopic matches DLQMessage.OriginalTopic exactly (empty = any)
+	OriginalTopic string
+	// ErrorCode matches DLQMessage.ErrorCode exactly (empty = any)
+	ErrorCode string
+	// From includes messages moved to DLQ at or after this time
+	From time.Time
+	// To includes messages moved to DLQ before this time
+	To time.Time
+}
+
+// Matches reports whether the message satisfies the filter
+func (f *DLQFilter) Matches(msg *DLQMessage) bool {
+	if f == nil {
+		return true
+	}
+	if msg == nil {
+		return false
+	}
+	if f.OriginalTopic != "" && msg.OriginalTopic != f.OriginalTopic {
+		return false
+	}
+	if f.ErrorCode != "" && msg.ErrorCode != f.ErrorCode {
+		return false
+	}
+	if !f.From.IsZero() && msg.MovedToDLQAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !msg.MovedToDLQAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// DecodeDLQMessage decodes a record value published by KafkaDLQPublisher
+func DecodeDLQMessage(value []byte) (*DLQMessage, error) {
+	var msg DLQMessage
+	if err := json.Unmarshal(value, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode DLQ message: %w", err)
+	}
+	if msg.OriginalTopic == "" {
+		return nil, fmt.Errorf("DLQ message has no original topic")
+	}
+	return &msg, nil
+}
+
+// ReplayConfig contains configuration for replaying DLQ messages
+type ReplayConfig struct {
+	// RatePerSecond limits how many messages are replayed per second (0 = unlimited)
+	RatePerSecond float64
+	// Source is the operator or tool name recorded on replayed messages
+	Source string
+}
+
+// DefaultReplayConfig returns default replay configuration
+func DefaultReplayConfig() *ReplayConfig {
+	return &ReplayConfig{
+		RatePerSecond: 10,
+		Source:        "dlq-replay",
+	}
+}
+
+// ReplayOptions overrides parts of a DLQ message when it is replayed
+type ReplayOptions struct {
+	// Topic replaces the original topic as the replay destination
+	Topic string
+	// Payload replaces the original payload (e.g. after fixing a bad field)
+	Payload json.RawMessage
+}
+
+// ReplayResult summarizes a batch replay
+type ReplayResult struct {
+	Replayed int               `json:"replayed"`
+	Failed   int               `json:"failed"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// DLQReplayer republishes DLQ messages to their original topic at a bounded rate
+type DLQReplayer struct {
+	publisher KafkaPublisher
+	config    *ReplayConfig
+	limiter   *intervalLimiter
+}
+
+// NewDLQReplayer creates a new DLQ replayer
+func NewDLQReplayer(publisher KafkaPublisher, config *ReplayConfig) *DLQReplayer {
+	if config == nil {
+		config = DefaultReplayConfig()
+	}
+	return &DLQReplayer{
+		publisher: publisher,
+		config:    config,
+		limiter:   newIntervalLimiter(config.RatePerSecond),
+	}
+}
+
+// Replay republishes a single DLQ message, waiting for the rate limiter first
+func (r *DLQReplayer) Replay(ctx context.Context, msg *DLQMessage, opts *ReplayOptions) error {
+	if msg == nil {
+		return fmt.Errorf("DLQ message cannot be nil")
+	}
+
+	topic := msg.OriginalTopic
+	payload := msg.Payload
+	if opts != nil {
+		if opts.Topic != "" {
+			topic = opts.Topic
+		}
+		if len(opts.Payload) > 0 {
+			payload = opts.Payload
+		}
+	}
+
+	if topic == "" {
+		return fmt.Errorf("DLQ message %s has no replay topic", msg.ID)
+	}
+	if len(payload) == 0 || !json.Valid(payload) {
+		return fmt.Errorf("DLQ message %s has invalid JSON payload", msg.ID)
+	}
+
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	headers := make(map[string]string, len(msg.Headers)+3)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers["dlq_replayed_at"] = time.Now().Format(time.RFC3339)
+	headers["dlq_replay_source"] = r.config.Source
+	if msg.ID != "" {
+		headers["dlq_message_id"] = msg.ID
+	}
+
+	if err := r.publisher.PublishJSON(ctx, topic, msg.OriginalKey, payload, headers); err != nil {
+		return fmt.Errorf("failed to replay message %s to %s: %w", msg.ID, topic, err)
+	}
+
+	return nil
+}
+
+// ReplayAll replays messages in order and keeps going past individual failures.
+// Options apply to every message. It stops early only if the context is cancelled.
+func (r *DLQReplayer) ReplayAll(ctx context.Context, msgs []*DLQMessage, opts *ReplayOptions) *ReplayResult {
+	result := &ReplayResult{Errors: make(map[string]string)}
+
+	for _, msg := range msgs {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := r.Replay(ctx, msg, opts); err != nil {
+			result.Failed++
+			result.Errors[msg.ID] = err.Error()
+			continue
+		}
+		result.Replayed++
+	}
+
+	return result
+}
+
+// intervalLimiter spaces calls evenly so at most rate calls happen per second
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newIntervalLimiter(ratePerSecond float64) *intervalLimiter {
+	l := &intervalLimiter{}
+	if ratePerSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / ratePerSecond)
+	}
+	return l
+}
+
+// Wait blocks until the next slot is available or the context is done
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
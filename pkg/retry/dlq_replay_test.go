@@ -0,0 +1,194 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDLQFilter_Matches(t *testing.T) {
+	now := time.Now()
+	msg := &DLQMessage{
+		OriginalTopic: "booking-events",
+		ErrorCode:     "TIMEOUT",
+		MovedToDLQAt:  now,
+	}
+
+	tests := []struct {
+		name   string
+		filter *DLQFilter
+		want   bool
+	}{
+		{"nil filter", nil, true},
+		{"empty filter", &DLQFilter{}, true},
+		{"topic match", &DLQFilter{OriginalTopic: "booking-events"}, true},
+		{"topic mismatch", &DLQFilter{OriginalTopic: "payment-events"}, false},
+		{"error code mismatch", &DLQFilter{ErrorCode: "INVALID"}, false},
+		{"inside window", &DLQFilter{From: now.Add(-time.Minute), To: now.Add(time.Minute)}, true},
+		{"before window", &DLQFilter{From: now.Add(time.Minute)}, false},
+		{"to is exclusive", &DLQFilter{To: now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(msg); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeDLQMessage(t *testing.T) {
+	data, _ := json.Marshal(&DLQMessage{ID: "msg-1", OriginalTopic: "booking-events"})
+
+	msg, err := DecodeDLQMessage(data)
+	if err != nil {
+		t.Fatalf("DecodeDLQMessage() error = %v", err)
+	}
+	if msg.ID != "msg-1" {
+		t.Errorf("ID = %s, want msg-1", msg.ID)
+	}
+
+	if _, err := DecodeDLQMessage([]byte(`{"id":"x"}`)); err == nil {
+		t.Error("expected error for message without original topic")
+	}
+	if _, err := DecodeDLQMessage([]byte(`not-json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestDLQReplayer_Replay(t *testing.T) {
+	publisher := &MockKafkaPublisher{}
+	replayer := NewDLQReplayer(publisher, &ReplayConfig{Source: "test"})
+
+	msg := &DLQMessage{
+		ID:            "msg-1",
+		OriginalTopic: "booking-events",
+		OriginalKey:   "booking-1",
+		Payload:       json.RawMessage(`{"status":"bad"}`),
+		Headers:       map[string]string{"event_type": "booking.created"},
+	}
+
+	if err := replayer.Replay(context.Background(), msg, nil); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if len(publisher.PublishedMessages) != 1 {
+		t.Fatalf("published %d messages, want 1", len(publisher.PublishedMessages))
+	}
+	published := publisher.PublishedMessages[0]
+	if published.Topic != "booking-events" {
+		t.Errorf("Topic = %s, want booking-events", published.Topic)
+	}
+	if published.Key != "booking-1" {
+		t.Errorf("Key = %s, want booking-1", published.Key)
+	}
+	if published.Headers["event_type"] != "booking.created" {
+		t.Error("original headers should be restored")
+	}
+	if published.Headers["dlq_message_id"] != "msg-1" {
+		t.Error("dlq_message_id header should be set")
+	}
+	if published.Headers["dlq_replay_source"] != "test" {
+		t.Error("dlq_replay_source header should be set")
+	}
+}
+
+func TestDLQReplayer_ReplayWithOverrides(t *testing.T) {
+	publisher := &MockKafkaPublisher{}
+	replayer := NewDLQReplayer(publisher, &ReplayConfig{})
+
+	msg := &DLQMessage{
+		ID:            "msg-1",
+		OriginalTopic: "booking-events",
+		Payload:       json.RawMessage(`{"status":"bad"}`),
+	}
+	opts := &ReplayOptions{
+		Topic:   "booking-events-retry",
+		Payload: json.RawMessage(`{"status":"fixed"}`),
+	}
+
+	if err := replayer.Replay(context.Background(), msg, opts); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	published := publisher.PublishedMessages[0]
+	if published.Topic != "booking-events-retry" {
+		t.Errorf("Topic = %s, want booking-events-retry", published.Topic)
+	}
+	if string(published.Data.(json.RawMessage)) != `{"status":"fixed"}` {
+		t.Errorf("Data = %s, want edited payload", published.Data)
+	}
+}
+
+func TestDLQReplayer_ReplayInvalidPayload(t *testing.T) {
+	publisher := &MockKafkaPublisher{}
+	replayer := NewDLQReplayer(publisher, &ReplayConfig{})
+
+	msg := &DLQMessage{ID: "msg-1", OriginalTopic: "booking-events"}
+	if err := replayer.Replay(context.Background(), msg, &ReplayOptions{Payload: json.RawMessage(`{broken`)}); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+	if len(publisher.PublishedMessages) != 0 {
+		t.Error("invalid payload should not be published")
+	}
+}
+
+func TestDLQReplayer_ReplayAll(t *testing.T) {
+	publisher := &MockKafkaPublisher{}
+	replayer := NewDLQReplayer(publisher, &ReplayConfig{})
+
+	msgs := []*DLQMessage{
+		{ID: "ok-1", OriginalTopic: "booking-events", Payload: json.RawMessage(`{}`)},
+		{ID: "bad", Payload: json.RawMessage(`{}`)},
+		{ID: "ok-2", OriginalTopic: "booking-events", Payload: json.RawMessage(`{}`)},
+	}
+
+	result := replayer.ReplayAll(context.Background(), msgs, nil)
+	if result.Replayed != 2 {
+		t.Errorf("Replayed = %d, want 2", result.Replayed)
+	}
+	if result.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", result.Failed)
+	}
+	if _, ok := result.Errors["bad"]; !ok {
+		t.Error("expected error recorded for message without topic")
+	}
+}
+
+func TestDLQReplayer_RateLimit(t *testing.T) {
+	publisher := &MockKafkaPublisher{}
+	replayer := NewDLQReplayer(publisher, &ReplayConfig{RatePerSecond: 20})
+
+	msgs := make([]*DLQMessage, 5)
+	for i := range msgs {
+		msgs[i] = &DLQMessage{ID: "m", OriginalTopic: "t", Payload: json.RawMessage(`{}`)}
+	}
+
+	start := time.Now()
+	replayer.ReplayAll(context.Background(), msgs, nil)
+	elapsed := time.Since(start)
+
+	// 5 messages at 20/s: first is immediate, remaining 4 are spaced 50ms apart
+	if elapsed < 180*time.Millisecond {
+		t.Errorf("replay took %v, expected rate limiting to take at least ~200ms", elapsed)
+	}
+}
+
+func TestDLQReplayer_RateLimitContextCancelled(t *testing.T) {
+	publisher := &MockKafkaPublisher{}
+	replayer := NewDLQReplayer(publisher, &ReplayConfig{RatePerSecond: 1})
+	msg := &DLQMessage{ID: "m", OriginalTopic: "t", Payload: json.RawMessage(`{}`)}
+
+	if err := replayer.Replay(context.Background(), msg, nil); err != nil {
+		t.Fatalf("first Replay() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := replayer.Replay(ctx, msg, nil); err == nil {
+		t.Error("expected context error while waiting for rate limiter")
+	}
+}
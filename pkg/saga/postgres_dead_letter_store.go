@@ -0,0 +1,216 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDeadLetterNotFound is returned when a dead letter does not exist
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetterFilter narrows dead letter queries for browsing, replay and purge
+type DeadLetterFilter struct {
+	// IDs restricts the query to specific dead letters
+	IDs []string
+	// Topic matches the original topic exactly
+	Topic string
+	// ErrorCode matches the recorded error code exactly
+	ErrorCode string
+	// SagaID matches the saga the message belonged to
+	SagaID string
+	// From includes dead letters created at or after this time
+	From time.Time
+	// To includes dead letters created before this time
+	To time.Time
+	// IncludeProcessed also returns dead letters already marked processed
+	IncludeProcessed bool
+	// Limit caps the number of rows returned (0 = no limit)
+	Limit int
+	// Offset skips rows for pagination
+	Offset int
+}
+
+// buildWhere renders the filter into a WHERE clause and its positional arguments
+func (f *DeadLetterFilter) buildWhere() (string, []interface{}) {
+	if f == nil {
+		f = &DeadLetterFilter{}
+	}
+
+	var conditions []string
+	var args []interface{}
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if !f.IncludeProcessed {
+		conditions = append(conditions, "processed = FALSE")
+	}
+	if len(f.IDs) > 0 {
+		add("id = ANY($%d::uuid[])", f.IDs)
+	}
+	if f.Topic != "" {
+		add("topic = $%d", f.Topic)
+	}
+	if f.ErrorCode != "" {
+		add("error_code = $%d", f.ErrorCode)
+	}
+	if f.SagaID != "" {
+		add("saga_id = $%d", f.SagaID)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+const deadLetterColumns = `id, saga_id, topic, message_key, message_value, error_message,
+		       error_code, retry_count, created_at, processed_at, processed`
+
+// ListDeadLetters retrieves dead letters matching the filter, oldest first
+func (s *PostgresStore) ListDeadLetters(ctx context.Context, filter *DeadLetterFilter) ([]*DeadLetter, error) {
+	where, args := filter.buildWhere()
+	query := "SELECT " + deadLetterColumns + " FROM saga_dead_letters" + where + " ORDER BY created_at ASC"
+
+	if filter != nil && filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	if filter != nil && filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", filter.Offset)
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters []*DeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, dl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dead letters: %w", err)
+	}
+
+	return deadLetters, nil
+}
+
+// CountDeadLetters counts dead letters matching the filter (Limit and Offset are ignored)
+func (s *PostgresStore) CountDeadLetters(ctx context.Context, filter *DeadLetterFilter) (int64, error) {
+	where, args := filter.buildWhere()
+
+	var count int64
+	if err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM saga_dead_letters"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	return count, nil
+}
+
+// GetDeadLetter retrieves a single dead letter by ID
+func (s *PostgresStore) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	query := "SELECT " + deadLetterColumns + " FROM saga_dead_letters WHERE id = $1"
+
+	dl, err := scanDeadLetter(s.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+	return dl, nil
+}
+
+// MarkDeadLettersProcessed marks several dead letters as processed and returns how many changed
+func (s *PostgresStore) MarkDeadLettersProcessed(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE saga_dead_letters
+		SET processed = TRUE, processed_at = NOW()
+		WHERE id = ANY($1::uuid[]) AND processed = FALSE
+	`
+
+	tag, err := s.pool.Exec(ctx, query, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark dead letters as processed: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeDeadLetters deletes dead letters matching the filter and returns how many were removed
+func (s *PostgresStore) PurgeDeadLetters(ctx context.Context, filter *DeadLetterFilter) (int64, error) {
+	where, args := filter.buildWhere()
+
+	tag, err := s.pool.Exec(ctx, "DELETE FROM saga_dead_letters"+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// scanDeadLetter scans a row selected with deadLetterColumns
+func scanDeadLetter(row pgx.Row) (*DeadLetter, error) {
+	var dl DeadLetter
+	var sagaID, messageKey, errorCode *string
+	var messageJSON []byte
+
+	err := row.Scan(
+		&dl.ID,
+		&sagaID,
+		&dl.Topic,
+		&messageKey,
+		&messageJSON,
+		&dl.ErrorMessage,
+		&errorCode,
+		&dl.RetryCount,
+		&dl.CreatedAt,
+		&dl.ProcessedAt,
+		&dl.Processed,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+	}
+
+	if sagaID != nil {
+		dl.SagaID = *sagaID
+	}
+	if messageKey != nil {
+		dl.MessageKey = *messageKey
+	}
+	if errorCode != nil {
+		dl.ErrorCode = *errorCode
+	}
+
+	if len(messageJSON) > 0 {
+		if err := json.Unmarshal(messageJSON, &dl.MessageValue); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message value: %w", err)
+		}
+	}
+
+	return &dl, nil
+}
@@ -0,0 +1,63 @@
+package saga
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeadLetterFilter_BuildWhere(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		filter    *DeadLetterFilter
+		wantWhere string
+		wantArgs  int
+	}{
+		{
+			name:      "nil filter only returns unprocessed",
+			filter:    nil,
+			wantWhere: " WHERE processed = FALSE",
+			wantArgs:  0,
+		},
+		{
+			name:      "include processed without conditions",
+			filter:    &DeadLetterFilter{IncludeProcessed: true},
+			wantWhere: "",
+			wantArgs:  0,
+		},
+		{
+			name:      "topic and error code",
+			filter:    &DeadLetterFilter{Topic: "saga.booking.reserve-seats.command", ErrorCode: "TIMEOUT"},
+			wantWhere: " WHERE processed = FALSE AND topic = $1 AND error_code = $2",
+			wantArgs:  2,
+		},
+		{
+			name: "all conditions are numbered in order",
+			filter: &DeadLetterFilter{
+				IDs:              []string{"a", "b"},
+				Topic:            "t",
+				ErrorCode:        "E",
+				SagaID:           "s",
+				From:             from,
+				To:               to,
+				IncludeProcessed: true,
+			},
+			wantWhere: " WHERE id = ANY($1::uuid[]) AND topic = $2 AND error_code = $3 AND saga_id = $4 AND created_at >= $5 AND created_at < $6",
+			wantArgs:  6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := tt.filter.buildWhere()
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if len(args) != tt.wantArgs {
+				t.Errorf("len(args) = %d, want %d", len(args), tt.wantArgs)
+			}
+		})
+	}
+}
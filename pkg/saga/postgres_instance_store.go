@@ -350,6 +350,7 @@ type DeadLetter struct {
 	MessageKey   string                 `json:"message_key,omitempty"`
 	MessageValue map[string]interface{} `json:"message_value"`
 	ErrorMessage string                 `json:"error_message"`
+	ErrorCode    string                 `json:"error_code,omitempty"`
 	RetryCount   int                    `json:"retry_count"`
 	CreatedAt    time.Time              `json:"created_at"`
 	ProcessedAt  *time.Time             `json:"processed_at,omitempty"`
@@ -365,19 +366,22 @@ func (s *PostgresStore) SaveDeadLetter(ctx context.Context, dl *DeadLetter) erro
 
 	query := `
 		INSERT INTO saga_dead_letters (
-			saga_id, topic, message_key, message_value, error_message, retry_count
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			saga_id, topic, message_key, message_value, error_message, error_code, retry_count
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	var sagaID, messageKey *string
+	var sagaID, messageKey, errorCode *string
 	if dl.SagaID != "" {
 		sagaID = &dl.SagaID
 	}
 	if dl.MessageKey != "" {
 		messageKey = &dl.MessageKey
 	}
+	if dl.ErrorCode != "" {
+		errorCode = &dl.ErrorCode
+	}
 
-	_, err = s.pool.Exec(ctx, query, sagaID, dl.Topic, messageKey, messageJSON, dl.ErrorMessage, dl.RetryCount)
+	_, err = s.pool.Exec(ctx, query, sagaID, dl.Topic, messageKey, messageJSON, dl.ErrorMessage, errorCode, dl.RetryCount)
 	if err != nil {
 		return fmt.Errorf("failed to save dead letter: %w", err)
 	}
@@ -387,59 +391,10 @@ func (s *PostgresStore) SaveDeadLetter(ctx context.Context, dl *DeadLetter) erro
 
 // GetUnprocessedDeadLetters retrieves unprocessed dead letters
 func (s *PostgresStore) GetUnprocessedDeadLetters(ctx context.Context, limit int) ([]*DeadLetter, error) {
-	query := `
-		SELECT id, saga_id, topic, message_key, message_value, error_message, retry_count, created_at
-		FROM saga_dead_letters
-		WHERE processed = FALSE
-		ORDER BY created_at ASC
-	`
-
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", limit)
-	}
-
-	rows, err := s.pool.Query(ctx, query)
+	deadLetters, err := s.ListDeadLetters(ctx, &DeadLetterFilter{Limit: limit})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get unprocessed dead letters: %w", err)
 	}
-	defer rows.Close()
-
-	var deadLetters []*DeadLetter
-	for rows.Next() {
-		var dl DeadLetter
-		var sagaID, messageKey *string
-		var messageJSON []byte
-
-		err := rows.Scan(
-			&dl.ID,
-			&sagaID,
-			&dl.Topic,
-			&messageKey,
-			&messageJSON,
-			&dl.ErrorMessage,
-			&dl.RetryCount,
-			&dl.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
-		}
-
-		if sagaID != nil {
-			dl.SagaID = *sagaID
-		}
-		if messageKey != nil {
-			dl.MessageKey = *messageKey
-		}
-
-		if len(messageJSON) > 0 {
-			if err := json.Unmarshal(messageJSON, &dl.MessageValue); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal message value: %w", err)
-			}
-		}
-
-		deadLetters = append(deadLetters, &dl)
-	}
-
 	return deadLetters, nil
 }
 
@@ -171,17 +171,20 @@ func (c *UpDownCounter) Dec(ctx context.Context, attrs ...attribute.KeyValue) {
 
 // Common metric attribute keys
 const (
-	AttrServiceName   = "service.name"
-	AttrEnvironment   = "environment"
-	AttrMethod        = "http.method"
-	AttrPath          = "http.path"
-	AttrStatusCode    = "http.status_code"
-	AttrErrorType     = "error.type"
-	AttrEventID       = "event.id"
-	AttrUserID        = "user.id"
-	AttrTenantID      = "tenant.id"
-	AttrBookingStatus = "booking.status"
-	AttrPaymentStatus = "payment.status"
+	AttrServiceName    = "service.name"
+	AttrEnvironment    = "environment"
+	AttrMethod         = "http.method"
+	AttrPath           = "http.path"
+	AttrStatusCode     = "http.status_code"
+	AttrErrorType      = "error.type"
+	AttrEventID        = "event.id"
+	AttrUserID         = "user.id"
+	AttrTenantID       = "tenant.id"
+	AttrBookingStatus  = "booking.status"
+	AttrPaymentStatus  = "payment.status"
+	AttrKafkaTopic     = "messaging.kafka.topic"
+	AttrKafkaPartition = "messaging.kafka.partition"
+	AttrKafkaGroup     = "messaging.kafka.consumer_group"
 )
 
 // Helper functions for common attributes
@@ -228,3 +231,15 @@ func BookingStatusAttr(status string) attribute.KeyValue {
 func PaymentStatusAttr(status string) attribute.KeyValue {
 	return attribute.String(AttrPaymentStatus, status)
 }
+
+func KafkaTopicAttr(topic string) attribute.KeyValue {
+	return attribute.String(AttrKafkaTopic, topic)
+}
+
+func KafkaPartitionAttr(partition int32) attribute.KeyValue {
+	return attribute.Int(AttrKafkaPartition, int(partition))
+}
+
+func KafkaGroupAttr(group string) attribute.KeyValue {
+	return attribute.String(AttrKafkaGroup, group)
+}
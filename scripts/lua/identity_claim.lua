@@ -0,0 +1,75 @@
+--[[
+    Identity Claim Lua Script
+    =========================
+    Version: 1
+
+    Atomically checks and counts seats against the purchase limits of the
+    identities behind a booking (verified phone, card fingerprint, device,
+    IP subnet). Either every identity is within its limit and all of them
+    are charged, or none is. The seats charged to each identity are recorded
+    in a claim hash so identity_release.lua can give them back.
+
+    All keys share the event's hash tag on a Redis Cluster.
+
+    Key Structure:
+    - KEYS[1]: identity:claim:{event_id}:{claim_id}          - Seats charged per tally key (hash)
+    - KEYS[2]: identity:seats:{event_id}:{kind}:{fingerprint} - Seats held by an identity (optional)
+    - KEYS[3]: identity:seats:{event_id}:{kind}:{fingerprint} - Seats held by an identity (optional)
+    - KEYS[4]: identity:seats:{event_id}:{kind}:{fingerprint} - Seats held by an identity (optional)
+    - KEYS[5]: identity:seats:{event_id}:{kind}:{fingerprint} - Seats held by an identity (optional)
+
+    Arguments:
+    - ARGV[1]: quantity          - Seats to charge to every identity
+    - ARGV[2]: ttl_seconds       - Expiry of the tallies and the claim (the limit window)
+    - ARGV[3]: max_1             - Limit of KEYS[2] (optional, 0 = unlimited)
+    - ARGV[4]: max_2             - Limit of KEYS[3] (optional)
+    - ARGV[5]: max_3             - Limit of KEYS[4] (optional)
+    - ARGV[6]: max_4             - Limit of KEYS[5] (optional)
+
+    Returns:
+    - Success: {1, identities_charged}
+    - Error: {0, error_code, error_message, identity_index, current}
+
+    Error Codes:
+    - INVALID_QUANTITY: Quantity must be positive
+    - ALREADY_CLAIMED: The claim already exists; nothing is charged twice
+    - IDENTITY_LIMIT_EXCEEDED: An identity would exceed its limit
+      (identity_index is its 1-based position after KEYS[1])
+--]]
+
+local claim_key = KEYS[1]
+
+local quantity = tonumber(ARGV[1])
+local ttl_seconds = tonumber(ARGV[2]) or 604800
+
+if not quantity or quantity <= 0 then
+    return {0, "INVALID_QUANTITY", "Quantity must be positive"}
+end
+
+if redis.call("EXISTS", claim_key) == 1 then
+    return {0, "ALREADY_CLAIMED", "Identities already claimed"}
+end
+
+-- Check every identity before charging any
+for i = 2, #KEYS do
+    local max = tonumber(ARGV[i + 1]) or 0
+    if max > 0 then
+        local current = tonumber(redis.call("GET", KEYS[i])) or 0
+        if current + quantity > max then
+            return {0, "IDENTITY_LIMIT_EXCEEDED",
+                string.format("Identity limit exceeded. Current: %d, Requested: %d, Max: %d", current, quantity, max),
+                i - 1, current}
+        end
+    end
+end
+
+for i = 2, #KEYS do
+    redis.call("INCRBY", KEYS[i], quantity)
+    redis.call("EXPIRE", KEYS[i], ttl_seconds)
+    redis.call("HINCRBY", claim_key, KEYS[i], quantity)
+end
+if #KEYS > 1 then
+    redis.call("EXPIRE", claim_key, ttl_seconds)
+end
+
+return {1, #KEYS - 1}
@@ -0,0 +1,31 @@
+--[[
+    Identity Release Lua Script
+    ===========================
+    Version: 1
+
+    Gives back the seats claims charged to their identities and deletes the
+    claims, when a reservation is cancelled or expires or a payment fails.
+    Releasing a missing claim does nothing, so it is safe to repeat.
+
+    Key Structure:
+    - KEYS[1]: identity:claim:{event_id}:{claim_id}         - Seats charged per tally key (hash)
+    - KEYS[2]: identity:claim:{event_id}:{claim_id}:payment - Seats charged at payment (hash, optional)
+
+    Returns:
+    - Success: {1, identities_released}
+--]]
+
+local released = 0
+for _, claim_key in ipairs(KEYS) do
+    local charged = redis.call("HGETALL", claim_key)
+    for i = 1, #charged, 2 do
+        local remaining = redis.call("DECRBY", charged[i], tonumber(charged[i + 1]) or 0)
+        if remaining <= 0 then
+            redis.call("DEL", charged[i])
+        end
+        released = released + 1
+    end
+    redis.call("DEL", claim_key)
+end
+
+return {1, released}
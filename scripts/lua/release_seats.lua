@@ -1,16 +1,29 @@
 --[[
     Release Seats Lua Script
     ========================
-    Atomically releases reserved seats back to inventory.
+    Version: 2
+
+    Atomically releases reserved seats back to inventory. Also used by the
+    expiry worker, which passes a due time so that a reservation is only
+    expired once its deadline has passed, and the expiry stream so that the
+    released reservation is published in the same step.
 
     Key Structure:
     - KEYS[1]: zone:availability:{zone_id}           - Available seats count (string/integer)
-    - KEYS[2]: user:reservations:{user_id}:{event_id} - User's total reserved for this event
-    - KEYS[3]: reservation:{booking_id}              - Reservation record (hash)
+    - KEYS[2]: reservation:{booking_id}              - Reservation record (hash)
+    - KEYS[3]: expiry:reservations                   - Deadline index (sorted set)
+    - KEYS[4]: user:reservations:{user_id}:{event_id} - User's total reserved for this event (optional)
+    - KEYS[5]: stream:reservation:expired        - Expiry stream (optional, with due_by)
+
+    Redis Cluster mode omits KEYS[4] and KEYS[5]; the caller then decrements
+    the per-user count with user_tally.lua and publishes to the expiry
+    stream itself.
 
     Arguments:
     - ARGV[1]: booking_id        - Booking ID (for validation)
     - ARGV[2]: user_id           - User ID (for validation)
+    - ARGV[3]: due_by            - Unix time the deadline must have passed (optional)
+    - ARGV[4]: stream_max_len    - Approximate cap of the expiry stream (optional, with KEYS[5])
 
     Returns:
     - Success: {1, new_available_seats, new_user_reserved}
@@ -21,18 +34,25 @@
     - INVALID_BOOKING_ID: Booking ID does not match
     - INVALID_USER_ID: User ID does not match
     - ALREADY_RELEASED: Reservation already released or confirmed
+    - INVALID_QUANTITY: Reservation record has no positive quantity
+    - NOT_DUE: Reservation deadline has not passed yet (due_by only)
 --]]
 
 local zone_availability_key = KEYS[1]
-local user_reservations_key = KEYS[2]
-local reservation_key = KEYS[3]
+local reservation_key = KEYS[2]
+local deadlines_key = KEYS[3]
+local user_reservations_key = KEYS[4]
+local expired_stream_key = KEYS[5]
 
 local booking_id = ARGV[1]
 local user_id = ARGV[2]
+local due_by = tonumber(ARGV[3])
+local stream_max_len = ARGV[4]
 
 -- Get reservation record
 local reservation = redis.call("HGETALL", reservation_key)
 if #reservation == 0 then
+    redis.call("ZREM", deadlines_key, reservation_key)
     return {0, "RESERVATION_NOT_FOUND", "Reservation does not exist or has expired"}
 end
 
@@ -58,6 +78,14 @@ if status ~= "reserved" then
     return {0, "ALREADY_RELEASED", "Reservation status is '" .. (status or "unknown") .. "', cannot release"}
 end
 
+-- Expiry only releases reservations whose deadline has passed
+if due_by then
+    local deadline = tonumber(redis.call("ZSCORE", deadlines_key, reservation_key))
+    if not deadline or deadline > due_by then
+        return {0, "NOT_DUE", "Reservation deadline has not passed"}
+    end
+end
+
 -- Get quantity from reservation
 local quantity = tonumber(reservation_data["quantity"])
 if not quantity or quantity <= 0 then
@@ -70,25 +98,40 @@ end
 local new_available = redis.call("INCRBY", zone_availability_key, quantity)
 
 -- 2. Decrement user's reserved count
-local current_user_reserved = redis.call("GET", user_reservations_key)
-current_user_reserved = tonumber(current_user_reserved) or 0
-
-local new_user_reserved = current_user_reserved - quantity
-if new_user_reserved < 0 then
-    new_user_reserved = 0
-end
-
-if new_user_reserved > 0 then
-    redis.call("SET", user_reservations_key, new_user_reserved)
-    -- Keep the same TTL as before
-    redis.call("EXPIRE", user_reservations_key, 660) -- 10 min + 1 min buffer
-else
-    -- If user has no more reservations, delete the key
-    redis.call("DEL", user_reservations_key)
+local new_user_reserved = 0
+if user_reservations_key then
+    local current_user_reserved = redis.call("GET", user_reservations_key)
+    current_user_reserved = tonumber(current_user_reserved) or 0
+
+    new_user_reserved = current_user_reserved - quantity
+    if new_user_reserved < 0 then
+        new_user_reserved = 0
+    end
+
+    if new_user_reserved > 0 then
+        redis.call("SET", user_reservations_key, new_user_reserved)
+        -- Keep the same TTL as before
+        redis.call("EXPIRE", user_reservations_key, 660) -- 10 min + 1 min buffer
+    else
+        -- If user has no more reservations, delete the key
+        redis.call("DEL", user_reservations_key)
+    end
 end
 
--- 3. Delete reservation record
+-- 3. Delete reservation record and its deadline
 redis.call("DEL", reservation_key)
+redis.call("ZREM", deadlines_key, reservation_key)
+
+-- 4. Publish the expired reservation to the expiry stream
+if expired_stream_key and due_by then
+    redis.call("XADD", expired_stream_key, "MAXLEN", "~", stream_max_len, "*",
+        "booking_id", booking_id,
+        "zone_id", reservation_data["zone_id"] or "",
+        "user_id", user_id,
+        "event_id", reservation_data["event_id"] or "",
+        "quantity", reservation_data["quantity"],
+        "expired_at", due_by)
+end
 
 -- Return success with new available seats and user's new reserved count
 return {1, new_available, new_user_reserved}
@@ -0,0 +1,52 @@
+--[[
+    Shard Transfer Lua Script
+    =========================
+    Version: 2
+
+    Moves seats between counters of a sharded zone: from the pool or a
+    sibling shard into the shard a reservation ran short on.
+
+    Key Structure:
+    - KEYS[1]: source counter  - zone:availability:{zone_id} (pool) or a sibling shard
+    - KEYS[2]: target counter  - zone:availability:{zone_id}:shard:{n}
+
+    Redis Cluster mode keeps the source and target in different slots and
+    moves seats with shard_transfer_out.lua and shard_transfer_in.lua instead.
+
+    Arguments:
+    - ARGV[1]: needed            - Seats the target is short of
+    - ARGV[2]: divisor           - Take at least 1/divisor of the source so
+                                   the target is not drained again at once
+
+    Returns:
+    - Seats moved (0 when the source is empty), or -1 when the source key
+      does not exist
+--]]
+
+local source_key = KEYS[1]
+local target_key = KEYS[2]
+
+local needed = tonumber(ARGV[1]) or 0
+local divisor = tonumber(ARGV[2]) or 1
+if divisor < 1 then
+    divisor = 1
+end
+
+local available = redis.call("GET", source_key)
+if not available then
+    return -1
+end
+available = tonumber(available) or 0
+if available <= 0 then
+    return 0
+end
+
+local take = math.max(needed, math.floor(available / divisor))
+if take > available then
+    take = available
+end
+
+redis.call("DECRBY", source_key, take)
+redis.call("INCRBY", target_key, take)
+
+return take
@@ -0,0 +1,35 @@
+--[[
+    Shard Transfer In Lua Script
+    ============================
+    Version: 1
+
+    Second half of a shard transfer on Redis Cluster: adds the seats taken by
+    shard_transfer_out.lua to the target counter. A marker in the target's
+    slot makes it idempotent, so the inventory reconciler can safely replay
+    a journaled transfer whose caller may or may not have finished it.
+
+    Key Structure:
+    - KEYS[1]: target counter  - zone:availability:{zone_id}:shard:{n}
+    - KEYS[2]: applied marker  - zone:transfer:{target tag}:{transfer_id}
+
+    Arguments:
+    - ARGV[1]: seats             - Seats taken off the source
+    - ARGV[2]: marker_ttl_ms     - How long the marker outlives a caller that
+                                   could not clear it
+
+    Returns:
+    - 1 when the seats were added, 0 when the transfer was applied already
+--]]
+
+local target_key = KEYS[1]
+local marker_key = KEYS[2]
+
+local seats = tonumber(ARGV[1]) or 0
+local marker_ttl_ms = tonumber(ARGV[2]) or 0
+
+if not redis.call("SET", marker_key, seats, "NX", "PX", marker_ttl_ms) then
+    return 0
+end
+
+redis.call("INCRBY", target_key, seats)
+return 1
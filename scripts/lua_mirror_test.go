@@ -0,0 +1,37 @@
+package scripts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// repositoryScripts is where the booking service keeps the scripts it runs;
+// lua/ mirrors them for this package and the docs
+const repositoryScripts = "../backend-booking/internal/repository/scripts"
+
+func TestLuaMirror_MatchesRepositoryScripts(t *testing.T) {
+	sources, err := filepath.Glob(filepath.Join(repositoryScripts, "*.lua"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(sources) == 0 {
+		t.Skipf("Skipping: %s not found", repositoryScripts)
+	}
+
+	for _, source := range sources {
+		name := filepath.Base(source)
+		want, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", source, err)
+		}
+		got, err := os.ReadFile(filepath.Join("lua", name))
+		if err != nil {
+			t.Errorf("lua/%s is missing; copy it from %s", name, repositoryScripts)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("lua/%s differs from %s; copy it over again", name, source)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+-- 000004_add_phone_verified.down.sql
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified;
@@ -0,0 +1,6 @@
+-- 000004_add_phone_verified.up.sql
+-- Auth DB: Mark phone numbers as verified; only verified numbers are put in
+-- access tokens (phone_number claim) for per-identity purchase limits
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified BOOLEAN DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP WITH TIME ZONE;
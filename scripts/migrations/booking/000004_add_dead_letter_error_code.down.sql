@@ -0,0 +1,4 @@
+-- Rollback dead letter error code
+
+DROP INDEX IF EXISTS idx_saga_dead_letters_topic_error_code;
+ALTER TABLE saga_dead_letters DROP COLUMN IF EXISTS error_code;
@@ -0,0 +1,6 @@
+-- Error code on dead letters so operators can filter DLQ entries by failure class
+ALTER TABLE saga_dead_letters ADD COLUMN IF NOT EXISTS error_code VARCHAR(100);
+
+-- Index for browsing dead letters by topic and error code
+CREATE INDEX IF NOT EXISTS idx_saga_dead_letters_topic_error_code
+    ON saga_dead_letters(topic, error_code, created_at);
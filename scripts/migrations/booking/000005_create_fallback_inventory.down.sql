@@ -0,0 +1,4 @@
+-- Rollback fallback inventory tables
+
+DROP TABLE IF EXISTS fallback_reservations;
+DROP TABLE IF EXISTS fallback_zone_inventory;
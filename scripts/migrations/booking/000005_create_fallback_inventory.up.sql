@@ -0,0 +1,41 @@
+-- ============================================================================
+-- Fallback Inventory for Booking Service
+-- ============================================================================
+-- Used while Redis is unavailable: reservations take seats from
+-- fallback_zone_inventory with a guarded UPDATE, and every reservation touched
+-- during the outage is recorded in fallback_reservations so it can be replayed
+-- into Redis once it recovers.
+-- ============================================================================
+
+-- Per-zone seat counter; a row is active while its zone is served from Postgres
+CREATE TABLE IF NOT EXISTS fallback_zone_inventory (
+    zone_id UUID PRIMARY KEY,            -- Reference to ticket_db.seat_zones
+    available_seats INT NOT NULL CHECK (available_seats >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    activated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Reservations reserved, confirmed or released while their zone was active
+CREATE TABLE IF NOT EXISTS fallback_reservations (
+    booking_id UUID PRIMARY KEY,
+    zone_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    event_id UUID NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,         -- reserved, confirmed, released
+    origin VARCHAR(20) NOT NULL,         -- fallback (reserved in Postgres) or redis
+    payment_id VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Index for resyncing a zone and releasing expired fallback reservations
+CREATE INDEX IF NOT EXISTS idx_fallback_reservations_zone_status
+    ON fallback_reservations(zone_id, status, expires_at);
+
+-- Index for the per-user limit while in fallback mode
+CREATE INDEX IF NOT EXISTS idx_fallback_reservations_user_event
+    ON fallback_reservations(user_id, event_id);
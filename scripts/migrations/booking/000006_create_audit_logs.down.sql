@@ -0,0 +1,4 @@
+-- Rollback booking audit log
+
+DROP TABLE IF EXISTS audit_logs;
+DROP TYPE IF EXISTS audit_action;
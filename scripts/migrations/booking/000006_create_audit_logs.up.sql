@@ -0,0 +1,62 @@
+-- ============================================================================
+-- Audit Log for Booking Service
+-- ============================================================================
+-- Written by pkg/middleware.AuditLogger. The booking service records
+-- purchases refused by per-identity purchase limits here (resource_type
+-- 'identity_limit'), with the identity kind and fingerprint in metadata.
+-- Same columns as the monolith's audit_logs, without foreign keys to
+-- tenants and users, which live in other databases.
+-- ============================================================================
+
+DO $$
+BEGIN
+    CREATE TYPE audit_action AS ENUM (
+        'create',
+        'update',
+        'delete',
+        'login',
+        'logout',
+        'reserve',
+        'confirm',
+        'cancel',
+        'refund',
+        'view'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+
+    -- Actor information
+    tenant_id UUID,               -- Reference to auth_db.tenants
+    user_id UUID,                 -- Reference to auth_db.users
+    user_email VARCHAR(255),
+    user_role VARCHAR(50),
+
+    -- Action details
+    action audit_action NOT NULL,
+    resource_type VARCHAR(100) NOT NULL, -- e.g., 'booking', 'identity_limit'
+    resource_id UUID,
+
+    -- Request context
+    ip_address INET,
+    user_agent TEXT,
+    request_id VARCHAR(255),
+    trace_id VARCHAR(255),
+
+    -- Change details
+    old_values JSONB,
+    new_values JSONB,
+    changes JSONB,
+
+    -- Metadata
+    metadata JSONB DEFAULT '{}',
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource_type, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_event_id ON audit_logs((metadata->>'event_id'), created_at DESC);
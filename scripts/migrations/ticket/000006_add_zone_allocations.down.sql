@@ -0,0 +1,17 @@
+-- 000006_add_zone_allocations.down.sql
+-- Held and comp seats are returned to public sale before the buckets are dropped
+
+DROP TABLE IF EXISTS zone_allocation_moves;
+
+UPDATE seat_zones SET available_seats = available_seats + held_seats + comp_seats;
+
+ALTER TABLE seat_zones DROP CONSTRAINT IF EXISTS chk_seats_sum_valid;
+ALTER TABLE seat_zones
+ADD CONSTRAINT chk_seats_sum_valid
+CHECK (available_seats + reserved_seats + sold_seats <= total_seats);
+
+ALTER TABLE seat_zones DROP CONSTRAINT IF EXISTS chk_comp_seats_non_negative;
+ALTER TABLE seat_zones DROP CONSTRAINT IF EXISTS chk_held_seats_non_negative;
+
+ALTER TABLE seat_zones DROP COLUMN IF EXISTS comp_seats;
+ALTER TABLE seat_zones DROP COLUMN IF EXISTS held_seats;
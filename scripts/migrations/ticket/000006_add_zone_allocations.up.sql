@@ -0,0 +1,35 @@
+-- 000006_add_zone_allocations.up.sql
+-- Allocation buckets: seats an organizer holds back from public sale
+-- (artist guests, sponsors, press) or sets aside for complimentary tickets.
+-- available_seats remains the public bucket.
+
+ALTER TABLE seat_zones ADD COLUMN IF NOT EXISTS held_seats INT NOT NULL DEFAULT 0;
+ALTER TABLE seat_zones ADD COLUMN IF NOT EXISTS comp_seats INT NOT NULL DEFAULT 0;
+
+ALTER TABLE seat_zones
+ADD CONSTRAINT chk_held_seats_non_negative
+CHECK (held_seats >= 0);
+
+ALTER TABLE seat_zones
+ADD CONSTRAINT chk_comp_seats_non_negative
+CHECK (comp_seats >= 0);
+
+-- Every bucket counts against the zone's capacity
+ALTER TABLE seat_zones DROP CONSTRAINT IF EXISTS chk_seats_sum_valid;
+ALTER TABLE seat_zones
+ADD CONSTRAINT chk_seats_sum_valid
+CHECK (available_seats + reserved_seats + sold_seats + held_seats + comp_seats <= total_seats);
+
+-- Audit trail of seats moved between buckets
+CREATE TABLE IF NOT EXISTS zone_allocation_moves (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    zone_id UUID NOT NULL REFERENCES seat_zones(id) ON DELETE CASCADE,
+    from_bucket VARCHAR(20) NOT NULL,
+    to_bucket VARCHAR(20) NOT NULL,
+    seats INT NOT NULL CHECK (seats > 0),
+    reason TEXT,
+    moved_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_zone_allocation_moves_zone_id ON zone_allocation_moves(zone_id, created_at DESC);
@@ -11,6 +11,9 @@ import (
 //go:embed lua/reserve_seats.lua
 var ReserveSeatsScript string
 
+// reservationDeadlinesKey indexes reservations by expiry deadline
+const reservationDeadlinesKey = "expiry:reservations"
+
 // ReserveSeatsParams holds parameters for seat reservation
 type ReserveSeatsParams struct {
 	ZoneID     string
@@ -50,8 +53,9 @@ func ReserveSeats(ctx context.Context, client *redis.Client, params ReserveSeats
 
 	keys := []string{
 		zoneAvailabilityKey,
-		userReservationsKey,
 		reservationKey,
+		reservationDeadlinesKey,
+		userReservationsKey,
 	}
 
 	args := []interface{}{
@@ -111,4 +115,9 @@ const (
 	ErrUserLimitExceeded = "USER_LIMIT_EXCEEDED"
 	ErrInvalidQuantity   = "INVALID_QUANTITY"
 	ErrZoneNotFound      = "ZONE_NOT_FOUND"
+
+	// Queue pass codes are only returned when a pass budget key is given
+	ErrQueuePassNotFound       = "QUEUE_PASS_NOT_FOUND"
+	ErrQueuePassExhausted      = "QUEUE_PASS_EXHAUSTED"
+	ErrQueuePassDeviceMismatch = "QUEUE_PASS_DEVICE_MISMATCH"
 )